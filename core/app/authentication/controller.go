@@ -2,11 +2,14 @@ package authentication
 
 import (
 	"base/core/email"
+	"base/core/helper"
+	"base/core/jobs"
 	"base/core/logger"
 	"base/core/router"
+	"base/core/router/middleware"
+	"base/core/types"
 	"errors"
 	"net/http"
-	"strings"
 
 	"go.uber.org/zap"
 )
@@ -25,193 +28,245 @@ func NewAuthController(service *AuthService, emailSender email.Sender, logger lo
 	}
 }
 
-func (c *AuthController) Routes(router *router.RouterGroup) {
-	router.POST("/register", c.Register)
-	router.POST("/login", c.Login)
-	router.POST("/logout", c.Logout)
-	router.POST("/forgot-password", c.ForgotPassword)
-	router.POST("/reset-password", c.ResetPassword)
+func (c *AuthController) Routes(r *router.RouterGroup) {
+	r.POST("/register", c.Register, middleware.Idempotency(nil)).Doc(routerDoc("Register", "Register user", RegisterRequest{}, AuthResponse{}, http.StatusCreated))
+	r.POST("/login", c.Login).Doc(routerDoc("Login", "Login user", LoginRequest{}, AuthResponse{}, http.StatusOK))
+	r.POST("/logout", c.Logout).Doc(routerDoc("Logout", "Logout user", nil, SuccessResponse{}, http.StatusOK))
+	r.POST("/forgot-password", c.ForgotPassword).Doc(routerDoc("Forgot Password", "Request to reset password", ForgotPasswordRequest{}, SuccessResponse{}, http.StatusOK))
+	r.POST("/reset-password", c.ResetPassword).Doc(routerDoc("Reset Password", "Reset user password using token", ResetPasswordRequest{}, SuccessResponse{}, http.StatusOK))
+	r.GET("/password-policy", c.PasswordPolicy).Doc(routerDoc("Password Policy", "Rules new passwords must follow", nil, helper.PasswordPolicy{}, http.StatusOK))
+	r.POST("/verify-2fa", c.Verify2FA).Doc(routerDoc("Verify 2FA", "Exchange a pending MFA token and code for an access token", Verify2FARequest{}, AuthResponse{}, http.StatusOK))
 }
 
-// @Summary Register
-// @Description Register user
-// @Security ApiKeyAuth
-// @Tags Core/Auth
-// @Accept json
-// @Produce json
-// @Param body body RegisterRequest true "Register Request"
-// @Success 201 {object} AuthResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 409 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Router /auth/register [post]
+// TOTPRoutes registers the 2FA management routes; r must require an access token
+func (c *AuthController) TOTPRoutes(r *router.RouterGroup) {
+	r.POST("/enable", c.EnableTOTP).Doc(routerDoc("Enable 2FA", "Generate a TOTP secret for the authenticated user", nil, EnableTOTPResponse{}, http.StatusOK))
+	r.POST("/confirm", c.ConfirmTOTP).Doc(routerDoc("Confirm 2FA", "Activate 2FA with a code from the authenticator app", TOTPCodeRequest{}, RecoveryCodesResponse{}, http.StatusOK))
+	r.POST("/disable", c.DisableTOTP).Doc(routerDoc("Disable 2FA", "Turn off 2FA with a current code", TOTPCodeRequest{}, SuccessResponse{}, http.StatusOK))
+}
+
+// routerDoc builds the swagger metadata shared by all auth routes
+func routerDoc(summary, description string, request, response any, status int) router.Doc {
+	return router.Doc{
+		Summary:     summary,
+		Description: description,
+		Tags:        []string{"Core/Auth"},
+		Request:     request,
+		Response:    response,
+		Status:      status,
+	}
+}
+
+// Register handles POST /auth/register
 func (c *AuthController) Register(ctx *router.Context) error {
+	log := logger.FromContext(ctx)
+
 	var req RegisterRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		// Log why the request was invalid
-		c.logger.Error("Invalid register request",
-			logger.String("error", err.Error()))
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	if errs := types.BindAndValidate(ctx, &req); errs != nil {
+		log.Error("Invalid register request")
+		return ctx.JSON(http.StatusBadRequest, errs)
 	}
 
-	user, err := c.service.Register(&req)
+	user, err := c.service.Register(ctx.Context(), &req)
 	if err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+		var weak *helper.PasswordError
+		if errors.As(err, &weak) {
+			return ctx.JSON(http.StatusBadRequest, weak.ValidationErrors("password"))
+		}
 		// Log the underlying service error to help debug 500s
-		c.logger.Error("Failed to register user",
+		log.Error("Failed to register user",
 			logger.String("error", err.Error()))
-		status := http.StatusInternalServerError
-		// Provide a better status for common cases
-		if strings.Contains(strings.ToLower(err.Error()), "user already exists") {
-			status = http.StatusConflict // 409
+		if errors.Is(err, ErrUserExists) {
+			return ctx.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
 		}
-		return ctx.JSON(status, ErrorResponse{Error: err.Error()})
-	}
-
-	//	Send welcome email
-	msg := email.Message{
-		To:      []string{user.Email},
-		From:    "no-reply@base.al",
-		Subject: "Welcome to Base",
-		Body:    c.getWelcomeEmailBody(user.FirstName),
-		IsHTML:  true,
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: types.T(ctx, "errors.internal")})
 	}
 
-	err = email.Send(msg)
+	// Send welcome email in the background
+	err = jobs.Enqueue(WelcomeEmailJob, emailJobPayload{Email: user.Email, FirstName: user.FirstName})
 	if err != nil {
-		c.logger.Error("Failed to send welcome email",
+		log.Error("Failed to enqueue welcome email",
 			logger.String("error", err.Error()),
 			logger.String("email", user.Email))
-	} else {
-		c.logger.Info("Welcome email sent",
-			logger.String("email", user.Email))
 	}
 
 	return ctx.JSON(http.StatusCreated, user)
 }
 
-// @Summary Login
-// @Description Login user
-// @Security ApiKeyAuth
-// @Tags Core/Auth
-// @Accept json
-// @Produce json
-// @Param body body LoginRequest true "Login Request"
-// @Success 200 {object} AuthResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Router /auth/login [post]
+// Login handles POST /auth/login
 func (c *AuthController) Login(ctx *router.Context) error {
 	var req LoginRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	if errs := types.BindAndValidate(ctx, &req); errs != nil {
+		return ctx.JSON(http.StatusBadRequest, errs)
 	}
 
-	response, err := c.service.Login(&req)
+	response, err := c.service.Login(ctx.Context(), &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "access_denied") {
-			// Return both the response and error when user is not an author
+		if ctx.Canceled(err) {
+			return nil
+		}
+		switch {
+		case errors.Is(err, ErrLoginDenied):
+			// A login listener turned the user away; it may have set a response
 			return ctx.JSON(http.StatusForbidden, map[string]any{
 				"error": err.Error(),
 				"data":  response,
 			})
+		case errors.Is(err, ErrInvalidCredentials):
+			return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: types.T(ctx, "errors.invalid_credentials")})
 		}
-		if strings.Contains(err.Error(), "invalid credentials") {
-			return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
-		}
-		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: types.T(ctx, "errors.internal")})
+	}
+
+	if response.MFARequired {
+		return ctx.JSON(http.StatusOK, MFAPendingResponse{
+			MFARequired: true,
+			MFAToken:    response.MFAToken,
+			Exp:         response.Exp,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// PasswordPolicy returns the rules new passwords are checked against
+func (c *AuthController) PasswordPolicy(ctx *router.Context) error {
+	return ctx.JSON(http.StatusOK, helper.CurrentPasswordPolicy())
+}
+
+// Verify2FA completes a login for users with two-factor authentication enabled
+func (c *AuthController) Verify2FA(ctx *router.Context) error {
+	var req Verify2FARequest
+	if errs := types.BindAndValidate(ctx, &req); errs != nil {
+		return ctx.JSON(http.StatusBadRequest, errs)
+	}
+
+	response, err := c.service.Verify2FA(ctx.Context(), req.MFAToken, req.Code, req.RememberMe)
+	if err != nil {
+		return c.totpError(ctx, err)
 	}
 
 	return ctx.JSON(http.StatusOK, response)
 }
 
+// EnableTOTP starts 2FA setup for the authenticated user
+func (c *AuthController) EnableTOTP(ctx *router.Context) error {
+	secret, url, err := c.service.EnableTOTP(ctx.Context(), ctx.GetUint("user_id"))
+	if err != nil {
+		return c.totpError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, EnableTOTPResponse{Secret: secret, OTPAuthURL: url})
+}
+
+// ConfirmTOTP activates 2FA and returns the recovery codes
+func (c *AuthController) ConfirmTOTP(ctx *router.Context) error {
+	var req TOTPCodeRequest
+	if errs := types.BindAndValidate(ctx, &req); errs != nil {
+		return ctx.JSON(http.StatusBadRequest, errs)
+	}
+
+	codes, err := c.service.ConfirmTOTP(ctx.Context(), ctx.GetUint("user_id"), req.Code)
+	if err != nil {
+		return c.totpError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTP turns off 2FA for the authenticated user
+func (c *AuthController) DisableTOTP(ctx *router.Context) error {
+	var req TOTPCodeRequest
+	if errs := types.BindAndValidate(ctx, &req); errs != nil {
+		return ctx.JSON(http.StatusBadRequest, errs)
+	}
+
+	if err := c.service.DisableTOTP(ctx.Context(), ctx.GetUint("user_id"), req.Code); err != nil {
+		return c.totpError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessResponse{Message: "Two-factor authentication disabled"})
+}
+
+// totpError maps 2FA service errors to responses
+func (c *AuthController) totpError(ctx *router.Context, err error) error {
+	if ctx.Canceled(err) {
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, ErrInvalidToken):
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: types.T(ctx, "errors.invalid_token")})
+	case errors.Is(err, ErrInvalidTOTPCode):
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: types.T(ctx, "errors.invalid_2fa_code")})
+	case errors.Is(err, ErrUserNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: types.T(ctx, "errors.user_not_found")})
+	case errors.Is(err, ErrTOTPAlreadyEnabled), errors.Is(err, ErrTOTPNotEnabled), errors.Is(err, ErrTOTPNotPending):
+		return ctx.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrMFAUnavailable):
+		return ctx.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: err.Error()})
+	default:
+		c.logger.Error("Two-factor authentication failed", logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: types.T(ctx, "errors.internal")})
+	}
+}
+
 // Logout handles user logout
-// @Summary Logout
-// @Description Logout user
-// @Security ApiKeyAuth
-// @Tags Core/Auth
-// @Accept json
-// @Produce json
-// @Success 200 {object} SuccessResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Router /auth/logout [post]
 func (c *AuthController) Logout(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, SuccessResponse{Message: "Logout successful"})
 }
 
-// @Summary Forgot Password
-// @Description Request to reset password
-// @Security ApiKeyAuth
-// @Tags Core/Auth
-// @Accept json
-// @Produce json
-// @Param body body ForgotPasswordRequest true "Forgot Password Request"
-// @Success 200 {object} SuccessResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Router /auth/forgot-password [post]
+// ForgotPassword handles POST /auth/forgot-password
 func (c *AuthController) ForgotPassword(ctx *router.Context) error {
 	var req ForgotPasswordRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		c.logger.Error("Failed to bind JSON in ForgotPassword", zap.Error(err))
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	if errs := types.BindAndValidate(ctx, &req); errs != nil {
+		c.logger.Error("Invalid forgot password request")
+		return ctx.JSON(http.StatusBadRequest, errs)
 	}
 
 	c.logger.Info("Processing forgot password request", zap.String("email", req.Email))
 
-	err := c.service.ForgotPassword(req.Email)
+	err := c.service.ForgotPassword(ctx.Context(), req.Email)
 	if err != nil {
-		if strings.Contains(err.Error(), "user not found") {
-			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
-		} else {
-			return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "An error occurred while processing your request"})
+		if ctx.Canceled(err) {
+			return nil
+		}
+		if errors.Is(err, ErrUserNotFound) {
+			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: types.T(ctx, "errors.user_not_found")})
 		}
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: types.T(ctx, "errors.request_failed")})
 	}
 
 	return ctx.JSON(http.StatusOK, SuccessResponse{Message: "Password reset email sent"})
 }
 
 // ResetPassword handles password reset requests
-// @Summary Reset Password
-// @Description Reset user password using token
-// @Security ApiKeyAuth
-// @Tags Core/Auth
-// @Accept json
-// @Produce json
-// @Param body body ResetPasswordRequest true "Reset Password Request"
-// @Success 200 {object} SuccessResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Router /auth/reset-password [post]
 func (c *AuthController) ResetPassword(ctx *router.Context) error {
 	var req ResetPasswordRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+	if errs := types.BindAndValidate(ctx, &req); errs != nil {
+		return ctx.JSON(http.StatusBadRequest, errs)
 	}
 
-	err := c.service.ResetPassword(req.Email, req.Token, req.NewPassword)
+	err := c.service.ResetPassword(ctx.Context(), req.Email, req.Token, req.NewPassword)
 	if err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+		var weak *helper.PasswordError
 		switch {
-		case errors.Is(err, ErrInvalidToken):
-			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid or expired token"})
+		case errors.As(err, &weak):
+			return ctx.JSON(http.StatusBadRequest, weak.ValidationErrors("new_password"))
+		case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrTokenExpired):
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: types.T(ctx, "errors.invalid_token")})
 		case errors.Is(err, ErrUserNotFound):
-			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: types.T(ctx, "errors.user_not_found")})
 		default:
-			return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to reset password"})
+			return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: types.T(ctx, "errors.reset_failed")})
 		}
 	}
 
 	return ctx.JSON(http.StatusOK, SuccessResponse{Message: "Password reset successful"})
 }
-
-func (c *AuthController) getWelcomeEmailBody(name string) string {
-	return "<h1>Welcome to Base!</h1>" +
-		"<p>Hi " + name + ",</p>" +
-		"<p>Thank you for registering with our application.</p>" +
-		"<p>Best regards,<br>Team</p>"
-}
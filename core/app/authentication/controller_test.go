@@ -0,0 +1,103 @@
+package authentication
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/helper"
+	"base/core/logger"
+	"base/core/router"
+	"base/core/types"
+
+	"go.uber.org/zap"
+)
+
+// withPasswordPolicy enforces policy for the rest of the test
+func withPasswordPolicy(t *testing.T, policy helper.PasswordPolicy) {
+	t.Helper()
+	saved := helper.CurrentPasswordPolicy()
+	helper.SetPasswordPolicy(policy)
+	t.Cleanup(func() { helper.SetPasswordPolicy(saved) })
+}
+
+func authRouter(s *AuthService) *router.Router {
+	r := router.New()
+	NewAuthController(s, nil, logger.NewLoggerFromZap(zap.NewNop())).Routes(r.Group("/auth"))
+	return r
+}
+
+func send(r *router.Router, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// rules returns the rules of a validation error response
+func rules(t *testing.T, w *httptest.ResponseRecorder, field string) []string {
+	t.Helper()
+	var response types.ValidationErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	var got []string
+	for _, e := range response.Errors {
+		if e.Field != field {
+			t.Errorf("error on %q, want %q", e.Field, field)
+		}
+		got = append(got, e.Rule)
+	}
+	return got
+}
+
+func TestPasswordPolicyEndpoint(t *testing.T) {
+	policy := helper.PasswordPolicy{MinLength: 12, RequireDigit: true, RejectCommon: true}
+	withPasswordPolicy(t, policy)
+
+	w := send(authRouter(newTestService(t, nil)), http.MethodGet, "/auth/password-policy", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var got helper.PasswordPolicy
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got != policy {
+		t.Errorf("policy = %+v, %v; want %+v", got, err, policy)
+	}
+}
+
+func TestWeakPasswordsRejected(t *testing.T) {
+	withPasswordPolicy(t, helper.PasswordPolicy{MinLength: 10, RequireDigit: true, RejectCommon: true})
+	s := newTestService(t, nil)
+	r := authRouter(s)
+
+	w := send(r, http.MethodPost, "/auth/register", `{"username":"ada","email":"ada@example.com","password":"password"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("register status = %d: %s", w.Code, w.Body.String())
+	}
+	if got := rules(t, w, "password"); strings.Join(got, ",") != "too_short,missing_digit,too_common" {
+		t.Errorf("register rules = %v", got)
+	}
+	var users int64
+	s.db.Model(&AuthUser{}).Count(&users)
+	if users != 0 {
+		t.Errorf("%d users registered with a weak password", users)
+	}
+
+	expiry := time.Now().Add(time.Hour)
+	bob := &AuthUser{ResetToken: "reset", ResetTokenExpiry: &expiry}
+	bob.Username, bob.Email = "bob", "bob@example.com"
+	createUser(t, s, bob)
+	w = send(r, http.MethodPost, "/auth/reset-password", `{"email":"bob@example.com","token":"reset","new_password":"short1"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("reset status = %d: %s", w.Code, w.Body.String())
+	}
+	if got := rules(t, w, "new_password"); strings.Join(got, ",") != "too_short" {
+		t.Errorf("reset rules = %v", got)
+	}
+}
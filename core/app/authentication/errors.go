@@ -1,13 +1,30 @@
 package authentication
 
-import "errors"
+import (
+	"errors"
 
-// Auth-specific errors
+	"base/core/types"
+)
+
+// Auth errors, shared with other packages through types
 var (
-	ErrInvalidToken    = errors.New("invalid token")
-	ErrUserNotFound    = errors.New("user not found")
-	ErrTokenExpired    = errors.New("token expired")
-	ErrInvalidPassword = errors.New("invalid password")
-	ErrEmailExists     = errors.New("email already exists")
-	ErrInvalidEmail    = errors.New("invalid email")
+	ErrInvalidToken       = types.ErrInvalidToken
+	ErrUserNotFound       = types.ErrUserNotFound
+	ErrTokenExpired       = types.ErrTokenExpired
+	ErrInvalidPassword    = types.ErrInvalidPassword
+	ErrEmailExists        = types.ErrEmailExists
+	ErrInvalidEmail       = types.ErrInvalidEmail
+	ErrInvalidCredentials = types.ErrInvalidCredentials
+	ErrUserExists         = types.ErrUserExists
+
+	// ErrLoginDenied is wrapped with the reason when a user.login_attempt
+	// listener rejects the login
+	ErrLoginDenied = types.ErrLoginDenied
+
+	// Two-factor authentication errors
+	ErrInvalidTOTPCode    = errors.New("invalid two-factor code")
+	ErrTOTPNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotPending     = errors.New("two-factor authentication has not been set up")
+	ErrMFAUnavailable     = errors.New("two-factor authentication is not configured")
 )
@@ -0,0 +1,76 @@
+package authentication
+
+import (
+	"context"
+
+	"base/core/app/profile"
+	"base/core/email"
+	"base/core/jobs"
+)
+
+// Background jobs for emails that shouldn't hold up the request, and for
+// cleanup that is retried until it succeeds
+const (
+	WelcomeEmailJob         = "auth.welcome_email"
+	PasswordChangedEmailJob = "auth.password_changed_email"
+	RemoveUserDataJob       = "auth.remove_user_data"
+)
+
+// emailJobPayload identifies the recipient of an auth email job
+type emailJobPayload struct {
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+}
+
+// userJobPayload identifies the user a cleanup job is for
+type userJobPayload struct {
+	UserId uint `json:"user_id"`
+}
+
+// registerJobs registers the auth job handlers
+func (s *AuthService) registerJobs() {
+	jobs.Register(WelcomeEmailJob, s.welcomeEmailJob)
+	jobs.Register(PasswordChangedEmailJob, s.passwordChangedEmailJob)
+	jobs.Register(RemoveUserDataJob, s.removeUserDataJob)
+}
+
+func (s *AuthService) removeUserDataJob(ctx context.Context, job *jobs.Job) error {
+	var payload userJobPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	return s.removeUserData(ctx, payload.UserId)
+}
+
+func (s *AuthService) welcomeEmailJob(ctx context.Context, job *jobs.Job) error {
+	var payload emailJobPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	return email.Send(email.Message{
+		To:      []string{payload.Email},
+		From:    "no-reply@base.al",
+		Subject: "Welcome to Base",
+		Body:    welcomeEmailBody(payload.FirstName),
+		IsHTML:  true,
+	})
+}
+
+func (s *AuthService) passwordChangedEmailJob(ctx context.Context, job *jobs.Job) error {
+	var payload emailJobPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	return s.sendPasswordChangedEmail(&AuthUser{
+		User: profile.User{Email: payload.Email, FirstName: payload.FirstName},
+	})
+}
+
+func welcomeEmailBody(name string) string {
+	return "<h1>Welcome to Base!</h1>" +
+		"<p>Hi " + name + ",</p>" +
+		"<p>Thank you for registering with our application.</p>" +
+		"<p>Best regards,<br>Team</p>"
+}
@@ -10,6 +10,30 @@ type AuthUser struct {
 	LastLogin        *time.Time `gorm:"column:last_login"`
 	ResetToken       string     `gorm:"column:reset_token"`
 	ResetTokenExpiry *time.Time `gorm:"column:reset_token_expiry"`
+	TOTPSecret       string     `gorm:"column:totp_secret;size:255"`
+	TOTPEnabled      bool       `gorm:"column:totp_enabled;default:false"`
+
+	// TOTPLastStep is the time step of the last accepted TOTP code; codes
+	// from it or earlier steps are rejected as replays
+	TOTPLastStep int64 `gorm:"column:totp_last_step;default:0"`
+
+	// MFAChallenge is the hash of the challenge in the outstanding MFA
+	// pending token, cleared once the token is used or exhausted
+	MFAChallenge      string `gorm:"column:mfa_challenge;size:64"`
+	MFAFailedAttempts int    `gorm:"column:mfa_failed_attempts;default:0"`
+}
+
+// RecoveryCode is a hashed, single-use backup code for two-factor login
+type RecoveryCode struct {
+	Id        uint       `gorm:"column:id;primary_key;auto_increment"`
+	UserId    uint       `gorm:"column:user_id;not null;index"`
+	CodeHash  string     `gorm:"column:code_hash;not null;size:64;index"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "auth_recovery_codes"
 }
 
 func (AuthUser) TableName() string {
@@ -24,29 +48,28 @@ type LoginEvent struct {
 }
 
 // RegisterRequest represents the payload for user registration
-// @Description Registration request payload
-// @name RegisterRequest
 type RegisterRequest struct {
-	// @Description User's first name
+	// User's first name
 	FirstName string `json:"first_name" example:"John" gorm:"column:first_name"`
-	// @Description User's last name
+	// User's last name
 	LastName string `json:"last_name" example:"Doe" gorm:"column:last_name"`
-	// @Description Username for the account
+	// Username for the account
 	Username string `json:"username" example:"johndoe" gorm:"column:username"`
-	// @Description User's phone number
+	// User's phone number
 	Phone string `json:"phone" example:"+1234567890" gorm:"column:phone"`
-	// @Description User's email address
+	// User's email address
 	Email string `json:"email" binding:"required,email" example:"john@example.com"`
-	// @Description Password for the account (minimum 8 characters)
-	Password string `json:"password" binding:"required,min=8" example:"password123"`
+	// Password for the account, checked against GET /auth/password-policy
+	Password string `json:"password" binding:"required,max=255" example:"password123"`
 }
 
 // LoginRequest represents the payload for user login
-// @Description Login request payload
-// @name LoginRequest
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email" example:"john@example.com"`
 	Password string `json:"password" binding:"required" example:"password123"`
+
+	// RememberMe issues a token lasting REMEMBER_ME_TTL instead of ACCESS_TOKEN_TTL
+	RememberMe bool `json:"remember_me" example:"false"`
 }
 
 type ForgotPasswordRequest struct {
@@ -56,7 +79,7 @@ type ForgotPasswordRequest struct {
 type ResetPasswordRequest struct {
 	Email       string `json:"email" binding:"required,email" example:"john@example.com"`
 	Token       string `json:"token" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6" example:"newpassword123"`
+	NewPassword string `json:"new_password" binding:"required,max=255" example:"newpassword123"`
 }
 
 type AuthResponse struct {
@@ -64,6 +87,42 @@ type AuthResponse struct {
 	AccessToken string `json:"accessToken"`
 	Exp         int64  `json:"exp"`
 	Extend      any    `json:"extend,omitempty"`
+
+	// Set instead of the access token when the user still has to pass 2FA
+	MFARequired bool   `json:"-"`
+	MFAToken    string `json:"-"`
+}
+
+// MFAPendingResponse is returned by login when two-factor verification is required
+type MFAPendingResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	Exp         int64  `json:"exp"`
+}
+
+// Verify2FARequest exchanges a pending MFA token and a TOTP or recovery code for an access token
+type Verify2FARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+
+	// RememberMe carries the choice made at login, see LoginRequest
+	RememberMe bool `json:"remember_me" example:"false"`
+}
+
+// TOTPCodeRequest carries a TOTP code for confirming or disabling 2FA
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// EnableTOTPResponse holds the secret to add to an authenticator app
+type EnableTOTPResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+// RecoveryCodesResponse holds the plaintext recovery codes, shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type ErrorResponse struct {
@@ -1,12 +1,18 @@
 package authentication
 
 import (
+	"base/core/config"
 	"base/core/email"
 	"base/core/emitter"
+	"base/core/events"
+	"base/core/jobs"
 	"base/core/logger"
 	"base/core/module"
 	"base/core/router"
 	"base/core/router/middleware"
+	"base/core/scheduler"
+	"base/core/types"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -21,8 +27,8 @@ type AuthenticationModule struct {
 	Emitter     *emitter.Emitter
 }
 
-func NewAuthenticationModule(db *gorm.DB, router *router.RouterGroup, emailSender email.Sender, logger logger.Logger, emitter *emitter.Emitter) module.Module {
-	service := NewAuthService(db, emailSender, emitter)
+func NewAuthenticationModule(db *gorm.DB, router *router.RouterGroup, emailSender email.Sender, logger logger.Logger, emitter *emitter.Emitter, cfg *config.Config) module.Module {
+	service := NewAuthService(db, emailSender, emitter, cfg)
 	controller := NewAuthController(service, emailSender, logger)
 
 	authModule := &AuthenticationModule{
@@ -37,20 +43,59 @@ func NewAuthenticationModule(db *gorm.DB, router *router.RouterGroup, emailSende
 	return authModule
 }
 
+// DependsOn initializes the users module first; AuthUser extends its table.
+// Emails are sent through the jobs queue, cleanup runs on the scheduler and
+// user.registered is published through the events outbox.
+func (m *AuthenticationModule) DependsOn() []string {
+	return []string{"events", "jobs", "scheduler", "users"}
+}
+
+// Init registers the auth background job handlers, cleanup tasks and the
+// user.registered payload type, and removes credentials of deleted accounts
+func (m *AuthenticationModule) Init() error {
+	m.Service.registerJobs()
+	events.Register("user.registered", types.UserData{})
+	m.Emitter.On("user.deleted", m.enqueueUserCleanup)
+
+	err := scheduler.Every("0 * * * *", "auth.cleanup-reset-tokens", m.Service.CleanupExpiredResetTokens,
+		scheduler.WithDescription("Clear expired password reset tokens"),
+		scheduler.WithJitter(time.Minute))
+	if err != nil {
+		m.Logger.Error("Failed to schedule reset token cleanup", logger.String("error", err.Error()))
+	}
+	return nil
+}
+
+// enqueueUserCleanup queues RemoveUserDataJob for a deleted user, so a
+// failed cleanup is retried by the jobs queue
+func (m *AuthenticationModule) enqueueUserCleanup(data any) {
+	user, ok := data.(types.UserData)
+	if !ok {
+		return
+	}
+	if err := jobs.Enqueue(RemoveUserDataJob, userJobPayload{UserId: user.Id}); err != nil {
+		m.Logger.Error("Failed to enqueue cleanup of deleted user",
+			logger.Uint("user_id", user.Id),
+			logger.String("error", err.Error()))
+	}
+}
+
 func (m *AuthenticationModule) Routes(router *router.RouterGroup) {
 	// Router is already /api/auth from start.go
 	authMiddleware := middleware.Api() // your X-Api-Key middleware
-	authRouter := router.Group("", authMiddleware)
+	authRouter := router.Group("", authMiddleware).Security("ApiKeyAuth")
 
 	m.Controller.Routes(authRouter)
+	m.Controller.TOTPRoutes(authRouter.Group("/2fa", middleware.BearerAuth()).Security("BearerAuth"))
 }
 
 func (m *AuthenticationModule) Migrate() error {
-	return m.DB.AutoMigrate(&AuthUser{})
+	return m.DB.AutoMigrate(&AuthUser{}, &RecoveryCode{})
 }
 
 func (m *AuthenticationModule) GetModels() []any {
 	return []any{
 		&AuthUser{},
+		&RecoveryCode{},
 	}
 }
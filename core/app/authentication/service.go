@@ -2,21 +2,27 @@ package authentication
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"text/template"
 	"time"
 
 	"base/core/app/profile"
+	"base/core/config"
+	"base/core/database"
 	"base/core/email"
 	"base/core/emitter"
+	"base/core/events"
 	"base/core/helper"
+	"base/core/jobs"
+	"base/core/logger"
 	"base/core/types"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -30,44 +36,134 @@ type AuthService struct {
 	db          *gorm.DB
 	emailSender email.Sender
 	emitter     *emitter.Emitter
+
+	// releaseDeletedUnique lets soft-deleted users give up their email,
+	// username and phone so they can be registered again
+	releaseDeletedUnique bool
+
+	// Lifetime of access tokens, and of those issued to remember_me logins
+	accessTokenTTL time.Duration
+	rememberMeTTL  time.Duration
+
+	// AES-256 key for TOTP secrets at rest, nil when MFA_ENCRYPTION_KEY is unset
+	mfaKey []byte
 }
 
 // NewAuthService creates a new authentication service
-func NewAuthService(db *gorm.DB, emailSender email.Sender, emitter *emitter.Emitter) *AuthService {
+func NewAuthService(db *gorm.DB, emailSender email.Sender, emitter *emitter.Emitter, cfg *config.Config) *AuthService {
 	return &AuthService{
-		db:          db,
-		emailSender: emailSender,
-		emitter:     emitter,
+		db:                   db,
+		emailSender:          emailSender,
+		emitter:              emitter,
+		releaseDeletedUnique: cfg.AuthReleaseDeletedUnique,
+		accessTokenTTL:       cfg.AccessTokenTTL,
+		rememberMeTTL:        cfg.RememberMeTTL,
+		mfaKey:               mfaKey(cfg.MFAEncryptionKey),
+	}
+}
+
+// tokenTTL is the lifetime of a token issued to a login with or without remember_me
+func (s *AuthService) tokenTTL(rememberMe bool) time.Duration {
+	if rememberMe {
+		return s.rememberMeTTL
 	}
+	return s.accessTokenTTL
 }
 
 func (s *AuthService) ValidateKey(key string) (any, error) {
 	return nil, nil
 }
 
-// validateUser checks if username or email already exists
-func (s *AuthService) validateUser(email, username string) error {
+// validateUser checks if username or email already exists, including emails
+// other users are waiting to confirm. Soft-deleted users only count when they
+// keep their unique values.
+func (s *AuthService) validateUser(ctx context.Context, email, username string) error {
+	query := s.db.WithContext(ctx).Model(&AuthUser{})
+	if !s.releaseDeletedUnique {
+		query = query.Unscoped()
+	}
+
 	var count int64
-	if err := s.db.Model(&AuthUser{}).
-		Where("email = ? OR username = ?", email, username).
+	if err := query.
+		Where("email = ? OR username = ? OR (pending_email = ? AND email_change_expires_at > ?)",
+			email, username, email, time.Now()).
 		Count(&count).Error; err != nil {
 		return fmt.Errorf("database error: %w", err)
 	}
 
 	if count > 0 {
-		return errors.New("user already exists")
+		return ErrUserExists
+	}
+	return nil
+}
+
+// releaseDeletedValues frees unique values still held by soft-deleted users.
+// The unique indexes cover deleted rows too, so their email, username and phone
+// are rewritten to a tombstone that cannot collide with a real value. Empty
+// values are optional fields and never match or get rewritten.
+func (s *AuthService) releaseDeletedValues(tx *gorm.DB, email, username, phone string) error {
+	var conditions []string
+	var args []any
+	for column, value := range map[string]string{"email": email, "username": username, "phone": phone} {
+		if value != "" {
+			conditions = append(conditions, column+" = ?")
+			args = append(args, value)
+		}
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	var deleted []AuthUser
+	if err := tx.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Where(strings.Join(conditions, " OR "), args...).
+		Find(&deleted).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	for _, user := range deleted {
+		updates := map[string]any{}
+		for column, value := range map[string]string{"email": user.Email, "username": user.Username, "phone": user.Phone} {
+			if value != "" {
+				updates[column] = tombstone(user.Id, value)
+			}
+		}
+		if err := tx.Unscoped().Model(&AuthUser{}).Where("id = ?", user.Id).UpdateColumns(updates).Error; err != nil {
+			return fmt.Errorf("failed to release deleted user values: %w", err)
+		}
 	}
 	return nil
 }
 
-func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
+// tombstone returns a released unique value, kept within the 255 column limit
+func tombstone(id uint, value string) string {
+	released := fmt.Sprintf("deleted-%d-%s", id, value)
+	if len(released) > 255 {
+		released = released[:255]
+	}
+	return released
+}
+
+func (s *AuthService) Register(ctx context.Context, req *RegisterRequest) (*AuthResponse, error) {
+	return s.RegisterWith(ctx, req, nil)
+}
+
+// RegisterWith registers a user like Register and, when fn is not nil, runs
+// it in the same transaction once the user is created. An error from fn
+// rolls the registration back and is returned as is.
+func (s *AuthService) RegisterWith(ctx context.Context, req *RegisterRequest, fn func(tx *gorm.DB, user *AuthUser) error) (*AuthResponse, error) {
+	if err := helper.ValidatePassword(req.Password); err != nil {
+		return nil, err
+	}
+
 	// Validate unique constraints first
-	if err := s.validateUser(req.Email, req.Username); err != nil {
+	if err := s.validateUser(ctx, req.Email, req.Username); err != nil {
 		return nil, err
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := helper.Passwords().Hash(req.Password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -77,7 +173,7 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 	user := AuthUser{
 		User: profile.User{
 			Email:     req.Email,
-			Password:  string(hashedPassword),
+			Password:  hashedPassword,
 			FirstName: req.FirstName,
 			LastName:  req.LastName,
 			Username:  req.Username,
@@ -86,78 +182,98 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		LastLogin: &now,
 	}
 
-	// Start transaction
-	tx := s.db.Begin()
-	if tx.Error != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
-	}
+	err = database.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+		if s.releaseDeletedUnique {
+			if err := s.releaseDeletedValues(tx, req.Email, req.Username, req.Phone); err != nil {
+				return err
+			}
+		}
 
-	if err := tx.Create(&user).Error; err != nil {
-		tx.Rollback()
-		if errors.Is(err, gorm.ErrDuplicatedKey) {
-			return nil, errors.New("user already exists")
+		if err := tx.Create(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrUserExists
+			}
+			return fmt.Errorf("failed to create user: %w", err)
 		}
-		return nil, fmt.Errorf("failed to create user: %w", err)
-	}
 
-	if err := tx.Commit().Error; err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		// Published through the outbox so it isn't lost if we crash
+		// between the commit and delivery
+		if err := events.Publish(tx, "user.registered", types.UserData{
+			Id:        user.Id,
+			FirstName: user.User.FirstName,
+			LastName:  user.User.LastName,
+			Username:  user.Username,
+			Email:     user.Email,
+		}); err != nil {
+			return err
+		}
+
+		if fn != nil {
+			return fn(tx, &user)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate JWT token
-	token, err := helper.GenerateJWT(user.User.Id)
+	ttl := s.tokenTTL(false)
+	token, err := helper.GenerateJWTWithTTL(user.User.Id, ttl)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	userData := types.UserData{
-		Id:        user.Id,
-		FirstName: user.User.FirstName,
-		LastName:  user.User.LastName,
-		Username:  user.Username,
-		Email:     user.Email,
-	}
-
-	// Emit registration event
-	if s.emitter != nil {
-		s.emitter.Emit("user.registered", userData)
-	} else {
-		fmt.Printf("Emitter is nil in AuthService.Register; cannot emit 'user.registered' event")
-	}
-
-	// Send welcome email asynchronously
-	// go func() {
-	// 	if err := s.sendWelcomeEmail(&user); err != nil {
-	// 		fmt.Printf("Failed to send welcome email: %v", err)
-	// 	}
-	// }()
-
 	userResponse := profile.ToResponse(&user.User)
 	userResponse.LastLogin = now.Format(time.RFC3339)
 
 	return &AuthResponse{
 		UserResponse: *userResponse,
 		AccessToken:  token,
-		Exp:          now.Add(24 * time.Hour).Unix(),
+		Exp:          now.Add(ttl).Unix(),
 	}, nil
 }
 
-func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
+func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*AuthResponse, error) {
 	var user AuthUser
-	if err := s.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
+	if err := s.db.WithContext(ctx).Where("email = ?", req.Email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("invalid credentials")
+			return nil, ErrInvalidCredentials
 		}
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid credentials")
+	if err := helper.Passwords().Verify(user.Password, req.Password); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	// Upgrade hashes from an older algorithm or weaker parameters
+	if helper.Passwords().NeedsRehash(user.Password) {
+		s.rehashPassword(ctx, &user, req.Password)
+	}
+
+	// Users with 2FA get a pending token to exchange via Verify2FA
+	if user.TOTPEnabled {
+		return s.mfaPendingResponse(ctx, &user)
 	}
 
-	// Proceed with generating token and response
+	return s.completeLogin(ctx, &user, s.tokenTTL(req.RememberMe))
+}
+
+// LoginUser logs in a user authenticated by other means, such as an OAuth
+// provider. Users with 2FA get a pending token, as with Login.
+func (s *AuthService) LoginUser(ctx context.Context, user *AuthUser) (*AuthResponse, error) {
+	if user.TOTPEnabled {
+		return s.mfaPendingResponse(ctx, user)
+	}
+	return s.completeLogin(ctx, user, s.tokenTTL(false))
+}
+
+// completeLogin issues an access token valid for ttl once all login factors
+// have been verified
+func (s *AuthService) completeLogin(ctx context.Context, user *AuthUser, ttl time.Duration) (*AuthResponse, error) {
 	now := time.Now()
-	token, err := helper.GenerateJWT(user.User.Id)
+	token, err := helper.GenerateJWTWithTTL(user.User.Id, ttl)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -171,13 +287,13 @@ func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
 	response := &AuthResponse{
 		UserResponse: *userResponse,
 		AccessToken:  token,
-		Exp:          now.Add(24 * time.Hour).Unix(),
+		Exp:          now.Add(ttl).Unix(),
 	}
 
 	// Prepare the login event
 	loginAllowed := true
 	event := LoginEvent{
-		User:         &user,
+		User:         user,
 		LoginAllowed: &loginAllowed,
 		Response:     response,
 	}
@@ -188,27 +304,40 @@ func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
 	// Check if login was allowed after event listeners have processed it
 	if !loginAllowed {
 		if event.Error != nil {
-			return event.Response, errors.New(event.Error.Error)
+			return event.Response, fmt.Errorf("%w: %s", ErrLoginDenied, event.Error.Error)
 		}
-		return event.Response, errors.New("not authorized")
+		return event.Response, ErrLoginDenied
 	}
 
-	// Update last login with proper time handling
-	if err := s.db.Model(&user).Update("last_login", sql.NullTime{
-		Time:  now,
-		Valid: true,
-	}).Error; err != nil {
+	// Update last login with proper time handling. Signing in during an
+	// account deletion grace period cancels the deletion.
+	updates := map[string]any{"last_login": sql.NullTime{Time: now, Valid: true}}
+	if user.DeletionScheduledAt != nil {
+		updates["deletion_scheduled_at"] = nil
+	}
+	if err := s.db.WithContext(ctx).Model(user).Updates(updates).Error; err != nil {
 		return nil, fmt.Errorf("failed to update last login: %w", err)
 	}
 
+	if user.DeletionScheduledAt != nil {
+		user.DeletionScheduledAt = nil
+		s.emitter.Emit("user.deletion_cancelled", types.UserData{
+			Id:        user.Id,
+			FirstName: user.User.FirstName,
+			LastName:  user.User.LastName,
+			Username:  user.Username,
+			Email:     user.Email,
+		})
+	}
+
 	return response, nil
 }
 
-func (s *AuthService) ForgotPassword(email string) error {
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
 	var user AuthUser
-	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := s.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("user not found: %w", err)
+			return ErrUserNotFound
 		}
 		return fmt.Errorf("database error: %w", err)
 	}
@@ -220,23 +349,19 @@ func (s *AuthService) ForgotPassword(email string) error {
 	expiry := time.Now().Add(15 * time.Minute)
 
 	// Update reset token fields in transaction
-	tx := s.db.Begin()
-	if tx.Error != nil {
-		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
-	}
-
-	updates := map[string]any{
-		"reset_token":        token,
-		"reset_token_expiry": sql.NullTime{Time: expiry, Valid: true},
-	}
-
-	if err := tx.Model(&user).Updates(updates).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to save reset token: %w", err)
-	}
+	err = database.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+		updates := map[string]any{
+			"reset_token":        token,
+			"reset_token_expiry": sql.NullTime{Time: expiry, Valid: true},
+		}
 
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to save reset token: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	if err := s.sendPasswordResetEmail(&user, token); err != nil {
@@ -246,59 +371,123 @@ func (s *AuthService) ForgotPassword(email string) error {
 	return nil
 }
 
-func (s *AuthService) ResetPassword(email, token, newPassword string) error {
+func (s *AuthService) ResetPassword(ctx context.Context, email, token, newPassword string) error {
 	var user AuthUser
-	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := s.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("user not found: %w", err)
+			return ErrUserNotFound
 		}
 		return fmt.Errorf("database error: %w", err)
 	}
 
 	if user.ResetToken != token {
-		return errors.New("invalid token")
+		return ErrInvalidToken
 	}
 
 	if user.ResetTokenExpiry == nil || time.Now().After(*user.ResetTokenExpiry) {
-		return errors.New("token expired")
+		return ErrTokenExpired
+	}
+
+	if err := helper.ValidatePassword(newPassword); err != nil {
+		return err
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	hashedPassword, err := helper.Passwords().Hash(newPassword)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	// Update password and clear reset token in transaction
-	tx := s.db.Begin()
-	if tx.Error != nil {
-		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
-	}
+	err = database.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+		// Bumping the token version signs out every existing session
+		updates := map[string]any{
+			"password":           hashedPassword,
+			"reset_token":        "",
+			"reset_token_expiry": nil,
+			"token_version":      gorm.Expr("token_version + 1"),
+		}
 
-	updates := map[string]any{
-		"password":           string(hashedPassword),
-		"reset_token":        "",
-		"reset_token_expiry": nil,
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	if err := tx.Model(&user).Updates(updates).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to update password: %w", err)
+	if s.emitter != nil {
+		s.emitter.Emit("user.password_changed", types.UserData{
+			Id:        user.Id,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Username:  user.Username,
+			Email:     user.Email,
+		})
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	// Send confirmation email in the background
+	if err := jobs.Enqueue(PasswordChangedEmailJob, emailJobPayload{Email: user.Email, FirstName: user.FirstName}); err != nil {
+		logger.FromContext(ctx).Error("Failed to enqueue password changed email", logger.String("error", err.Error()))
 	}
 
-	// Send confirmation email asynchronously
-	go func() {
-		if err := s.sendPasswordChangedEmail(&user); err != nil {
-			fmt.Printf("Failed to send password changed email: %v\n", err)
+	return nil
+}
+
+// removeUserData clears the credentials kept for a user once profile has
+// deleted the account; RemoveUserDataJob runs it for user.deleted
+func (s *AuthService) removeUserData(ctx context.Context, userId uint) error {
+	return database.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&RecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
 		}
-	}()
+		err := tx.Unscoped().Model(&AuthUser{}).Where("id = ?", userId).Updates(map[string]any{
+			"reset_token":        "",
+			"reset_token_expiry": nil,
+			"totp_secret":        "",
+			"totp_enabled":       false,
+			"mfa_challenge":      "",
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to clear credentials: %w", err)
+		}
+		return nil
+	})
+}
 
+// CleanupExpiredResetTokens clears password reset tokens past their expiry
+func (s *AuthService) CleanupExpiredResetTokens(ctx context.Context) error {
+	result := s.db.WithContext(ctx).Model(&AuthUser{}).
+		Where("reset_token_expiry < ?", time.Now()).
+		Updates(map[string]any{
+			"reset_token":        "",
+			"reset_token_expiry": nil,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to clear expired reset tokens: %w", result.Error)
+	}
 	return nil
 }
 
+// rehashPassword stores a fresh hash of the verified password. Failures are
+// only logged since the login itself already succeeded.
+func (s *AuthService) rehashPassword(ctx context.Context, user *AuthUser, password string) {
+	hashedPassword, err := helper.Passwords().Hash(password)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to rehash password",
+			logger.Uint("user_id", user.Id),
+			logger.String("error", err.Error()))
+		return
+	}
+	if err := s.db.WithContext(ctx).Model(&AuthUser{}).Where("id = ?", user.Id).Update("password", hashedPassword).Error; err != nil {
+		logger.FromContext(ctx).Error("Failed to save rehashed password",
+			logger.Uint("user_id", user.Id),
+			logger.String("error", err.Error()))
+		return
+	}
+	user.Password = hashedPassword
+}
+
 func generateToken() (string, error) {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
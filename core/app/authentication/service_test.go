@@ -0,0 +1,253 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/app/profile"
+	"base/core/config"
+	"base/core/emitter"
+	"base/core/events"
+	"base/core/helper"
+	"base/core/jobs"
+	"base/core/storage"
+	"base/core/types"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestService(t *testing.T, cfg *config.Config) *AuthService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "auth.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&storage.Attachment{}, &AuthUser{}, &RecoveryCode{}, &events.OutboxEvent{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	types.SetKeySet(types.NewHMACKeySet("test", "test-secret"))
+	t.Cleanup(func() { types.SetKeySet(nil) })
+	return NewAuthService(db, nil, emitter.New(), cfg)
+}
+
+func createUser(t *testing.T, s *AuthService, user *AuthUser) *AuthUser {
+	t.Helper()
+	if user.Username == "" {
+		user.Username = "user" + time.Now().Format("150405.000000000")
+	}
+	if user.Email == "" {
+		user.Email = user.Username + "@example.com"
+	}
+	if err := s.db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func TestRemoveUserDataJob(t *testing.T) {
+	s := newTestService(t, nil)
+	expiry := time.Now().Add(time.Hour)
+	user := createUser(t, s, &AuthUser{
+		User:             profile.User{FirstName: "Ada", LastName: "Lovelace"},
+		ResetToken:       "reset-token",
+		ResetTokenExpiry: &expiry,
+		TOTPSecret:       "encrypted-secret",
+		TOTPEnabled:      true,
+	})
+	if err := s.db.Create(&RecoveryCode{UserId: user.Id, CodeHash: hashRecoveryCode("abcde-12345")}).Error; err != nil {
+		t.Fatalf("create recovery code: %v", err)
+	}
+
+	job := &jobs.Job{Name: RemoveUserDataJob, Payload: fmt.Sprintf(`{"user_id": %d}`, user.Id)}
+	if err := s.removeUserDataJob(context.Background(), job); err != nil {
+		t.Fatalf("removeUserDataJob: %v", err)
+	}
+
+	var stored AuthUser
+	s.db.Unscoped().First(&stored, user.Id)
+	if stored.ResetToken != "" || stored.ResetTokenExpiry != nil || stored.TOTPSecret != "" || stored.TOTPEnabled {
+		t.Errorf("credentials kept after cleanup: %+v", stored)
+	}
+	var codes int64
+	s.db.Model(&RecoveryCode{}).Where("user_id = ?", user.Id).Count(&codes)
+	if codes != 0 {
+		t.Errorf("%d recovery codes kept after cleanup", codes)
+	}
+}
+
+func TestRemoveUserDataReturnsErrors(t *testing.T) {
+	s := newTestService(t, nil)
+	if err := s.db.Migrator().DropTable(&RecoveryCode{}); err != nil {
+		t.Fatalf("drop table: %v", err)
+	}
+
+	// The job queue retries the cleanup when it fails
+	if err := s.removeUserData(context.Background(), 1); err == nil {
+		t.Error("removeUserData hid a database error")
+	}
+}
+
+func TestRegisterAfterSoftDelete(t *testing.T) {
+	req := func() *RegisterRequest {
+		return &RegisterRequest{
+			FirstName: "Ada",
+			LastName:  "Lovelace",
+			Username:  "ada",
+			Email:     "ada@example.com",
+			Password:  "correct-horse-battery",
+		}
+	}
+
+	for _, release := range []bool{true, false} {
+		t.Run(fmt.Sprintf("release=%t", release), func(t *testing.T) {
+			s := newTestService(t, &config.Config{AuthReleaseDeletedUnique: release, AccessTokenTTL: time.Hour})
+			ctx := context.Background()
+
+			first, err := s.Register(ctx, req())
+			if err != nil {
+				t.Fatalf("Register: %v", err)
+			}
+			if err := s.db.Delete(&AuthUser{}, first.Id).Error; err != nil {
+				t.Fatalf("soft delete: %v", err)
+			}
+
+			second, err := s.Register(ctx, req())
+			if !release {
+				if !errors.Is(err, ErrUserExists) {
+					t.Fatalf("Register = %v, want ErrUserExists while deleted users keep their values", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Register after soft delete: %v", err)
+			}
+			if second.Id == first.Id {
+				t.Fatal("re-registration reused the deleted user")
+			}
+
+			var deleted AuthUser
+			s.db.Unscoped().First(&deleted, first.Id)
+			if deleted.Email == "ada@example.com" || deleted.Username == "ada" {
+				t.Errorf("deleted user still holds its values: %q, %q", deleted.Email, deleted.Username)
+			}
+			if deleted.Phone != "" {
+				t.Errorf("empty phone was rewritten to %q", deleted.Phone)
+			}
+		})
+	}
+}
+
+func TestLoginRehashesPassword(t *testing.T) {
+	s := newTestService(t, &config.Config{AccessTokenTTL: time.Hour})
+	oldHash, err := helper.NewBcryptHasher(bcrypt.MinCost).Hash("correct-horse-battery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := createUser(t, s, &AuthUser{User: profile.User{Email: "ada@example.com", Password: oldHash}})
+
+	helper.SetPasswords(helper.NewArgon2idHasher())
+	t.Cleanup(func() { helper.SetPasswords(nil) })
+
+	if _, err := s.Login(context.Background(), &LoginRequest{Email: user.Email, Password: "wrong"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login with a wrong password = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := s.Login(context.Background(), &LoginRequest{Email: user.Email, Password: "correct-horse-battery"}); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	var stored AuthUser
+	s.db.First(&stored, user.Id)
+	if stored.Password == oldHash || !strings.HasPrefix(stored.Password, "$argon2id$") {
+		t.Errorf("password hash = %q, want an argon2id hash", stored.Password)
+	}
+	if helper.Passwords().Verify(stored.Password, "correct-horse-battery") != nil {
+		t.Error("rehashed password does not verify")
+	}
+}
+
+func TestCleanupExpiredResetTokens(t *testing.T) {
+	s := newTestService(t, nil)
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	expired := createUser(t, s, &AuthUser{ResetToken: "expired", ResetTokenExpiry: &past})
+	valid := createUser(t, s, &AuthUser{ResetToken: "valid", ResetTokenExpiry: &future})
+
+	if err := s.CleanupExpiredResetTokens(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var cleared, kept AuthUser
+	s.db.First(&cleared, expired.Id)
+	if cleared.ResetToken != "" || cleared.ResetTokenExpiry != nil {
+		t.Errorf("expired token kept: %q %v", cleared.ResetToken, cleared.ResetTokenExpiry)
+	}
+	s.db.First(&kept, valid.Id)
+	if kept.ResetToken != "valid" || kept.ResetTokenExpiry == nil {
+		t.Errorf("unexpired token cleared: %q %v", kept.ResetToken, kept.ResetTokenExpiry)
+	}
+}
+
+func TestTokenLifetimes(t *testing.T) {
+	s := newTestService(t, &config.Config{AccessTokenTTL: 15 * time.Minute, RememberMeTTL: 30 * 24 * time.Hour})
+
+	// expiresIn checks the token's exp claim matches Exp and returns how far
+	// off it is
+	expiresIn := func(resp *AuthResponse) time.Duration {
+		t.Helper()
+		token, err := types.ActiveKeySet().Parse(resp.AccessToken)
+		if err != nil {
+			t.Fatalf("parse token: %v", err)
+		}
+		exp, err := token.Claims.GetExpirationTime()
+		if err != nil || exp == nil {
+			t.Fatalf("exp claim: %v", err)
+		}
+		if exp.Unix() != resp.Exp {
+			t.Errorf("exp claim %d differs from the response's %d", exp.Unix(), resp.Exp)
+		}
+		return time.Until(exp.Time)
+	}
+	near := func(got, want time.Duration) bool {
+		return got > want-time.Minute && got <= want
+	}
+
+	registered, err := s.Register(context.Background(), &RegisterRequest{
+		FirstName: "Ada",
+		LastName:  "Lovelace",
+		Username:  "ada",
+		Email:     "ada@example.com",
+		Password:  "correct-horse-battery",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := expiresIn(registered); !near(got, 15*time.Minute) {
+		t.Errorf("registration token expires in %v, want ACCESS_TOKEN_TTL", got)
+	}
+
+	login := &LoginRequest{Email: "ada@example.com", Password: "correct-horse-battery"}
+	resp, err := s.Login(context.Background(), login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := expiresIn(resp); !near(got, 15*time.Minute) {
+		t.Errorf("login token expires in %v, want ACCESS_TOKEN_TTL", got)
+	}
+
+	login.RememberMe = true
+	if resp, err = s.Login(context.Background(), login); err != nil {
+		t.Fatal(err)
+	}
+	if got := expiresIn(resp); !near(got, 30*24*time.Hour) {
+		t.Errorf("remember_me token expires in %v, want REMEMBER_ME_TTL", got)
+	}
+}
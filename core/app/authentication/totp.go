@@ -0,0 +1,406 @@
+package authentication
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"base/core/types"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer        = "Base"
+	mfaPendingTTL     = 5 * time.Minute
+	recoveryCodeCount = 10
+
+	// maxMFAAttempts is how many wrong codes an MFA pending token takes
+	// before it stops working and the user has to log in again
+	maxMFAAttempts = 5
+)
+
+// totpValidateOpts is a 30s window with ±1 step of clock skew
+var totpValidateOpts = totp.ValidateOpts{
+	Period:    30,
+	Skew:      1,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// EnableTOTP generates a new TOTP secret for the user. 2FA stays inactive
+// until the secret is confirmed with a valid code via ConfirmTOTP.
+func (s *AuthService) EnableTOTP(ctx context.Context, userID uint) (string, string, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if user.TOTPEnabled {
+		return "", "", ErrTOTPAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      totpIssuer,
+		AccountName: user.Email,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+
+	encrypted, err := s.encryptSecret(key.Secret())
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.db.WithContext(ctx).Model(&AuthUser{}).Where("id = ?", userID).Update("totp_secret", encrypted).Error; err != nil {
+		return "", "", fmt.Errorf("failed to save totp secret: %w", err)
+	}
+
+	return key.Secret(), key.URL(), nil
+}
+
+// ConfirmTOTP activates 2FA once the user proves their authenticator works,
+// and returns a fresh set of single-use recovery codes
+func (s *AuthService) ConfirmTOTP(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTOTPNotPending
+	}
+	step, err := s.validateTOTP(user.TOTPSecret, code, user.TOTPLastStep)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := useTOTPStep(tx, userID, step, map[string]any{"totp_enabled": true}); err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP turns 2FA off after verifying a current code
+func (s *AuthService) DisableTOTP(ctx context.Context, userID uint, code string) error {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+	step, err := s.validateTOTP(user.TOTPSecret, code, user.TOTPLastStep)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := useTOTPStep(tx, userID, step, map[string]any{
+			"totp_enabled":        false,
+			"totp_secret":         "",
+			"mfa_challenge":       "",
+			"mfa_failed_attempts": 0,
+		}); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		return nil
+	})
+}
+
+// Verify2FA completes a login started by Login for a user with 2FA enabled.
+// The code may be a TOTP code or one of the user's unused recovery codes.
+// Each MFA pending token is accepted once and stops working after
+// maxMFAAttempts wrong codes.
+func (s *AuthService) Verify2FA(ctx context.Context, mfaToken, code string, rememberMe bool) (*AuthResponse, error) {
+	userID, challenge, err := types.ValidateMFAPendingJWT(mfaToken)
+	if err != nil || challenge == "" {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTOTPNotEnabled
+	}
+	hash := hashChallenge(challenge)
+	if user.MFAChallenge == "" || subtle.ConstantTimeCompare([]byte(user.MFAChallenge), []byte(hash)) != 1 {
+		return nil, ErrInvalidToken
+	}
+
+	step, err := s.validateTOTP(user.TOTPSecret, code, user.TOTPLastStep)
+	switch {
+	case err == nil:
+		err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := useChallenge(tx, userID, hash); err != nil {
+				return err
+			}
+			return useTOTPStep(tx, userID, step, nil)
+		})
+	case errors.Is(err, ErrInvalidTOTPCode):
+		err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := useChallenge(tx, userID, hash); err != nil {
+				return err
+			}
+			return useRecoveryCode(tx, userID, code)
+		})
+	}
+	if errors.Is(err, ErrInvalidTOTPCode) {
+		if err := s.recordMFAFailure(ctx, userID, hash); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidTOTPCode
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, user, s.tokenTTL(rememberMe))
+}
+
+// mfaPendingResponse builds the login response for a user who still has to
+// pass 2FA, replacing any earlier challenge so older pending tokens stop working
+func (s *AuthService) mfaPendingResponse(ctx context.Context, user *AuthUser) (*AuthResponse, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	challenge := hex.EncodeToString(b)
+
+	if err := s.db.WithContext(ctx).Model(&AuthUser{}).Where("id = ?", user.Id).Updates(map[string]any{
+		"mfa_challenge":       hashChallenge(challenge),
+		"mfa_failed_attempts": 0,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to save challenge: %w", err)
+	}
+
+	token, err := types.GenerateMFAPendingJWT(user.Id, challenge, mfaPendingTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	return &AuthResponse{
+		MFARequired: true,
+		MFAToken:    token,
+		Exp:         time.Now().Add(mfaPendingTTL).Unix(),
+	}, nil
+}
+
+// useChallenge consumes the user's MFA challenge, failing with
+// ErrInvalidToken when a concurrent request already used it
+func useChallenge(tx *gorm.DB, userID uint, hash string) error {
+	result := tx.Model(&AuthUser{}).
+		Where("id = ? AND mfa_challenge = ?", userID, hash).
+		Updates(map[string]any{"mfa_challenge": "", "mfa_failed_attempts": 0})
+	if result.Error != nil {
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// useTOTPStep records step as the user's last accepted TOTP step along with
+// updates, failing with ErrInvalidTOTPCode when a concurrent request already
+// accepted a code from it
+func useTOTPStep(tx *gorm.DB, userID uint, step int64, updates map[string]any) error {
+	values := map[string]any{"totp_last_step": step}
+	for column, value := range updates {
+		values[column] = value
+	}
+	result := tx.Model(&AuthUser{}).Where("id = ? AND totp_last_step < ?", userID, step).Updates(values)
+	if result.Error != nil {
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+// recordMFAFailure counts a wrong code against the user's MFA challenge and
+// drops the challenge once it reaches maxMFAAttempts
+func (s *AuthService) recordMFAFailure(ctx context.Context, userID uint, hash string) error {
+	if err := s.db.WithContext(ctx).Model(&AuthUser{}).
+		Where("id = ? AND mfa_challenge = ?", userID, hash).
+		Update("mfa_failed_attempts", gorm.Expr("mfa_failed_attempts + 1")).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if err := s.db.WithContext(ctx).Model(&AuthUser{}).
+		Where("id = ? AND mfa_challenge = ? AND mfa_failed_attempts >= ?", userID, hash, maxMFAAttempts).
+		Update("mfa_challenge", "").Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// hashChallenge hashes an MFA challenge for storage
+func hashChallenge(challenge string) string {
+	sum := sha256.Sum256([]byte(challenge))
+	return hex.EncodeToString(sum[:])
+}
+
+// useRecoveryCode marks a matching unused recovery code as used
+func useRecoveryCode(tx *gorm.DB, userID uint, code string) error {
+	now := time.Now()
+	result := tx.Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashRecoveryCode(code)).
+		Update("used_at", &now)
+	if result.Error != nil {
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+func (s *AuthService) findUser(ctx context.Context, userID uint) (*AuthUser, error) {
+	var user AuthUser
+	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &user, nil
+}
+
+// replaceRecoveryCodes deletes the user's existing codes and stores new hashed ones
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	records := make([]RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := hex.EncodeToString(b)
+		codes[i] = raw[:5] + "-" + raw[5:]
+		records[i] = RecoveryCode{UserId: userID, CodeHash: hashRecoveryCode(codes[i])}
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode normalizes and hashes a recovery code; the codes are random
+// enough that a fast hash is sufficient and allows direct lookup
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// validateTOTP checks a code against the user's encrypted secret and returns
+// the time step it belongs to. Codes from lastStep or earlier were already
+// used, so they fail like wrong ones.
+func (s *AuthService) validateTOTP(encryptedSecret, code string, lastStep int64) (int64, error) {
+	secret, err := s.decryptSecret(encryptedSecret)
+	if err != nil {
+		return 0, err
+	}
+
+	code = strings.TrimSpace(code)
+	period := int64(totpValidateOpts.Period)
+	skew := int64(totpValidateOpts.Skew)
+	current := time.Now().Unix() / period
+	for step := current - skew; step <= current+skew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*period, 0), totpValidateOpts)
+		if err != nil {
+			return 0, ErrInvalidTOTPCode
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, nil
+		}
+	}
+	return 0, ErrInvalidTOTPCode
+}
+
+// mfaKey derives the AES-256 key used to encrypt TOTP secrets at rest
+func mfaKey(secret string) []byte {
+	if secret == "" {
+		return nil
+	}
+	sum := sha256.Sum256([]byte("totp:" + secret))
+	return sum[:]
+}
+
+func (s *AuthService) encryptSecret(secret string) (string, error) {
+	gcm, err := s.newGCM()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *AuthService) decryptSecret(encrypted string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode totp secret: %w", err)
+	}
+	gcm, err := s.newGCM()
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("invalid totp secret")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+	return string(plain), nil
+}
+
+func (s *AuthService) newGCM() (cipher.AEAD, error) {
+	if s.mfaKey == nil {
+		return nil, ErrMFAUnavailable
+	}
+	block, err := aes.NewCipher(s.mfaKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,216 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/app/profile"
+	"base/core/config"
+	"base/core/helper"
+
+	"github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const totpPassword = "correct-horse-battery"
+
+// newTOTPService returns a service that can encrypt TOTP secrets and a user
+// who signs in with totpPassword
+func newTOTPService(t *testing.T) (*AuthService, *AuthUser) {
+	t.Helper()
+	s := newTestService(t, &config.Config{MFAEncryptionKey: "mfa-key", AccessTokenTTL: time.Hour})
+	hash, err := helper.NewBcryptHasher(bcrypt.MinCost).Hash(totpPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, createUser(t, s, &AuthUser{User: profile.User{Password: hash}})
+}
+
+// totpCode returns the code for the time step offset steps from now
+func totpCode(t *testing.T, secret string, offset int) string {
+	t.Helper()
+	at := time.Now().Add(time.Duration(offset) * time.Duration(totpValidateOpts.Period) * time.Second)
+	code, err := totp.GenerateCodeCustom(secret, at, totpValidateOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// enableTOTP sets up and confirms 2FA with the current code, returning the
+// secret and recovery codes
+func enableTOTP(t *testing.T, s *AuthService, user *AuthUser) (string, []string) {
+	t.Helper()
+	secret, _, err := s.EnableTOTP(context.Background(), user.Id)
+	if err != nil {
+		t.Fatalf("EnableTOTP: %v", err)
+	}
+	codes, err := s.ConfirmTOTP(context.Background(), user.Id, totpCode(t, secret, 0))
+	if err != nil {
+		t.Fatalf("ConfirmTOTP: %v", err)
+	}
+	return secret, codes
+}
+
+// mfaToken logs the user in and returns the pending token
+func mfaToken(t *testing.T, s *AuthService, user *AuthUser) string {
+	t.Helper()
+	resp, err := s.Login(context.Background(), &LoginRequest{Email: user.Email, Password: totpPassword})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if !resp.MFARequired || resp.MFAToken == "" || resp.AccessToken != "" {
+		t.Fatalf("Login with 2FA = %+v, want only an MFA token", resp)
+	}
+	return resp.MFAToken
+}
+
+func TestTOTPSecretEncryption(t *testing.T) {
+	s, _ := newTOTPService(t)
+
+	first, err := s.encryptSecret("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.encryptSecret("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second || strings.Contains(first, "JBSWY3DPEHPK3PXP") {
+		t.Errorf("ciphertexts %q and %q reuse a nonce or leak the secret", first, second)
+	}
+	if plain, err := s.decryptSecret(first); err != nil || plain != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("decryptSecret = %q, %v", plain, err)
+	}
+
+	other := newTestService(t, &config.Config{MFAEncryptionKey: "another-key"})
+	if _, err := other.decryptSecret(first); err == nil {
+		t.Error("a secret decrypted with another key")
+	}
+	unconfigured := newTestService(t, nil)
+	if _, err := unconfigured.encryptSecret("JBSWY3DPEHPK3PXP"); !errors.Is(err, ErrMFAUnavailable) {
+		t.Errorf("encryptSecret without MFA_ENCRYPTION_KEY = %v, want ErrMFAUnavailable", err)
+	}
+}
+
+func TestTOTPLifecycle(t *testing.T) {
+	s, user := newTOTPService(t)
+	ctx := context.Background()
+
+	secret, url, err := s.EnableTOTP(ctx, user.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(url, "otpauth://totp/") || !strings.Contains(url, "secret="+secret) {
+		t.Errorf("otpauth URL = %q", url)
+	}
+	var stored AuthUser
+	s.db.First(&stored, user.Id)
+	if stored.TOTPEnabled || stored.TOTPSecret == "" || stored.TOTPSecret == secret {
+		t.Fatalf("after EnableTOTP: enabled %v, stored secret %q", stored.TOTPEnabled, stored.TOTPSecret)
+	}
+
+	// Logging in before the secret is confirmed needs no second factor
+	resp, err := s.Login(ctx, &LoginRequest{Email: user.Email, Password: totpPassword})
+	if err != nil || resp.MFARequired || resp.AccessToken == "" {
+		t.Fatalf("Login before confirming = %+v, %v", resp, err)
+	}
+
+	if _, err := s.ConfirmTOTP(ctx, user.Id, "000000"); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Errorf("ConfirmTOTP with a wrong code = %v, want ErrInvalidTOTPCode", err)
+	}
+	confirmed := totpCode(t, secret, 0)
+	codes, err := s.ConfirmTOTP(ctx, user.Id, confirmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Errorf("%d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+	if _, _, err := s.EnableTOTP(ctx, user.Id); !errors.Is(err, ErrTOTPAlreadyEnabled) {
+		t.Errorf("EnableTOTP when enabled = %v, want ErrTOTPAlreadyEnabled", err)
+	}
+
+	// The code used to confirm can't be replayed to finish a login
+	token := mfaToken(t, s, user)
+	if _, err := s.Verify2FA(ctx, token, confirmed, false); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Errorf("Verify2FA with a replayed code = %v, want ErrInvalidTOTPCode", err)
+	}
+	next := totpCode(t, secret, 1)
+	resp, err = s.Verify2FA(ctx, token, next, false)
+	if err != nil || resp.AccessToken == "" {
+		t.Fatalf("Verify2FA = %+v, %v", resp, err)
+	}
+	if _, err := s.Verify2FA(ctx, token, totpCode(t, secret, 1), false); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify2FA reusing the MFA token = %v, want ErrInvalidToken", err)
+	}
+	if _, err := s.Verify2FA(ctx, mfaToken(t, s, user), next, false); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Errorf("Verify2FA replaying a code on a new login = %v, want ErrInvalidTOTPCode", err)
+	}
+
+	if err := s.DisableTOTP(ctx, user.Id, next); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Errorf("DisableTOTP with a used code = %v, want ErrInvalidTOTPCode", err)
+	}
+	// Once a later time step starts its code disables 2FA
+	s.db.Model(&AuthUser{}).Where("id = ?", user.Id).Update("totp_last_step", 0)
+	if err := s.DisableTOTP(ctx, user.Id, totpCode(t, secret, 0)); err != nil {
+		t.Fatalf("DisableTOTP: %v", err)
+	}
+	s.db.First(&stored, user.Id)
+	var remaining int64
+	s.db.Model(&RecoveryCode{}).Where("user_id = ?", user.Id).Count(&remaining)
+	if stored.TOTPEnabled || stored.TOTPSecret != "" || remaining != 0 {
+		t.Errorf("after DisableTOTP: enabled %v, secret %q, %d recovery codes", stored.TOTPEnabled, stored.TOTPSecret, remaining)
+	}
+	if resp, err := s.Login(ctx, &LoginRequest{Email: user.Email, Password: totpPassword}); err != nil || resp.MFARequired {
+		t.Errorf("Login after disabling = %+v, %v", resp, err)
+	}
+}
+
+func TestRecoveryCodesAreSingleUse(t *testing.T) {
+	s, user := newTOTPService(t)
+	ctx := context.Background()
+	_, codes := enableTOTP(t, s, user)
+
+	if resp, err := s.Verify2FA(ctx, mfaToken(t, s, user), codes[0], false); err != nil || resp.AccessToken == "" {
+		t.Fatalf("Verify2FA with a recovery code = %+v, %v", resp, err)
+	}
+	if _, err := s.Verify2FA(ctx, mfaToken(t, s, user), codes[0], false); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Errorf("Verify2FA reusing a recovery code = %v, want ErrInvalidTOTPCode", err)
+	}
+
+	// Codes are matched without the dash and regardless of case
+	typed := strings.ToUpper(strings.ReplaceAll(codes[1], "-", ""))
+	if _, err := s.Verify2FA(ctx, mfaToken(t, s, user), typed, false); err != nil {
+		t.Errorf("Verify2FA with %q: %v", typed, err)
+	}
+}
+
+func TestVerify2FALimitsAttempts(t *testing.T) {
+	s, user := newTOTPService(t)
+	ctx := context.Background()
+	secret, _ := enableTOTP(t, s, user)
+
+	// A new login replaces the outstanding challenge
+	stale := mfaToken(t, s, user)
+	token := mfaToken(t, s, user)
+	if _, err := s.Verify2FA(ctx, stale, totpCode(t, secret, 1), false); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify2FA with a superseded token = %v, want ErrInvalidToken", err)
+	}
+
+	for i := 0; i < maxMFAAttempts; i++ {
+		if _, err := s.Verify2FA(ctx, token, "000000", false); !errors.Is(err, ErrInvalidTOTPCode) {
+			t.Fatalf("attempt %d = %v, want ErrInvalidTOTPCode", i+1, err)
+		}
+	}
+	if _, err := s.Verify2FA(ctx, token, totpCode(t, secret, 1), false); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify2FA after %d wrong codes = %v, want ErrInvalidToken", maxMFAAttempts, err)
+	}
+
+	if _, err := s.Verify2FA(ctx, mfaToken(t, s, user), totpCode(t, secret, 1), false); err != nil {
+		t.Errorf("Verify2FA after logging in again: %v", err)
+	}
+}
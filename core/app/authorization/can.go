@@ -56,8 +56,11 @@ func Can(action, resourceType string) router.MiddlewareFunc {
 			normalizedAction := strings.ToLower(action)
 
 			// Check if the user has permission to perform the action on the resource type
-			hasPermission, err := authorizationService.HasPermission(userId, orgId, normalizedResourceType, normalizedAction)
+			hasPermission, err := authorizationService.HasPermission(c.Context(), userId, orgId, normalizedResourceType, normalizedAction)
 			if err != nil {
+				if c.Canceled(err) {
+					return nil
+				}
 				c.AbortWithStatusJSON(http.StatusInternalServerError, map[string]any{
 					"error": fmt.Sprintf("error checking permission: %v", err),
 				})
@@ -130,8 +133,11 @@ func CanAccess(action, resourceType, resourceIdParam string) router.MiddlewareFu
 			normalizedAction := strings.ToLower(action)
 
 			// Check if the user has permission to access the specific resource
-			hasPermission, err := authorizationService.HasResourcePermission(userId, orgId, normalizedResourceType, resourceId, normalizedAction)
+			hasPermission, err := authorizationService.HasResourcePermission(c.Context(), userId, orgId, normalizedResourceType, resourceId, normalizedAction)
 			if err != nil {
+				if c.Canceled(err) {
+					return nil
+				}
 				c.AbortWithStatusJSON(http.StatusInternalServerError, map[string]any{
 					"error": fmt.Sprintf("error checking resource permission: %v", err),
 				})
@@ -191,8 +197,11 @@ func HasRole(roleName string) router.MiddlewareFunc {
 			}
 
 			// Check if user has the required role by checking role permissions
-			hasPermission, err := authorizationService.HasPermission(userId, orgId, "role", "read")
+			hasPermission, err := authorizationService.HasPermission(c.Context(), userId, orgId, "role", "read")
 			if err != nil {
+				if c.Canceled(err) {
+					return nil
+				}
 				c.AbortWithStatusJSON(http.StatusInternalServerError, map[string]any{
 					"error": fmt.Sprintf("error checking role permission: %v", err),
 				})
@@ -261,8 +270,11 @@ func CanAny(permissions []string) router.MiddlewareFunc {
 				action := strings.ToLower(strings.TrimSpace(parts[0]))
 				resourceType := strings.ToLower(strings.TrimSpace(parts[1]))
 
-				hasPermission, err := authorizationService.HasPermission(userId, orgId, resourceType, action)
+				hasPermission, err := authorizationService.HasPermission(c.Context(), userId, orgId, resourceType, action)
 				if err != nil {
+					if c.Canceled(err) {
+						return nil
+					}
 					continue // Skip on error, try next permission
 				}
 
@@ -333,8 +345,11 @@ func CanAll(permissions []string) router.MiddlewareFunc {
 				action := strings.ToLower(strings.TrimSpace(parts[0]))
 				resourceType := strings.ToLower(strings.TrimSpace(parts[1]))
 
-				hasPermission, err := authorizationService.HasPermission(userId, orgId, resourceType, action)
+				hasPermission, err := authorizationService.HasPermission(c.Context(), userId, orgId, resourceType, action)
 				if err != nil {
+					if c.Canceled(err) {
+						return nil
+					}
 					c.AbortWithStatusJSON(http.StatusInternalServerError, map[string]any{
 						"error": fmt.Sprintf("error checking permission %s: %v", permission, err),
 					})
@@ -2,11 +2,15 @@ package authorization
 
 import (
 	"base/core/logger"
+	"base/core/query"
 	"base/core/router"
+	"base/core/router/middleware"
 	"base/core/types"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 // AuthorizationController handles HTTP requests for authorization
@@ -23,546 +27,416 @@ func NewAuthorizationController(service *AuthorizationService, logger logger.Log
 	}
 }
 
+// CacheTag groups the cached role responses; cache.Invalidate(CacheTag)
+// drops them after changes made outside these routes
+const CacheTag = "authorization.roles"
+
+// cacheTTL bounds how long a cached role response is served
+const cacheTTL = time.Minute
+
+// cacheKey separates cached role responses by organization
+func cacheKey(ctx *router.Context) string {
+	return middleware.CacheKey(ctx) + "|org=" + strconv.FormatUint(ctx.OrgID(), 10)
+}
+
 // Routes registers routes for the authorization controller
-func (c *AuthorizationController) Routes(router *router.RouterGroup) {
+func (c *AuthorizationController) Routes(r *router.RouterGroup) {
 	c.Logger.Info("Setting up authorization routes")
-	authzRoutes := router.Group("/authorization")
+	authzRoutes := r.Group("/authorization")
 	{
 		c.Logger.Info("Registering authorization role management routes")
+		// Role reads are cached per organization; role writes drop them
+		roleRoutes := authzRoutes.Group("", middleware.CacheWithConfig(&middleware.CacheConfig{
+			TTL:     cacheTTL,
+			KeyFunc: cacheKey,
+			Tags:    []string{CacheTag},
+		}))
+
 		// Role management
-		authzRoutes.GET("/roles", c.GetRoles)
-		authzRoutes.GET("/roles/:id", c.GetRole)
-		authzRoutes.POST("/roles", c.CreateRole)
-		authzRoutes.PUT("/roles/:id", c.UpdateRole)
-		authzRoutes.DELETE("/roles/:id", c.DeleteRole)
+		roleRoutes.GET("/roles", c.GetRoles).Doc(router.Doc{
+			Summary:     "List the organization's roles",
+			Description: "Roles of the organization given by the Base-Orgid header, with the system roles; paged with page and limit",
+			Tags:        []string{"Core/Authorization"},
+			Response:    []RoleResponse{},
+		})
+		roleRoutes.GET("/roles/:id", c.GetRole).Doc(router.Doc{
+			Summary:  "Get a role",
+			Tags:     []string{"Core/Authorization"},
+			Response: RoleResponse{},
+		})
+		roleRoutes.POST("/roles", c.CreateRole).Doc(router.Doc{
+			Summary:  "Create a role",
+			Tags:     []string{"Core/Authorization"},
+			Request:  CreateRoleRequest{},
+			Response: RoleResponse{},
+			Status:   http.StatusCreated,
+		})
+		roleRoutes.PUT("/roles/:id", c.UpdateRole).Doc(router.Doc{
+			Summary:     "Update a role",
+			Description: "System roles cannot be modified",
+			Tags:        []string{"Core/Authorization"},
+			Request:     UpdateRoleRequest{},
+			Response:    RoleResponse{},
+		})
+		roleRoutes.DELETE("/roles/:id", c.DeleteRole).Doc(router.Doc{
+			Summary:     "Delete a role",
+			Description: "System roles cannot be deleted",
+			Tags:        []string{"Core/Authorization"},
+			Response:    types.SuccessResponse{},
+		})
 
 		// Role-permission management
-		authzRoutes.GET("/roles/:id/permissions", c.GetRolePermissions)
-		authzRoutes.POST("/roles/:id/permissions", c.AssignPermission)
-		authzRoutes.DELETE("/roles/:id/permissions/:permissionId", c.RevokePermission)
+		roleRoutes.GET("/roles/:id/permissions", c.GetRolePermissions).Doc(router.Doc{
+			Summary:  "List the permissions of a role",
+			Tags:     []string{"Core/Authorization"},
+			Response: []PermissionResponse{},
+		})
+		roleRoutes.POST("/roles/:id/permissions", c.AssignPermission).Doc(router.Doc{
+			Summary:  "Assign a permission to a role",
+			Tags:     []string{"Core/Authorization"},
+			Request:  AssignPermissionRequest{},
+			Response: types.SuccessResponse{},
+		})
+		roleRoutes.DELETE("/roles/:id/permissions/:permissionId", c.RevokePermission).Doc(router.Doc{
+			Summary:  "Revoke a permission from a role",
+			Tags:     []string{"Core/Authorization"},
+			Response: types.SuccessResponse{},
+		})
 
 		// Resource permissions
-		authzRoutes.POST("/resource-permissions", c.CreateResourcePermission)
-		authzRoutes.DELETE("/resource-permissions/:id", c.DeleteResourcePermission)
+		authzRoutes.POST("/resource-permissions", c.CreateResourcePermission).Doc(router.Doc{
+			Summary:     "Create a resource permission",
+			Description: "Overrides a role's permissions on a single resource; organization_id defaults to the Base-Orgid header",
+			Tags:        []string{"Core/Authorization"},
+			Request:     ResourcePermission{},
+			Response:    ResourcePermissionResponse{},
+			Status:      http.StatusCreated,
+		})
+		authzRoutes.DELETE("/resource-permissions/:id", c.DeleteResourcePermission).Doc(router.Doc{
+			Summary:  "Delete a resource permission",
+			Tags:     []string{"Core/Authorization"},
+			Response: types.SuccessResponse{},
+		})
 
 		// Permission checks
-		authzRoutes.POST("/check", c.CheckPermission)
+		authzRoutes.POST("/check", c.CheckPermission).Doc(router.Doc{
+			Summary:     "Check a user's permission",
+			Description: "Checks whether the user may perform the action on the resource type, or on the single resource when resource_id is set",
+			Tags:        []string{"Core/Authorization"},
+			Request:     CheckPermissionRequest{},
+			Response:    CheckPermissionResponse{},
+		})
+		authzRoutes.GET("/me/permissions", c.GetMyPermissions).Doc(router.Doc{
+			Summary:     "Get the current user's permissions",
+			Description: "What the authenticated user may do in the organization given by the Base-Orgid header. Owners get a single \"*\" permission; others get resource_type:action pairs plus grants on individual resources",
+			Tags:        []string{"Core/Authorization"},
+			Response:    EffectivePermissions{},
+		})
 
 	}
 	c.Logger.Info("Authorization routes registered successfully")
 }
 
 // GetRoles returns all roles for an organization
-// @Summary Get all roles for an organization
-// @Description Retrieves all roles associated with a specific organization via Base-Orgid header, you need to provide it in the header
-// @Tags Core/Authorization
-// @Security BearerAuth
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Success 200 {object} object{data=[]Role} "Successful operation"
-// @Failure 400 {object} types.ErrorResponse "Bad request - Missing organization_id"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /authorization/roles [get]
 func (c *AuthorizationController) GetRoles(ctx *router.Context) error {
-	orgIdStr := ctx.GetHeader("Base-Orgid")
-	var orgId uint64
-	if orgIdStr != "" {
-		parsedId, err := strconv.ParseUint(orgIdStr, 10, 64)
-		if err == nil {
-			// Successfully parsed the organization Id
-			orgId = parsedId
-			c.Logger.Info("Fetching roles for organization",
-				logger.String("organization_id", fmt.Sprintf("%d", orgId)))
-		} else {
-			c.Logger.Warn("Invalid organization Id in header",
-				logger.String("Base-Orgid", orgIdStr),
-				logger.String("error", err.Error()))
-		}
-	} else {
-		c.Logger.Info("No organization Id provided, fetching system roles only")
+	// Resolved and membership-checked by the organization context middleware;
+	// 0 returns system roles only
+	orgId := ctx.OrgID()
+
+	pagination, err := types.ParsePagination(ctx)
+	var perr *query.PaginationError
+	if errors.As(err, &perr) {
+		return ctx.Fail(http.StatusBadRequest, "invalid_"+perr.Param, perr.Message)
 	}
+	page, limit := pagination.Page, pagination.Limit
 
-	roles, err := c.Service.GetRoles(orgId)
+	roles, total, err := c.Service.GetRoles(ctx.Context(), orgId, page, limit)
 	if err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+
 		c.Logger.Error("Error getting roles",
 			logger.String("error", err.Error()),
 			logger.String("organization_id", fmt.Sprintf("%d", orgId)))
 
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error: "Failed to retrieve roles",
-		})
+		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to retrieve roles")
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]any{
-		"data": roles,
-	})
+	return ctx.Paginated(roles, page, limit, total)
 }
 
 // GetRole returns a specific role by Id
-// @Summary Get role by Id
-// @Description Retrieves a specific role by its Id
-// @Tags Core/Authorization
-// @Security BearerAuth
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param id path string true "Role Id"
-// @Success 200 {object} object{data=Role} "Successful operation"
-// @Failure 404 {object} types.ErrorResponse "Role not found"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /authorization/roles/{id} [get]
 func (c *AuthorizationController) GetRole(ctx *router.Context) error {
-	roleId := ctx.Param("id")
-	roleIdUint, err := strconv.ParseUint(roleId, 10, 64)
-	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid role Id: " + err.Error(),
-		})
+	roleIdUint, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
 	}
 
-	role, err := c.Service.GetRole(roleIdUint)
+	role, err := c.Service.GetRole(ctx.Context(), roleIdUint)
 	if err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+
 		if err == ErrRoleNotFound {
-			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
-				Error: "Role not found",
-			})
+			return ctx.Fail(http.StatusNotFound, "role_not_found", "Role not found")
 		}
 
 		c.Logger.Error("Error getting role",
 			logger.String("error", err.Error()),
-			logger.String("role_id", roleId))
+			logger.Uint64("role_id", roleIdUint))
 
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error: "Failed to retrieve role",
-		})
+		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to retrieve role")
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]any{
-		"data": role,
-	})
+	return ctx.OK(router.Wrapped(role))
 }
 
 // CreateRole creates a new role
-// @Summary Create a new role
-// @Description Creates a new role with the provided information
-// @Tags Core/Authorization
-// @Security BearerAuth
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param role body Role true "Role object to be created"
-// @Success 201 {object} object{data=Role} "Role created successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid role data"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /authorization/roles [post]
 func (c *AuthorizationController) CreateRole(ctx *router.Context) error {
 	var role Role
-	if err := ctx.ShouldBindJSON(&role); err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid role data: " + err.Error(),
-		})
+	if errs := types.BindAndValidate(ctx, &role); errs != nil {
+		return ctx.JSON(http.StatusBadRequest, errs)
 	}
 
-	if err := c.Service.CreateRole(&role); err != nil {
+	if err := c.Service.CreateRole(ctx.Context(), &role); err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+
 		c.Logger.Error("Error creating role",
 			logger.String("error", err.Error()),
 			logger.String("role_name", role.Name))
 
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error: "Failed to create role: " + err.Error(),
-		})
+		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to create role: "+err.Error())
 	}
 
-	return ctx.JSON(http.StatusCreated, map[string]any{
-		"data": role,
-	})
+	return ctx.Created(router.Wrapped(role))
 }
 
 // UpdateRole updates an existing role
-// @Summary Update a role
-// @Description Updates an existing role with the provided information
-// @Tags Core/Authorization
-// @Security BearerAuth
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param id path string true "Role Id"
-// @Param role body Role true "Updated role object"
-// @Success 200 {object} object{data=Role} "Role updated successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid role data"
-// @Failure 403 {object} types.ErrorResponse "System role cannot be modified"
-// @Failure 404 {object} types.ErrorResponse "Role not found"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /authorization/roles/{id} [put]
 func (c *AuthorizationController) UpdateRole(ctx *router.Context) error {
-	roleId := ctx.Param("id")
-	roleIdInt, err := strconv.ParseUint(roleId, 10, 64)
-	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid role Id: " + err.Error(),
-		})
+	roleIdInt, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
 	}
 
 	var role Role
-	if err := ctx.ShouldBindJSON(&role); err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid role data: " + err.Error(),
-		})
+	if errs := types.BindAndValidate(ctx, &role); errs != nil {
+		return ctx.JSON(http.StatusBadRequest, errs)
 	}
 
 	role.Id = uint(roleIdInt)
 
-	if err := c.Service.UpdateRole(&role); err != nil {
+	if err := c.Service.UpdateRole(ctx.Context(), &role); err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+
 		switch err {
 		case ErrRoleNotFound:
-			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
-				Error: "Role not found",
-			})
+			return ctx.Fail(http.StatusNotFound, "role_not_found", "Role not found")
 		case ErrSystemRoleUnmodifiable:
-			return ctx.JSON(http.StatusForbidden, types.ErrorResponse{
-				Error: "System roles cannot be modified",
-			})
+			return ctx.Fail(http.StatusForbidden, "system_role_unmodifiable", "System roles cannot be modified")
 		}
 
 		c.Logger.Error("Error updating role",
 			logger.String("error", err.Error()),
-			logger.String("role_id", roleId))
+			logger.Uint64("role_id", roleIdInt))
 
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error: "Failed to update role",
-		})
+		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to update role")
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]any{
-		"data": role,
-	})
+	return ctx.OK(router.Wrapped(role))
 }
 
 // DeleteRole deletes a role
-// @Summary Delete a role
-// @Description Deletes a role by its Id
-// @Tags Core/Authorization
-// @Security BearerAuth
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param id path string true "Role Id"
-// @Success 200 {object} object{success=boolean} "Role deleted successfully"
-// @Failure 403 {object} types.ErrorResponse "System role cannot be deleted"
-// @Failure 404 {object} types.ErrorResponse "Role not found"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /authorization/roles/{id} [delete]
 func (c *AuthorizationController) DeleteRole(ctx *router.Context) error {
-	roleId := ctx.Param("id")
-	roleIdUint, err := strconv.ParseUint(roleId, 10, 64)
-	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid role Id: " + err.Error(),
-		})
+	roleIdUint, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
 	}
 
-	if err := c.Service.DeleteRole(roleIdUint); err != nil {
+	if err := c.Service.DeleteRole(ctx.Context(), roleIdUint); err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+
 		switch err {
 		case ErrRoleNotFound:
-			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
-				Error: "Role not found",
-			})
+			return ctx.Fail(http.StatusNotFound, "role_not_found", "Role not found")
 		case ErrSystemRoleUnmodifiable:
-			return ctx.JSON(http.StatusForbidden, types.ErrorResponse{
-				Error: "System roles cannot be deleted",
-			})
+			return ctx.Fail(http.StatusForbidden, "system_role_unmodifiable", "System roles cannot be deleted")
 		}
 
 		c.Logger.Error("Error deleting role",
 			logger.String("error", err.Error()),
-			logger.String("role_id", roleId))
+			logger.Uint64("role_id", roleIdUint))
 
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error: "Failed to delete role",
-		})
+		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to delete role")
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]any{
-		"success": true,
-	})
+	return ctx.OK(nil)
 }
 
 // GetRolePermissions returns all permissions for a role
-// @Summary Get permissions for a role
-// @Description Retrieves all permissions associated with a specific role
-// @Tags Core/Authorization
-// @Security BearerAuth
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param id path string true "Role Id"
-// @Success 200 {object} object{data=[]Permission} "Successful operation"
-// @Failure 404 {object} types.ErrorResponse "Role not found"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /authorization/roles/{id}/permissions [get]
 func (c *AuthorizationController) GetRolePermissions(ctx *router.Context) error {
-	roleId := ctx.Param("id")
-	roleIdUint, err := strconv.ParseUint(roleId, 10, 64)
-	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid role Id: " + err.Error(),
-		})
+	roleIdUint, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
 	}
 
-	permissions, err := c.Service.GetRolePermissions(roleIdUint)
+	permissions, err := c.Service.GetRolePermissions(ctx.Context(), roleIdUint)
 	if err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+
 		if err == ErrRoleNotFound {
-			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
-				Error: "Role not found",
-			})
+			return ctx.Fail(http.StatusNotFound, "role_not_found", "Role not found")
 		}
 
 		c.Logger.Error("Error getting role permissions",
 			logger.String("error", err.Error()),
-			logger.String("role_id", roleId))
+			logger.Uint64("role_id", roleIdUint))
 
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error: "Failed to retrieve permissions",
-		})
+		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to retrieve permissions")
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]any{
-		"data": permissions,
-	})
+	return ctx.OK(router.Wrapped(permissions))
 }
 
 // AssignPermission assigns a permission to a role
-// @Summary Assign permission to role
-// @Description Assigns a permission to a role
-// @Tags Core/Authorization
-// @Security BearerAuth
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param id path string true "Role Id"
-// @Param assignRequest body object{permission_id=string} true "Permission Id to assign"
-// @Success 200 {object} object{success=boolean} "Permission assigned successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid request data"
-// @Failure 404 {object} types.ErrorResponse "Role or permission not found"
-// @Failure 409 {object} types.ErrorResponse "Permission already assigned"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /authorization/roles/{id}/permissions [post]
 func (c *AuthorizationController) AssignPermission(ctx *router.Context) error {
-	roleId := ctx.Param("id")
-	roleIdUint, err := strconv.ParseUint(roleId, 10, 64)
-	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid role Id: " + err.Error(),
-		})
+	roleIdUint, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
 	}
 
-	var request struct {
-		PermissionId string `json:"permission_id" binding:"required"`
-	}
+	var request AssignPermissionRequest
 
-	if err := ctx.ShouldBindJSON(&request); err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid request: " + err.Error(),
-		})
+	if errs := types.BindAndValidate(ctx, &request); errs != nil {
+		return ctx.JSON(http.StatusBadRequest, errs)
 	}
 
 	permissionIdUint, err := strconv.ParseUint(request.PermissionId, 10, 64)
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid permission Id: " + err.Error(),
-		})
+		return ctx.Fail(http.StatusBadRequest, "invalid_request", "Invalid permission Id: "+err.Error())
 	}
 
-	if err := c.Service.AssignPermissionToRole(roleIdUint, permissionIdUint); err != nil {
+	if err := c.Service.AssignPermissionToRole(ctx.Context(), roleIdUint, permissionIdUint); err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+
 		switch err {
 		case ErrRoleNotFound:
-			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
-				Error: "Role not found",
-			})
+			return ctx.Fail(http.StatusNotFound, "role_not_found", "Role not found")
 		case ErrPermissionNotFound:
-			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
-				Error: "Permission not found",
-			})
+			return ctx.Fail(http.StatusNotFound, "permission_not_found", "Permission not found")
 		case ErrDuplicatePermission:
-			return ctx.JSON(http.StatusConflict, types.ErrorResponse{
-				Error: "Permission already assigned to this role",
-			})
+			return ctx.Fail(http.StatusConflict, "duplicate_permission", "Permission already assigned to this role")
 		}
 
 		c.Logger.Error("Error assigning permission",
 			logger.String("error", err.Error()),
-			logger.String("role_id", roleId),
+			logger.Uint64("role_id", roleIdUint),
 			logger.String("permission_id", request.PermissionId))
 
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error: "Failed to assign permission",
-		})
+		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to assign permission")
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]any{
-		"success": true,
-	})
+	return ctx.OK(nil)
 }
 
 // RevokePermission removes a permission from a role
-// @Summary Revoke permission from role
-// @Description Removes a permission from a role
-// @Tags Core/Authorization
-// @Security BearerAuth
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param id path string true "Role Id"
-// @Param permissionId path string true "Permission Id"
-// @Success 200 {object} object{success=boolean} "Permission revoked successfully"
-// @Failure 404 {object} types.ErrorResponse "Role or permission not found"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /authorization/roles/{id}/permissions/{permissionId} [delete]
 func (c *AuthorizationController) RevokePermission(ctx *router.Context) error {
-	roleId := ctx.Param("id")
-	permissionId := ctx.Param("permissionId")
-
-	roleIdUint, err := strconv.ParseUint(roleId, 10, 64)
-	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid role Id: " + err.Error(),
-		})
+	var params struct {
+		RoleId       uint64 `uri:"id"`
+		PermissionId uint64 `uri:"permissionId"`
 	}
-
-	permissionIdUint, err := strconv.ParseUint(permissionId, 10, 64)
-	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid permission Id: " + err.Error(),
-		})
+	if err := ctx.BindURI(&params); err != nil {
+		return ctx.Fail(http.StatusBadRequest, "invalid_request", err.Error())
 	}
 
-	if err := c.Service.RevokePermissionFromRole(roleIdUint, permissionIdUint); err != nil {
+	if err := c.Service.RevokePermissionFromRole(ctx.Context(), params.RoleId, params.PermissionId); err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+
 		switch err {
 		case ErrRoleNotFound:
-			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
-				Error: "Role not found",
-			})
+			return ctx.Fail(http.StatusNotFound, "role_not_found", "Role not found")
 		case ErrPermissionNotFound:
-			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{
-				Error: "Permission not found",
-			})
+			return ctx.Fail(http.StatusNotFound, "permission_not_found", "Permission not found")
 		}
 
 		c.Logger.Error("Error revoking permission",
 			logger.String("error", err.Error()),
-			logger.String("role_id", roleId),
-			logger.String("permission_id", permissionId))
+			logger.Uint64("role_id", params.RoleId),
+			logger.Uint64("permission_id", params.PermissionId))
 
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error: "Failed to revoke permission",
-		})
+		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to revoke permission")
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]any{
-		"success": true,
-	})
+	return ctx.OK(nil)
 }
 
 // CreateResourcePermission creates a resource-specific permission
-// @Summary Create resource permission
-// @Description Creates a resource-specific permission override
-// @Tags Core/Authorization
-// @Security BearerAuth
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param resourcePermission body ResourcePermission true "Resource permission to create"
-// @Success 201 {object} object{data=ResourcePermission} "Resource permission created successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid resource permission data"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /authorization/resource-permissions [post]
 func (c *AuthorizationController) CreateResourcePermission(ctx *router.Context) error {
 	var resourcePermission ResourcePermission
-	if err := ctx.ShouldBindJSON(&resourcePermission); err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid resource permission data: " + err.Error(),
-		})
+	if errs := types.BindAndValidate(ctx, &resourcePermission); errs != nil {
+		return ctx.JSON(http.StatusBadRequest, errs)
 	}
+	if resourcePermission.OrganizationId == 0 {
+		resourcePermission.OrganizationId = uint(ctx.OrgID())
+	}
+
+	if err := c.Service.CreateResourcePermission(ctx.Context(), &resourcePermission); err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
 
-	if err := c.Service.CreateResourcePermission(&resourcePermission); err != nil {
 		c.Logger.Error("Error creating resource permission",
 			logger.String("error", err.Error()),
 			logger.String("resource_type", resourcePermission.ResourceType),
 			logger.String("resource_id", resourcePermission.ResourceId))
 
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error: "Failed to create resource permission",
-		})
+		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to create resource permission")
 	}
 
-	return ctx.JSON(http.StatusCreated, map[string]any{
-		"data": resourcePermission,
-	})
+	return ctx.Created(router.Wrapped(resourcePermission))
 }
 
 // DeleteResourcePermission deletes a resource-specific permission
-// @Summary Delete resource permission
-// @Description Deletes a resource-specific permission override
-// @Tags Core/Authorization
-// @Security BearerAuth
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param id path string true "Resource Permission Id"
-// @Success 200 {object} object{success=boolean} "Resource permission deleted successfully"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /authorization/resource-permissions/{id} [delete]
 func (c *AuthorizationController) DeleteResourcePermission(ctx *router.Context) error {
-	id := ctx.Param("id")
-	idUint, err := strconv.ParseUint(id, 10, 64)
-	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid resource permission Id: " + err.Error(),
-		})
+	idUint, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
 	}
 
-	if err := c.Service.DeleteResourcePermission(idUint); err != nil {
+	if err := c.Service.DeleteResourcePermission(ctx.Context(), idUint); err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+
 		c.Logger.Error("Error deleting resource permission",
 			logger.String("error", err.Error()),
-			logger.String("id", id))
+			logger.Uint64("id", idUint))
 
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error: "Failed to delete resource permission",
-		})
+		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to delete resource permission")
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]any{
-		"success": true,
-	})
+	return ctx.OK(nil)
 }
 
 // CheckPermission checks if a user has a specific permission
-// @Summary Check user permission
-// @Description Checks if a user has permission to perform an action on a resource
-// @Tags Core/Authorization
-// @Security BearerAuth
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param checkRequest body object{user_id=string,organization_id=string,resource_type=string,action=string,resource_id=string} true "Permission check request"
-// @Success 200 {object} object{has_permission=boolean} "Permission check result"
-// @Failure 400 {object} types.ErrorResponse "Invalid request data"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
-// @Router /authorization/check [post]
 func (c *AuthorizationController) CheckPermission(ctx *router.Context) error {
-	var request struct {
-		UserId       uint64 `json:"user_id" binding:"required"`
-		OrgId        uint64 `json:"organization_id" binding:"required"`
-		ResourceType string `json:"resource_type" binding:"required"`
-		Action       string `json:"action" binding:"required"`
-		ResourceId   string `json:"resource_id"`
-	}
+	var request CheckPermissionRequest
 
-	if err := ctx.ShouldBindJSON(&request); err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error: "Invalid request: " + err.Error(),
-		})
+	if errs := types.BindAndValidate(ctx, &request); errs != nil {
+		return ctx.JSON(http.StatusBadRequest, errs)
 	}
 
 	var hasPermission bool
@@ -570,6 +444,7 @@ func (c *AuthorizationController) CheckPermission(ctx *router.Context) error {
 
 	if request.ResourceId != "" {
 		hasPermission, err = c.Service.HasResourcePermission(
+			ctx.Context(),
 			request.UserId,
 			request.OrgId,
 			request.ResourceType,
@@ -578,6 +453,7 @@ func (c *AuthorizationController) CheckPermission(ctx *router.Context) error {
 		)
 	} else {
 		hasPermission, err = c.Service.HasPermission(
+			ctx.Context(),
 			request.UserId,
 			request.OrgId,
 			request.ResourceType,
@@ -586,6 +462,10 @@ func (c *AuthorizationController) CheckPermission(ctx *router.Context) error {
 	}
 
 	if err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+
 		c.Logger.Error("Error checking permission",
 			logger.String("error", err.Error()),
 			logger.String("user_id", fmt.Sprintf("%d", request.UserId)),
@@ -594,12 +474,41 @@ func (c *AuthorizationController) CheckPermission(ctx *router.Context) error {
 			logger.String("action", request.Action),
 			logger.String("resource_id", request.ResourceId))
 
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error: "Failed to check permission",
-		})
+		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to check permission")
+	}
+
+	return ctx.OK(CheckPermissionResponse{HasPermission: hasPermission})
+}
+
+// GetMyPermissions returns the authenticated user's effective permissions
+func (c *AuthorizationController) GetMyPermissions(ctx *router.Context) error {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil || userId == 0 {
+		return ctx.Fail(http.StatusUnauthorized, "unauthorized", "Authentication required")
+	}
+
+	orgId := ctx.OrgID()
+	if orgId == 0 {
+		return ctx.Fail(http.StatusBadRequest, "missing_organization", "Base-Orgid header is required")
+	}
+
+	permissions, err := c.Service.GetMemberPermissions(ctx.Context(), userId, orgId)
+	if err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+
+		if err == ErrUserNotAuthorized {
+			return ctx.Fail(http.StatusForbidden, "not_a_member", "Not a member of the organization")
+		}
+
+		c.Logger.Error("Error getting user permissions",
+			logger.String("error", err.Error()),
+			logger.Uint64("user_id", userId),
+			logger.Uint64("organization_id", orgId))
+
+		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to retrieve permissions")
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]any{
-		"has_permission": hasPermission,
-	})
+	return ctx.OK(permissions)
 }
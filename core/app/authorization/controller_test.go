@@ -0,0 +1,131 @@
+package authorization
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+)
+
+// myPermissions requests GET /authorization/me/permissions as userId in
+// orgId, either of which may be zero to leave it out
+func myPermissions(t *testing.T, s *AuthorizationService, userId, orgId uint64) (int, EffectivePermissions) {
+	t.Helper()
+	c := NewAuthorizationController(s, logger.NewLoggerFromZap(zap.NewNop()))
+	r := router.New()
+	r.GET("/authorization/me/permissions", c.GetMyPermissions, func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			if userId != 0 {
+				c.Set("user_id", uint(userId))
+			}
+			if orgId != 0 {
+				c.Set(router.OrgIDKey, orgId)
+			}
+			return next(c)
+		}
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/authorization/me/permissions", nil))
+
+	var body struct {
+		Data EffectivePermissions `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode %q: %v", w.Body.String(), err)
+		}
+	}
+	return w.Code, body.Data
+}
+
+func TestGetMyPermissionsOwner(t *testing.T) {
+	s := newTestService(t)
+	addMember(t, s, 1, "Owner")
+
+	status, got := myPermissions(t, s, 1, testOrg)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if !got.Owner || !slices.Equal(got.Permissions, []string{AllPermissions}) || got.OrganizationId != testOrg {
+		t.Errorf("owner permissions = %+v, want just *", got)
+	}
+
+	// The is_owner flag counts as much as the Owner role
+	if err := s.DB.Create(&member{OrganizationId: testOrg, UserId: 2, RoleId: "999", IsOwner: true}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, got := myPermissions(t, s, 2, testOrg); !got.Owner {
+		t.Errorf("flagged owner = %+v, want owner", got)
+	}
+}
+
+func TestGetMyPermissionsMember(t *testing.T) {
+	s := newTestService(t)
+	addMember(t, s, 2, "Member")
+
+	var m member
+	s.DB.Where("user_id = 2").First(&m)
+	grants := []ResourcePermission{
+		{ResourceType: "media", Action: ActionUpdate, UserId: 2, OrganizationId: testOrg, ResourceId: "7"},
+		{ResourceType: "report", Action: ActionCreate, RoleId: m.RoleId},
+		// Another organization's grants stay out
+		{ResourceType: "media", Action: ActionDelete, UserId: 2, OrganizationId: testOrg + 1, ResourceId: "8"},
+	}
+	if err := s.DB.Create(&grants).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DB.Create(&ResourceAccess{MemberId: m.Id, ResourceType: "invoice", AccessType: "read_only"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	status, got := myPermissions(t, s, 2, testOrg)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if got.Owner || got.RoleId != m.RoleId {
+		t.Errorf("member = %+v", got)
+	}
+	for _, want := range []string{"media:read", "user:list", "report:create", "invoice:read"} {
+		if !slices.Contains(got.Permissions, want) {
+			t.Errorf("permissions %v lack %s", got.Permissions, want)
+		}
+	}
+	for _, unwanted := range []string{AllPermissions, "media:update", "media:delete", "user:delete"} {
+		if slices.Contains(got.Permissions, unwanted) {
+			t.Errorf("permissions %v include %s", got.Permissions, unwanted)
+		}
+	}
+	if !slices.IsSorted(got.Permissions) {
+		t.Errorf("permissions %v not sorted", got.Permissions)
+	}
+	want := []ResourceGrant{{ResourceType: "media", ResourceId: "7", Action: ActionUpdate}}
+	if !slices.Equal(got.Resources, want) {
+		t.Errorf("resources = %+v, want %+v", got.Resources, want)
+	}
+}
+
+func TestGetMyPermissionsErrors(t *testing.T) {
+	s := newTestService(t)
+	addMember(t, s, 2, "Member")
+
+	tests := []struct {
+		userId, orgId uint64
+		status        int
+	}{
+		{0, testOrg, http.StatusUnauthorized},
+		{2, 0, http.StatusBadRequest},
+		{3, testOrg, http.StatusForbidden},
+		{2, testOrg + 1, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if status, _ := myPermissions(t, s, tt.userId, tt.orgId); status != tt.status {
+			t.Errorf("user %d in org %d = %d, want %d", tt.userId, tt.orgId, status, tt.status)
+		}
+	}
+}
@@ -119,8 +119,11 @@ func AuthMiddleware(resourceType string, action string) router.MiddlewareFunc {
 			}
 
 			// Check if the user has permission to perform the action on the resource type
-			hasPermission, err := authorizationService.HasPermission(userId, orgId, resourceType, action)
+			hasPermission, err := authorizationService.HasPermission(c.Context(), userId, orgId, resourceType, action)
 			if err != nil {
+				if c.Canceled(err) {
+					return nil
+				}
 				c.AbortWithStatusJSON(http.StatusInternalServerError, map[string]any{
 					"error": fmt.Sprintf("error checking permission: %v", err),
 				})
@@ -188,8 +191,11 @@ func ResourceAuthMiddleware(resourceType string, action string, resourceIdParam
 			}
 
 			// Check if the user has permission to access the specific resource
-			hasPermission, err := authorizationService.HasResourcePermission(userId, orgId, resourceType, resourceId, action)
+			hasPermission, err := authorizationService.HasResourcePermission(c.Context(), userId, orgId, resourceType, resourceId, action)
 			if err != nil {
+				if c.Canceled(err) {
+					return nil
+				}
 				c.AbortWithStatusJSON(http.StatusInternalServerError, map[string]any{
 					"error": fmt.Sprintf("error checking resource permission: %v", err),
 				})
@@ -249,8 +255,11 @@ func RequireRole(roleName string) router.MiddlewareFunc {
 
 			// TODO: Implement HasRole method in AuthorizationService or use alternative approach
 			// For now, just check if user has general permission
-			hasPermission, err := authorizationService.HasPermission(userId, orgId, "role", "read")
+			hasPermission, err := authorizationService.HasPermission(c.Context(), userId, orgId, "role", "read")
 			if err != nil {
+				if c.Canceled(err) {
+					return nil
+				}
 				c.AbortWithStatusJSON(http.StatusInternalServerError, map[string]any{
 					"error": fmt.Sprintf("error checking role permission: %v", err),
 				})
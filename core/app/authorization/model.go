@@ -161,18 +161,19 @@ type RolePermissionResponse struct {
 
 // ResourcePermission grants permissions on resource types or specific resources
 type ResourcePermission struct {
-	Id           uint       `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
-	ResourceType string     `gorm:"not null" json:"resource_type"` // Resource type (e.g., "project", "employee", etc.)
-	ResourceId   string     `json:"resource_id"`                   // Optional: specific resource Id if applicable
-	UserId       uint       `json:"user_id"`                       // Optional: specific user Id if applicable
-	RoleId       string     `gorm:"index" json:"role_id"`          // Optional: role Id for role-based permissions
-	Action       string     `json:"action"`                        // Action type (e.g., "create", "read", "update", "delete")
-	DefaultScope string     `json:"default_scope"`                 // Default permission scope (e.g., "own", "team", "all")
-	PermissionId uint       `gorm:"index" json:"permission_id"`    // Optional: legacy permission Id
-	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
-	Permission   Permission `gorm:"foreignKey:PermissionId" json:"-"`
-	Role         Role       `gorm:"foreignKey:RoleId;references:Id" json:"-"` // Relationship to Role
+	Id             uint       `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	ResourceType   string     `gorm:"not null" json:"resource_type"`          // Resource type (e.g., "project", "employee", etc.)
+	ResourceId     string     `json:"resource_id"`                            // Optional: specific resource Id if applicable
+	UserId         uint       `json:"user_id"`                                // Optional: specific user Id if applicable
+	OrganizationId uint       `gorm:"index;default:0" json:"organization_id"` // Organization the grant applies in
+	RoleId         string     `gorm:"index" json:"role_id"`                   // Optional: role Id for role-based permissions
+	Action         string     `json:"action"`                                 // Action type (e.g., "create", "read", "update", "delete")
+	DefaultScope   string     `json:"default_scope"`                          // Default permission scope (e.g., "own", "team", "all")
+	PermissionId   uint       `gorm:"index" json:"permission_id"`             // Optional: legacy permission Id
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	Permission     Permission `gorm:"foreignKey:PermissionId" json:"-"`
+	Role           Role       `gorm:"foreignKey:RoleId;references:Id" json:"-"` // Relationship to Role
 }
 
 // ToResponse converts the resource permission to a response object
@@ -181,34 +182,36 @@ func (rp *ResourcePermission) ToResponse() *ResourcePermissionResponse {
 		return nil
 	}
 	return &ResourcePermissionResponse{
-		Id:           rp.Id,
-		ResourceType: rp.ResourceType,
-		ResourceId:   rp.ResourceId,
-		UserId:       rp.UserId,
-		RoleId:       rp.RoleId,
-		Action:       rp.Action,
-		DefaultScope: rp.DefaultScope,
-		PermissionId: rp.PermissionId,
-		CreatedAt:    rp.CreatedAt,
-		UpdatedAt:    rp.UpdatedAt,
+		Id:             rp.Id,
+		ResourceType:   rp.ResourceType,
+		ResourceId:     rp.ResourceId,
+		UserId:         rp.UserId,
+		OrganizationId: rp.OrganizationId,
+		RoleId:         rp.RoleId,
+		Action:         rp.Action,
+		DefaultScope:   rp.DefaultScope,
+		PermissionId:   rp.PermissionId,
+		CreatedAt:      rp.CreatedAt,
+		UpdatedAt:      rp.UpdatedAt,
 		// Role details will be added where needed
 	}
 }
 
 // ResourcePermissionResponse represents the response structure for a resource permission
 type ResourcePermissionResponse struct {
-	Id           uint                `json:"id"`
-	ResourceType string              `json:"resource_type"`
-	ResourceId   string              `json:"resource_id,omitempty"`
-	UserId       uint                `json:"user_id,omitempty"`
-	RoleId       string              `json:"role_id,omitempty"`
-	Action       string              `json:"action,omitempty"`
-	DefaultScope string              `json:"default_scope,omitempty"`
-	PermissionId uint                `json:"permission_id,omitempty"`
-	CreatedAt    time.Time           `json:"created_at"`
-	UpdatedAt    time.Time           `json:"updated_at"`
-	Permission   *PermissionResponse `json:"permission,omitempty"`
-	RoleDetails  *RoleResponse       `json:"role_details,omitempty"`
+	Id             uint                `json:"id"`
+	ResourceType   string              `json:"resource_type"`
+	ResourceId     string              `json:"resource_id,omitempty"`
+	UserId         uint                `json:"user_id,omitempty"`
+	OrganizationId uint                `json:"organization_id,omitempty"`
+	RoleId         string              `json:"role_id,omitempty"`
+	Action         string              `json:"action,omitempty"`
+	DefaultScope   string              `json:"default_scope,omitempty"`
+	PermissionId   uint                `json:"permission_id,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+	Permission     *PermissionResponse `json:"permission,omitempty"`
+	RoleDetails    *RoleResponse       `json:"role_details,omitempty"`
 }
 
 // ErrorResponse represents an error response
@@ -221,6 +224,26 @@ type SuccessResponse struct {
 	Message string `json:"message"`
 }
 
+// AssignPermissionRequest represents the request to assign a permission to a role
+type AssignPermissionRequest struct {
+	PermissionId string `json:"permission_id" binding:"required"`
+}
+
+// CheckPermissionRequest represents a permission check; ResourceId narrows
+// it to a single resource
+type CheckPermissionRequest struct {
+	UserId       uint64 `json:"user_id" binding:"required"`
+	OrgId        uint64 `json:"organization_id" binding:"required"`
+	ResourceType string `json:"resource_type" binding:"required"`
+	Action       string `json:"action" binding:"required"`
+	ResourceId   string `json:"resource_id"`
+}
+
+// CheckPermissionResponse represents the result of a permission check
+type CheckPermissionResponse struct {
+	HasPermission bool `json:"has_permission"`
+}
+
 // Constants for actions
 const (
 	ActionCreate     = "create"
@@ -232,6 +255,28 @@ const (
 	ActionManageRole = "manage_role"
 )
 
+// AllPermissions stands for every action, or every permission when it is
+// the only one listed
+const AllPermissions = "*"
+
+// EffectivePermissions is what a member may do in an organization.
+// Permissions are resource_type:action pairs, an action of * allowing every
+// action on the type; owners get just "*".
+type EffectivePermissions struct {
+	OrganizationId uint64          `json:"organization_id"`
+	RoleId         string          `json:"role_id"`
+	Owner          bool            `json:"owner"`
+	Permissions    []string        `json:"permissions"`
+	Resources      []ResourceGrant `json:"resources"`
+}
+
+// ResourceGrant allows an action on a single resource
+type ResourceGrant struct {
+	ResourceType string `json:"resource_type"`
+	ResourceId   string `json:"resource_id"`
+	Action       string `json:"action"`
+}
+
 // ResourceAccess defines fine-grained access control for specific resources
 type ResourceAccess struct {
 	Id           uint      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
@@ -0,0 +1,133 @@
+package authorization
+
+import (
+	"base/core/seed"
+
+	"gorm.io/gorm"
+)
+
+// Default system roles (OrganizationId 0) and the permissions they're granted,
+// as resource_type:action. Grants naming a permission that doesn't exist are
+// skipped.
+var (
+	defaultRoles = []Role{
+		{Name: "Owner", Description: "Full access to all resources", IsSystem: true},
+		{Name: "Administrator", Description: "Administrative access with some limitations", IsSystem: true},
+		{Name: "Member", Description: "Standard member with limited access", IsSystem: true},
+		{Name: "Viewer", Description: "Read-only access to resources", IsSystem: true},
+	}
+
+	defaultResourceTypes = []string{"user", "authorization", "media", "profile"}
+	defaultActions       = []string{"create", "read", "update", "delete", "list"}
+
+	specialPermissions = []Permission{
+		{Name: "Manage Roles", Description: "Create, update, and delete roles", ResourceType: "role", Action: "manage"},
+		{Name: "Assign Permissions", Description: "Assign permissions to roles", ResourceType: "permission", Action: "assign"},
+	}
+
+	readOnlyGrants = []string{
+		"user:read", "user:list",
+		"authorization:read", "authorization:list",
+		"media:read", "media:list",
+		"profile:read", "profile:list",
+		"role:read", "role:list",
+		"permission:read", "permission:list",
+		"resource_permission:read", "resource_permission:list",
+	}
+
+	defaultGrants = map[string][]string{
+		"Administrator": {
+			"user:create", "user:read", "user:update", "user:delete", "user:list", "user:manage_members",
+			"authorization:create", "authorization:read", "authorization:update", "authorization:delete", "authorization:list",
+			"media:create", "media:read", "media:update", "media:delete", "media:list",
+			"profile:create", "profile:read", "profile:update", "profile:delete", "profile:list",
+			"role:create", "role:read", "role:update", "role:delete", "role:list",
+			"permission:create", "permission:read", "permission:update", "permission:delete", "permission:list",
+			"resource_permission:create", "resource_permission:read", "resource_permission:update", "resource_permission:delete", "resource_permission:list",
+		},
+		"Member": readOnlyGrants,
+		"Viewer": readOnlyGrants,
+	}
+)
+
+// registerSeeders registers the default roles, permissions and grants
+func registerSeeders() {
+	seed.Register(seed.Seeder{Name: "authorization.roles", Run: seedRoles})
+	seed.Register(seed.Seeder{Name: "authorization.permissions", Run: seedPermissions})
+	seed.Register(seed.Seeder{Name: "authorization.role_permissions", Run: seedRolePermissions})
+}
+
+func seedRoles(tx *gorm.DB) error {
+	for _, role := range defaultRoles {
+		keys := map[string]any{"name": role.Name, "is_system": role.IsSystem}
+		if _, err := seed.CreateIfNotExists(tx, &role, keys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func seedPermissions(tx *gorm.DB) error {
+	var permissions []Permission
+	for _, resourceType := range defaultResourceTypes {
+		for _, action := range defaultActions {
+			permissions = append(permissions, Permission{
+				Name:         resourceType + " " + action,
+				Description:  "Allows " + action + " operations on " + resourceType,
+				ResourceType: resourceType,
+				Action:       action,
+			})
+		}
+	}
+	permissions = append(permissions, specialPermissions...)
+
+	for _, permission := range permissions {
+		keys := map[string]any{"resource_type": permission.ResourceType, "action": permission.Action}
+		if _, err := seed.CreateIfNotExists(tx, &permission, keys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedRolePermissions grants the Owner every permission and the other system
+// roles their defaultGrants
+func seedRolePermissions(tx *gorm.DB) error {
+	var permissions []Permission
+	if err := tx.Find(&permissions).Error; err != nil {
+		return err
+	}
+	byKey := make(map[string]Permission, len(permissions))
+	for _, permission := range permissions {
+		byKey[permission.ResourceType+":"+permission.Action] = permission
+	}
+
+	grants := map[string][]Permission{"Owner": permissions}
+	for roleName, keys := range defaultGrants {
+		for _, key := range keys {
+			if permission, ok := byKey[key]; ok {
+				grants[roleName] = append(grants[roleName], permission)
+			}
+		}
+	}
+
+	for roleName, rolePermissions := range grants {
+		var role Role
+		result := tx.Where("name = ? AND is_system = ?", roleName, true).Limit(1).Find(&role)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		for _, permission := range rolePermissions {
+			rolePermission := RolePermission{RoleId: role.Id, PermissionId: permission.Id}
+			keys := map[string]any{"role_id": role.Id, "permission_id": permission.Id}
+			if _, err := seed.CreateIfNotExists(tx, &rolePermission, keys); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
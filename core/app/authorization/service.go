@@ -1,66 +1,102 @@
 package authorization
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"slices"
 	"strconv"
 	"time"
 
+	"base/core/database"
+	"base/core/logger"
+
 	"gorm.io/gorm"
 )
 
 // AuthorizationService handles business logic for authorization
 type AuthorizationService struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	Logger logger.Logger
 }
 
 // NewAuthorizationService creates a new authorization service
-func NewAuthorizationService(db *gorm.DB) *AuthorizationService {
+func NewAuthorizationService(db *gorm.DB, log logger.Logger) *AuthorizationService {
 	return &AuthorizationService{
-		DB: db,
+		DB:     db,
+		Logger: log,
 	}
 }
 
-// GetRoles returns all roles for an organization
-func (s *AuthorizationService) GetRoles(organizationId uint64) ([]Role, error) {
-	// If organizationId is not 0, fetch both system roles (organization_id=0) and org-specific roles
-	// If organizationId is 0, just fetch system roles
-	var roles []Role
-	var result *gorm.DB
-
+// GetRoles returns one page of the roles for an organization, ordered by
+// name then id, and the total number of roles. The system roles are
+// included; an organizationId of 0 returns only them. A limit of 0 or less
+// returns every role.
+func (s *AuthorizationService) GetRoles(ctx context.Context, organizationId uint64, page, limit int) ([]Role, int64, error) {
+	query := s.DB.WithContext(ctx).Model(&Role{})
 	if organizationId != 0 {
 		// Fetch both system roles (organization_id=0) and organization-specific roles
-		result = s.DB.Where("organization_id = ? OR organization_id = 0", organizationId).Find(&roles)
+		query = query.Where("organization_id = ? OR organization_id = 0", organizationId)
 	} else {
 		// Just fetch system roles
-		result = s.DB.Where("organization_id = 0").Find(&roles)
+		query = query.Where("organization_id = 0")
 	}
 
-	if result.Error != nil {
-		return nil, result.Error
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
-	// For each role, count its permissions
-	for i := range roles {
-		// Count permissions for this role
-		var count int64
-		if err := s.DB.Model(&RolePermission{}).
-			Where("role_id = ?", roles[i].Id).
-			Count(&count).Error; err != nil {
-			// Log the error but continue
-			fmt.Printf("Error counting permissions for role %d: %v\n", roles[i].Id, err)
+	query = query.Order("name ASC").Order("id ASC")
+	if limit > 0 {
+		if page < 1 {
+			page = 1
 		}
+		query = query.Offset((page - 1) * limit).Limit(limit)
+	}
+
+	roles := []Role{}
+	if err := query.Find(&roles).Error; err != nil {
+		return nil, 0, err
+	}
+	if len(roles) == 0 {
+		return roles, total, nil
+	}
+
+	// Count the permissions of every role on the page in one query
+	roleIds := make([]uint, len(roles))
+	for i := range roles {
+		roleIds[i] = roles[i].Id
+	}
+	var counts []struct {
+		RoleId uint
+		Count  int
+	}
+	if err := s.DB.WithContext(ctx).Model(&RolePermission{}).
+		Select("role_id, COUNT(*) AS count").
+		Where("role_id IN ?", roleIds).
+		Group("role_id").
+		Scan(&counts).Error; err != nil {
+		// Log the error but continue; the roles are returned with zero counts
+		s.Logger.Error("Failed to count role permissions",
+			logger.Uint64("organization_id", organizationId),
+			logger.String("error", err.Error()))
+		return roles, total, nil
+	}
 
-		// Set the permission count
-		roles[i].PermissionCount = int(count)
+	countByRole := make(map[uint]int, len(counts))
+	for _, c := range counts {
+		countByRole[c.RoleId] = c.Count
 	}
-	return roles, nil
+	for i := range roles {
+		roles[i].PermissionCount = countByRole[roles[i].Id]
+	}
+	return roles, total, nil
 }
 
 // GetRole returns a role by Id
-func (s *AuthorizationService) GetRole(id uint64) (*Role, error) {
+func (s *AuthorizationService) GetRole(ctx context.Context, id uint64) (*Role, error) {
 	var role Role
-	result := s.DB.First(&role, "id = ?", id)
+	result := s.DB.WithContext(ctx).First(&role, "id = ?", id)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -73,19 +109,19 @@ func (s *AuthorizationService) GetRole(id uint64) (*Role, error) {
 }
 
 // CreateRole creates a new role
-func (s *AuthorizationService) CreateRole(role *Role) error {
+func (s *AuthorizationService) CreateRole(ctx context.Context, role *Role) error {
 	// Set creation time
 	role.CreatedAt = time.Now()
 	role.UpdatedAt = time.Now()
 
-	result := s.DB.Create(role)
+	result := s.DB.WithContext(ctx).Create(role)
 	return result.Error
 }
 
 // UpdateRole updates an existing role
-func (s *AuthorizationService) UpdateRole(role *Role) error {
+func (s *AuthorizationService) UpdateRole(ctx context.Context, role *Role) error {
 	var existingRole Role
-	result := s.DB.First(&existingRole, "id = ?", role.Id)
+	result := s.DB.WithContext(ctx).First(&existingRole, "id = ?", role.Id)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -104,7 +140,7 @@ func (s *AuthorizationService) UpdateRole(role *Role) error {
 	existingRole.Description = role.Description
 	existingRole.UpdatedAt = time.Now()
 
-	result = s.DB.Save(&existingRole)
+	result = s.DB.WithContext(ctx).Save(&existingRole)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -116,9 +152,9 @@ func (s *AuthorizationService) UpdateRole(role *Role) error {
 }
 
 // DeleteRole deletes a role
-func (s *AuthorizationService) DeleteRole(id uint64) error {
+func (s *AuthorizationService) DeleteRole(ctx context.Context, id uint64) error {
 	var existingRole Role
-	result := s.DB.First(&existingRole, "id = ?", id)
+	result := s.DB.WithContext(ctx).First(&existingRole, "id = ?", id)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -132,23 +168,24 @@ func (s *AuthorizationService) DeleteRole(id uint64) error {
 		return ErrSystemRoleUnmodifiable
 	}
 
-	// First delete associated role permissions
-	if err := s.DB.Where("role_id = ?", id).Delete(&RolePermission{}).Error; err != nil {
-		return err
-	}
+	return database.Transaction(ctx, s.DB, func(tx *gorm.DB) error {
+		// First delete associated role permissions
+		if err := tx.Where("role_id = ?", id).Delete(&RolePermission{}).Error; err != nil {
+			return err
+		}
 
-	// Then delete the role
-	result = s.DB.Delete(&existingRole)
-	return result.Error
+		// Then delete the role
+		return tx.Delete(&existingRole).Error
+	})
 }
 
 // GetRolePermissions returns all permissions for a role
-func (s *AuthorizationService) GetRolePermissions(roleId uint64) ([]Permission, error) {
+func (s *AuthorizationService) GetRolePermissions(ctx context.Context, roleId uint64) ([]Permission, error) {
 	// Convert string Id to uint
 
 	// Check if role exists
 	var role Role
-	result := s.DB.First(&role, "id = ?", roleId)
+	result := s.DB.WithContext(ctx).First(&role, "id = ?", roleId)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -159,7 +196,7 @@ func (s *AuthorizationService) GetRolePermissions(roleId uint64) ([]Permission,
 
 	// Get permissions
 	var permissions []Permission
-	err := s.DB.Raw(`
+	err := s.DB.WithContext(ctx).Raw(`
 		SELECT p.* FROM permissions p
 		JOIN role_permissions rp ON p.id = rp.permission_id
 		WHERE rp.role_id = ?
@@ -173,11 +210,11 @@ func (s *AuthorizationService) GetRolePermissions(roleId uint64) ([]Permission,
 }
 
 // AssignPermissionToRole assigns a permission to a role
-func (s *AuthorizationService) AssignPermissionToRole(roleId uint64, permissionId uint64) error {
+func (s *AuthorizationService) AssignPermissionToRole(ctx context.Context, roleId uint64, permissionId uint64) error {
 
 	// Check if role exists
 	var role Role
-	result := s.DB.First(&role, "id = ?", roleId)
+	result := s.DB.WithContext(ctx).First(&role, "id = ?", roleId)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -188,7 +225,7 @@ func (s *AuthorizationService) AssignPermissionToRole(roleId uint64, permissionI
 
 	// Check if permission exists
 	var permission Permission
-	result = s.DB.First(&permission, "id = ?", permissionId)
+	result = s.DB.WithContext(ctx).First(&permission, "id = ?", permissionId)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -197,32 +234,35 @@ func (s *AuthorizationService) AssignPermissionToRole(roleId uint64, permissionI
 		return result.Error
 	}
 
-	// Check if permission is already assigned
-	var count int64
-	s.DB.Model(&RolePermission{}).
-		Where("role_id = ? AND permission_id = ?", roleId, permissionId).
-		Count(&count)
+	return database.Transaction(ctx, s.DB, func(tx *gorm.DB) error {
+		// Check if permission is already assigned
+		var count int64
+		if err := tx.Model(&RolePermission{}).
+			Where("role_id = ? AND permission_id = ?", roleId, permissionId).
+			Count(&count).Error; err != nil {
+			return err
+		}
 
-	if count > 0 {
-		return ErrDuplicatePermission
-	}
+		if count > 0 {
+			return ErrDuplicatePermission
+		}
 
-	// Create role permission
-	rolePermission := RolePermission{
-		RoleId:       uint(roleId),
-		PermissionId: uint(permissionId),
-		CreatedAt:    time.Now(),
-	}
+		// Create role permission
+		rolePermission := RolePermission{
+			RoleId:       uint(roleId),
+			PermissionId: uint(permissionId),
+			CreatedAt:    time.Now(),
+		}
 
-	result = s.DB.Create(&rolePermission)
-	return result.Error
+		return tx.Create(&rolePermission).Error
+	})
 }
 
 // RevokePermissionFromRole removes a permission from a role
-func (s *AuthorizationService) RevokePermissionFromRole(roleId uint64, permissionId uint64) error {
+func (s *AuthorizationService) RevokePermissionFromRole(ctx context.Context, roleId uint64, permissionId uint64) error {
 	// Check if role exists
 	var role Role
-	result := s.DB.First(&role, "id = ?", roleId)
+	result := s.DB.WithContext(ctx).First(&role, "id = ?", roleId)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -233,7 +273,7 @@ func (s *AuthorizationService) RevokePermissionFromRole(roleId uint64, permissio
 
 	// Check if permission exists
 	var permission Permission
-	result = s.DB.First(&permission, "id = ?", permissionId)
+	result = s.DB.WithContext(ctx).First(&permission, "id = ?", permissionId)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -243,30 +283,30 @@ func (s *AuthorizationService) RevokePermissionFromRole(roleId uint64, permissio
 	}
 
 	// Delete role permission
-	result = s.DB.Where("role_id = ? AND permission_id = ?", roleId, permissionId).
+	result = s.DB.WithContext(ctx).Where("role_id = ? AND permission_id = ?", roleId, permissionId).
 		Delete(&RolePermission{})
 
 	return result.Error
 }
 
 // CreateResourcePermission creates a resource-specific permission
-func (s *AuthorizationService) CreateResourcePermission(rp *ResourcePermission) error {
+func (s *AuthorizationService) CreateResourcePermission(ctx context.Context, rp *ResourcePermission) error {
 	// Set creation time
 	rp.CreatedAt = time.Now()
 	rp.UpdatedAt = time.Now()
 
-	result := s.DB.Create(rp)
+	result := s.DB.WithContext(ctx).Create(rp)
 	return result.Error
 }
 
 // DeleteResourcePermission deletes a resource-specific permission
-func (s *AuthorizationService) DeleteResourcePermission(id uint64) error {
-	result := s.DB.Delete(&ResourcePermission{}, "id = ?", id)
+func (s *AuthorizationService) DeleteResourcePermission(ctx context.Context, id uint64) error {
+	result := s.DB.WithContext(ctx).Delete(&ResourcePermission{}, "id = ?", id)
 	return result.Error
 }
 
 // HasPermission checks if a user has permission for a resource type
-func (s *AuthorizationService) HasPermission(userId uint64, orgId uint64, resourceType, action string) (bool, error) {
+func (s *AuthorizationService) HasPermission(ctx context.Context, userId uint64, orgId uint64, resourceType, action string) (bool, error) {
 	// Skip organization check if orgId is 0 (indicates a global endpoint)
 	if orgId == 0 {
 		return true, nil
@@ -279,7 +319,7 @@ func (s *AuthorizationService) HasPermission(userId uint64, orgId uint64, resour
 	var department string
 	var membershipType string
 
-	memberErr := s.DB.Raw(`
+	memberErr := s.DB.WithContext(ctx).Raw(`
 		SELECT id, role_id, is_owner, COALESCE(department, '') as department, 
 		COALESCE(membership_type, 'Internal') as membership_type 
 		FROM organization_members
@@ -287,6 +327,10 @@ func (s *AuthorizationService) HasPermission(userId uint64, orgId uint64, resour
 	`, userId, orgId).Row().Scan(&memberId, &roleId, &isOwnerFlag, &department, &membershipType)
 
 	if memberErr != nil {
+		// A canceled request is not a denial
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
 		return false, ErrUserNotAuthorized
 	}
 
@@ -296,27 +340,19 @@ func (s *AuthorizationService) HasPermission(userId uint64, orgId uint64, resour
 	}
 
 	// STEP 2: Check if the user has the Owner role for this organization
-	var isOwnerRole int64
-	ownerErr := s.DB.Raw(`
-		SELECT COUNT(*) FROM organization_members om
-		JOIN roles r ON CAST(om.role_id AS UNSIGNED) = r.id
-		WHERE om.user_id = ?
-		AND om.organization_id = ?
-		AND r.name = 'Owner'
-	`, userId, orgId).Count(&isOwnerRole).Error
-
+	isOwnerRole, ownerErr := s.hasOwnerRole(ctx, userId, orgId)
 	if ownerErr != nil {
 		return false, ownerErr
 	}
 
 	// If the user has an Owner role, automatically grant all permissions
-	if isOwnerRole > 0 {
+	if isOwnerRole {
 		return true, nil
 	}
 
 	// STEP 3: Check for specific ResourceAccess entries for this member
 	var resourceAccessCount int64
-	s.DB.Model(&ResourceAccess{}).
+	s.DB.WithContext(ctx).Model(&ResourceAccess{}).
 		Where("member_id = ? AND resource_type = ?", memberId, resourceType).
 		Count(&resourceAccessCount)
 
@@ -324,7 +360,7 @@ func (s *AuthorizationService) HasPermission(userId uint64, orgId uint64, resour
 		// Found specific access rules for this member, so we'll use them
 		// Check if any of the resource access entries allow the requested action
 		var actionAllowed int64
-		resourceAccessErr := s.DB.Raw(`
+		resourceAccessErr := s.DB.WithContext(ctx).Raw(`
 			SELECT COUNT(*) FROM resource_access
 			WHERE member_id = ?
 			AND resource_type = ?
@@ -343,7 +379,7 @@ func (s *AuthorizationService) HasPermission(userId uint64, orgId uint64, resour
 		if action == "read" {
 			// Check if the user has any access type that allows reading
 			var readAllowed int64
-			s.DB.Raw(`
+			s.DB.WithContext(ctx).Raw(`
 				SELECT COUNT(*) FROM resource_access
 				WHERE member_id = ?
 				AND resource_type = ?
@@ -361,7 +397,7 @@ func (s *AuthorizationService) HasPermission(userId uint64, orgId uint64, resour
 	// STEP 4: Check for role-based resource permissions
 	if roleId != "" {
 		var rolePermCount int64
-		s.DB.Model(&ResourcePermission{}).
+		s.DB.WithContext(ctx).Model(&ResourcePermission{}).
 			Where("role_id = ? AND resource_type = ? AND action = ?", roleId, resourceType, action).
 			Count(&rolePermCount)
 
@@ -372,10 +408,10 @@ func (s *AuthorizationService) HasPermission(userId uint64, orgId uint64, resour
 
 	// STEP 5: Fall back to the legacy permission system
 	var count int64
-	err := s.DB.Raw(`
+	err := s.DB.WithContext(ctx).Raw(`
 		SELECT COUNT(*) FROM role_permissions rp
 		JOIN permissions p ON rp.permission_id = p.id
-		JOIN organization_members om ON CAST(om.role_id AS UNSIGNED) = rp.role_id
+		JOIN organization_members om ON `+s.memberRoleId()+` = rp.role_id
 		WHERE om.user_id = ?
 		AND om.organization_id = ?
 		AND p.resource_type = ?
@@ -389,34 +425,56 @@ func (s *AuthorizationService) HasPermission(userId uint64, orgId uint64, resour
 	return count > 0, nil
 }
 
+// hasOwnerRole reports whether the user's membership in the organization carries the Owner role
+func (s *AuthorizationService) hasOwnerRole(ctx context.Context, userId uint64, orgId uint64) (bool, error) {
+	var count int64
+	err := s.DB.WithContext(ctx).Raw(`
+		SELECT COUNT(*) FROM organization_members om
+		JOIN roles r ON `+s.memberRoleId()+` = r.id
+		WHERE om.user_id = ?
+		AND om.organization_id = ?
+		AND r.name = 'Owner'
+	`, userId, orgId).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// memberRoleId returns organization_members.role_id cast to an integer for
+// comparison with role ids. role_id is stored as a string and each database
+// spells the integer cast differently.
+func (s *AuthorizationService) memberRoleId() string {
+	switch s.DB.Dialector.Name() {
+	case "mysql":
+		return "CAST(om.role_id AS UNSIGNED)"
+	case "postgres":
+		return "CAST(NULLIF(om.role_id, '') AS BIGINT)"
+	default:
+		return "CAST(om.role_id AS INTEGER)"
+	}
+}
+
 // HasResourcePermission checks if a user has permission for a specific resource
-func (s *AuthorizationService) HasResourcePermission(userId uint64, orgId uint64, resourceType, resourceId, action string) (bool, error) {
+func (s *AuthorizationService) HasResourcePermission(ctx context.Context, userId uint64, orgId uint64, resourceType, resourceId, action string) (bool, error) {
 	// Skip organization check if orgId is 0 (indicates a global endpoint)
 	if orgId == 0 {
 		return true, nil
 	}
 
 	// STEP 1: Check if the user has the Owner role for this organization
-	var isOwner int64
-	ownerErr := s.DB.Raw(`
-		SELECT COUNT(*) FROM organization_members om
-		JOIN roles r ON CAST(om.role_id AS UNSIGNED) = r.id
-		WHERE om.user_id = ?
-		AND om.organization_id = ?
-		AND r.name = 'Owner'
-	`, userId, orgId).Count(&isOwner).Error
-
+	isOwner, ownerErr := s.hasOwnerRole(ctx, userId, orgId)
 	if ownerErr != nil {
 		return false, ownerErr
 	}
 
 	// If the user is an Owner, automatically grant all permissions
-	if isOwner > 0 {
+	if isOwner {
 		return true, nil
 	}
 
 	// STEP 2: Check if the user has general permission for this resource type
-	hasGeneralPermission, err := s.HasPermission(userId, orgId, resourceType, action)
+	hasGeneralPermission, err := s.HasPermission(ctx, userId, orgId, resourceType, action)
 	if err != nil {
 		return false, err
 	}
@@ -428,7 +486,7 @@ func (s *AuthorizationService) HasResourcePermission(userId uint64, orgId uint64
 
 	// STEP 3: Check resource-specific permission
 	var count int64
-	err = s.DB.Raw(`
+	err = s.DB.WithContext(ctx).Raw(`
 		SELECT COUNT(*) FROM resource_permissions rp 
 		WHERE rp.user_id = ? 
 		AND rp.organization_id = ? 
@@ -445,329 +503,184 @@ func (s *AuthorizationService) HasResourcePermission(userId uint64, orgId uint64
 }
 
 // GetUserPermissions returns all permissions for a user across all organizations
-func (s *AuthorizationService) GetUserPermissions(userId string) ([]Permission, error) {
+func (s *AuthorizationService) GetUserPermissions(ctx context.Context, userId string) ([]Permission, error) {
 	// Convert string Id to uint
 	userIdUint, err := strconv.ParseUint(userId, 10, 32)
 	if err != nil {
-		fmt.Printf("GetUserPermissions: Invalid user Id format: %s, error: %v\n", userId, err)
+		s.Logger.Debug("Invalid user id for permission lookup",
+			logger.String("user_id", userId),
+			logger.String("error", err.Error()))
 		return nil, ErrInvalidId
 	}
 
-	fmt.Printf("GetUserPermissions: Getting permissions for user Id: %d\n", userIdUint)
-
 	// Get permissions from role-based permissions
 	var permissions []Permission
-	err = s.DB.Raw(`
+	err = s.DB.WithContext(ctx).Raw(`
 		SELECT DISTINCT p.* FROM permissions p
 		JOIN role_permissions rp ON p.id = rp.permission_id
-		JOIN organization_members om ON om.role_id = rp.role_id
+		JOIN organization_members om ON `+s.memberRoleId()+` = rp.role_id
 		WHERE om.user_id = ?
 	`, uint(userIdUint)).Scan(&permissions).Error
 
 	if err != nil {
-		fmt.Printf("GetUserPermissions: Error getting role-based permissions: %v\n", err)
+		s.Logger.Error("Failed to load role-based permissions",
+			logger.Uint64("user_id", userIdUint),
+			logger.String("error", err.Error()))
 		return nil, err
 	}
 
-	fmt.Printf("GetUserPermissions: Found %d role-based permissions\n", len(permissions))
-
 	// Get permissions from resource-specific permissions
 	var resourcePermissions []Permission
-	err = s.DB.Raw(`
+	err = s.DB.WithContext(ctx).Raw(`
 		SELECT DISTINCT p.* FROM permissions p
 		JOIN resource_permissions rp ON p.id = rp.permission_id
 		WHERE rp.user_id = ?
 	`, uint(userIdUint)).Scan(&resourcePermissions).Error
 
 	if err != nil {
-		fmt.Printf("GetUserPermissions: Error getting resource-specific permissions: %v\n", err)
+		s.Logger.Error("Failed to load resource-specific permissions",
+			logger.Uint64("user_id", userIdUint),
+			logger.String("error", err.Error()))
 		return nil, err
 	}
 
-	fmt.Printf("GetUserPermissions: Found %d resource-specific permissions\n", len(resourcePermissions))
+	result := mergePermissions(permissions, resourcePermissions)
+
+	s.Logger.Debug("Loaded user permissions",
+		logger.Uint64("user_id", userIdUint),
+		logger.Int("role_permissions", len(permissions)),
+		logger.Int("resource_permissions", len(resourcePermissions)),
+		logger.Int("total", len(result)))
+	return result, nil
+}
 
-	// Merge the two sets of permissions
-	// Create a map to avoid duplicates
-	permMap := make(map[uint]Permission)
-	for _, p := range permissions {
+// mergePermissions returns the permissions of both sets, each once
+func mergePermissions(a, b []Permission) []Permission {
+	permMap := make(map[uint]Permission, len(a)+len(b))
+	for _, p := range a {
 		permMap[p.Id] = p
 	}
-
-	for _, p := range resourcePermissions {
+	for _, p := range b {
 		permMap[p.Id] = p
 	}
 
-	// Convert map back to slice
 	result := make([]Permission, 0, len(permMap))
 	for _, p := range permMap {
 		result = append(result, p)
 	}
-
-	fmt.Printf("GetUserPermissions: Returning %d total permissions\n", len(result))
-	return result, nil
+	return result
 }
 
-// SeedPermissions creates default permissions if they don't exist
-func (s *AuthorizationService) SeedPermissions() error {
-	// Define resource types and actions (aligned with module seeding) Only for system roles and core modules
-	resourceTypes := []string{
-		"user", "authorization", "media", "profile",
-	}
-	actions := []string{"create", "read", "update", "delete", "list"}
-
-	// Create permissions for each resource type and action
-	for _, resourceType := range resourceTypes {
-		for _, action := range actions {
-			var permission Permission
-
-			// Check if permission already exists
-			result := s.DB.Where("resource_type = ? AND action = ?", resourceType, action).First(&permission)
-			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-				// Create permission
-				permission = Permission{
-					Name:         action + " " + resourceType,
-					Description:  "Permission to " + action + " " + resourceType,
-					ResourceType: resourceType,
-					Action:       action,
-					CreatedAt:    time.Now(),
-					UpdatedAt:    time.Now(),
-				}
-
-				if err := s.DB.Create(&permission).Error; err != nil {
-					return err
-				}
-			} else if result.Error != nil {
-				return result.Error
-			}
-		}
+// GetMemberPermissions returns what a user may do in one organization,
+// following the same rules as HasPermission: owners may do everything,
+// others get their role's permissions, the permissions granted to them or
+// their role through resource permissions, and their resource access rules.
+// Grants on individual resources are listed separately.
+func (s *AuthorizationService) GetMemberPermissions(ctx context.Context, userId uint64, orgId uint64) (*EffectivePermissions, error) {
+	if orgId == 0 {
+		return nil, ErrInvalidOrganizationId
 	}
 
-	return nil
-}
-
-// SeedRoles creates default roles if they don't exist
-func (s *AuthorizationService) SeedRoles() error {
-	// Define default roles
-	defaultRoles := []Role{
-		{
-			Name:        "Owner",
-			Description: "Full access to all resources",
-			IsSystem:    true,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			Name:        "Administrator",
-			Description: "Administrative access with some limitations",
-			IsSystem:    true,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			Name:        "Member",
-			Description: "Standard member with limited access",
-			IsSystem:    true,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			Name:        "External",
-			Description: "External user with minimal access",
-			IsSystem:    true,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-	}
-
-	// Create roles if they don't exist
-	for _, role := range defaultRoles {
-		var existingRole Role
-		result := s.DB.First(&existingRole, "name = ? AND is_system = ?", role.Name, role.IsSystem)
-
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			if err := s.DB.Create(&role).Error; err != nil {
-				return err
-			}
-		} else if result.Error != nil {
-			return result.Error
-		}
+	var member struct {
+		Id      uint
+		RoleId  string
+		IsOwner bool
 	}
-
-	return nil
-}
-
-// SetupRolePermissions assigns default permissions to system roles
-func (s *AuthorizationService) SetupRolePermissions() error {
-	// First seed permissions
-	if err := s.SeedPermissions(); err != nil {
-		return err
-	}
-
-	// Then seed roles
-	if err := s.SeedRoles(); err != nil {
-		return err
+	result := s.DB.WithContext(ctx).Table("organization_members").
+		Select("id, role_id, is_owner").
+		Where("user_id = ? AND organization_id = ?", userId, orgId).
+		Limit(1).
+		Scan(&member)
+	if result.Error != nil {
+		return nil, result.Error
 	}
-
-	// Get all permissions
-	var permissions []Permission
-	if err := s.DB.Find(&permissions).Error; err != nil {
-		return err
+	if result.RowsAffected == 0 {
+		return nil, ErrUserNotAuthorized
 	}
 
-	// Get the owner role
-	var ownerRole Role
-	if err := s.DB.Where("name = ? AND is_system = ?", "Owner", true).First(&ownerRole).Error; err != nil {
-		return err
+	effective := &EffectivePermissions{
+		OrganizationId: orgId,
+		RoleId:         member.RoleId,
+		Permissions:    []string{},
+		Resources:      []ResourceGrant{},
 	}
 
-	// Assign all permissions to the owner role
-	for _, permission := range permissions {
-		// Skip organization:delete for non-system roles
-		if permission.ResourceType == "organization" && permission.Action == "delete" {
-			continue
-		}
-
-		// Check if permission is already assigned
-		var count int64
-		s.DB.Model(&RolePermission{}).
-			Where("role_id = ? AND permission_id = ?", ownerRole.Id, permission.Id).
-			Count(&count)
-
-		if count == 0 {
-			rolePermission := RolePermission{
-				RoleId:       ownerRole.Id,
-				PermissionId: permission.Id,
-				CreatedAt:    time.Now(),
-			}
-
-			if err := s.DB.Create(&rolePermission).Error; err != nil {
-				return err
-			}
+	owner := member.IsOwner
+	if !owner {
+		var err error
+		if owner, err = s.hasOwnerRole(ctx, userId, orgId); err != nil {
+			return nil, err
 		}
 	}
-
-	// Get the admin role
-	var adminRole Role
-	if err := s.DB.Where("name = ? AND is_system = ?", "Administrator", true).First(&adminRole).Error; err != nil {
-		return err
+	if owner {
+		effective.Owner = true
+		effective.Permissions = []string{AllPermissions}
+		return effective, nil
 	}
 
-	// Define admin permissions
-	adminPermissionTypes := map[string][]string{
-		"user":    {"create", "read", "update", "delete", "list"},
-		"media":   {"create", "read", "update", "delete", "list"},
-		"profile": {"create", "read", "update", "delete", "list"},
+	// Role permissions, and permissions granted to the user in this organization
+	var rolePermissions, grantedPermissions []Permission
+	err := s.DB.WithContext(ctx).Raw(`
+		SELECT DISTINCT p.* FROM permissions p
+		JOIN role_permissions rp ON p.id = rp.permission_id
+		JOIN organization_members om ON `+s.memberRoleId()+` = rp.role_id
+		WHERE om.user_id = ? AND om.organization_id = ?
+	`, userId, orgId).Scan(&rolePermissions).Error
+	if err != nil {
+		return nil, err
 	}
-
-	// Assign admin permissions
-	for resourceType, actions := range adminPermissionTypes {
-		for _, action := range actions {
-			var permission Permission
-			if err := s.DB.Where("resource_type = ? AND action = ?", resourceType, action).First(&permission).Error; err != nil {
-				continue // Skip if permission not found
-			}
-
-			// Check if permission is already assigned
-			var count int64
-			s.DB.Model(&RolePermission{}).
-				Where("role_id = ? AND permission_id = ?", adminRole.Id, permission.Id).
-				Count(&count)
-
-			if count == 0 {
-				rolePermission := RolePermission{
-					RoleId:       adminRole.Id,
-					PermissionId: permission.Id,
-					CreatedAt:    time.Now(),
-				}
-
-				if err := s.DB.Create(&rolePermission).Error; err != nil {
-					return err
-				}
-			}
-		}
+	err = s.DB.WithContext(ctx).Raw(`
+		SELECT DISTINCT p.* FROM permissions p
+		JOIN resource_permissions rp ON p.id = rp.permission_id
+		WHERE rp.user_id = ? AND rp.organization_id = ?
+	`, userId, orgId).Scan(&grantedPermissions).Error
+	if err != nil {
+		return nil, err
 	}
 
-	// Get the member role
-	var memberRole Role
-	if err := s.DB.Where("name = ? AND is_system = ?", "Member", true).First(&memberRole).Error; err != nil {
-		return err
+	keys := make(map[string]bool)
+	for _, p := range mergePermissions(rolePermissions, grantedPermissions) {
+		keys[p.ResourceType+":"+p.Action] = true
 	}
 
-	// Define member permissions
-	memberPermissionTypes := map[string][]string{
-		"user":    {"read", "list"},
-		"media":   {"read", "list"},
-		"profile": {"read", "list"},
+	// Resource permissions given to the role, and to the user on single resources
+	var resourcePermissions []ResourcePermission
+	query := s.DB.WithContext(ctx).Where("user_id = ? AND organization_id = ? AND resource_id <> ''", userId, orgId)
+	if member.RoleId != "" {
+		query = query.Or("role_id = ? AND action <> ''", member.RoleId)
 	}
-
-	// Assign member permissions
-	for resourceType, actions := range memberPermissionTypes {
-		for _, action := range actions {
-			var permission Permission
-			if err := s.DB.Where("resource_type = ? AND action = ?", resourceType, action).First(&permission).Error; err != nil {
-				continue // Skip if permission not found
-			}
-
-			// Check if permission is already assigned
-			var count int64
-			s.DB.Model(&RolePermission{}).
-				Where("role_id = ? AND permission_id = ?", memberRole.Id, permission.Id).
-				Count(&count)
-
-			if count == 0 {
-				rolePermission := RolePermission{
-					RoleId:       memberRole.Id,
-					PermissionId: permission.Id,
-					CreatedAt:    time.Now(),
-				}
-
-				if err := s.DB.Create(&rolePermission).Error; err != nil {
-					return err
-				}
-			}
-		}
+	if err := query.Find(&resourcePermissions).Error; err != nil {
+		return nil, err
 	}
-
-	// Get the external role
-	var externalRole Role
-	if err := s.DB.Where("name = ? AND is_system = ?", "External", true).First(&externalRole).Error; err != nil {
-		return err
+	for _, rp := range resourcePermissions {
+		if rp.ResourceId != "" && rp.UserId == uint(userId) {
+			effective.Resources = append(effective.Resources, ResourceGrant{
+				ResourceType: rp.ResourceType,
+				ResourceId:   rp.ResourceId,
+				Action:       rp.Action,
+			})
+			continue
+		}
+		keys[rp.ResourceType+":"+rp.Action] = true
 	}
 
-	// Define external permissions
-	externalPermissionTypes := map[string][]string{
-		"user":    {"read", "list"},
-		"media":   {"read", "list"},
-		"profile": {"read"},
+	// Resource access rules apply to the whole resource type
+	var accesses []ResourceAccess
+	if err := s.DB.WithContext(ctx).Where("member_id = ?", member.Id).Find(&accesses).Error; err != nil {
+		return nil, err
 	}
-
-	// Assign external permissions
-	for resourceType, actions := range externalPermissionTypes {
-		for _, action := range actions {
-			var permission Permission
-			if err := s.DB.Where("resource_type = ? AND action = ?", resourceType, action).First(&permission).Error; err != nil {
-				continue // Skip if permission not found
-			}
-
-			// Check if permission is already assigned
-			var count int64
-			s.DB.Model(&RolePermission{}).
-				Where("role_id = ? AND permission_id = ?", externalRole.Id, permission.Id).
-				Count(&count)
-
-			if count == 0 {
-				rolePermission := RolePermission{
-					RoleId:       externalRole.Id,
-					PermissionId: permission.Id,
-					CreatedAt:    time.Now(),
-				}
-
-				if err := s.DB.Create(&rolePermission).Error; err != nil {
-					return err
-				}
-			}
+	for _, access := range accesses {
+		switch access.AccessType {
+		case "all", "read_write":
+			keys[access.ResourceType+":"+AllPermissions] = true
+		case "read_only":
+			keys[access.ResourceType+":"+ActionRead] = true
 		}
 	}
 
-	return nil
+	for key := range keys {
+		effective.Permissions = append(effective.Permissions, key)
+	}
+	slices.Sort(effective.Permissions)
+	return effective, nil
 }
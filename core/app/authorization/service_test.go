@@ -0,0 +1,174 @@
+package authorization
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"base/core/logger"
+	"base/core/router"
+	"base/core/router/middleware"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// member mirrors organizations.Member, which this package can't import
+type member struct {
+	Id             uint `gorm:"primaryKey"`
+	OrganizationId uint64
+	UserId         uint64
+	RoleId         string
+	IsOwner        bool
+	Department     string
+	MembershipType string
+}
+
+func (member) TableName() string {
+	return "organization_members"
+}
+
+const testOrg = 10
+
+// newTestService returns a service over a database seeded with the default
+// roles and permissions
+func newTestService(t *testing.T) *AuthorizationService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "authorization.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&Role{}, &Permission{}, &RolePermission{}, &ResourcePermission{}, &ResourceAccess{}, &member{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	for _, seed := range []func(*gorm.DB) error{seedRoles, seedPermissions, seedRolePermissions} {
+		if err := seed(db); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	return NewAuthorizationService(db, logger.NewLoggerFromZap(zap.NewNop()))
+}
+
+// addMember adds userId to the test organization with the named system role
+func addMember(t *testing.T, s *AuthorizationService, userId uint64, roleName string) {
+	t.Helper()
+	var role Role
+	if err := s.DB.Where("name = ? AND is_system = ?", roleName, true).First(&role).Error; err != nil {
+		t.Fatalf("role %s: %v", roleName, err)
+	}
+	m := member{OrganizationId: testOrg, UserId: userId, RoleId: strconv.Itoa(int(role.Id))}
+	if err := s.DB.Create(&m).Error; err != nil {
+		t.Fatalf("add member: %v", err)
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	s := newTestService(t)
+	middleware.SetPermissionChecker(s)
+	t.Cleanup(func() { middleware.SetPermissionChecker(nil) })
+
+	const owner, memberUser = 1, 2
+	addMember(t, s, owner, "Owner")
+	addMember(t, s, memberUser, "Member")
+
+	// Stands in for BearerAuth and OrganizationContext
+	authenticate := func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			id, _ := strconv.Atoi(c.GetHeader("X-User"))
+			c.Set("user_id", uint(id))
+			c.Set(router.OrgIDKey, uint64(testOrg))
+			return next(c)
+		}
+	}
+	r := router.New()
+	media := r.Group("/media", authenticate)
+	media.GET("/:id", func(c *router.Context) error { return c.NoContent() }, middleware.RequirePermission("media", "read"))
+	media.PUT("/:id", func(c *router.Context) error { return c.NoContent() }, middleware.RequirePermission("media", "update"))
+
+	tests := []struct {
+		method string
+		user   int
+		status int
+	}{
+		{http.MethodPut, memberUser, http.StatusForbidden},
+		{http.MethodPut, owner, http.StatusNoContent},
+		{http.MethodGet, memberUser, http.StatusNoContent},
+		{http.MethodPut, 0, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/media/5", nil)
+		req.Header.Set("X-User", strconv.Itoa(tt.user))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s by user %d = %d, want %d", tt.method, tt.user, w.Code, tt.status)
+		}
+	}
+}
+
+func TestMemberRoleCastsRunOnSQLite(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	const owner, memberUser = 1, 2
+	addMember(t, s, owner, "Owner")
+	addMember(t, s, memberUser, "Member")
+
+	if ok, err := s.hasOwnerRole(ctx, owner, testOrg); err != nil || !ok {
+		t.Errorf("hasOwnerRole(owner) = %t, %v; want true", ok, err)
+	}
+	if ok, err := s.hasOwnerRole(ctx, memberUser, testOrg); err != nil || ok {
+		t.Errorf("hasOwnerRole(member) = %t, %v; want false", ok, err)
+	}
+
+	// The member's grants come from the role_permissions join on the cast role id
+	if ok, err := s.HasPermission(ctx, memberUser, testOrg, "media", "read"); err != nil || !ok {
+		t.Errorf("HasPermission(member, media:read) = %t, %v; want true", ok, err)
+	}
+	if ok, err := s.HasPermission(ctx, memberUser, testOrg, "media", "delete"); err != nil || ok {
+		t.Errorf("HasPermission(member, media:delete) = %t, %v; want false", ok, err)
+	}
+
+	permissions, err := s.GetUserPermissions(ctx, strconv.Itoa(memberUser))
+	if err != nil {
+		t.Fatalf("GetUserPermissions: %v", err)
+	}
+	granted := map[string]bool{}
+	for _, p := range permissions {
+		granted[p.ResourceType+":"+p.Action] = true
+	}
+	if !granted["media:read"] || granted["media:delete"] {
+		t.Errorf("GetUserPermissions = %v, want media:read without media:delete", granted)
+	}
+
+	// A grant on one resource applies only in its organization
+	grant := &ResourcePermission{UserId: memberUser, OrganizationId: testOrg, ResourceType: "media", ResourceId: "5", Action: "delete"}
+	if err := s.CreateResourcePermission(ctx, grant); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.HasResourcePermission(ctx, memberUser, testOrg, "media", "5", "delete"); err != nil || !ok {
+		t.Errorf("HasResourcePermission(granted) = %t, %v; want true", ok, err)
+	}
+	if ok, err := s.HasResourcePermission(ctx, memberUser, testOrg, "media", "6", "delete"); err != nil || ok {
+		t.Errorf("HasResourcePermission(other resource) = %t, %v; want false", ok, err)
+	}
+}
+
+func TestMemberRoleIdPerDialect(t *testing.T) {
+	tests := map[gorm.Dialector]string{
+		mysql.New(mysql.Config{}):       "CAST(om.role_id AS UNSIGNED)",
+		postgres.New(postgres.Config{}): "CAST(NULLIF(om.role_id, '') AS BIGINT)",
+		sqlite.Open(":memory:"):         "CAST(om.role_id AS INTEGER)",
+	}
+	for dialector, want := range tests {
+		s := &AuthorizationService{DB: &gorm.DB{Config: &gorm.Config{Dialector: dialector}}}
+		if got := s.memberRoleId(); got != want {
+			t.Errorf("%s: memberRoleId() = %q, want %q", dialector.Name(), got, want)
+		}
+	}
+}
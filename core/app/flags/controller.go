@@ -0,0 +1,143 @@
+package flags
+
+import (
+	"errors"
+	"net/http"
+
+	"base/core/logger"
+	"base/core/router"
+)
+
+type FlagController struct {
+	Service *FlagService
+	Logger  logger.Logger
+}
+
+func NewFlagController(service *FlagService, logger logger.Logger) *FlagController {
+	return &FlagController{
+		Service: service,
+		Logger:  logger,
+	}
+}
+
+func (c *FlagController) Routes(r *router.RouterGroup) {
+	r.GET("/flags", c.List).Doc(router.Doc{
+		Summary:  "List feature flags",
+		Tags:     []string{"Core/Flags"},
+		Response: []FeatureFlagResponse{},
+	})
+	r.POST("/flags", c.Create).Doc(router.Doc{
+		Summary:  "Create a feature flag",
+		Tags:     []string{"Core/Flags"},
+		Request:  CreateFeatureFlagRequest{},
+		Response: FeatureFlagResponse{},
+		Status:   http.StatusCreated,
+	})
+	r.GET("/flags/:id", c.Get).Doc(router.Doc{
+		Summary:  "Get a feature flag",
+		Tags:     []string{"Core/Flags"},
+		Response: FeatureFlagResponse{},
+	})
+	r.PUT("/flags/:id", c.Update).Doc(router.Doc{
+		Summary:  "Update a feature flag",
+		Tags:     []string{"Core/Flags"},
+		Request:  UpdateFeatureFlagRequest{},
+		Response: FeatureFlagResponse{},
+	})
+	r.DELETE("/flags/:id", c.Delete).Doc(router.Doc{
+		Summary: "Delete a feature flag",
+		Tags:    []string{"Core/Flags"},
+		Status:  http.StatusNoContent,
+	})
+}
+
+// List handles GET /flags
+func (c *FlagController) List(ctx *router.Context) error {
+	flags, err := c.Service.List()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	responses := make([]*FeatureFlagResponse, 0, len(flags))
+	for i := range flags {
+		responses = append(responses, flags[i].ToResponse())
+	}
+	return ctx.JSON(http.StatusOK, responses)
+}
+
+// Create handles POST /flags
+func (c *FlagController) Create(ctx *router.Context) error {
+	var req CreateFeatureFlagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	flag, err := c.Service.Create(&req)
+	if err != nil {
+		return c.error(ctx, err)
+	}
+	return ctx.JSON(http.StatusCreated, flag.ToResponse())
+}
+
+// Get handles GET /flags/:id
+func (c *FlagController) Get(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
+	}
+
+	flag, err := c.Service.GetById(uint(id))
+	if err != nil {
+		return c.error(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, flag.ToResponse())
+}
+
+// Update handles PUT /flags/:id
+func (c *FlagController) Update(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
+	}
+
+	var req UpdateFeatureFlagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	flag, err := c.Service.Update(uint(id), &req)
+	if err != nil {
+		return c.error(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, flag.ToResponse())
+}
+
+// Delete handles DELETE /flags/:id
+func (c *FlagController) Delete(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
+	}
+
+	if err := c.Service.Delete(uint(id)); err != nil {
+		return c.error(ctx, err)
+	}
+
+	ctx.Status(http.StatusNoContent)
+	return nil
+}
+
+// error maps service errors to responses
+func (c *FlagController) error(ctx *router.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrFlagNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrFlagExists):
+		return ctx.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidName), errors.Is(err, ErrInvalidPercentage):
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.Logger.Error("Feature flag request failed", logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
@@ -0,0 +1,23 @@
+package flags
+
+import (
+	"net/http"
+
+	"base/core/router"
+)
+
+// RequireFlag answers 404 when flag is off for the request, so experimental
+// routes look absent to callers who don't have them. Put it after BearerAuth
+// and OrganizationContext for user and organization targeting to apply, e.g.
+//
+//	api.GET("/reports/v2", h.ReportsV2, middleware.BearerAuth(), flags.RequireFlag("reports-v2"))
+func RequireFlag(flag string) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			if !IsEnabled(c, flag) {
+				return c.String(http.StatusNotFound, "404 page not found")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package flags
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FeatureFlag turns a feature on for some or all callers. A disabled flag is
+// off for everyone. An enabled flag is on for the users and organizations it
+// targets, and for Percentage percent of the other callers, picked by hashing
+// the flag name with the caller's user (or organization) id so each caller
+// keeps getting the same answer.
+type FeatureFlag struct {
+	Id          uint   `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"column:name;not null;size:100;uniqueIndex"`
+	Description string `json:"description" gorm:"column:description;size:1024"`
+	Enabled     bool   `json:"enabled" gorm:"column:enabled;not null"`
+	Percentage  int    `json:"percentage" gorm:"column:percentage;not null"`
+	// Users and Organizations are comma separated ids
+	Users         string    `json:"users" gorm:"column:users;type:text"`
+	Organizations string    `json:"organizations" gorm:"column:organizations;type:text"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the FeatureFlag model
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
+
+// UserIds returns the targeted user ids
+func (f *FeatureFlag) UserIds() []uint64 {
+	return parseIds(f.Users)
+}
+
+// OrganizationIds returns the targeted organization ids
+func (f *FeatureFlag) OrganizationIds() []uint64 {
+	return parseIds(f.Organizations)
+}
+
+// ToResponse converts the flag to a response object
+func (f *FeatureFlag) ToResponse() *FeatureFlagResponse {
+	return &FeatureFlagResponse{
+		Id:            f.Id,
+		Name:          f.Name,
+		Description:   f.Description,
+		Enabled:       f.Enabled,
+		Percentage:    f.Percentage,
+		Users:         f.UserIds(),
+		Organizations: f.OrganizationIds(),
+		CreatedAt:     f.CreatedAt,
+		UpdatedAt:     f.UpdatedAt,
+	}
+}
+
+func parseIds(s string) []uint64 {
+	ids := []uint64{}
+	for _, part := range strings.Split(s, ",") {
+		if id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// joinIds stores ids sorted and without duplicates
+func joinIds(ids []uint64) string {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 {
+			parts = append(parts, strconv.FormatUint(id, 10))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// FeatureFlagResponse represents a feature flag in API responses
+type FeatureFlagResponse struct {
+	Id            uint      `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	Enabled       bool      `json:"enabled"`
+	Percentage    int       `json:"percentage"`
+	Users         []uint64  `json:"users"`
+	Organizations []uint64  `json:"organizations"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CreateFeatureFlagRequest represents the request payload for creating a flag
+type CreateFeatureFlagRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	// Percentage of untargeted callers the flag is on for; defaults to 100,
+	// or to 0 when users or organizations are targeted
+	Percentage    *int     `json:"percentage"`
+	Users         []uint64 `json:"users"`
+	Organizations []uint64 `json:"organizations"`
+}
+
+// UpdateFeatureFlagRequest represents the request payload for updating a flag
+type UpdateFeatureFlagRequest struct {
+	Description   *string  `json:"description"`
+	Enabled       *bool    `json:"enabled"`
+	Percentage    *int     `json:"percentage"`
+	Users         []uint64 `json:"users"`
+	Organizations []uint64 `json:"organizations"`
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,64 @@
+package flags
+
+import (
+	"context"
+
+	"base/core/config"
+	"base/core/logger"
+	"base/core/module"
+	"base/core/router"
+	"base/core/router/middleware"
+
+	"gorm.io/gorm"
+)
+
+type FlagModule struct {
+	module.DefaultModule
+	DB         *gorm.DB
+	Controller *FlagController
+	Service    *FlagService
+	Logger     logger.Logger
+	AdminToken string
+}
+
+// NewFlagModule creates the feature flags module and makes its service the
+// default one used by IsEnabled and RequireFlag
+func NewFlagModule(db *gorm.DB, logger logger.Logger, cfg *config.Config) module.Module {
+	service := NewFlagService(db, logger, cfg.FlagsRefreshInterval)
+	controller := NewFlagController(service, logger)
+	SetDefault(service)
+
+	return &FlagModule{
+		DB:         db,
+		Controller: controller,
+		Service:    service,
+		Logger:     logger,
+		AdminToken: cfg.AdminToken,
+	}
+}
+
+func (m *FlagModule) Routes(router *router.RouterGroup) {
+	// Flags apply to every organization, so only operators holding
+	// ADMIN_TOKEN manage them
+	m.Controller.Routes(router.Group("", middleware.AdminAuth(m.AdminToken)).Security("AdminToken"))
+}
+
+func (m *FlagModule) Migrate() error {
+	return m.DB.AutoMigrate(&FeatureFlag{})
+}
+
+func (m *FlagModule) GetModels() []any {
+	return []any{
+		&FeatureFlag{},
+	}
+}
+
+// Start loads the flags and starts refreshing them
+func (m *FlagModule) Start(ctx context.Context) error {
+	return m.Service.Start(ctx)
+}
+
+// Stop ends the refresh
+func (m *FlagModule) Stop(ctx context.Context) error {
+	return m.Service.Stop(ctx)
+}
@@ -0,0 +1,347 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"base/core/logger"
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFlagNotFound      = errors.New("feature flag not found")
+	ErrFlagExists        = errors.New("a feature flag with this name already exists")
+	ErrInvalidName       = errors.New("name may only contain lowercase letters, digits, '.', '_' and '-'")
+	ErrInvalidPercentage = errors.New("percentage must be between 0 and 100")
+)
+
+// FlagService stores feature flags and evaluates them from an in-memory
+// copy, refreshed every RefreshInterval and after each change made through
+// it, so checks don't touch the database
+type FlagService struct {
+	DB              *gorm.DB
+	Logger          logger.Logger
+	RefreshInterval time.Duration
+
+	mu    sync.RWMutex
+	flags map[string]*rule
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// rule is a loaded flag with its targeted ids parsed once, so checks don't
+// parse them again
+type rule struct {
+	FeatureFlag
+	users         map[uint64]bool
+	organizations map[uint64]bool
+}
+
+func newRule(flag FeatureFlag) *rule {
+	r := &rule{
+		FeatureFlag:   flag,
+		users:         make(map[uint64]bool),
+		organizations: make(map[uint64]bool),
+	}
+	for _, id := range flag.UserIds() {
+		r.users[id] = true
+	}
+	for _, id := range flag.OrganizationIds() {
+		r.organizations[id] = true
+	}
+	return r
+}
+
+func NewFlagService(db *gorm.DB, logger logger.Logger, refreshInterval time.Duration) *FlagService {
+	return &FlagService{
+		DB:              db,
+		Logger:          logger,
+		RefreshInterval: refreshInterval,
+		flags:           make(map[string]*rule),
+	}
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultService *FlagService
+)
+
+// SetDefault sets the service used by IsEnabled and RequireFlag. The flags
+// module sets its service when it is created.
+func SetDefault(s *FlagService) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultService = s
+}
+
+// Default returns the service set with SetDefault, or nil
+func Default() *FlagService {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultService
+}
+
+// IsEnabled reports whether flag is on for ctx using the default service.
+// It is false when there is no default service.
+func IsEnabled(ctx context.Context, flag string) bool {
+	if s := Default(); s != nil {
+		return s.IsEnabled(ctx, flag)
+	}
+	return false
+}
+
+type subjectKey struct{}
+
+type subject struct {
+	userId uint64
+	orgId  uint64
+}
+
+// WithSubject returns a context that IsEnabled evaluates for the given user
+// and organization, for checks made outside of a request, e.g. in jobs. Either
+// id may be 0.
+func WithSubject(ctx context.Context, userId, orgId uint64) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject{userId: userId, orgId: orgId})
+}
+
+// subjectOf returns the user and organization a check is made for: those of
+// WithSubject, or the authenticated user and the request's organization
+func subjectOf(ctx context.Context) subject {
+	if s, ok := ctx.Value(subjectKey{}).(subject); ok {
+		return s
+	}
+	if c, ok := ctx.(*router.Context); ok {
+		return subject{userId: uint64(c.GetUint("user_id")), orgId: c.OrgID()}
+	}
+	return subject{}
+}
+
+// IsEnabled reports whether flag is on for the user and organization of ctx.
+// Unknown flags are off. Callers without a user or organization only get
+// flags rolled out to 100%.
+func (s *FlagService) IsEnabled(ctx context.Context, flag string) bool {
+	s.mu.RLock()
+	f, ok := s.flags[flag]
+	s.mu.RUnlock()
+	if !ok || !f.Enabled {
+		return false
+	}
+
+	who := subjectOf(ctx)
+	if who.userId != 0 && f.users[who.userId] {
+		return true
+	}
+	if who.orgId != 0 && f.organizations[who.orgId] {
+		return true
+	}
+
+	switch {
+	case f.Percentage >= 100:
+		return true
+	case f.Percentage <= 0:
+		return false
+	case who.userId != 0:
+		return bucket(f.Name, "user:"+strconv.FormatUint(who.userId, 10)) < f.Percentage
+	case who.orgId != 0:
+		return bucket(f.Name, "org:"+strconv.FormatUint(who.orgId, 10)) < f.Percentage
+	}
+	return false
+}
+
+// bucket places key in one of 100 buckets for flag. Hashing the flag name
+// along with the key rolls each flag out to a different set of callers.
+func bucket(flag, key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(flag + ":" + key))
+	return int(h.Sum32() % 100)
+}
+
+// Refresh reloads the in-memory flags from the database
+func (s *FlagService) Refresh(ctx context.Context) error {
+	var flags []FeatureFlag
+	if err := s.DB.WithContext(ctx).Find(&flags).Error; err != nil {
+		return fmt.Errorf("failed to load feature flags: %w", err)
+	}
+
+	loaded := make(map[string]*rule, len(flags))
+	for _, flag := range flags {
+		loaded[flag.Name] = newRule(flag)
+	}
+
+	s.mu.Lock()
+	s.flags = loaded
+	s.mu.Unlock()
+	return nil
+}
+
+// Start loads the flags and refreshes them every RefreshInterval until Stop
+func (s *FlagService) Start(ctx context.Context) error {
+	if err := s.Refresh(ctx); err != nil {
+		return err
+	}
+	if s.RefreshInterval <= 0 {
+		return nil
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if err := s.Refresh(context.Background()); err != nil {
+					s.Logger.Error("Failed to refresh feature flags", logger.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop ends the periodic refresh
+func (s *FlagService) Stop(ctx context.Context) error {
+	if s.stop == nil {
+		return nil
+	}
+	close(s.stop)
+	s.stop = nil
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// refreshAfterChange reloads the flags so a change applies at once on this
+// instance; other instances pick it up on their next refresh
+func (s *FlagService) refreshAfterChange() {
+	if err := s.Refresh(context.Background()); err != nil {
+		s.Logger.Error("Failed to refresh feature flags", logger.String("error", err.Error()))
+	}
+}
+
+func (s *FlagService) Create(req *CreateFeatureFlagRequest) (*FeatureFlag, error) {
+	if err := validateName(req.Name); err != nil {
+		return nil, err
+	}
+
+	percentage := 100
+	if len(req.Users) > 0 || len(req.Organizations) > 0 {
+		percentage = 0
+	}
+	if req.Percentage != nil {
+		percentage = *req.Percentage
+	}
+	if percentage < 0 || percentage > 100 {
+		return nil, ErrInvalidPercentage
+	}
+
+	var count int64
+	if err := s.DB.Model(&FeatureFlag{}).Where("name = ?", req.Name).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrFlagExists
+	}
+
+	flag := FeatureFlag{
+		Name:          req.Name,
+		Description:   req.Description,
+		Enabled:       req.Enabled,
+		Percentage:    percentage,
+		Users:         joinIds(req.Users),
+		Organizations: joinIds(req.Organizations),
+	}
+	if err := s.DB.Create(&flag).Error; err != nil {
+		return nil, fmt.Errorf("failed to create feature flag: %w", err)
+	}
+
+	s.refreshAfterChange()
+	return &flag, nil
+}
+
+func (s *FlagService) Update(id uint, req *UpdateFeatureFlagRequest) (*FeatureFlag, error) {
+	flag, err := s.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		flag.Description = *req.Description
+	}
+	if req.Enabled != nil {
+		flag.Enabled = *req.Enabled
+	}
+	if req.Percentage != nil {
+		if *req.Percentage < 0 || *req.Percentage > 100 {
+			return nil, ErrInvalidPercentage
+		}
+		flag.Percentage = *req.Percentage
+	}
+	if req.Users != nil {
+		flag.Users = joinIds(req.Users)
+	}
+	if req.Organizations != nil {
+		flag.Organizations = joinIds(req.Organizations)
+	}
+
+	if err := s.DB.Save(flag).Error; err != nil {
+		return nil, fmt.Errorf("failed to update feature flag: %w", err)
+	}
+
+	s.refreshAfterChange()
+	return flag, nil
+}
+
+func (s *FlagService) Delete(id uint) error {
+	result := s.DB.Delete(&FeatureFlag{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFlagNotFound
+	}
+
+	s.refreshAfterChange()
+	return nil
+}
+
+func (s *FlagService) GetById(id uint) (*FeatureFlag, error) {
+	var flag FeatureFlag
+	if err := s.DB.First(&flag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFlagNotFound
+		}
+		return nil, err
+	}
+	return &flag, nil
+}
+
+func (s *FlagService) List() ([]FeatureFlag, error) {
+	var flags []FeatureFlag
+	if err := s.DB.Order("name").Find(&flags).Error; err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+func validateName(name string) error {
+	if name == "" || len(name) > 100 || strings.Trim(name, "abcdefghijklmnopqrstuvwxyz0123456789._-") != "" {
+		return ErrInvalidName
+	}
+	return nil
+}
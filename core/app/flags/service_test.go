@@ -0,0 +1,222 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestService(t *testing.T) *FlagService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "flags.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&FeatureFlag{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return NewFlagService(db, logger.NewLoggerFromZap(zap.NewNop()), 0)
+}
+
+func create(t *testing.T, s *FlagService, req *CreateFeatureFlagRequest) *FeatureFlag {
+	t.Helper()
+	flag, err := s.Create(req)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return flag
+}
+
+func percent(p int) *int { return &p }
+
+// enabledUsers returns which of users 1 to 1000 have flag on
+func enabledUsers(s *FlagService, flag string) map[uint64]bool {
+	on := make(map[uint64]bool)
+	for id := uint64(1); id <= 1000; id++ {
+		if s.IsEnabled(WithSubject(context.Background(), id, 0), flag) {
+			on[id] = true
+		}
+	}
+	return on
+}
+
+func TestPercentageRollout(t *testing.T) {
+	s := newTestService(t)
+	editorFlag := create(t, s, &CreateFeatureFlagRequest{Name: "new-editor", Enabled: true, Percentage: percent(30)})
+	create(t, s, &CreateFeatureFlagRequest{Name: "new-search", Enabled: true, Percentage: percent(30)})
+
+	editor := enabledUsers(s, "new-editor")
+	if len(editor) < 250 || len(editor) > 350 {
+		t.Errorf("new-editor is on for %d of 1000 users, want about 300", len(editor))
+	}
+	// The same users keep getting it, also after a reload
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	again := enabledUsers(s, "new-editor")
+	for id := range editor {
+		if !again[id] {
+			t.Fatalf("user %d lost new-editor between checks", id)
+		}
+	}
+	if len(again) != len(editor) {
+		t.Errorf("new-editor went from %d to %d users", len(editor), len(again))
+	}
+
+	// Each flag rolls out to its own set of users
+	search := enabledUsers(s, "new-search")
+	shared := 0
+	for id := range search {
+		if editor[id] {
+			shared++
+		}
+	}
+	if shared == len(search) {
+		t.Error("two flags at 30% picked the same users")
+	}
+
+	// Raising the percentage keeps the users who already had it
+	if _, err := s.Update(editorFlag.Id, &UpdateFeatureFlagRequest{Percentage: percent(60)}); err != nil {
+		t.Fatal(err)
+	}
+	raised := enabledUsers(s, "new-editor")
+	for id := range editor {
+		if !raised[id] {
+			t.Fatalf("user %d lost new-editor when it went to 60%%", id)
+		}
+	}
+
+	// Organizations are bucketed when there is no user; anonymous callers
+	// only get flags at 100%
+	orgs := 0
+	for id := uint64(1); id <= 1000; id++ {
+		if s.IsEnabled(WithSubject(context.Background(), 0, id), "new-search") {
+			orgs++
+		}
+	}
+	if orgs < 250 || orgs > 350 {
+		t.Errorf("new-search is on for %d of 1000 organizations, want about 300", orgs)
+	}
+	if s.IsEnabled(context.Background(), "new-search") {
+		t.Error("a 30% flag is on for an anonymous caller")
+	}
+}
+
+func TestPercentageBounds(t *testing.T) {
+	s := newTestService(t)
+	create(t, s, &CreateFeatureFlagRequest{Name: "everyone", Enabled: true})
+	create(t, s, &CreateFeatureFlagRequest{Name: "nobody", Enabled: true, Percentage: percent(0)})
+	create(t, s, &CreateFeatureFlagRequest{Name: "off", Enabled: false})
+
+	if got := len(enabledUsers(s, "everyone")); got != 1000 || !s.IsEnabled(context.Background(), "everyone") {
+		t.Errorf("a flag at 100%% is on for %d of 1000 users", got)
+	}
+	for _, flag := range []string{"nobody", "off", "missing"} {
+		if got := len(enabledUsers(s, flag)); got != 0 {
+			t.Errorf("%s is on for %d users", flag, got)
+		}
+	}
+	if _, err := s.Create(&CreateFeatureFlagRequest{Name: "too-many", Percentage: percent(101)}); !errors.Is(err, ErrInvalidPercentage) {
+		t.Errorf("Create at 101%% = %v, want ErrInvalidPercentage", err)
+	}
+}
+
+func TestTargeting(t *testing.T) {
+	s := newTestService(t)
+	flag := create(t, s, &CreateFeatureFlagRequest{Name: "beta", Enabled: true, Users: []uint64{7, 9, 7}, Organizations: []uint64{3}})
+	if flag.Percentage != 0 || flag.Users != "7,9" {
+		t.Errorf("targeted flag stored as %+v, want 0%% for users 7,9", flag)
+	}
+
+	tests := []struct {
+		userId, orgId uint64
+		want          bool
+	}{
+		{7, 0, true},
+		{9, 5, true},
+		{8, 0, false},
+		{8, 3, true},
+		{0, 3, true},
+		{0, 4, false},
+	}
+	for _, tt := range tests {
+		if got := s.IsEnabled(WithSubject(context.Background(), tt.userId, tt.orgId), "beta"); got != tt.want {
+			t.Errorf("user %d in organization %d = %v, want %v", tt.userId, tt.orgId, got, tt.want)
+		}
+	}
+
+	// Changes through the service apply at once
+	if _, err := s.Update(flag.Id, &UpdateFeatureFlagRequest{Users: []uint64{8}}); err != nil {
+		t.Fatal(err)
+	}
+	if s.IsEnabled(WithSubject(context.Background(), 7, 0), "beta") || !s.IsEnabled(WithSubject(context.Background(), 8, 0), "beta") {
+		t.Error("retargeting users didn't apply")
+	}
+	disabled := false
+	if _, err := s.Update(flag.Id, &UpdateFeatureFlagRequest{Enabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+	if s.IsEnabled(WithSubject(context.Background(), 8, 3), "beta") {
+		t.Error("a disabled flag is on for a targeted user")
+	}
+}
+
+func TestRequireFlag(t *testing.T) {
+	s := newTestService(t)
+	SetDefault(s)
+	t.Cleanup(func() { SetDefault(nil) })
+	flag := create(t, s, &CreateFeatureFlagRequest{Name: "reports-v2", Enabled: false, Users: []uint64{7}})
+
+	// The X-User header stands in for BearerAuth
+	r := router.New()
+	r.Use(func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			if id, err := strconv.Atoi(c.GetHeader("X-User")); err == nil {
+				c.Set("user_id", uint(id))
+			}
+			return next(c)
+		}
+	})
+	r.GET("/reports/v2", func(c *router.Context) error { return c.NoContent() }, RequireFlag("reports-v2"))
+	r.GET("/reports/v3", func(c *router.Context) error { return c.NoContent() }, RequireFlag("reports-v3"))
+	get := func(path, user string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := get("/reports/v2", "7"); code != http.StatusNotFound {
+		t.Errorf("disabled flag = %d, want 404", code)
+	}
+	enabled := true
+	if _, err := s.Update(flag.Id, &UpdateFeatureFlagRequest{Enabled: &enabled}); err != nil {
+		t.Fatal(err)
+	}
+	if code := get("/reports/v2", "7"); code != http.StatusNoContent {
+		t.Errorf("targeted user = %d, want the route", code)
+	}
+	if code := get("/reports/v2", "8"); code != http.StatusNotFound {
+		t.Errorf("untargeted user = %d, want 404", code)
+	}
+	if code := get("/reports/v3", "7"); code != http.StatusNotFound {
+		t.Errorf("unknown flag = %d, want 404", code)
+	}
+
+	SetDefault(nil)
+	if code := get("/reports/v2", "7"); code != http.StatusNotFound {
+		t.Errorf("without a flag service = %d, want 404", code)
+	}
+}
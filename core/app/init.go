@@ -3,9 +3,14 @@ package app
 import (
 	"base/core/app/authentication"
 	"base/core/app/authorization"
+	"base/core/app/flags"
 	"base/core/app/media"
 	"base/core/app/oauth"
+	"base/core/app/organizations"
 	"base/core/app/profile"
+	"base/core/app/webhooks"
+	"base/core/events"
+	"base/core/jobs"
 	"base/core/module"
 	"base/core/scheduler"
 	"base/core/translation"
@@ -20,11 +25,26 @@ func (cm *CoreModules) GetCoreModules(deps module.Dependencies) map[string]modul
 	modules := make(map[string]module.Module)
 
 	// Core modules - essential system functionality
+	modules["jobs"] = jobs.NewJobsModule(
+		deps.DB,
+		deps.Logger,
+		deps.Config,
+	)
+
+	modules["events"] = events.NewEventsModule(
+		deps.DB,
+		deps.Emitter,
+		deps.Logger,
+		deps.Config,
+	)
+
 	modules["users"] = profile.NewUserModule(
 		deps.DB,
 		deps.Router,
 		deps.Logger,
 		deps.Storage,
+		deps.Emitter,
+		deps.Config,
 	)
 
 	modules["media"] = media.NewMediaModule(
@@ -41,6 +61,7 @@ func (cm *CoreModules) GetCoreModules(deps module.Dependencies) map[string]modul
 		deps.EmailSender,
 		deps.Logger,
 		deps.Emitter,
+		deps.Config,
 	)
 
 	modules["oauth"] = oauth.NewOAuthModule(
@@ -69,6 +90,28 @@ func (cm *CoreModules) GetCoreModules(deps module.Dependencies) map[string]modul
 		deps.Router,
 		deps.Logger,
 		deps.Emitter,
+		deps.Config,
+	)
+
+	modules["webhooks"] = webhooks.NewWebhookModule(
+		deps.DB,
+		deps.Emitter,
+		deps.Logger,
+		deps.Config,
+	)
+
+	modules["organizations"] = organizations.NewOrganizationModule(
+		deps.DB,
+		deps.EmailSender,
+		deps.Emitter,
+		deps.Logger,
+		deps.Config,
+	)
+
+	modules["flags"] = flags.NewFlagModule(
+		deps.DB,
+		deps.Logger,
+		deps.Config,
 	)
 
 	return modules
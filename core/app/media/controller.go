@@ -1,11 +1,20 @@
 package media
 
 import (
+	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"time"
 
+	"base/core/base"
+	"base/core/export"
 	"base/core/logger"
+	"base/core/query"
 	"base/core/router"
+	"base/core/router/middleware"
 	"base/core/storage"
 )
 
@@ -23,47 +32,146 @@ func NewMediaController(service *MediaService, storage *storage.ActiveStorage, l
 	}
 }
 
-func (c *MediaController) Routes(router *router.RouterGroup) {
+// CacheTag groups the cached media responses; cache.Invalidate(CacheTag)
+// drops them after changes made outside these routes
+const CacheTag = "media"
+
+// cacheTTL bounds how long a cached media response is served
+const cacheTTL = time.Minute
+
+func (c *MediaController) Routes(r *router.RouterGroup) {
+	// Exports and downloads stream, so they bypass the response cache and
+	// the request deadline, which would cut them off after the 200 is sent
+	streams := r.Group("").Set(middleware.TimeoutKey, time.Duration(0))
+	streams.GET("/media/export", c.Export).Doc(router.Doc{
+		Summary:     "Export media items",
+		Description: "Streams every media item matching the list filters as CSV (format=csv, the default) or JSON (format=json)",
+		Tags:        []string{"Core/Media"},
+	})
+
+	// Reads are cached; successful writes through these routes drop them.
+	// Writes with an Idempotency-Key are safe to retry.
+	r = r.Group("", middleware.Cache(cacheTTL, nil, CacheTag), middleware.Idempotency(nil))
+
+	// Routes taking a file accept larger bodies, sent as forms
+	uploads := r.Group("").
+		Set(middleware.BodyLimitKey, middleware.UploadBodyLimit()).
+		Set(middleware.ContentTypesKey, []string{"multipart/form-data", "application/x-www-form-urlencoded"})
+
 	// Main CRUD endpoints
-	router.GET("/media", c.List) // Paginated list
-	router.POST("/media", c.Create)
+	r.GET("/media", c.List).Doc(router.Doc{
+		Summary:     "List media items",
+		Description: "Paged with page and limit; q searches name and description, filter[field] and sort narrow and order the list, trashed=only|with includes trashed items",
+		Tags:        []string{"Core/Media"},
+		Response:    []MediaListResponse{},
+	})
+	uploads.POST("/media", c.Create).Doc(router.Doc{
+		Summary:     "Create a media item",
+		Description: "Sent as a form with name, type and description fields and an optional file",
+		Tags:        []string{"Core/Media"},
+		Response:    MediaResponse{},
+		Status:      http.StatusCreated,
+	})
 
 	// Specific endpoints (must come before :id routes)
-	router.GET("/media/all", c.ListAll) // Unpaginated list
+	r.GET("/media/all", c.ListAll).Doc(router.Doc{
+		Summary:     "List all media items",
+		Description: "Takes the same q, filter, sort and trashed parameters as the paginated list",
+		Tags:        []string{"Core/Media"},
+		Response:    []MediaListResponse{},
+	})
+	r.POST("/media/bulk", c.BulkCreate).Doc(router.Doc{
+		Summary:     "Create media items in bulk",
+		Description: "Creates up to BULK_MAX_ITEMS media items, without files, in one transaction. If any item fails nothing is created and the response reports each item.",
+		Tags:        []string{"Core/Media"},
+		Request:     []CreateMediaRequest{},
+		Response:    base.BulkResponse{},
+	})
+	r.PATCH("/media/bulk", c.BulkUpdate).Doc(router.Doc{
+		Summary:     "Update media items in bulk",
+		Description: "Updates up to BULK_MAX_ITEMS media items in one transaction. If any item fails nothing is updated and the response reports each item.",
+		Tags:        []string{"Core/Media"},
+		Request:     []BulkUpdateMediaRequest{},
+		Response:    base.BulkResponse{},
+	})
+	r.DELETE("/media/bulk", c.BulkDelete).Doc(router.Doc{
+		Summary:     "Delete media items in bulk",
+		Description: "Moves up to BULK_MAX_ITEMS media items to the trash in one transaction. If any id is missing nothing is deleted and the response reports each item.",
+		Tags:        []string{"Core/Media"},
+		Request:     []uint{},
+		Response:    base.BulkResponse{},
+	})
 
 	// Parameterized routes (must come last)
-	router.GET("/media/:id", c.Get)
-	router.PUT("/media/:id", c.Update)
-	router.DELETE("/media/:id", c.Delete)
+	r.GET("/media/:id", c.Get).Doc(router.Doc{
+		Summary:  "Get a media item",
+		Tags:     []string{"Core/Media"},
+		Response: MediaResponse{},
+	})
+	uploads.PUT("/media/:id", c.Update).Doc(router.Doc{
+		Summary:     "Update a media item",
+		Description: "Sent as a form; updates the name, type and description fields and, when a file is sent, the file",
+		Tags:        []string{"Core/Media"},
+		Response:    MediaResponse{},
+	})
+	r.DELETE("/media/:id", c.Delete).Doc(router.Doc{
+		Summary:     "Delete a media item",
+		Description: "Moves the media item to the trash; its file is kept until it is deleted permanently",
+		Tags:        []string{"Core/Media"},
+		Status:      http.StatusNoContent,
+	})
+	r.POST("/media/:id/restore", c.Restore).Doc(router.Doc{
+		Summary:  "Restore a media item from the trash",
+		Tags:     []string{"Core/Media"},
+		Response: MediaResponse{},
+	})
+
+	// File management endpoints; downloads validate with the file's checksum
+	streams.GET("/media/:id/file", c.Download).Doc(router.Doc{
+		Summary:     "Download a media item's file",
+		Description: "Streams the attached file. The ETag is the file's SHA-256, so a matching If-None-Match is answered with 304.",
+		Tags:        []string{"Core/Media"},
+	})
+	uploads.PUT("/media/:id/file", c.UpdateFile).Doc(router.Doc{
+		Summary:     "Replace a media item's file",
+		Description: "Sent as a form with the new file in the file field",
+		Tags:        []string{"Core/Media"},
+		Response:    MediaResponse{},
+	})
+	r.DELETE("/media/:id/file", c.RemoveFile).Doc(router.Doc{
+		Summary:  "Remove a media item's file",
+		Tags:     []string{"Core/Media"},
+		Response: MediaResponse{},
+	})
+}
 
-	// File management endpoints
-	router.PUT("/media/:id/file", c.UpdateFile)
-	router.DELETE("/media/:id/file", c.RemoveFile)
+// ForceRoutes registers permanent deletion; guard must check the caller's
+// media delete permission
+func (c *MediaController) ForceRoutes(r *router.RouterGroup, guard ...router.MiddlewareFunc) {
+	guard = append(guard, middleware.Cache(cacheTTL, nil, CacheTag))
+	r.DELETE("/media/:id/force", c.ForceDelete, guard...).Doc(router.Doc{
+		Summary:     "Permanently delete a media item",
+		Description: "Deletes the media item, trashed or not, and its stored file. Requires the media delete permission in the Base-Orgid organization.",
+		Tags:        []string{"Core/Media"},
+		Status:      http.StatusNoContent,
+	})
 }
 
-// Create godoc
-// @Summary Create a new media item
-// @Description Create a new media item with optional file upload
-// @Tags Core/Media
-// @Accept multipart/form-data
-// @Produce json
-// @Param name formData string true "Media name"
-// @Param type formData string true "Media type"
-// @Param description formData string false "Media description"
-// @Param file formData file false "Media file"
-// @Success 201 {object} MediaResponse
-// @Router /media [post]
-// @Security ApiKeyAuth
-// @Security BearerAuth
+// Create handles POST /media
 func (c *MediaController) Create(ctx *router.Context) error {
 	var req CreateMediaRequest
 	if err := ctx.ShouldBind(&req); err != nil {
+		if ctx.BodyTooLarge(err) {
+			return nil
+		}
 		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
 	// Handle file upload
 	if file, err := ctx.FormFile("file"); err == nil {
 		req.File = file
+	} else if ctx.BodyTooLarge(err) {
+		return nil
 	}
 
 	item, err := c.Service.Create(&req)
@@ -74,26 +182,18 @@ func (c *MediaController) Create(ctx *router.Context) error {
 	return ctx.JSON(http.StatusCreated, item.ToResponse())
 }
 
-// UpdateFile godoc
-// @Summary Update media file
-// @Description Update the file attached to a media item
-// @Tags Core/Media
-// @Accept multipart/form-data
-// @Produce json
-// @Param id path int true "Media Id"
-// @Param file formData file true "Media file"
-// @Success 200 {object} MediaResponse
-// @Router /media/{id}/file [put]
-// @Security ApiKeyAuth
-// @Security BearerAuth
+// UpdateFile handles PUT /media/:id/file
 func (c *MediaController) UpdateFile(ctx *router.Context) error {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
-	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid id parameter"})
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
 	}
 
 	file, err := ctx.FormFile("file")
 	if err != nil {
+		if ctx.BodyTooLarge(err) {
+			return nil
+		}
 		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "file is required"})
 	}
 
@@ -105,20 +205,11 @@ func (c *MediaController) UpdateFile(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, item.ToResponse())
 }
 
-// RemoveFile godoc
-// @Summary Remove media file
-// @Description Remove the file attached to a media item
-// @Tags Core/Media
-// @Produce json
-// @Param id path int true "Media Id"
-// @Success 200 {object} MediaResponse
-// @Router /media/{id}/file [delete]
-// @Security ApiKeyAuth
-// @Security BearerAuth
+// RemoveFile handles DELETE /media/:id/file
 func (c *MediaController) RemoveFile(ctx *router.Context) error {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
-	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid id parameter"})
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
 	}
 
 	item, err := c.Service.RemoveFile(ctx, uint(id))
@@ -129,35 +220,26 @@ func (c *MediaController) RemoveFile(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, item.ToResponse())
 }
 
-// Update godoc
-// @Summary Update a media item
-// @Description Update a media item's details and optionally its file
-// @Tags Core/Media
-// @Accept multipart/form-data
-// @Produce json
-// @Param id path int true "Media Id"
-// @Param name formData string false "Media name"
-// @Param type formData string false "Media type"
-// @Param description formData string false "Media description"
-// @Param file formData file false "Media file"
-// @Success 200 {object} MediaResponse
-// @Router /media/{id} [put]
-// @Security ApiKeyAuth
-// @Security BearerAuth
+// Update handles PUT /media/:id
 func (c *MediaController) Update(ctx *router.Context) error {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
-	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid id parameter"})
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
 	}
 
 	var req UpdateMediaRequest
 	if err := ctx.ShouldBind(&req); err != nil {
+		if ctx.BodyTooLarge(err) {
+			return nil
+		}
 		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
 	// Handle file upload
 	if file, err := ctx.FormFile("file"); err == nil {
 		req.File = file
+	} else if ctx.BodyTooLarge(err) {
+		return nil
 	}
 
 	item, err := c.Service.Update(uint(id), &req)
@@ -168,20 +250,11 @@ func (c *MediaController) Update(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, item.ToResponse())
 }
 
-// Delete godoc
-// @Summary Delete a media item
-// @Description Delete a media item and its associated file
-// @Tags Core/Media
-// @Produce json
-// @Param id path int true "Media Id"
-// @Success 204 "No Content"
-// @Router /media/{id} [delete]
-// @Security ApiKeyAuth
-// @Security BearerAuth
+// Delete handles DELETE /media/:id
 func (c *MediaController) Delete(ctx *router.Context) error {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
-	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid id parameter"})
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
 	}
 
 	if err := c.Service.Delete(uint(id)); err != nil {
@@ -192,20 +265,96 @@ func (c *MediaController) Delete(ctx *router.Context) error {
 	return nil
 }
 
-// Get godoc
-// @Summary Get a media item
-// @Description Get a media item by Id
-// @Tags Core/Media
-// @Produce json
-// @Param id path int true "Media Id"
-// @Success 200 {object} MediaResponse
-// @Router /media/{id} [get]
-// @Security ApiKeyAuth
-// @Security BearerAuth
-func (c *MediaController) Get(ctx *router.Context) error {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+// Restore handles POST /media/:id/restore
+func (c *MediaController) Restore(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
+	}
+
+	item, err := c.Service.Restore(uint(id))
+	if errors.Is(err, ErrNotTrashed) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, item.ToResponse())
+}
+
+// ForceDelete handles DELETE /media/:id/force
+func (c *MediaController) ForceDelete(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
+	}
+
+	err := c.Service.ForceDelete(uint(id))
+	if errors.Is(err, ErrNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx.Status(http.StatusNoContent)
+	return nil
+}
+
+// BulkCreate handles POST /media/bulk
+func (c *MediaController) BulkCreate(ctx *router.Context) error {
+	var reqs []CreateMediaRequest
+	if err := ctx.ShouldBindJSON(&reqs); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	result, err := c.Service.BulkCreate(reqs)
+	return respondBulk(ctx, result, err)
+}
+
+// BulkUpdate handles PATCH /media/bulk
+func (c *MediaController) BulkUpdate(ctx *router.Context) error {
+	var reqs []BulkUpdateMediaRequest
+	if err := ctx.ShouldBindJSON(&reqs); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	result, err := c.Service.BulkUpdate(reqs)
+	return respondBulk(ctx, result, err)
+}
+
+// BulkDelete handles DELETE /media/bulk
+func (c *MediaController) BulkDelete(ctx *router.Context) error {
+	var ids []uint
+	if err := ctx.ShouldBindJSON(&ids); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	result, err := c.Service.BulkDelete(ids)
+	return respondBulk(ctx, result, err)
+}
+
+// respondBulk answers 200 for a committed batch and 422 for one that was
+// rolled back
+func respondBulk(ctx *router.Context, result *base.BulkResponse, err error) error {
+	if errors.Is(err, base.ErrEmptyBatch) || errors.Is(err, base.ErrBatchTooLarge) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid id parameter"})
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if !result.Committed {
+		return ctx.JSON(http.StatusUnprocessableEntity, result)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// Get handles GET /media/:id
+func (c *MediaController) Get(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
 	}
 
 	item, err := c.Service.GetById(uint(id))
@@ -216,34 +365,54 @@ func (c *MediaController) Get(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, item.ToResponse())
 }
 
-// List godoc
-// @Summary List media items
-// @Description Get a paginated list of media items
-// @Tags Core/Media
-// @Produce json
-// @Param page query int false "Page number"
-// @Param limit query int false "Items per page"
-// @Success 200 {object} types.PaginatedResponse
-// @Router /media [get]
-// @Security ApiKeyAuth
-// @Security BearerAuth
-func (c *MediaController) List(ctx *router.Context) error {
-	page := 1
-	limit := 10
+// Download handles GET /media/:id/file
+func (c *MediaController) Download(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
+	}
 
-	if pageStr := ctx.Query("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
+	item, err := c.Service.GetById(uint(id))
+	if err != nil || item.File == nil {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "media file not found"})
 	}
 
-	if limitStr := ctx.Query("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
+	if item.File.Checksum != "" {
+		tag := `"` + item.File.Checksum + `"`
+		ctx.SetHeader("ETag", tag)
+		if middleware.ETagMatches(ctx.GetHeader("If-None-Match"), tag) {
+			ctx.Status(http.StatusNotModified)
+			return nil
 		}
 	}
 
-	result, err := c.Service.GetAll(&page, &limit)
+	file, err := c.Storage.Open(ctx.Request.Context(), item.File)
+	if err != nil {
+		c.Logger.Error("Failed to open media file", logger.Uint("id", uint(id)), logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to open file"})
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(filepath.Ext(item.File.Filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	ctx.SetHeader("Content-Type", contentType)
+	ctx.SetHeader("Content-Length", strconv.FormatInt(item.File.Size, 10))
+	ctx.SetHeader("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": item.File.Filename}))
+	ctx.Status(http.StatusOK)
+	_, err = io.Copy(ctx.Writer, file)
+	return err
+}
+
+// List handles GET /media
+func (c *MediaController) List(ctx *router.Context) error {
+	params, err := query.Parse(ctx.Request.URL.Query(), &Media{})
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	result, err := c.Service.GetAll(params)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
@@ -251,17 +420,20 @@ func (c *MediaController) List(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, result)
 }
 
-// ListAll godoc
-// @Summary List all media items
-// @Description Get an unpaginated list of all media items
-// @Tags Core/Media
-// @Produce json
-// @Success 200 {array} MediaListResponse
-// @Router /media/all [get]
-// @Security ApiKeyAuth
-// @Security BearerAuth
+// Export handles GET /media/export
+func (c *MediaController) Export(ctx *router.Context) error {
+	return export.Respond(ctx, c.Service.DB, &Media{}, "media")
+}
+
+// ListAll handles GET /media/all
 func (c *MediaController) ListAll(ctx *router.Context) error {
-	result, err := c.Service.GetAll(nil, nil)
+	params, err := query.Parse(ctx.Request.URL.Query(), &Media{})
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	params.Limit = 0
+
+	result, err := c.Service.GetAll(params)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
@@ -0,0 +1,74 @@
+package media
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+)
+
+func TestListPagination(t *testing.T) {
+	s, _ := newTestService(t)
+	for i := range 3 {
+		if _, err := s.Create(&CreateMediaRequest{Name: fmt.Sprintf("item %d", i), Type: "image"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := NewMediaController(s, nil, logger.NewLoggerFromZap(zap.NewNop()))
+	r := router.New()
+	r.GET("/media", c.List)
+
+	list := func(rawQuery string) (int, []any, map[string]any) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/media?"+rawQuery, nil))
+		var body struct {
+			Data       []any          `json:"data"`
+			Pagination map[string]any `json:"pagination"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body.Data, body.Pagination
+	}
+
+	if status, items, page := list(""); status != http.StatusOK || len(items) != 3 || page["page_size"] != 20.0 {
+		t.Errorf("defaults = %d, %d items, %v", status, len(items), page)
+	}
+	if _, items, page := list("page=2&limit=2"); len(items) != 1 || page["page"] != 2.0 {
+		t.Errorf("page 2 of 2 = %d items, %v", len(items), page)
+	}
+	if _, _, page := list("limit=1000000"); page["page_size"] != 100.0 {
+		t.Errorf("limit=1000000 = %v, want it lowered to 100", page)
+	}
+	for _, rawQuery := range []string{"page=-1", "limit=abc", "page=0"} {
+		if status, _, _ := list(rawQuery); status != http.StatusBadRequest {
+			t.Errorf("%s = %d, want 400", rawQuery, status)
+		}
+	}
+}
+
+func TestListRejectsUnknownQueryParams(t *testing.T) {
+	s, _ := newTestService(t)
+	c := NewMediaController(s, nil, logger.NewLoggerFromZap(zap.NewNop()))
+	r := router.New()
+	r.GET("/media", c.List)
+
+	tests := map[string]int{
+		"filter[path]=x":        http.StatusBadRequest,
+		"filter[name][regex]=x": http.StatusBadRequest,
+		"sort=path":             http.StatusBadRequest,
+		"fields=path":           http.StatusBadRequest,
+		"sort=name,-type":       http.StatusOK,
+	}
+	for rawQuery, status := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/media?"+rawQuery, nil))
+		if w.Code != status {
+			t.Errorf("%s = %d %s, want %d", rawQuery, w.Code, w.Body.String(), status)
+		}
+	}
+}
@@ -36,6 +36,21 @@ func (item *Media) GetModelName() string {
 	return "media"
 }
 
+// SearchableFields returns the columns matched by ?q= on the list endpoint
+func (item *Media) SearchableFields() []string {
+	return []string{"name", "description"}
+}
+
+// FilterableFields returns the columns allowed in filter[...] on the list endpoint
+func (item *Media) FilterableFields() []string {
+	return []string{"name", "type", "created_at", "updated_at"}
+}
+
+// SortableFields returns the columns allowed in sort= on the list endpoint
+func (item *Media) SortableFields() []string {
+	return []string{"id", "name", "type", "created_at", "updated_at"}
+}
+
 // Preload preloads all the model's relationships
 func (item *Media) Preload(db *gorm.DB) *gorm.DB {
 	return db.Preload("File")
@@ -66,10 +81,10 @@ type MediaResponse struct {
 
 // CreateMediaRequest represents the request payload for creating a Media
 type CreateMediaRequest struct {
-	Name        string                `form:"name" binding:"required"`
-	Type        string                `form:"type" binding:"required"`
-	Description string                `form:"description"`
-	File        *multipart.FileHeader `form:"file"`
+	Name        string                `form:"name" json:"name" binding:"required"`
+	Type        string                `form:"type" json:"type" binding:"required"`
+	Description string                `form:"description" json:"description"`
+	File        *multipart.FileHeader `form:"file" json:"-"`
 }
 
 // UpdateMediaRequest represents the request payload for updating a Media
@@ -80,6 +95,15 @@ type UpdateMediaRequest struct {
 	File        *multipart.FileHeader `form:"file"`
 }
 
+// BulkUpdateMediaRequest is one item of a bulk update; fields left out are
+// unchanged
+type BulkUpdateMediaRequest struct {
+	Id          uint    `json:"id" binding:"required"`
+	Name        *string `json:"name"`
+	Type        *string `json:"type"`
+	Description *string `json:"description"`
+}
+
 // ToListResponse converts the model to a list response
 func (item *Media) ToListResponse() *MediaListResponse {
 	return &MediaListResponse{
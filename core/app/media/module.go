@@ -5,6 +5,7 @@ import (
 	"base/core/logger"
 	"base/core/module"
 	"base/core/router"
+	"base/core/router/middleware"
 	"base/core/storage"
 
 	"gorm.io/gorm"
@@ -45,6 +46,11 @@ func NewMediaModule(
 func (m *MediaModule) Routes(router *router.RouterGroup) {
 	m.Logger.Info("Registering media module routes")
 	m.Controller.Routes(router)
+	m.Controller.ForceRoutes(router.Group("").Security("BearerAuth"),
+		middleware.BearerAuth(),
+		middleware.OrganizationContext(m.DB),
+		middleware.RequirePermission("media", "delete"),
+	)
 	m.Logger.Info("Media module routes registered")
 }
 
@@ -2,19 +2,28 @@ package media
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"mime/multipart"
 
+	"base/core/base"
 	"base/core/emitter"
 	"base/core/logger"
+	"base/core/query"
 	"base/core/storage"
 	"base/core/types"
+	"base/core/validator"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+var (
+	ErrNotFound   = errors.New("media not found")
+	ErrNotTrashed = errors.New("media not found in trash")
+)
+
 type MediaService struct {
 	DB            *gorm.DB
 	Emitter       *emitter.Emitter
@@ -61,28 +70,21 @@ func (s *MediaService) GetById(id uint) (*Media, error) {
 	return &item, nil
 }
 
-// GetAll returns a paginated list of media items
-func (s *MediaService) GetAll(page, limit *int) (*types.PaginatedResponse, error) {
+// GetAll returns a page of media items matching the list parameters; a zero
+// params.Limit returns every match
+func (s *MediaService) GetAll(params *query.Params) (*types.PaginatedResponse, error) {
 	var items []*Media
 	var total int64
 
-	// Get total count
-	if err := s.DB.Model(&Media{}).Count(&total).Error; err != nil {
+	// Get total count of the matching rows
+	if err := s.DB.Model(&Media{}).Scopes(params.Where()).Count(&total).Error; err != nil {
 		s.Logger.Error("failed to count media", logger.String("error", err.Error()))
 		return nil, fmt.Errorf("failed to count media: %w", err)
 	}
 
-	// Build query
-	query := s.DB.Model(&Media{})
-
-	// Add pagination if provided
-	if page != nil && limit != nil {
-		offset := (*page - 1) * *limit
-		query = query.Offset(offset).Limit(*limit)
-	}
-
 	// Execute query with preloads
-	if err := query.Preload(clause.Associations).Find(&items).Error; err != nil {
+	db := s.DB.Model(&Media{}).Scopes(params.Scope(), params.Paginate())
+	if err := db.Preload(clause.Associations).Find(&items).Error; err != nil {
 		s.Logger.Error("failed to get media", logger.String("error", err.Error()))
 		return nil, fmt.Errorf("failed to get media: %w", err)
 	}
@@ -94,13 +96,10 @@ func (s *MediaService) GetAll(page, limit *int) (*types.PaginatedResponse, error
 	}
 
 	// Calculate pagination
-	pageSize := 10
-	currentPage := 1
-	if limit != nil {
-		pageSize = *limit
-	}
-	if page != nil {
-		currentPage = *page
+	pageSize := params.Limit
+	currentPage := params.Page
+	if pageSize <= 0 {
+		pageSize = max(int(total), 1)
 	}
 	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
 	if totalPages == 0 {
@@ -247,7 +246,8 @@ func (s *MediaService) Update(id uint, req *UpdateMediaRequest) (*Media, error)
 	return s.GetById(id)
 }
 
-// Delete deletes a media item
+// Delete moves a media item to the trash. Its file is kept so Restore can
+// bring it back; ForceDelete removes both.
 func (s *MediaService) Delete(id uint) error {
 	// Get existing item
 	item, err := s.GetById(id)
@@ -255,19 +255,43 @@ func (s *MediaService) Delete(id uint) error {
 		return err
 	}
 
-	// Begin transaction
-	tx := s.DB.Begin()
-	if tx.Error != nil {
-		s.Logger.Error("failed to begin transaction", logger.String("error", tx.Error.Error()))
-		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	if err := s.DB.Delete(item).Error; err != nil {
+		s.Logger.Error("failed to delete media", logger.String("error", err.Error()))
+		return fmt.Errorf("failed to delete media: %w", err)
 	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+
+	return nil
+}
+
+// Restore takes a media item out of the trash
+func (s *MediaService) Restore(id uint) (*Media, error) {
+	result := s.DB.Unscoped().Model(&Media{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		s.Logger.Error("failed to restore media", logger.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("failed to restore media: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrNotTrashed
+	}
+
+	return s.GetById(id)
+}
+
+// ForceDelete permanently deletes a media item, trashed or not, along with
+// its stored file
+func (s *MediaService) ForceDelete(id uint) error {
+	var item Media
+	if err := s.DB.Unscoped().Preload(clause.Associations).First(&item, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
 		}
-	}()
+		s.Logger.Error("failed to get media", logger.String("error", err.Error()))
+		return fmt.Errorf("failed to get media: %w", err)
+	}
 
-	// Delete the file if it exists
+	// Remove the file first; a failure leaves the record to retry with
 	if item.File != nil {
 		if err := s.ActiveStorage.Delete(item.File); err != nil {
 			s.Logger.Error("failed to delete file", logger.String("error", err.Error()))
@@ -275,22 +299,82 @@ func (s *MediaService) Delete(id uint) error {
 		}
 	}
 
-	// Delete the media item
-	if err := tx.Delete(item).Error; err != nil {
-		tx.Rollback()
+	if err := s.DB.Unscoped().Delete(&item).Error; err != nil {
 		s.Logger.Error("failed to delete media", logger.String("error", err.Error()))
 		return fmt.Errorf("failed to delete media: %w", err)
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		s.Logger.Error("failed to commit transaction", logger.String("error", err.Error()))
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
+// BulkCreate creates media items without files in one transaction; if any
+// item is invalid or fails, none are created
+func (s *MediaService) BulkCreate(reqs []CreateMediaRequest) (*base.BulkResponse, error) {
+	return base.Bulk(s.DB, len(reqs), func(tx *gorm.DB, i int) (uint, error) {
+		if errs := validator.Validate(&reqs[i]); errs != nil {
+			return 0, errs
+		}
+
+		item := &Media{
+			Name:        reqs[i].Name,
+			Type:        reqs[i].Type,
+			Description: reqs[i].Description,
+		}
+		if err := tx.Create(item).Error; err != nil {
+			return 0, fmt.Errorf("failed to create media: %w", err)
+		}
+		return item.Id, nil
+	})
+}
+
+// BulkUpdate updates the given fields of media items in one transaction; if
+// any item is invalid or missing, none are updated
+func (s *MediaService) BulkUpdate(reqs []BulkUpdateMediaRequest) (*base.BulkResponse, error) {
+	return base.Bulk(s.DB, len(reqs), func(tx *gorm.DB, i int) (uint, error) {
+		req := reqs[i]
+		if errs := validator.Validate(&req); errs != nil {
+			return 0, errs
+		}
+
+		var item Media
+		if err := tx.First(&item, req.Id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, ErrNotFound
+			}
+			return 0, fmt.Errorf("failed to get media: %w", err)
+		}
+
+		if req.Name != nil {
+			item.Name = *req.Name
+		}
+		if req.Type != nil {
+			item.Type = *req.Type
+		}
+		if req.Description != nil {
+			item.Description = *req.Description
+		}
+		if err := tx.Save(&item).Error; err != nil {
+			return 0, fmt.Errorf("failed to update media: %w", err)
+		}
+		return item.Id, nil
+	})
+}
+
+// BulkDelete moves media items to the trash in one transaction; if any id is
+// missing, none are deleted
+func (s *MediaService) BulkDelete(ids []uint) (*base.BulkResponse, error) {
+	return base.Bulk(s.DB, len(ids), func(tx *gorm.DB, i int) (uint, error) {
+		result := tx.Delete(&Media{}, ids[i])
+		if result.Error != nil {
+			return 0, fmt.Errorf("failed to delete media: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return 0, ErrNotFound
+		}
+		return ids[i], nil
+	})
+}
+
 // UpdateFile updates the file of a media item
 func (s *MediaService) UpdateFile(ctx context.Context, id uint, file *multipart.FileHeader) (*Media, error) {
 	// Begin transaction
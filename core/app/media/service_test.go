@@ -0,0 +1,230 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/query"
+	"base/core/storage"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTestService returns a service whose files are stored under the
+// returned directory
+func newTestService(t *testing.T) (*MediaService, string) {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "media.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&Media{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	root := filepath.Join(dir, "storage")
+	as, err := storage.NewActiveStorage(db, storage.Config{Provider: "local", Path: root})
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+	return NewMediaService(db, emitter.New(), as, logger.NewLoggerFromZap(zap.NewNop())), root
+}
+
+// fileHeader returns an uploaded file as the multipart parser would
+func fileHeader(t *testing.T, filename string, data []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	form.Close()
+
+	parsed, err := multipart.NewReader(&body, form.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { parsed.RemoveAll() })
+	return parsed.File["file"][0]
+}
+
+// list returns the names of the media matching the list query
+func list(t *testing.T, s *MediaService, rawQuery string) []string {
+	t.Helper()
+	values, _ := url.ParseQuery(rawQuery)
+	params, err := query.Parse(values, &Media{})
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", rawQuery, err)
+	}
+	page, err := s.GetAll(params)
+	if err != nil {
+		t.Fatalf("GetAll(%q): %v", rawQuery, err)
+	}
+	names := []string{}
+	for _, item := range page.Data.([]any) {
+		names = append(names, item.(*MediaListResponse).Name)
+	}
+	return names
+}
+
+func TestTrashAndRestore(t *testing.T) {
+	s, _ := newTestService(t)
+	kept, _ := s.Create(&CreateMediaRequest{Name: "kept", Type: "image"})
+	trashed, _ := s.Create(&CreateMediaRequest{Name: "trashed", Type: "image"})
+	if err := s.Delete(trashed.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := list(t, s, ""); len(got) != 1 || got[0] != "kept" {
+		t.Errorf("default list = %v, want only kept", got)
+	}
+	if got := list(t, s, "trashed=only"); len(got) != 1 || got[0] != "trashed" {
+		t.Errorf("trashed=only = %v, want only trashed", got)
+	}
+	if got := list(t, s, "trashed=with&sort=id"); len(got) != 2 {
+		t.Errorf("trashed=with = %v, want both", got)
+	}
+	if _, err := s.GetById(trashed.Id); err == nil {
+		t.Error("GetById found a trashed item")
+	}
+
+	if _, err := s.Restore(kept.Id); !errors.Is(err, ErrNotTrashed) {
+		t.Errorf("Restore(untrashed) = %v, want ErrNotTrashed", err)
+	}
+	restored, err := s.Restore(trashed.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt.Valid {
+		t.Errorf("deleted_at = %v after restore, want null", restored.DeletedAt)
+	}
+	if got := list(t, s, ""); len(got) != 2 {
+		t.Errorf("list after restore = %v, want both", got)
+	}
+}
+
+func TestForceDeleteRemovesFile(t *testing.T) {
+	s, root := newTestService(t)
+	item, err := s.Create(&CreateMediaRequest{Name: "clip", Type: "audio"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Attached separately: Create attaches inside its open write transaction,
+	// which SQLite's single writer connection can't serve
+	if item, err = s.UpdateFile(context.Background(), item.Id, fileHeader(t, "clip.mp3", []byte("ID3 audio"))); err != nil {
+		t.Fatal(err)
+	}
+	if item.File == nil {
+		t.Fatal("no file attached")
+	}
+	path := filepath.Join(root, item.File.Path)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("uploaded file: %v", err)
+	}
+
+	// Trashing keeps the file so the item can be restored
+	if err := s.Delete(item.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("file removed by soft delete: %v", err)
+	}
+
+	if err := s.ForceDelete(item.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file still stored after force delete: %v", err)
+	}
+	var rows, attachments int64
+	s.DB.Unscoped().Model(&Media{}).Where("id = ?", item.Id).Count(&rows)
+	s.DB.Model(&storage.Attachment{}).Where("id = ?", item.File.Id).Count(&attachments)
+	if rows != 0 || attachments != 0 {
+		t.Errorf("%d media rows and %d attachments left", rows, attachments)
+	}
+	if got := list(t, s, "trashed=with"); len(got) != 0 {
+		t.Errorf("trashed=with = %v after force delete", got)
+	}
+
+	if err := s.ForceDelete(item.Id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ForceDelete(missing) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestBulkCreateRollsBackInvalidBatch(t *testing.T) {
+	s, _ := newTestService(t)
+
+	resp, err := s.BulkCreate([]CreateMediaRequest{
+		{Name: "first", Type: "image"},
+		{Name: "missing type"},
+		{Name: "third", Type: "audio"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Committed || resp.Succeeded != 2 || resp.Failed != 1 {
+		t.Errorf("response = %+v, want 2 ok and 1 failed, not committed", resp)
+	}
+	if r := resp.Results[1]; r.Success || r.Error == "" {
+		t.Errorf("result for the invalid item = %+v", r)
+	}
+	for _, r := range resp.Results {
+		if r.Id != 0 {
+			t.Errorf("result %d reports id %d for a rolled back row", r.Index, r.Id)
+		}
+	}
+	if got := list(t, s, ""); len(got) != 0 {
+		t.Errorf("stored %v from a failed batch", got)
+	}
+
+	resp, err = s.BulkCreate([]CreateMediaRequest{{Name: "first", Type: "image"}, {Name: "third", Type: "audio"}})
+	if err != nil || !resp.Committed || resp.Results[1].Id == 0 {
+		t.Fatalf("valid batch = %+v, %v", resp, err)
+	}
+	if got := list(t, s, "sort=id"); len(got) != 2 {
+		t.Errorf("stored %v, want both", got)
+	}
+}
+
+func TestBulkUpdateAndDelete(t *testing.T) {
+	s, _ := newTestService(t)
+	a, _ := s.Create(&CreateMediaRequest{Name: "a", Type: "image"})
+	b, _ := s.Create(&CreateMediaRequest{Name: "b", Type: "image"})
+
+	renamed := "renamed"
+	resp, err := s.BulkUpdate([]BulkUpdateMediaRequest{{Id: a.Id, Name: &renamed}, {Id: 999, Name: &renamed}})
+	if err != nil || resp.Committed || resp.Results[1].Error != ErrNotFound.Error() {
+		t.Fatalf("update with a missing id = %+v, %v", resp, err)
+	}
+	if item, _ := s.GetById(a.Id); item.Name != "a" {
+		t.Errorf("name = %q after a rolled back batch", item.Name)
+	}
+
+	resp, err = s.BulkDelete([]uint{a.Id, 999})
+	if err != nil || resp.Committed {
+		t.Fatalf("delete with a missing id = %+v, %v", resp, err)
+	}
+	if got := list(t, s, ""); len(got) != 2 {
+		t.Errorf("list = %v after a rolled back delete", got)
+	}
+
+	resp, err = s.BulkDelete([]uint{a.Id, b.Id})
+	if err != nil || !resp.Committed {
+		t.Fatalf("delete = %+v, %v", resp, err)
+	}
+	if got := list(t, s, "trashed=only"); len(got) != 2 {
+		t.Errorf("trashed = %v, want both", got)
+	}
+}
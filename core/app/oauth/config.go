@@ -9,6 +9,7 @@ type OAuthConfig struct {
 	Google    ProviderConfig
 	Facebook  ProviderConfig
 	Apple     ProviderConfig
+	GitHub    ProviderConfig
 	JWTSecret string
 }
 
@@ -36,6 +37,11 @@ func LoadConfig() *OAuthConfig {
 			ClientSecret: os.Getenv("APPLE_CLIENT_SECRET"),
 			RedirectURL:  os.Getenv("APPLE_REDIRECT_URL"),
 		},
+		GitHub: ProviderConfig{
+			ClientId:     os.Getenv("GITHUB_CLIENT_ID"),
+			ClientSecret: os.Getenv("GITHUB_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("GITHUB_REDIRECT_URL"),
+		},
 		JWTSecret: os.Getenv("JWT_SECRET"),
 	}
 	log.Println("OAuth configuration loaded successfully")
@@ -59,6 +65,10 @@ func ValidateConfig(config *OAuthConfig) {
 		hasProvider = true
 		log.Println("Apple OAuth provider configured")
 	}
+	if config.GitHub.ClientId != "" && config.GitHub.ClientSecret != "" {
+		hasProvider = true
+		log.Println("GitHub OAuth provider configured")
+	}
 
 	if !hasProvider {
 		log.Println("Warning: No OAuth providers configured. OAuth functionality will be disabled.")
@@ -71,3 +81,14 @@ func ValidateConfig(config *OAuthConfig) {
 
 	log.Println("OAuth configuration validated successfully")
 }
+
+// registerProviders registers the redirect flow providers that have
+// credentials configured
+func registerProviders(config *OAuthConfig) {
+	if config.Google.ClientId != "" && config.Google.ClientSecret != "" {
+		RegisterProvider(NewGoogleProvider(config.Google))
+	}
+	if config.GitHub.ClientId != "" && config.GitHub.ClientSecret != "" {
+		RegisterProvider(NewGitHubProvider(config.GitHub))
+	}
+}
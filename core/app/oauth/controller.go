@@ -1,9 +1,21 @@
 package oauth
 
 import (
+	"base/core/app/authentication"
 	"base/core/logger"
 	"base/core/router"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"net/http"
+	"time"
+)
+
+// stateCookie holds the state parameter between the redirect and the callback
+const (
+	stateCookie = "oauth_state"
+	stateTTL    = 10 * time.Minute
 )
 
 type OAuthController struct {
@@ -20,29 +32,128 @@ func NewOAuthController(service *OAuthService, logger logger.Logger, config *OAu
 	}
 }
 
-func (c *OAuthController) Routes(router *router.RouterGroup) {
-	router.POST("/google/callback", c.GoogleCallback)
-	router.POST("/facebook/callback", c.FacebookCallback)
-	router.POST("/apple/callback", c.AppleCallback)
+func (c *OAuthController) Routes(r *router.RouterGroup) {
+	r.POST("/google/callback", c.GoogleCallback).Doc(router.Doc{
+		Summary:  "Sign in with a Google id token",
+		Tags:     []string{"Core/OAuth"},
+		Request:  IdTokenRequest{},
+		Response: OAuthUser{},
+	})
+	r.POST("/facebook/callback", c.FacebookCallback).Doc(router.Doc{
+		Summary:  "Sign in with a Facebook access token",
+		Tags:     []string{"Core/OAuth"},
+		Request:  AccessTokenRequest{},
+		Response: OAuthUser{},
+	})
+	r.POST("/apple/callback", c.AppleCallback).Doc(router.Doc{
+		Summary:  "Sign in with an Apple id token",
+		Tags:     []string{"Core/OAuth"},
+		Request:  IdTokenRequest{},
+		Response: OAuthUser{},
+	})
 }
 
-// GoogleCallback godoc
-// @Summary Google OAuth callback
-// @Description Handle the OAuth callback from Google
-// @Security ApiKeyAuth
-// @Tags Core/OAuth
-// @Accept json
-// @Produce json
-// @Param idToken body string true "Google Id Token"
-// @Success 200 {object} profile.UserResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Router /oauth/google/callback [post]
-func (c *OAuthController) GoogleCallback(ctx *router.Context) error {
-	var req struct {
-		IdToken string `json:"idToken"`
+// RedirectRoutes registers the browser redirect flow for registered providers
+func (c *OAuthController) RedirectRoutes(r *router.RouterGroup) {
+	r.GET("/:provider", c.Redirect).Doc(router.Doc{
+		Summary:     "Start an OAuth login",
+		Description: "Redirects to the provider's consent page, e.g. /auth/oauth/google",
+		Tags:        []string{"Core/OAuth"},
+		Status:      http.StatusFound,
+	})
+	r.GET("/:provider/callback", c.Callback).Doc(router.Doc{
+		Summary:     "Finish an OAuth login",
+		Description: "Exchanges the provider's code, sent with the state from the redirect, finds or creates the user by verified email and logs them in",
+		Tags:        []string{"Core/OAuth"},
+		Response:    authentication.AuthResponse{},
+	})
+}
+
+// Redirect handles GET /auth/oauth/:provider
+func (c *OAuthController) Redirect(ctx *router.Context) error {
+	provider, ok := GetProvider(ctx.Param("provider"))
+	if !ok {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, ErrorResponse{Error: ErrUnknownProvider.Error()})
+		return nil
 	}
 
+	state, err := newState()
+	if err != nil {
+		c.Logger.Error("Failed to generate OAuth state", logger.String("error", err.Error()))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to start login"})
+		return nil
+	}
+
+	ctx.SetCookie(&http.Cookie{
+		Name:     stateCookie,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   int(stateTTL.Seconds()),
+		HttpOnly: true,
+		// Strict would keep the cookie off the provider's redirect back
+		SameSite: http.SameSiteLaxMode,
+	})
+	return ctx.Redirect(http.StatusFound, provider.AuthCodeURL(state))
+}
+
+// Callback handles GET /auth/oauth/:provider/callback
+func (c *OAuthController) Callback(ctx *router.Context) error {
+	provider, ok := GetProvider(ctx.Param("provider"))
+	if !ok {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, ErrorResponse{Error: ErrUnknownProvider.Error()})
+		return nil
+	}
+
+	// The state must match the cookie set by Redirect, which is used only once
+	cookie, err := ctx.Cookie(stateCookie)
+	state := ctx.Query("state")
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid OAuth state"})
+		return nil
+	}
+	ctx.SetCookie(&http.Cookie{Name: stateCookie, Path: "/", MaxAge: -1, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+
+	if reason := ctx.Query("error"); reason != "" {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: "Login cancelled: " + reason})
+		return nil
+	}
+	code := ctx.Query("code")
+	if code == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: "Missing authorization code"})
+		return nil
+	}
+
+	response, err := c.Service.LoginWithProvider(ctx.Context(), provider, code)
+	if err != nil {
+		c.Logger.Error("OAuth login failed",
+			logger.String("provider", provider.Name()),
+			logger.String("error", err.Error()))
+		if errors.Is(err, ErrNoEmail) || errors.Is(err, ErrEmailNotVerified) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return nil
+		}
+		// Exchange and database errors can carry provider responses or internals
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: "OAuth login failed"})
+		return nil
+	}
+
+	ctx.JSON(http.StatusOK, response)
+	return nil
+}
+
+// newState returns a random state parameter
+func newState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GoogleCallback handles POST /oauth/google/callback
+func (c *OAuthController) GoogleCallback(ctx *router.Context) error {
+	var req IdTokenRequest
+
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		c.Logger.Error("Failed to bind JSON request", logger.String("error", err.Error()))
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
@@ -60,22 +171,9 @@ func (c *OAuthController) GoogleCallback(ctx *router.Context) error {
 	return nil
 }
 
-// FacebookCallback godoc
-// @Summary Facebook OAuth callback
-// @Description Handle the OAuth callback from Facebook
-// @Security ApiKeyAuth
-// @Tags Core/OAuth
-// @Accept json
-// @Produce json
-// @Param accessToken body string true "Facebook Access Token"
-// @Success 200 {object} profile.UserResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Router /oauth/facebook/callback [post]
+// FacebookCallback handles POST /oauth/facebook/callback
 func (c *OAuthController) FacebookCallback(ctx *router.Context) error {
-	var req struct {
-		AccessToken string `json:"accessToken"`
-	}
+	var req AccessTokenRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		c.Logger.Error("Failed to bind JSON request", logger.String("error", err.Error()))
@@ -94,22 +192,9 @@ func (c *OAuthController) FacebookCallback(ctx *router.Context) error {
 	return nil
 }
 
-// AppleCallback godoc
-// @Summary Apple OAuth callback
-// @Description Handle the OAuth callback from Apple
-// @Security ApiKeyAuth
-// @Tags Core/OAuth
-// @Accept json
-// @Produce json
-// @Param idToken body string true "Apple Id Token"
-// @Success 200 {object} profile.UserResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Router /oauth/apple/callback [post]
+// AppleCallback handles POST /oauth/apple/callback
 func (c *OAuthController) AppleCallback(ctx *router.Context) error {
-	var req struct {
-		IdToken string `json:"idToken"`
-	}
+	var req IdTokenRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		c.Logger.Error("Failed to bind JSON request", logger.String("error", err.Error()))
@@ -128,6 +213,16 @@ func (c *OAuthController) AppleCallback(ctx *router.Context) error {
 	return nil
 }
 
+// IdTokenRequest carries the id token issued by Google or Apple
+type IdTokenRequest struct {
+	IdToken string `json:"idToken"`
+}
+
+// AccessTokenRequest carries the access token issued by Facebook
+type AccessTokenRequest struct {
+	AccessToken string `json:"accessToken"`
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error string `json:"error"`
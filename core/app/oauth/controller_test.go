@@ -0,0 +1,245 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/app/authentication"
+	"base/core/app/profile"
+	"base/core/config"
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/router"
+	"base/core/storage"
+	"base/core/types"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeProvider logs in the profile registered for each code
+type fakeProvider struct {
+	profiles map[string]*Profile
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) AuthCodeURL(state string) string {
+	return "https://provider.example.com/authorize?state=" + url.QueryEscape(state)
+}
+
+func (p *fakeProvider) Exchange(ctx context.Context, code string) (*Profile, error) {
+	profile, ok := p.profiles[code]
+	if !ok {
+		return nil, errors.New("failed to exchange code: oauth2: \"invalid_grant\" for client secret s3cret")
+	}
+	return profile, nil
+}
+
+// oauthTest is the redirect flow of the fake provider against a SQLite
+// database, logging to logs
+type oauthTest struct {
+	db       *gorm.DB
+	router   *router.Router
+	provider *fakeProvider
+	logs     *observer.ObservedLogs
+}
+
+func newOAuthTest(t *testing.T) *oauthTest {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "oauth.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&storage.Attachment{}, &authentication.AuthUser{}, &OAuthIdentity{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	types.SetKeySet(types.NewHMACKeySet("test", "test-secret"))
+	t.Cleanup(func() { types.SetKeySet(nil) })
+
+	provider := &fakeProvider{profiles: make(map[string]*Profile)}
+	RegisterProvider(provider)
+
+	service := NewOAuthService(db, &OAuthConfig{}, nil)
+	service.Auth = authentication.NewAuthService(db, nil, emitter.New(), &config.Config{AccessTokenTTL: time.Hour})
+	core, logs := observer.New(zap.InfoLevel)
+	r := router.New()
+	NewOAuthController(service, logger.NewLoggerFromZap(zap.New(core)), &OAuthConfig{}).RedirectRoutes(r.Group("/auth/oauth"))
+	return &oauthTest{db: db, router: r, provider: provider, logs: logs}
+}
+
+func (o *oauthTest) serve(req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	o.router.ServeHTTP(w, req)
+	return w
+}
+
+// login starts a login and returns the provider's callback for code
+func (o *oauthTest) login(t *testing.T, code string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := o.serve(httptest.NewRequest(http.MethodGet, "/auth/oauth/fake", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("redirect = %d: %s", w.Code, w.Body.String())
+	}
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != stateCookie || !cookies[0].HttpOnly {
+		t.Fatalf("redirect set cookies %v, want the HttpOnly state cookie", cookies)
+	}
+
+	query := url.Values{"code": {code}, "state": {location.Query().Get("state")}}
+	req := httptest.NewRequest(http.MethodGet, "/auth/oauth/fake/callback?"+query.Encode(), nil)
+	req.AddCookie(cookies[0])
+	return o.serve(req)
+}
+
+func loggedIn(t *testing.T, w *httptest.ResponseRecorder) authentication.AuthResponse {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("callback = %d: %s", w.Code, w.Body.String())
+	}
+	var response authentication.AuthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || response.AccessToken == "" {
+		t.Fatalf("callback response %s: %v", w.Body.String(), err)
+	}
+	return response
+}
+
+func (o *oauthTest) identities(t *testing.T) []OAuthIdentity {
+	t.Helper()
+	var identities []OAuthIdentity
+	if err := o.db.Find(&identities).Error; err != nil {
+		t.Fatal(err)
+	}
+	return identities
+}
+
+func TestCallbackChecksState(t *testing.T) {
+	o := newOAuthTest(t)
+	o.provider.profiles["ok"] = &Profile{ProviderId: "1", Email: "ada@example.com", EmailVerified: true}
+
+	tests := map[string]struct {
+		query  string
+		cookie string
+	}{
+		"without cookie": {query: "code=ok&state=abc"},
+		"without state":  {query: "code=ok", cookie: "abc"},
+		"another state":  {query: "code=ok&state=abd", cookie: "abc"},
+	}
+	for name, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/auth/oauth/fake/callback?"+tt.query, nil)
+		if tt.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: stateCookie, Value: tt.cookie})
+		}
+		if w := o.serve(req); w.Code != http.StatusBadRequest {
+			t.Errorf("%s = %d, want 400", name, w.Code)
+		}
+	}
+	if identities := o.identities(t); len(identities) != 0 {
+		t.Errorf("a callback with a bad state linked %+v", identities)
+	}
+
+	// The state is cleared once used
+	w := o.login(t, "ok")
+	loggedIn(t, w)
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("callback set cookies %v, want the state cookie deleted", cookies)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/oauth/missing/callback?code=ok&state=abc", nil)
+	req.AddCookie(&http.Cookie{Name: stateCookie, Value: "abc"})
+	if w := o.serve(req); w.Code != http.StatusNotFound {
+		t.Errorf("unknown provider = %d, want 404", w.Code)
+	}
+}
+
+func TestCallbackRequiresVerifiedEmail(t *testing.T) {
+	o := newOAuthTest(t)
+	o.provider.profiles["unverified"] = &Profile{ProviderId: "1", Email: "ada@example.com"}
+	o.provider.profiles["no-email"] = &Profile{ProviderId: "2", EmailVerified: true}
+	existing := authentication.AuthUser{User: profile.User{Email: "ada@example.com", Username: "ada"}}
+	if err := o.db.Create(&existing).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for code, want := range map[string]error{"unverified": ErrEmailNotVerified, "no-email": ErrNoEmail} {
+		w := o.login(t, code)
+		var response ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if w.Code != http.StatusBadRequest || response.Error != want.Error() {
+			t.Errorf("%s = %d %q, want 400 %q", code, w.Code, response.Error, want)
+		}
+	}
+	if identities := o.identities(t); len(identities) != 0 {
+		t.Errorf("an unverified account was linked: %+v", identities)
+	}
+	var users int64
+	o.db.Model(&authentication.AuthUser{}).Count(&users)
+	if users != 1 {
+		t.Errorf("%d users, want only the existing one", users)
+	}
+}
+
+func TestCallbackLinksUserByVerifiedEmail(t *testing.T) {
+	o := newOAuthTest(t)
+	existing := authentication.AuthUser{User: profile.User{Email: "ada@example.com", Username: "ada"}}
+	if err := o.db.Create(&existing).Error; err != nil {
+		t.Fatal(err)
+	}
+	o.provider.profiles["first"] = &Profile{ProviderId: "42", Email: "ada@example.com", EmailVerified: true, Name: "Ada Lovelace"}
+
+	if response := loggedIn(t, o.login(t, "first")); response.Id != existing.Id {
+		t.Errorf("logged in user %d, want the existing user %d", response.Id, existing.Id)
+	}
+	identities := o.identities(t)
+	if len(identities) != 1 || identities[0].UserId != existing.Id || identities[0].Provider != "fake" || identities[0].ProviderId != "42" {
+		t.Fatalf("identities %+v, want fake account 42 linked to user %d", identities, existing.Id)
+	}
+
+	// Once linked, the account logs in as the same user whatever its email
+	o.provider.profiles["again"] = &Profile{ProviderId: "42", Email: "ada@elsewhere.example.com"}
+	if response := loggedIn(t, o.login(t, "again")); response.Id != existing.Id {
+		t.Errorf("repeat login as user %d, want %d", response.Id, existing.Id)
+	}
+	if identities := o.identities(t); len(identities) != 1 {
+		t.Errorf("repeat login left identities %+v, want the one link", identities)
+	}
+
+	// A new verified email creates a user
+	o.provider.profiles["new"] = &Profile{ProviderId: "43", Email: "grace@example.com", EmailVerified: true, Name: "Grace Hopper"}
+	response := loggedIn(t, o.login(t, "new"))
+	if response.Id == existing.Id || response.Email != "grace@example.com" {
+		t.Errorf("new account logged in as %+v", response.UserResponse)
+	}
+}
+
+func TestCallbackHidesLoginErrors(t *testing.T) {
+	o := newOAuthTest(t)
+
+	w := o.login(t, "expired")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("failed exchange = %d, want 401", w.Code)
+	}
+	var response ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Error != "OAuth login failed" || strings.Contains(w.Body.String(), "s3cret") {
+		t.Errorf("401 response %s, want the generic message", w.Body.String())
+	}
+	entries := o.logs.FilterMessage("OAuth login failed").All()
+	if len(entries) != 1 || !strings.Contains(entries[0].ContextMap()["error"].(string), "invalid_grant") {
+		t.Errorf("logged %+v, want the provider error", entries)
+	}
+}
@@ -0,0 +1,119 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"base/core/app/authentication"
+	"base/core/app/profile"
+	"base/core/database"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownProvider  = errors.New("unknown OAuth provider")
+	ErrNoEmail          = errors.New("the provider account has no email address")
+	ErrEmailNotVerified = errors.New("the provider account's email address is not verified")
+)
+
+// LoginWithProvider completes the redirect flow for provider: it exchanges
+// code for the provider profile, finds the user linked to that account or,
+// failing that, the user with the same verified email, creating one when there
+// is none, links the account and logs the user in.
+func (s *OAuthService) LoginWithProvider(ctx context.Context, provider Provider, code string) (*authentication.AuthResponse, error) {
+	account, err := provider.Exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	var user authentication.AuthUser
+	err = database.Transaction(ctx, s.DB, func(tx *gorm.DB) error {
+		var identity OAuthIdentity
+		result := tx.Where("provider = ? AND provider_id = ?", provider.Name(), account.ProviderId).Limit(1).Find(&identity)
+		if result.Error != nil {
+			return fmt.Errorf("failed to query identity: %w", result.Error)
+		}
+
+		if result.RowsAffected > 0 {
+			if err := tx.First(&user, identity.UserId).Error; err != nil {
+				return fmt.Errorf("failed to load linked user: %w", err)
+			}
+		} else {
+			// Only a verified email may claim an existing account
+			if account.Email == "" {
+				return ErrNoEmail
+			}
+			if !account.EmailVerified {
+				return ErrEmailNotVerified
+			}
+			if err := s.findOrCreateUser(tx, account, &user); err != nil {
+				return err
+			}
+		}
+
+		return s.linkIdentity(tx, user.Id, provider.Name(), account.ProviderId, account.Email, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Auth.LoginUser(ctx, &user)
+}
+
+// findOrCreateUser loads the user with the account's email into user,
+// creating a user without a password when there is none
+func (s *OAuthService) findOrCreateUser(tx *gorm.DB, account *Profile, user *authentication.AuthUser) error {
+	result := tx.Where("email = ?", account.Email).Limit(1).Find(user)
+	if result.Error != nil {
+		return fmt.Errorf("failed to query user: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	firstName, lastName := splitName(account.Name)
+	now := time.Now()
+	*user = authentication.AuthUser{
+		User: profile.User{
+			Email:     account.Email,
+			FirstName: firstName,
+			LastName:  lastName,
+			Username:  s.generateUniqueUsername(tx, strings.ToLower(strings.SplitN(account.Email, "@", 2)[0])),
+		},
+		LastLogin: &now,
+	}
+	if err := tx.Create(user).Error; err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	return nil
+}
+
+// linkIdentity links the provider account to the user, updating the link
+// when it already exists
+func (s *OAuthService) linkIdentity(tx *gorm.DB, userId uint, provider, providerId, email, token string) error {
+	identity := OAuthIdentity{
+		UserId:      userId,
+		Provider:    provider,
+		ProviderId:  providerId,
+		Email:       email,
+		AccessToken: token,
+		LastLogin:   time.Now(),
+	}
+	err := tx.Where("provider = ? AND provider_id = ?", provider, providerId).
+		Assign(OAuthIdentity{UserId: userId, Email: email, AccessToken: token, LastLogin: identity.LastLogin}).
+		FirstOrCreate(&identity).Error
+	if err != nil {
+		return fmt.Errorf("failed to link %s identity: %w", provider, err)
+	}
+	return nil
+}
+
+// splitName splits a display name into first and last name at the first space
+func splitName(name string) (string, string) {
+	first, last, _ := strings.Cut(strings.TrimSpace(name), " ")
+	return first, last
+}
@@ -1,10 +1,14 @@
 package oauth
 
 import (
+	"base/core/app/authentication"
+	"base/core/jobs"
 	"base/core/logger"
 	"base/core/module"
 	"base/core/router"
 	"base/core/storage"
+	"base/core/types"
+	"fmt"
 
 	"gorm.io/gorm"
 )
@@ -12,6 +16,7 @@ import (
 type OAuthModule struct {
 	module.DefaultModule
 	DB            *gorm.DB
+	Logger        logger.Logger
 	Controller    *OAuthController
 	Service       *OAuthService
 	Config        *OAuthConfig
@@ -21,12 +26,14 @@ type OAuthModule struct {
 func NewOAuthModule(db *gorm.DB, router *router.RouterGroup, logger logger.Logger, activeStorage *storage.ActiveStorage) module.Module {
 	config := LoadConfig()
 	ValidateConfig(config)
+	registerProviders(config)
 
 	service := NewOAuthService(db, config, activeStorage)
 	controller := NewOAuthController(service, logger, config)
 
 	oauthModule := &OAuthModule{
 		DB:            db,
+		Logger:        logger,
 		Controller:    controller,
 		Service:       service,
 		Config:        config,
@@ -36,17 +43,58 @@ func NewOAuthModule(db *gorm.DB, router *router.RouterGroup, logger logger.Logge
 	return oauthModule
 }
 
+// DependsOn initializes the users module first; OAuth logins create users
+// and are issued tokens by the authentication module. Deleted users are
+// unlinked through the jobs queue.
+func (m *OAuthModule) DependsOn() []string {
+	return []string{"authentication", "jobs", "users"}
+}
+
+// Init hands the authentication service to the redirect flow and unlinks
+// providers from deleted accounts
+func (m *OAuthModule) Init() error {
+	mod, err := module.GetModule("authentication")
+	if err != nil {
+		return err
+	}
+	authModule, ok := mod.(*authentication.AuthenticationModule)
+	if !ok {
+		return fmt.Errorf("authentication module has unexpected type %T", mod)
+	}
+	m.Service.Auth = authModule.Service
+	jobs.Register(RemoveIdentitiesJob, m.Service.removeIdentitiesJob)
+	if authModule.Emitter != nil {
+		authModule.Emitter.On("user.deleted", m.enqueueUnlink)
+	}
+	return nil
+}
+
+// enqueueUnlink queues RemoveIdentitiesJob for a deleted user, so a failed
+// unlink is retried by the jobs queue
+func (m *OAuthModule) enqueueUnlink(data any) {
+	user, ok := data.(types.UserData)
+	if !ok {
+		return
+	}
+	if err := jobs.Enqueue(RemoveIdentitiesJob, identitiesJobPayload{UserId: user.Id}); err != nil {
+		m.Logger.Error("Failed to enqueue oauth unlink of deleted user",
+			logger.Uint("user_id", user.Id),
+			logger.String("error", err.Error()))
+	}
+}
+
 func (m *OAuthModule) Routes(router *router.RouterGroup) {
 	oauthGroup := router.Group("/oauth")
 	m.Controller.Routes(oauthGroup)
+	m.Controller.RedirectRoutes(router.Group("/auth/oauth"))
 }
 
 func (m *OAuthModule) Migrate() error {
-	return m.DB.AutoMigrate(&AuthProvider{})
+	return m.DB.AutoMigrate(&OAuthIdentity{})
 }
 
 func (m *OAuthModule) GetModels() []any {
 	return []any{
-		&AuthProvider{},
+		&OAuthIdentity{},
 	}
 }
@@ -19,17 +19,20 @@ func (OAuthUser) TableName() string {
 	return "users"
 }
 
-type AuthProvider struct {
+// OAuthIdentity links a provider account to a user. A user can link several
+// providers; each provider account belongs to one user.
+type OAuthIdentity struct {
 	gorm.Model
-	UserId      uint
-	Provider    string
-	ProviderId  string
+	UserId      uint   `gorm:"not null;index"`
+	Provider    string `gorm:"not null;size:50;uniqueIndex:idx_oauth_identity"`
+	ProviderId  string `gorm:"not null;size:255;uniqueIndex:idx_oauth_identity"`
+	Email       string `gorm:"size:255"`
 	AccessToken string
 	LastLogin   time.Time
 }
 
-func (AuthProvider) TableName() string {
-	return "auth_providers"
+func (OAuthIdentity) TableName() string {
+	return "oauth_identities"
 }
 
 // You might want to add OAuth-specific request/response structs here
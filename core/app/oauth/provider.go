@@ -0,0 +1,191 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+	"golang.org/x/oauth2/google"
+)
+
+// Profile is the account a provider reports after a successful login
+type Profile struct {
+	ProviderId    string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Provider is an OAuth2 login provider used by the redirect flow
+type Provider interface {
+	// Name identifies the provider in routes and linked identities
+	Name() string
+	// AuthCodeURL returns the provider's consent page URL carrying state
+	AuthCodeURL(state string) string
+	// Exchange trades the callback code for the user's profile
+	Exchange(ctx context.Context, code string) (*Profile, error)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]Provider)
+)
+
+// RegisterProvider makes a provider available under its name, replacing any
+// provider registered under the same name
+func RegisterProvider(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[p.Name()] = p
+}
+
+// GetProvider returns the provider registered under name
+func GetProvider(name string) (Provider, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	p, ok := providers[name]
+	return p, ok
+}
+
+// OAuth2Provider is a Provider for the authorization code flow that loads the
+// profile with FetchProfile using the authorized client
+type OAuth2Provider struct {
+	ProviderName string
+	Config       *oauth2.Config
+	FetchProfile func(ctx context.Context, client *http.Client) (*Profile, error)
+}
+
+func (p *OAuth2Provider) Name() string {
+	return p.ProviderName
+}
+
+func (p *OAuth2Provider) AuthCodeURL(state string) string {
+	return p.Config.AuthCodeURL(state)
+}
+
+func (p *OAuth2Provider) Exchange(ctx context.Context, code string) (*Profile, error) {
+	token, err := p.Config.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	profile, err := p.FetchProfile(ctx, p.Config.Client(ctx, token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s profile: %w", p.ProviderName, err)
+	}
+	if profile.ProviderId == "" {
+		return nil, fmt.Errorf("%s profile has no account id", p.ProviderName)
+	}
+	return profile, nil
+}
+
+// NewGoogleProvider creates the Google provider using OpenID Connect userinfo
+func NewGoogleProvider(cfg ProviderConfig) *OAuth2Provider {
+	return &OAuth2Provider{
+		ProviderName: "google",
+		Config: &oauth2.Config{
+			ClientID:     cfg.ClientId,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     google.Endpoint,
+			Scopes:       []string{"openid", "email", "profile"},
+		},
+		FetchProfile: func(ctx context.Context, client *http.Client) (*Profile, error) {
+			var info struct {
+				Sub           string `json:"sub"`
+				Email         string `json:"email"`
+				EmailVerified bool   `json:"email_verified"`
+				Name          string `json:"name"`
+				Picture       string `json:"picture"`
+			}
+			if err := getJSON(ctx, client, "https://openidconnect.googleapis.com/v1/userinfo", &info); err != nil {
+				return nil, err
+			}
+			return &Profile{
+				ProviderId:    info.Sub,
+				Email:         info.Email,
+				EmailVerified: info.EmailVerified,
+				Name:          info.Name,
+				Picture:       info.Picture,
+			}, nil
+		},
+	}
+}
+
+// NewGitHubProvider creates the GitHub provider. The email is the account's
+// primary verified address since the public profile email may be unset.
+func NewGitHubProvider(cfg ProviderConfig) *OAuth2Provider {
+	return &OAuth2Provider{
+		ProviderName: "github",
+		Config: &oauth2.Config{
+			ClientID:     cfg.ClientId,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     github.Endpoint,
+			Scopes:       []string{"read:user", "user:email"},
+		},
+		FetchProfile: func(ctx context.Context, client *http.Client) (*Profile, error) {
+			var user struct {
+				Id        int64  `json:"id"`
+				Login     string `json:"login"`
+				Name      string `json:"name"`
+				AvatarURL string `json:"avatar_url"`
+			}
+			if err := getJSON(ctx, client, "https://api.github.com/user", &user); err != nil {
+				return nil, err
+			}
+
+			var emails []struct {
+				Email    string `json:"email"`
+				Primary  bool   `json:"primary"`
+				Verified bool   `json:"verified"`
+			}
+			if err := getJSON(ctx, client, "https://api.github.com/user/emails", &emails); err != nil {
+				return nil, err
+			}
+
+			profile := &Profile{
+				ProviderId: strconv.FormatInt(user.Id, 10),
+				Name:       user.Name,
+				Picture:    user.AvatarURL,
+			}
+			if profile.Name == "" {
+				profile.Name = user.Login
+			}
+			for _, e := range emails {
+				if e.Primary {
+					profile.Email = e.Email
+					profile.EmailVerified = e.Verified
+					break
+				}
+			}
+			return profile, nil
+		},
+	}
+}
+
+// getJSON decodes the JSON response of a GET request into dst
+func getJSON(ctx context.Context, client *http.Client, url string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
@@ -1,7 +1,9 @@
 package oauth
 
 import (
+	"base/core/app/authentication"
 	"base/core/app/profile"
+	"base/core/jobs"
 	"base/core/storage"
 	"bytes"
 	"context"
@@ -22,6 +24,7 @@ type OAuthService struct {
 	DB            *gorm.DB
 	Config        *OAuthConfig
 	ActiveStorage *storage.ActiveStorage
+	Auth          *authentication.AuthService // issues tokens for the redirect flow, set in Init
 }
 
 func NewOAuthService(db *gorm.DB, config *OAuthConfig, activeStorage *storage.ActiveStorage) *OAuthService {
@@ -134,7 +137,7 @@ func (s *OAuthService) processUser(email, name, username, pictureURL, provider,
 					Email:     email,
 					FirstName: name[:strings.Index(name, " ")],
 					LastName:  name[strings.Index(name, " ")+1:],
-					Username:  s.generateUniqueUsername(username),
+					Username:  s.generateUniqueUsername(s.DB, username),
 				},
 				Provider:       provider,
 				ProviderId:     providerId,
@@ -184,18 +187,8 @@ func (s *OAuthService) processUser(email, name, username, pictureURL, provider,
 		}
 	}
 
-	// Update or create AuthProvider
-	authProvider := AuthProvider{
-		UserId:      user.Id,
-		Provider:    provider,
-		ProviderId:  providerId,
-		AccessToken: token,
-		LastLogin:   time.Now(),
-	}
-	if err := s.DB.Where("user_id = ? AND provider = ?", user.Id, provider).
-		Assign(authProvider).
-		FirstOrCreate(&authProvider).Error; err != nil {
-		return nil, fmt.Errorf("failed to update or create auth provider: %w", err)
+	if err := s.linkIdentity(s.DB, user.Id, provider, providerId, email, token); err != nil {
+		return nil, err
 	}
 
 	return &user, nil
@@ -258,12 +251,12 @@ func (s *OAuthService) fetchAndAttachAvatar(user *OAuthUser, avatarURL string) (
 	return attachment, nil
 }
 
-func (s *OAuthService) generateUniqueUsername(baseUsername string) string {
+func (s *OAuthService) generateUniqueUsername(db *gorm.DB, baseUsername string) string {
 	username := baseUsername
 	counter := 1
 	for {
 		var existingUser profile.User
-		if s.DB.Where("username = ?", username).First(&existingUser).Error == gorm.ErrRecordNotFound {
+		if db.Where("username = ?", username).First(&existingUser).Error == gorm.ErrRecordNotFound {
 			break
 		}
 		username = fmt.Sprintf("%s%d", baseUsername, counter)
@@ -271,3 +264,28 @@ func (s *OAuthService) generateUniqueUsername(baseUsername string) string {
 	}
 	return username
 }
+
+// RemoveIdentitiesJob unlinks the provider accounts of a deleted user
+const RemoveIdentitiesJob = "oauth.remove_identities"
+
+// identitiesJobPayload identifies the user RemoveIdentitiesJob unlinks
+type identitiesJobPayload struct {
+	UserId uint `json:"user_id"`
+}
+
+// removeIdentities unlinks every provider account of a deleted user, along
+// with its provider tokens; RemoveIdentitiesJob runs it for user.deleted
+func (s *OAuthService) removeIdentities(ctx context.Context, userId uint) error {
+	if err := s.DB.WithContext(ctx).Unscoped().Where("user_id = ?", userId).Delete(&OAuthIdentity{}).Error; err != nil {
+		return fmt.Errorf("failed to delete oauth identities: %w", err)
+	}
+	return nil
+}
+
+func (s *OAuthService) removeIdentitiesJob(ctx context.Context, job *jobs.Job) error {
+	var payload identitiesJobPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	return s.removeIdentities(ctx, payload.UserId)
+}
@@ -0,0 +1,181 @@
+package organizations
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"base/core/helper"
+	"base/core/logger"
+	"base/core/router"
+	"base/core/router/middleware"
+	"base/core/types"
+)
+
+type InvitationController struct {
+	Service *InvitationService
+	Logger  logger.Logger
+}
+
+func NewInvitationController(service *InvitationService, logger logger.Logger) *InvitationController {
+	return &InvitationController{
+		Service: service,
+		Logger:  logger,
+	}
+}
+
+// Routes registers the invitation management routes of an organization.
+// middleware must resolve the organization and check the permission to
+// manage its members.
+func (c *InvitationController) Routes(r *router.RouterGroup, middleware ...router.MiddlewareFunc) {
+	r.GET("/organizations/:id/invitations", c.List, middleware...).Doc(router.Doc{
+		Summary:  "List the invitations of an organization",
+		Tags:     []string{"Core/Organizations"},
+		Response: []InvitationResponse{},
+	})
+	r.POST("/organizations/:id/invitations", c.Create, middleware...).Doc(router.Doc{
+		Summary:     "Invite someone to an organization",
+		Description: "Emails a link to join the organization with a role. Inviting an email with a pending invitation sends a new link and extends the expiry.",
+		Tags:        []string{"Core/Organizations"},
+		Request:     CreateInvitationRequest{},
+		Response:    InvitationResponse{},
+		Status:      http.StatusCreated,
+	})
+	r.DELETE("/organizations/:id/invitations/:invitation_id", c.Revoke, middleware...).Doc(router.Doc{
+		Summary: "Revoke an invitation",
+		Tags:    []string{"Core/Organizations"},
+		Status:  http.StatusNoContent,
+	})
+}
+
+// PublicRoutes registers the routes reached from an invitation link
+func (c *InvitationController) PublicRoutes(r *router.RouterGroup) {
+	r.GET("/invitations/:token", c.Get).Doc(router.Doc{
+		Summary:     "View an invitation",
+		Description: "The invitation behind an emailed link, with its status",
+		Tags:        []string{"Core/Organizations"},
+		Response:    InvitationResponse{},
+	})
+	r.POST("/invitations/:token/accept", c.Accept).Doc(router.Doc{
+		Summary:     "Accept an invitation",
+		Description: "Signed in users whose email was invited join with no body. Without an account, send a password (and optionally names, username and phone) to register with the invited email; the response then includes the access token.",
+		Tags:        []string{"Core/Organizations"},
+		Request:     AcceptInvitationRequest{},
+		Response:    AcceptInvitationResponse{},
+	})
+}
+
+// List handles GET /organizations/:id/invitations
+func (c *InvitationController) List(ctx *router.Context) error {
+	invitations, err := c.Service.List(ctx.OrgID())
+	if err != nil {
+		return c.error(ctx, err)
+	}
+
+	responses := make([]*InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		responses = append(responses, invitations[i].ToResponse())
+	}
+	return ctx.JSON(http.StatusOK, responses)
+}
+
+// Create handles POST /organizations/:id/invitations
+func (c *InvitationController) Create(ctx *router.Context) error {
+	var req CreateInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	invitation, err := c.Service.Invite(ctx.OrgMembership(), &req)
+	if err != nil {
+		return c.error(ctx, err)
+	}
+	return ctx.JSON(http.StatusCreated, invitation.ToResponse())
+}
+
+// Revoke handles DELETE /organizations/:id/invitations/:invitation_id
+func (c *InvitationController) Revoke(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("invitation_id")
+	if !ok {
+		return nil
+	}
+
+	if err := c.Service.Revoke(ctx.OrgID(), uint(id)); err != nil {
+		return c.error(ctx, err)
+	}
+
+	ctx.Status(http.StatusNoContent)
+	return nil
+}
+
+// Get handles GET /invitations/:token
+func (c *InvitationController) Get(ctx *router.Context) error {
+	invitation, err := c.Service.Get(ctx.Param("token"))
+	if err != nil {
+		return c.error(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, invitation)
+}
+
+// Accept handles POST /invitations/:token/accept
+func (c *InvitationController) Accept(ctx *router.Context) error {
+	// Signing in is optional here, but a token that is sent must be valid
+	var userId uint64
+	if header := ctx.GetHeader("Authorization"); header != "" {
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		id, err := types.ValidateJWT(token)
+		if !ok || err != nil {
+			return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid access token"})
+		}
+		userId = uint64(id)
+	}
+
+	var req AcceptInvitationRequest
+	if userId == 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		}
+	}
+
+	response, err := c.Service.Accept(ctx.Context(), ctx.Param("token"), userId, &req)
+	if err != nil {
+		return c.error(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// pathOrganization makes the :id organization the one OrganizationContext
+// resolves, so membership and permissions are checked against it. A
+// Base-Orgid header naming another organization is rejected.
+func pathOrganization(next router.HandlerFunc) router.HandlerFunc {
+	return func(c *router.Context) error {
+		id := c.Param("id")
+		if header := c.GetHeader(middleware.OrgHeader); header != "" && header != id {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: middleware.OrgHeader + " does not match the organization in the path"})
+		}
+		c.Request.Header.Set(middleware.OrgHeader, id)
+		return next(c)
+	}
+}
+
+// error maps service errors to responses
+func (c *InvitationController) error(ctx *router.Context, err error) error {
+	var weak *helper.PasswordError
+	switch {
+	case errors.As(err, &weak):
+		return ctx.JSON(http.StatusBadRequest, weak.ValidationErrors("password"))
+	case errors.Is(err, ErrInvitationNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvitationExpired), errors.Is(err, ErrInvitationRevoked):
+		return ctx.JSON(http.StatusGone, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvitationAccepted), errors.Is(err, ErrAlreadyMember), errors.Is(err, ErrAccountExists):
+		return ctx.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrOwnerInvite), errors.Is(err, ErrEmailMismatch):
+		return ctx.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidRole), errors.Is(err, ErrPasswordRequired):
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.Logger.Error("Invitation request failed", logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
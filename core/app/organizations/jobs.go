@@ -0,0 +1,49 @@
+package organizations
+
+import (
+	"context"
+	"html"
+	"time"
+
+	"base/core/email"
+	"base/core/jobs"
+)
+
+// InvitationEmailJob sends the link of an invitation
+const InvitationEmailJob = "organizations.invitation_email"
+
+type invitationEmailPayload struct {
+	Email    string    `json:"email"`
+	RoleName string    `json:"role_name"`
+	URL      string    `json:"url"`
+	Expires  time.Time `json:"expires"`
+}
+
+// registerJobs registers the invitation email job handler
+func (s *InvitationService) registerJobs() {
+	jobs.Register(InvitationEmailJob, s.invitationEmailJob)
+}
+
+func (s *InvitationService) invitationEmailJob(ctx context.Context, job *jobs.Job) error {
+	var payload invitationEmailPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	return email.Send(email.Message{
+		To:      []string{payload.Email},
+		From:    "no-reply@base.al",
+		Subject: "You've been invited to join an organization on Base",
+		Body:    invitationEmailBody(payload),
+		IsHTML:  true,
+	})
+}
+
+func invitationEmailBody(payload invitationEmailPayload) string {
+	url := html.EscapeString(payload.URL)
+	return "<h1>You've been invited</h1>" +
+		"<p>You have been invited to join an organization on Base as " + html.EscapeString(payload.RoleName) + ".</p>" +
+		"<p><a href=\"" + url + "\">Accept the invitation</a></p>" +
+		"<p>Or open this link: " + url + "</p>" +
+		"<p>The invitation expires on " + payload.Expires.Format("January 2, 2006 at 15:04 MST") + ".</p>"
+}
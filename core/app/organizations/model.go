@@ -0,0 +1,154 @@
+package organizations
+
+import (
+	"time"
+
+	"base/core/app/authentication"
+)
+
+// Invitation statuses
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+	StatusRevoked  = "revoked"
+	StatusExpired  = "expired"
+)
+
+// Member is a user's membership in an organization. RoleId holds the id of
+// an authorization role as a string.
+type Member struct {
+	Id             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationId uint64    `json:"organization_id" gorm:"column:organization_id;not null;index"`
+	UserId         uint64    `json:"user_id" gorm:"column:user_id;not null;index"`
+	RoleId         string    `json:"role_id" gorm:"column:role_id;size:32"`
+	IsOwner        bool      `json:"is_owner" gorm:"column:is_owner;not null;default:false"`
+	Department     string    `json:"department" gorm:"column:department;size:255"`
+	MembershipType string    `json:"membership_type" gorm:"column:membership_type;size:50;default:Internal"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the Member model
+func (Member) TableName() string {
+	return "organization_members"
+}
+
+// Invitation asks someone to join an organization with a role. Only a hash
+// of the token is stored; the token itself is in the link emailed to them.
+type Invitation struct {
+	Id             uint       `json:"id" gorm:"primaryKey"`
+	OrganizationId uint64     `json:"organization_id" gorm:"column:organization_id;not null;index"`
+	Email          string     `json:"email" gorm:"column:email;not null;size:255;index"`
+	RoleId         uint       `json:"role_id" gorm:"column:role_id;not null"`
+	TokenHash      string     `json:"-" gorm:"column:token_hash;not null;size:64;uniqueIndex"`
+	InvitedBy      uint64     `json:"invited_by" gorm:"column:invited_by"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	AcceptedAt     *time.Time `json:"accepted_at" gorm:"column:accepted_at"`
+	AcceptedBy     uint64     `json:"accepted_by" gorm:"column:accepted_by"`
+	RevokedAt      *time.Time `json:"revoked_at" gorm:"column:revoked_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for the Invitation model
+func (Invitation) TableName() string {
+	return "organization_invitations"
+}
+
+// Status returns whether the invitation is pending, accepted, revoked or expired
+func (i *Invitation) Status() string {
+	switch {
+	case i.AcceptedAt != nil:
+		return StatusAccepted
+	case i.RevokedAt != nil:
+		return StatusRevoked
+	case time.Now().After(i.ExpiresAt):
+		return StatusExpired
+	}
+	return StatusPending
+}
+
+// ToResponse converts the invitation to a response object
+func (i *Invitation) ToResponse() *InvitationResponse {
+	return &InvitationResponse{
+		Id:             i.Id,
+		OrganizationId: i.OrganizationId,
+		Email:          i.Email,
+		RoleId:         i.RoleId,
+		Status:         i.Status(),
+		InvitedBy:      i.InvitedBy,
+		ExpiresAt:      i.ExpiresAt,
+		AcceptedAt:     i.AcceptedAt,
+		CreatedAt:      i.CreatedAt,
+	}
+}
+
+// InvitationResponse represents an invitation in API responses
+type InvitationResponse struct {
+	Id             uint       `json:"id"`
+	OrganizationId uint64     `json:"organization_id"`
+	Email          string     `json:"email"`
+	RoleId         uint       `json:"role_id"`
+	RoleName       string     `json:"role_name,omitempty"`
+	Status         string     `json:"status"`
+	InvitedBy      uint64     `json:"invited_by"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// CreateInvitationRequest represents the request payload for inviting someone
+type CreateInvitationRequest struct {
+	Email  string `json:"email" binding:"required,email"`
+	RoleId uint   `json:"role_id" binding:"required"`
+}
+
+// AcceptInvitationRequest represents the request payload for accepting an
+// invitation. Signed in users send no body; someone without an account
+// registers with the invited email by sending a password and their details.
+type AcceptInvitationRequest struct {
+	FirstName string `json:"first_name" example:"John"`
+	LastName  string `json:"last_name" example:"Doe"`
+	Username  string `json:"username" example:"johndoe"`
+	Phone     string `json:"phone" example:"+1234567890"`
+	Password  string `json:"password" example:"password123"`
+}
+
+// AcceptInvitationResponse represents the membership created by accepting an
+// invitation. Auth is set when the invitation registered a new user.
+type AcceptInvitationResponse struct {
+	OrganizationId uint64                       `json:"organization_id"`
+	UserId         uint64                       `json:"user_id"`
+	RoleId         uint                         `json:"role_id"`
+	Auth           *authentication.AuthResponse `json:"auth,omitempty"`
+}
+
+// InvitationEvent is emitted as organization.member_invited
+type InvitationEvent struct {
+	InvitationId   uint      `json:"invitation_id"`
+	OrganizationId uint64    `json:"organization_id"`
+	Email          string    `json:"email"`
+	RoleId         uint      `json:"role_id"`
+	InvitedBy      uint64    `json:"invited_by"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// MemberEvent is emitted as organization.member_joined
+type MemberEvent struct {
+	OrganizationId uint64 `json:"organization_id"`
+	UserId         uint64 `json:"user_id"`
+	RoleId         uint   `json:"role_id"`
+	InvitationId   uint   `json:"invitation_id"`
+	NewUser        bool   `json:"new_user"`
+}
+
+// EventOrganizationId scopes the event to its organization, so webhooks only
+// deliver it to that organization's endpoints
+func (e MemberEvent) EventOrganizationId() uint64 {
+	return e.OrganizationId
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
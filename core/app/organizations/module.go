@@ -0,0 +1,108 @@
+package organizations
+
+import (
+	"base/core/app/authentication"
+	"base/core/app/authorization"
+	"base/core/config"
+	"base/core/email"
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/module"
+	"base/core/router"
+	"base/core/router/middleware"
+	"base/core/seed"
+
+	"gorm.io/gorm"
+)
+
+type OrganizationModule struct {
+	module.DefaultModule
+	DB         *gorm.DB
+	Controller *InvitationController
+	Service    *InvitationService
+	Logger     logger.Logger
+}
+
+func NewOrganizationModule(db *gorm.DB, emailSender email.Sender, emitter *emitter.Emitter, logger logger.Logger, cfg *config.Config) module.Module {
+	auth := authentication.NewAuthService(db, emailSender, emitter, cfg)
+	url := cfg.InvitationURL
+	if url == "" {
+		url = cfg.BaseURL + "/api/invitations/{token}"
+	}
+	service := NewInvitationService(db, auth, emitter, logger, cfg.InvitationTTL, url)
+	controller := NewInvitationController(service, logger)
+
+	return &OrganizationModule{
+		DB:         db,
+		Controller: controller,
+		Service:    service,
+		Logger:     logger,
+	}
+}
+
+// DependsOn initializes the modules owning users and roles first; invitation
+// emails are sent through the jobs queue
+func (m *OrganizationModule) DependsOn() []string {
+	return []string{"authentication", "authorization", "jobs"}
+}
+
+// Init registers the invitation email job and the permission to invite
+func (m *OrganizationModule) Init() error {
+	m.Service.registerJobs()
+	seed.Register(seed.Seeder{Name: "organizations.permissions", Run: seedPermissions})
+	return nil
+}
+
+func (m *OrganizationModule) Routes(router *router.RouterGroup) {
+	// Inviting is checked against the organization in the path
+	m.Controller.Routes(router.Group("", middleware.BearerAuth()).Security("BearerAuth"),
+		pathOrganization,
+		middleware.OrganizationContext(m.DB),
+		middleware.RequirePermission(ManageMembersResource, ManageMembersAction),
+	)
+	// Invitation links are followed before signing in, like registration
+	m.Controller.PublicRoutes(router.Group("", middleware.Api()).Security("ApiKeyAuth"))
+}
+
+func (m *OrganizationModule) Migrate() error {
+	return m.DB.AutoMigrate(&Member{}, &Invitation{})
+}
+
+func (m *OrganizationModule) GetModels() []any {
+	return []any{
+		&Member{},
+		&Invitation{},
+	}
+}
+
+// The permission needed to invite members and manage invitations; owners
+// have it implicitly and the Administrator role is granted it
+const (
+	ManageMembersResource = "user"
+	ManageMembersAction   = "manage_members"
+)
+
+// seedPermissions creates the manage members permission and grants it to the
+// Administrator system role
+func seedPermissions(tx *gorm.DB) error {
+	permission := authorization.Permission{
+		Name:         "Manage Members",
+		Description:  "Invite members and manage invitations",
+		ResourceType: ManageMembersResource,
+		Action:       ManageMembersAction,
+	}
+	keys := map[string]any{"resource_type": permission.ResourceType, "action": permission.Action}
+	if _, err := seed.CreateIfNotExists(tx, &permission, keys); err != nil {
+		return err
+	}
+
+	var role authorization.Role
+	result := tx.Where("name = ? AND is_system = ?", "Administrator", true).Limit(1).Find(&role)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	rolePermission := authorization.RolePermission{RoleId: role.Id, PermissionId: permission.Id}
+	keys = map[string]any{"role_id": role.Id, "permission_id": permission.Id}
+	_, err := seed.CreateIfNotExists(tx, &rolePermission, keys)
+	return err
+}
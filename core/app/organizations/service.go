@@ -0,0 +1,377 @@
+package organizations
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"base/core/app/authentication"
+	"base/core/app/authorization"
+	"base/core/app/profile"
+	"base/core/database"
+	"base/core/emitter"
+	"base/core/jobs"
+	"base/core/logger"
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvitationExpired  = errors.New("invitation has expired")
+	ErrInvitationRevoked  = errors.New("invitation has been revoked")
+	ErrInvitationAccepted = errors.New("invitation has already been accepted")
+	ErrInvalidRole        = errors.New("role does not exist in this organization")
+	ErrOwnerInvite        = errors.New("only owners can invite owners")
+	ErrAlreadyMember      = errors.New("user is already a member of this organization")
+	ErrEmailMismatch      = errors.New("invitation was sent to a different email address")
+	ErrAccountExists      = errors.New("an account with this email already exists, sign in to accept the invitation")
+	ErrPasswordRequired   = errors.New("password is required to register")
+)
+
+type InvitationService struct {
+	DB      *gorm.DB
+	Auth    *authentication.AuthService
+	Emitter *emitter.Emitter
+	Logger  logger.Logger
+
+	// TTL is how long an invitation can be accepted
+	TTL time.Duration
+	// URL is the link sent in invitation emails, with {token} replaced
+	URL string
+}
+
+func NewInvitationService(db *gorm.DB, auth *authentication.AuthService, emitter *emitter.Emitter, logger logger.Logger, ttl time.Duration, url string) *InvitationService {
+	return &InvitationService{
+		DB:      db,
+		Auth:    auth,
+		Emitter: emitter,
+		Logger:  logger,
+		TTL:     ttl,
+		URL:     url,
+	}
+}
+
+// Invite invites email to the organization with a role and emails them a
+// link. Inviting an email with a pending invitation re-invites it: the old
+// link stops working and the new one gets a fresh expiry. Only owners can
+// invite with the Owner role.
+func (s *InvitationService) Invite(inviter *router.OrgMembership, req *CreateInvitationRequest) (*Invitation, error) {
+	orgId := inviter.OrganizationId
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	role, err := s.role(orgId, req.RoleId)
+	if err != nil {
+		return nil, err
+	}
+	if role.Name == "Owner" && !s.isOwner(inviter) {
+		return nil, ErrOwnerInvite
+	}
+
+	member, err := s.isMember(orgId, email)
+	if err != nil {
+		return nil, err
+	}
+	if member {
+		return nil, ErrAlreadyMember
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var invitation Invitation
+	err = database.Transaction(context.Background(), s.DB, func(tx *gorm.DB) error {
+		result := tx.Where("organization_id = ? AND email = ? AND accepted_at IS NULL AND revoked_at IS NULL", orgId, email).
+			Order("id DESC").
+			Limit(1).
+			Find(&invitation)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		invitation.OrganizationId = orgId
+		invitation.Email = email
+		invitation.RoleId = role.Id
+		invitation.TokenHash = hashToken(token)
+		invitation.InvitedBy = inviter.UserId
+		invitation.ExpiresAt = time.Now().Add(s.TTL)
+		return tx.Save(&invitation).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save invitation: %w", err)
+	}
+
+	err = jobs.Enqueue(InvitationEmailJob, invitationEmailPayload{
+		Email:    email,
+		RoleName: role.Name,
+		URL:      strings.ReplaceAll(s.URL, "{token}", token),
+		Expires:  invitation.ExpiresAt,
+	})
+	if err != nil {
+		s.Logger.Error("Failed to enqueue invitation email",
+			logger.String("email", email),
+			logger.String("error", err.Error()))
+	}
+
+	s.Emitter.Emit("organization.member_invited", InvitationEvent{
+		InvitationId:   invitation.Id,
+		OrganizationId: invitation.OrganizationId,
+		Email:          invitation.Email,
+		RoleId:         invitation.RoleId,
+		InvitedBy:      invitation.InvitedBy,
+		ExpiresAt:      invitation.ExpiresAt,
+	})
+	return &invitation, nil
+}
+
+// List returns the organization's invitations, newest first
+func (s *InvitationService) List(orgId uint64) ([]Invitation, error) {
+	var invitations []Invitation
+	if err := s.DB.Where("organization_id = ?", orgId).Order("id DESC").Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// Revoke stops a pending invitation from being accepted
+func (s *InvitationService) Revoke(orgId uint64, id uint) error {
+	var invitation Invitation
+	if err := s.DB.Where("id = ? AND organization_id = ?", id, orgId).First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvitationNotFound
+		}
+		return err
+	}
+	if invitation.AcceptedAt != nil {
+		return ErrInvitationAccepted
+	}
+	if invitation.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	return s.DB.Model(&invitation).Update("revoked_at", now).Error
+}
+
+// Get returns the invitation with token, whatever its status, along with
+// its role name
+func (s *InvitationService) Get(token string) (*InvitationResponse, error) {
+	invitation, err := s.byToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	response := invitation.ToResponse()
+	var role authorization.Role
+	if err := s.DB.Select("name").Limit(1).Find(&role, invitation.RoleId).Error; err == nil {
+		response.RoleName = role.Name
+	}
+	return response, nil
+}
+
+// Accept adds a user to the organization of a pending invitation. A signed
+// in user (userId != 0) must have the invited email. Otherwise a new user
+// is registered with that email from req, and the response carries their
+// access token.
+func (s *InvitationService) Accept(ctx context.Context, token string, userId uint64, req *AcceptInvitationRequest) (*AcceptInvitationResponse, error) {
+	invitation, err := s.byToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := pending(invitation); err != nil {
+		return nil, err
+	}
+
+	response := &AcceptInvitationResponse{
+		OrganizationId: invitation.OrganizationId,
+		RoleId:         invitation.RoleId,
+	}
+
+	if userId != 0 {
+		var user profile.User
+		if err := s.DB.Select("id, email").First(&user, userId).Error; err != nil {
+			return nil, fmt.Errorf("failed to load user: %w", err)
+		}
+		if !strings.EqualFold(user.Email, invitation.Email) {
+			return nil, ErrEmailMismatch
+		}
+		err = database.Transaction(ctx, s.DB, func(tx *gorm.DB) error {
+			return join(tx, invitation, userId)
+		})
+		if err != nil {
+			if errors.Is(err, ErrInvitationAccepted) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to accept invitation: %w", err)
+		}
+	} else {
+		// The account is created in the same transaction as the membership,
+		// so a failed accept leaves no account behind to block a retry
+		response.Auth, err = s.register(ctx, invitation.Email, req, func(tx *gorm.DB, user *authentication.AuthUser) error {
+			userId = uint64(user.Id)
+			return join(tx, invitation, userId)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	response.UserId = userId
+
+	s.Emitter.Emit("organization.member_joined", MemberEvent{
+		OrganizationId: invitation.OrganizationId,
+		UserId:         userId,
+		RoleId:         invitation.RoleId,
+		InvitationId:   invitation.Id,
+		NewUser:        response.Auth != nil,
+	})
+	return response, nil
+}
+
+// register creates the account of someone accepting an invitation and runs
+// fn in the same transaction. Their username defaults to the email, which is
+// known to be unique.
+func (s *InvitationService) register(ctx context.Context, email string, req *AcceptInvitationRequest, fn func(tx *gorm.DB, user *authentication.AuthUser) error) (*authentication.AuthResponse, error) {
+	if req == nil || req.Password == "" {
+		return nil, ErrPasswordRequired
+	}
+
+	var count int64
+	if err := s.DB.Model(&profile.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrAccountExists
+	}
+
+	username := req.Username
+	if username == "" {
+		username = email
+	}
+	auth, err := s.Auth.RegisterWith(ctx, &authentication.RegisterRequest{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Username:  username,
+		Phone:     req.Phone,
+		Email:     email,
+		Password:  req.Password,
+	}, fn)
+	if err != nil {
+		if errors.Is(err, authentication.ErrUserExists) {
+			return nil, ErrAccountExists
+		}
+		return nil, err
+	}
+	return auth, nil
+}
+
+// join marks the invitation accepted by userId and adds them to its
+// organization. Only the first of concurrent accepts succeeds; the others
+// get ErrInvitationAccepted.
+func join(tx *gorm.DB, invitation *Invitation, userId uint64) error {
+	result := tx.Model(&Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.Id).
+		Updates(map[string]any{"accepted_at": time.Now(), "accepted_by": userId})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvitationAccepted
+	}
+
+	var count int64
+	if err := tx.Model(&Member{}).Where("organization_id = ? AND user_id = ?", invitation.OrganizationId, userId).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return tx.Create(&Member{
+		OrganizationId: invitation.OrganizationId,
+		UserId:         userId,
+		RoleId:         strconv.FormatUint(uint64(invitation.RoleId), 10),
+		MembershipType: "Internal",
+	}).Error
+}
+
+// role returns a system role or one of the organization's roles
+func (s *InvitationService) role(orgId uint64, roleId uint) (*authorization.Role, error) {
+	var role authorization.Role
+	err := s.DB.Where("id = ? AND (organization_id = 0 OR organization_id = ?)", roleId, orgId).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidRole
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// isOwner reports whether a membership is flagged as owner or has the Owner role
+func (s *InvitationService) isOwner(membership *router.OrgMembership) bool {
+	if membership.IsOwner {
+		return true
+	}
+	roleId, err := strconv.ParseUint(membership.RoleId, 10, 64)
+	if err != nil {
+		return false
+	}
+	role, err := s.role(membership.OrganizationId, uint(roleId))
+	return err == nil && role.Name == "Owner"
+}
+
+// isMember reports whether the user with email belongs to the organization
+func (s *InvitationService) isMember(orgId uint64, email string) (bool, error) {
+	var count int64
+	err := s.DB.Model(&Member{}).
+		Joins("JOIN users ON users.id = organization_members.user_id").
+		Where("organization_members.organization_id = ? AND LOWER(users.email) = ? AND users.deleted_at IS NULL", orgId, email).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (s *InvitationService) byToken(token string) (*Invitation, error) {
+	var invitation Invitation
+	if err := s.DB.Where("token_hash = ?", hashToken(token)).First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// pending returns why an invitation can't be accepted, if it can't
+func pending(invitation *Invitation) error {
+	switch invitation.Status() {
+	case StatusAccepted:
+		return ErrInvitationAccepted
+	case StatusRevoked:
+		return ErrInvitationRevoked
+	case StatusExpired:
+		return ErrInvitationExpired
+	}
+	return nil
+}
+
+// newToken returns a random invitation token
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
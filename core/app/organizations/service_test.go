@@ -0,0 +1,231 @@
+package organizations
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"base/core/app/authentication"
+	"base/core/app/authorization"
+	"base/core/config"
+	"base/core/emitter"
+	"base/core/events"
+	"base/core/jobs"
+	"base/core/logger"
+	"base/core/router"
+	"base/core/storage"
+	"base/core/types"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// mailbox is a job queue that keeps the links of invitation emails
+type mailbox struct {
+	links []string
+}
+
+func (m *mailbox) Enqueue(ctx context.Context, name string, payload any) error {
+	m.links = append(m.links, payload.(invitationEmailPayload).URL)
+	return nil
+}
+
+func (m *mailbox) Start(ctx context.Context) error { return nil }
+func (m *mailbox) Stop(ctx context.Context) error  { return nil }
+
+// newTestService returns a service whose invitation links are the bare
+// token, an owner of organization 1 and the Member role id
+func newTestService(t *testing.T) (*InvitationService, *router.OrgMembership, uint) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "organizations.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	err = db.AutoMigrate(&storage.Attachment{}, &authentication.AuthUser{}, &events.OutboxEvent{},
+		&authorization.Role{}, &Invitation{}, &Member{})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	role := authorization.Role{Name: "Member", IsSystem: true}
+	if err := db.Create(&role).Error; err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+
+	types.SetKeySet(types.NewHMACKeySet("test", "test-secret"))
+	t.Cleanup(func() { types.SetKeySet(nil) })
+	jobs.SetDefault(&mailbox{})
+	t.Cleanup(func() { jobs.SetDefault(nil) })
+
+	auth := authentication.NewAuthService(db, nil, emitter.New(), &config.Config{AccessTokenTTL: time.Hour})
+	s := NewInvitationService(db, auth, emitter.New(), logger.NewLoggerFromZap(zap.NewNop()), time.Hour, "{token}")
+	return s, &router.OrgMembership{OrganizationId: 1, UserId: 1, IsOwner: true}, role.Id
+}
+
+// invite invites email and returns the token from the invitation email
+func invite(t *testing.T, s *InvitationService, owner *router.OrgMembership, roleId uint, email string) string {
+	t.Helper()
+	if _, err := s.Invite(owner, &CreateInvitationRequest{Email: email, RoleId: roleId}); err != nil {
+		t.Fatalf("Invite: %v", err)
+	}
+	links := jobs.Default().(*mailbox).links
+	return links[len(links)-1]
+}
+
+func members(t *testing.T, s *InvitationService, userId uint64) int64 {
+	t.Helper()
+	var count int64
+	s.DB.Model(&Member{}).Where("organization_id = 1 AND user_id = ?", userId).Count(&count)
+	return count
+}
+
+func TestAcceptRegistersNewUser(t *testing.T) {
+	s, owner, roleId := newTestService(t)
+	token := invite(t, s, owner, roleId, "New@Example.com")
+
+	if _, err := s.Accept(context.Background(), token, 0, nil); !errors.Is(err, ErrPasswordRequired) {
+		t.Errorf("Accept without a password = %v, want ErrPasswordRequired", err)
+	}
+	resp, err := s.Accept(context.Background(), token, 0, &AcceptInvitationRequest{FirstName: "Ada", Password: "correct-horse-battery"})
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if resp.Auth == nil || resp.Auth.AccessToken == "" || resp.UserId == 0 || resp.RoleId != roleId {
+		t.Fatalf("Accept = %+v", resp)
+	}
+	if resp.Auth.Email != "new@example.com" {
+		t.Errorf("registered email %q, want the invited one", resp.Auth.Email)
+	}
+	if members(t, s, resp.UserId) != 1 {
+		t.Error("the new user was not added to the organization")
+	}
+	if _, err := s.Accept(context.Background(), token, 0, &AcceptInvitationRequest{Password: "correct-horse-battery"}); !errors.Is(err, ErrInvitationAccepted) {
+		t.Errorf("accepting twice = %v, want ErrInvitationAccepted", err)
+	}
+}
+
+func TestAcceptExistingUser(t *testing.T) {
+	s, owner, roleId := newTestService(t)
+	user := authentication.AuthUser{}
+	user.Email = "member@example.com"
+	user.Username = "member"
+	if err := s.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	token := invite(t, s, owner, roleId, user.Email)
+
+	// Someone signed out can't register a second account for the email
+	if _, err := s.Accept(context.Background(), token, 0, &AcceptInvitationRequest{Password: "correct-horse-battery"}); !errors.Is(err, ErrAccountExists) {
+		t.Errorf("registering an existing email = %v, want ErrAccountExists", err)
+	}
+	resp, err := s.Accept(context.Background(), token, uint64(user.Id), nil)
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if resp.Auth != nil || resp.UserId != uint64(user.Id) {
+		t.Errorf("Accept = %+v", resp)
+	}
+	if members(t, s, uint64(user.Id)) != 1 {
+		t.Error("the user was not added to the organization")
+	}
+	if _, err := s.Invite(owner, &CreateInvitationRequest{Email: user.Email, RoleId: roleId}); !errors.Is(err, ErrAlreadyMember) {
+		t.Errorf("inviting a member = %v, want ErrAlreadyMember", err)
+	}
+}
+
+func TestAcceptEmailMismatch(t *testing.T) {
+	s, owner, roleId := newTestService(t)
+	user := authentication.AuthUser{}
+	user.Email = "someone-else@example.com"
+	user.Username = "someone-else"
+	if err := s.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	token := invite(t, s, owner, roleId, "invited@example.com")
+
+	if _, err := s.Accept(context.Background(), token, uint64(user.Id), nil); !errors.Is(err, ErrEmailMismatch) {
+		t.Errorf("Accept = %v, want ErrEmailMismatch", err)
+	}
+	if members(t, s, uint64(user.Id)) != 0 {
+		t.Error("the user was added to the organization")
+	}
+}
+
+func TestAcceptUnavailableInvitations(t *testing.T) {
+	s, owner, roleId := newTestService(t)
+	req := &AcceptInvitationRequest{Password: "correct-horse-battery"}
+
+	expired := invite(t, s, owner, roleId, "expired@example.com")
+	s.DB.Model(&Invitation{}).Where("email = ?", "expired@example.com").Update("expires_at", time.Now().Add(-time.Minute))
+	if _, err := s.Accept(context.Background(), expired, 0, req); !errors.Is(err, ErrInvitationExpired) {
+		t.Errorf("accepting an expired invitation = %v, want ErrInvitationExpired", err)
+	}
+
+	revoked := invite(t, s, owner, roleId, "revoked@example.com")
+	invitation, err := s.byToken(revoked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Revoke(1, invitation.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Accept(context.Background(), revoked, 0, req); !errors.Is(err, ErrInvitationRevoked) {
+		t.Errorf("accepting a revoked invitation = %v, want ErrInvitationRevoked", err)
+	}
+
+	if _, err := s.Accept(context.Background(), "unknown", 0, req); !errors.Is(err, ErrInvitationNotFound) {
+		t.Errorf("accepting an unknown token = %v, want ErrInvitationNotFound", err)
+	}
+
+	var users int64
+	s.DB.Model(&authentication.AuthUser{}).Count(&users)
+	if users != 0 {
+		t.Errorf("%d users registered through unavailable invitations", users)
+	}
+}
+
+func TestReinviteReplacesToken(t *testing.T) {
+	s, owner, roleId := newTestService(t)
+	first := invite(t, s, owner, roleId, "again@example.com")
+	second := invite(t, s, owner, roleId, "again@example.com")
+
+	var count int64
+	s.DB.Model(&Invitation{}).Where("email = ?", "again@example.com").Count(&count)
+	if count != 1 {
+		t.Errorf("%d invitations after re-inviting, want 1", count)
+	}
+	req := &AcceptInvitationRequest{Password: "correct-horse-battery"}
+	if _, err := s.Accept(context.Background(), first, 0, req); !errors.Is(err, ErrInvitationNotFound) {
+		t.Errorf("accepting the replaced token = %v, want ErrInvitationNotFound", err)
+	}
+	if _, err := s.Accept(context.Background(), second, 0, req); err != nil {
+		t.Errorf("accepting the new token: %v", err)
+	}
+}
+
+func TestAcceptRollsBackRegistration(t *testing.T) {
+	s, owner, roleId := newTestService(t)
+	token := invite(t, s, owner, roleId, "racer@example.com")
+
+	// Another accept wins the race between the user and membership inserts
+	err := s.DB.Callback().Create().After("gorm:create").Register("test:accept", func(tx *gorm.DB) {
+		if tx.Statement.Table == "users" {
+			tx.Session(&gorm.Session{NewDB: true}).Model(&Invitation{}).
+				Where("email = ?", "racer@example.com").Update("accepted_at", time.Now())
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Accept(context.Background(), token, 0, &AcceptInvitationRequest{Password: "correct-horse-battery"}); !errors.Is(err, ErrInvitationAccepted) {
+		t.Fatalf("Accept = %v, want ErrInvitationAccepted", err)
+	}
+	var users int64
+	s.DB.Model(&authentication.AuthUser{}).Where("email = ?", "racer@example.com").Count(&users)
+	if users != 0 {
+		t.Error("the account was kept although the invitation wasn't accepted")
+	}
+}
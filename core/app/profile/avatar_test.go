@@ -0,0 +1,168 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"base/core/config"
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/router"
+	"base/core/storage"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// avatarRouter serves the profile routes as user 1, with avatars stored
+// under a temporary directory
+func avatarRouter(t *testing.T, cfg *config.Config) *router.Router {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "profile.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&User{}, &UserSettings{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := db.Create(&User{Email: "ada@example.com", Username: "ada"}).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	as, err := storage.NewActiveStorage(db, storage.Config{Provider: "local", Path: filepath.Join(dir, "storage")})
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+
+	log := logger.NewLoggerFromZap(zap.NewNop())
+	controller := NewProfileController(NewProfileService(db, log, as, emitter.New(), cfg), log)
+	r := router.New()
+	controller.Routes(r.Group("", func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			c.Set("user_id", uint(1))
+			return next(c)
+		}
+	}))
+	return r
+}
+
+func uploadAvatar(t *testing.T, r *router.Router, filename string, data []byte) (int, router.Envelope) {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("avatar", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPut, "/profile/avatar", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var envelope router.Envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	return w.Code, envelope
+}
+
+// avatarFile returns an uploaded avatar as the multipart parser would
+func avatarFile(t *testing.T, filename string, data []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("avatar", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	form.Close()
+
+	parsed, err := multipart.NewReader(&body, form.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { parsed.RemoveAll() })
+	return parsed.File["avatar"][0]
+}
+
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUpdateAvatarAcceptsImages(t *testing.T) {
+	r := avatarRouter(t, nil)
+
+	code, envelope := uploadAvatar(t, r, "me.png", pngImage(t, 64, 64))
+	if code != http.StatusOK || !envelope.Success {
+		t.Fatalf("upload = %d %+v", code, envelope)
+	}
+	user, _ := envelope.Data.(map[string]any)
+	if url, _ := user["avatar_url"].(string); url == "" {
+		t.Errorf("response has no avatar: %v", envelope.Data)
+	}
+}
+
+func TestUpdateAvatarRejections(t *testing.T) {
+	cfg := &config.Config{AvatarMaxSize: 4096, AvatarMinDimension: 32, AvatarMaxDimension: 128}
+	r := avatarRouter(t, cfg)
+
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		code     string
+	}{
+		{"bytes that aren't an image", "me.jpg", []byte("fake image data"), "invalid_avatar_content_type"},
+		{"a PNG named as a JPEG is fine", "me.jpg", pngImage(t, 64, 64), ""},
+		{"disallowed extension", "me.gif", pngImage(t, 64, 64), "invalid_avatar_extension"},
+		{"too small", "me.png", pngImage(t, 8, 8), "invalid_avatar_dimensions"},
+		{"too large", "me.png", pngImage(t, 256, 64), "invalid_avatar_dimensions"},
+		{"over the size limit", "me.png", append(pngImage(t, 64, 64), make([]byte, 4096)...), "invalid_avatar_size"},
+	}
+	for _, tt := range tests {
+		status, envelope := uploadAvatar(t, r, tt.filename, tt.data)
+		if tt.code == "" {
+			if status != http.StatusOK {
+				t.Errorf("%s: status = %d %+v, want 200", tt.name, status, envelope)
+			}
+			continue
+		}
+		if status != http.StatusBadRequest || envelope.Code != tt.code || envelope.Error == "" {
+			t.Errorf("%s: got %d %q %q, want 400 %s", tt.name, status, envelope.Code, envelope.Error, tt.code)
+		}
+	}
+}
+
+func TestUpdateAvatarRemovesReplacedFile(t *testing.T) {
+	s, first := newDeletionService(t, "anonymize", 0)
+
+	if _, err := s.UpdateAvatar(context.Background(), 1, avatarFile(t, "new.png", pngImage(t, 32, 32))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("replaced avatar still stored: %v", err)
+	}
+	avatars, err := s.avatars(1)
+	if err != nil || len(avatars) != 1 {
+		t.Fatalf("avatars = %d, %v; want only the new one", len(avatars), err)
+	}
+}
@@ -1,13 +1,15 @@
 package profile
 
 import (
+	"base/core/helper"
 	"base/core/logger"
 	"base/core/router"
+	"base/core/router/middleware"
+	"base/core/storage"
 	"base/core/types"
 	"errors"
 	"net/http"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -23,162 +25,299 @@ func NewProfileController(service *ProfileService, logger logger.Logger) *Profil
 	}
 }
 
-func (c *ProfileController) Routes(router *router.RouterGroup) {
-	router.GET("/profile", c.Get)
-	router.PUT("/profile", c.Update)
-	router.PUT("/profile/avatar", c.UpdateAvatar)
-	router.PUT("/profile/password", c.UpdatePassword)
+func (c *ProfileController) Routes(r *router.RouterGroup) {
+	r.GET("/profile", c.Get).Doc(router.Doc{
+		Summary:  "Get profile from Authenticated User Token",
+		Tags:     []string{"Core/Profile"},
+		Response: UserResponse{},
+	})
+	r.PUT("/profile", c.Update).Doc(router.Doc{
+		Summary:     "Update profile from Authenticated User Token",
+		Description: "A new email is kept pending and a confirmation link is sent to it; the current email stays in use until the link is opened",
+		Tags:        []string{"Core/Profile"},
+		Request:     UpdateRequest{},
+		Response:    UserResponse{},
+	})
+	r.DELETE("/profile", c.Delete).Doc(router.Doc{
+		Summary:     "Delete the account of the Authenticated User",
+		Description: "Anonymizes or deletes the account per ACCOUNT_DELETION_MODE, removing its files and signing it out everywhere. With ACCOUNT_DELETION_GRACE_DAYS the deletion is scheduled instead; signing in again before then cancels it.",
+		Tags:        []string{"Core/Profile"},
+		Response:    DeletionResponse{},
+	})
+	// Avatar uploads are multipart and accept larger bodies than the JSON endpoints
+	uploads := r.Group("").
+		Set(middleware.BodyLimitKey, middleware.UploadBodyLimit()).
+		Set(middleware.ContentTypesKey, []string{"multipart/form-data"})
+	uploads.PUT("/profile/avatar", c.UpdateAvatar).Doc(router.Doc{
+		Summary:  "Update profile avatar from Authenticated User Token",
+		Tags:     []string{"Core/Profile"},
+		Response: UserResponse{},
+	})
+	r.PUT("/profile/password", c.UpdatePassword).Doc(router.Doc{
+		Summary:  "Update profile password from Authenticated User Token",
+		Tags:     []string{"Core/Profile"},
+		Request:  UpdatePasswordRequest{},
+		Response: types.SuccessResponse{},
+	})
+	r.DELETE("/profile/email/pending", c.CancelEmailChange).Doc(router.Doc{
+		Summary:     "Cancel the pending email change of the Authenticated User",
+		Description: "Its confirmation link stops working",
+		Tags:        []string{"Core/Profile"},
+		Response:    UserResponse{},
+	})
+	r.GET("/profile/settings", c.GetSettings).Doc(router.Doc{
+		Summary:  "Get settings of the Authenticated User",
+		Tags:     []string{"Core/Profile"},
+		Response: map[string]any{},
+	})
+	r.PATCH("/profile/settings", c.UpdateSettings).Doc(router.Doc{
+		Summary:     "Update settings of the Authenticated User",
+		Description: "Merges the body into the stored settings; keys set to null are removed",
+		Tags:        []string{"Core/Profile"},
+		Request:     map[string]any{},
+		Response:    map[string]any{},
+	})
 }
 
-// @Summary Get profile from Authenticated User Token
-// @Description Get profile by Bearer Token
-// @Security ApiKeyAuth
-// @Security BearerAuth
-// @Tags Core/Profile
-// @Accept json
-// @Produce json
-// @Success 200 {object} User
-// @Failure 400 {object} types.ErrorResponse
-// @Failure 404 {object} types.ErrorResponse
-// @Failure 500 {object} types.ErrorResponse
-// @Router /profile [get]
+// PublicRoutes registers the routes reached from an email change link
+func (c *ProfileController) PublicRoutes(r *router.RouterGroup) {
+	r.GET("/profile/email/confirm", c.ConfirmEmailChange).Doc(router.Doc{
+		Summary:     "Confirm an email change with the token from the link",
+		Description: "Makes the pending email the user's email; the token comes from the link sent to the new address",
+		Tags:        []string{"Core/Profile"},
+		Response:    UserResponse{},
+	})
+}
+
+// Get handles GET /profile
 func (c *ProfileController) Get(ctx *router.Context) error {
 	id := ctx.GetUint("user_id")
 	c.logger.Debug("Getting user", logger.Uint("user_id", id))
 	if id == 0 {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid user Id"})
+		return ctx.Fail(http.StatusBadRequest, "invalid_id", types.T(ctx, "errors.invalid_id"))
 	}
 
 	item, err := c.service.GetById(uint(id))
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{Error: "User not found"})
+			return ctx.Fail(http.StatusNotFound, "user_not_found", types.T(ctx, "errors.user_not_found"))
 		}
 		c.logger.Error("Failed to get user",
 			logger.Uint("user_id", id))
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to fetch user"})
+		return ctx.Fail(http.StatusInternalServerError, "fetch_user_failed", types.T(ctx, "errors.fetch_user_failed"))
 	}
 
-	return ctx.JSON(http.StatusOK, item)
+	return ctx.OK(item)
 }
 
-// @Summary Update profile from Authenticated User Token
-// @Description Update profile by Bearer Token
-// @Security ApiKeyAuth
-// @Security BearerAuth
-// @Tags Core/Profile
-// @Accept json
-// @Produce json
-// @Param input body UpdateRequest true "Update Request"
-// @Success 200 {object} User
-// @Failure 400 {object} types.ErrorResponse
-// @Failure 404 {object} types.ErrorResponse
-// @Failure 500 {object} types.ErrorResponse
-// @Router /profile [put]
+// Update handles PUT /profile
 func (c *ProfileController) Update(ctx *router.Context) error {
 	id := ctx.GetUint("user_id")
 	if id == 0 {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid Id format"})
+		return ctx.Fail(http.StatusBadRequest, "invalid_id", types.T(ctx, "errors.invalid_id"))
 	}
 
 	var req UpdateRequest
-	if err := ctx.ShouldBind(&req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
+	if errs := types.BindAndValidate(ctx, &req); errs != nil {
+		return ctx.JSON(http.StatusBadRequest, errs)
 	}
 
 	item, err := c.service.Update(uint(id), &req)
 	if err != nil {
+		if errors.Is(err, ErrEmailTaken) {
+			return ctx.Fail(http.StatusConflict, "email_taken", "Email is already in use")
+		}
 		c.logger.Error("Failed to update user",
 			logger.Uint("user_id", id))
 
-		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to update user: " + err.Error()})
+		return ctx.Fail(http.StatusInternalServerError, "update_user_failed", "Failed to update user: "+err.Error())
 	}
 
-	return ctx.JSON(http.StatusOK, item)
+	return ctx.OK(item)
 }
 
-// @Summary Update profile avatar from Authenticated User Token
-// @Description Update profile avatar by Bearer Token
-// @Security ApiKeyAuth
-// @Security BearerAuth
-// @Tags Core/Profile
-// @Accept multipart/form-data
-// @Produce json
-// @Param avatar formData file true "Avatar file"
-// @Success 200 {object} User
-// @Failure 400 {object} types.ErrorResponse
-// @Failure 404 {object} types.ErrorResponse
-// @Failure 500 {object} types.ErrorResponse
-// @Router /profile/avatar [put]
+// Delete handles DELETE /profile
+func (c *ProfileController) Delete(ctx *router.Context) error {
+	id := ctx.GetUint("user_id")
+	if id == 0 {
+		return ctx.Fail(http.StatusBadRequest, "invalid_id", types.T(ctx, "errors.invalid_id"))
+	}
+
+	result, err := c.service.DeleteAccount(ctx.Context(), id)
+	if err != nil {
+		if ctx.Canceled(err) {
+			return nil
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.Fail(http.StatusNotFound, "user_not_found", types.T(ctx, "errors.user_not_found"))
+		}
+		c.logger.Error("Failed to delete account",
+			logger.Uint("user_id", id),
+			logger.String("error", err.Error()))
+		return ctx.Fail(http.StatusInternalServerError, "delete_account_failed", "Failed to delete account")
+	}
+
+	return ctx.OK(result)
+}
+
+// UpdateAvatar handles PUT /profile/avatar
 func (c *ProfileController) UpdateAvatar(ctx *router.Context) error {
 	id := ctx.GetUint("user_id")
 	if id == 0 {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid Id format"})
+		return ctx.Fail(http.StatusBadRequest, "invalid_id", types.T(ctx, "errors.invalid_id"))
 	}
 
 	file, err := ctx.FormFile("avatar")
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Failed to get avatar file: " + err.Error()})
+		if ctx.BodyTooLarge(err) {
+			return nil
+		}
+		return ctx.Fail(http.StatusBadRequest, "invalid_avatar", "Failed to get avatar file: "+err.Error())
 	}
 
 	updatedUser, err := c.service.UpdateAvatar(ctx, uint(id), file)
 	if err != nil {
+		var invalid *storage.ValidationError
+		if errors.As(err, &invalid) {
+			return ctx.Fail(http.StatusBadRequest, "invalid_avatar_"+invalid.Check, invalid.Message)
+		}
+
 		c.logger.Error("Failed to update avatar",
 			logger.Uint("user_id", id))
 
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{Error: "User not found"})
+			return ctx.Fail(http.StatusNotFound, "user_not_found", types.T(ctx, "errors.user_not_found"))
 		} else {
-			return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to update avatar: " + err.Error()})
+			return ctx.Fail(http.StatusInternalServerError, "update_avatar_failed", "Failed to update avatar: "+err.Error())
 		}
 	}
 
-	return ctx.JSON(http.StatusOK, updatedUser)
+	return ctx.OK(updatedUser)
 }
 
-// @Summary Update profile password from Authenticated User Token
-// @Description Update profile password by Bearer Token
-// @Security ApiKeyAuth
-// @Security BearerAuth
-// @Tags Core/Profile
-// @Accept json
-// @Produce json
-// @Param input body UpdatePasswordRequest true "Update Password Request"
-// @Success 200 {object} User
-// @Failure 400 {object} types.ErrorResponse
-// @Failure 404 {object} types.ErrorResponse
-// @Failure 500 {object} types.ErrorResponse
-// @Router /profile/password [put]
+// UpdatePassword handles PUT /profile/password
 func (c *ProfileController) UpdatePassword(ctx *router.Context) error {
 	id := ctx.GetUint("user_id")
 	if id == 0 {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid user Id"})
+		return ctx.Fail(http.StatusBadRequest, "invalid_id", types.T(ctx, "errors.invalid_id"))
 	}
 
 	var req UpdatePasswordRequest
-	if err := ctx.ShouldBind(&req); err != nil {
+	if errs := types.BindAndValidate(ctx, &req); errs != nil {
 		c.logger.Error("Failed to bind password update request")
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
-	}
-
-	if len(req.NewPassword) < 6 {
-		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "New password must be at least 6 characters long"})
+		return ctx.JSON(http.StatusBadRequest, errs)
 	}
 
 	err := c.service.UpdatePassword(uint(id), &req)
 	if err != nil {
+		var weak *helper.PasswordError
+		if errors.As(err, &weak) {
+			return ctx.JSON(http.StatusBadRequest, weak.ValidationErrors("NewPassword"))
+		}
+
 		c.logger.Error("Failed to update password",
 			logger.Uint("user_id", id))
 
 		switch {
 		case errors.Is(err, gorm.ErrRecordNotFound):
-			return ctx.JSON(http.StatusNotFound, types.ErrorResponse{Error: "User not found"})
-		case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
-			return ctx.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "Current password is incorrect"})
+			return ctx.Fail(http.StatusNotFound, "user_not_found", types.T(ctx, "errors.user_not_found"))
+		case errors.Is(err, helper.ErrPasswordMismatch):
+			return ctx.Fail(http.StatusUnauthorized, "incorrect_password", types.T(ctx, "errors.incorrect_password"))
+		default:
+			return ctx.Fail(http.StatusInternalServerError, "password_failed", types.T(ctx, "errors.password_failed"))
+		}
+	}
+
+	return ctx.OK(types.SuccessResponse{Success: true, Message: "Password updated successfully"})
+}
+
+// ConfirmEmailChange handles GET /profile/email/confirm
+func (c *ProfileController) ConfirmEmailChange(ctx *router.Context) error {
+	item, err := c.service.ConfirmEmailChange(ctx.Query("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrEmailChangeNotFound):
+			return ctx.Fail(http.StatusNotFound, "invalid_token", types.T(ctx, "errors.invalid_token"))
+		case errors.Is(err, ErrEmailChangeExpired):
+			return ctx.Fail(http.StatusGone, "token_expired", types.T(ctx, "errors.invalid_token"))
+		case errors.Is(err, ErrEmailTaken):
+			return ctx.Fail(http.StatusConflict, "email_taken", "Email is already in use")
 		default:
-			return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to update password"})
+			c.logger.Error("Failed to confirm email change", logger.String("error", err.Error()))
+			return ctx.Fail(http.StatusInternalServerError, "confirm_email_failed", "Failed to confirm email change")
+		}
+	}
+
+	return ctx.OK(item)
+}
+
+// CancelEmailChange handles DELETE /profile/email/pending
+func (c *ProfileController) CancelEmailChange(ctx *router.Context) error {
+	id := ctx.GetUint("user_id")
+	if id == 0 {
+		return ctx.Fail(http.StatusBadRequest, "invalid_id", types.T(ctx, "errors.invalid_id"))
+	}
+
+	item, err := c.service.CancelEmailChange(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.Fail(http.StatusNotFound, "user_not_found", types.T(ctx, "errors.user_not_found"))
 		}
+		c.logger.Error("Failed to cancel email change",
+			logger.Uint("user_id", id))
+		return ctx.Fail(http.StatusInternalServerError, "cancel_email_change_failed", "Failed to cancel email change")
+	}
+
+	return ctx.OK(item)
+}
+
+// GetSettings handles GET /profile/settings
+func (c *ProfileController) GetSettings(ctx *router.Context) error {
+	id := ctx.GetUint("user_id")
+	if id == 0 {
+		return ctx.Fail(http.StatusBadRequest, "invalid_id", types.T(ctx, "errors.invalid_id"))
+	}
+
+	settings, err := c.service.GetSettings(id)
+	if err != nil {
+		c.logger.Error("Failed to get settings",
+			logger.Uint("user_id", id))
+		return ctx.Fail(http.StatusInternalServerError, "fetch_settings_failed", "Failed to get settings")
+	}
+
+	return ctx.OK(settings)
+}
+
+// UpdateSettings handles PATCH /profile/settings
+func (c *ProfileController) UpdateSettings(ctx *router.Context) error {
+	id := ctx.GetUint("user_id")
+	if id == 0 {
+		return ctx.Fail(http.StatusBadRequest, "invalid_id", types.T(ctx, "errors.invalid_id"))
+	}
+
+	var patch map[string]any
+	if err := ctx.BindJSON(&patch); err != nil || patch == nil {
+		return ctx.Fail(http.StatusBadRequest, "invalid_request", "Request body must be a JSON object")
+	}
+
+	settings, err := c.service.UpdateSettings(id, patch)
+	if err != nil {
+		var validationErr *SettingsValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			return ctx.Negotiate(http.StatusUnprocessableEntity, map[string]any{
+				"success": false,
+				"code":    "invalid_settings",
+				"error":   "Invalid settings",
+				"errors":  validationErr.Errors,
+			})
+		case errors.Is(err, ErrSettingsConflict):
+			return ctx.Fail(http.StatusConflict, "settings_conflict", err.Error())
+		}
+		c.logger.Error("Failed to update settings",
+			logger.Uint("user_id", id))
+		return ctx.Fail(http.StatusInternalServerError, "update_settings_failed", "Failed to update settings")
 	}
 
-	return ctx.JSON(http.StatusOK, types.SuccessResponse{Message: "Password updated successfully"})
+	return ctx.OK(settings)
 }
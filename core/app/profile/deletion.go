@@ -0,0 +1,134 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"base/core/database"
+	"base/core/events"
+	"base/core/logger"
+	"base/core/storage"
+	"base/core/types"
+
+	"gorm.io/gorm"
+)
+
+// DeleteAccount deletes the user's account, or with a grace period signs the
+// user out everywhere and schedules the deletion; PurgeScheduledDeletions
+// carries it out once due. Signing in again before then cancels it.
+func (s *ProfileService) DeleteAccount(ctx context.Context, id uint) (*DeletionResponse, error) {
+	var user User
+	if err := s.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if s.deletionGrace <= 0 {
+		if err := s.purge(ctx, &user); err != nil {
+			return nil, err
+		}
+		return &DeletionResponse{Deleted: true}, nil
+	}
+
+	// Bumping the token version signs out every existing session
+	scheduled := time.Now().Add(s.deletionGrace)
+	err := s.db.WithContext(ctx).Model(&user).Updates(map[string]any{
+		"deletion_scheduled_at": scheduled,
+		"token_version":         gorm.Expr("token_version + 1"),
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+
+	if s.emitter != nil {
+		s.emitter.Emit("user.deletion_scheduled", types.UserData{
+			Id:        user.Id,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Username:  user.Username,
+			Email:     user.Email,
+		})
+	}
+	return &DeletionResponse{ScheduledFor: &scheduled}, nil
+}
+
+// PurgeScheduledDeletions deletes the accounts whose grace period is over
+func (s *ProfileService) PurgeScheduledDeletions(ctx context.Context) error {
+	var due []User
+	err := s.db.WithContext(ctx).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", time.Now()).
+		Find(&due).Error
+	if err != nil {
+		return fmt.Errorf("failed to load scheduled deletions: %w", err)
+	}
+
+	for i := range due {
+		if err := s.purge(ctx, &due[i]); err != nil {
+			// Left scheduled, so the next run retries it
+			s.logger.Error("Failed to purge account",
+				logger.Uint("user_id", due[i].Id),
+				logger.String("error", err.Error()))
+		}
+	}
+	return nil
+}
+
+// purge removes the user's files and settings, then anonymizes or deletes
+// the user and publishes user.deleted through the outbox in the same
+// transaction. Modules listen for it to remove rows of their own.
+func (s *ProfileService) purge(ctx context.Context, user *User) error {
+	var attachments []*storage.Attachment
+	err := s.db.WithContext(ctx).
+		Where("model_type = ? AND model_id = ?", user.GetModelName(), user.Id).
+		Find(&attachments).Error
+	if err != nil {
+		return fmt.Errorf("failed to load user files: %w", err)
+	}
+	for _, attachment := range attachments {
+		if err := s.activeStorage.Delete(attachment); err != nil {
+			return fmt.Errorf("failed to delete user file %d: %w", attachment.Id, err)
+		}
+	}
+
+	deleted := types.UserData{
+		Id:        user.Id,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Username:  user.Username,
+		Email:     user.Email,
+	}
+
+	return database.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.Id).Delete(&UserSettings{}).Error; err != nil {
+			return fmt.Errorf("failed to delete user settings: %w", err)
+		}
+
+		if s.deletionMode == "delete" {
+			if err := tx.Unscoped().Delete(&User{}, user.Id).Error; err != nil {
+				return fmt.Errorf("failed to delete user: %w", err)
+			}
+		} else {
+			// Unique columns get values no real account can have
+			anonymized := fmt.Sprintf("deleted-%d", user.Id)
+			err := tx.Unscoped().Model(&User{}).Where("id = ?", user.Id).Updates(map[string]any{
+				"first_name":              "Deleted",
+				"last_name":               "User",
+				"username":                anonymized,
+				"email":                   anonymized + "@deleted.invalid",
+				"phone":                   anonymized,
+				"password":                "",
+				"pending_email":           "",
+				"email_change_token_hash": "",
+				"email_change_expires_at": nil,
+				"deletion_scheduled_at":   nil,
+				"token_version":           gorm.Expr("token_version + 1"),
+				"deleted_at":              time.Now(),
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to anonymize user: %w", err)
+			}
+		}
+
+		return events.Publish(tx, "user.deleted", deleted)
+	})
+}
@@ -0,0 +1,153 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"base/core/emitter"
+	"base/core/events"
+	"base/core/logger"
+	"base/core/storage"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newDeletionService returns a service holding user 1 with an avatar and
+// settings, and the path of the avatar file
+func newDeletionService(t *testing.T, mode string, grace time.Duration) (*ProfileService, string) {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "profile.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&User{}, &UserSettings{}, &events.OutboxEvent{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := db.Create(&User{Email: "ada@example.com", Username: "ada", Phone: "1", Password: "hash"}).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	root := filepath.Join(dir, "storage")
+	as, err := storage.NewActiveStorage(db, storage.Config{Provider: "local", Path: root})
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+
+	s := NewProfileService(db, logger.NewLoggerFromZap(zap.NewNop()), as, emitter.New(), nil)
+	s.deletionMode = mode
+	s.deletionGrace = grace
+
+	if _, err := s.UpdateAvatar(context.Background(), 1, avatarFile(t, "ada.png", pngImage(t, 64, 64))); err != nil {
+		t.Fatalf("upload avatar: %v", err)
+	}
+	if _, err := s.UpdateSettings(1, map[string]any{"theme": "dark"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var avatar storage.Attachment
+	if err := db.Where("model_type = ? AND model_id = ?", "users", 1).First(&avatar).Error; err != nil {
+		t.Fatalf("avatar attachment: %v", err)
+	}
+	return s, filepath.Join(root, avatar.Path)
+}
+
+// assertPurged checks the user's files, settings and sessions are gone and
+// user.deleted was published
+func assertPurged(t *testing.T, s *ProfileService, avatar string, versionBefore uint) {
+	t.Helper()
+	if _, err := os.Stat(avatar); !os.IsNotExist(err) {
+		t.Errorf("avatar file still stored: %v", err)
+	}
+	var attachments, settings, outbox int64
+	s.db.Model(&storage.Attachment{}).Where("model_id = 1").Count(&attachments)
+	s.db.Model(&UserSettings{}).Where("user_id = 1").Count(&settings)
+	s.db.Model(&events.OutboxEvent{}).Where("event = ?", "user.deleted").Count(&outbox)
+	if attachments != 0 || settings != 0 {
+		t.Errorf("%d attachments and %d settings rows left", attachments, settings)
+	}
+	if outbox != 1 {
+		t.Errorf("%d user.deleted events published, want 1", outbox)
+	}
+	if version, err := s.TokenVersion(1); err == nil && version <= versionBefore {
+		t.Errorf("token version = %d, want above %d so issued tokens stop working", version, versionBefore)
+	}
+}
+
+func TestDeleteAccountAnonymizes(t *testing.T) {
+	s, avatar := newDeletionService(t, "anonymize", 0)
+	before, _ := s.TokenVersion(1)
+
+	resp, err := s.DeleteAccount(context.Background(), 1)
+	if err != nil || !resp.Deleted {
+		t.Fatalf("DeleteAccount = %+v, %v", resp, err)
+	}
+	assertPurged(t, s, avatar, before)
+
+	var user User
+	if err := s.db.Unscoped().First(&user, 1).Error; err != nil {
+		t.Fatalf("anonymized row: %v", err)
+	}
+	if user.Email != "deleted-1@deleted.invalid" || user.Username != "deleted-1" || user.Password != "" || !user.DeletedAt.Valid {
+		t.Errorf("user = %+v, want anonymized and soft deleted", user)
+	}
+	if user.TokenVersion <= before {
+		t.Errorf("token version = %d, want above %d", user.TokenVersion, before)
+	}
+	if _, err := s.GetById(1); err == nil {
+		t.Error("GetById found the deleted user")
+	}
+}
+
+func TestDeleteAccountHardDeletes(t *testing.T) {
+	s, avatar := newDeletionService(t, "delete", 0)
+
+	if _, err := s.DeleteAccount(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	assertPurged(t, s, avatar, 0)
+	var rows int64
+	s.db.Unscoped().Model(&User{}).Where("id = 1").Count(&rows)
+	if rows != 0 {
+		t.Error("user row kept in delete mode")
+	}
+
+	if _, err := s.DeleteAccount(context.Background(), 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("deleting again = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func TestDeleteAccountGracePeriod(t *testing.T) {
+	s, avatar := newDeletionService(t, "anonymize", 24*time.Hour)
+	before, _ := s.TokenVersion(1)
+
+	resp, err := s.DeleteAccount(context.Background(), 1)
+	if err != nil || resp.Deleted || resp.ScheduledFor == nil {
+		t.Fatalf("DeleteAccount = %+v, %v; want a scheduled deletion", resp, err)
+	}
+
+	// Signed out right away, but nothing is removed yet
+	if version, _ := s.TokenVersion(1); version != before+1 {
+		t.Errorf("token version = %d, want %d", version, before+1)
+	}
+	if _, err := os.Stat(avatar); err != nil {
+		t.Errorf("avatar removed during the grace period: %v", err)
+	}
+	if err := s.PurgeScheduledDeletions(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.GetById(1); got == nil || got.Email != "ada@example.com" {
+		t.Fatalf("purged before the grace period ended: %+v", got)
+	}
+
+	s.db.Model(&User{}).Where("id = 1").Update("deletion_scheduled_at", time.Now().Add(-time.Minute))
+	if err := s.PurgeScheduledDeletions(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertPurged(t, s, avatar, before+1)
+}
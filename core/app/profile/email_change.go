@@ -0,0 +1,184 @@
+package profile
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"base/core/jobs"
+	"base/core/logger"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailTaken          = errors.New("email is already in use")
+	ErrEmailChangeNotFound = errors.New("email change not found")
+	ErrEmailChangeExpired  = errors.New("email change link has expired")
+)
+
+// requestEmailChange makes email the user's pending email with a fresh
+// confirmation token, replacing any earlier request. The caller saves user
+// and then sends the returned token with sendEmailChange.
+func (s *ProfileService) requestEmailChange(user *User, email string) (string, error) {
+	if err := s.emailAvailable(user.Id, email); err != nil {
+		return "", err
+	}
+
+	token, err := newEmailChangeToken()
+	if err != nil {
+		return "", err
+	}
+	expires := time.Now().Add(s.emailChangeTTL)
+	user.PendingEmail = email
+	user.EmailChangeTokenHash = hashEmailChangeToken(token)
+	user.EmailChangeExpiresAt = &expires
+	return token, nil
+}
+
+// sendEmailChange queues the confirmation link for the pending email and
+// emits user.email_change_requested
+func (s *ProfileService) sendEmailChange(user *User, token string) {
+	err := jobs.Enqueue(EmailChangeEmailJob, emailChangePayload{
+		Email:     user.PendingEmail,
+		FirstName: user.FirstName,
+		URL:       strings.ReplaceAll(s.emailChangeURL, "{token}", token),
+		Expires:   *user.EmailChangeExpiresAt,
+	})
+	if err != nil {
+		s.logger.Error("Failed to enqueue email change confirmation",
+			logger.Uint("user_id", user.Id),
+			logger.String("error", err.Error()))
+	}
+
+	if s.emitter != nil {
+		s.emitter.Emit("user.email_change_requested", EmailChangeEvent{
+			UserId:    user.Id,
+			OldEmail:  user.Email,
+			NewEmail:  user.PendingEmail,
+			ExpiresAt: user.EmailChangeExpiresAt,
+		})
+	}
+}
+
+// ConfirmEmailChange makes the pending email of the user holding token their
+// email and emits user.email_changed
+func (s *ProfileService) ConfirmEmailChange(token string) (*UserResponse, error) {
+	if token == "" {
+		return nil, ErrEmailChangeNotFound
+	}
+
+	var user User
+	err := s.db.Where("email_change_token_hash = ?", hashEmailChangeToken(token)).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmailChangeNotFound
+		}
+		return nil, err
+	}
+	if user.EmailChangeExpiresAt == nil || time.Now().After(*user.EmailChangeExpiresAt) {
+		return nil, ErrEmailChangeExpired
+	}
+
+	// Another account may have taken the address since it was requested
+	if err := s.emailAvailable(user.Id, user.PendingEmail); err != nil {
+		return nil, err
+	}
+
+	oldEmail := user.Email
+	result := s.db.Model(&User{}).
+		Where("id = ? AND email_change_token_hash = ?", user.Id, user.EmailChangeTokenHash).
+		Updates(map[string]any{
+			"email":                   user.PendingEmail,
+			"pending_email":           "",
+			"email_change_token_hash": "",
+			"email_change_expires_at": nil,
+		})
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, ErrEmailTaken
+		}
+		return nil, fmt.Errorf("failed to change email: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// Confirmed or replaced by a concurrent request
+		return nil, ErrEmailChangeNotFound
+	}
+
+	user.Email = user.PendingEmail
+	user.PendingEmail = ""
+	user.EmailChangeTokenHash = ""
+	user.EmailChangeExpiresAt = nil
+
+	if s.emitter != nil {
+		s.emitter.Emit("user.email_changed", EmailChangeEvent{
+			UserId:   user.Id,
+			OldEmail: oldEmail,
+			NewEmail: user.Email,
+		})
+	}
+
+	return s.ToResponse(&user), nil
+}
+
+// CancelEmailChange drops the user's pending email, if any; its link stops
+// working
+func (s *ProfileService) CancelEmailChange(id uint) (*UserResponse, error) {
+	var user User
+	if err := s.db.First(&user, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.EmailChangeTokenHash == "" {
+		return s.ToResponse(&user), nil
+	}
+
+	err := s.db.Model(&user).Updates(map[string]any{
+		"pending_email":           "",
+		"email_change_token_hash": "",
+		"email_change_expires_at": nil,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel email change: %w", err)
+	}
+	user.PendingEmail = ""
+	user.EmailChangeTokenHash = ""
+	user.EmailChangeExpiresAt = nil
+
+	return s.ToResponse(&user), nil
+}
+
+// emailAvailable returns ErrEmailTaken when another user has email as their
+// email, deleted or not, or has it pending confirmation
+func (s *ProfileService) emailAvailable(id uint, email string) error {
+	var count int64
+	err := s.db.Unscoped().Model(&User{}).
+		Where("id <> ?", id).
+		Where("email = ? OR (pending_email = ? AND email_change_expires_at > ? AND deleted_at IS NULL)",
+			email, email, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("failed to check email: %w", err)
+	}
+	if count > 0 {
+		return ErrEmailTaken
+	}
+	return nil
+}
+
+func newEmailChangeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashEmailChangeToken is what's stored; the token itself is only sent by email
+func hashEmailChangeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
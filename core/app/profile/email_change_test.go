@@ -0,0 +1,220 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"base/core/emitter"
+	"base/core/jobs"
+	"base/core/logger"
+	"base/core/storage"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// mailbox captures the confirmation emails queued by the service
+type mailbox struct {
+	mu   sync.Mutex
+	sent []emailChangePayload
+}
+
+func (m *mailbox) Enqueue(ctx context.Context, name string, payload any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if name == EmailChangeEmailJob {
+		m.sent = append(m.sent, payload.(emailChangePayload))
+	}
+	return nil
+}
+
+func (m *mailbox) Start(ctx context.Context) error { return nil }
+func (m *mailbox) Stop(ctx context.Context) error  { return nil }
+
+// last returns the most recent confirmation email
+func (m *mailbox) last(t *testing.T) emailChangePayload {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.sent) == 0 {
+		t.Fatal("no confirmation email queued")
+	}
+	return m.sent[len(m.sent)-1]
+}
+
+// newEmailChangeService returns a service with users ada (1) and bob (2)
+// whose links are the bare token
+func newEmailChangeService(t *testing.T) (*ProfileService, *mailbox, map[string][]EmailChangeEvent) {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "profile.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&User{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	users := []User{
+		{Email: "ada@example.com", Username: "ada", Phone: "1"},
+		{Email: "bob@example.com", Username: "bob", Phone: "2"},
+	}
+	if err := db.Create(&users).Error; err != nil {
+		t.Fatalf("create users: %v", err)
+	}
+	as, err := storage.NewActiveStorage(db, storage.Config{Provider: "local", Path: filepath.Join(dir, "storage")})
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+
+	mail := &mailbox{}
+	saved := jobs.Default()
+	jobs.SetDefault(mail)
+	t.Cleanup(func() { jobs.SetDefault(saved) })
+
+	em := emitter.New()
+	var mu sync.Mutex
+	events := make(map[string][]EmailChangeEvent)
+	for _, name := range []string{"user.email_change_requested", "user.email_changed"} {
+		em.On(name, func(data any) {
+			mu.Lock()
+			defer mu.Unlock()
+			events[name] = append(events[name], data.(EmailChangeEvent))
+		})
+	}
+
+	s := NewProfileService(db, logger.NewLoggerFromZap(zap.NewNop()), as, em, nil)
+	s.emailChangeURL = "{token}"
+	return s, mail, events
+}
+
+func currentEmail(t *testing.T, s *ProfileService, id uint) string {
+	t.Helper()
+	var user User
+	if err := s.db.First(&user, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user.Email
+}
+
+func TestEmailChangeConfirm(t *testing.T) {
+	s, mail, events := newEmailChangeService(t)
+
+	resp, err := s.Update(1, &UpdateRequest{Email: "ada@new.example"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Email != "ada@example.com" || resp.PendingEmail != "ada@new.example" {
+		t.Errorf("response email = %q pending %q, want the old one active", resp.Email, resp.PendingEmail)
+	}
+	if got := currentEmail(t, s, 1); got != "ada@example.com" {
+		t.Errorf("email = %q before confirmation", got)
+	}
+
+	link := mail.last(t)
+	if link.Email != "ada@new.example" || link.URL == "" {
+		t.Fatalf("confirmation = %+v, want a link sent to the new address", link)
+	}
+	if requested := events["user.email_change_requested"]; len(requested) != 1 ||
+		requested[0].OldEmail != "ada@example.com" || requested[0].NewEmail != "ada@new.example" {
+		t.Errorf("requested events = %+v", requested)
+	}
+
+	// Only the hash is stored
+	var stored User
+	s.db.First(&stored, 1)
+	if stored.EmailChangeTokenHash == link.URL {
+		t.Error("token stored in plain text")
+	}
+
+	confirmed, err := s.ConfirmEmailChange(link.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confirmed.Email != "ada@new.example" || confirmed.PendingEmail != "" {
+		t.Errorf("confirmed = %+v", confirmed)
+	}
+	if got := currentEmail(t, s, 1); got != "ada@new.example" {
+		t.Errorf("email = %q after confirmation", got)
+	}
+	if changed := events["user.email_changed"]; len(changed) != 1 || changed[0].OldEmail != "ada@example.com" {
+		t.Errorf("changed events = %+v", changed)
+	}
+
+	// Links work once
+	if _, err := s.ConfirmEmailChange(link.URL); !errors.Is(err, ErrEmailChangeNotFound) {
+		t.Errorf("second confirmation = %v, want ErrEmailChangeNotFound", err)
+	}
+}
+
+func TestEmailChangeCancelAndExpiry(t *testing.T) {
+	s, mail, _ := newEmailChangeService(t)
+
+	if _, err := s.Update(1, &UpdateRequest{Email: "first@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	first := mail.last(t).URL
+
+	// A new request replaces the earlier link
+	if _, err := s.Update(1, &UpdateRequest{Email: "second@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	second := mail.last(t).URL
+	if _, err := s.ConfirmEmailChange(first); !errors.Is(err, ErrEmailChangeNotFound) {
+		t.Errorf("replaced link = %v, want ErrEmailChangeNotFound", err)
+	}
+
+	resp, err := s.CancelEmailChange(1)
+	if err != nil || resp.PendingEmail != "" {
+		t.Fatalf("cancel = %+v, %v", resp, err)
+	}
+	if _, err := s.ConfirmEmailChange(second); !errors.Is(err, ErrEmailChangeNotFound) {
+		t.Errorf("canceled link = %v, want ErrEmailChangeNotFound", err)
+	}
+	if got := currentEmail(t, s, 1); got != "ada@example.com" {
+		t.Errorf("email = %q after cancel", got)
+	}
+
+	s.emailChangeTTL = -time.Minute
+	if _, err := s.Update(1, &UpdateRequest{Email: "late@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ConfirmEmailChange(mail.last(t).URL); !errors.Is(err, ErrEmailChangeExpired) {
+		t.Errorf("expired link = %v, want ErrEmailChangeExpired", err)
+	}
+	if _, err := s.ConfirmEmailChange(""); !errors.Is(err, ErrEmailChangeNotFound) {
+		t.Errorf("empty token = %v, want ErrEmailChangeNotFound", err)
+	}
+}
+
+func TestEmailChangeUniqueness(t *testing.T) {
+	s, mail, _ := newEmailChangeService(t)
+
+	if _, err := s.Update(1, &UpdateRequest{Email: "bob@example.com"}); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("another user's email = %v, want ErrEmailTaken", err)
+	}
+
+	// Pending emails are reserved too
+	if _, err := s.Update(1, &UpdateRequest{Email: "shared@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	link := mail.last(t).URL
+	if _, err := s.Update(2, &UpdateRequest{Email: "shared@example.com"}); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("another user's pending email = %v, want ErrEmailTaken", err)
+	}
+
+	// An address taken after the request can't be confirmed
+	if err := s.db.Model(&User{}).Where("id = 2").Update("email", "shared@example.com").Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ConfirmEmailChange(link); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("confirming a taken address = %v, want ErrEmailTaken", err)
+	}
+	if got := currentEmail(t, s, 1); got != "ada@example.com" {
+		t.Errorf("email = %q", got)
+	}
+}
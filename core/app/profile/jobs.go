@@ -0,0 +1,50 @@
+package profile
+
+import (
+	"context"
+	"html"
+	"time"
+
+	"base/core/email"
+	"base/core/jobs"
+)
+
+// EmailChangeEmailJob sends the confirmation link to a new email address
+const EmailChangeEmailJob = "profile.email_change_email"
+
+type emailChangePayload struct {
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	URL       string    `json:"url"`
+	Expires   time.Time `json:"expires"`
+}
+
+// registerJobs registers the profile email job handler
+func (s *ProfileService) registerJobs() {
+	jobs.Register(EmailChangeEmailJob, s.emailChangeEmailJob)
+}
+
+func (s *ProfileService) emailChangeEmailJob(ctx context.Context, job *jobs.Job) error {
+	var payload emailChangePayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	return email.Send(email.Message{
+		To:      []string{payload.Email},
+		From:    "no-reply@base.al",
+		Subject: "Confirm your new email address",
+		Body:    emailChangeEmailBody(payload),
+		IsHTML:  true,
+	})
+}
+
+func emailChangeEmailBody(payload emailChangePayload) string {
+	url := html.EscapeString(payload.URL)
+	return "<h1>Confirm your new email address</h1>" +
+		"<p>Hi " + html.EscapeString(payload.FirstName) + ",</p>" +
+		"<p>Confirm that you want to use this address for your Base account. Until you do, your current address stays in use.</p>" +
+		"<p><a href=\"" + url + "\">Confirm email address</a></p>" +
+		"<p>Or open this link: " + url + "</p>" +
+		"<p>The link expires on " + payload.Expires.Format("January 2, 2006 at 15:04 MST") + ". If you didn't ask for this change, ignore this email.</p>"
+}
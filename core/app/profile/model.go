@@ -8,31 +8,63 @@ import (
 )
 
 type User struct {
-	Id        uint                `gorm:"column:id;primary_key;auto_increment"`
-	FirstName string              `gorm:"column:first_name;not null;size:255"`
-	LastName  string              `gorm:"column:last_name;not null;size:255"`
-	Username  string              `gorm:"column:username;unique;not null;size:255"`
-	Phone     string              `gorm:"column:phone;unique;size:255"`
-	Email     string              `gorm:"column:email;unique;not null;size:255"`
-	Avatar    *storage.Attachment `gorm:"foreignKey:ModelId;references:Id"`
-	Password  string              `gorm:"column:password;size:255"`
-	LastLogin *time.Time          `gorm:"column:last_login"`
-	CreatedAt time.Time           `gorm:"column:created_at"`
-	UpdatedAt time.Time           `gorm:"column:updated_at"`
-	DeletedAt gorm.DeletedAt      `gorm:"column:deleted_at"`
+	Id           uint                `gorm:"column:id;primary_key;auto_increment"`
+	FirstName    string              `gorm:"column:first_name;not null;size:255"`
+	LastName     string              `gorm:"column:last_name;not null;size:255"`
+	Username     string              `gorm:"column:username;unique;not null;size:255"`
+	Phone        string              `gorm:"column:phone;unique;size:255"`
+	Email        string              `gorm:"column:email;unique;not null;size:255"`
+	PendingEmail string              `gorm:"column:pending_email;size:255;index"`
+	Avatar       *storage.Attachment `gorm:"foreignKey:ModelId;references:Id"`
+	Password     string              `gorm:"column:password;size:255"`
+	LastLogin    *time.Time          `gorm:"column:last_login"`
+	TokenVersion uint                `gorm:"column:token_version;not null;default:0"`
+
+	// The pending email becomes Email once the link sent to it is opened
+	EmailChangeTokenHash string     `gorm:"column:email_change_token_hash;size:64;index"`
+	EmailChangeExpiresAt *time.Time `gorm:"column:email_change_expires_at"`
+
+	// Set by DELETE /profile with a grace period; the account is purged then
+	DeletionScheduledAt *time.Time `gorm:"column:deletion_scheduled_at;index"`
+
+	CreatedAt time.Time      `gorm:"column:created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
 }
 
 func (User) TableName() string {
 	return "users"
 }
 
+// BeforeCreate leaves an empty phone NULL, so users without one don't collide
+// on its unique index
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.Phone == "" {
+		tx.Statement.Omit("phone")
+	}
+	return nil
+}
+
+// UserSettings stores arbitrary per-user preferences as a JSON object
+type UserSettings struct {
+	Id        uint      `gorm:"column:id;primary_key;auto_increment"`
+	UserId    uint      `gorm:"column:user_id;not null;uniqueIndex"`
+	Settings  string    `gorm:"column:settings;type:text;not null"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (UserSettings) TableName() string {
+	return "user_settings"
+}
+
 type CreateRequest struct {
 	FirstName string `json:"first_name" binding:"required,max=255"`
 	LastName  string `json:"last_name" binding:"required,max=255"`
 	Username  string `json:"username" binding:"required,max=255"`
 	Phone     string `json:"phone" binding:"max=255"`
 	Email     string `json:"email" binding:"required,email,max=255"`
-	Password  string `json:"password" binding:"required,min=8,max=255"`
+	Password  string `json:"password" binding:"required,max=255"`
 }
 
 type UpdateRequest struct {
@@ -40,12 +72,29 @@ type UpdateRequest struct {
 	LastName  string `form:"last_name" binding:"max=255"`
 	Username  string `form:"username" binding:"max=255"`
 	Phone     string `form:"phone" binding:"max=255"`
-	Email     string `form:"email" binding:"email,max=255"`
+	Email     string `form:"email" binding:"omitempty,email,max=255"`
+}
+
+// EmailChangeEvent is the payload of user.email_change_requested, where
+// ExpiresAt is when the confirmation link stops working, and of
+// user.email_changed
+type EmailChangeEvent struct {
+	UserId    uint       `json:"user_id"`
+	OldEmail  string     `json:"old_email"`
+	NewEmail  string     `json:"new_email"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// DeletionResponse reports the outcome of DELETE /profile: deleted right
+// away, or scheduled for ScheduledFor when a grace period is configured
+type DeletionResponse struct {
+	Deleted      bool       `json:"deleted"`
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
 }
 
 type UpdatePasswordRequest struct {
 	OldPassword string `form:"OldPassword" binding:"required,max=255"`
-	NewPassword string `form:"NewPassword" binding:"required,min=6,max=255"`
+	NewPassword string `form:"NewPassword" binding:"required,max=255"`
 }
 
 // Implement the Attachable interface
@@ -65,8 +114,10 @@ type UserResponse struct {
 	Username  string `json:"username"`
 	Phone     string `json:"phone"`
 	Email     string `json:"email"`
-	AvatarURL string `json:"avatar_url"`
-	LastLogin string `json:"last_login"`
+	// PendingEmail is waiting for confirmation; Email stays in use until then
+	PendingEmail string `json:"pending_email,omitempty"`
+	AvatarURL    string `json:"avatar_url"`
+	LastLogin    string `json:"last_login"`
 }
 
 // AvatarResponse represents the avatar in API responses
@@ -89,6 +140,9 @@ func (u *User) ToResponse() *UserResponse {
 		Phone:     u.Phone,
 		Email:     u.Email,
 	}
+	if u.EmailChangeExpiresAt != nil && time.Now().Before(*u.EmailChangeExpiresAt) {
+		response.PendingEmail = u.PendingEmail
+	}
 
 	if u.Avatar != nil {
 		response.AvatarURL = u.Avatar.URL
@@ -1,10 +1,17 @@
 package profile
 
 import (
+	"base/core/config"
+	"base/core/emitter"
+	"base/core/events"
 	"base/core/logger"
 	"base/core/module"
 	"base/core/router"
+	"base/core/router/middleware"
+	"base/core/scheduler"
 	"base/core/storage"
+	"base/core/types"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -23,9 +30,11 @@ func NewUserModule(
 	router *router.RouterGroup,
 	logger logger.Logger,
 	activeStorage *storage.ActiveStorage,
+	emitter *emitter.Emitter,
+	cfg *config.Config,
 ) module.Module {
 	// Initialize service with active storage
-	service := NewProfileService(db, logger, activeStorage)
+	service := NewProfileService(db, logger, activeStorage, emitter, cfg)
 	controller := NewProfileController(service, logger)
 
 	usersModule := &UserModule{
@@ -39,12 +48,38 @@ func NewUserModule(
 	return usersModule
 }
 
+// DependsOn initializes the modules the profile uses first: email change
+// confirmations go through the jobs queue, scheduled account deletions run
+// on the scheduler and user.deleted is published through the events outbox
+func (m *UserModule) DependsOn() []string {
+	return []string{"events", "jobs", "scheduler"}
+}
+
+// Init rejects tokens issued before the user's last password change,
+// registers the email change job and schedules purging deleted accounts
+func (m *UserModule) Init() error {
+	types.SetTokenVersionFunc(m.Service.TokenVersion)
+	m.Service.registerJobs()
+	events.Register("user.deleted", types.UserData{})
+
+	err := scheduler.Every("0 * * * *", "profile.purge-deleted-accounts", m.Service.PurgeScheduledDeletions,
+		scheduler.WithDescription("Purge accounts whose deletion grace period is over"),
+		scheduler.WithJitter(time.Minute))
+	if err != nil {
+		m.Logger.Error("Failed to schedule account purging", logger.String("error", err.Error()))
+	}
+	return nil
+}
+
 func (m *UserModule) Routes(router *router.RouterGroup) {
-	m.Controller.Routes(router)
+	// Every profile route acts on the user identified by the bearer token
+	m.Controller.Routes(router.Group("", middleware.BearerAuth()).Security("BearerAuth"))
+	// Email change links are opened from the new inbox, possibly signed out
+	m.Controller.PublicRoutes(router.Group("", middleware.Api()).Security("ApiKeyAuth"))
 }
 
 func (m *UserModule) Migrate() error {
-	err := m.DB.AutoMigrate(&User{})
+	err := m.DB.AutoMigrate(&User{}, &UserSettings{})
 	if err != nil {
 		m.Logger.Error("Migration failed", logger.String("error", err.Error()))
 		return err
@@ -55,6 +90,7 @@ func (m *UserModule) Migrate() error {
 func (m *UserModule) GetModels() []any {
 	return []any{
 		&User{},
+		&UserSettings{},
 	}
 }
 
@@ -0,0 +1,40 @@
+package profile
+
+import (
+	"errors"
+	"testing"
+
+	"base/core/helper"
+)
+
+func TestUpdatePasswordEnforcesPolicy(t *testing.T) {
+	saved := helper.CurrentPasswordPolicy()
+	helper.SetPasswordPolicy(helper.PasswordPolicy{MinLength: 10, RequireSymbol: true})
+	t.Cleanup(func() { helper.SetPasswordPolicy(saved) })
+
+	s, _ := newDeletionService(t, "", 0)
+	hash, err := helper.Passwords().Hash("old password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.db.Model(&User{}).Where("id = 1").Update("password", hash)
+
+	err = s.UpdatePassword(1, &UpdatePasswordRequest{OldPassword: "old password", NewPassword: "nosymbols1"})
+	var weak *helper.PasswordError
+	if !errors.As(err, &weak) || len(weak.Reasons) != 1 || weak.Reasons[0].Code != helper.PasswordMissingSymbol {
+		t.Fatalf("UpdatePassword(weak) = %v, want a missing_symbol PasswordError", err)
+	}
+	var user User
+	s.db.First(&user, 1)
+	if helper.Passwords().Verify(user.Password, "old password") != nil {
+		t.Error("a weak password replaced the old one")
+	}
+
+	if err := s.UpdatePassword(1, &UpdatePasswordRequest{OldPassword: "old password", NewPassword: "with-symbols"}); err != nil {
+		t.Fatalf("UpdatePassword: %v", err)
+	}
+	s.db.First(&user, 1)
+	if helper.Passwords().Verify(user.Password, "with-symbols") != nil {
+		t.Error("new password not stored")
+	}
+}
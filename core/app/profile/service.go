@@ -1,15 +1,19 @@
 package profile
 
 import (
+	"base/core/config"
+	"base/core/emitter"
+	"base/core/helper"
 	"base/core/logger"
 	"base/core/storage"
+	"base/core/types"
 	"context"
 	"errors"
 	"fmt"
 	"mime/multipart"
+	"time"
 
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -17,9 +21,17 @@ type ProfileService struct {
 	db            *gorm.DB
 	logger        logger.Logger
 	activeStorage *storage.ActiveStorage
+	emitter       *emitter.Emitter
+	settings      settingsSchema
+
+	emailChangeTTL time.Duration
+	emailChangeURL string // with {token} replaced by the confirmation token
+
+	deletionMode  string // "anonymize" or "delete"
+	deletionGrace time.Duration
 }
 
-func NewProfileService(db *gorm.DB, logger logger.Logger, activeStorage *storage.ActiveStorage) *ProfileService {
+func NewProfileService(db *gorm.DB, logger logger.Logger, activeStorage *storage.ActiveStorage, emitter *emitter.Emitter, cfg *config.Config) *ProfileService {
 	if db == nil {
 		panic("db is required")
 	}
@@ -31,19 +43,58 @@ func NewProfileService(db *gorm.DB, logger logger.Logger, activeStorage *storage
 	}
 
 	// Register avatar attachment configuration
-	activeStorage.RegisterAttachment("users", storage.AttachmentConfig{
-		Field:             "avatar",
-		Path:              "avatars",
-		AllowedExtensions: []string{".jpg", ".jpeg", ".png", ".gif"},
-		MaxFileSize:       5 << 20, // 5MB
-		Multiple:          false,
-	})
-
-	return &ProfileService{
-		db:            db,
-		logger:        logger,
-		activeStorage: activeStorage,
+	avatar := storage.AttachmentConfig{
+		Field:               "avatar",
+		Path:                "avatars",
+		AllowedExtensions:   []string{".jpg", ".jpeg", ".png", ".webp"},
+		AllowedContentTypes: []string{"image/jpeg", "image/png", "image/webp"},
+		MaxFileSize:         config.DefaultAvatarMaxSize,
+		MaxWidth:            config.DefaultAvatarMaxDimension,
+		MaxHeight:           config.DefaultAvatarMaxDimension,
+		Multiple:            false,
+	}
+	if cfg != nil {
+		avatar.MaxFileSize = cfg.AvatarMaxSize
+		avatar.MinWidth, avatar.MinHeight = cfg.AvatarMinDimension, cfg.AvatarMinDimension
+		avatar.MaxWidth, avatar.MaxHeight = cfg.AvatarMaxDimension, cfg.AvatarMaxDimension
+	}
+	activeStorage.RegisterAttachment("users", avatar)
+
+	service := &ProfileService{
+		db:             db,
+		logger:         logger,
+		activeStorage:  activeStorage,
+		emitter:        emitter,
+		emailChangeTTL: config.DefaultEmailChangeTTL,
+		emailChangeURL: "/api/profile/email/confirm?token={token}",
+		deletionMode:   config.DefaultAccountDeletionMode,
+	}
+	if cfg != nil {
+		service.deletionMode = cfg.AccountDeletionMode
+		service.deletionGrace = time.Duration(cfg.AccountDeletionGraceDays) * 24 * time.Hour
+		service.emailChangeTTL = cfg.EmailChangeTTL
+		service.emailChangeURL = cfg.EmailChangeURL
+		if service.emailChangeURL == "" {
+			service.emailChangeURL = cfg.BaseURL + "/api/profile/email/confirm?token={token}"
+		}
+	}
+
+	// Built-in settings; apps register their own keys with RegisterSetting
+	service.RegisterSetting("locale", IsString)
+	service.RegisterSetting("theme", OneOf("light", "dark", "system"))
+	service.RegisterSetting("notifications", nil)
+
+	return service
+}
+
+// TokenVersion returns the user's current token version. It's installed with
+// types.SetTokenVersionFunc so tokens issued before a password change are rejected.
+func (s *ProfileService) TokenVersion(userID uint) (uint, error) {
+	var user User
+	if err := s.db.Select("id", "token_version").First(&user, userID).Error; err != nil {
+		return 0, err
 	}
+	return user.TokenVersion, nil
 }
 
 // Helper method to convert user to response
@@ -86,8 +137,15 @@ func (s *ProfileService) Update(id uint, req *UpdateRequest) (*UserResponse, err
 	if req.Username != "" {
 		user.Username = req.Username
 	}
-	if req.Email != "" {
-		user.Email = req.Email
+
+	// A new email only replaces the current one once it's confirmed
+	var emailToken string
+	if req.Email != "" && req.Email != user.Email {
+		token, err := s.requestEmailChange(&user, req.Email)
+		if err != nil {
+			return nil, err
+		}
+		emailToken = token
 	}
 
 	if err := s.db.Save(&user).Error; err != nil {
@@ -97,6 +155,10 @@ func (s *ProfileService) Update(id uint, req *UpdateRequest) (*UserResponse, err
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	if emailToken != "" {
+		s.sendEmailChange(&user, emailToken)
+	}
+
 	return s.ToResponse(&user), nil
 }
 
@@ -106,7 +168,11 @@ func (s *ProfileService) UpdateAvatar(ctx context.Context, id uint, avatarFile *
 		return nil, err
 	}
 
-	// Just attach the new file - cleanup is handled inside Attach
+	previous, err := s.avatars(id)
+	if err != nil {
+		return nil, err
+	}
+
 	attachment, err := s.activeStorage.Attach(&user, "avatar", avatarFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload avatar: %w", err)
@@ -118,9 +184,30 @@ func (s *ProfileService) UpdateAvatar(ctx context.Context, id uint, avatarFile *
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	// The new avatar is saved; a failure here only leaves a file for
+	// storage:gc to collect
+	for _, old := range previous {
+		if err := s.activeStorage.Delete(old); err != nil {
+			s.logger.Error("Failed to delete previous avatar",
+				zap.Error(err),
+				zap.Uint("user_id", id))
+		}
+	}
+
 	return s.ToResponse(&user), nil
 }
 
+// avatars loads the user's avatar attachments
+func (s *ProfileService) avatars(id uint) ([]*storage.Attachment, error) {
+	var avatars []*storage.Attachment
+	err := s.db.Where("model_type = ? AND model_id = ? AND field = ?", "users", id, "avatar").
+		Find(&avatars).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load avatar: %w", err)
+	}
+	return avatars, nil
+}
+
 func (s *ProfileService) RemoveAvatar(ctx context.Context, id uint) (*UserResponse, error) {
 	tx := s.db.Begin()
 	defer func() {
@@ -135,13 +222,21 @@ func (s *ProfileService) RemoveAvatar(ctx context.Context, id uint) (*UserRespon
 		return nil, err
 	}
 
-	if user.Avatar != nil {
-		if err := s.activeStorage.Delete(user.Avatar); err != nil {
-			tx.Rollback()
-			s.logger.Error("Failed to delete avatar",
-				zap.Error(err),
-				zap.Uint("user_id", id))
-			return nil, fmt.Errorf("failed to delete avatar: %w", err)
+	avatars, err := s.avatars(id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if len(avatars) > 0 {
+		for _, avatar := range avatars {
+			if err := s.activeStorage.Delete(avatar); err != nil {
+				tx.Rollback()
+				s.logger.Error("Failed to delete avatar",
+					zap.Error(err),
+					zap.Uint("user_id", id))
+				return nil, fmt.Errorf("failed to delete avatar: %w", err)
+			}
 		}
 		user.Avatar = nil
 		if err := tx.Save(&user).Error; err != nil {
@@ -166,13 +261,17 @@ func (s *ProfileService) UpdatePassword(id uint, req *UpdatePasswordRequest) err
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
+	if err := helper.Passwords().Verify(user.Password, req.OldPassword); err != nil {
 		s.logger.Info("Invalid old password provided",
 			zap.Uint("user_id", id))
-		return bcrypt.ErrMismatchedHashAndPassword
+		return helper.ErrPasswordMismatch
+	}
+
+	if err := helper.ValidatePassword(req.NewPassword); err != nil {
+		return err
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := helper.Passwords().Hash(req.NewPassword)
 	if err != nil {
 		s.logger.Error("Failed to hash new password",
 			zap.Error(err),
@@ -180,7 +279,9 @@ func (s *ProfileService) UpdatePassword(id uint, req *UpdatePasswordRequest) err
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	user.Password = string(hashedPassword)
+	// Bumping the token version signs out every existing session
+	user.Password = hashedPassword
+	user.TokenVersion++
 	if err := s.db.Save(&user).Error; err != nil {
 		s.logger.Error("Failed to save new password",
 			zap.Error(err),
@@ -188,5 +289,15 @@ func (s *ProfileService) UpdatePassword(id uint, req *UpdatePasswordRequest) err
 		return fmt.Errorf("failed to update user password: %w", err)
 	}
 
+	if s.emitter != nil {
+		s.emitter.Emit("user.password_changed", types.UserData{
+			Id:        user.Id,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Username:  user.Username,
+			Email:     user.Email,
+		})
+	}
+
 	return nil
 }
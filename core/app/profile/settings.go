@@ -0,0 +1,205 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"base/core/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrSettingsConflict is returned when concurrent updates keep racing the same row
+var ErrSettingsConflict = errors.New("settings were modified concurrently")
+
+// SettingValidator checks a single setting value; returning an error rejects the update
+type SettingValidator func(value any) error
+
+// SettingsValidationError lists the keys that failed validation
+type SettingsValidationError struct {
+	Errors map[string]string
+}
+
+func (e *SettingsValidationError) Error() string {
+	return fmt.Sprintf("invalid settings: %v", e.Errors)
+}
+
+// settingsSchema holds the validators registered with RegisterSetting
+type settingsSchema struct {
+	mu         sync.RWMutex
+	validators map[string]SettingValidator
+	strict     bool
+}
+
+// RegisterSetting adds a known settings key; a nil validator accepts any value
+func (s *ProfileService) RegisterSetting(key string, validate SettingValidator) {
+	s.settings.mu.Lock()
+	defer s.settings.mu.Unlock()
+	if s.settings.validators == nil {
+		s.settings.validators = make(map[string]SettingValidator)
+	}
+	s.settings.validators[key] = validate
+}
+
+// StrictSettings rejects keys that weren't registered with RegisterSetting
+func (s *ProfileService) StrictSettings(strict bool) {
+	s.settings.mu.Lock()
+	defer s.settings.mu.Unlock()
+	s.settings.strict = strict
+}
+
+// validate checks every non-null value in patch; nulls delete keys and are always allowed
+func (s *settingsSchema) validate(patch map[string]any) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	errs := make(map[string]string)
+	for key, value := range patch {
+		validate, known := s.validators[key]
+		if !known {
+			if s.strict {
+				errs[key] = "unknown setting"
+			}
+			continue
+		}
+		if validate == nil || value == nil {
+			continue
+		}
+		if err := validate(value); err != nil {
+			errs[key] = err.Error()
+		}
+	}
+	if len(errs) > 0 {
+		return &SettingsValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// OneOf accepts only the listed string values
+func OneOf(values ...string) SettingValidator {
+	return func(value any) error {
+		str, ok := value.(string)
+		if ok {
+			for _, v := range values {
+				if str == v {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("must be one of %v", values)
+	}
+}
+
+// IsBool accepts only booleans
+func IsBool(value any) error {
+	if _, ok := value.(bool); !ok {
+		return errors.New("must be a boolean")
+	}
+	return nil
+}
+
+// IsString accepts only strings
+func IsString(value any) error {
+	if _, ok := value.(string); !ok {
+		return errors.New("must be a string")
+	}
+	return nil
+}
+
+// GetSettings returns the user's settings, or an empty map when none are stored
+func (s *ProfileService) GetSettings(userID uint) (map[string]any, error) {
+	var row UserSettings
+	err := s.db.Where("user_id = ?", userID).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return map[string]any{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	return decodeSettings(row.Settings)
+}
+
+// UpdateSettings merges patch into the stored settings and returns the result.
+// Keys set to null are removed. Each write is conditional on updated_at being
+// unchanged since the read, so a concurrent update is retried against the new
+// value instead of being overwritten.
+func (s *ProfileService) UpdateSettings(userID uint, patch map[string]any) (map[string]any, error) {
+	if err := s.settings.validate(patch); err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		var row UserSettings
+		err := s.db.Where("user_id = ?", userID).First(&row).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			merged := mergeSettings(map[string]any{}, patch)
+			encoded, err := json.Marshal(merged)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode settings: %w", err)
+			}
+			// The unique index on user_id turns a racing insert into a retry
+			if err := s.db.Create(&UserSettings{UserId: userID, Settings: string(encoded)}).Error; err == nil {
+				return merged, nil
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get settings: %w", err)
+		}
+
+		current, err := decodeSettings(row.Settings)
+		if err != nil {
+			return nil, err
+		}
+		merged := mergeSettings(current, patch)
+		encoded, err := json.Marshal(merged)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode settings: %w", err)
+		}
+
+		result := s.db.Model(&UserSettings{}).
+			Where("id = ? AND updated_at = ?", row.Id, row.UpdatedAt).
+			Updates(map[string]any{"settings": string(encoded), "updated_at": time.Now()})
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to update settings: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return merged, nil
+		}
+	}
+
+	s.logger.Warn("Settings update conflicted repeatedly", logger.Uint("user_id", userID))
+	return nil, ErrSettingsConflict
+}
+
+// mergeSettings applies patch to current, recursing into nested objects
+func mergeSettings(current, patch map[string]any) map[string]any {
+	for key, value := range patch {
+		if value == nil {
+			delete(current, key)
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok {
+			if existing, ok := current[key].(map[string]any); ok {
+				current[key] = mergeSettings(existing, nested)
+				continue
+			}
+		}
+		current[key] = value
+	}
+	return current
+}
+
+func decodeSettings(raw string) (map[string]any, error) {
+	settings := map[string]any{}
+	if raw == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		return nil, fmt.Errorf("failed to decode settings: %w", err)
+	}
+	return settings, nil
+}
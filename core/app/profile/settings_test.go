@@ -0,0 +1,123 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newSettingsService(t *testing.T) *ProfileService {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "profile.db") + "?_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&UserSettings{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return &ProfileService{db: db, logger: logger.NewLoggerFromZap(zap.NewNop())}
+}
+
+func TestUpdateSettingsMerges(t *testing.T) {
+	s := newSettingsService(t)
+
+	if _, err := s.UpdateSettings(1, map[string]any{
+		"theme":         "dark",
+		"notifications": map[string]any{"email": true, "push": false},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.UpdateSettings(1, map[string]any{
+		"language":      "fr",
+		"theme":         nil,
+		"notifications": map[string]any{"push": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"language":      "fr",
+		"notifications": map[string]any{"email": true, "push": true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged = %v, want %v", got, want)
+	}
+	if stored, _ := s.GetSettings(1); !reflect.DeepEqual(stored, want) {
+		t.Errorf("stored = %v, want %v", stored, want)
+	}
+	if other, _ := s.GetSettings(2); len(other) != 0 {
+		t.Errorf("another user's settings = %v, want none", other)
+	}
+}
+
+func TestUpdateSettingsValidation(t *testing.T) {
+	s := newSettingsService(t)
+	s.RegisterSetting("theme", OneOf("light", "dark"))
+	s.RegisterSetting("beta", IsBool)
+
+	var invalid *SettingsValidationError
+	_, err := s.UpdateSettings(1, map[string]any{"theme": "blue", "beta": "yes"})
+	if !errors.As(err, &invalid) || len(invalid.Errors) != 2 {
+		t.Fatalf("UpdateSettings = %v, want errors for theme and beta", err)
+	}
+
+	// Unknown keys are only rejected in strict mode
+	if _, err := s.UpdateSettings(1, map[string]any{"anything": 1.0}); err != nil {
+		t.Errorf("non-strict unknown key: %v", err)
+	}
+	s.StrictSettings(true)
+	if _, err := s.UpdateSettings(1, map[string]any{"other": 1.0}); !errors.As(err, &invalid) {
+		t.Errorf("strict unknown key = %v, want a validation error", err)
+	}
+}
+
+func TestUpdateSettingsConcurrently(t *testing.T) {
+	s := newSettingsService(t)
+	if _, err := s.UpdateSettings(1, map[string]any{"seed": true}); err != nil {
+		t.Fatal(err)
+	}
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = s.UpdateSettings(1, map[string]any{fmt.Sprintf("key%d", i): float64(i)})
+		}()
+	}
+	wg.Wait()
+
+	// A write either lands on top of the others or reports the conflict;
+	// it never silently drops another writer's key
+	stored, err := s.GetSettings(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, err := range errs {
+		key := fmt.Sprintf("key%d", i)
+		switch {
+		case err == nil:
+			if stored[key] != float64(i) {
+				t.Errorf("%s was accepted but lost: %v", key, stored)
+			}
+		case !errors.Is(err, ErrSettingsConflict):
+			t.Errorf("writer %d: %v", i, err)
+		}
+	}
+	if stored["seed"] != true {
+		t.Errorf("seed was lost: %v", stored)
+	}
+}
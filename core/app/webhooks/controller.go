@@ -0,0 +1,201 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"base/core/logger"
+	"base/core/router"
+)
+
+type WebhookController struct {
+	Service *WebhookService
+	Logger  logger.Logger
+}
+
+func NewWebhookController(service *WebhookService, logger logger.Logger) *WebhookController {
+	return &WebhookController{
+		Service: service,
+		Logger:  logger,
+	}
+}
+
+// Routes registers the webhook management routes; r must resolve the
+// organization and require ManageResource/ManageAction, as the module does.
+// On an operator group without an organization they manage system webhooks.
+func (c *WebhookController) Routes(r *router.RouterGroup) {
+	r.GET("/webhooks", c.List).Doc(router.Doc{
+		Summary:  "List webhooks",
+		Tags:     []string{"Core/Webhooks"},
+		Response: []WebhookResponse{},
+	})
+	r.POST("/webhooks", c.Create).Doc(router.Doc{
+		Summary:     "Register a webhook",
+		Description: "Registers an endpoint for events; the response carries the signing secret, which is not shown again",
+		Tags:        []string{"Core/Webhooks"},
+		Request:     CreateWebhookRequest{},
+		Response:    WebhookResponse{},
+		Status:      http.StatusCreated,
+	})
+	r.GET("/webhooks/:id", c.Get).Doc(router.Doc{
+		Summary:  "Get a webhook",
+		Tags:     []string{"Core/Webhooks"},
+		Response: WebhookResponse{},
+	})
+	r.PUT("/webhooks/:id", c.Update).Doc(router.Doc{
+		Summary:  "Update a webhook",
+		Tags:     []string{"Core/Webhooks"},
+		Request:  UpdateWebhookRequest{},
+		Response: WebhookResponse{},
+	})
+	r.DELETE("/webhooks/:id", c.Delete).Doc(router.Doc{
+		Summary: "Delete a webhook",
+		Tags:    []string{"Core/Webhooks"},
+		Status:  http.StatusNoContent,
+	})
+	r.GET("/webhooks/:id/deliveries", c.Deliveries).Doc(router.Doc{
+		Summary:     "List webhook deliveries",
+		Description: "Delivery attempts with their response codes, newest first; limit defaults to 50, at most 500",
+		Tags:        []string{"Core/Webhooks"},
+		Response:    []Delivery{},
+	})
+	r.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", c.Redeliver).Doc(router.Doc{
+		Summary:     "Redeliver a webhook event",
+		Description: "Queues the payload of a past delivery again",
+		Tags:        []string{"Core/Webhooks"},
+		Status:      http.StatusAccepted,
+	})
+}
+
+// List handles GET /webhooks
+func (c *WebhookController) List(ctx *router.Context) error {
+	webhooks, err := c.Service.List(ctx.Context(), ctx.OrgID())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	responses := make([]*WebhookResponse, 0, len(webhooks))
+	for i := range webhooks {
+		responses = append(responses, webhooks[i].ToResponse())
+	}
+	return ctx.JSON(http.StatusOK, responses)
+}
+
+// Create handles POST /webhooks
+func (c *WebhookController) Create(ctx *router.Context) error {
+	var req CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	webhook, err := c.Service.Create(ctx.Context(), ctx.OrgID(), &req)
+	if err != nil {
+		return c.error(ctx, err)
+	}
+
+	response := webhook.ToResponse()
+	response.Secret = webhook.Secret
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+// Get handles GET /webhooks/:id
+func (c *WebhookController) Get(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
+	}
+
+	webhook, err := c.Service.Get(ctx.Context(), ctx.OrgID(), uint(id))
+	if err != nil {
+		return c.error(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, webhook.ToResponse())
+}
+
+// Update handles PUT /webhooks/:id
+func (c *WebhookController) Update(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
+	}
+
+	var req UpdateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	webhook, err := c.Service.Update(ctx.Context(), ctx.OrgID(), uint(id), &req)
+	if err != nil {
+		return c.error(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, webhook.ToResponse())
+}
+
+// Delete handles DELETE /webhooks/:id
+func (c *WebhookController) Delete(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
+	}
+
+	if err := c.Service.Delete(ctx.Context(), ctx.OrgID(), uint(id)); err != nil {
+		return c.error(ctx, err)
+	}
+
+	ctx.Status(http.StatusNoContent)
+	return nil
+}
+
+// Deliveries handles GET /webhooks/:id/deliveries
+func (c *WebhookController) Deliveries(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
+	}
+
+	limit := 50
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = min(l, 500)
+		}
+	}
+
+	deliveries, err := c.Service.Deliveries(ctx.Context(), ctx.OrgID(), uint(id), limit)
+	if err != nil {
+		return c.error(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver handles POST /webhooks/:id/deliveries/:delivery_id/redeliver
+func (c *WebhookController) Redeliver(ctx *router.Context) error {
+	id, ok := ctx.MustParamUint("id")
+	if !ok {
+		return nil
+	}
+	deliveryId, ok := ctx.MustParamUint("delivery_id")
+	if !ok {
+		return nil
+	}
+
+	if err := c.Service.Redeliver(ctx.Context(), ctx.OrgID(), uint(id), uint(deliveryId)); err != nil {
+		return c.error(ctx, err)
+	}
+
+	ctx.Status(http.StatusAccepted)
+	return nil
+}
+
+// error maps service errors to responses
+func (c *WebhookController) error(ctx *router.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrWebhookNotFound), errors.Is(err, ErrDeliveryNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrInvalidURL), errors.Is(err, ErrForbiddenTarget), errors.Is(err, ErrNoEvents):
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.Logger.Error("Webhook request failed", logger.String("error", err.Error()))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
@@ -0,0 +1,161 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"base/core/jobs"
+	"base/core/logger"
+)
+
+// DeliverJob is the background job POSTing an event to a webhook. Failed
+// deliveries are retried by the job queue with exponential backoff.
+const DeliverJob = "webhooks.deliver"
+
+// Headers sent with every delivery
+const (
+	SignatureHeader = "X-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// deliverPayload identifies a delivery; Payload is the exact body to sign and
+// send. OrganizationId selects the database holding the webhook.
+type deliverPayload struct {
+	OrganizationId uint64 `json:"organization_id"`
+	WebhookId      uint   `json:"webhook_id"`
+	DeliveryId     string `json:"delivery_id"`
+	Event          string `json:"event"`
+	Payload        string `json:"payload"`
+}
+
+// Client POSTs signed deliveries
+type Client struct {
+	HTTP *http.Client
+}
+
+// NewClient creates a client that gives up on an endpoint after 10 seconds.
+// It refuses to connect to loopback, private and link-local addresses, also
+// when a public name resolves to one or a redirect leads to one, and ignores
+// proxy settings so the check sees the real destination.
+func NewClient() *Client {
+	return newClient(publicIP)
+}
+
+// newClient creates a client that only connects to addresses allowed
+// accepts
+func newClient(allowed func(net.IP) bool) *Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowed(ip) {
+				return ErrForbiddenTarget
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &Client{HTTP: &http.Client{Timeout: 10 * time.Second, Transport: transport}}
+}
+
+// Sign returns the X-Signature value for body: "sha256=" followed by the hex
+// HMAC-SHA256 of the body keyed with the webhook secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is a valid X-Signature for body, for
+// receivers checking deliveries
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// deliver sends one attempt of a delivery and records it. Any response other
+// than 2xx is returned as an error so the job queue retries it.
+func (s *WebhookService) deliver(ctx context.Context, job *jobs.Job) error {
+	var payload deliverPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	db := s.db(ctx, payload.OrganizationId)
+	webhook, err := s.find(db, payload.WebhookId)
+	if errors.Is(err, ErrWebhookNotFound) {
+		return nil // deleted since the event was queued
+	}
+	if err != nil {
+		return err
+	}
+	if !webhook.Active {
+		return nil
+	}
+
+	delivery := Delivery{
+		WebhookId:  webhook.Id,
+		DeliveryId: payload.DeliveryId,
+		Event:      payload.Event,
+		Payload:    payload.Payload,
+		Attempt:    job.Attempts,
+	}
+
+	start := time.Now()
+	statusCode, sendErr := s.Client.send(ctx, webhook, &payload)
+	delivery.Duration = time.Since(start).Milliseconds()
+	delivery.StatusCode = statusCode
+	delivery.Success = sendErr == nil
+	if sendErr != nil {
+		delivery.Error = sendErr.Error()
+	}
+
+	if err := db.Create(&delivery).Error; err != nil {
+		s.Logger.Error("Failed to record webhook delivery",
+			logger.Uint("webhook_id", webhook.Id),
+			logger.String("error", err.Error()))
+	}
+	return sendErr
+}
+
+// send POSTs the payload and returns the response status
+func (c *Client) send(ctx context.Context, webhook *Webhook, payload *deliverPayload) (int, error) {
+	body := []byte(payload.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Base-Webhooks/1.0")
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+	req.Header.Set(EventHeader, payload.Event)
+	req.Header.Set(DeliveryHeader, payload.DeliveryId)
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	// Drain a little so the connection can be reused; the body is not kept
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,212 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"base/core/emitter"
+	"base/core/jobs"
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTestService returns a service whose client may reach the loopback
+// addresses httptest servers listen on
+func newTestService(t *testing.T) *WebhookService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "webhooks.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&Webhook{}, &Delivery{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	s := NewWebhookService(db, emitter.New(), logger.NewLoggerFromZap(zap.NewNop()))
+	s.Client = newClient(func(ip net.IP) bool { return ip.IsLoopback() })
+	return s
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event":"user.registered"}`)
+	signature := Sign("whsec_test", body)
+
+	// sha256=<hex>, stable for the same secret and body
+	if len(signature) != len("sha256=")+64 || signature[:7] != "sha256=" || Sign("whsec_test", body) != signature {
+		t.Fatalf("Sign = %q", signature)
+	}
+	if !Verify("whsec_test", body, signature) {
+		t.Error("Verify rejected a valid signature")
+	}
+	if Verify("other", body, signature) {
+		t.Error("Verify accepted a signature made with another secret")
+	}
+	if Verify("whsec_test", []byte(`{"event":"user.deleted"}`), signature) {
+		t.Error("Verify accepted a signature of another body")
+	}
+	if Verify("whsec_test", body, signature[7:]) {
+		t.Error("Verify accepted a signature without the sha256= prefix")
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://hooks.example.com/in", nil},
+		{"http://203.0.113.7:8080/hook", nil},
+		{"ftp://example.com/hook", ErrInvalidURL},
+		{"/relative", ErrInvalidURL},
+		{"https://", ErrInvalidURL},
+		{"http://localhost:8080/", ErrForbiddenTarget},
+		{"http://api.localhost/", ErrForbiddenTarget},
+		{"http://LOCALHOST./", ErrForbiddenTarget},
+		{"http://127.0.0.1/", ErrForbiddenTarget},
+		{"http://[::1]/", ErrForbiddenTarget},
+		{"http://10.1.2.3/", ErrForbiddenTarget},
+		{"http://172.16.0.1/", ErrForbiddenTarget},
+		{"http://192.168.1.1/", ErrForbiddenTarget},
+		{"http://169.254.169.254/latest/meta-data", ErrForbiddenTarget},
+		{"http://[fe80::1]/", ErrForbiddenTarget},
+		{"http://[fd00::1]/", ErrForbiddenTarget},
+		{"http://100.64.0.1/", ErrForbiddenTarget},
+		{"http://0.0.0.0/", ErrForbiddenTarget},
+		{"http://224.0.0.1/", ErrForbiddenTarget},
+	}
+	for _, tt := range tests {
+		if err := validateURL(tt.url); !errors.Is(err, tt.want) {
+			t.Errorf("validateURL(%q) = %v, want %v", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestPublicIP(t *testing.T) {
+	for ip, want := range map[string]bool{
+		"8.8.8.8":         true,
+		"2001:4860::8888": true,
+		"100.63.255.255":  true,
+		"100.128.0.0":     true,
+		"127.0.0.1":       false,
+		"10.0.0.1":        false,
+		"169.254.1.1":     false,
+		"100.100.0.1":     false,
+		"::":              false,
+		"ff02::1":         false,
+	} {
+		if got := publicIP(net.ParseIP(ip)); got != want {
+			t.Errorf("publicIP(%s) = %v, want %v", ip, got, want)
+		}
+	}
+}
+
+func TestClientRefusesInternalTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// The client connects by address, so these are refused even when a
+	// public name resolves to them
+	client := NewClient()
+	for _, target := range []string{server.URL, "http://10.0.0.1:9/", "http://169.254.169.254/", "http://[::1]:9/"} {
+		resp, err := client.HTTP.Get(target)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, ErrForbiddenTarget) {
+			t.Errorf("GET %s = %v, want ErrForbiddenTarget", target, err)
+		}
+	}
+}
+
+func TestClientRefusesRedirectToInternalTarget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://10.0.0.1:9/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	// The endpoint itself is reachable, where it redirects to isn't
+	client := newClient(func(ip net.IP) bool { return ip.IsLoopback() })
+	resp, err := client.HTTP.Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrForbiddenTarget) {
+		t.Errorf("following the redirect = %v, want ErrForbiddenTarget", err)
+	}
+}
+
+func TestDeliverRetriesFailedResponses(t *testing.T) {
+	var mu sync.Mutex
+	var received []*http.Request
+	var bodies [][]byte
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s := newTestService(t)
+	webhook := Webhook{OrganizationId: 1, URL: server.URL, Events: "*", Secret: "whsec_test", Active: true}
+	if err := s.DB.Create(&webhook).Error; err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := json.Marshal(deliverPayload{WebhookId: webhook.Id, DeliveryId: "d1", Event: "user.registered", Payload: `{"id":"e1"}`})
+	job := &jobs.Job{Name: DeliverJob, Payload: string(payload), Attempts: 1}
+
+	// A 5xx fails the job so the queue retries it
+	if err := s.deliver(context.Background(), job); err == nil {
+		t.Fatal("deliver succeeded on a 500")
+	}
+	mu.Lock()
+	status = http.StatusNoContent
+	mu.Unlock()
+	job.Attempts = 2
+	if err := s.deliver(context.Background(), job); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("%d requests, want 2", len(received))
+	}
+	for i, r := range received {
+		if !Verify("whsec_test", bodies[i], r.Header.Get(SignatureHeader)) || string(bodies[i]) != `{"id":"e1"}` {
+			t.Errorf("request %d: body %s with signature %q", i+1, bodies[i], r.Header.Get(SignatureHeader))
+		}
+		if r.Header.Get(EventHeader) != "user.registered" || r.Header.Get(DeliveryHeader) != "d1" {
+			t.Errorf("request %d headers: %v", i+1, r.Header)
+		}
+	}
+
+	var deliveries []Delivery
+	s.DB.Order("id").Find(&deliveries)
+	if len(deliveries) != 2 {
+		t.Fatalf("%d deliveries recorded, want 2", len(deliveries))
+	}
+	if first := deliveries[0]; first.Success || first.StatusCode != 500 || first.Attempt != 1 || first.Error == "" {
+		t.Errorf("first attempt recorded as %+v", first)
+	}
+	if second := deliveries[1]; !second.Success || second.StatusCode != 204 || second.Attempt != 2 || second.DeliveryId != "d1" {
+		t.Errorf("second attempt recorded as %+v", second)
+	}
+
+	// Deliveries to a deactivated webhook are dropped, not retried
+	s.DB.Model(&webhook).Update("active", false)
+	if err := s.deliver(context.Background(), job); err != nil || len(received) != 2 {
+		t.Errorf("deliver to an inactive webhook = %v after %d requests", err, len(received))
+	}
+}
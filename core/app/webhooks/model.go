@@ -0,0 +1,116 @@
+package webhooks
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook is an organization's endpoint notified of the events it subscribes
+// to. Events is a comma separated list of event names, "*" subscribing to all
+// of them.
+type Webhook struct {
+	Id             uint           `json:"id" gorm:"primaryKey"`
+	OrganizationId uint64         `json:"organization_id" gorm:"column:organization_id;not null;default:0;index"`
+	URL            string         `json:"url" gorm:"column:url;not null;size:2048"`
+	Events         string         `json:"events" gorm:"column:events;not null;size:1024"`
+	Secret         string         `json:"-" gorm:"column:secret;not null;size:255"`
+	Active         bool           `json:"active" gorm:"column:active;not null;default:true"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName returns the table name for the Webhook model
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// EventList returns the subscribed event names
+func (w *Webhook) EventList() []string {
+	var events []string
+	for _, event := range strings.Split(w.Events, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Matches reports whether the webhook subscribes to event
+func (w *Webhook) Matches(event string) bool {
+	events := w.EventList()
+	return slices.Contains(events, "*") || slices.Contains(events, event)
+}
+
+// ToResponse converts the webhook to a response object
+func (w *Webhook) ToResponse() *WebhookResponse {
+	return &WebhookResponse{
+		Id:             w.Id,
+		OrganizationId: w.OrganizationId,
+		URL:            w.URL,
+		Events:         w.EventList(),
+		Active:         w.Active,
+		CreatedAt:      w.CreatedAt,
+		UpdatedAt:      w.UpdatedAt,
+	}
+}
+
+// Delivery records one attempt to deliver an event to a webhook. A failed
+// attempt is retried by the job queue, adding an attempt with the same
+// DeliveryId. The endpoint's response body is neither stored nor returned,
+// so a webhook can't be used to read what an endpoint answers.
+type Delivery struct {
+	Id         uint      `json:"id" gorm:"primaryKey"`
+	WebhookId  uint      `json:"webhook_id" gorm:"column:webhook_id;not null;index"`
+	DeliveryId string    `json:"delivery_id" gorm:"column:delivery_id;not null;size:64;index"`
+	Event      string    `json:"event" gorm:"column:event;not null;size:255"`
+	Payload    string    `json:"-" gorm:"column:payload;type:text;not null"`
+	Attempt    int       `json:"attempt" gorm:"column:attempt;not null"`
+	StatusCode int       `json:"status_code" gorm:"column:status_code"`
+	Error      string    `json:"error,omitempty" gorm:"column:error;type:text"`
+	Success    bool      `json:"success" gorm:"column:success;not null;default:false"`
+	Duration   int64     `json:"duration_ms" gorm:"column:duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName returns the table name for the Delivery model
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookResponse represents a webhook in API responses. The secret is only
+// returned when the webhook is created.
+type WebhookResponse struct {
+	Id             uint      `json:"id"`
+	OrganizationId uint64    `json:"organization_id"`
+	URL            string    `json:"url"`
+	Events         []string  `json:"events"`
+	Active         bool      `json:"active"`
+	Secret         string    `json:"secret,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CreateWebhookRequest represents the request payload for registering a webhook
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1"`
+	// Secret signs deliveries; one is generated when empty
+	Secret string `json:"secret"`
+}
+
+// UpdateWebhookRequest represents the request payload for updating a webhook
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url" binding:"omitempty,url"`
+	Events []string `json:"events"`
+	Secret *string  `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
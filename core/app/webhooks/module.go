@@ -0,0 +1,123 @@
+package webhooks
+
+import (
+	"base/core/app/authorization"
+	"base/core/config"
+	"base/core/emitter"
+	"base/core/jobs"
+	"base/core/logger"
+	"base/core/module"
+	"base/core/router"
+	"base/core/router/middleware"
+	"base/core/seed"
+	"base/core/tenant"
+
+	"gorm.io/gorm"
+)
+
+// ForwardedEvents are the emitter events delivered to subscribed webhooks.
+// Apps can forward more with WebhookService.Forward. Payloads implementing
+// OrganizationEvent go to that organization's webhooks, all others to the
+// system webhooks operators register under /system/webhooks.
+var ForwardedEvents = []string{
+	"user.registered",
+	"organization.member_joined",
+}
+
+// The permission needed to manage an organization's webhooks; owners have it
+// implicitly and the Administrator role is granted it
+const (
+	ManageResource = "webhook"
+	ManageAction   = "manage"
+)
+
+type WebhookModule struct {
+	module.DefaultModule
+	DB         *gorm.DB
+	Controller *WebhookController
+	Service    *WebhookService
+	Logger     logger.Logger
+	AdminToken string
+}
+
+func NewWebhookModule(db *gorm.DB, emitter *emitter.Emitter, logger logger.Logger, cfg *config.Config) module.Module {
+	service := NewWebhookService(db, emitter, logger)
+	controller := NewWebhookController(service, logger)
+
+	return &WebhookModule{
+		DB:         db,
+		Controller: controller,
+		Service:    service,
+		Logger:     logger,
+		AdminToken: cfg.AdminToken,
+	}
+}
+
+// DependsOn initializes the jobs module first, as deliveries run on its
+// queue, and the authorization module that checks the manage permission
+func (m *WebhookModule) DependsOn() []string {
+	return []string{"authorization", "jobs"}
+}
+
+// Init registers the delivery job, the manage permission and the models kept
+// in each organization's database, and forwards the default events
+func (m *WebhookModule) Init() error {
+	jobs.Register(DeliverJob, m.Service.deliver)
+	tenant.RegisterModels(&Webhook{}, &Delivery{})
+	seed.Register(seed.Seeder{Name: "webhooks.permissions", Run: seedPermissions})
+	for _, event := range ForwardedEvents {
+		m.Service.Forward(event)
+	}
+	return nil
+}
+
+func (m *WebhookModule) Routes(router *router.RouterGroup) {
+	// Webhooks belong to the organization in the Base-Orgid header
+	m.Controller.Routes(router.Group("",
+		middleware.BearerAuth(),
+		middleware.OrganizationContext(m.DB),
+		middleware.RequirePermission(ManageResource, ManageAction),
+	).Security("BearerAuth"))
+
+	// System webhooks receive events that belong to no organization, such as
+	// user.registered, so only operators holding ADMIN_TOKEN manage them
+	m.Controller.Routes(router.Group("/system", middleware.AdminAuth(m.AdminToken)).Security("AdminToken"))
+}
+
+// Migrate creates the tables of the system webhooks in the main database;
+// organizations' webhooks are migrated with the tenant models
+func (m *WebhookModule) Migrate() error {
+	return m.DB.AutoMigrate(&Webhook{}, &Delivery{})
+}
+
+func (m *WebhookModule) GetModels() []any {
+	return []any{
+		&Webhook{},
+		&Delivery{},
+	}
+}
+
+// seedPermissions creates the manage webhooks permission and grants it to the
+// Administrator system role
+func seedPermissions(tx *gorm.DB) error {
+	permission := authorization.Permission{
+		Name:         "Manage Webhooks",
+		Description:  "Register webhooks and inspect their deliveries",
+		ResourceType: ManageResource,
+		Action:       ManageAction,
+	}
+	keys := map[string]any{"resource_type": permission.ResourceType, "action": permission.Action}
+	if _, err := seed.CreateIfNotExists(tx, &permission, keys); err != nil {
+		return err
+	}
+
+	var role authorization.Role
+	result := tx.Where("name = ? AND is_system = ?", "Administrator", true).Limit(1).Find(&role)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	rolePermission := authorization.RolePermission{RoleId: role.Id, PermissionId: permission.Id}
+	keys = map[string]any{"role_id": role.Id, "permission_id": permission.Id}
+	_, err := seed.CreateIfNotExists(tx, &rolePermission, keys)
+	return err
+}
@@ -0,0 +1,321 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"base/core/emitter"
+	"base/core/jobs"
+	"base/core/logger"
+	"base/core/tenant"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("delivery not found")
+	ErrInvalidURL       = errors.New("url must be an absolute http or https URL")
+	ErrForbiddenTarget  = errors.New("url must not point at a loopback, private or link-local address")
+	ErrNoEvents         = errors.New("at least one event is required")
+)
+
+// Envelope is the JSON body POSTed to webhooks
+type Envelope struct {
+	Id        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+type WebhookService struct {
+	DB      *gorm.DB
+	Emitter *emitter.Emitter
+	Logger  logger.Logger
+	Client  *Client
+}
+
+func NewWebhookService(db *gorm.DB, emitter *emitter.Emitter, logger logger.Logger) *WebhookService {
+	return &WebhookService{
+		DB:      db,
+		Emitter: emitter,
+		Logger:  logger,
+		Client:  NewClient(),
+	}
+}
+
+// OrganizationEvent is implemented by event payloads that belong to an
+// organization. Forward delivers them to that organization's webhooks only.
+type OrganizationEvent interface {
+	EventOrganizationId() uint64
+}
+
+// SystemOrganization is the organization id of system webhooks, which
+// operators register and which receive events belonging to no organization
+const SystemOrganization uint64 = 0
+
+// Forward delivers every emission of event to the subscribed webhooks of the
+// event's organization, so one organization never receives another's data.
+// Payloads that don't implement OrganizationEvent go to the system webhooks.
+func (s *WebhookService) Forward(event string) {
+	s.Emitter.On(event, func(data any) {
+		orgId := SystemOrganization
+		if scoped, ok := data.(OrganizationEvent); ok {
+			if orgId = scoped.EventOrganizationId(); orgId == SystemOrganization {
+				return
+			}
+		}
+		if err := s.Dispatch(context.Background(), orgId, event, data); err != nil {
+			s.Logger.Error("Failed to dispatch webhook event",
+				logger.String("event", event),
+				logger.String("error", err.Error()))
+		}
+	})
+}
+
+// db returns the database holding the webhooks of an organization: its
+// tenant database, or the main one in shared mode and for system webhooks
+func (s *WebhookService) db(ctx context.Context, orgId uint64) *gorm.DB {
+	if m := tenant.Default(); m != nil && orgId != SystemOrganization {
+		return m.DB(tenant.WithOrg(ctx, orgId))
+	}
+	return s.DB.WithContext(ctx)
+}
+
+// Dispatch queues a delivery of event to each active webhook of the
+// organization subscribed to it
+func (s *WebhookService) Dispatch(ctx context.Context, orgId uint64, event string, data any) error {
+	var webhooks []Webhook
+	err := s.db(ctx, orgId).Where("organization_id = ? AND active = ?", orgId, true).Find(&webhooks).Error
+	if err != nil {
+		return fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.Matches(event) {
+			continue
+		}
+
+		// Every webhook gets the same body, encoded once
+		if payload == nil {
+			body, err := json.Marshal(Envelope{Id: newId(), Event: event, CreatedAt: time.Now(), Data: data})
+			if err != nil {
+				return fmt.Errorf("failed to encode %s payload: %w", event, err)
+			}
+			payload = body
+		}
+
+		if err := s.enqueue(orgId, webhook.Id, newId(), event, string(payload)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Redeliver queues the payload of a past delivery again as a new delivery
+func (s *WebhookService) Redeliver(ctx context.Context, orgId uint64, webhookId, deliveryId uint) error {
+	if _, err := s.Get(ctx, orgId, webhookId); err != nil {
+		return err
+	}
+
+	var delivery Delivery
+	if err := s.db(ctx, orgId).Where("id = ? AND webhook_id = ?", deliveryId, webhookId).First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDeliveryNotFound
+		}
+		return err
+	}
+	return s.enqueue(orgId, webhookId, newId(), delivery.Event, delivery.Payload)
+}
+
+func (s *WebhookService) enqueue(orgId uint64, webhookId uint, deliveryId, event, payload string) error {
+	err := jobs.Enqueue(DeliverJob, deliverPayload{
+		OrganizationId: orgId,
+		WebhookId:      webhookId,
+		DeliveryId:     deliveryId,
+		Event:          event,
+		Payload:        payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to queue webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (s *WebhookService) Create(ctx context.Context, orgId uint64, req *CreateWebhookRequest) (*Webhook, error) {
+	if err := validateURL(req.URL); err != nil {
+		return nil, err
+	}
+	events := normalizeEvents(req.Events)
+	if len(events) == 0 {
+		return nil, ErrNoEvents
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = newSecret()
+	}
+
+	webhook := Webhook{
+		OrganizationId: orgId,
+		URL:            req.URL,
+		Events:         strings.Join(events, ","),
+		Secret:         secret,
+		Active:         true,
+	}
+	if err := s.db(ctx, orgId).Create(&webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return &webhook, nil
+}
+
+func (s *WebhookService) Update(ctx context.Context, orgId uint64, id uint, req *UpdateWebhookRequest) (*Webhook, error) {
+	webhook, err := s.Get(ctx, orgId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := validateURL(*req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		events := normalizeEvents(req.Events)
+		if len(events) == 0 {
+			return nil, ErrNoEvents
+		}
+		webhook.Events = strings.Join(events, ",")
+	}
+	if req.Secret != nil && *req.Secret != "" {
+		webhook.Secret = *req.Secret
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := s.db(ctx, orgId).Save(webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, orgId uint64, id uint) error {
+	result := s.db(ctx, orgId).Where("organization_id = ?", orgId).Delete(&Webhook{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// Get returns one of the organization's webhooks
+func (s *WebhookService) Get(ctx context.Context, orgId uint64, id uint) (*Webhook, error) {
+	return s.find(s.db(ctx, orgId).Where("organization_id = ?", orgId), id)
+}
+
+func (s *WebhookService) find(db *gorm.DB, id uint) (*Webhook, error) {
+	var webhook Webhook
+	if err := db.First(&webhook, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// List returns the organization's webhooks
+func (s *WebhookService) List(ctx context.Context, orgId uint64) ([]Webhook, error) {
+	var webhooks []Webhook
+	if err := s.db(ctx, orgId).Where("organization_id = ?", orgId).Order("id").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// Deliveries returns the most recent delivery attempts for a webhook, newest first
+func (s *WebhookService) Deliveries(ctx context.Context, orgId uint64, webhookId uint, limit int) ([]Delivery, error) {
+	if _, err := s.Get(ctx, orgId, webhookId); err != nil {
+		return nil, err
+	}
+
+	var deliveries []Delivery
+	err := s.db(ctx, orgId).Where("webhook_id = ?", webhookId).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// validateURL accepts absolute http(s) URLs whose host isn't obviously
+// internal. Names resolving to internal addresses are refused when the
+// delivery connects; see NewClient.
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrForbiddenTarget
+	}
+	if ip := net.ParseIP(host); ip != nil && !publicIP(ip) {
+		return ErrForbiddenTarget
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, internal like the
+// private ranges
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// publicIP reports whether webhooks may deliver to ip: not loopback,
+// private, link-local, unspecified or multicast
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// normalizeEvents trims event names and drops empty and duplicate ones
+func normalizeEvents(events []string) []string {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if event == "" || seen[event] {
+			continue
+		}
+		seen[event] = true
+		normalized = append(normalized, event)
+	}
+	return normalized
+}
+
+// newId returns a random id for envelopes and deliveries
+func newId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// newSecret returns a random signing secret
+func newSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,169 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"base/core/config"
+	"base/core/jobs"
+	"base/core/tenant"
+	"base/core/types"
+)
+
+// recorder is a job queue that keeps enqueued deliveries
+type recorder struct {
+	mu         sync.Mutex
+	deliveries []deliverPayload
+}
+
+func (r *recorder) Enqueue(ctx context.Context, name string, payload any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries = append(r.deliveries, payload.(deliverPayload))
+	return nil
+}
+
+func (r *recorder) Start(ctx context.Context) error { return nil }
+func (r *recorder) Stop(ctx context.Context) error  { return nil }
+
+func newRecorder(t *testing.T) *recorder {
+	t.Helper()
+	r := &recorder{}
+	jobs.SetDefault(r)
+	t.Cleanup(func() { jobs.SetDefault(nil) })
+	return r
+}
+
+// memberJoined stands in for an organization's event payload
+type memberJoined struct {
+	OrganizationId uint64 `json:"organization_id"`
+}
+
+func (e memberJoined) EventOrganizationId() uint64 { return e.OrganizationId }
+
+func TestForwardScopesEvents(t *testing.T) {
+	s := newTestService(t)
+	queue := newRecorder(t)
+	hooks := map[string]*Webhook{
+		"system": {OrganizationId: SystemOrganization, URL: "https://ops.example.com", Events: "*"},
+		"org1":   {OrganizationId: 1, URL: "https://one.example.com", Events: "*"},
+		"org2":   {OrganizationId: 2, URL: "https://two.example.com", Events: "organization.member_joined"},
+		"other":  {OrganizationId: 2, URL: "https://two.example.com/other", Events: "order.paid"},
+	}
+	for _, hook := range hooks {
+		hook.Secret = "whsec_test"
+		hook.Active = true
+		if err := s.DB.Create(hook).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, event := range ForwardedEvents {
+		s.Forward(event)
+	}
+
+	s.Emitter.Emit("user.registered", types.UserData{Id: 7, Email: "ada@example.com"})
+	s.Emitter.Emit("organization.member_joined", memberJoined{OrganizationId: 2})
+	s.Emitter.Emit("organization.member_joined", memberJoined{})
+
+	got := map[uint]string{}
+	for _, delivery := range queue.deliveries {
+		got[delivery.WebhookId] = delivery.Event
+
+		var envelope Envelope
+		if err := json.Unmarshal([]byte(delivery.Payload), &envelope); err != nil || envelope.Event != delivery.Event || envelope.Id == "" {
+			t.Errorf("payload %s: %v", delivery.Payload, err)
+		}
+	}
+	want := map[uint]string{
+		hooks["system"].Id: "user.registered",
+		hooks["org2"].Id:   "organization.member_joined",
+	}
+	if len(got) != len(want) || len(queue.deliveries) != len(want) {
+		t.Fatalf("deliveries %v, want %v", got, want)
+	}
+	for id, event := range want {
+		if got[id] != event {
+			t.Errorf("webhook %d got %q, want %q", id, got[id], event)
+		}
+	}
+}
+
+func TestRedeliverIsScopedToOrganization(t *testing.T) {
+	s := newTestService(t)
+	queue := newRecorder(t)
+	webhook, err := s.Create(context.Background(), 1, &CreateWebhookRequest{URL: "https://one.example.com", Events: []string{"*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivery := Delivery{WebhookId: webhook.Id, DeliveryId: "d1", Event: "user.registered", Payload: `{"id":"e1"}`, Attempt: 1}
+	if err := s.DB.Create(&delivery).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Redeliver(context.Background(), 2, webhook.Id, delivery.Id); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("Redeliver from another organization = %v, want ErrWebhookNotFound", err)
+	}
+	if err := s.Redeliver(context.Background(), 1, webhook.Id, delivery.Id); err != nil {
+		t.Fatal(err)
+	}
+	if len(queue.deliveries) != 1 || queue.deliveries[0].Payload != delivery.Payload || queue.deliveries[0].DeliveryId == "d1" {
+		t.Errorf("queued %+v, want the same payload as a new delivery", queue.deliveries)
+	}
+}
+
+func TestWebhooksLiveInTenantDatabases(t *testing.T) {
+	s := newTestService(t)
+	manager, err := tenant.NewManager(s.DB, &config.Config{
+		Tenancy:   tenant.ModeDatabase,
+		DBDriver:  "sqlite",
+		TenantDSN: filepath.Join(t.TempDir(), "org_{org}.db"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenant.RegisterModels(&Webhook{}, &Delivery{})
+	tenant.SetDefault(manager)
+	t.Cleanup(func() {
+		tenant.SetDefault(nil)
+		manager.Close()
+	})
+	ctx := context.Background()
+
+	webhook, err := s.Create(ctx, 1, &CreateWebhookRequest{URL: "https://one.example.com", Events: []string{"*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create(ctx, SystemOrganization, &CreateWebhookRequest{URL: "https://ops.example.com", Events: []string{"*"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var shared []Webhook
+	s.DB.Find(&shared)
+	if len(shared) != 1 || shared[0].URL != "https://ops.example.com" {
+		t.Errorf("main database holds %+v, want only the system webhook", shared)
+	}
+	orgDB, err := manager.For(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored Webhook
+	if err := orgDB.First(&stored, webhook.Id).Error; err != nil || stored.URL != "https://one.example.com" {
+		t.Errorf("organization database holds %+v, %v", stored, err)
+	}
+	if list, err := s.List(ctx, 2); err != nil || len(list) != 0 {
+		t.Errorf("organization 2 lists %+v, %v", list, err)
+	}
+
+	// Deliveries are queued with the organization to find the webhook again
+	queue := newRecorder(t)
+	if err := s.Dispatch(ctx, 1, "order.paid", map[string]int{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if len(queue.deliveries) != 1 || queue.deliveries[0].OrganizationId != 1 || queue.deliveries[0].WebhookId != webhook.Id {
+		t.Errorf("queued %+v", queue.deliveries)
+	}
+}
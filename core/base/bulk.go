@@ -0,0 +1,125 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmptyBatch    = errors.New("batch is empty")
+	ErrBatchTooLarge = errors.New("batch is too large")
+)
+
+var maxBulkItems atomic.Int64
+
+func init() {
+	maxBulkItems.Store(100)
+}
+
+// SetMaxBulkItems sets the largest batch Bulk accepts
+func SetMaxBulkItems(n int) {
+	maxBulkItems.Store(int64(n))
+}
+
+// BulkResult reports the outcome of one item of a batch
+type BulkResult struct {
+	Index   int    `json:"index"`
+	Id      uint   `json:"id,omitempty"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkResponse reports a batch. Committed is false when any item failed, in
+// which case nothing was written and Results say which items to fix; items
+// that would have succeeded are reported without an id.
+type BulkResponse struct {
+	Committed bool         `json:"committed"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Results   []BulkResult `json:"results"`
+}
+
+// Bulk runs fn for items 0 to count-1 in a single transaction. Each item runs
+// under its own savepoint so a failure doesn't stop the rest from being
+// checked; if any item fails the whole transaction is rolled back. fn returns
+// the id of the record it wrote.
+//
+//	resp, err := base.Bulk(s.DB, len(reqs), func(tx *gorm.DB, i int) (uint, error) {
+//		post := Post{Title: reqs[i].Title}
+//		return post.Id, tx.Create(&post).Error
+//	})
+func Bulk(db *gorm.DB, count int, fn func(tx *gorm.DB, index int) (uint, error)) (*BulkResponse, error) {
+	if count == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if limit := int(maxBulkItems.Load()); count > limit {
+		return nil, fmt.Errorf("%w: %d items, at most %d allowed", ErrBatchTooLarge, count, limit)
+	}
+
+	response := &BulkResponse{Results: make([]BulkResult, count)}
+	errFailed := errors.New("batch failed")
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for i := range count {
+			savepoint := "bulk_" + strconv.Itoa(i)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return err
+			}
+
+			id, err := fn(tx, i)
+			response.Results[i] = BulkResult{Index: i, Id: id, Success: err == nil}
+			if err != nil {
+				response.Results[i] = BulkResult{Index: i, Error: err.Error()}
+				response.Failed++
+				if err := tx.RollbackTo(savepoint).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			response.Succeeded++
+		}
+
+		if response.Failed > 0 {
+			// Rolled back, so the ids handed out don't exist
+			for i := range response.Results {
+				response.Results[i].Id = 0
+			}
+			return errFailed
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errFailed) {
+		return nil, err
+	}
+
+	response.Committed = err == nil
+	return response, nil
+}
+
+// Bulk runs fn for each item of a batch in one transaction; see Bulk
+func (bs *Service) Bulk(count int, fn func(tx *gorm.DB, index int) (uint, error)) (*BulkResponse, error) {
+	return Bulk(bs.DB, count, fn)
+}
+
+// RespondBulk answers a bulk request: 200 when the batch was committed, 422
+// with the per-item results when it was rolled back, and 400 for an empty or
+// oversized batch
+func (bc *Controller) RespondBulk(c *router.Context, response *BulkResponse, err error) {
+	switch {
+	case errors.Is(err, ErrEmptyBatch), errors.Is(err, ErrBatchTooLarge):
+		bc.RespondError(c, http.StatusBadRequest, err.Error())
+	case err != nil:
+		bc.RespondInternalError(c, "Bulk operation failed")
+	case !response.Committed:
+		c.JSON(http.StatusUnprocessableEntity, response)
+	default:
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,39 @@
+package base
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestBulkBatchSize(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetMaxBulkItems(2)
+	t.Cleanup(func() { SetMaxBulkItems(100) })
+
+	calls := 0
+	fn := func(tx *gorm.DB, i int) (uint, error) {
+		calls++
+		return uint(i + 1), nil
+	}
+
+	if _, err := Bulk(db, 0, fn); !errors.Is(err, ErrEmptyBatch) {
+		t.Errorf("empty batch = %v, want ErrEmptyBatch", err)
+	}
+	if _, err := Bulk(db, 3, fn); !errors.Is(err, ErrBatchTooLarge) {
+		t.Errorf("3 items = %v, want ErrBatchTooLarge", err)
+	}
+	if calls != 0 {
+		t.Errorf("fn ran %d times for rejected batches", calls)
+	}
+
+	resp, err := Bulk(db, 2, fn)
+	if err != nil || !resp.Committed || resp.Succeeded != 2 || resp.Results[1].Id != 2 {
+		t.Errorf("Bulk = %+v, %v", resp, err)
+	}
+}
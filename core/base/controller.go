@@ -1,12 +1,15 @@
 package base
 
 import (
+	"base/core/export"
 	"base/core/logger"
 	"base/core/router"
 	"base/core/storage"
 	"base/core/types"
 	"net/http"
 	"strconv"
+
+	"gorm.io/gorm"
 )
 
 // Controller provides common functionality for all controllers
@@ -125,6 +128,18 @@ func (bc *Controller) GetPaginationParams(c *router.Context) (page int, limit in
 	return page, limit
 }
 
+// Export streams the records of model matching the request's list filters as
+// CSV or JSON; see export.Respond. Register it before any caching middleware,
+// on a group without a request deadline so large exports aren't cut off:
+//
+//	streams := router.Group("").Set(middleware.TimeoutKey, time.Duration(0))
+//	streams.GET("/posts/export", func(c *router.Context) error {
+//		return h.Export(c, h.DB, &models.Post{}, "posts")
+//	})
+func (bc *Controller) Export(c *router.Context, db *gorm.DB, model any, name string) error {
+	return export.Respond(c, db, model, name)
+}
+
 // GetIDParam extracts ID parameter from URL path
 func (bc *Controller) GetIDParam(c *router.Context) (uint, error) {
 	idStr := c.Param("id")
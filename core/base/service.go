@@ -7,8 +7,10 @@ import (
 	"base/core/types"
 	"fmt"
 	"math"
+	"reflect"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 // Service provides common functionality for all services
@@ -17,8 +19,22 @@ type Service struct {
 	Logger  logger.Logger
 	Emitter *emitter.Emitter
 	Storage *storage.ActiveStorage
+
+	// Resource prefixes the lifecycle events Create, Update, Delete and
+	// HardDelete emit ("post" emits post.created). When empty it's the
+	// model's type name in snake_case.
+	Resource string
 }
 
+// Lifecycle events emitted as <resource>.<event>. The payload is the model
+// pointer passed to the service, e.g. *models.Post; for deletes it holds the
+// record as it was loaded just before it was removed.
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
 // NewService creates a new Service instance
 func NewService(db *gorm.DB, logger logger.Logger, emitter *emitter.Emitter, storage *storage.ActiveStorage) *Service {
 	return &Service{
@@ -56,6 +72,23 @@ func (bs *Service) EmitEvent(eventName string, data any) {
 	}
 }
 
+// EmitResourceEvent emits <resource>.<event> for model, named after
+// bs.Resource or the model's type
+func (bs *Service) EmitResourceEvent(event string, model any) {
+	bs.EmitEvent(bs.resourceName(model)+"."+event, model)
+}
+
+func (bs *Service) resourceName(model any) string {
+	if bs.Resource != "" {
+		return bs.Resource
+	}
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return schema.NamingStrategy{SingularTable: true}.TableName(t.Name())
+}
+
 // CreatePaginatedResponse creates a paginated response
 func (bs *Service) CreatePaginatedResponse(data any, total int64, page int, limit int) *types.PaginatedResponse {
 	totalPages := int(math.Ceil(float64(total) / float64(limit)))
@@ -133,6 +166,24 @@ func (bs *Service) FindByID(model any, id uint, preloads ...string) error {
 	return query.First(model, id).Error
 }
 
+// Create inserts model and emits <resource>.created with it
+func (bs *Service) Create(model any) error {
+	if err := bs.DB.Create(model).Error; err != nil {
+		return err
+	}
+	bs.EmitResourceEvent(EventCreated, model)
+	return nil
+}
+
+// Update saves every field of model and emits <resource>.updated with it
+func (bs *Service) Update(model any) error {
+	if err := bs.DB.Save(model).Error; err != nil {
+		return err
+	}
+	bs.EmitResourceEvent(EventUpdated, model)
+	return nil
+}
+
 // Count counts records matching the given conditions
 func (bs *Service) Count(model any, conditions ...any) (int64, error) {
 	var count int64
@@ -145,20 +196,51 @@ func (bs *Service) Count(model any, conditions ...any) (int64, error) {
 	return count, query.Count(&count).Error
 }
 
-// Delete performs a soft delete on a record
+// Delete performs a soft delete on a record. The record is loaded into model
+// first so <resource>.deleted carries it; a missing record returns
+// gorm.ErrRecordNotFound.
 func (bs *Service) Delete(model any, id uint) error {
+	if err := bs.FindByID(model, id); err != nil {
+		return err
+	}
+	if err := bs.DB.Delete(model).Error; err != nil {
+		return err
+	}
+	bs.EmitResourceEvent(EventDeleted, model)
+	return nil
+}
+
+// Restore brings back a soft-deleted record by clearing its deleted_at;
+// records that aren't deleted return gorm.ErrRecordNotFound
+func (bs *Service) Restore(model any, id uint) error {
 	if err := bs.ValidateID(id); err != nil {
 		return err
 	}
 
-	return bs.DB.Delete(model, id).Error
+	result := bs.DB.Unscoped().Model(model).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
-// HardDelete performs a hard delete on a record
+// HardDelete permanently removes a record, soft-deleted or not, and emits
+// <resource>.deleted with it like Delete
 func (bs *Service) HardDelete(model any, id uint) error {
 	if err := bs.ValidateID(id); err != nil {
 		return err
 	}
-
-	return bs.DB.Unscoped().Delete(model, id).Error
+	if err := bs.DB.Unscoped().First(model, id).Error; err != nil {
+		return err
+	}
+	if err := bs.DB.Unscoped().Delete(model).Error; err != nil {
+		return err
+	}
+	bs.EmitResourceEvent(EventDeleted, model)
+	return nil
 }
@@ -0,0 +1,127 @@
+package base
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"base/core/emitter"
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// BlogPost stands in for a generated module's model
+type BlogPost struct {
+	Id        uint `gorm:"primaryKey"`
+	Title     string
+	DeletedAt gorm.DeletedAt
+}
+
+// recorder collects the payloads emitted for each event
+type recorder struct {
+	mu     sync.Mutex
+	events map[string][]any
+}
+
+func record(e *emitter.Emitter, events ...string) *recorder {
+	r := &recorder{events: make(map[string][]any)}
+	for _, event := range events {
+		e.On(event, func(data any) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.events[event] = append(r.events[event], data)
+		})
+	}
+	return r
+}
+
+func (r *recorder) posts(event string) []*BlogPost {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var posts []*BlogPost
+	for _, data := range r.events[event] {
+		posts = append(posts, data.(*BlogPost))
+	}
+	return posts
+}
+
+func newEventService(t *testing.T) (*Service, *emitter.Emitter) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&BlogPost{}); err != nil {
+		t.Fatal(err)
+	}
+	e := emitter.New()
+	return NewService(db, logger.NewLoggerFromZap(zap.NewNop()), e, nil), e
+}
+
+func TestServiceEmitsLifecycleEvents(t *testing.T) {
+	s, e := newEventService(t)
+	events := record(e, "blog_post.created", "blog_post.updated", "blog_post.deleted")
+
+	post := &BlogPost{Title: "Hello"}
+	if err := s.Create(post); err != nil {
+		t.Fatal(err)
+	}
+	created := events.posts("blog_post.created")
+	if len(created) != 1 || created[0].Id == 0 || created[0].Title != "Hello" {
+		t.Fatalf("created events = %+v, want the stored post", created)
+	}
+
+	post.Title = "Hello again"
+	if err := s.Update(post); err != nil {
+		t.Fatal(err)
+	}
+	if updated := events.posts("blog_post.updated"); len(updated) != 1 || updated[0].Title != "Hello again" {
+		t.Errorf("updated events = %+v", updated)
+	}
+
+	// Deletes carry the record as it was before removal
+	var deleted BlogPost
+	if err := s.Delete(&deleted, post.Id); err != nil {
+		t.Fatal(err)
+	}
+	if got := events.posts("blog_post.deleted"); len(got) != 1 || got[0].Id != post.Id || got[0].Title != "Hello again" {
+		t.Errorf("deleted events = %+v, want the loaded post", got)
+	}
+	var purged BlogPost
+	if err := s.HardDelete(&purged, post.Id); err != nil {
+		t.Fatal(err)
+	}
+	if got := events.posts("blog_post.deleted"); len(got) != 2 || got[1].Title != "Hello again" {
+		t.Errorf("deleted events after HardDelete = %+v", got)
+	}
+
+	// Failed writes emit nothing
+	if err := s.Delete(&BlogPost{}, 999); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Delete(missing) = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if got := events.posts("blog_post.deleted"); len(got) != 2 {
+		t.Errorf("%d deleted events, want none for the missing record", len(got)-2)
+	}
+}
+
+func TestServiceResourceName(t *testing.T) {
+	s, e := newEventService(t)
+	s.Resource = "article"
+	events := record(e, "article.created", "blog_post.created")
+
+	if err := s.Create(&BlogPost{Title: "Named"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(events.posts("article.created")) != 1 || len(events.posts("blog_post.created")) != 0 {
+		t.Errorf("events = %v, want only article.created", events.events)
+	}
+
+	// Without an emitter writes still succeed
+	s.Emitter = nil
+	if err := s.Create(&BlogPost{Title: "Quiet"}); err != nil {
+		t.Fatal(err)
+	}
+}
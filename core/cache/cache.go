@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotInteger is returned by Incr when the key holds a value that isn't an
+// integer
+var ErrNotInteger = errors.New("cache: value is not an integer")
+
+// Store keeps cached values with a TTL. Values are grouped under tags so
+// everything derived from a resource can be dropped at once when it changes.
+type Store interface {
+	// Get returns the value stored under key; ok is false on a miss or expiry
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+
+	// Set stores value under key for ttl and adds the key to each tag
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+
+	// Add stores value under key only if nothing is stored there yet and
+	// reports whether it did
+	Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+
+	// Incr adds delta to the integer stored under key and returns the new
+	// value. A missing key starts at 0 and expires after ttl; incrementing
+	// an existing key keeps its expiry.
+	Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+
+	// Delete removes a single key
+	Delete(ctx context.Context, key string) error
+
+	// Invalidate removes every key stored under any of tags
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+var defaultStore Store = NewMemoryStore(0)
+
+// SetDefault replaces the default store, e.g. with a RedisStore shared by
+// all instances of the application. main sets it from CACHE_DRIVER and hands
+// the same store to modules as deps.Cache.
+func SetDefault(store Store) {
+	defaultStore = store
+}
+
+// Default returns the default store; an in-memory store until SetDefault is
+// called
+func Default() Store {
+	return defaultStore
+}
+
+// Invalidate removes everything cached under tags in the default store.
+// Services call it after writes that bypass the cache middleware:
+//
+//	cache.Invalidate("media")
+func Invalidate(tags ...string) error {
+	return defaultStore.Invalidate(context.Background(), tags...)
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+	tags    []string
+}
+
+// MemoryStore keeps values in process. It's the default store; instances
+// behind a load balancer each keep their own copy, so use a RedisStore when
+// invalidation has to reach all of them.
+type MemoryStore struct {
+	entries    map[string]*list.Element
+	order      *list.List // most recently used at the front
+	tags       map[string]map[string]struct{}
+	maxEntries int
+	mu         sync.Mutex
+	cleanup    *time.Ticker
+}
+
+// NewMemoryStore creates an in-memory store that drops expired entries
+// every minute. Once it holds maxEntries, storing another key evicts the
+// least recently used one; 0 or less keeps every entry until it expires.
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	s := &MemoryStore{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		tags:       make(map[string]map[string]struct{}),
+		maxEntries: maxEntries,
+		cleanup:    time.NewTicker(time.Minute),
+	}
+
+	go s.cleanupRoutine()
+
+	return s
+}
+
+// Get returns the value stored under key
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.live(key)
+	if !ok {
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Set stores value under key for ttl and adds the key to each tag
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store(key, value, time.Now().Add(ttl), tags)
+	return nil
+}
+
+// Add stores value under key only if nothing live is stored there yet
+func (s *MemoryStore) Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.live(key); ok {
+		return false, nil
+	}
+	s.store(key, value, time.Now().Add(ttl), nil)
+	return true, nil
+}
+
+// Incr adds delta to the integer stored under key
+func (s *MemoryStore) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.live(key)
+	if !ok {
+		s.store(key, []byte(strconv.FormatInt(delta, 10)), time.Now().Add(ttl), nil)
+		return delta, nil
+	}
+
+	n, err := strconv.ParseInt(string(entry.value), 10, 64)
+	if err != nil {
+		return 0, ErrNotInteger
+	}
+	n += delta
+	entry.value = []byte(strconv.FormatInt(n, 10))
+	return n, nil
+}
+
+// Delete removes a single key
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	s.remove(key)
+	s.mu.Unlock()
+	return nil
+}
+
+// Invalidate removes every key stored under any of tags
+func (s *MemoryStore) Invalidate(ctx context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			s.remove(key)
+		}
+		delete(s.tags, tag)
+	}
+	return nil
+}
+
+// live returns the unexpired entry under key and marks it recently used;
+// the caller holds the lock
+func (s *MemoryStore) live(key string) (*memoryEntry, bool) {
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		s.remove(key)
+		return nil, false
+	}
+	s.order.MoveToFront(elem)
+	return entry, true
+}
+
+// store replaces key, evicting the least recently used entry when the store
+// is full; the caller holds the lock
+func (s *MemoryStore) store(key string, value []byte, expires time.Time, tags []string) {
+	s.remove(key)
+	if s.maxEntries > 0 && len(s.entries) >= s.maxEntries {
+		if oldest := s.order.Back(); oldest != nil {
+			s.remove(oldest.Value.(*memoryEntry).key)
+		}
+	}
+
+	s.entries[key] = s.order.PushFront(&memoryEntry{
+		key:     key,
+		value:   value,
+		expires: expires,
+		tags:    tags,
+	})
+	for _, tag := range tags {
+		if s.tags[tag] == nil {
+			s.tags[tag] = make(map[string]struct{})
+		}
+		s.tags[tag][key] = struct{}{}
+	}
+}
+
+// remove deletes key and its tag memberships; the caller holds the lock
+func (s *MemoryStore) remove(key string) {
+	elem, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	entry := elem.Value.(*memoryEntry)
+	for _, tag := range entry.tags {
+		delete(s.tags[tag], key)
+		if len(s.tags[tag]) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+	s.order.Remove(elem)
+	delete(s.entries, key)
+}
+
+// cleanupRoutine removes expired entries periodically
+func (s *MemoryStore) cleanupRoutine() {
+	for range s.cleanup.C {
+		s.mu.Lock()
+		now := time.Now()
+		for key, elem := range s.entries {
+			if now.After(elem.Value.(*memoryEntry).expires) {
+				s.remove(key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Stop stops the cleanup routine
+func (s *MemoryStore) Stop() {
+	s.cleanup.Stop()
+}
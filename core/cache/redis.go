@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore keeps values in Redis so every instance of the application
+// shares hits and invalidations. Tags are Redis sets of the keys stored
+// under them.
+type RedisStore struct {
+	Client *redis.Client
+	Prefix string
+}
+
+// NewRedisStore creates a store on client; keys are namespaced with prefix
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{Client: client, Prefix: prefix}
+}
+
+// NewRedisStoreFromURL connects to a redis:// URL and checks the connection
+func NewRedisStoreFromURL(ctx context.Context, url, prefix string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return NewRedisStore(client, prefix), nil
+}
+
+// Get returns the value stored under key
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.Client.Get(ctx, s.Prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores value under key for ttl and adds the key to each tag. A tag set
+// expires with the latest key added to it.
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	_, err := s.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.Prefix+key, value, ttl)
+		for _, tag := range tags {
+			pipe.SAdd(ctx, s.tagKey(tag), s.Prefix+key)
+			pipe.Expire(ctx, s.tagKey(tag), ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// Add stores value under key only if nothing is stored there yet (SET NX)
+func (s *RedisStore) Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.Client.SetNX(ctx, s.Prefix+key, value, ttl).Result()
+}
+
+// Incr adds delta to the integer stored under key (INCRBY). The ttl is only
+// set by the increment that creates the key.
+func (s *RedisStore) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	n, err := s.Client.IncrBy(ctx, s.Prefix+key, delta).Result()
+	if err != nil {
+		if strings.Contains(err.Error(), "not an integer") {
+			return 0, ErrNotInteger
+		}
+		return 0, err
+	}
+	if n == delta && ttl > 0 {
+		if err := s.Client.Expire(ctx, s.Prefix+key, ttl).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// Delete removes a single key
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.Client.Del(ctx, s.Prefix+key).Err()
+}
+
+// Invalidate removes every key stored under any of tags
+func (s *RedisStore) Invalidate(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		keys, err := s.Client.SMembers(ctx, s.tagKey(tag)).Result()
+		if err != nil {
+			return err
+		}
+		if err := s.Client.Del(ctx, append(keys, s.tagKey(tag))...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RedisStore) tagKey(tag string) string {
+	return s.Prefix + "tag:" + tag
+}
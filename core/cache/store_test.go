@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testStore checks the behavior every Store shares
+func testStore(t *testing.T, newStore func(t *testing.T) Store) {
+	ctx := context.Background()
+
+	t.Run("GetSetDelete", func(t *testing.T) {
+		s := newStore(t)
+		if _, ok, err := s.Get(ctx, "missing"); ok || err != nil {
+			t.Errorf("Get(missing) = %t, %v", ok, err)
+		}
+		if err := s.Set(ctx, "greeting", []byte("hello"), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if value, ok, err := s.Get(ctx, "greeting"); !ok || err != nil || string(value) != "hello" {
+			t.Errorf("Get = %q, %t, %v", value, ok, err)
+		}
+		if err := s.Delete(ctx, "greeting"); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, _ := s.Get(ctx, "greeting"); ok {
+			t.Error("Get found a deleted key")
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		s := newStore(t)
+		s.Set(ctx, "short", []byte("x"), 50*time.Millisecond)
+		time.Sleep(100 * time.Millisecond)
+		if _, ok, _ := s.Get(ctx, "short"); ok {
+			t.Error("Get found an expired key")
+		}
+		if added, err := s.Add(ctx, "short", []byte("y"), time.Minute); !added || err != nil {
+			t.Errorf("Add over an expired key = %t, %v", added, err)
+		}
+	})
+
+	t.Run("Add", func(t *testing.T) {
+		s := newStore(t)
+		if added, err := s.Add(ctx, "lock", []byte("first"), time.Minute); !added || err != nil {
+			t.Fatalf("first Add = %t, %v", added, err)
+		}
+		if added, _ := s.Add(ctx, "lock", []byte("second"), time.Minute); added {
+			t.Error("second Add replaced the value")
+		}
+		if value, _, _ := s.Get(ctx, "lock"); string(value) != "first" {
+			t.Errorf("value = %q, want first", value)
+		}
+	})
+
+	t.Run("Incr", func(t *testing.T) {
+		s := newStore(t)
+		for i, want := range []int64{1, 3, 2} {
+			delta := []int64{1, 2, -1}[i]
+			if n, err := s.Incr(ctx, "hits", delta, time.Minute); n != want || err != nil {
+				t.Errorf("Incr(%d) = %d, %v, want %d", delta, n, err, want)
+			}
+		}
+		if value, _, _ := s.Get(ctx, "hits"); string(value) != "2" {
+			t.Errorf("stored %q, want 2", value)
+		}
+
+		s.Set(ctx, "name", []byte("bob"), time.Minute)
+		if _, err := s.Incr(ctx, "name", 1, time.Minute); !errors.Is(err, ErrNotInteger) {
+			t.Errorf("Incr(name) = %v, want ErrNotInteger", err)
+		}
+
+		// The window starts with the first increment
+		s.Incr(ctx, "window", 1, 100*time.Millisecond)
+		time.Sleep(60 * time.Millisecond)
+		s.Incr(ctx, "window", 1, 100*time.Millisecond)
+		time.Sleep(60 * time.Millisecond)
+		if n, _ := s.Incr(ctx, "window", 1, time.Minute); n != 1 {
+			t.Errorf("count after the window = %d, want a fresh 1", n)
+		}
+	})
+
+	t.Run("IncrConcurrent", func(t *testing.T) {
+		s := newStore(t)
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.Incr(ctx, "hits", 1, time.Minute)
+			}()
+		}
+		wg.Wait()
+		if value, _, _ := s.Get(ctx, "hits"); string(value) != "50" {
+			t.Errorf("hits = %s after 50 concurrent increments", value)
+		}
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		s := newStore(t)
+		s.Set(ctx, "post:1", []byte("a"), time.Minute, "posts")
+		s.Set(ctx, "post:list", []byte("b"), time.Minute, "posts", "lists")
+		s.Set(ctx, "user:1", []byte("c"), time.Minute, "users")
+
+		if err := s.Invalidate(ctx, "posts"); err != nil {
+			t.Fatal(err)
+		}
+		for key, want := range map[string]bool{"post:1": false, "post:list": false, "user:1": true} {
+			if _, ok, _ := s.Get(ctx, key); ok != want {
+				t.Errorf("%s cached = %t after invalidating posts, want %t", key, ok, want)
+			}
+		}
+		if err := s.Invalidate(ctx, "unknown"); err != nil {
+			t.Errorf("Invalidate(unknown) = %v", err)
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func(t *testing.T) Store {
+		s := NewMemoryStore(0)
+		t.Cleanup(s.Stop)
+		return s
+	})
+}
+
+// TestRedisStore runs against the server in CACHE_TEST_REDIS_URL, e.g.
+// redis://localhost:6379/15
+func TestRedisStore(t *testing.T) {
+	url := os.Getenv("CACHE_TEST_REDIS_URL")
+	if url == "" {
+		t.Skip("CACHE_TEST_REDIS_URL not set")
+	}
+	testStore(t, func(t *testing.T) Store {
+		prefix := fmt.Sprintf("cache_test:%d:%s:", time.Now().UnixNano(), t.Name())
+		s, err := NewRedisStoreFromURL(context.Background(), url, prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			keys, _ := s.Client.Keys(context.Background(), prefix+"*").Result()
+			if len(keys) > 0 {
+				s.Client.Del(context.Background(), keys...)
+			}
+			s.Client.Close()
+		})
+		return s
+	})
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(2)
+	t.Cleanup(s.Stop)
+
+	s.Set(ctx, "a", []byte("1"), time.Minute, "letters")
+	s.Set(ctx, "b", []byte("2"), time.Minute)
+	s.Get(ctx, "a") // b is now the least recently used
+	s.Set(ctx, "c", []byte("3"), time.Minute)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok, _ := s.Get(ctx, key); ok != want {
+			t.Errorf("%s cached = %t, want %t", key, ok, want)
+		}
+	}
+
+	// Replacing a key doesn't evict another
+	s.Set(ctx, "c", []byte("4"), time.Minute)
+	if _, ok, _ := s.Get(ctx, "a"); !ok {
+		t.Error("overwriting c evicted a")
+	}
+
+	// Evicted keys leave their tags
+	s.Set(ctx, "d", []byte("5"), time.Minute)
+	s.Set(ctx, "e", []byte("6"), time.Minute)
+	s.mu.Lock()
+	tagged := len(s.tags["letters"])
+	s.mu.Unlock()
+	if tagged != 0 {
+		t.Errorf("letters tag still lists %d evicted keys", tagged)
+	}
+}
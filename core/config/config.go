@@ -2,9 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Configuration defaults - centralized for easier maintenance
@@ -12,10 +15,18 @@ const (
 	// Server defaults
 	DefaultServerAddress = "localhost"
 	DefaultServerPort    = ":8001"
+	DefaultServerHost    = ""
 	DefaultAppHost       = "http://localhost"
 	DefaultEnvironment   = "debug"
 	DefaultVersion       = "0.0.1"
 
+	// Response envelope for router.Context.OK/Fail: "standard" or "legacy"
+	DefaultResponseEnvelope = "legacy"
+
+	// Language for requests that ask for none, and the last translation
+	// fallback before the built-in English messages
+	DefaultLocale = "en"
+
 	// Database defaults
 	DefaultDBDriver   = "mysql"
 	DefaultDBHost     = "localhost"
@@ -24,10 +35,40 @@ const (
 	DefaultDBPassword = "RockeT"
 	DefaultDBName     = "mydatabase"
 	DefaultDBPath     = "test.db"
+	DefaultDBSSLMode  = "disable"
+
+	// Queries slower than the threshold are logged as warnings; with
+	// DB_LOG_QUERIES every query is logged at debug level. Logged SQL keeps
+	// its placeholders unless DB_LOG_QUERY_PARAMS is set.
+	DefaultDBSlowQueryThreshold = 200 * time.Millisecond
+	DefaultDBLogQueries         = false
+	DefaultDBLogQueryParams     = false
+
+	// Deadline for each request's context; queries run with it are canceled
+	// when it passes or the client disconnects
+	DefaultDBQueryTimeout = 30 * time.Second
+
+	// Connection pool sizing, and how often the pool is pinged to notice a
+	// lost database
+	DefaultDBMaxOpen         = 25
+	DefaultDBMaxIdle         = 10
+	DefaultDBConnMaxLifetime = 30 * time.Minute
+	DefaultDBHealthInterval  = 10 * time.Second
+
+	// Tenancy: "shared" keeps all organizations in one database, "schema"
+	// gives each a Postgres schema named TENANT_SCHEMA_PREFIX + org id, and
+	// "database" a database of its own at TENANT_DSN with {org} replaced
+	DefaultTenancy            = "shared"
+	DefaultTenantSchemaPrefix = "org_"
+
+	// Run pending seeders at startup; "base seed" runs them on demand
+	DefaultSeedOnBoot = true
 
 	// Security defaults
-	DefaultJWTSecret = "secret"
-	DefaultAPIKey    = "test_api_key"
+	DefaultJWTSecret        = "secret"
+	DefaultAPIKey           = "test_api_key"
+	DefaultPasswordHashAlgo = "bcrypt"
+	DefaultJWTAlgorithm     = "HS256"
 
 	// Email defaults
 	DefaultEmailProvider    = "default"
@@ -41,55 +82,316 @@ const (
 	DefaultStorageRegion     = "eu-central-1"
 	DefaultStorageBucket     = "default"
 	DefaultStorageExtensions = ".jpg,.jpeg,.png,.gif,.pdf,.doc,.docx"
+	DefaultStorageDedup      = false
+
+	// Avatar upload limits; dimensions are pixels per side, 0 disables
+	DefaultAvatarMaxSize      = 5242880 // 5MB
+	DefaultAvatarMinDimension = 0
+	DefaultAvatarMaxDimension = 4096
+
+	// Static file Cache-Control max-age in seconds
+	DefaultStaticMaxAge = 3600
+
+	// Static directories served as URL prefix=directory pairs
+	DefaultStaticMounts = "/static=./static,/storage=./storage,/docs=./docs"
 
 	// Feature toggles defaults
 	DefaultWebSocketEnabled = true
 	DefaultSwaggerEnabled   = true
+	DefaultSwaggerUseCDN    = false
+	DefaultMetricsEnabled   = false
+	DefaultCompressEnabled  = true
+
+	// Startup banner default; NO_BANNER=true hides it
+	DefaultNoBanner = false
+
+	// Responses smaller than this many bytes are sent uncompressed
+	DefaultCompressMinSize = 1024
+
+	// WebSocket heartbeat defaults; the ping interval must be shorter than the pong wait
+	DefaultWSPingInterval = 54 * time.Second
+	DefaultWSPongWait     = 60 * time.Second
+	DefaultWSWriteWait    = 10 * time.Second
+
+	// A user whose last WebSocket connection drops is reported offline after
+	// this long without reconnecting
+	DefaultWSPresenceGrace = 5 * time.Second
+
+	// Idle server-sent event streams get a heartbeat this often
+	DefaultSSEHeartbeat = 15 * time.Second
+
+	// WebSocket limits: largest client message in bytes, what to do when a
+	// client's send buffer is full ("disconnect" or "drop"), and client
+	// messages relayed per second across the hub (0 = unlimited)
+	DefaultWSMaxMessageSize = 65536
+	DefaultWSOverflowPolicy = "disconnect"
+	DefaultWSBroadcastRate  = 0
+
+	// Background jobs: "database" persists jobs and dead letters, "memory"
+	// keeps them in process. Failed jobs retry with backoff up to max attempts.
+	DefaultJobsDriver       = "database"
+	DefaultJobsConcurrency  = 4
+	DefaultJobsMaxAttempts  = 5
+	DefaultJobsPollInterval = time.Second
+
+	// How often the outbox relay looks for committed events
+	DefaultOutboxPollInterval = time.Second
+
+	// Logging: an empty format or output picks the environment default
+	// (JSON to stdout in production, colored console plus file otherwise).
+	// The file rotates at LOG_MAX_SIZE megabytes. Sampling, off by default,
+	// keeps the first LOG_SAMPLING_INITIAL identical entries below warn per
+	// second, then every LOG_SAMPLING_THEREAFTER-th (0 disables it).
+	DefaultLogLevel              = "debug"
+	DefaultLogFormat             = ""
+	DefaultLogOutput             = ""
+	DefaultLogPath               = "logs"
+	DefaultLogMaxSize            = 100
+	DefaultLogMaxAge             = 30
+	DefaultLogMaxBackups         = 10
+	DefaultLogSamplingInitial    = 0
+	DefaultLogSamplingThereafter = 100
+
+	// Cache driver: "memory" keeps entries per instance (evicting the least
+	// recently used past CACHE_MAX_ENTRIES), "redis" shares them (and their
+	// invalidation) through REDIS_URL
+	DefaultCacheDriver     = "memory"
+	DefaultCacheMaxEntries = 10000
+	DefaultRedisURL        = "redis://localhost:6379/0"
+
+	// Responses to requests with an Idempotency-Key are replayed for retries
+	// of the same key within this window
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// Largest batch the bulk create, update and delete endpoints accept
+	DefaultBulkMaxItems = 100
+
+	// Password policy for registration, resets and password changes
+	DefaultPasswordMinLength     = 8
+	DefaultPasswordRejectCommon  = true
+	DefaultPasswordRequireUpper  = false
+	DefaultPasswordRequireLower  = false
+	DefaultPasswordRequireDigit  = false
+	DefaultPasswordRequireSymbol = false
+
+	// Page size of list endpoints when ?limit= is missing, and the largest allowed
+	DefaultPaginationLimit    = 20
+	DefaultPaginationMaxLimit = 100
+
+	// Request body limits: uploads (avatar, media) get the larger one.
+	// Multipart parts past the memory threshold are spooled to temp files.
+	DefaultBodyLimit       = 4194304  // 4MB
+	DefaultUploadBodyLimit = 20971520 // 20MB
+	DefaultMultipartMemory = 8388608  // 8MB
+
+	// How often each instance reloads feature flags changed elsewhere
+	DefaultFlagsRefreshInterval = 30 * time.Second
+
+	// Auth defaults
+	DefaultAuthReleaseDeletedUnique = true
+
+	// Access tokens last this long, or the longer remember-me lifetime when
+	// the login asks for it
+	DefaultAccessTokenTTL = 24 * time.Hour
+	DefaultRememberMeTTL  = 30 * 24 * time.Hour
+
+	// TLS defaults: autocert certificates are cached here, and HTTPS
+	// responses ask browsers to keep using HTTPS for HSTSMaxAge
+	DefaultTLSAutocertCache = "./certs"
+	DefaultHSTSMaxAge       = 180 * 24 * time.Hour
+
+	// Cookie SameSite mode: lax, strict or none
+	DefaultCookieSameSite = "lax"
+
+	// Organization invitations can be accepted for this long
+	DefaultInvitationTTL = 7 * 24 * time.Hour
+
+	// Email change confirmation links can be opened for this long
+	DefaultEmailChangeTTL = 24 * time.Hour
+
+	// Account deletion: "anonymize" scrubs the user's personal data and keeps
+	// the row for references, "delete" removes it. With a grace period the
+	// account is signed out and purged after that many days.
+	DefaultAccountDeletionMode      = "anonymize"
+	DefaultAccountDeletionGraceDays = 0
 )
 
 // Config holds the application configuration.
 // Maintains exact same structure for backward compatibility
 type Config struct {
-	BaseURL              string
-	CDN                  string
-	Env                  string
-	DBDriver             string
-	DBUser               string
-	DBPassword           string
-	DBHost               string
-	DBPort               string
-	DBName               string
-	DBPath               string
-	DBURL                string
-	ApiKey               string
-	JWTSecret            string
-	ServerAddress        string
-	ServerPort           string
-	CORSAllowedOrigins   []string
-	Version              string
-	EmailProvider        string
-	EmailFromAddress     string
-	SMTPHost             string
-	SMTPPort             int
-	SMTPUsername         string
-	SMTPPassword         string
-	SendGridAPIKey       string
-	PostmarkServerToken  string
-	PostmarkAccountToken string
-	StorageProvider      string   `json:"storage_provider"`
-	StoragePath          string   `json:"storage_path"`
-	StorageBaseURL       string   `json:"storage_base_url"`
-	StorageAPIKey        string   `json:"storage_api_key"`
-	StorageAPISecret     string   `json:"storage_api_secret"`
-	StorageAccountID     string   `json:"storage_account_id"`
-	StorageEndpoint      string   `json:"storage_endpoint"`
-	StorageRegion        string   `json:"storage_region"`
-	StorageBucket        string   `json:"storage_bucket"`
-	StoragePublicURL     string   `json:"storage_public_url"`
-	StorageMaxSize       int64    `json:"storage_max_size"`
-	StorageAllowedExt    []string `json:"storage_allowed_ext"`
-	WebSocketEnabled     bool     `json:"websocket_enabled"`
-	SwaggerEnabled       bool     `json:"swagger_enabled"`
+	BaseURL               string
+	CDN                   string
+	Env                   string
+	DBDriver              string
+	DBUser                string
+	DBPassword            string
+	DBHost                string
+	DBPort                string
+	DBSSLMode             string
+	DBName                string
+	DBPath                string
+	DBURL                 string
+	DBSlowQueryThreshold  time.Duration `json:"db_slow_query_threshold"`
+	DBLogQueries          bool          `json:"db_log_queries"`
+	DBLogQueryParams      bool          `json:"db_log_query_params"`
+	DBQueryTimeout        time.Duration `json:"db_query_timeout"`
+	DBMaxOpen             int           `json:"db_max_open"`
+	DBMaxIdle             int           `json:"db_max_idle"`
+	DBConnMaxLifetime     time.Duration `json:"db_conn_max_lifetime"`
+	DBHealthInterval      time.Duration `json:"db_health_interval"`
+	SeedOnBoot            bool          `json:"seed_on_boot"`
+	Tenancy               string        `json:"tenancy"`
+	TenantSchemaPrefix    string        `json:"tenant_schema_prefix"`
+	TenantDSN             string        `json:"tenant_dsn"`
+	ApiKey                string
+	JWTSecret             string
+	PasswordHashAlgo      string
+	JWTAlgorithm          string
+	JWTKeyID              string
+	JWTPrivateKey         string
+	JWTPreviousKeys       []string
+	MFAEncryptionKey      string `json:"-"`
+	ServerAddress         string
+	ServerPort            string
+	ServerHost            string
+	CORSAllowedOrigins    []string
+	Version               string
+	EmailProvider         string
+	EmailFromAddress      string
+	SMTPHost              string
+	SMTPPort              int
+	SMTPUsername          string
+	SMTPPassword          string
+	SendGridAPIKey        string
+	PostmarkServerToken   string
+	PostmarkAccountToken  string
+	StorageProvider       string        `json:"storage_provider"`
+	StoragePath           string        `json:"storage_path"`
+	StorageBaseURL        string        `json:"storage_base_url"`
+	StorageAPIKey         string        `json:"storage_api_key"`
+	StorageAPISecret      string        `json:"storage_api_secret"`
+	StorageAccountID      string        `json:"storage_account_id"`
+	StorageEndpoint       string        `json:"storage_endpoint"`
+	StorageRegion         string        `json:"storage_region"`
+	StorageBucket         string        `json:"storage_bucket"`
+	StoragePublicURL      string        `json:"storage_public_url"`
+	StorageMaxSize        int64         `json:"storage_max_size"`
+	StorageAllowedExt     []string      `json:"storage_allowed_ext"`
+	StorageDedup          bool          `json:"storage_dedup"`
+	StaticMaxAge          int           `json:"static_max_age"`
+	AvatarMaxSize         int64         `json:"avatar_max_size"`
+	AvatarMinDimension    int           `json:"avatar_min_dimension"`
+	AvatarMaxDimension    int           `json:"avatar_max_dimension"`
+	WebSocketEnabled      bool          `json:"websocket_enabled"`
+	WSPingInterval        time.Duration `json:"ws_ping_interval"`
+	WSPongWait            time.Duration `json:"ws_pong_wait"`
+	WSWriteWait           time.Duration `json:"ws_write_wait"`
+	WSMaxMessageSize      int64         `json:"ws_max_message_size"`
+	WSOverflowPolicy      string        `json:"ws_overflow_policy"`
+	WSBroadcastRate       int           `json:"ws_broadcast_rate"`
+	WSPresenceGrace       time.Duration `json:"ws_presence_grace"`
+	SSEHeartbeat          time.Duration `json:"sse_heartbeat"`
+	SSEEvents             []string      `json:"sse_events"`
+	LogLevel              string        `json:"log_level"`
+	LogFormat             string        `json:"log_format"`
+	LogOutputs            []string      `json:"log_outputs"`
+	LogRedactKeys         []string      `json:"log_redact_keys"`
+	LogPath               string        `json:"log_path"`
+	LogMaxSize            int           `json:"log_max_size"`
+	LogMaxAge             int           `json:"log_max_age"`
+	LogMaxBackups         int           `json:"log_max_backups"`
+	LogSamplingInitial    int           `json:"log_sampling_initial"`
+	LogSamplingThereafter int           `json:"log_sampling_thereafter"`
+	JobsDriver            string        `json:"jobs_driver"`
+	JobsConcurrency       int           `json:"jobs_concurrency"`
+	JobsMaxAttempts       int           `json:"jobs_max_attempts"`
+	JobsPollInterval      time.Duration `json:"jobs_poll_interval"`
+	OutboxPollInterval    time.Duration `json:"outbox_poll_interval"`
+	MetricsEnabled        bool          `json:"metrics_enabled"`
+	CompressEnabled       bool          `json:"compress_enabled"`
+	CompressMinSize       int           `json:"compress_min_size"`
+	SwaggerEnabled        bool          `json:"swagger_enabled"`
+	SwaggerUseCDN         bool          `json:"swagger_use_cdn"`
+	ResponseEnvelope      string        `json:"response_envelope"`
+	DefaultLocale         string        `json:"default_locale"`
+	SupportedLocales      []string      `json:"supported_locales"`
+	CacheDriver           string        `json:"cache_driver"`
+	CacheMaxEntries       int           `json:"cache_max_entries"`
+	RedisURL              string        `json:"redis_url"`
+	IdempotencyTTL        time.Duration `json:"idempotency_ttl"`
+	BulkMaxItems          int           `json:"bulk_max_items"`
+	PaginationLimit       int           `json:"pagination_limit"`
+	PaginationMaxLimit    int           `json:"pagination_max_limit"`
+	BodyLimit             int64         `json:"body_limit"`
+	UploadBodyLimit       int64         `json:"upload_body_limit"`
+	MultipartMemory       int64         `json:"multipart_memory"`
+	FlagsRefreshInterval  time.Duration `json:"flags_refresh_interval"`
+
+	// InvitationURL is the link in invitation emails, {token} being replaced
+	// by the invitation token; empty links to the API's GET /invitations/{token}
+	InvitationURL string        `json:"invitation_url"`
+	InvitationTTL time.Duration `json:"invitation_ttl"`
+
+	// EmailChangeURL is the link sent to a new email address, {token} being
+	// replaced by the confirmation token; empty links to the API's
+	// GET /profile/email/confirm?token={token}
+	EmailChangeURL string        `json:"email_change_url"`
+	EmailChangeTTL time.Duration `json:"email_change_ttl"`
+
+	AccountDeletionMode      string `json:"account_deletion_mode"`
+	AccountDeletionGraceDays int    `json:"account_deletion_grace_days"`
+
+	// AuthReleaseDeletedUnique lets soft-deleted users free their email/username for re-registration
+	AuthReleaseDeletedUnique bool `json:"auth_release_deleted_unique"`
+
+	AccessTokenTTL time.Duration `json:"access_token_ttl"`
+	RememberMeTTL  time.Duration `json:"remember_me_ttl"`
+
+	// ReadyFile, when set, is written with the bound address and pid once
+	// the server is listening, so scripts can wait on it
+	ReadyFile string `json:"ready_file"`
+	NoBanner  bool   `json:"no_banner"`
+
+	// StrictModuleInit aborts startup when a core module, or an app module
+	// that isn't optional, fails to initialize
+	StrictModuleInit bool `json:"strict_module_init"`
+
+	// AdminToken guards operator endpoints such as GET /system/modules,
+	// feature flag management and the scheduler cron routes, which are
+	// unavailable while it is empty
+	AdminToken string `json:"-"`
+
+	// TLS is served with TLSCert and TLSKey, or with certificates Let's
+	// Encrypt issues for TLSAutocertDomains. TLSRedirectAddress, when set,
+	// answers plain HTTP there with a redirect to HTTPS.
+	TLSCert            string        `json:"tls_cert"`
+	TLSKey             string        `json:"tls_key"`
+	TLSAutocertDomains []string      `json:"tls_autocert_domains"`
+	TLSAutocertCache   string        `json:"tls_autocert_cache"`
+	TLSRedirectAddress string        `json:"tls_redirect_address"`
+	HSTSMaxAge         time.Duration `json:"hsts_max_age"`
+
+	// StaticMounts are the directories served as static files
+	StaticMounts []StaticMount `json:"static_mounts"`
+
+	// Cookie attributes; CookieSecure defaults to true in production
+	CookieSecure   bool   `json:"cookie_secure"`
+	CookieSameSite string `json:"cookie_same_site"`
+	CookieDomain   string `json:"cookie_domain"`
+
+	// Password policy; see helper.PasswordPolicy
+	PasswordMinLength     int  `json:"password_min_length"`
+	PasswordRequireUpper  bool `json:"password_require_upper"`
+	PasswordRequireLower  bool `json:"password_require_lower"`
+	PasswordRequireDigit  bool `json:"password_require_digit"`
+	PasswordRequireSymbol bool `json:"password_require_symbol"`
+	PasswordRejectCommon  bool `json:"password_reject_common"`
+}
+
+// StaticMount serves the files in Dir under the URL Prefix
+type StaticMount struct {
+	Prefix string `json:"prefix"`
+	Dir    string `json:"dir"`
 }
 
 // NewConfig returns a new Config instance with default values.
@@ -108,21 +410,59 @@ func NewConfig() *Config {
 		Env:           getEnvWithLog("ENV", DefaultEnvironment),
 		ServerAddress: serverAddr,
 		ServerPort:    serverPort,
+		ServerHost:    getEnvWithLog("SERVER_HOST", DefaultServerHost),
 		Version:       getEnvWithLog("APP_VERSION", DefaultVersion),
 
+		ResponseEnvelope: getEnvWithLog("RESPONSE_ENVELOPE", DefaultResponseEnvelope),
+		DefaultLocale:    getEnvWithLog("DEFAULT_LOCALE", DefaultLocale),
+		WSOverflowPolicy: getEnvWithLog("WS_OVERFLOW_POLICY", DefaultWSOverflowPolicy),
+		InvitationURL:    getEnvWithLog("INVITATION_URL", ""),
+		EmailChangeURL:   getEnvWithLog("EMAIL_CHANGE_URL", ""),
+		JobsDriver:       getEnvWithLog("JOBS_DRIVER", DefaultJobsDriver),
+		CacheDriver:      getEnvWithLog("CACHE_DRIVER", getEnvWithLog("CACHE_STORE", DefaultCacheDriver)),
+		RedisURL:         getEnvWithLog("REDIS_URL", DefaultRedisURL),
+		ReadyFile:        getEnvWithLog("READY_FILE", ""),
+		AdminToken:       getEnvWithLog("ADMIN_TOKEN", ""),
+
+		TLSCert:            getEnvWithLog("TLS_CERT", ""),
+		TLSKey:             getEnvWithLog("TLS_KEY", ""),
+		TLSAutocertCache:   getEnvWithLog("TLS_AUTOCERT_CACHE", DefaultTLSAutocertCache),
+		TLSRedirectAddress: normalizePort(getEnvWithLog("TLS_REDIRECT_ADDRESS", "")),
+
+		CookieSameSite: getEnvWithLog("COOKIE_SAMESITE", DefaultCookieSameSite),
+		CookieDomain:   getEnvWithLog("COOKIE_DOMAIN", ""),
+
+		AccountDeletionMode: getEnvWithLog("ACCOUNT_DELETION_MODE", DefaultAccountDeletionMode),
+
+		// Logging settings
+		LogLevel:  getEnvWithLog("LOG_LEVEL", DefaultLogLevel),
+		LogFormat: getEnvWithLog("LOG_FORMAT", DefaultLogFormat),
+		LogPath:   getEnvWithLog("LOG_PATH", DefaultLogPath),
+
 		// Database settings
 		DBDriver:   getEnvWithLog("DB_DRIVER", DefaultDBDriver),
 		DBUser:     getEnvWithLog("DB_USER", DefaultDBUser),
 		DBPassword: getEnvWithLog("DB_PASSWORD", DefaultDBPassword),
 		DBHost:     getEnvWithLog("DB_HOST", DefaultDBHost),
-		DBPort:     getEnvWithLog("DB_PORT", DefaultDBPort),
+		DBPort:     getEnvWithLog("DB_PORT", defaultDBPort(getEnvWithLog("DB_DRIVER", DefaultDBDriver))),
 		DBName:     getEnvWithLog("DB_NAME", DefaultDBName),
 		DBPath:     getEnvWithLog("DB_PATH", DefaultDBPath),
 		DBURL:      getEnvWithLog("DB_URL", ""),
+		DBSSLMode:  getEnvWithLog("DB_SSLMODE", DefaultDBSSLMode),
+
+		// Tenancy settings
+		Tenancy:            getEnvWithLog("TENANCY", DefaultTenancy),
+		TenantSchemaPrefix: getEnvWithLog("TENANT_SCHEMA_PREFIX", DefaultTenantSchemaPrefix),
+		TenantDSN:          getEnvWithLog("TENANT_DSN", ""),
 
 		// Security settings
-		ApiKey:    getEnvWithLog("API_KEY", DefaultAPIKey),
-		JWTSecret: getEnvWithLog("JWT_SECRET", DefaultJWTSecret),
+		ApiKey:           getEnvWithLog("API_KEY", DefaultAPIKey),
+		JWTSecret:        getEnvWithLog("JWT_SECRET", DefaultJWTSecret),
+		PasswordHashAlgo: getEnvWithLog("PASSWORD_HASH_ALGO", DefaultPasswordHashAlgo),
+		JWTAlgorithm:     getEnvWithLog("JWT_ALGORITHM", DefaultJWTAlgorithm),
+		JWTKeyID:         getEnvWithLog("JWT_KEY_ID", ""),
+		JWTPrivateKey:    getEnvWithLog("JWT_PRIVATE_KEY", ""),
+		MFAEncryptionKey: getEnvWithLog("MFA_ENCRYPTION_KEY", ""),
 
 		// Email settings
 		EmailProvider:        getEnvWithLog("EMAIL_PROVIDER", DefaultEmailProvider),
@@ -150,8 +490,16 @@ func NewConfig() *Config {
 	// Parse complex values with proper error handling
 	parseCORSOrigins(config)
 	parseStorageExtensions(config)
+	parseJWTPreviousKeys(config)
+	parseLogOutputs(config)
+	parseLogRedactKeys(config)
+	parseSupportedLocales(config)
+	parseAutocertDomains(config)
+	parseStaticMounts(config)
+	parseSSEEvents(config)
 	parseIntegerValues(config)
 	parseBooleanValues(config)
+	parseDurationValues(config)
 
 	return config
 }
@@ -169,6 +517,47 @@ func parseCORSOrigins(config *Config) {
 	}
 }
 
+// parseLogOutputs parses the comma separated log sinks (stdout, file)
+func parseLogOutputs(config *Config) {
+	outputsStr := getEnvWithLog("LOG_OUTPUT", DefaultLogOutput)
+	if outputsStr != "" {
+		outputs := strings.Split(outputsStr, ",")
+		// Clean up whitespace
+		for i, output := range outputs {
+			outputs[i] = strings.TrimSpace(output)
+		}
+		config.LogOutputs = outputs
+	}
+}
+
+// parseLogRedactKeys parses the comma separated field, header and query
+// parameter names masked in logs on top of the built-in ones
+func parseLogRedactKeys(config *Config) {
+	keysStr := getEnvWithLog("LOG_REDACT_KEYS", "")
+	if keysStr != "" {
+		keys := strings.Split(keysStr, ",")
+		// Clean up whitespace
+		for i, key := range keys {
+			keys[i] = strings.TrimSpace(key)
+		}
+		config.LogRedactKeys = keys
+	}
+}
+
+// parseSupportedLocales parses the comma separated languages requests may
+// choose; empty allows any
+func parseSupportedLocales(config *Config) {
+	localesStr := getEnvWithLog("SUPPORTED_LOCALES", "")
+	if localesStr != "" {
+		locales := strings.Split(localesStr, ",")
+		// Clean up whitespace
+		for i, locale := range locales {
+			locales[i] = strings.TrimSpace(locale)
+		}
+		config.SupportedLocales = locales
+	}
+}
+
 // parseStorageExtensions parses allowed storage extensions
 func parseStorageExtensions(config *Config) {
 	extensionsStr := getEnvWithLog("STORAGE_ALLOWED_EXT", DefaultStorageExtensions)
@@ -182,22 +571,222 @@ func parseStorageExtensions(config *Config) {
 	}
 }
 
+// parseAutocertDomains parses the comma separated domains autocert issues
+// certificates for
+func parseAutocertDomains(config *Config) {
+	domainsStr := getEnvWithLog("TLS_AUTOCERT_DOMAINS", "")
+	if domainsStr != "" {
+		domains := strings.Split(domainsStr, ",")
+		// Clean up whitespace
+		for i, domain := range domains {
+			domains[i] = strings.TrimSpace(domain)
+		}
+		config.TLSAutocertDomains = domains
+	}
+}
+
+// parseStaticMounts parses the comma separated prefix=directory pairs served
+// as static files; an empty STATIC_MOUNTS serves none
+func parseStaticMounts(config *Config) {
+	mountsStr := getEnvWithLog("STATIC_MOUNTS", DefaultStaticMounts)
+	for _, mount := range strings.Split(mountsStr, ",") {
+		mount = strings.TrimSpace(mount)
+		if mount == "" {
+			continue
+		}
+		prefix, dir, ok := strings.Cut(mount, "=")
+		prefix, dir = strings.TrimSpace(prefix), strings.TrimSpace(dir)
+		if !ok || !strings.HasPrefix(prefix, "/") || dir == "" {
+			logConfigError("Invalid STATIC_MOUNTS entry: %s. Expected /prefix=directory", mount)
+			continue
+		}
+		config.StaticMounts = append(config.StaticMounts, StaticMount{Prefix: prefix, Dir: dir})
+	}
+}
+
+// parseSSEEvents parses the comma separated emitter events streamed to
+// subscribers of the topic with the same name
+func parseSSEEvents(config *Config) {
+	eventsStr := getEnvWithLog("SSE_EVENTS", "")
+	if eventsStr != "" {
+		events := strings.Split(eventsStr, ",")
+		// Clean up whitespace
+		for i, event := range events {
+			events[i] = strings.TrimSpace(event)
+		}
+		config.SSEEvents = events
+	}
+}
+
+// parseJWTPreviousKeys parses retired JWT keys still accepted for verification
+func parseJWTPreviousKeys(config *Config) {
+	keysStr := getEnvWithLog("JWT_PREVIOUS_KEYS", "")
+	if keysStr != "" {
+		keys := strings.Split(keysStr, ",")
+		// Clean up whitespace
+		for i, key := range keys {
+			keys[i] = strings.TrimSpace(key)
+		}
+		config.JWTPreviousKeys = keys
+	}
+}
+
 // parseIntegerValues parses all integer configuration values
 func parseIntegerValues(config *Config) {
 	// SMTP Port
 	config.SMTPPort = parseIntWithDefault("SMTP_PORT", DefaultSMTPPort)
 
+	// Days a deleted account waits before it is purged
+	config.AccountDeletionGraceDays = parseIntWithDefault("ACCOUNT_DELETION_GRACE_DAYS", DefaultAccountDeletionGraceDays)
+
+	// List page sizes
+	config.PaginationLimit = parseIntWithDefault("PAGINATION_DEFAULT_LIMIT", DefaultPaginationLimit)
+	config.PaginationMaxLimit = parseIntWithDefault("PAGINATION_MAX_LIMIT", DefaultPaginationMaxLimit)
+
+	// In-memory cache size
+	config.CacheMaxEntries = parseIntWithDefault("CACHE_MAX_ENTRIES", DefaultCacheMaxEntries)
+
+	// Database connection pool
+	config.DBMaxOpen = parseIntWithDefault("DB_MAX_OPEN", DefaultDBMaxOpen)
+	config.DBMaxIdle = parseIntWithDefault("DB_MAX_IDLE", DefaultDBMaxIdle)
+
 	// Storage Max Size
 	config.StorageMaxSize = parseInt64WithDefault("STORAGE_MAX_SIZE", DefaultStorageMaxSize)
+
+	// Avatar upload limits
+	config.AvatarMaxSize = parseInt64WithDefault("AVATAR_MAX_SIZE", DefaultAvatarMaxSize)
+	config.AvatarMinDimension = parseIntWithDefault("AVATAR_MIN_DIMENSION", DefaultAvatarMinDimension)
+	config.AvatarMaxDimension = parseIntWithDefault("AVATAR_MAX_DIMENSION", DefaultAvatarMaxDimension)
+
+	// WebSocket limits
+	config.WSMaxMessageSize = parseInt64WithDefault("WS_MAX_MESSAGE_SIZE", DefaultWSMaxMessageSize)
+	config.WSBroadcastRate = parseIntWithDefault("WS_BROADCAST_RATE", DefaultWSBroadcastRate)
+
+	// Log file rotation and sampling
+	config.LogMaxSize = parseIntWithDefault("LOG_MAX_SIZE", DefaultLogMaxSize)
+	config.LogMaxAge = parseIntWithDefault("LOG_MAX_AGE", DefaultLogMaxAge)
+	config.LogMaxBackups = parseIntWithDefault("LOG_MAX_BACKUPS", DefaultLogMaxBackups)
+	config.LogSamplingInitial = parseIntWithDefault("LOG_SAMPLING_INITIAL", DefaultLogSamplingInitial)
+	config.LogSamplingThereafter = parseIntWithDefault("LOG_SAMPLING_THEREAFTER", DefaultLogSamplingThereafter)
+
+	// Background job workers
+	config.JobsConcurrency = parseIntWithDefault("JOBS_CONCURRENCY", DefaultJobsConcurrency)
+	config.JobsMaxAttempts = parseIntWithDefault("JOBS_MAX_ATTEMPTS", DefaultJobsMaxAttempts)
+
+	// Static file cache lifetime
+	config.StaticMaxAge = parseIntWithDefault("STATIC_MAX_AGE", DefaultStaticMaxAge)
+
+	// Response compression threshold
+	config.CompressMinSize = parseIntWithDefault("COMPRESS_MIN_SIZE", DefaultCompressMinSize)
+
+	// Bulk endpoint batch size
+	config.BulkMaxItems = parseIntWithDefault("BULK_MAX_ITEMS", DefaultBulkMaxItems)
+
+	// Shortest accepted password
+	config.PasswordMinLength = parseIntWithDefault("PASSWORD_MIN_LENGTH", DefaultPasswordMinLength)
+
+	// Request body limits
+	config.BodyLimit = parseInt64WithDefault("BODY_LIMIT", DefaultBodyLimit)
+	config.UploadBodyLimit = parseInt64WithDefault("UPLOAD_BODY_LIMIT", DefaultUploadBodyLimit)
+	config.MultipartMemory = parseInt64WithDefault("MULTIPART_MEMORY", DefaultMultipartMemory)
 }
 
 // parseBooleanValues parses all boolean configuration values
 func parseBooleanValues(config *Config) {
+	// Log every database query
+	config.DBLogQueries = parseBoolWithDefault("DB_LOG_QUERIES", DefaultDBLogQueries)
+
+	// Write bound values into logged SQL; they can hold passwords and personal data
+	config.DBLogQueryParams = parseBoolWithDefault("DB_LOG_QUERY_PARAMS", DefaultDBLogQueryParams)
+
+	// Seed data at startup
+	config.SeedOnBoot = parseBoolWithDefault("SEED_ON_BOOT", DefaultSeedOnBoot)
+
 	// WebSocket enabled
 	config.WebSocketEnabled = parseBoolWithDefault("WS_ENABLED", DefaultWebSocketEnabled)
 
+	// Prometheus metrics on /metrics
+	config.MetricsEnabled = parseBoolWithDefault("METRICS_ENABLED", DefaultMetricsEnabled)
+
+	// gzip/brotli response compression
+	config.CompressEnabled = parseBoolWithDefault("COMPRESS_ENABLED", DefaultCompressEnabled)
+
+	// Store identical uploads once
+	config.StorageDedup = parseBoolWithDefault("STORAGE_DEDUP", DefaultStorageDedup)
+
 	// Swagger enabled
 	config.SwaggerEnabled = parseBoolWithDefault("SWAGGER_ENABLED", DefaultSwaggerEnabled)
+
+	// Swagger UI assets from unpkg instead of the embedded copy
+	config.SwaggerUseCDN = parseBoolWithDefault("SWAGGER_USE_CDN", DefaultSwaggerUseCDN)
+
+	// Secure cookies; on by default in production, where TLS is usually
+	// terminated by a proxy and requests arrive over plain HTTP
+	config.CookieSecure = parseBoolWithDefault("COOKIE_SECURE", config.Env == "production")
+
+	// Abort startup on module failures; on by default in production, where
+	// serving traffic with a module missing is worse than not starting
+	config.StrictModuleInit = parseBoolWithDefault("STRICT_MODULE_INIT", config.Env == "production")
+
+	// Startup banner, off for containers that only want structured logs
+	config.NoBanner = parseBoolWithDefault("NO_BANNER", DefaultNoBanner)
+
+	// Soft-deleted users release their unique values
+	config.AuthReleaseDeletedUnique = parseBoolWithDefault("AUTH_RELEASE_DELETED_UNIQUE", DefaultAuthReleaseDeletedUnique)
+
+	// Character classes a password must contain, and the common password list
+	config.PasswordRequireUpper = parseBoolWithDefault("PASSWORD_REQUIRE_UPPER", DefaultPasswordRequireUpper)
+	config.PasswordRequireLower = parseBoolWithDefault("PASSWORD_REQUIRE_LOWER", DefaultPasswordRequireLower)
+	config.PasswordRequireDigit = parseBoolWithDefault("PASSWORD_REQUIRE_DIGIT", DefaultPasswordRequireDigit)
+	config.PasswordRequireSymbol = parseBoolWithDefault("PASSWORD_REQUIRE_SYMBOL", DefaultPasswordRequireSymbol)
+	config.PasswordRejectCommon = parseBoolWithDefault("PASSWORD_REJECT_COMMON", DefaultPasswordRejectCommon)
+}
+
+// parseDurationValues parses all duration configuration values
+func parseDurationValues(config *Config) {
+	// Slow database query warnings
+	config.DBSlowQueryThreshold = parseDurationWithDefault("DB_SLOW_QUERY_THRESHOLD", DefaultDBSlowQueryThreshold)
+
+	// Request deadline for database work
+	config.DBQueryTimeout = parseDurationAllowZero("DB_QUERY_TIMEOUT", DefaultDBQueryTimeout)
+
+	// Database connection pool and health checks
+	config.DBConnMaxLifetime = parseDurationWithDefault("DB_CONN_MAX_LIFETIME", DefaultDBConnMaxLifetime)
+	config.DBHealthInterval = parseDurationWithDefault("DB_HEALTH_INTERVAL", DefaultDBHealthInterval)
+
+	// WebSocket heartbeat
+	config.WSPingInterval = parseDurationWithDefault("WS_PING_INTERVAL", DefaultWSPingInterval)
+	config.WSPongWait = parseDurationWithDefault("WS_PONG_WAIT", DefaultWSPongWait)
+	config.WSWriteWait = parseDurationWithDefault("WS_WRITE_WAIT", DefaultWSWriteWait)
+
+	// How long a dropped user may take to reconnect before going offline
+	config.WSPresenceGrace = parseDurationWithDefault("WS_PRESENCE_GRACE", DefaultWSPresenceGrace)
+
+	// Comment lines keeping idle event streams open through proxies
+	config.SSEHeartbeat = parseDurationWithDefault("SSE_HEARTBEAT", DefaultSSEHeartbeat)
+
+	// How often the database job queue looks for due jobs
+	config.JobsPollInterval = parseDurationWithDefault("JOBS_POLL_INTERVAL", DefaultJobsPollInterval)
+	config.OutboxPollInterval = parseDurationWithDefault("OUTBOX_POLL_INTERVAL", DefaultOutboxPollInterval)
+
+	// How long Idempotency-Key responses are kept
+	config.IdempotencyTTL = parseDurationWithDefault("IDEMPOTENCY_TTL", DefaultIdempotencyTTL)
+
+	// How long organization invitations stay valid
+	config.InvitationTTL = parseDurationWithDefault("INVITATION_TTL", DefaultInvitationTTL)
+
+	// How long email change confirmation links stay valid
+	config.EmailChangeTTL = parseDurationWithDefault("EMAIL_CHANGE_TTL", DefaultEmailChangeTTL)
+
+	// Access token lifetimes
+	config.AccessTokenTTL = parseDurationWithDefault("ACCESS_TOKEN_TTL", DefaultAccessTokenTTL)
+	config.RememberMeTTL = parseDurationWithDefault("REMEMBER_ME_TTL", DefaultRememberMeTTL)
+
+	// Strict-Transport-Security max-age on HTTPS responses; 0 sends none
+	config.HSTSMaxAge = parseDurationWithDefault("HSTS_MAX_AGE", DefaultHSTSMaxAge)
+
+	// How often feature flags are reloaded from the database
+	config.FlagsRefreshInterval = parseDurationWithDefault("FLAGS_REFRESH_INTERVAL", DefaultFlagsRefreshInterval)
 }
 
 // Helper functions for type parsing with error handling
@@ -224,7 +813,30 @@ func parseInt64WithDefault(key string, defaultValue int64) int64 {
 	return value
 }
 
+// parseDurationWithDefault parses a duration environment variable (e.g. "30s") with default fallback
+func parseDurationWithDefault(key string, defaultValue time.Duration) time.Duration {
+	valueStr := getEnvWithLog(key, defaultValue.String())
+	value, err := time.ParseDuration(valueStr)
+	if err != nil || value <= 0 {
+		logConfigError("Invalid %s value: %s. Using default: %s", key, valueStr, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
 // parseBoolWithDefault parses a boolean environment variable with default fallback
+// parseDurationAllowZero is parseDurationWithDefault for settings where 0
+// turns the feature off
+func parseDurationAllowZero(key string, defaultValue time.Duration) time.Duration {
+	valueStr := getEnvWithLog(key, defaultValue.String())
+	value, err := time.ParseDuration(valueStr)
+	if err != nil || value < 0 {
+		logConfigError("Invalid %s value: %s. Using default: %s", key, valueStr, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
 func parseBoolWithDefault(key string, defaultValue bool) bool {
 	valueStr := getEnvWithLog(key, fmt.Sprintf("%t", defaultValue))
 	value, err := strconv.ParseBool(valueStr)
@@ -243,6 +855,33 @@ func normalizePort(port string) string {
 	return port
 }
 
+// ListenAddress returns the network and address the server binds to:
+// SERVER_HOST joined with SERVER_PORT over tcp, all interfaces when the host
+// is empty, or a Unix socket when SERVER_HOST is "unix:/path/to.sock"
+func (c *Config) ListenAddress() (network, address string, err error) {
+	if path, ok := strings.CutPrefix(c.ServerHost, "unix:"); ok {
+		if path == "" {
+			return "", "", fmt.Errorf("SERVER_HOST %q has no socket path", c.ServerHost)
+		}
+		return "unix", path, nil
+	}
+
+	port := strings.TrimPrefix(c.ServerPort, ":")
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return "", "", fmt.Errorf("SERVER_PORT %q is not a port number", c.ServerPort)
+	}
+	host := strings.Trim(c.ServerHost, "[]")
+	if strings.Contains(host, ":") && net.ParseIP(host) == nil {
+		return "", "", fmt.Errorf("SERVER_HOST %q must be a host name or IP address without a port", c.ServerHost)
+	}
+	return "tcp", net.JoinHostPort(host, port), nil
+}
+
+// TLSEnabled reports whether the server serves HTTPS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCert != "" || len(c.TLSAutocertDomains) > 0
+}
+
 // buildBaseURL constructs the base URL with port if needed
 func buildBaseURL(baseURL, port string) string {
 	if !strings.Contains(baseURL, ":") || strings.HasSuffix(baseURL, "localhost") {
@@ -290,6 +929,9 @@ func (c *Config) Validate() []error {
 	var errors []error
 
 	// Validate database configuration
+	if !slices.Contains(DBDrivers, c.DBDriver) {
+		errors = append(errors, fmt.Errorf("DB_DRIVER %q is not supported; use one of %s", c.DBDriver, strings.Join(DBDrivers, ", ")))
+	}
 	if c.DBDriver != "sqlite" && c.DBURL == "" {
 		if c.DBHost == "" {
 			errors = append(errors, fmt.Errorf("DB_HOST is required for database driver: %s", c.DBDriver))
@@ -303,6 +945,19 @@ func (c *Config) Validate() []error {
 		errors = append(errors, fmt.Errorf("DB_PATH is required for SQLite driver"))
 	}
 
+	// Validate the listen address
+	if _, _, err := c.ListenAddress(); err != nil {
+		errors = append(errors, err)
+	}
+
+	// Validate TLS configuration
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		errors = append(errors, fmt.Errorf("TLS_CERT and TLS_KEY must be set together"))
+	}
+	if c.TLSCert != "" && len(c.TLSAutocertDomains) > 0 {
+		errors = append(errors, fmt.Errorf("TLS_CERT and TLS_AUTOCERT_DOMAINS can't both be set"))
+	}
+
 	// Validate storage configuration
 	if c.StorageProvider == "s3" || c.StorageProvider == "r2" {
 		if c.StorageAPIKey == "" {
@@ -344,7 +999,11 @@ func (c *Config) IsDevelopment() bool {
 	return c.Env == "debug" || c.Env == "development"
 }
 
-// GetDatabaseDSN builds a database connection string based on the driver
+// DBDrivers lists the supported DB_DRIVER values
+var DBDrivers = []string{"postgres", "mysql", "sqlite"}
+
+// GetDatabaseDSN returns DB_URL, or builds a connection string for the driver
+// from the individual DB_* settings. It is empty for an unsupported driver.
 func (c *Config) GetDatabaseDSN() string {
 	if c.DBURL != "" {
 		return c.DBURL
@@ -354,12 +1013,30 @@ func (c *Config) GetDatabaseDSN() string {
 	case "sqlite":
 		return c.DBPath
 	case "mysql":
-		return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-			c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
+		return fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			c.DBUser, c.DBPassword, net.JoinHostPort(c.DBHost, c.DBPort), c.DBName)
 	case "postgres":
-		return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=disable",
-			c.DBHost, c.DBPort, c.DBUser, c.DBName, c.DBPassword)
+		return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
+			pgValue(c.DBHost), pgValue(c.DBPort), pgValue(c.DBUser), pgValue(c.DBName),
+			pgValue(c.DBPassword), pgValue(c.DBSSLMode))
 	default:
 		return ""
 	}
 }
+
+// defaultDBPort is the driver's usual port, used when DB_PORT isn't set
+func defaultDBPort(driver string) string {
+	if driver == "postgres" {
+		return "5432"
+	}
+	return DefaultDBPort
+}
+
+// pgValue quotes a value for a postgres key=value connection string
+func pgValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// unsetEnv removes keys from the environment for the rest of the test
+func unsetEnv(t *testing.T, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+}
+
+func TestCookieSecureFollowsEnvironment(t *testing.T) {
+	tests := []struct {
+		env, secure string
+		want        bool
+	}{
+		{"production", "", true},
+		{"debug", "", false},
+		{"production", "false", false},
+		{"debug", "true", true},
+	}
+	for _, tt := range tests {
+		t.Setenv("ENV", tt.env)
+		if tt.secure == "" {
+			unsetEnv(t, "COOKIE_SECURE")
+		} else {
+			t.Setenv("COOKIE_SECURE", tt.secure)
+		}
+		if got := NewConfig().CookieSecure; got != tt.want {
+			t.Errorf("ENV=%s COOKIE_SECURE=%q: CookieSecure = %t, want %t", tt.env, tt.secure, got, tt.want)
+		}
+	}
+}
+
+func TestGetDatabaseDSN(t *testing.T) {
+	unsetEnv(t, "DB_URL", "DB_PORT", "DB_SSLMODE")
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_USER", "app")
+	t.Setenv("DB_NAME", "shop")
+	t.Setenv("DB_PATH", "data/app.db")
+
+	tests := []struct {
+		driver, password, url, want string
+	}{
+		{"sqlite", "", "", "data/app.db"},
+		{"mysql", "s3cret", "", "app:s3cret@tcp(db.internal:3306)/shop?charset=utf8mb4&parseTime=True&loc=Local"},
+		{"postgres", "s3cret", "", "host=db.internal port=5432 user=app dbname=shop password=s3cret sslmode=disable"},
+		{"postgres", `it's a \pass`, "", `host=db.internal port=5432 user=app dbname=shop password='it\'s a \\pass' sslmode=disable`},
+		{"postgres", "", "", "host=db.internal port=5432 user=app dbname=shop password='' sslmode=disable"},
+		{"mysql", "", "app@tcp(other:3307)/shop", "app@tcp(other:3307)/shop"},
+		{"oracle", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Setenv("DB_DRIVER", tt.driver)
+		t.Setenv("DB_PASSWORD", tt.password)
+		t.Setenv("DB_URL", tt.url)
+		if got := NewConfig().GetDatabaseDSN(); got != tt.want {
+			t.Errorf("%s DSN = %q, want %q", tt.driver, got, tt.want)
+		}
+	}
+
+	// An explicit DB_PORT wins over the driver's default
+	t.Setenv("DB_DRIVER", "postgres")
+	t.Setenv("DB_PORT", "6543")
+	t.Setenv("DB_SSLMODE", "require")
+	t.Setenv("DB_PASSWORD", "x")
+	if got, want := NewConfig().GetDatabaseDSN(), "host=db.internal port=6543 user=app dbname=shop password=x sslmode=require"; got != want {
+		t.Errorf("DSN = %q, want %q", got, want)
+	}
+}
+
+func TestValidateRejectsUnknownDriver(t *testing.T) {
+	for _, driver := range []string{"oracle", "sqlite3", "Postgres"} {
+		config := NewConfig()
+		config.DBDriver = driver
+		found := false
+		for _, err := range config.Validate() {
+			found = found || strings.Contains(err.Error(), "DB_DRIVER")
+		}
+		if !found {
+			t.Errorf("Validate accepted DB_DRIVER=%s", driver)
+		}
+	}
+	for _, driver := range DBDrivers {
+		config := NewConfig()
+		config.DBDriver = driver
+		for _, err := range config.Validate() {
+			if strings.Contains(err.Error(), "DB_DRIVER") {
+				t.Errorf("DB_DRIVER=%s: %v", driver, err)
+			}
+		}
+	}
+}
+
+func TestStrictModuleInitFollowsEnvironment(t *testing.T) {
+	tests := []struct {
+		env, strict string
+		want        bool
+	}{
+		{"production", "", true},
+		{"debug", "", false},
+		{"production", "false", false},
+		{"development", "true", true},
+	}
+	for _, tt := range tests {
+		t.Setenv("ENV", tt.env)
+		if tt.strict == "" {
+			unsetEnv(t, "STRICT_MODULE_INIT")
+		} else {
+			t.Setenv("STRICT_MODULE_INIT", tt.strict)
+		}
+		if got := NewConfig().StrictModuleInit; got != tt.want {
+			t.Errorf("ENV=%s STRICT_MODULE_INIT=%q: StrictModuleInit = %t, want %t", tt.env, tt.strict, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/joho/godotenv"
+)
+
+var current atomic.Pointer[Config]
+
+// Current returns the active configuration: the one given to SetCurrent,
+// as updated by Reload. Code that should follow a reload reads values
+// through it on each use instead of keeping them from startup.
+func Current() *Config {
+	return current.Load()
+}
+
+// SetCurrent makes c the active configuration
+func SetCurrent(c *Config) {
+	current.Store(c)
+}
+
+// Reloadable lists the fields Reload applies to the running process. Other
+// fields are read once at startup, so changing them needs a restart.
+var Reloadable = map[string]bool{
+	"LogLevel":           true,
+	"CORSAllowedOrigins": true,
+	"ResponseEnvelope":   true,
+	"BulkMaxItems":       true,
+}
+
+var (
+	envMu      sync.Mutex
+	envFiles   []string
+	processEnv map[string]bool // set outside the .env files; these always win
+	fileEnv    map[string]bool // set from the .env files when last read
+)
+
+// LoadEnv loads the .env files (".env" by default) into the environment
+// without overriding variables that are already set. Missing files are
+// skipped. Reload reads the same files again.
+func LoadEnv(files ...string) error {
+	envMu.Lock()
+	defer envMu.Unlock()
+
+	if len(files) == 0 {
+		files = []string{".env"}
+	}
+	envFiles = files
+	processEnv = make(map[string]bool)
+	for _, entry := range os.Environ() {
+		key, _, _ := strings.Cut(entry, "=")
+		processEnv[key] = true
+	}
+	return readEnvFiles()
+}
+
+// readEnvFiles sets the variables of envFiles, earlier files first, and
+// unsets the ones that were removed from them since the last read
+func readEnvFiles() error {
+	values := make(map[string]string)
+	for _, file := range envFiles {
+		fileValues, err := godotenv.Read(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		for key, value := range fileValues {
+			if _, ok := values[key]; !ok {
+				values[key] = value
+			}
+		}
+	}
+
+	for key := range fileEnv {
+		if _, ok := values[key]; !ok {
+			os.Unsetenv(key)
+		}
+	}
+	fileEnv = make(map[string]bool, len(values))
+	for key, value := range values {
+		if processEnv[key] {
+			continue
+		}
+		os.Setenv(key, value)
+		fileEnv[key] = true
+	}
+	return nil
+}
+
+// Reload re-reads the .env files given to LoadEnv and builds a new
+// configuration. If its reloadable values are valid it becomes Current and
+// is returned, along with the names of changed fields that need a restart;
+// those keep their running values in the new configuration. On error the
+// current configuration stays active.
+func Reload() (*Config, []string, error) {
+	envMu.Lock()
+	err := readEnvFiles()
+	envMu.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next := NewConfig()
+	if err := next.validateReloadable(); err != nil {
+		return nil, nil, err
+	}
+
+	var restart []string
+	if previous := Current(); previous != nil {
+		restart = keepStartupValues(next, previous)
+	}
+
+	SetCurrent(next)
+	return next, restart, nil
+}
+
+// validateReloadable checks the values Reload applies at runtime
+func (c *Config) validateReloadable() error {
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error", "dpanic", "panic", "fatal":
+	default:
+		return fmt.Errorf("invalid LOG_LEVEL: %s", c.LogLevel)
+	}
+	switch c.ResponseEnvelope {
+	case "standard", "legacy":
+	default:
+		return fmt.Errorf("invalid RESPONSE_ENVELOPE: %s", c.ResponseEnvelope)
+	}
+	if c.BulkMaxItems < 1 {
+		return fmt.Errorf("invalid BULK_MAX_ITEMS: %d", c.BulkMaxItems)
+	}
+	return nil
+}
+
+// keepStartupValues copies every field that isn't Reloadable from previous
+// into next and returns the names of those that had changed
+func keepStartupValues(next, previous *Config) []string {
+	var changed []string
+	nv := reflect.ValueOf(next).Elem()
+	pv := reflect.ValueOf(previous).Elem()
+	for i := 0; i < nv.NumField(); i++ {
+		name := nv.Type().Field(i).Name
+		if Reloadable[name] || reflect.DeepEqual(nv.Field(i).Interface(), pv.Field(i).Interface()) {
+			continue
+		}
+		changed = append(changed, name)
+		nv.Field(i).Set(pv.Field(i))
+	}
+	return changed
+}
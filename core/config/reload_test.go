@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// withEnvFile points LoadEnv at a temporary .env file holding contents and
+// returns a function that rewrites it
+func withEnvFile(t *testing.T, contents string, unset ...string) func(string) {
+	t.Helper()
+	for _, key := range unset {
+		if value, ok := os.LookupEnv(key); ok {
+			os.Unsetenv(key)
+			t.Cleanup(func() { os.Setenv(key, value) })
+		}
+	}
+	path := filepath.Join(t.TempDir(), ".env")
+	write := func(contents string) {
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(contents)
+	if err := LoadEnv(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, key := range unset {
+			os.Unsetenv(key)
+		}
+		SetCurrent(nil)
+	})
+	SetCurrent(NewConfig())
+	return write
+}
+
+func TestReload(t *testing.T) {
+	write := withEnvFile(t, "LOG_LEVEL=info\nSERVER_PORT=8100\nCORS_ALLOWED_ORIGINS=https://a.example\n",
+		"LOG_LEVEL", "SERVER_PORT", "CORS_ALLOWED_ORIGINS")
+	started := Current()
+
+	write("LOG_LEVEL=warn\nSERVER_PORT=9100\nCORS_ALLOWED_ORIGINS=https://b.example\n")
+	next, restart, err := Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Current() != next || next == started {
+		t.Error("Reload didn't swap the current config")
+	}
+	if next.LogLevel != "warn" || !slices.Equal(next.CORSAllowedOrigins, []string{"https://b.example"}) {
+		t.Errorf("reloadable values not applied: %q %v", next.LogLevel, next.CORSAllowedOrigins)
+	}
+
+	// The port can't change without a restart, so it keeps its running value
+	if next.ServerPort != started.ServerPort || !slices.Contains(restart, "ServerPort") {
+		t.Errorf("ServerPort = %q, restart = %v; want the startup port reported", next.ServerPort, restart)
+	}
+	if slices.Contains(restart, "LogLevel") {
+		t.Errorf("restart lists a reloadable field: %v", restart)
+	}
+}
+
+func TestReloadKeepsCurrentOnInvalidValues(t *testing.T) {
+	write := withEnvFile(t, "LOG_LEVEL=info\n", "LOG_LEVEL")
+	started := Current()
+
+	write("LOG_LEVEL=chatty\n")
+	if _, _, err := Reload(); err == nil {
+		t.Fatal("Reload accepted LOG_LEVEL=chatty")
+	}
+	if Current() != started || Current().LogLevel != "info" {
+		t.Errorf("current config changed by a failed reload: %q", Current().LogLevel)
+	}
+}
+
+func TestReloadProcessEnvironmentWins(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "error")
+	write := withEnvFile(t, "LOG_LEVEL=info\n")
+
+	write("LOG_LEVEL=debug\n")
+	next, _, err := Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.LogLevel != "error" {
+		t.Errorf("LogLevel = %q, want the process environment's error", next.LogLevel)
+	}
+}
@@ -3,6 +3,8 @@ package database
 import (
 	"base/core/config"
 	"fmt"
+	"slices"
+	"strings"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
@@ -19,28 +21,42 @@ type Database struct {
 // InitDB initializes the database connection based on the provided configuration.
 func InitDB(cfg *config.Config) (*Database, error) {
 	var err error
-	switch cfg.DBDriver {
-	case "sqlite":
-		DB, err = gorm.Open(sqlite.Open(cfg.DBPath), &gorm.Config{})
-	case "mysql":
-		if cfg.DBURL == "" {
-			cfg.DBURL = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-				cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName)
-		}
-		DB, err = gorm.Open(mysql.Open(cfg.DBURL), &gorm.Config{})
-	case "postgres":
-		if cfg.DBURL == "" {
-			cfg.DBURL = fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=disable",
-				cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBName, cfg.DBPassword)
-		}
-		DB, err = gorm.Open(postgres.Open(cfg.DBURL), &gorm.Config{})
-	default:
-		return nil, fmt.Errorf("unsupported database driver: %s", cfg.DBDriver)
+	gormConfig := &gorm.Config{
+		Logger: NewQueryLogger(cfg.DBSlowQueryThreshold, cfg.DBLogQueries, cfg.DBLogQueryParams),
 	}
 
+	if !slices.Contains(config.DBDrivers, cfg.DBDriver) {
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q: use one of %s",
+			cfg.DBDriver, strings.Join(config.DBDrivers, ", "))
+	}
+	dsn := cfg.GetDatabaseDSN()
+
+	DB, err = Open(cfg.DBDriver, dsn, gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to the database: %v", err)
 	}
 
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to access the connection pool: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpen)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdle)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+
 	return &Database{DB: DB}, nil
 }
+
+// Open connects to dsn with the driver named like DB_DRIVER: sqlite, mysql
+// or postgres. For sqlite dsn is the database file.
+func Open(driver, dsn string, gormConfig *gorm.Config) (*gorm.DB, error) {
+	switch driver {
+	case "sqlite":
+		return gorm.Open(sqlite.Open(dsn), gormConfig)
+	case "mysql":
+		return gorm.Open(mysql.Open(dsn), gormConfig)
+	case "postgres":
+		return gorm.Open(postgres.Open(dsn), gormConfig)
+	}
+	return nil, fmt.Errorf("unsupported database driver: %s", driver)
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"base/core/config"
+	"base/core/logger"
+)
+
+// connect opens the database DB_DRIVER selects, with the other DB_*
+// settings taken from the environment, and checks it answers a query
+func connect(t *testing.T, driver string) *Database {
+	t.Helper()
+	t.Setenv("DB_DRIVER", driver)
+	useTempLogger(t)
+	saved := DB
+	t.Cleanup(func() { DB = saved })
+
+	db, err := InitDB(config.NewConfig())
+	if err != nil {
+		t.Fatalf("InitDB(%s): %v", driver, err)
+	}
+	if got := db.Dialector.Name(); got != driver {
+		t.Errorf("connected with the %s dialect, want %s", got, driver)
+	}
+	var one int
+	if err := db.Raw("SELECT 1").Scan(&one).Error; err != nil || one != 1 {
+		t.Fatalf("SELECT 1 = %d, %v", one, err)
+	}
+	sqlDB, _ := db.DB.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// useTempLogger points the default logger, which queries run without a
+// request logger write to, at a log file under the test's temp directory
+func useTempLogger(t *testing.T) {
+	t.Helper()
+	log, err := logger.NewLogger(logger.Config{LogPath: t.TempDir(), Outputs: []string{"file"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.SetDefault(log)
+	t.Cleanup(func() { logger.SetDefault(nil) })
+}
+
+func TestInitDBSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.db")
+	t.Setenv("DB_URL", "")
+	t.Setenv("DB_PATH", path)
+	db := connect(t, "sqlite")
+	if DB != db.DB {
+		t.Error("InitDB didn't set the package DB")
+	}
+}
+
+func TestInitDBRejectsUnknownDriver(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.DBDriver = "oracle"
+	_, err := InitDB(cfg)
+	if err == nil || !strings.Contains(err.Error(), "oracle") || !strings.Contains(err.Error(), "postgres, mysql, sqlite") {
+		t.Errorf("InitDB(oracle) = %v, want an error naming the supported drivers", err)
+	}
+}
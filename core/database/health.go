@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"base/core/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnavailable is reported while the last health check failed
+var ErrUnavailable = errors.New("database unavailable")
+
+const (
+	healthPingTimeout = 2 * time.Second
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+var health struct {
+	mu  sync.RWMutex
+	err error
+}
+
+// Healthy reports whether the last health check reached the database. It is
+// true until Monitor sees a failure.
+func Healthy() bool {
+	return HealthError() == nil
+}
+
+// HealthError returns why the last health check failed, or nil
+func HealthError() error {
+	health.mu.RLock()
+	defer health.mu.RUnlock()
+	return health.err
+}
+
+func setHealth(err error) {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+	health.err = err
+}
+
+// Monitor pings db every interval until ctx is done. When a ping fails the
+// database is marked unavailable and pings are retried with backoff, from one
+// second doubling up to 30; database/sql redials on the next ping, so the
+// first one that succeeds marks the database available again.
+func Monitor(ctx context.Context, db *gorm.DB, interval time.Duration, log logger.Logger) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Error("Database health monitor not started", logger.String("error", err.Error()))
+		return
+	}
+
+	delay := interval
+	backoff := minReconnectDelay
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+		err := sqlDB.PingContext(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			if Healthy() {
+				log.Error("Database connection lost", logger.String("error", err.Error()))
+			} else {
+				log.Warn("Database reconnect failed",
+					logger.String("error", err.Error()),
+					logger.String("retry_in", backoff.String()))
+			}
+			setHealth(errors.Join(ErrUnavailable, err))
+			delay = backoff
+			backoff = min(backoff*2, maxReconnectDelay)
+		} else {
+			if !Healthy() {
+				log.Info("Database connection restored")
+			}
+			setHealth(nil)
+			delay = interval
+			backoff = minReconnectDelay
+		}
+		timer.Reset(delay)
+	}
+}
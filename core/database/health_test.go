@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// outage makes the flaky driver refuse connections while set
+var outage atomic.Bool
+
+// flakyDriver is SQLite that can be taken down: while outage is set new
+// connections fail and pooled ones report themselves broken
+type flakyDriver struct{ driver.Driver }
+
+func (d flakyDriver) Open(name string) (driver.Conn, error) {
+	if outage.Load() {
+		return nil, errors.New("connection refused")
+	}
+	conn, err := d.Driver.Open(name)
+	return flakyConn{conn}, err
+}
+
+type flakyConn struct{ driver.Conn }
+
+func (c flakyConn) Ping(ctx context.Context) error {
+	if outage.Load() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func init() {
+	db, _ := sql.Open("sqlite3", "")
+	sql.Register("flaky_sqlite3", flakyDriver{db.Driver()})
+	db.Close()
+}
+
+func waitForHealth(t *testing.T, healthy bool, within time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(within)
+	for Healthy() != healthy {
+		if time.Now().After(deadline) {
+			t.Fatalf("Healthy() still %t after %v", !healthy, within)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMonitorDetectsOutageAndRecovers(t *testing.T) {
+	db, err := gorm.Open(sqlite.New(sqlite.Config{
+		DriverName: "flaky_sqlite3",
+		DSN:        filepath.Join(t.TempDir(), "health.db"),
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&ledgerAccount{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		Monitor(ctx, db, 20*time.Millisecond, logger.NewLoggerFromZap(zap.NewNop()))
+		close(done)
+	}()
+	t.Cleanup(func() {
+		outage.Store(false)
+		cancel()
+		<-done
+		setHealth(nil)
+	})
+
+	waitForHealth(t, true, time.Second)
+
+	outage.Store(true)
+	waitForHealth(t, false, time.Second)
+	if err := HealthError(); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("HealthError() = %v, want ErrUnavailable", err)
+	}
+
+	// The pool redials once the database is back, without reopening it
+	outage.Store(false)
+	waitForHealth(t, true, 3*time.Second)
+	if err := db.Create(&ledgerAccount{Balance: 5}).Error; err != nil {
+		t.Errorf("insert after recovery: %v", err)
+	}
+}
+
+func TestMonitorStopsWithContext(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "health.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		Monitor(ctx, db, time.Hour, logger.NewLoggerFromZap(zap.NewNop()))
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Monitor kept running after its context was canceled")
+	}
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"base/core/logger"
+
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// QueryLogger forwards GORM logs to the framework logger. Queries are logged
+// through logger.FromContext, so a query run with db.WithContext(ctx) on a
+// request context carries that request's id.
+type QueryLogger struct {
+	level         gormLogger.LogLevel
+	slowThreshold time.Duration
+	logParams     bool
+}
+
+// NewQueryLogger creates a GORM logger that reports failed queries as errors
+// and queries slower than slowThreshold as warnings. With logQueries every
+// other query is logged at debug level. A zero threshold disables slow query
+// warnings. Logged SQL keeps its placeholders unless logParams is set, since
+// bound values include password hashes, tokens and personal data.
+func NewQueryLogger(slowThreshold time.Duration, logQueries, logParams bool) *QueryLogger {
+	level := gormLogger.Warn
+	if logQueries {
+		level = gormLogger.Info
+	}
+	return &QueryLogger{
+		level:         level,
+		slowThreshold: slowThreshold,
+		logParams:     logParams,
+	}
+}
+
+// LogMode returns a copy logging at level
+func (l *QueryLogger) LogMode(level gormLogger.LogLevel) gormLogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// ParamsFilter drops the bound values from logged SQL unless logParams is set
+func (l *QueryLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	if l.logParams {
+		return sql, params
+	}
+	return sql, nil
+}
+
+func (l *QueryLogger) Info(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormLogger.Info {
+		logger.FromContext(ctx).Info(fmt.Sprintf(msg, data...), logger.String("source", utils.FileWithLineNum()))
+	}
+}
+
+func (l *QueryLogger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormLogger.Warn {
+		logger.FromContext(ctx).Warn(fmt.Sprintf(msg, data...), logger.String("source", utils.FileWithLineNum()))
+	}
+}
+
+func (l *QueryLogger) Error(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormLogger.Error {
+		logger.FromContext(ctx).Error(fmt.Sprintf(msg, data...), logger.String("source", utils.FileWithLineNum()))
+	}
+}
+
+// Trace logs a finished query. Record not found is not treated as a failure
+// since lookups use it to mean "no match".
+func (l *QueryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormLogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormLogger.Error
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormLogger.Warn
+	if !failed && !slow && l.level < gormLogger.Info {
+		return
+	}
+
+	sql, rows := fc()
+	fields := []logger.Field{
+		logger.String("sql", sql),
+		logger.Duration("duration", elapsed),
+		logger.Int64("rows", rows),
+		logger.String("source", utils.FileWithLineNum()),
+	}
+
+	log := logger.FromContext(ctx)
+	switch {
+	case failed:
+		log.Error("Query failed", append(fields, logger.String("error", err.Error()))...)
+	case slow:
+		log.Warn("Slow query", append(fields, logger.Duration("threshold", l.slowThreshold))...)
+	default:
+		log.Debug("Query", fields...)
+	}
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type account struct {
+	Id       uint
+	Email    string
+	Password string
+}
+
+// loggedSQL runs a lookup through a QueryLogger and returns the SQL it logged
+func loggedSQL(t *testing.T, logParams bool) string {
+	t.Helper()
+	useTempLogger(t)
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := logger.IntoContext(context.Background(), logger.NewLoggerFromZap(zap.New(core)))
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: NewQueryLogger(0, true, logParams),
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&account{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	var found []account
+	db.WithContext(ctx).Where("email = ? AND password = ?", "user@example.com", "hunter2").Find(&found)
+
+	for _, entry := range logs.FilterMessage("Query").All() {
+		if sql, ok := entry.ContextMap()["sql"].(string); ok && strings.Contains(sql, "email") {
+			return sql
+		}
+	}
+	t.Fatal("lookup was not logged")
+	return ""
+}
+
+func TestQueryLoggerKeepsPlaceholders(t *testing.T) {
+	sql := loggedSQL(t, false)
+	if strings.Contains(sql, "hunter2") || strings.Contains(sql, "user@example.com") {
+		t.Errorf("logged SQL contains bound values: %s", sql)
+	}
+	if !strings.Contains(sql, "email = ?") {
+		t.Errorf("logged SQL lost its placeholders: %s", sql)
+	}
+}
+
+func TestQueryLoggerLogsParamsWhenEnabled(t *testing.T) {
+	sql := loggedSQL(t, true)
+	if !strings.Contains(sql, `"user@example.com"`) {
+		t.Errorf("logged SQL is missing bound values: %s", sql)
+	}
+}
@@ -0,0 +1,184 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a versioned schema change. ID should be prefixed with a
+// timestamp (e.g. "20240101120000_create_posts") so migrations sort in the
+// order they were written.
+type Migration struct {
+	ID   string
+	Up   func(tx *gorm.DB) error
+	Down func(tx *gorm.DB) error
+}
+
+// SchemaMigration records an applied migration in the schema_migrations table
+type SchemaMigration struct {
+	ID        string    `gorm:"primaryKey;size:255"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for applied migrations
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus reports whether a registered migration has been applied
+type MigrationStatus struct {
+	ID        string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+var (
+	migrationsMu sync.RWMutex
+	migrations   = make(map[string]Migration)
+)
+
+// RegisterMigration registers a migration.
+// This should be called from the migration file's init() function
+func RegisterMigration(m Migration) {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+	if _, exists := migrations[m.ID]; exists {
+		panic(fmt.Sprintf("migration already registered: %s", m.ID))
+	}
+	migrations[m.ID] = m
+}
+
+// HasMigrations reports whether any migrations have been registered
+func HasMigrations() bool {
+	migrationsMu.RLock()
+	defer migrationsMu.RUnlock()
+	return len(migrations) > 0
+}
+
+// registeredMigrations returns all registered migrations sorted by ID
+func registeredMigrations() []Migration {
+	migrationsMu.RLock()
+	defer migrationsMu.RUnlock()
+	list := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Migrator applies and reverts registered migrations, tracking them in schema_migrations
+type Migrator struct {
+	db *gorm.DB
+}
+
+// NewMigrator creates a new migrator for the given database
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return &Migrator{db: db}, nil
+}
+
+// Migrate applies all pending migrations in order and returns the IDs it applied.
+// Each migration runs in its own transaction together with its schema_migrations record.
+func (m *Migrator) Migrate() ([]string, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []string
+	for _, migration := range registeredMigrations() {
+		if _, ok := applied[migration.ID]; ok {
+			continue
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if migration.Up != nil {
+				if err := migration.Up(tx); err != nil {
+					return err
+				}
+			}
+			return tx.Create(&SchemaMigration{ID: migration.ID, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return ran, fmt.Errorf("migration %s failed: %w", migration.ID, err)
+		}
+		ran = append(ran, migration.ID)
+	}
+
+	return ran, nil
+}
+
+// Rollback reverts the last applied migrations, up to steps, and returns the IDs it reverted
+func (m *Migrator) Rollback(steps int) ([]string, error) {
+	var records []SchemaMigration
+	if err := m.db.Order("id DESC").Limit(steps).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+
+	registered := make(map[string]Migration)
+	for _, migration := range registeredMigrations() {
+		registered[migration.ID] = migration
+	}
+
+	var reverted []string
+	for _, record := range records {
+		migration, ok := registered[record.ID]
+		if !ok {
+			return reverted, fmt.Errorf("migration %s is applied but not registered", record.ID)
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if migration.Down != nil {
+				if err := migration.Down(tx); err != nil {
+					return err
+				}
+			}
+			return tx.Delete(&SchemaMigration{ID: record.ID}).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("rollback of %s failed: %w", record.ID, err)
+		}
+		reverted = append(reverted, record.ID)
+	}
+
+	return reverted, nil
+}
+
+// Status lists every registered migration and whether it has been applied
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range registeredMigrations() {
+		status := MigrationStatus{ID: migration.ID}
+		if appliedAt, ok := applied[migration.ID]; ok {
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// applied returns the applied migration IDs with their timestamps
+func (m *Migrator) applied() (map[string]time.Time, error) {
+	var records []SchemaMigration
+	if err := m.db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+	applied := make(map[string]time.Time, len(records))
+	for _, record := range records {
+		applied[record.ID] = record.AppliedAt
+	}
+	return applied, nil
+}
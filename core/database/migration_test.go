@@ -0,0 +1,88 @@
+package database
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// withMigrations swaps the registry for the duration of a test
+func withMigrations(t *testing.T, ms ...Migration) {
+	t.Helper()
+	migrationsMu.Lock()
+	saved := migrations
+	migrations = map[string]Migration{}
+	migrationsMu.Unlock()
+	t.Cleanup(func() {
+		migrationsMu.Lock()
+		migrations = saved
+		migrationsMu.Unlock()
+	})
+	for _, m := range ms {
+		RegisterMigration(m)
+	}
+}
+
+func createTable(name string) Migration {
+	return Migration{
+		ID: name,
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec("CREATE TABLE " + name + " (id integer)").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Exec("DROP TABLE " + name).Error
+		},
+	}
+}
+
+func TestMigrateRollbackStatus(t *testing.T) {
+	withMigrations(t, createTable("m1_first"), createTable("m2_second"))
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "migrate.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := migrator.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(applied, []string{"m1_first", "m2_second"}) {
+		t.Fatalf("applied = %v", applied)
+	}
+	if applied, _ := migrator.Migrate(); len(applied) != 0 {
+		t.Errorf("second Migrate applied %v, want nothing", applied)
+	}
+
+	rolledBack, err := migrator.Rollback(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(rolledBack, []string{"m2_second"}) {
+		t.Fatalf("rolled back = %v, want [m2_second]", rolledBack)
+	}
+	if db.Migrator().HasTable("m2_second") {
+		t.Error("m2_second still exists after rollback")
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("statuses = %+v", statuses)
+	}
+	if !statuses[0].Applied || statuses[0].AppliedAt == nil {
+		t.Errorf("m1_first status = %+v, want applied", statuses[0])
+	}
+	if statuses[1].Applied {
+		t.Errorf("m2_second status = %+v, want pending", statuses[1])
+	}
+}
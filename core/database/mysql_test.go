@@ -0,0 +1,13 @@
+//go:build mysql
+
+package database
+
+import "testing"
+
+// Run against a server configured through DB_HOST, DB_PORT, DB_USER,
+// DB_PASSWORD and DB_NAME, or DB_URL:
+//
+//	go test -tags mysql ./core/database/
+func TestInitDBMySQL(t *testing.T) {
+	connect(t, "mysql")
+}
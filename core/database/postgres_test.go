@@ -0,0 +1,13 @@
+//go:build postgres
+
+package database
+
+import "testing"
+
+// Run against a server configured through DB_HOST, DB_PORT, DB_USER,
+// DB_PASSWORD, DB_NAME and DB_SSLMODE, or DB_URL:
+//
+//	go test -tags postgres ./core/database/
+func TestInitDBPostgres(t *testing.T) {
+	connect(t, "postgres")
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// WithTransaction runs fn in a transaction on the default DB
+func WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return Transaction(ctx, DB, fn)
+}
+
+// Transaction runs fn in a transaction on db. It commits when fn returns nil
+// and rolls back when fn returns an error or panics; a panic is re-raised
+// once the rollback is done.
+func Transaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	tx := db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type ledgerAccount struct {
+	Id      uint
+	Balance int
+}
+
+func newAccounts(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tx.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&ledgerAccount{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&[]ledgerAccount{{Id: 1, Balance: 100}, {Id: 2, Balance: 0}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// transfer moves amount between the two accounts, calling between after the
+// debit and before the credit
+func transfer(tx *gorm.DB, amount int, between func() error) error {
+	if err := tx.Model(&ledgerAccount{}).Where("id = 1").Update("balance", gorm.Expr("balance - ?", amount)).Error; err != nil {
+		return err
+	}
+	if err := between(); err != nil {
+		return err
+	}
+	return tx.Model(&ledgerAccount{}).Where("id = 2").Update("balance", gorm.Expr("balance + ?", amount)).Error
+}
+
+func balances(t *testing.T, db *gorm.DB) [2]int {
+	t.Helper()
+	var accounts []ledgerAccount
+	if err := db.Order("id").Find(&accounts).Error; err != nil {
+		t.Fatal(err)
+	}
+	return [2]int{accounts[0].Balance, accounts[1].Balance}
+}
+
+func TestTransactionCommits(t *testing.T) {
+	db := newAccounts(t)
+	err := Transaction(context.Background(), db, func(tx *gorm.DB) error {
+		return transfer(tx, 30, func() error { return nil })
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := balances(t, db); got != [2]int{70, 30} {
+		t.Errorf("balances = %v, want [70 30]", got)
+	}
+}
+
+func TestTransactionRollsBackOnError(t *testing.T) {
+	db := newAccounts(t)
+	failure := errors.New("declined")
+	err := Transaction(context.Background(), db, func(tx *gorm.DB) error {
+		return transfer(tx, 30, func() error { return failure })
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("err = %v, want the callback's error", err)
+	}
+	if got := balances(t, db); got != [2]int{100, 0} {
+		t.Errorf("balances = %v after rollback, want [100 0]", got)
+	}
+}
+
+func TestTransactionRollsBackOnPanic(t *testing.T) {
+	db := newAccounts(t)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recovered %v, want the panic re-raised", r)
+			}
+		}()
+		Transaction(context.Background(), db, func(tx *gorm.DB) error {
+			return transfer(tx, 30, func() error { panic("boom") })
+		})
+	}()
+
+	if got := balances(t, db); got != [2]int{100, 0} {
+		t.Errorf("balances = %v after panic, want [100 0]", got)
+	}
+
+	// The connection went back to the pool usable
+	if err := Transaction(context.Background(), db, func(tx *gorm.DB) error {
+		return transfer(tx, 10, func() error { return nil })
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := balances(t, db); got != [2]int{90, 10} {
+		t.Errorf("balances = %v, want [90 10]", got)
+	}
+}
+
+func TestWithTransactionUsesDefaultDB(t *testing.T) {
+	saved := DB
+	DB = newAccounts(t)
+	t.Cleanup(func() { DB = saved })
+
+	if err := WithTransaction(context.Background(), func(tx *gorm.DB) error {
+		return transfer(tx, 100, func() error { return nil })
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := balances(t, DB); got != [2]int{0, 100} {
+		t.Errorf("balances = %v, want [0 100]", got)
+	}
+}
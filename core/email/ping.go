@@ -0,0 +1,39 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Pinger is implemented by senders that can report whether their provider is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks that the provider behind s is reachable. Senders that can't
+// report their health, like the default one, are assumed to be up.
+func Ping(ctx context.Context, s Sender) error {
+	if pinger, ok := s.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// pingHTTP sends an authenticated GET to a provider API and expects a 2xx
+func pingHTTP(ctx context.Context, url string, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("%s answered %s", url, res.Status)
+	}
+	return nil
+}
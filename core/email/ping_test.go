@@ -0,0 +1,75 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSMTPPing(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("220 localhost ESMTP\r\n"))
+		lines := bufio.NewScanner(conn)
+		for lines.Scan() {
+			if strings.HasPrefix(lines.Text(), "QUIT") {
+				conn.Write([]byte("221 bye\r\n"))
+				return
+			}
+			conn.Write([]byte("250 localhost\r\n"))
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	up := &SMTPSender{host: "127.0.0.1", port: addr.Port}
+	if err := Ping(ctx, up); err != nil {
+		t.Fatalf("Ping() = %v, want nil", err)
+	}
+
+	ln.Close()
+	down := &SMTPSender{host: "127.0.0.1", port: addr.Port}
+	if err := Ping(ctx, down); err == nil {
+		t.Fatal("Ping() on a closed port = nil, want an error")
+	}
+}
+
+func TestSendGridPing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"scopes":["mail.send"]}`))
+	}))
+	defer srv.Close()
+
+	for key, wantErr := range map[string]bool{"good": false, "bad": true} {
+		s := &SendGridSender{apiKey: key, pingURL: srv.URL}
+		if err := Ping(context.Background(), s); (err != nil) != wantErr {
+			t.Errorf("Ping() with key %q = %v, want error %t", key, err, wantErr)
+		}
+	}
+}
+
+func TestPingDefaultSender(t *testing.T) {
+	if err := Ping(context.Background(), &DefaultSender{}); err != nil {
+		t.Fatalf("Ping() = %v, want nil", err)
+	}
+}
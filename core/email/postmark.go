@@ -2,6 +2,8 @@ package email
 
 import (
 	"base/core/config"
+	"context"
+	"net/http"
 
 	"github.com/keighl/postmark"
 )
@@ -37,3 +39,11 @@ func (s *PostmarkSender) Send(msg Message) error {
 	_, err := s.client.SendEmail(email)
 	return err
 }
+
+// Ping checks that the Postmark API is reachable and accepts the server token
+func (s *PostmarkSender) Ping(ctx context.Context) error {
+	return pingHTTP(ctx, s.client.BaseURL+"/server", http.Header{
+		"Accept":                  {"application/json"},
+		"X-Postmark-Server-Token": {s.client.ServerToken},
+	})
+}
@@ -2,21 +2,30 @@ package email
 
 import (
 	"base/core/config"
+	"context"
+	"net/http"
 
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
 )
 
+// sendGridScopesURL lists the API key's scopes, which any valid key can read
+const sendGridScopesURL = "https://api.sendgrid.com/v3/scopes"
+
 type SendGridSender struct {
-	client *sendgrid.Client
-	from   string
+	client  *sendgrid.Client
+	from    string
+	apiKey  string
+	pingURL string
 }
 
 func NewSendGridSender(cfg *config.Config) (*SendGridSender, error) {
 	client := sendgrid.NewSendClient(cfg.SendGridAPIKey)
 	return &SendGridSender{
-		client: client,
-		from:   cfg.EmailFromAddress,
+		client:  client,
+		from:    cfg.EmailFromAddress,
+		apiKey:  cfg.SendGridAPIKey,
+		pingURL: sendGridScopesURL,
 	}, nil
 }
 
@@ -33,3 +42,10 @@ func (s *SendGridSender) Send(msg Message) error {
 	_, err := s.client.Send(email)
 	return err
 }
+
+// Ping checks that the SendGrid API is reachable and accepts the API key
+func (s *SendGridSender) Ping(ctx context.Context) error {
+	return pingHTTP(ctx, s.pingURL, http.Header{
+		"Authorization": {"Bearer " + s.apiKey},
+	})
+}
@@ -2,8 +2,11 @@ package email
 
 import (
 	"base/core/config"
+	"context"
 	"fmt"
+	"net"
 	"net/smtp"
+	"strconv"
 )
 
 type SMTPSender struct {
@@ -40,3 +43,22 @@ func (s *SMTPSender) Send(msg Message) error {
 
 	return smtp.SendMail(addr, auth, s.from, msg.To, []byte(message))
 }
+
+// Ping connects to the SMTP server and waits for its greeting
+func (s *SMTPSender) Ping(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.host, strconv.Itoa(s.port)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,26 @@
+package events
+
+import (
+	"time"
+)
+
+// OutboxEvent is an event written in the same transaction as the change it
+// describes. The relay emits it once the transaction has committed and sets
+// SentAt; rows are kept for a week after that. An event whose payload can't
+// be decoded is given up on after relayMaxAttempts and gets FailedAt and
+// Error instead; failed rows are kept for inspection.
+type OutboxEvent struct {
+	Id        uint       `gorm:"column:id;primary_key;auto_increment"`
+	Event     string     `gorm:"column:event;not null;size:255;index"`
+	Payload   string     `gorm:"column:payload;type:text;not null"`
+	Attempts  int        `gorm:"column:attempts;not null;default:0"`
+	LockedAt  *time.Time `gorm:"column:locked_at;index"`
+	SentAt    *time.Time `gorm:"column:sent_at;index"`
+	FailedAt  *time.Time `gorm:"column:failed_at;index"`
+	Error     string     `gorm:"column:error;type:text"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
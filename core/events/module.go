@@ -0,0 +1,50 @@
+package events
+
+import (
+	"context"
+
+	"base/core/config"
+	"base/core/emitter"
+	"base/core/logger"
+	"base/core/module"
+
+	"gorm.io/gorm"
+)
+
+// Module runs the outbox relay
+type Module struct {
+	module.DefaultModule
+	DB    *gorm.DB
+	Relay *Relay
+}
+
+// NewEventsModule creates the events module. Its relay polls the outbox
+// every OUTBOX_POLL_INTERVAL.
+func NewEventsModule(db *gorm.DB, emitter *emitter.Emitter, log logger.Logger, cfg *config.Config) module.Module {
+	return &Module{
+		DB:    db,
+		Relay: NewRelay(db, emitter, log, cfg.OutboxPollInterval),
+	}
+}
+
+func (m *Module) Migrate() error {
+	return m.DB.AutoMigrate(&OutboxEvent{})
+}
+
+func (m *Module) GetModels() []any {
+	return []any{
+		&OutboxEvent{},
+	}
+}
+
+// Start launches the relay
+func (m *Module) Start(ctx context.Context) error {
+	m.Relay.Start()
+	return nil
+}
+
+// Stop waits for the relay to finish its current batch
+func (m *Module) Stop(ctx context.Context) error {
+	m.Relay.Stop()
+	return nil
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var (
+	typesMu sync.RWMutex
+	types   = make(map[string]reflect.Type)
+)
+
+// Publish writes event to the outbox on tx, so it is delivered if and only
+// if tx commits. Use it inside database.WithTransaction:
+//
+//	err := database.WithTransaction(ctx, func(tx *gorm.DB) error {
+//		if err := tx.Create(&user).Error; err != nil {
+//			return err
+//		}
+//		return events.Publish(tx, "user.registered", userData)
+//	})
+//
+// Payload is stored as JSON. The relay emits committed events on the emitter,
+// and from there to webhooks, at least once: a crash after an event was
+// emitted but before it was marked sent emits it again on the next run.
+func Publish(tx *gorm.DB, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s payload: %w", event, err)
+	}
+	if err := tx.Create(&OutboxEvent{Event: event, Payload: string(data)}).Error; err != nil {
+		return fmt.Errorf("failed to publish %s: %w", event, err)
+	}
+	return nil
+}
+
+// Register makes the relay decode payloads of event into the type of
+// example, so listeners receive the same value they would get from a direct
+// Emit:
+//
+//	events.Register("user.registered", types.UserData{})
+//
+// Payloads of unregistered events are emitted as json.RawMessage.
+func Register(event string, example any) {
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	types[event] = reflect.TypeOf(example)
+}
+
+// decode unmarshals the payload of e into its registered type
+func decode(e *OutboxEvent) (any, error) {
+	typesMu.RLock()
+	t, ok := types[e.Event]
+	typesMu.RUnlock()
+	if !ok {
+		return json.RawMessage(e.Payload), nil
+	}
+
+	v := reflect.New(t)
+	if err := json.Unmarshal([]byte(e.Payload), v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
@@ -0,0 +1,188 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"base/core/emitter"
+	"base/core/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	relayBatchSize = 100
+
+	// A claimed event not marked sent by then is picked up again; its
+	// relay most likely crashed mid-delivery
+	relayLockTimeout = 5 * time.Minute
+
+	// An event that still can't be decoded after this many claims is
+	// marked failed instead of being retried forever
+	relayMaxAttempts = 5
+
+	// Sent events are kept this long, then deleted
+	outboxRetention = 7 * 24 * time.Hour
+	pruneInterval   = time.Hour
+)
+
+// Relay emits committed outbox events in the order they were published and
+// marks them sent. Several instances can run against one database; each event
+// is claimed by one of them.
+type Relay struct {
+	db           *gorm.DB
+	emitter      *emitter.Emitter
+	logger       logger.Logger
+	pollInterval time.Duration
+
+	quit      chan struct{}
+	done      chan struct{}
+	stopOnce  sync.Once
+	lastPrune time.Time
+}
+
+// NewRelay creates a relay that looks for new events every pollInterval
+func NewRelay(db *gorm.DB, emitter *emitter.Emitter, log logger.Logger, pollInterval time.Duration) *Relay {
+	return &Relay{
+		db:           db,
+		emitter:      emitter,
+		logger:       log,
+		pollInterval: pollInterval,
+		quit:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Start launches the relay loop
+func (r *Relay) Start() {
+	go r.run()
+}
+
+// Stop stops the relay after the batch it is delivering
+func (r *Relay) Stop() {
+	r.stopOnce.Do(func() { close(r.quit) })
+	<-r.done
+}
+
+func (r *Relay) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := r.RelayPending()
+		if err != nil {
+			r.logger.Error("Failed to relay outbox events", logger.String("error", err.Error()))
+		}
+		r.prune()
+
+		// A full batch means more are likely waiting
+		if n == relayBatchSize {
+			select {
+			case <-r.quit:
+				return
+			default:
+				continue
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// RelayPending emits up to one batch of unsent events and returns how many
+// it emitted
+func (r *Relay) RelayPending() (int, error) {
+	var pending []OutboxEvent
+	err := r.db.Where("sent_at IS NULL AND failed_at IS NULL AND (locked_at IS NULL OR locked_at < ?)", time.Now().Add(-relayLockTimeout)).
+		Order("id").
+		Limit(relayBatchSize).
+		Find(&pending).Error
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range pending {
+		event := &pending[i]
+		claimed, err := r.claim(event)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+
+		payload, err := decode(event)
+		if err != nil {
+			if err := r.fail(event, err); err != nil {
+				return sent, err
+			}
+			continue
+		}
+
+		r.emitter.Emit(event.Event, payload)
+
+		now := time.Now()
+		if err := r.db.Model(&OutboxEvent{}).Where("id = ?", event.Id).
+			Updates(map[string]any{"sent_at": now, "locked_at": nil}).Error; err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// claim locks event for this relay. The conditional update makes sure only
+// one relay wins it.
+func (r *Relay) claim(event *OutboxEvent) (bool, error) {
+	query := r.db.Model(&OutboxEvent{}).Where("id = ? AND sent_at IS NULL AND attempts = ?", event.Id, event.Attempts)
+	result := query.Updates(map[string]any{
+		"locked_at": time.Now(),
+		"attempts":  gorm.Expr("attempts + 1"),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// fail records why event could not be decoded. Until it has been claimed
+// relayMaxAttempts times it stays locked and is retried once the lock times
+// out, e.g. after a deploy registers the type it was published with; then it
+// is marked failed and no longer relayed.
+func (r *Relay) fail(event *OutboxEvent, decodeErr error) error {
+	updates := map[string]any{"error": decodeErr.Error()}
+	final := event.Attempts+1 >= relayMaxAttempts
+	if final {
+		updates["failed_at"] = time.Now()
+		updates["locked_at"] = nil
+	}
+
+	r.logger.Error("Failed to decode outbox event",
+		logger.Int("id", int(event.Id)),
+		logger.String("event", event.Event),
+		logger.Int("attempt", event.Attempts+1),
+		logger.Bool("final", final),
+		logger.String("error", decodeErr.Error()))
+
+	return r.db.Model(&OutboxEvent{}).Where("id = ?", event.Id).Updates(updates).Error
+}
+
+// prune deletes events sent more than outboxRetention ago, at most once per
+// pruneInterval
+func (r *Relay) prune() {
+	if time.Since(r.lastPrune) < pruneInterval {
+		return
+	}
+	r.lastPrune = time.Now()
+
+	result := r.db.Where("sent_at < ?", time.Now().Add(-outboxRetention)).Delete(&OutboxEvent{})
+	if result.Error != nil {
+		r.logger.Error("Failed to prune outbox events", logger.String("error", result.Error.Error()))
+	}
+}
@@ -0,0 +1,174 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"base/core/database"
+	"base/core/emitter"
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type memberJoined struct {
+	UserId uint `json:"user_id"`
+}
+
+func newTestRelay(t *testing.T) (*Relay, *emitter.Emitter) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "events.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&OutboxEvent{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	em := emitter.New()
+	return NewRelay(db, em, logger.NewLoggerFromZap(zap.NewNop()), time.Second), em
+}
+
+func TestRelayEmitsAndMarksSent(t *testing.T) {
+	Register("test.member_joined", memberJoined{})
+	relay, em := newTestRelay(t)
+
+	var got []memberJoined
+	em.On("test.member_joined", func(data any) { got = append(got, data.(memberJoined)) })
+
+	if err := Publish(relay.db, "test.member_joined", memberJoined{UserId: 7}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	n, err := relay.RelayPending()
+	if err != nil || n != 1 {
+		t.Fatalf("RelayPending = %d, %v; want 1, nil", n, err)
+	}
+	if len(got) != 1 || got[0].UserId != 7 {
+		t.Errorf("listener got %v", got)
+	}
+
+	if n, _ := relay.RelayPending(); n != 0 {
+		t.Errorf("sent event relayed again")
+	}
+}
+
+func TestRelayFailsUndecodableEvents(t *testing.T) {
+	Register("test.undecodable", memberJoined{})
+	relay, em := newTestRelay(t)
+
+	emitted := 0
+	em.On("test.undecodable", func(any) { emitted++ })
+
+	event := OutboxEvent{Event: "test.undecodable", Payload: `{"user_id": "seven"}`}
+	if err := relay.db.Create(&event).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	for attempt := 1; attempt <= relayMaxAttempts; attempt++ {
+		if _, err := relay.RelayPending(); err != nil {
+			t.Fatalf("attempt %d: %v", attempt, err)
+		}
+
+		var stored OutboxEvent
+		relay.db.First(&stored, event.Id)
+		if stored.Attempts != attempt {
+			t.Fatalf("attempts = %d, want %d", stored.Attempts, attempt)
+		}
+		if stored.Error == "" {
+			t.Errorf("attempt %d: decode error not recorded", attempt)
+		}
+		if failed := stored.FailedAt != nil; failed != (attempt == relayMaxAttempts) {
+			t.Fatalf("attempt %d: failed = %v", attempt, failed)
+		}
+
+		// Let the lock time out so the next run claims it again
+		relay.db.Model(&OutboxEvent{}).Where("id = ?", event.Id).
+			Update("locked_at", time.Now().Add(-2*relayLockTimeout))
+	}
+
+	// Failed events are no longer claimed
+	if _, err := relay.RelayPending(); err != nil {
+		t.Fatal(err)
+	}
+	var stored OutboxEvent
+	relay.db.First(&stored, event.Id)
+	if stored.Attempts != relayMaxAttempts {
+		t.Errorf("failed event claimed again: attempts = %d", stored.Attempts)
+	}
+	if emitted != 0 {
+		t.Errorf("undecodable event emitted %d times", emitted)
+	}
+}
+
+func TestCommittedEventsAreDeliveredLater(t *testing.T) {
+	Register("test.member_joined", memberJoined{})
+	relay, em := newTestRelay(t)
+	relay.pollInterval = 10 * time.Millisecond
+
+	delivered := make(chan memberJoined, 2)
+	em.On("test.member_joined", func(data any) { delivered <- data.(memberJoined) })
+
+	// Nothing is emitted while the transactions run
+	ctx := context.Background()
+	if err := database.Transaction(ctx, relay.db, func(tx *gorm.DB) error {
+		return Publish(tx, "test.member_joined", memberJoined{UserId: 1})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rollback := errors.New("rollback")
+	if err := database.Transaction(ctx, relay.db, func(tx *gorm.DB) error {
+		if err := Publish(tx, "test.member_joined", memberJoined{UserId: 2}); err != nil {
+			return err
+		}
+		return rollback
+	}); !errors.Is(err, rollback) {
+		t.Fatalf("transaction = %v", err)
+	}
+	if len(delivered) != 0 {
+		t.Fatal("event emitted before the relay ran")
+	}
+
+	relay.Start()
+	defer relay.Stop()
+	select {
+	case got := <-delivered:
+		if got.UserId != 1 {
+			t.Errorf("delivered %+v, want the committed event", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("committed event not delivered")
+	}
+	select {
+	case got := <-delivered:
+		t.Errorf("delivered %+v from a rolled back transaction", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRelayRedeliversUnconfirmedEvents(t *testing.T) {
+	Register("test.member_joined", memberJoined{})
+	relay, em := newTestRelay(t)
+
+	emitted := 0
+	em.On("test.member_joined", func(any) { emitted++ })
+	if err := Publish(relay.db, "test.member_joined", memberJoined{UserId: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A relay that crashed after claiming the event left it locked and unsent
+	relay.db.Model(&OutboxEvent{}).Where("sent_at IS NULL").Update("locked_at", time.Now())
+	if n, _ := relay.RelayPending(); n != 0 {
+		t.Fatalf("relayed %d events locked by another relay", n)
+	}
+
+	relay.db.Model(&OutboxEvent{}).Where("sent_at IS NULL").
+		Update("locked_at", time.Now().Add(-2*relayLockTimeout))
+	if n, err := relay.RelayPending(); n != 1 || err != nil || emitted != 1 {
+		t.Errorf("RelayPending = %d, %v with %d emitted, want the stale event delivered", n, err, emitted)
+	}
+}
@@ -0,0 +1,301 @@
+package export
+
+import (
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"base/core/logger"
+	"base/core/query"
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+// Supported formats
+const (
+	CSV  = "csv"
+	JSON = "json"
+)
+
+// ErrUnsupportedFormat is returned for formats other than csv and json
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// flushEvery is how many rows are written between flushes
+const flushEvery = 500
+
+// Flusher is implemented by writers that can send what was written so far,
+// such as router.ResponseWriter
+type Flusher interface {
+	Flush()
+}
+
+// ContentType returns the Content-Type for format
+func ContentType(format string) string {
+	if format == CSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/json"
+}
+
+// Column is a CSV column: a simple field of the model and its json name
+type Column struct {
+	Name  string
+	index []int
+}
+
+// Columns lists the CSV columns of model in field order. Names come from
+// json tags; fields tagged "-", unexported fields, and nested structs,
+// slices and maps other than times are left out.
+func Columns(model any) []Column {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return columns(t, nil)
+}
+
+func columns(t reflect.Type, parent []int) []Column {
+	var cols []Column
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int(nil), parent...), i)
+
+		// Embedded structs such as gorm.Model contribute their fields
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			cols = append(cols, columns(field.Type, index)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !simple(field.Type) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		cols = append(cols, Column{Name: name, index: index})
+	}
+	return cols
+}
+
+// simple reports whether a field type has a single CSV value
+func simple(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) || t == reflect.TypeOf(gorm.DeletedAt{}) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+		return false
+	}
+	return true
+}
+
+// Write streams every row of db, which must have its model set, to w in
+// format. Rows are scanned one at a time so the table is never held in
+// memory, and w is flushed as it goes when it's a Flusher.
+//
+//	db := s.DB.Model(&Post{}).Scopes(params.Where())
+//	export.Write(c.Writer, export.CSV, db, &Post{})
+func Write(w io.Writer, format string, db *gorm.DB, model any) error {
+	if format != CSV && format != JSON {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	rows, err := db.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var out rowWriter
+	if format == CSV {
+		out, err = newCSVWriter(w, Columns(model))
+	} else {
+		out, err = newJSONWriter(w)
+	}
+	if err != nil {
+		return err
+	}
+
+	for n := 1; rows.Next(); n++ {
+		item := reflect.New(t)
+		if err := db.ScanRows(rows, item.Interface()); err != nil {
+			return err
+		}
+		if err := out.write(item.Elem()); err != nil {
+			return err
+		}
+		if n%flushEvery == 0 {
+			if err := out.flush(); err != nil {
+				return err
+			}
+			if f, ok := w.(Flusher); ok {
+				f.Flush()
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return out.close()
+}
+
+type rowWriter interface {
+	write(item reflect.Value) error
+	flush() error
+	close() error
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), columns: columns, record: make([]string, len(columns))}
+	for i, column := range columns {
+		cw.record[i] = column.Name
+	}
+	return cw, cw.w.Write(cw.record)
+}
+
+func (cw *csvWriter) write(item reflect.Value) error {
+	for i, column := range cw.columns {
+		cw.record[i] = formatValue(item.FieldByIndex(column.index))
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvWriter) flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) close() error {
+	return cw.flush()
+}
+
+// formatValue renders a simple field as a CSV cell; nil and null values are
+// empty
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		if value.IsZero() {
+			return ""
+		}
+		return value.Format(time.RFC3339)
+	case gorm.DeletedAt:
+		if !value.Valid {
+			return ""
+		}
+		return value.Time.Format(time.RFC3339)
+	case driver.Valuer:
+		if dv, err := value.Value(); err == nil && dv != nil {
+			return fmt.Sprint(dv)
+		}
+		return ""
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// jsonWriter writes a JSON array one element at a time
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONWriter(w io.Writer) (*jsonWriter, error) {
+	_, err := io.WriteString(w, "[")
+	return &jsonWriter{w: w}, err
+}
+
+func (jw *jsonWriter) write(item reflect.Value) error {
+	data, err := json.Marshal(item.Addr().Interface())
+	if err != nil {
+		return err
+	}
+	if jw.count > 0 {
+		if _, err := io.WriteString(jw.w, ","); err != nil {
+			return err
+		}
+	}
+	jw.count++
+	_, err = jw.w.Write(data)
+	return err
+}
+
+func (jw *jsonWriter) flush() error {
+	return nil
+}
+
+func (jw *jsonWriter) close() error {
+	_, err := io.WriteString(jw.w, "]\n")
+	return err
+}
+
+// Respond streams the rows of model matching the request's list parameters
+// as an attachment named name.csv or name.json (?format=csv|json, csv by
+// default). Filters, search, sort and trashed work as on the list endpoint;
+// page and limit are ignored. Invalid parameters answer 400; an error once
+// rows are streaming can only be logged.
+func Respond(c *router.Context, db *gorm.DB, model any, name string) error {
+	format := c.DefaultQuery("format", CSV)
+	if format != CSV && format != JSON {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "format must be csv or json",
+		})
+	}
+
+	params, err := query.Parse(c.Request.URL.Query(), model)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	c.SetHeader("Content-Type", ContentType(format))
+	c.SetHeader("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	c.Writer.WriteHeader(http.StatusOK)
+
+	if err := Write(c.Writer, format, db.WithContext(c).Model(model).Scopes(params.Scope()), model); err != nil {
+		logger.FromContext(c).Error("Export failed",
+			logger.String("resource", name),
+			logger.String("error", err.Error()))
+	}
+	return nil
+}
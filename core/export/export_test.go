@@ -0,0 +1,155 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/router"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type post struct {
+	Id        uint           `json:"id" gorm:"primaryKey"`
+	Title     string         `json:"title"`
+	Views     int            `json:"views"`
+	Published *time.Time     `json:"published_at"`
+	Tags      []string       `json:"tags" gorm:"serializer:json"`
+	Secret    string         `json:"-"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at"`
+}
+
+func (post) FilterableFields() []string { return []string{"views"} }
+func (post) SortableFields() []string   { return []string{"id", "views"} }
+
+func newPosts(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "export.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&post{}); err != nil {
+		t.Fatal(err)
+	}
+	published := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	posts := []post{
+		{Title: "Hello, world", Views: 10, Published: &published, Tags: []string{"a"}, Secret: "x"},
+		{Title: `Say "hi"`, Views: 3},
+		{Title: "Trashed", Views: 7, DeletedAt: gorm.DeletedAt{Time: published, Valid: true}},
+	}
+	if err := db.Create(&posts).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func exported(t *testing.T, db *gorm.DB, rawQuery string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := router.New()
+	r.GET("/posts/export", func(c *router.Context) error {
+		return Respond(c, db, &post{}, "posts")
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/export?"+rawQuery, nil))
+	return w
+}
+
+func TestColumns(t *testing.T) {
+	var names []string
+	for _, column := range Columns(&post{}) {
+		names = append(names, column.Name)
+	}
+	if want := []string{"id", "title", "views", "published_at", "deleted_at"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("columns = %v, want %v", names, want)
+	}
+}
+
+func TestRespondCSV(t *testing.T) {
+	w := exported(t, newPosts(t), "sort=id")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="posts.csv"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"id", "title", "views", "published_at", "deleted_at"},
+		{"1", "Hello, world", "10", "2026-01-02T03:04:05Z", ""},
+		{"2", `Say "hi"`, "3", "", ""},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %q, want %q", records, want)
+	}
+}
+
+func TestRespondFilters(t *testing.T) {
+	db := newPosts(t)
+
+	w := exported(t, db, "format=json&filter[views][gte]=5&trashed=with&sort=-views")
+	var posts []post
+	if err := json.Unmarshal(w.Body.Bytes(), &posts); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	if len(posts) != 2 || posts[0].Title != "Hello, world" || posts[1].Title != "Trashed" {
+		t.Errorf("posts = %+v, want the two with 5+ views, most viewed first", posts)
+	}
+	if w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Content-Type = %q", w.Header().Get("Content-Type"))
+	}
+
+	// Page and limit don't truncate an export
+	if records, _ := csv.NewReader(exported(t, db, "limit=1").Body).ReadAll(); len(records) != 3 {
+		t.Errorf("limit=1 exported %d records, want a header and both rows", len(records))
+	}
+
+	for _, rawQuery := range []string{"format=xml", "filter[title]=x", "sort=title"} {
+		if w := exported(t, db, rawQuery); w.Code != http.StatusBadRequest {
+			t.Errorf("%s = %d, want 400", rawQuery, w.Code)
+		}
+	}
+}
+
+func TestWriteFlushesLargeExports(t *testing.T) {
+	db := newPosts(t)
+	rows := make([]post, flushEvery*2)
+	for i := range rows {
+		rows[i].Title = "bulk"
+	}
+	if err := db.CreateInBatches(&rows, 200).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	w := &flushRecorder{}
+	if err := Write(w, CSV, db.Model(&post{}), &post{}); err != nil {
+		t.Fatal(err)
+	}
+	if w.flushes < 2 {
+		t.Errorf("flushed %d times for %d rows", w.flushes, len(rows))
+	}
+	if lines := strings.Count(w.String(), "\n"); lines != len(rows)+3 {
+		t.Errorf("wrote %d lines, want %d", lines, len(rows)+3)
+	}
+}
+
+type flushRecorder struct {
+	strings.Builder
+	flushes int
+}
+
+func (w *flushRecorder) Flush() { w.flushes++ }
@@ -1,14 +1,13 @@
 package helper
 
 import (
-	"base/core/config"
 	"base/core/types"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gertd/go-pluralize"
-	"github.com/golang-jwt/jwt/v5"
 	"gorm.io/gorm"
 )
 
@@ -23,24 +22,19 @@ func GenerateJWT(userId uint) (string, error) {
 	return types.GenerateJWT(userId, nil)
 }
 
-func ValidateJWT(tokenString string) (any, uint, error) {
-	cfg := config.NewConfig()
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		return []byte(cfg.JWTSecret), nil
-	})
+// GenerateJWTWithTTL issues a token for userId valid for ttl
+func GenerateJWTWithTTL(userId uint, ttl time.Duration) (string, error) {
+	return types.GenerateJWTWithTTL(userId, nil, ttl)
+}
 
+// ValidateJWT is a wrapper around types.ValidateJWT for backward compatibility,
+// so it also rejects MFA pending tokens and tokens with a stale version
+func ValidateJWT(tokenString string) (any, uint, error) {
+	userId, err := types.ValidateJWT(tokenString)
 	if err != nil {
-		return 0, 0, err
+		return nil, 0, err
 	}
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userId := uint(claims["user_id"].(float64))
-
-		return nil, userId, nil
-	}
-
-	return nil, 0, jwt.ErrSignatureInvalid
+	return nil, userId, nil
 }
 
 // ModelRegistry holds registered model constructors for dynamic object retrieval
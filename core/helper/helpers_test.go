@@ -0,0 +1,39 @@
+package helper
+
+import (
+	"testing"
+	"time"
+
+	"base/core/types"
+)
+
+func TestValidateJWT(t *testing.T) {
+	types.SetKeySet(types.NewHMACKeySet("test", "test-secret"))
+	t.Cleanup(func() { types.SetKeySet(nil) })
+
+	version := uint(1)
+	types.SetTokenVersionFunc(func(uint) (uint, error) { return version, nil })
+	t.Cleanup(func() { types.SetTokenVersionFunc(nil) })
+
+	token, err := GenerateJWT(42)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	if _, userId, err := ValidateJWT(token); err != nil || userId != 42 {
+		t.Fatalf("ValidateJWT = %d, %v; want 42, nil", userId, err)
+	}
+
+	// A password change bumps the version and signs the token out
+	version = 2
+	if _, _, err := ValidateJWT(token); err == nil {
+		t.Error("ValidateJWT accepted a token with a stale version")
+	}
+
+	pending, err := types.GenerateMFAPendingJWT(42, "challenge", time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateMFAPendingJWT: %v", err)
+	}
+	if _, _, err := ValidateJWT(pending); err == nil {
+		t.Error("ValidateJWT accepted an MFA pending token")
+	}
+}
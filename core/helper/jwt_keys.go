@@ -0,0 +1,138 @@
+package helper
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"base/core/config"
+	"base/core/types"
+)
+
+// LoadKeys builds the JWT key set from configuration and installs it for
+// token issuance and validation.
+//
+// HS256 (the default) signs with JWT_SECRET. RS256 signs with JWT_PRIVATE_KEY,
+// given either as PEM content or a path to a PEM file. JWT_PREVIOUS_KEYS lists
+// retired keys as comma-separated "kid:value" pairs that are still accepted for
+// verification; the value is an old secret for HS256 or a public key PEM
+// (content or path) for RS256.
+func LoadKeys(cfg *config.Config) (*types.KeySet, error) {
+	var ks *types.KeySet
+
+	switch strings.ToUpper(cfg.JWTAlgorithm) {
+	case "", "HS256":
+		kid := cfg.JWTKeyID
+		if kid == "" {
+			kid = "default"
+		}
+		ks = types.NewHMACKeySet(kid, cfg.JWTSecret)
+	case "RS256":
+		if cfg.JWTPrivateKey == "" {
+			return nil, errors.New("JWT_PRIVATE_KEY is required for RS256")
+		}
+		data, err := readPEM(cfg.JWTPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, err := parseRSAPrivateKey(data)
+		if err != nil {
+			return nil, err
+		}
+		kid := cfg.JWTKeyID
+		if kid == "" {
+			kid = keyThumbprint(&privateKey.PublicKey)
+		}
+		ks = types.NewRSAKeySet(kid, privateKey)
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm: %s", cfg.JWTAlgorithm)
+	}
+
+	for _, entry := range cfg.JWTPreviousKeys {
+		kid, value, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || kid == "" || value == "" {
+			return nil, fmt.Errorf("invalid JWT_PREVIOUS_KEYS entry %q, expected kid:value", entry)
+		}
+
+		if ks.Algorithm() == "HS256" {
+			ks.AddHMACKey(kid, value)
+			continue
+		}
+
+		data, err := readPEM(value)
+		if err != nil {
+			return nil, err
+		}
+		publicKey, err := parseRSAPublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("previous key %s: %w", kid, err)
+		}
+		ks.AddRSAKey(kid, publicKey)
+	}
+
+	types.SetKeySet(ks)
+	return ks, nil
+}
+
+// readPEM returns the value itself when it is PEM content, otherwise reads it as a file path
+func readPEM(value string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		// Env vars often carry escaped newlines
+		return []byte(strings.ReplaceAll(value, `\n`, "\n")), nil
+	}
+	data, err := os.ReadFile(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	return data, nil
+}
+
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM public key")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// keyThumbprint derives a stable kid from the public key
+func keyThumbprint(publicKey *rsa.PublicKey) string {
+	sum := sha256.Sum256(x509.MarshalPKCS1PublicKey(publicKey))
+	return hex.EncodeToString(sum[:8])
+}
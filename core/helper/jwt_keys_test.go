@@ -0,0 +1,142 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"base/core/config"
+	"base/core/types"
+)
+
+func rsaKeyPEM(t *testing.T) (*rsa.PrivateKey, string, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	private := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return key, string(private), string(public)
+}
+
+// issue loads cfg's keys and returns a token for userId signed with them
+func issue(t *testing.T, cfg *config.Config, userId uint) string {
+	t.Helper()
+	if _, err := LoadKeys(cfg); err != nil {
+		t.Fatalf("LoadKeys: %v", err)
+	}
+	token, err := GenerateJWT(userId)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	return token
+}
+
+func TestRS256KeyRotation(t *testing.T) {
+	t.Cleanup(func() { types.SetKeySet(nil) })
+
+	_, oldPrivate, oldPublic := rsaKeyPEM(t)
+	_, newPrivate, _ := rsaKeyPEM(t)
+
+	// The private key is read from a file, as JWT_PRIVATE_KEY allows
+	oldPath := filepath.Join(t.TempDir(), "old.pem")
+	if err := os.WriteFile(oldPath, []byte(oldPrivate), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	oldToken := issue(t, &config.Config{JWTAlgorithm: "RS256", JWTKeyID: "2024", JWTPrivateKey: oldPath}, 7)
+
+	// Rotate: sign with a new key and keep the old public key for verification
+	ks, err := LoadKeys(&config.Config{
+		JWTAlgorithm:    "RS256",
+		JWTKeyID:        "2025",
+		JWTPrivateKey:   newPrivate,
+		JWTPreviousKeys: []string{"2024:" + oldPublic},
+	})
+	if err != nil {
+		t.Fatalf("LoadKeys after rotation: %v", err)
+	}
+	if ks.Algorithm() != "RS256" {
+		t.Errorf("Algorithm = %s, want RS256", ks.Algorithm())
+	}
+
+	if _, userId, err := ValidateJWT(oldToken); err != nil || userId != 7 {
+		t.Errorf("token from the previous key = %d, %v; want 7, nil", userId, err)
+	}
+	newToken, err := GenerateJWT(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, userId, err := ValidateJWT(newToken); err != nil || userId != 8 {
+		t.Errorf("token from the current key = %d, %v; want 8, nil", userId, err)
+	}
+
+	keys, _ := ks.JWKS()["keys"].([]map[string]any)
+	kids := map[any]bool{}
+	for _, key := range keys {
+		kids[key["kid"]] = true
+	}
+	if len(keys) != 2 || !kids["2024"] || !kids["2025"] {
+		t.Errorf("JWKS = %v, want keys 2024 and 2025", keys)
+	}
+
+	// Once the old key is dropped its tokens are rejected
+	issue(t, &config.Config{JWTAlgorithm: "RS256", JWTKeyID: "2025", JWTPrivateKey: newPrivate}, 9)
+	if _, _, err := ValidateJWT(oldToken); err == nil {
+		t.Error("token from a removed key was accepted")
+	}
+}
+
+func TestHS256KeyRotation(t *testing.T) {
+	t.Cleanup(func() { types.SetKeySet(nil) })
+
+	oldToken := issue(t, &config.Config{JWTKeyID: "v1", JWTSecret: "old-secret"}, 3)
+	issue(t, &config.Config{JWTKeyID: "v2", JWTSecret: "new-secret", JWTPreviousKeys: []string{"v1:old-secret"}}, 4)
+
+	if _, userId, err := ValidateJWT(oldToken); err != nil || userId != 3 {
+		t.Errorf("token from the previous secret = %d, %v; want 3, nil", userId, err)
+	}
+
+	issue(t, &config.Config{JWTKeyID: "v2", JWTSecret: "new-secret"}, 4)
+	if _, _, err := ValidateJWT(oldToken); err == nil {
+		t.Error("token from a removed secret was accepted")
+	}
+}
+
+func TestRejectsHS256SignedWithRSAPublicKey(t *testing.T) {
+	t.Cleanup(func() { types.SetKeySet(nil) })
+
+	_, private, public := rsaKeyPEM(t)
+	issue(t, &config.Config{JWTAlgorithm: "RS256", JWTKeyID: "rsa", JWTPrivateKey: private}, 1)
+
+	// An attacker signs with the public key as an HMAC secret under the RSA kid
+	forged, err := types.NewHMACKeySet("rsa", public).Sign(map[string]any{"user_id": 1, "exp": 9999999999})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ValidateJWT(forged); err == nil {
+		t.Error("HS256 token signed with the RSA public key was accepted")
+	}
+}
+
+func TestLoadKeysErrors(t *testing.T) {
+	tests := map[string]*config.Config{
+		"unknown algorithm":   {JWTAlgorithm: "ES256"},
+		"missing private key": {JWTAlgorithm: "RS256"},
+		"bad previous key":    {JWTSecret: "s", JWTPreviousKeys: []string{"no-separator"}},
+	}
+	for name, cfg := range tests {
+		if _, err := LoadKeys(cfg); err == nil {
+			t.Errorf("%s: LoadKeys succeeded", name)
+		}
+	}
+	types.SetKeySet(nil)
+}
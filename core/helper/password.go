@@ -0,0 +1,218 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"base/core/config"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrPasswordMismatch is returned when a password does not match its hash
+var ErrPasswordMismatch = errors.New("password does not match")
+
+// PasswordHasher hashes and verifies passwords.
+// Verify accepts hashes produced by any supported algorithm so stored hashes
+// keep working after PASSWORD_HASH_ALGO changes; NeedsRehash reports hashes
+// that should be replaced with one from the configured algorithm.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) error
+	NeedsRehash(hash string) bool
+}
+
+// Supported PASSWORD_HASH_ALGO values
+const (
+	HashAlgoBcrypt   = "bcrypt"
+	HashAlgoArgon2id = "argon2id"
+)
+
+// NewPasswordHasher returns the hasher for the given algorithm, defaulting to bcrypt
+func NewPasswordHasher(algo string) PasswordHasher {
+	if strings.ToLower(algo) == HashAlgoArgon2id {
+		return NewArgon2idHasher()
+	}
+	return NewBcryptHasher(bcrypt.DefaultCost)
+}
+
+// ParsePasswordHasher returns the hasher for algo, or an error when algo is
+// not a supported PASSWORD_HASH_ALGO value
+func ParsePasswordHasher(algo string) (PasswordHasher, error) {
+	switch strings.ToLower(algo) {
+	case HashAlgoBcrypt:
+		return NewBcryptHasher(bcrypt.DefaultCost), nil
+	case HashAlgoArgon2id:
+		return NewArgon2idHasher(), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q: use %s or %s", algo, HashAlgoBcrypt, HashAlgoArgon2id)
+	}
+}
+
+var (
+	hasherMu      sync.RWMutex
+	defaultHasher PasswordHasher
+)
+
+// SetPasswords installs the hasher returned by Passwords; the app sets it at
+// startup from PASSWORD_HASH_ALGO
+func SetPasswords(h PasswordHasher) {
+	hasherMu.Lock()
+	defer hasherMu.Unlock()
+	defaultHasher = h
+}
+
+// Passwords returns the hasher configured by PASSWORD_HASH_ALGO. Until
+// SetPasswords is called it is built from the environment on first use.
+func Passwords() PasswordHasher {
+	hasherMu.RLock()
+	h := defaultHasher
+	hasherMu.RUnlock()
+	if h != nil {
+		return h
+	}
+
+	hasherMu.Lock()
+	defer hasherMu.Unlock()
+	if defaultHasher == nil {
+		defaultHasher = NewPasswordHasher(config.NewConfig().PasswordHashAlgo)
+	}
+	return defaultHasher
+}
+
+// verifyAny checks a password against a hash of any supported algorithm
+func verifyAny(hash, password string) error {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		return verifyArgon2id(hash, password)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrPasswordMismatch
+		}
+		return err
+	}
+	return nil
+}
+
+// BcryptHasher hashes passwords with bcrypt
+type BcryptHasher struct {
+	Cost int
+}
+
+// NewBcryptHasher creates a new bcrypt hasher with the given cost
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{Cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h *BcryptHasher) Verify(hash, password string) error {
+	return verifyAny(hash, password)
+}
+
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < h.Cost
+}
+
+// Argon2idHasher hashes passwords with Argon2id, encoded in the PHC string format
+type Argon2idHasher struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// NewArgon2idHasher creates a new Argon2id hasher with the RFC 9106 second recommended parameters
+func NewArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 4,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *Argon2idHasher) Verify(hash, password string) error {
+	return verifyAny(hash, password)
+}
+
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	params, _, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	return params.Memory < h.Memory ||
+		params.Iterations < h.Iterations ||
+		params.Parallelism < h.Parallelism ||
+		uint32(len(key)) < h.KeyLength
+}
+
+func verifyArgon2id(hash, password string) error {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+// decodeArgon2id parses "$argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>"
+func decodeArgon2id(hash string) (*Argon2idHasher, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, errors.New("unsupported argon2id version")
+	}
+
+	params := &Argon2idHasher{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id key: %w", err)
+	}
+
+	return params, salt, key, nil
+}
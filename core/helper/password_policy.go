@@ -0,0 +1,175 @@
+package helper
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"base/core/config"
+	"base/core/types"
+)
+
+// ErrWeakPassword is returned, wrapped in a *PasswordError, when a password
+// breaks the password policy
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+// Reasons a password breaks the policy
+const (
+	PasswordTooShort      = "too_short"
+	PasswordMissingUpper  = "missing_upper"
+	PasswordMissingLower  = "missing_lower"
+	PasswordMissingDigit  = "missing_digit"
+	PasswordMissingSymbol = "missing_symbol"
+	PasswordTooCommon     = "too_common"
+)
+
+// PasswordPolicy is what registration, password resets and password changes
+// require of a new password. It is served at GET /auth/password-policy so
+// clients can check passwords before submitting them.
+type PasswordPolicy struct {
+	MinLength     int  `json:"min_length"`
+	RequireUpper  bool `json:"require_upper"`
+	RequireLower  bool `json:"require_lower"`
+	RequireDigit  bool `json:"require_digit"`
+	RequireSymbol bool `json:"require_symbol"`
+	RejectCommon  bool `json:"reject_common"`
+}
+
+// PasswordReason is one way a password breaks the policy
+type PasswordReason struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordError lists every way a password breaks the policy
+type PasswordError struct {
+	Reasons []PasswordReason
+}
+
+func (e *PasswordError) Error() string {
+	messages := make([]string, len(e.Reasons))
+	for i, reason := range e.Reasons {
+		messages[i] = reason.Message
+	}
+	return ErrWeakPassword.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *PasswordError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// ValidationErrors returns the reasons as a validation response for field,
+// one error per reason with the reason code as the rule
+func (e *PasswordError) ValidationErrors(field string) *types.ValidationErrorResponse {
+	response := &types.ValidationErrorResponse{Errors: make([]types.ValidationError, 0, len(e.Reasons))}
+	for _, reason := range e.Reasons {
+		response.Errors = append(response.Errors, types.ValidationError{
+			Field:   field,
+			Rule:    reason.Code,
+			Message: reason.Message,
+		})
+	}
+	return response
+}
+
+var (
+	passwordPolicyMu sync.RWMutex
+	passwordPolicy   = PasswordPolicy{
+		MinLength:     config.DefaultPasswordMinLength,
+		RequireUpper:  config.DefaultPasswordRequireUpper,
+		RequireLower:  config.DefaultPasswordRequireLower,
+		RequireDigit:  config.DefaultPasswordRequireDigit,
+		RequireSymbol: config.DefaultPasswordRequireSymbol,
+		RejectCommon:  config.DefaultPasswordRejectCommon,
+	}
+)
+
+// SetPasswordPolicy replaces the policy ValidatePassword enforces
+func SetPasswordPolicy(policy PasswordPolicy) {
+	passwordPolicyMu.Lock()
+	defer passwordPolicyMu.Unlock()
+	passwordPolicy = policy
+}
+
+// CurrentPasswordPolicy returns the policy ValidatePassword enforces
+func CurrentPasswordPolicy() PasswordPolicy {
+	passwordPolicyMu.RLock()
+	defer passwordPolicyMu.RUnlock()
+	return passwordPolicy
+}
+
+// ValidatePassword checks password against the current policy. It returns
+// nil, or a *PasswordError listing every rule the password breaks.
+func ValidatePassword(password string) error {
+	return CurrentPasswordPolicy().Validate(password)
+}
+
+// Validate checks password against the policy; see ValidatePassword
+func (p PasswordPolicy) Validate(password string) error {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	var reasons []PasswordReason
+	if utf8.RuneCountInString(password) < p.MinLength {
+		reasons = append(reasons, PasswordReason{PasswordTooShort, fmt.Sprintf("Password must be at least %d characters long", p.MinLength)})
+	}
+	if p.RequireUpper && !upper {
+		reasons = append(reasons, PasswordReason{PasswordMissingUpper, "Password must contain an uppercase letter"})
+	}
+	if p.RequireLower && !lower {
+		reasons = append(reasons, PasswordReason{PasswordMissingLower, "Password must contain a lowercase letter"})
+	}
+	if p.RequireDigit && !digit {
+		reasons = append(reasons, PasswordReason{PasswordMissingDigit, "Password must contain a digit"})
+	}
+	if p.RequireSymbol && !symbol {
+		reasons = append(reasons, PasswordReason{PasswordMissingSymbol, "Password must contain a symbol"})
+	}
+	if p.RejectCommon && isCommonPassword(password) {
+		reasons = append(reasons, PasswordReason{PasswordTooCommon, "Password is too common"})
+	}
+
+	if reasons == nil {
+		return nil
+	}
+	return &PasswordError{Reasons: reasons}
+}
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+var (
+	commonPasswords     map[string]bool
+	commonPasswordsOnce sync.Once
+)
+
+// isCommonPassword reports whether password, ignoring case, is in the
+// bundled list of frequently used passwords
+func isCommonPassword(password string) bool {
+	commonPasswordsOnce.Do(func() {
+		commonPasswords = make(map[string]bool)
+		scanner := bufio.NewScanner(strings.NewReader(commonPasswordList))
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				commonPasswords[strings.ToLower(line)] = true
+			}
+		}
+	})
+	return commonPasswords[strings.ToLower(password)]
+}
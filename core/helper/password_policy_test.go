@@ -0,0 +1,99 @@
+package helper
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// reasons returns the reason codes for password, nil when it passes
+func reasons(t *testing.T, policy PasswordPolicy, password string) []string {
+	t.Helper()
+	err := policy.Validate(password)
+	if err == nil {
+		return nil
+	}
+	var weak *PasswordError
+	if !errors.As(err, &weak) || !errors.Is(err, ErrWeakPassword) {
+		t.Fatalf("Validate(%q) = %v, want a *PasswordError", password, err)
+	}
+	var codes []string
+	for _, reason := range weak.Reasons {
+		if reason.Message == "" {
+			t.Errorf("reason %s has no message", reason.Code)
+		}
+		codes = append(codes, reason.Code)
+	}
+	return codes
+}
+
+func TestPasswordPolicyRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		want     []string
+	}{
+		{"min length", PasswordPolicy{MinLength: 8}, "short", []string{PasswordTooShort}},
+		{"min length counts characters", PasswordPolicy{MinLength: 4}, "äöüß", nil},
+		{"upper", PasswordPolicy{RequireUpper: true}, "lowercase", []string{PasswordMissingUpper}},
+		{"upper met", PasswordPolicy{RequireUpper: true}, "Lowercase", nil},
+		{"lower", PasswordPolicy{RequireLower: true}, "UPPERCASE", []string{PasswordMissingLower}},
+		{"lower met", PasswordPolicy{RequireLower: true}, "UPPERCASe", nil},
+		{"digit", PasswordPolicy{RequireDigit: true}, "no digits", []string{PasswordMissingDigit}},
+		{"digit met", PasswordPolicy{RequireDigit: true}, "d1git", nil},
+		{"symbol", PasswordPolicy{RequireSymbol: true}, "no symbols 123", []string{PasswordMissingSymbol}},
+		{"symbol met", PasswordPolicy{RequireSymbol: true}, "symbol!", nil},
+		{"common", PasswordPolicy{RejectCommon: true}, "123456", []string{PasswordTooCommon}},
+		{"common ignores case", PasswordPolicy{RejectCommon: true}, "PASSWORD", []string{PasswordTooCommon}},
+		{"uncommon", PasswordPolicy{RejectCommon: true}, "violet-anchor-mango", nil},
+		{"common allowed", PasswordPolicy{}, "123456", nil},
+		{
+			"every failure is reported",
+			PasswordPolicy{MinLength: 12, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true, RejectCommon: true},
+			"password",
+			[]string{PasswordTooShort, PasswordMissingUpper, PasswordMissingDigit, PasswordMissingSymbol, PasswordTooCommon},
+		},
+	}
+	for _, tt := range tests {
+		if got := reasons(t, tt.policy, tt.password); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Validate(%q) reasons = %v, want %v", tt.name, tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestValidatePasswordUsesCurrentPolicy(t *testing.T) {
+	saved := CurrentPasswordPolicy()
+	t.Cleanup(func() { SetPasswordPolicy(saved) })
+
+	if err := ValidatePassword("qwerty"); err == nil {
+		t.Error("default policy accepted a short, common password")
+	}
+	SetPasswordPolicy(PasswordPolicy{MinLength: 4, RequireDigit: true})
+	if got := CurrentPasswordPolicy(); got.MinLength != 4 || !got.RequireDigit {
+		t.Errorf("CurrentPasswordPolicy = %+v", got)
+	}
+	if err := ValidatePassword("qwerty"); err == nil {
+		t.Error("policy requiring a digit accepted qwerty")
+	}
+	if err := ValidatePassword("qwerty1"); err != nil {
+		t.Errorf("ValidatePassword(qwerty1) = %v", err)
+	}
+}
+
+func TestPasswordErrorValidationErrors(t *testing.T) {
+	err := PasswordPolicy{MinLength: 10, RequireDigit: true}.Validate("short")
+	var weak *PasswordError
+	if !errors.As(err, &weak) {
+		t.Fatalf("Validate = %v", err)
+	}
+	response := weak.ValidationErrors("new_password")
+	if len(response.Errors) != 2 {
+		t.Fatalf("errors = %+v, want one per reason", response.Errors)
+	}
+	for i, rule := range []string{PasswordTooShort, PasswordMissingDigit} {
+		if e := response.Errors[i]; e.Field != "new_password" || e.Rule != rule || e.Message == "" {
+			t.Errorf("error %d = %+v, want %s on new_password", i, e, rule)
+		}
+	}
+}
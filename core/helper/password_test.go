@@ -0,0 +1,62 @@
+package helper
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParsePasswordHasher(t *testing.T) {
+	for _, algo := range []string{"bcrypt", "argon2id", "Argon2id"} {
+		if _, err := ParsePasswordHasher(algo); err != nil {
+			t.Errorf("ParsePasswordHasher(%q): %v", algo, err)
+		}
+	}
+	for _, algo := range []string{"", "argon2", "md5"} {
+		if _, err := ParsePasswordHasher(algo); err == nil {
+			t.Errorf("ParsePasswordHasher(%q) accepted an unsupported algorithm", algo)
+		}
+	}
+}
+
+func TestHashersVerifyEachOther(t *testing.T) {
+	bcryptHasher, _ := ParsePasswordHasher(HashAlgoBcrypt)
+	argonHasher, _ := ParsePasswordHasher(HashAlgoArgon2id)
+
+	hash, err := bcryptHasher.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	// Hashes from the previous algorithm keep working and are flagged for rehashing
+	if err := argonHasher.Verify(hash, "correct horse battery staple"); err != nil {
+		t.Errorf("argon2id hasher rejected a bcrypt hash: %v", err)
+	}
+	if !argonHasher.NeedsRehash(hash) {
+		t.Error("bcrypt hash not flagged for rehash under argon2id")
+	}
+	if err := argonHasher.Verify(hash, "wrong"); !errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("Verify(wrong) = %v, want ErrPasswordMismatch", err)
+	}
+
+	hash, err = argonHasher.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		t.Errorf("argon2id hash = %q", hash)
+	}
+	if argonHasher.NeedsRehash(hash) {
+		t.Error("fresh argon2id hash flagged for rehash")
+	}
+}
+
+func TestSetPasswords(t *testing.T) {
+	hasher, _ := ParsePasswordHasher(HashAlgoArgon2id)
+	SetPasswords(hasher)
+	t.Cleanup(func() { SetPasswords(nil) })
+
+	if Passwords() != hasher {
+		t.Error("Passwords did not return the installed hasher")
+	}
+}
@@ -0,0 +1,222 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"base/core/logger"
+
+	"gorm.io/gorm"
+)
+
+// DatabaseQueue persists jobs in the jobs table so they survive restarts.
+// Workers claim due jobs by setting locked_at, and jobs that fail their last
+// attempt are moved to the failed_jobs table.
+type DatabaseQueue struct {
+	db     *gorm.DB
+	config Config
+	logger logger.Logger
+
+	mu      sync.Mutex
+	stopped bool
+	quit    chan struct{}
+	workers sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewDatabaseQueue creates a database-backed queue
+func NewDatabaseQueue(db *gorm.DB, config Config, log logger.Logger) *DatabaseQueue {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultConfig().PollInterval
+	}
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = DefaultConfig().LockTimeout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DatabaseQueue{
+		db:     db,
+		config: config,
+		logger: log,
+		quit:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Enqueue inserts a job that is due immediately
+func (q *DatabaseQueue) Enqueue(ctx context.Context, name string, payload any) error {
+	q.mu.Lock()
+	stopped := q.stopped
+	q.mu.Unlock()
+	if stopped {
+		return ErrQueueStopped
+	}
+
+	job, err := newJob(name, payload, q.config.MaxAttempts)
+	if err != nil {
+		return err
+	}
+	if err := q.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to enqueue job %s: %w", name, err)
+	}
+	return nil
+}
+
+// Start releases locks left behind by a crashed process and launches
+// Concurrency workers
+func (q *DatabaseQueue) Start(ctx context.Context) error {
+	stale := time.Now().Add(-q.config.LockTimeout)
+	result := q.db.WithContext(ctx).Model(&Job{}).
+		Where("locked_at < ?", stale).
+		Update("locked_at", nil)
+	if result.Error != nil {
+		return fmt.Errorf("failed to release stale jobs: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		q.logger.Warn("Released stale job locks", logger.Int64("jobs", result.RowsAffected))
+	}
+
+	for range max(q.config.Concurrency, 1) {
+		q.workers.Add(1)
+		go q.work()
+	}
+	q.logger.Info("Job queue started",
+		logger.String("driver", "database"),
+		logger.Int("concurrency", q.config.Concurrency))
+	return nil
+}
+
+// Stop stops claiming jobs and waits for running ones. Jobs still running
+// when ctx is done are canceled and stay locked until LockTimeout passes.
+func (q *DatabaseQueue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if q.stopped {
+		q.mu.Unlock()
+		return nil
+	}
+	q.stopped = true
+	close(q.quit)
+	q.mu.Unlock()
+
+	err := waitGroup(ctx, &q.workers)
+	q.cancel()
+	return err
+}
+
+func (q *DatabaseQueue) work() {
+	defer q.workers.Done()
+	ticker := time.NewTicker(q.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.quit:
+			return
+		default:
+		}
+
+		job, err := q.claim()
+		if err != nil {
+			q.logger.Error("Failed to claim job", logger.String("error", err.Error()))
+		}
+		if job != nil {
+			q.process(job)
+			continue
+		}
+
+		select {
+		case <-ticker.C:
+		case <-q.quit:
+			return
+		}
+	}
+}
+
+// claim locks the next due job, returning nil when none is due. Jobs locked
+// longer than LockTimeout are claimed again, since the worker that held them
+// is gone. The conditional update makes sure only one worker wins a job;
+// matching the attempts read keeps a worker from claiming a job that another
+// worker ran and released for a retry in the meantime.
+func (q *DatabaseQueue) claim() (*Job, error) {
+	for range 3 {
+		var due []Job
+		now := time.Now()
+		stale := now.Add(-q.config.LockTimeout)
+		err := q.db.Where("(locked_at IS NULL OR locked_at < ?) AND run_at <= ?", stale, now).
+			Order("run_at, id").
+			Limit(1).
+			Find(&due).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(due) == 0 {
+			return nil, nil
+		}
+		job := due[0]
+
+		result := q.db.Model(&Job{}).
+			Where("id = ? AND (locked_at IS NULL OR locked_at < ?) AND attempts = ?", job.Id, stale, job.Attempts).
+			Updates(map[string]any{
+				"locked_at": now,
+				"attempts":  gorm.Expr("attempts + 1"),
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			job.LockedAt = &now
+			job.Attempts++
+			return &job, nil
+		}
+		// Another worker claimed it first; try the next one
+	}
+	return nil, nil
+}
+
+func (q *DatabaseQueue) process(job *Job) {
+	runErr := run(q.ctx, job)
+	if runErr == nil {
+		jobsProcessed.Inc(job.Name, "succeeded")
+		if err := q.db.Delete(&Job{}, job.Id).Error; err != nil {
+			q.logger.Error("Failed to delete finished job",
+				logger.String("job", job.Name),
+				logger.String("error", err.Error()))
+		}
+		return
+	}
+
+	final := job.Attempts >= job.MaxAttempts
+	logFailure(q.logger, job, runErr, final)
+
+	var err error
+	if final {
+		err = q.db.Transaction(func(tx *gorm.DB) error {
+			failed := FailedJob{
+				JobId:    job.Id,
+				Name:     job.Name,
+				Payload:  job.Payload,
+				Attempts: job.Attempts,
+				Error:    runErr.Error(),
+			}
+			if err := tx.Create(&failed).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&Job{}, job.Id).Error
+		})
+	} else {
+		err = q.db.Model(&Job{}).Where("id = ?", job.Id).Updates(map[string]any{
+			"locked_at":  nil,
+			"run_at":     time.Now().Add(q.config.backoff(job.Attempts)),
+			"last_error": runErr.Error(),
+		}).Error
+	}
+	if err != nil {
+		q.logger.Error("Failed to record job failure",
+			logger.String("job", job.Name),
+			logger.String("error", err.Error()))
+	}
+}
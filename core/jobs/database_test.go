@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"base/core/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestQueue(t *testing.T) *DatabaseQueue {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "jobs.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&Job{}, &FailedJob{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	config := DefaultConfig()
+	config.LockTimeout = time.Minute
+	return NewDatabaseQueue(db, config, logger.NewLoggerFromZap(zap.NewNop()))
+}
+
+func insertJob(t *testing.T, q *DatabaseQueue, lockedAt *time.Time) *Job {
+	t.Helper()
+	job, err := newJob("test", map[string]string{}, 5)
+	if err != nil {
+		t.Fatalf("newJob: %v", err)
+	}
+	job.RunAt = time.Now().Add(-time.Second)
+	job.LockedAt = lockedAt
+	if lockedAt != nil {
+		job.Attempts = 1
+	}
+	if err := q.db.Create(job).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return job
+}
+
+func TestClaimSkipsLockedJobs(t *testing.T) {
+	q := newTestQueue(t)
+	recent := time.Now().Add(-10 * time.Second)
+	insertJob(t, q, &recent)
+
+	job, err := q.claim()
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if job != nil {
+		t.Fatalf("claimed job %d locked %s ago", job.Id, time.Since(recent))
+	}
+}
+
+func TestClaimReclaimsStaleLocks(t *testing.T) {
+	q := newTestQueue(t)
+	stale := time.Now().Add(-2 * time.Minute)
+	want := insertJob(t, q, &stale)
+
+	job, err := q.claim()
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if job == nil || job.Id != want.Id {
+		t.Fatalf("claim = %v, want job %d", job, want.Id)
+	}
+	if job.Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", job.Attempts)
+	}
+
+	// The fresh lock keeps other workers off it
+	again, err := q.claim()
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if again != nil {
+		t.Errorf("job %d claimed twice", again.Id)
+	}
+}
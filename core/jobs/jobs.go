@@ -0,0 +1,192 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"base/core/logger"
+	"base/core/metrics"
+)
+
+var (
+	// ErrNoQueue is returned by Enqueue before a default queue is set
+	ErrNoQueue = errors.New("jobs: no queue configured")
+
+	// ErrQueueStopped is returned when enqueueing on a stopped queue
+	ErrQueueStopped = errors.New("jobs: queue stopped")
+)
+
+var (
+	jobsProcessed = metrics.Default.NewCounterVec("jobs_processed_total",
+		"Background jobs processed by name and outcome (succeeded, retried, failed).", "name", "status")
+	jobsDuration = metrics.Default.NewHistogramVec("jobs_duration_seconds",
+		"Background job run time in seconds by name.", nil, "name")
+)
+
+// Handler processes a job. Returning an error retries the job with backoff
+// until it has been attempted MaxAttempts times.
+type Handler func(ctx context.Context, job *Job) error
+
+// Queue runs registered handlers for enqueued jobs on a pool of workers
+type Queue interface {
+	// Enqueue stores a job for name with payload encoded as JSON
+	Enqueue(ctx context.Context, name string, payload any) error
+
+	// Start launches the workers
+	Start(ctx context.Context) error
+
+	// Stop stops taking new jobs and waits for running ones until ctx is done
+	Stop(ctx context.Context) error
+}
+
+// Config contains worker pool configuration
+type Config struct {
+	// Concurrency is the number of jobs processed at once
+	Concurrency int
+
+	// MaxAttempts is how often a job runs before it is given up on
+	MaxAttempts int
+
+	// PollInterval is how often idle database workers look for due jobs
+	PollInterval time.Duration
+
+	// BaseBackoff is the delay before the first retry; it doubles per attempt
+	BaseBackoff time.Duration
+
+	// MaxBackoff caps the retry delay
+	MaxBackoff time.Duration
+
+	// LockTimeout is how long a claimed job may run before another worker may
+	// claim it again, e.g. after the process crashed mid-job
+	LockTimeout time.Duration
+}
+
+// DefaultConfig returns default worker pool configuration
+func DefaultConfig() Config {
+	return Config{
+		Concurrency:  4,
+		MaxAttempts:  5,
+		PollInterval: time.Second,
+		BaseBackoff:  5 * time.Second,
+		MaxBackoff:   time.Hour,
+		LockTimeout:  15 * time.Minute,
+	}
+}
+
+// backoff returns the delay before retrying a job that failed attempt times
+func (c Config) backoff(attempt int) time.Duration {
+	delay := c.BaseBackoff
+	for i := 1; i < attempt && delay < c.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, c.MaxBackoff)
+}
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = map[string]Handler{}
+
+	defaultMu    sync.RWMutex
+	defaultQueue Queue
+)
+
+// Register sets the handler for jobs named name, replacing any previous one
+func Register(name string, handler Handler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	handlers[name] = handler
+}
+
+// SetDefault sets the queue used by Enqueue
+func SetDefault(q Queue) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultQueue = q
+}
+
+// Default returns the queue used by Enqueue, or nil if none is set
+func Default() Queue {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultQueue
+}
+
+// Enqueue adds a job to the default queue
+func Enqueue(name string, payload any) error {
+	q := Default()
+	if q == nil {
+		return ErrNoQueue
+	}
+	return q.Enqueue(context.Background(), name, payload)
+}
+
+// newJob builds an unsaved job with payload encoded as JSON
+func newJob(name string, payload any, maxAttempts int) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload for job %s: %w", name, err)
+	}
+	return &Job{
+		Name:        name,
+		Payload:     string(data),
+		MaxAttempts: maxAttempts,
+		RunAt:       time.Now(),
+	}, nil
+}
+
+// run executes the handler registered for job, turning panics into errors
+func run(ctx context.Context, job *Job) (err error) {
+	handlersMu.RLock()
+	handler, ok := handlers[job.Name]
+	handlersMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no handler registered for job %s", job.Name)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job %s panicked: %v", job.Name, r)
+		}
+	}()
+
+	start := time.Now()
+	defer func() { jobsDuration.Observe(time.Since(start).Seconds(), job.Name) }()
+	return handler(ctx, job)
+}
+
+// logFailure records a failed attempt; final is true once the job is given up on
+func logFailure(log logger.Logger, job *Job, err error, final bool) {
+	if final {
+		jobsProcessed.Inc(job.Name, "failed")
+		log.Error("Job failed permanently",
+			logger.String("job", job.Name),
+			logger.Int("attempts", job.Attempts),
+			logger.String("error", err.Error()))
+		return
+	}
+	jobsProcessed.Inc(job.Name, "retried")
+	log.Warn("Job failed, will retry",
+		logger.String("job", job.Name),
+		logger.Int("attempt", job.Attempts),
+		logger.String("error", err.Error()))
+}
+
+// waitGroup waits for wg until ctx is done
+func waitGroup(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,146 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"base/core/logger"
+)
+
+// memoryBuffer is how many jobs a memory queue holds before Enqueue blocks
+const memoryBuffer = 1024
+
+// MemoryQueue runs jobs on in-process workers. Jobs are lost if the process
+// exits, so it suits development and work that is cheap to lose.
+type MemoryQueue struct {
+	config Config
+	logger logger.Logger
+	jobs   chan *Job
+
+	mu      sync.Mutex
+	stopped bool
+	quit    chan struct{}
+	workers sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewMemoryQueue creates an in-memory queue
+func NewMemoryQueue(config Config, log logger.Logger) *MemoryQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &MemoryQueue{
+		config: config,
+		logger: log,
+		jobs:   make(chan *Job, memoryBuffer),
+		quit:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Enqueue buffers a job, blocking while the buffer is full
+func (q *MemoryQueue) Enqueue(ctx context.Context, name string, payload any) error {
+	job, err := newJob(name, payload, q.config.MaxAttempts)
+	if err != nil {
+		return err
+	}
+	return q.push(ctx, job)
+}
+
+func (q *MemoryQueue) push(ctx context.Context, job *Job) error {
+	q.mu.Lock()
+	stopped := q.stopped
+	q.mu.Unlock()
+	if stopped {
+		return ErrQueueStopped
+	}
+
+	select {
+	case q.jobs <- job:
+		return nil
+	case <-q.quit:
+		return ErrQueueStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Start launches Concurrency workers
+func (q *MemoryQueue) Start(ctx context.Context) error {
+	for range max(q.config.Concurrency, 1) {
+		q.workers.Add(1)
+		go q.work()
+	}
+	q.logger.Info("Job queue started",
+		logger.String("driver", "memory"),
+		logger.Int("concurrency", q.config.Concurrency))
+	return nil
+}
+
+// Stop rejects new jobs and drains the buffer. Jobs still buffered or
+// waiting to retry when ctx is done are dropped.
+func (q *MemoryQueue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if q.stopped {
+		q.mu.Unlock()
+		return nil
+	}
+	q.stopped = true
+	close(q.quit)
+	q.mu.Unlock()
+
+	err := waitGroup(ctx, &q.workers)
+	q.cancel()
+	if err != nil {
+		q.logger.Warn("Job queue stopped before draining",
+			logger.Int("dropped", len(q.jobs)))
+	}
+	return err
+}
+
+func (q *MemoryQueue) work() {
+	defer q.workers.Done()
+	for {
+		select {
+		case job := <-q.jobs:
+			q.process(job)
+		case <-q.quit:
+			// Drain what is already buffered, then exit
+			for {
+				select {
+				case job := <-q.jobs:
+					q.process(job)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (q *MemoryQueue) process(job *Job) {
+	job.Attempts++
+	err := run(q.ctx, job)
+	if err == nil {
+		jobsProcessed.Inc(job.Name, "succeeded")
+		return
+	}
+
+	job.LastError = err.Error()
+	final := job.Attempts >= job.MaxAttempts
+	logFailure(q.logger, job, err, final)
+	if final {
+		return
+	}
+
+	delay := q.config.backoff(job.Attempts)
+	job.RunAt = time.Now().Add(delay)
+	time.AfterFunc(delay, func() {
+		if err := q.push(q.ctx, job); err != nil {
+			q.logger.Warn("Dropped job retry",
+				logger.String("job", job.Name),
+				logger.String("error", err.Error()))
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job is a unit of background work. The database queue persists it in the
+// jobs table until it succeeds or runs out of attempts.
+type Job struct {
+	Id          uint       `gorm:"column:id;primary_key;auto_increment"`
+	Name        string     `gorm:"column:name;not null;size:255;index"`
+	Payload     string     `gorm:"column:payload;type:text;not null"`
+	Attempts    int        `gorm:"column:attempts;not null;default:0"`
+	MaxAttempts int        `gorm:"column:max_attempts;not null"`
+	RunAt       time.Time  `gorm:"column:run_at;not null;index"`
+	LockedAt    *time.Time `gorm:"column:locked_at;index"`
+	LastError   string     `gorm:"column:last_error;type:text"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// Decode unmarshals the job payload into v
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal([]byte(j.Payload), v)
+}
+
+// FailedJob is a dead letter: a job that failed on its last attempt
+type FailedJob struct {
+	Id        uint      `gorm:"column:id;primary_key;auto_increment"`
+	JobId     uint      `gorm:"column:job_id;not null"`
+	Name      string    `gorm:"column:name;not null;size:255;index"`
+	Payload   string    `gorm:"column:payload;type:text;not null"`
+	Attempts  int       `gorm:"column:attempts;not null"`
+	Error     string    `gorm:"column:error;type:text"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (FailedJob) TableName() string {
+	return "failed_jobs"
+}
@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+
+	"base/core/config"
+	"base/core/logger"
+	"base/core/module"
+
+	"gorm.io/gorm"
+)
+
+// Module runs the background job queue and makes it the default queue
+type Module struct {
+	module.DefaultModule
+	DB     *gorm.DB
+	Queue  Queue
+	Logger logger.Logger
+	Driver string
+}
+
+// NewJobsModule creates the jobs module. JOBS_DRIVER selects the database
+// queue (the default) or the in-memory queue.
+func NewJobsModule(db *gorm.DB, log logger.Logger, cfg *config.Config) module.Module {
+	queueConfig := DefaultConfig()
+	queueConfig.Concurrency = cfg.JobsConcurrency
+	queueConfig.MaxAttempts = cfg.JobsMaxAttempts
+	queueConfig.PollInterval = cfg.JobsPollInterval
+
+	var queue Queue
+	switch cfg.JobsDriver {
+	case "memory":
+		queue = NewMemoryQueue(queueConfig, log)
+	default:
+		queue = NewDatabaseQueue(db, queueConfig, log)
+	}
+	SetDefault(queue)
+
+	return &Module{
+		DB:     db,
+		Queue:  queue,
+		Logger: log,
+		Driver: cfg.JobsDriver,
+	}
+}
+
+func (m *Module) Migrate() error {
+	if m.Driver == "memory" {
+		return nil
+	}
+	return m.DB.AutoMigrate(&Job{}, &FailedJob{})
+}
+
+func (m *Module) GetModels() []any {
+	return []any{
+		&Job{},
+		&FailedJob{},
+	}
+}
+
+// Start launches the queue workers
+func (m *Module) Start(ctx context.Context) error {
+	return m.Queue.Start(ctx)
+}
+
+// Stop drains the queue
+func (m *Module) Stop(ctx context.Context) error {
+	return m.Queue.Stop(ctx)
+}
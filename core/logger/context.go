@@ -0,0 +1,22 @@
+package logger
+
+import "context"
+
+type contextKey struct{}
+
+// IntoContext returns a copy of ctx carrying l, typically a request-scoped
+// child created with With
+func IntoContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored by IntoContext, or the default
+// logger when ctx carries none
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+			return l
+		}
+	}
+	return GetLogger()
+}
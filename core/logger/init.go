@@ -34,6 +34,11 @@ func Initialize(env string) error {
 	return nil
 }
 
+// SetDefault replaces the default logger, e.g. with the application logger
+func SetDefault(l Logger) {
+	defaultLogger = l
+}
+
 // GetLogger returns the default logger instance
 func GetLogger() Logger {
 	if defaultLogger == nil {
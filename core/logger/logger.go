@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // Logger is our custom logger interface that wraps the actual logging implementation
@@ -29,11 +31,59 @@ type Config struct {
 	Environment string // "development" or "production"
 	LogPath     string // Path to log directory
 	Level       string // "debug", "info", "warn", "error", "fatal"
+
+	// Format is the stdout encoding, "console" (colored) or "json". It
+	// defaults to json in production and console otherwise. The log file is
+	// always JSON.
+	Format string
+
+	// Outputs lists the sinks, "stdout" and/or "file". It defaults to stdout
+	// only in production, for log collectors, and to both otherwise.
+	Outputs []string
+
+	// File rotation: megabytes before the file is rotated, and the days and
+	// number of rotated files to keep. Zero keeps rotated files forever.
+	MaxSize    int
+	MaxAge     int
+	MaxBackups int
+
+	// Sampling logs the first SamplingInitial entries with the same level and
+	// message each second, then every SamplingThereafter-th. Zero disables it.
+	// Warnings and errors are never sampled.
+	SamplingInitial    int
+	SamplingThereafter int
+
+	// RedactKeys are masked in every entry along with the built-in
+	// sensitive names; see Redact
+	RedactKeys []string
+}
+
+// withDefaults fills in the environment dependent format and outputs. Any
+// environment other than production gets the development defaults.
+func (c Config) withDefaults() Config {
+	production := c.Environment == "production"
+	if c.Format == "" {
+		c.Format = "console"
+		if production {
+			c.Format = "json"
+		}
+	}
+	if len(c.Outputs) == 0 {
+		c.Outputs = []string{"stdout"}
+		if !production {
+			c.Outputs = append(c.Outputs, "file")
+		}
+	}
+	if c.LogPath == "" {
+		c.LogPath = "logs"
+	}
+	return c
 }
 
 // ZapLogger implements the Logger interface using zap
 type ZapLogger struct {
 	logger *zap.Logger
+	level  *zap.AtomicLevel // nil for loggers wrapping an existing zap.Logger
 }
 
 // timeEncoder encodes the time as RFC3339Nano
@@ -43,6 +93,9 @@ func timeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 
 // NewLogger creates a new logger based on the configuration
 func NewLogger(config Config) (Logger, error) {
+	config = config.withDefaults()
+	AddRedactedKeys(config.RedactKeys...)
+
 	var cfg zap.Config
 
 	// Set default level if not specified
@@ -58,25 +111,68 @@ func NewLogger(config Config) (Logger, error) {
 	}
 
 	cfg.EncoderConfig.EncodeTime = timeEncoder
+	jsonEncoder := zapcore.NewJSONEncoder(cfg.EncoderConfig)
+
+	type sink struct {
+		encoder zapcore.Encoder
+		writer  zapcore.WriteSyncer
+	}
+	var sinks []sink
+	for _, output := range config.Outputs {
+		switch strings.TrimSpace(output) {
+		case "stdout":
+			encoder := jsonEncoder
+			if config.Format == "console" {
+				encoder = consoleEncoder()
+			}
+			sinks = append(sinks, sink{encoder, zapcore.AddSync(os.Stdout)})
+		case "file":
+			// Create log directory if it doesn't exist
+			if err := os.MkdirAll(config.LogPath, 0755); err != nil {
+				return nil, fmt.Errorf("can't create log directory: %w", err)
+			}
+			file := &lumberjack.Logger{
+				Filename:   filepath.Join(config.LogPath, "app.log"),
+				MaxSize:    config.MaxSize,
+				MaxAge:     config.MaxAge,
+				MaxBackups: config.MaxBackups,
+			}
+			sinks = append(sinks, sink{jsonEncoder, zapcore.AddSync(file)})
+		default:
+			return nil, fmt.Errorf("unknown log output: %s", output)
+		}
+	}
 
-	// Create log directory if it doesn't exist
-	if err := os.MkdirAll(config.LogPath, 0755); err != nil {
-		return nil, fmt.Errorf("can't create log directory: %w", err)
+	tee := func(enabler zapcore.LevelEnabler) zapcore.Core {
+		cores := make([]zapcore.Core, len(sinks))
+		for i, s := range sinks {
+			cores[i] = zapcore.NewCore(s.encoder, s.writer, enabler)
+		}
+		return zapcore.NewTee(cores...)
 	}
 
-	// Set up log file
-	logFile := filepath.Join(config.LogPath, "app.log")
-	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return nil, fmt.Errorf("can't open log file: %w", err)
+	core := tee(level)
+	if config.SamplingInitial > 0 && config.SamplingThereafter > 0 {
+		// Only entries below warn go through the sampler
+		sampled := tee(zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l < zapcore.WarnLevel && level.Enabled(l)
+		}))
+		unsampled := tee(zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l >= zapcore.WarnLevel && level.Enabled(l)
+		}))
+		core = zapcore.NewTee(
+			zapcore.NewSamplerWithOptions(sampled, time.Second, config.SamplingInitial, config.SamplingThereafter),
+			unsampled,
+		)
 	}
+	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 
-	// Create logger with file and console output
-	encoder := zapcore.NewJSONEncoder(cfg.EncoderConfig)
+	return &ZapLogger{logger: logger, level: &level}, nil
+}
 
-	// Custom console encoder config
+// consoleEncoder returns the colored encoder used for console output
+func consoleEncoder() zapcore.Encoder {
 	consoleConfig := zap.NewDevelopmentEncoderConfig()
-	consoleConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	consoleConfig.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 		// Add blue color to timestamp
 		enc.AppendString(fmt.Sprintf("\033[36m%s\033[0m", t.Format("2006-01-02 15:04:05")))
@@ -102,24 +198,7 @@ func NewLogger(config Config) (Logger, error) {
 		}
 	}
 	consoleConfig.ConsoleSeparator = "  "
-	consoleEncoder := zapcore.NewConsoleEncoder(consoleConfig)
-
-	// Create multi-writer core
-	core := zapcore.NewTee(
-		zapcore.NewCore(
-			encoder,
-			zapcore.AddSync(f),
-			level,
-		),
-		zapcore.NewCore(
-			consoleEncoder,
-			zapcore.AddSync(os.Stdout),
-			level,
-		),
-	)
-	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
-
-	return &ZapLogger{logger: logger}, nil
+	return zapcore.NewConsoleEncoder(consoleConfig)
 }
 
 // NewLoggerFromZap creates a new Logger from an existing zap.Logger
@@ -177,25 +256,34 @@ func Duration(key string, value time.Duration) Field {
 
 // Logger interface implementation
 func (l *ZapLogger) Info(msg string, fields ...Field) {
-	l.logger.Info(msg, fields...)
+	l.logger.Info(msg, Redact(fields)...)
 }
 
 func (l *ZapLogger) Error(msg string, fields ...Field) {
-	l.logger.Error(msg, fields...)
+	l.logger.Error(msg, Redact(fields)...)
 }
 
 func (l *ZapLogger) Debug(msg string, fields ...Field) {
-	l.logger.Debug(msg, fields...)
+	l.logger.Debug(msg, Redact(fields)...)
 }
 
 func (l *ZapLogger) Warn(msg string, fields ...Field) {
-	l.logger.Warn(msg, fields...)
+	l.logger.Warn(msg, Redact(fields)...)
 }
 
 func (l *ZapLogger) Fatal(msg string, fields ...Field) {
-	l.logger.Fatal(msg, fields...)
+	l.logger.Fatal(msg, Redact(fields)...)
 }
 
 func (l *ZapLogger) With(fields ...Field) Logger {
-	return &ZapLogger{logger: l.logger.With(fields...)}
+	return &ZapLogger{logger: l.logger.With(Redact(fields)...), level: l.level}
+}
+
+// SetLevel changes the minimum level ("debug", "info", "warn", "error",
+// "fatal") of the logger and every logger derived from it with With
+func (l *ZapLogger) SetLevel(level string) error {
+	if l.level == nil {
+		return fmt.Errorf("logger level can't be changed")
+	}
+	return l.level.UnmarshalText([]byte(level))
 }
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// logRepeated writes n identical entries at info and at warn and returns how
+// many of each reached the log file
+func logRepeated(t *testing.T, config Config, n int) (infos, warns int) {
+	t.Helper()
+	config.LogPath = t.TempDir()
+	config.Outputs = []string{"file"}
+	config.Level = "debug"
+
+	l, err := NewLogger(config)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	for range n {
+		l.Info("repeated info")
+		l.Warn("repeated warning")
+	}
+
+	data, err := os.ReadFile(filepath.Join(config.LogPath, "app.log"))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	return strings.Count(string(data), "repeated info"), strings.Count(string(data), "repeated warning")
+}
+
+func TestSamplingOffByDefault(t *testing.T) {
+	infos, warns := logRepeated(t, Config{}, 50)
+	if infos != 50 || warns != 50 {
+		t.Errorf("logged %d infos and %d warnings, want 50 of each", infos, warns)
+	}
+}
+
+func TestSamplingSparesWarnings(t *testing.T) {
+	infos, warns := logRepeated(t, Config{SamplingInitial: 5, SamplingThereafter: 100}, 50)
+	if infos != 5 {
+		t.Errorf("logged %d sampled infos, want 5", infos)
+	}
+	if warns != 50 {
+		t.Errorf("logged %d warnings, want all 50", warns)
+	}
+}
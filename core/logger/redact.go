@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces the values of sensitive fields in logs
+const Redacted = "[REDACTED]"
+
+// defaultRedactedKeys are the field, header and query parameter names whose
+// values never reach the logs
+var defaultRedactedKeys = []string{
+	"authorization", "proxy-authorization", "x-api-key", "cookie", "set-cookie",
+	"password", "new_password", "current_password", "old_password", "password_confirmation",
+	"token", "access_token", "refresh_token", "api_key", "secret", "client_secret",
+}
+
+var (
+	redactedMu   sync.RWMutex
+	redactedKeys = keySet(defaultRedactedKeys)
+)
+
+// AddRedactedKeys masks more field, header or query parameter names, on top
+// of the defaults. Names match case-insensitively.
+func AddRedactedKeys(keys ...string) {
+	redactedMu.Lock()
+	defer redactedMu.Unlock()
+	for _, key := range keys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			redactedKeys[key] = true
+		}
+	}
+}
+
+// IsRedacted reports whether values under key are masked
+func IsRedacted(key string) bool {
+	redactedMu.RLock()
+	defer redactedMu.RUnlock()
+	return redactedKeys[strings.ToLower(key)]
+}
+
+// Redact masks sensitive fields: those named like a redacted key, and
+// redacted keys inside map values such as headers or decoded JSON bodies.
+// The loggers from NewLogger apply it to every entry; the fields passed in
+// are left unchanged.
+func Redact(fields []Field) []Field {
+	var out []Field
+	for i, field := range fields {
+		redacted, ok := redactField(field)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = append(make([]Field, 0, len(fields)), fields...)
+		}
+		out[i] = redacted
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// RedactQuery masks the values of redacted parameters in a raw query string,
+// keeping the parameter order
+func RedactQuery(rawQuery string) string {
+	parts := strings.Split(rawQuery, "&")
+	changed := false
+	for i, part := range parts {
+		name, _, _ := strings.Cut(part, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if IsRedacted(name) {
+			parts[i] = url.QueryEscape(name) + "=" + Redacted
+			changed = true
+		}
+	}
+	if !changed {
+		return rawQuery
+	}
+	return strings.Join(parts, "&")
+}
+
+// redactField returns the masked field, or false when it has nothing to mask
+func redactField(field Field) (Field, bool) {
+	if IsRedacted(field.Key) {
+		return String(field.Key, Redacted), true
+	}
+	if field.Type != zapcore.ReflectType {
+		return field, false
+	}
+	if value, ok := redactValue(field.Interface); ok {
+		return Any(field.Key, value), true
+	}
+	return field, false
+}
+
+// redactValue copies maps holding redacted keys, at any depth, with those
+// values masked
+func redactValue(value any) (any, bool) {
+	switch v := value.(type) {
+	case http.Header:
+		return redactMap(v, func(any) []string { return []string{Redacted} })
+	case map[string][]string:
+		return redactMap(v, func(any) []string { return []string{Redacted} })
+	case map[string]string:
+		return redactMap(v, func(any) string { return Redacted })
+	case map[string]any:
+		return redactMap(v, func(any) any { return Redacted })
+	case []any:
+		var out []any
+		for i, item := range v {
+			redacted, ok := redactValue(item)
+			if !ok {
+				continue
+			}
+			if out == nil {
+				out = append([]any(nil), v...)
+			}
+			out[i] = redacted
+		}
+		return out, out != nil
+	}
+	return value, false
+}
+
+func redactMap[M ~map[string]V, V any](m M, mask func(any) V) (M, bool) {
+	var out M
+	for key, value := range m {
+		var replacement V
+		if IsRedacted(key) {
+			replacement = mask(value)
+		} else if nested, ok := redactValue(value); ok {
+			replacement = nested.(V)
+		} else {
+			continue
+		}
+		if out == nil {
+			out = make(M, len(m))
+			for k, v := range m {
+				out[k] = v
+			}
+		}
+		out[key] = replacement
+	}
+	return out, out != nil
+}
+
+func keySet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return set
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedact(t *testing.T) {
+	body := map[string]any{
+		"email":    "ada@example.com",
+		"password": "hunter2",
+		"profile":  map[string]any{"name": "Ada", "api_key": "k"},
+		"sessions": []any{map[string]any{"Token": "t"}},
+	}
+	header := http.Header{"Authorization": {"Bearer abc"}, "Accept": {"*/*"}}
+	fields := []Field{
+		String("Password", "hunter2"),
+		String("email", "ada@example.com"),
+		Any("body", body),
+		Any("headers", header),
+	}
+
+	got := Redact(fields)
+	want := []Field{
+		String("Password", Redacted),
+		String("email", "ada@example.com"),
+		Any("body", map[string]any{
+			"email":    "ada@example.com",
+			"password": Redacted,
+			"profile":  map[string]any{"name": "Ada", "api_key": Redacted},
+			"sessions": []any{map[string]any{"Token": Redacted}},
+		}),
+		Any("headers", http.Header{"Authorization": {Redacted}, "Accept": {"*/*"}}),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Redact = %+v\nwant %+v", got, want)
+	}
+
+	// The caller's fields and maps are left alone
+	if fields[0].String != "hunter2" || body["password"] != "hunter2" || header.Get("Authorization") != "Bearer abc" {
+		t.Error("Redact modified its input")
+	}
+	clean := []Field{String("email", "ada@example.com"), Int("status", 200)}
+	if got := Redact(clean); &got[0] != &clean[0] {
+		t.Error("Redact copied fields with nothing to mask")
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	tests := map[string]string{
+		"page=2&sort=name":             "page=2&sort=name",
+		"token=abc&page=2":             "token=" + Redacted + "&page=2",
+		"email=a%40b.c&Access_Token=x": "email=a%40b.c&Access_Token=" + Redacted,
+		"new%5Fpassword=x":             "new_password=" + Redacted,
+		"":                             "",
+	}
+	for query, want := range tests {
+		if got := RedactQuery(query); got != want {
+			t.Errorf("RedactQuery(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestAddRedactedKeys(t *testing.T) {
+	if IsRedacted("x-tenant-secret") {
+		t.Fatal("x-tenant-secret redacted before it was added")
+	}
+	AddRedactedKeys(" X-Tenant-Secret ", "")
+	if !IsRedacted("x-tenant-secret") || !IsRedacted("X-TENANT-SECRET") {
+		t.Error("added key isn't redacted")
+	}
+	if IsRedacted("") {
+		t.Error("empty key redacted")
+	}
+}
+
+func TestLoggerMasksPasswords(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := NewLoggerFromZap(zap.New(core))
+
+	log.Info("login attempt", String("email", "ada@example.com"), String("password", "hunter2"))
+	log.With(Any("body", map[string]any{"new_password": "hunter3"})).Warn("password change")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("logged %d entries, want 2", len(entries))
+	}
+	if got := entries[0].ContextMap(); got["password"] != Redacted || got["email"] != "ada@example.com" {
+		t.Errorf("login entry = %v", got)
+	}
+	body, _ := entries[1].ContextMap()["body"].(map[string]any)
+	if body["new_password"] != Redacted {
+		t.Errorf("password change entry body = %v", body)
+	}
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"gorm.io/gorm"
+)
+
+var dbQueries = Default.NewCounterVec("db_queries_total",
+	"Database queries by operation and outcome.", "operation", "status")
+
+// InstrumentDB counts every query run through db by operation (create, query,
+// update, delete, row, raw) and whether it failed
+func InstrumentDB(db *gorm.DB) error {
+	record := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			status := "ok"
+			if tx.Error != nil && tx.Error != gorm.ErrRecordNotFound {
+				status = "error"
+			}
+			dbQueries.Inc(operation, status)
+		}
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().After("gorm:create").Register("metrics:create", record("create")),
+		callbacks.Query().After("gorm:query").Register("metrics:query", record("query")),
+		callbacks.Update().After("gorm:update").Register("metrics:update", record("update")),
+		callbacks.Delete().After("gorm:delete").Register("metrics:delete", record("delete")),
+		callbacks.Row().After("gorm:row").Register("metrics:row", record("row")),
+		callbacks.Raw().After("gorm:raw").Register("metrics:raw", record("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
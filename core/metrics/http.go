@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"base/core/router"
+)
+
+var (
+	httpRequests = Default.NewCounterVec("http_requests_total",
+		"Total HTTP requests by method, route and status.", "method", "route", "status")
+	httpDuration = Default.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency in seconds by method, route and status.", nil, "method", "route", "status")
+	httpInFlight = Default.NewGaugeVec("http_requests_in_flight",
+		"HTTP requests currently being served by method and route.", "method", "route")
+)
+
+// HTTPCollector records request metrics into the default registry. It
+// implements middleware.MetricsCollector and middleware.InFlightCollector.
+type HTTPCollector struct{}
+
+// RequestStarted marks a request as in flight
+func (HTTPCollector) RequestStarted(method, route string) {
+	httpInFlight.Add(1, method, route)
+}
+
+// RecordRequest records a completed request
+func (HTTPCollector) RecordRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpInFlight.Add(-1, method, route)
+	httpRequests.Inc(method, route, code)
+	httpDuration.Observe(duration.Seconds(), method, route, code)
+}
+
+// Handler serves the default registry in the Prometheus text format
+func Handler() router.HandlerFunc {
+	return func(c *router.Context) error {
+		c.SetHeader("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Writer.WriteHeader(http.StatusOK)
+		Default.Write(c.Writer)
+		return nil
+	}
+}
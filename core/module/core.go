@@ -2,9 +2,6 @@ package module
 
 import (
 	"fmt"
-
-	"base/core/logger"
-	"base/core/router"
 )
 
 // CoreModuleProvider defines the interface for providing core modules
@@ -38,56 +35,12 @@ func (co *CoreOrchestrator) InitializeCoreModules(deps Dependencies) ([]Module,
 		return []Module{}, nil
 	}
 
-	// Initialize them using a custom core initializer that handles auth routing
-	initializedModules := co.initializeCoreModules(modules, deps)
+	// Initialize them in dependency order using the generic initializer
+	initializedModules, err := co.initializer.initialize(modules, deps, KindCore)
+	if err != nil {
+		return nil, err
+	}
 
 	deps.Logger.Info(fmt.Sprintf("✅ Core modules initialization complete (%d modules)", len(initializedModules)))
 	return initializedModules, nil
 }
-
-// initializeCoreModules initializes core modules with special handling for auth modules
-func (co *CoreOrchestrator) initializeCoreModules(modules map[string]Module, deps Dependencies) []Module {
-	var initializedModules []Module
-
-	for name, mod := range modules {
-		deps.Logger.Info("Initializing core module", logger.String("module", name))
-
-		// Register module
-		if err := RegisterModule(name, mod); err != nil {
-			deps.Logger.Error("Failed to register core module",
-				logger.String("module", name),
-				logger.String("error", err.Error()))
-			continue
-		}
-
-		// Initialize
-		if initModule, ok := mod.(interface{ Init() error }); ok {
-			if err := initModule.Init(); err != nil {
-				deps.Logger.Error("Failed to initialize core module",
-					logger.String("module", name),
-					logger.String("error", err.Error()))
-				continue
-			}
-		}
-
-		// Migrate
-		if migrator, ok := mod.(interface{ Migrate() error }); ok {
-			if err := migrator.Migrate(); err != nil {
-				deps.Logger.Error("Failed to migrate core module",
-					logger.String("module", name),
-					logger.String("error", err.Error()))
-				continue
-			}
-		}
-
-		// Setup routes
-		if routeModule, ok := mod.(interface{ Routes(*router.RouterGroup) }); ok {
-			routeModule.Routes(deps.Router)
-		}
-
-		initializedModules = append(initializedModules, mod)
-		deps.Logger.Info("Core module initialized successfully", logger.String("module", name))
-	}
-
-	return initializedModules
-}
@@ -1,11 +1,16 @@
 package module
 
 import (
+	"maps"
+	"slices"
+
+	"base/core/cache"
 	"base/core/config"
 	"base/core/email"
 	"base/core/emitter"
 	"base/core/logger"
 	"base/core/router"
+	"base/core/sse"
 	"base/core/storage"
 
 	"gorm.io/gorm"
@@ -20,6 +25,8 @@ type Dependencies struct {
 	Storage     *storage.ActiveStorage
 	EmailSender email.Sender
 	Config      *config.Config
+	Cache       cache.Store // shared cache from CACHE_DRIVER
+	Events      *sse.Broker // server-sent event streams at GET /api/events
 }
 
 // Initializer handles module initialization logic
@@ -34,18 +41,56 @@ func NewInitializer(logger logger.Logger) *Initializer {
 	}
 }
 
-// Initialize initializes a map of modules with dependencies
-func (mi *Initializer) Initialize(modules map[string]Module, deps Dependencies) []Module {
+// Initialize initializes a map of modules with dependencies. Modules are
+// processed in dependency order; a module whose dependency failed is skipped.
+// It returns an error without initializing anything when the dependency
+// graph has a cycle or names an unknown module. Every module's outcome is
+// recorded as an app module in Statuses.
+func (mi *Initializer) Initialize(modules map[string]Module, deps Dependencies) ([]Module, error) {
+	return mi.initialize(modules, deps, KindApp)
+}
+
+func (mi *Initializer) initialize(modules map[string]Module, deps Dependencies, kind string) ([]Module, error) {
+	order, err := SortByDependencies(modules)
+	if err != nil {
+		mi.logger.Error("Failed to order modules", logger.String("error", err.Error()))
+		for _, name := range slices.Sorted(maps.Keys(modules)) {
+			status := newStatus(name, kind, modules[name])
+			status.State = StateFailed
+			status.Error = err.Error()
+			recordStatus(status)
+		}
+		return nil, err
+	}
+
 	var initializedModules []Module
+	failed := make(map[string]bool)
 
-	for name, mod := range modules {
+	for _, name := range order {
+		mod := modules[name]
+		status := newStatus(name, kind, mod)
 		mi.logger.Info("Initializing module", logger.String("module", name))
 
+		if dep, ok := failedDependency(mod, failed); ok {
+			mi.logger.Error("Skipping module with failed dependency",
+				logger.String("module", name),
+				logger.String("dependency", dep))
+			failed[name] = true
+			status.State = StateSkipped
+			status.Error = "dependency " + dep + " failed"
+			recordStatus(status)
+			continue
+		}
+
 		// Register module
 		if err := RegisterModule(name, mod); err != nil {
 			mi.logger.Error("Failed to register module",
 				logger.String("module", name),
 				logger.String("error", err.Error()))
+			failed[name] = true
+			status.State = StateFailed
+			status.Error = err.Error()
+			recordStatus(status)
 			continue
 		}
 
@@ -55,6 +100,10 @@ func (mi *Initializer) Initialize(modules map[string]Module, deps Dependencies)
 				mi.logger.Error("Failed to initialize module",
 					logger.String("module", name),
 					logger.String("error", err.Error()))
+				failed[name] = true
+				status.State = StateFailed
+				status.Error = err.Error()
+				recordStatus(status)
 				continue
 			}
 		}
@@ -65,18 +114,30 @@ func (mi *Initializer) Initialize(modules map[string]Module, deps Dependencies)
 				mi.logger.Error("Failed to migrate module",
 					logger.String("module", name),
 					logger.String("error", err.Error()))
+				failed[name] = true
+				status.State = StateFailed
+				status.Migration = MigrationFailed
+				status.Error = err.Error()
+				recordStatus(status)
 				continue
 			}
+			status.Migration = MigrationDone
 		}
 
-		// Setup routes
+		// Setup routes, noting the ones the module adds
 		if routeModule, ok := mod.(interface{ Routes(*router.RouterGroup) }); ok {
+			before := len(deps.Router.Router().Routes())
 			routeModule.Routes(deps.Router)
+			added := deps.Router.Router().Routes()[before:]
+			status.Routes = len(added)
+			status.RoutePrefix = routePrefix(added)
 		}
 
 		initializedModules = append(initializedModules, mod)
+		status.State = StateInitialized
+		recordStatus(status)
 		mi.logger.Info("Module initialized successfully", logger.String("module", name))
 	}
 
-	return initializedModules
+	return initializedModules, nil
 }
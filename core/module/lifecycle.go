@@ -0,0 +1,142 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"base/core/logger"
+)
+
+// Starter is implemented by modules that run work once every module has been
+// initialized, migrated and routed, e.g. a background worker that needs the DB
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper is implemented by modules that release resources on shutdown
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// Dependent is implemented by modules that must be initialized after others.
+// Names refer to the keys modules are registered under, e.g. "users".
+type Dependent interface {
+	DependsOn() []string
+}
+
+// SortByDependencies orders module names so each module follows the modules
+// it depends on. Dependencies may also name modules registered earlier, such
+// as core modules an app module builds on. Names are otherwise sorted
+// alphabetically so startup order is stable between runs.
+func SortByDependencies(modules map[string]Module) ([]string, error) {
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(modules))
+	order := make([]string, 0, len(modules))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("module dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		}
+		state[name] = visiting
+
+		if dependent, ok := modules[name].(Dependent); ok {
+			deps := slices.Clone(dependent.DependsOn())
+			slices.Sort(deps)
+			for _, dep := range deps {
+				if _, ok := modules[dep]; !ok {
+					if _, err := GetModule(dep); err != nil {
+						return fmt.Errorf("module %s depends on unknown module %s", name, dep)
+					}
+					continue
+				}
+				if err := visit(dep, append(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+
+		state[name] = done
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// failedDependency returns the first dependency of mod that didn't initialize
+func failedDependency(mod Module, failed map[string]bool) (string, bool) {
+	dependent, ok := mod.(Dependent)
+	if !ok {
+		return "", false
+	}
+	for _, dep := range dependent.DependsOn() {
+		if failed[dep] {
+			return dep, true
+		}
+	}
+	return "", false
+}
+
+// StartModules calls Start on each module that implements Starter, in order.
+// It stops at the first error and returns the modules that started, which
+// should be passed to StopModules on shutdown.
+func StartModules(ctx context.Context, modules []Module, log logger.Logger) ([]Module, error) {
+	started := make([]Module, 0, len(modules))
+	for _, mod := range modules {
+		starter, ok := mod.(Starter)
+		if !ok {
+			started = append(started, mod)
+			continue
+		}
+		if err := starter.Start(ctx); err != nil {
+			log.Error("Failed to start module",
+				logger.String("module", fmt.Sprintf("%T", mod)),
+				logger.String("error", err.Error()))
+			return started, fmt.Errorf("failed to start %T: %w", mod, err)
+		}
+		started = append(started, mod)
+	}
+	return started, nil
+}
+
+// StopModules calls Stop on each module that implements Stopper in reverse
+// order, so modules stop before the modules they depend on. Every module is
+// stopped even if an earlier one fails; the errors are joined.
+func StopModules(ctx context.Context, modules []Module, log logger.Logger) error {
+	var errs []error
+	for _, mod := range slices.Backward(modules) {
+		stopper, ok := mod.(Stopper)
+		if !ok {
+			continue
+		}
+		if err := stopper.Stop(ctx); err != nil {
+			log.Error("Failed to stop module",
+				logger.String("module", fmt.Sprintf("%T", mod)),
+				logger.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("failed to stop %T: %w", mod, err))
+		}
+	}
+	return errors.Join(errs...)
+}
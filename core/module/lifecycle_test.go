@@ -0,0 +1,135 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"base/core/logger"
+
+	"go.uber.org/zap"
+)
+
+// fakeModule records its lifecycle calls in a shared log
+type fakeModule struct {
+	name    string
+	deps    []string
+	initErr error
+	stopErr error
+	log     *[]string
+}
+
+func (m *fakeModule) Init() error {
+	*m.log = append(*m.log, "init "+m.name)
+	return m.initErr
+}
+
+func (m *fakeModule) Migrate() error      { return nil }
+func (m *fakeModule) GetModels() []any    { return nil }
+func (m *fakeModule) DependsOn() []string { return m.deps }
+
+func (m *fakeModule) Start(ctx context.Context) error {
+	*m.log = append(*m.log, "start "+m.name)
+	return nil
+}
+
+func (m *fakeModule) Stop(ctx context.Context) error {
+	*m.log = append(*m.log, "stop "+m.name)
+	return m.stopErr
+}
+
+// graph builds modules from name -> dependencies, unregistering them when
+// the test ends
+func graph(t *testing.T, log *[]string, edges map[string][]string) map[string]Module {
+	t.Helper()
+	modules := make(map[string]Module, len(edges))
+	for name, deps := range edges {
+		modules[name] = &fakeModule{name: name, deps: deps, log: log}
+	}
+	t.Cleanup(func() {
+		lock.Lock()
+		defer lock.Unlock()
+		for name := range modules {
+			delete(modulesRegistry, name)
+		}
+	})
+	return modules
+}
+
+func TestSortByDependencies(t *testing.T) {
+	modules := graph(t, new([]string), map[string][]string{
+		"api":   {"users", "media"},
+		"media": {"users"},
+		"users": nil,
+		"audit": nil,
+	})
+
+	order, err := SortByDependencies(modules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"users", "media", "api", "audit"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
+
+func TestSortByDependenciesErrors(t *testing.T) {
+	cycle := graph(t, new([]string), map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"a"},
+	})
+	if _, err := SortByDependencies(cycle); err == nil || !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Errorf("cycle: err = %v", err)
+	}
+
+	unknown := graph(t, new([]string), map[string][]string{"a": {"missing"}})
+	if _, err := SortByDependencies(unknown); err == nil || !strings.Contains(err.Error(), "unknown module missing") {
+		t.Errorf("unknown dependency: err = %v", err)
+	}
+}
+
+func TestInitializeSkipsDependentsOfFailedModules(t *testing.T) {
+	var log []string
+	modules := graph(t, &log, map[string][]string{
+		"lifecycle_users":  nil,
+		"lifecycle_media":  {"lifecycle_users"},
+		"lifecycle_search": nil,
+	})
+	modules["lifecycle_users"].(*fakeModule).initErr = errors.New("boom")
+
+	initialized, err := NewInitializer(logger.NewLoggerFromZap(zap.NewNop())).Initialize(modules, Dependencies{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(initialized) != 1 || initialized[0] != modules["lifecycle_search"] {
+		t.Errorf("initialized %v, want only lifecycle_search", initialized)
+	}
+	if want := []string{"init lifecycle_users", "init lifecycle_search"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("calls = %v, want %v", log, want)
+	}
+}
+
+func TestStartStopModules(t *testing.T) {
+	var log []string
+	modules := graph(t, &log, map[string][]string{"users": nil, "media": {"users"}})
+	ordered := []Module{modules["users"], modules["media"]}
+	modules["media"].(*fakeModule).stopErr = errors.New("busy")
+	nop := logger.NewLoggerFromZap(zap.NewNop())
+
+	started, err := StartModules(context.Background(), ordered, nop)
+	if err != nil || len(started) != 2 {
+		t.Fatalf("StartModules = %d, %v", len(started), err)
+	}
+
+	// Dependents stop first, and one failing doesn't keep the rest running
+	err = StopModules(context.Background(), started, nop)
+	if err == nil || !strings.Contains(err.Error(), "busy") {
+		t.Errorf("StopModules err = %v, want the media failure", err)
+	}
+	if want := []string{"start users", "start media", "stop media", "stop users"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("calls = %v, want %v", log, want)
+	}
+}
@@ -0,0 +1,154 @@
+package module
+
+import (
+	"strings"
+	"sync"
+
+	"base/core/router"
+)
+
+// Module kinds in a Status
+const (
+	KindCore = "core"
+	KindApp  = "app"
+)
+
+// Module states in a Status
+const (
+	// StateInitialized modules were initialized, migrated and routed
+	StateInitialized = "initialized"
+	// StateFailed modules returned an error from registration, Init or Migrate
+	StateFailed = "failed"
+	// StateSkipped modules depend on a module that failed
+	StateSkipped = "skipped"
+)
+
+// Migration states in a Status
+const (
+	MigrationDone   = "migrated"
+	MigrationFailed = "failed"
+	MigrationNotRun = "not_run"
+)
+
+// Versioned is implemented by modules that report their own version
+type Versioned interface {
+	Version() string
+}
+
+// Optional is implemented by app modules the app can run without. With
+// STRICT_MODULE_INIT, a failed core module or required app module aborts
+// startup, while an optional one is only logged.
+type Optional interface {
+	Optional() bool
+}
+
+// Status is what happened to a module at startup, as listed by
+// GET /system/modules
+type Status struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Version     string `json:"version,omitempty"`
+	Optional    bool   `json:"optional,omitempty"`
+	State       string `json:"state"`
+	Migration   string `json:"migration"`
+	RoutePrefix string `json:"route_prefix,omitempty"`
+	Routes      int    `json:"routes"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Summary counts the modules processed at startup by kind and state
+type Summary struct {
+	Total       int `json:"total"`
+	Core        int `json:"core"`
+	App         int `json:"app"`
+	Initialized int `json:"initialized"`
+	Failed      int `json:"failed"`
+	Skipped     int `json:"skipped"`
+}
+
+var (
+	statusMu sync.RWMutex
+	statuses []Status
+)
+
+// Statuses returns the status of every module the initializers processed,
+// in the order they were processed
+func Statuses() []Status {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	return append([]Status(nil), statuses...)
+}
+
+// Summarize counts statuses by kind and state
+func Summarize(statuses []Status) Summary {
+	summary := Summary{Total: len(statuses)}
+	for _, status := range statuses {
+		switch status.Kind {
+		case KindCore:
+			summary.Core++
+		case KindApp:
+			summary.App++
+		}
+		switch status.State {
+		case StateInitialized:
+			summary.Initialized++
+		case StateFailed:
+			summary.Failed++
+		case StateSkipped:
+			summary.Skipped++
+		}
+	}
+	return summary
+}
+
+// Required reports whether startup must abort in strict mode when the
+// module isn't running: core modules always, app modules unless optional
+func (s Status) Required() bool {
+	return s.Kind == KindCore || !s.Optional
+}
+
+func recordStatus(status Status) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	statuses = append(statuses, status)
+}
+
+// newStatus starts the status of a module that hasn't been processed yet
+func newStatus(name, kind string, mod Module) Status {
+	status := Status{Name: name, Kind: kind, Migration: MigrationNotRun}
+	if versioned, ok := mod.(Versioned); ok {
+		status.Version = versioned.Version()
+	}
+	if optional, ok := mod.(Optional); ok {
+		status.Optional = optional.Optional()
+	}
+	return status
+}
+
+// routePrefix returns the longest path, in whole segments, that the routes
+// share
+func routePrefix(routes []router.Route) string {
+	if len(routes) == 0 {
+		return ""
+	}
+	prefix := strings.Split(routes[0].Path, "/")
+	for _, route := range routes[1:] {
+		segments := strings.Split(route.Path, "/")
+		n := 0
+		for n < len(prefix) && n < len(segments) && prefix[n] == segments[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	// A parameter or wildcard is not part of the prefix
+	for i, segment := range prefix {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			prefix = prefix[:i]
+			break
+		}
+	}
+	if joined := strings.Join(prefix, "/"); joined != "" {
+		return joined
+	}
+	return "/"
+}
@@ -0,0 +1,108 @@
+package module
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+)
+
+// statusModule is a fakeModule with a version, routes under /api/<name>
+// and an optional migration failure
+type statusModule struct {
+	fakeModule
+	migrateErr error
+	optional   bool
+}
+
+func (m *statusModule) Version() string { return "1.2.0" }
+func (m *statusModule) Optional() bool  { return m.optional }
+func (m *statusModule) Migrate() error  { return m.migrateErr }
+func (m *statusModule) Routes(r *router.RouterGroup) {
+	handler := func(c *router.Context) error { return c.NoContent() }
+	r.GET("/api/"+m.name, handler)
+	r.GET("/api/"+m.name+"/:id", handler)
+}
+
+func TestInitializeRecordsStatuses(t *testing.T) {
+	var log []string
+	modules := graph(t, &log, map[string][]string{
+		"status_users":  nil,
+		"status_media":  {"status_users"},
+		"status_search": nil,
+		"status_audit":  nil,
+	})
+	for name, mod := range modules {
+		modules[name] = &statusModule{fakeModule: *mod.(*fakeModule)}
+	}
+	modules["status_users"].(*statusModule).migrateErr = errors.New("no table")
+	modules["status_audit"].(*statusModule).initErr = errors.New("boom")
+	modules["status_audit"].(*statusModule).optional = true
+
+	before := len(Statuses())
+	deps := Dependencies{Router: router.New().Group("")}
+	if _, err := NewInitializer(logger.NewLoggerFromZap(zap.NewNop())).initialize(modules, deps, KindCore); err != nil {
+		t.Fatal(err)
+	}
+
+	got := Statuses()[before:]
+	want := []Status{
+		{Name: "status_audit", Kind: KindCore, Version: "1.2.0", Optional: true, State: StateFailed, Migration: MigrationNotRun, Error: "boom"},
+		{Name: "status_users", Kind: KindCore, Version: "1.2.0", State: StateFailed, Migration: MigrationFailed, Error: "no table"},
+		{Name: "status_media", Kind: KindCore, Version: "1.2.0", State: StateSkipped, Migration: MigrationNotRun, Error: "dependency status_users failed"},
+		{Name: "status_search", Kind: KindCore, Version: "1.2.0", State: StateInitialized, Migration: MigrationDone, RoutePrefix: "/api/status_search", Routes: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("statuses =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if summary := Summarize(got); summary != (Summary{Total: 4, Core: 4, Initialized: 1, Failed: 2, Skipped: 1}) {
+		t.Errorf("summary = %+v", summary)
+	}
+	// Core modules are required even when they say they're optional
+	if !got[0].Required() || !(Status{Kind: KindApp}).Required() || (Status{Kind: KindApp, Optional: true}).Required() {
+		t.Error("Required doesn't follow the kind and Optional")
+	}
+}
+
+func TestInitializeRecordsOrderingFailures(t *testing.T) {
+	modules := graph(t, new([]string), map[string][]string{"status_a": {"status_b"}, "status_b": {"status_a"}})
+	before := len(Statuses())
+	if _, err := NewInitializer(logger.NewLoggerFromZap(zap.NewNop())).Initialize(modules, Dependencies{}); err == nil {
+		t.Fatal("Initialize accepted a cycle")
+	}
+	got := Statuses()[before:]
+	if len(got) != 2 || got[0].Name != "status_a" || got[1].State != StateFailed || got[1].Kind != KindApp || got[1].Error == "" {
+		t.Errorf("statuses = %+v, want both app modules failed", got)
+	}
+}
+
+func TestRoutePrefix(t *testing.T) {
+	routes := func(paths ...string) []router.Route {
+		var out []router.Route
+		for _, path := range paths {
+			out = append(out, router.Route{Method: http.MethodGet, Path: path})
+		}
+		return out
+	}
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"/api/media", "/api/media/:id"}, "/api/media"},
+		{[]string{"/api/media", "/api/mediafiles"}, "/api"},
+		{[]string{"/api/:org/items", "/api/:org/users"}, "/api"},
+		{[]string{"/users", "/media"}, "/"},
+	}
+	for _, tt := range tests {
+		if got := routePrefix(routes(tt.paths...)); got != tt.want {
+			t.Errorf("routePrefix(%v) = %q, want %q", tt.paths, got, tt.want)
+		}
+	}
+}
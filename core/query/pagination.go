@@ -0,0 +1,83 @@
+package query
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+)
+
+// Pagination defaults; SetLimits overrides them from PAGINATION_DEFAULT_LIMIT
+// and PAGINATION_MAX_LIMIT
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	defaultLimit = DefaultLimit
+	maxLimit     = MaxLimit
+)
+
+// SetLimits sets the limit used when a request sends none and the largest
+// one it may ask for
+func SetLimits(defaultPerPage, maxPerPage int) {
+	if maxPerPage > 0 {
+		maxLimit = maxPerPage
+	}
+	if defaultPerPage > 0 {
+		defaultLimit = min(defaultPerPage, maxLimit)
+	}
+}
+
+// Pagination is a request's page and page size
+type Pagination struct {
+	Page  int
+	Limit int
+}
+
+// Offset is the number of rows before the page
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// PaginationError reports a page or limit parameter that isn't a positive
+// integer. It wraps ErrInvalidQuery.
+type PaginationError struct {
+	Param   string
+	Message string
+}
+
+func (e *PaginationError) Error() string {
+	return ErrInvalidQuery.Error() + ": " + e.Message
+}
+
+func (e *PaginationError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+// ParsePagination reads ?page= and ?limit=, defaulting to the first page of
+// the default limit. Limits above the maximum are lowered to it; values that
+// aren't positive integers return a *PaginationError.
+func ParsePagination(values url.Values) (Pagination, error) {
+	p := Pagination{Page: 1, Limit: defaultLimit}
+
+	if page := values.Get("page"); page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			return p, &PaginationError{Param: "page", Message: "page must be a positive integer"}
+		}
+		// Keeps Offset from overflowing
+		if n > math.MaxInt32 {
+			return p, &PaginationError{Param: "page", Message: "page is too large"}
+		}
+		p.Page = n
+	}
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return p, &PaginationError{Param: "limit", Message: "limit must be a positive integer"}
+		}
+		p.Limit = min(n, maxLimit)
+	}
+	return p, nil
+}
@@ -0,0 +1,80 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		query  string
+		page   int
+		limit  int
+		offset int
+	}{
+		{"", 1, DefaultLimit, 0},
+		{"page=3", 3, DefaultLimit, 2 * DefaultLimit},
+		{"page=2&limit=5", 2, 5, 5},
+		{"limit=1000000", 1, MaxLimit, 0},
+		{"limit=100", 1, 100, 0},
+	}
+	for _, tt := range tests {
+		values, _ := url.ParseQuery(tt.query)
+		p, err := ParsePagination(values)
+		if err != nil {
+			t.Errorf("%q: %v", tt.query, err)
+			continue
+		}
+		if p.Page != tt.page || p.Limit != tt.limit || p.Offset() != tt.offset {
+			t.Errorf("%q = page %d limit %d offset %d, want %d %d %d",
+				tt.query, p.Page, p.Limit, p.Offset(), tt.page, tt.limit, tt.offset)
+		}
+	}
+}
+
+func TestParsePaginationRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		query string
+		param string
+	}{
+		{"page=-1", "page"},
+		{"page=0", "page"},
+		{"page=two", "page"},
+		{"page=99999999999", "page"},
+		{"limit=0", "limit"},
+		{"limit=-5", "limit"},
+		{"limit=1.5", "limit"},
+	}
+	for _, tt := range tests {
+		values, _ := url.ParseQuery(tt.query)
+		_, err := ParsePagination(values)
+		var perr *PaginationError
+		if !errors.As(err, &perr) || perr.Param != tt.param {
+			t.Errorf("%q = %v, want a PaginationError for %s", tt.query, err, tt.param)
+		}
+		if !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%q = %v, want it to wrap ErrInvalidQuery", tt.query, err)
+		}
+	}
+}
+
+func TestSetLimits(t *testing.T) {
+	t.Cleanup(func() { SetLimits(DefaultLimit, MaxLimit) })
+
+	SetLimits(10, 50)
+	p, _ := ParsePagination(url.Values{})
+	if p.Limit != 10 {
+		t.Errorf("default limit = %d, want 10", p.Limit)
+	}
+	p, _ = ParsePagination(url.Values{"limit": {"75"}})
+	if p.Limit != 50 {
+		t.Errorf("limit=75 = %d, want the maximum 50", p.Limit)
+	}
+
+	// The default never exceeds the maximum, and zero keeps the current values
+	SetLimits(200, 0)
+	if p, _ := ParsePagination(url.Values{}); p.Limit != 50 {
+		t.Errorf("default limit = %d, want it capped at 50", p.Limit)
+	}
+}
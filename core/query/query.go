@@ -0,0 +1,309 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+
+	"base/core/search"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidQuery is wrapped by every Parse error; list endpoints answer it
+// with 400
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Values of trashed= on models with soft delete: "only" lists deleted rows,
+// "with" lists them alongside the others
+const (
+	TrashedOnly = "only"
+	TrashedWith = "with"
+)
+
+// Operators supported in filter[field][op]=value. "in" takes a comma
+// separated list and "null" takes true or false.
+var Operators = []string{"eq", "ne", "gt", "gte", "lt", "lte", "in", "like", "null"}
+
+// Filterable is implemented by models that allow filter[...] on these columns
+type Filterable interface {
+	FilterableFields() []string
+}
+
+// Sortable is implemented by models that allow sort= on these columns
+type Sortable interface {
+	SortableFields() []string
+}
+
+// Selectable is implemented by models that allow fields= on these columns
+type Selectable interface {
+	SelectableFields() []string
+}
+
+// Filter is one filter[field][op]=value condition
+type Filter struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+// Sort orders by Field, descending when the sort= entry had a "-" prefix
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Params holds the list parameters of a request, checked against a model's
+// allowlists
+type Params struct {
+	Page    int
+	Limit   int
+	Search  string
+	Filters []Filter
+	Sorts   []Sort
+	Fields  []string
+	Trashed string
+
+	model any
+}
+
+// Parse reads the standard list parameters for model:
+//
+//	?page=2&limit=20&q=text&filter[status]=active&filter[age][gte]=18&sort=-created_at,name&fields=id,name&trashed=only
+//
+// Fields missing from the model's allowlists, unknown operators and
+// malformed values return an error wrapping ErrInvalidQuery rather than
+// being ignored.
+func Parse(values url.Values, model any) (*Params, error) {
+	pagination, err := ParsePagination(values)
+	if err != nil {
+		return nil, err
+	}
+	params := &Params{
+		Page:   pagination.Page,
+		Limit:  pagination.Limit,
+		Search: values.Get(search.Param),
+		model:  model,
+	}
+
+	if trashed := values.Get("trashed"); trashed != "" {
+		if trashed != TrashedOnly && trashed != TrashedWith {
+			return nil, invalid("trashed must be only or with")
+		}
+		if !softDeletes(model) {
+			return nil, invalid("records of this type can't be trashed")
+		}
+		params.Trashed = trashed
+	}
+
+	if err := params.parseFilters(values); err != nil {
+		return nil, err
+	}
+	if err := params.parseSorts(values.Get("sort")); err != nil {
+		return nil, err
+	}
+	if err := params.parseFields(values.Get("fields")); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func (p *Params) parseFilters(values url.Values) error {
+	var allowed []string
+	if filterable, ok := p.model.(Filterable); ok {
+		allowed = filterable.FilterableFields()
+	}
+
+	// Sorted keys keep the generated SQL stable
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		field, operator, ok := parseFilterKey(key)
+		if !ok {
+			return invalid("malformed filter " + key)
+		}
+		if !slices.Contains(allowed, field) {
+			return invalid("cannot filter by " + field)
+		}
+		if !slices.Contains(Operators, operator) {
+			return invalid("unknown filter operator " + operator)
+		}
+
+		value := values.Get(key)
+		if operator == "null" && value != "true" && value != "false" {
+			return invalid("filter[" + field + "][null] must be true or false")
+		}
+		p.Filters = append(p.Filters, Filter{Field: field, Operator: operator, Value: value})
+	}
+	return nil
+}
+
+// parseFilterKey splits filter[field] and filter[field][op], defaulting to eq
+func parseFilterKey(key string) (field, operator string, ok bool) {
+	rest := strings.TrimPrefix(key, "filter[")
+	field, rest, ok = strings.Cut(rest, "]")
+	if !ok || field == "" {
+		return "", "", false
+	}
+	if rest == "" {
+		return field, "eq", true
+	}
+	if !strings.HasPrefix(rest, "[") || !strings.HasSuffix(rest, "]") {
+		return "", "", false
+	}
+	return field, rest[1 : len(rest)-1], true
+}
+
+func (p *Params) parseSorts(sort string) error {
+	if sort == "" {
+		return nil
+	}
+
+	var allowed []string
+	if sortable, ok := p.model.(Sortable); ok {
+		allowed = sortable.SortableFields()
+	}
+
+	for _, entry := range strings.Split(sort, ",") {
+		entry = strings.TrimSpace(entry)
+		desc := strings.HasPrefix(entry, "-")
+		field := strings.TrimPrefix(entry, "-")
+		if !slices.Contains(allowed, field) {
+			return invalid("cannot sort by " + field)
+		}
+		p.Sorts = append(p.Sorts, Sort{Field: field, Desc: desc})
+	}
+	return nil
+}
+
+func (p *Params) parseFields(fields string) error {
+	if fields == "" {
+		return nil
+	}
+
+	var allowed []string
+	if selectable, ok := p.model.(Selectable); ok {
+		allowed = selectable.SelectableFields()
+	}
+
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if !slices.Contains(allowed, field) {
+			return invalid("cannot select " + field)
+		}
+		p.Fields = append(p.Fields, field)
+	}
+	return nil
+}
+
+// Where applies the trashed option, search and filters only, for counting the
+// matching rows
+func (p *Params) Where() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch p.Trashed {
+		case TrashedWith:
+			db = db.Unscoped()
+		case TrashedOnly:
+			db = db.Unscoped().Where(clause.Expr{SQL: "? IS NOT NULL", Vars: []any{clause.Column{Table: clause.CurrentTable, Name: "deleted_at"}}})
+		}
+
+		db = db.Scopes(search.ScopeFor(p.model, p.Search, search.Options{}))
+		for _, filter := range p.Filters {
+			db = db.Where(filter.expression())
+		}
+		return db
+	}
+}
+
+// Scope applies the search, filters, sorts and field selection. Values are
+// always bound as parameters and columns come from the model's allowlists.
+func (p *Params) Scope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Scopes(p.Where())
+		for _, sort := range p.Sorts {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: sort.Field}, Desc: sort.Desc})
+		}
+		if len(p.Fields) > 0 {
+			db = db.Select(p.Fields)
+		}
+		return db
+	}
+}
+
+// Paginate applies Page and Limit; a zero Limit returns every row
+func (p *Params) Paginate() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if p.Limit <= 0 {
+			return db
+		}
+		return db.Offset(Pagination{Page: p.Page, Limit: p.Limit}.Offset()).Limit(p.Limit)
+	}
+}
+
+func (f Filter) expression() clause.Expression {
+	column := clause.Column{Name: f.Field}
+	switch f.Operator {
+	case "ne":
+		return clause.Neq{Column: column, Value: f.Value}
+	case "gt":
+		return clause.Gt{Column: column, Value: f.Value}
+	case "gte":
+		return clause.Gte{Column: column, Value: f.Value}
+	case "lt":
+		return clause.Lt{Column: column, Value: f.Value}
+	case "lte":
+		return clause.Lte{Column: column, Value: f.Value}
+	case "in":
+		values := make([]any, 0)
+		for _, value := range strings.Split(f.Value, ",") {
+			values = append(values, strings.TrimSpace(value))
+		}
+		return clause.IN{Column: column, Values: values}
+	case "like":
+		return clause.Expr{SQL: "? LIKE ? ESCAPE '!'", Vars: []any{column, "%" + search.EscapeLike(f.Value) + "%"}}
+	case "null":
+		if f.Value == "true" {
+			return clause.Expr{SQL: "? IS NULL", Vars: []any{column}}
+		}
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []any{column}}
+	default:
+		return clause.Eq{Column: column, Value: f.Value}
+	}
+}
+
+// softDeletes reports whether model has a gorm.DeletedAt field
+func softDeletes(model any) bool {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type == reflect.TypeOf(gorm.DeletedAt{}) {
+			return true
+		}
+		// Embedded structs such as gorm.Model
+		if field.Anonymous && softDeletes(reflect.New(field.Type).Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+func invalid(msg string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidQuery, msg)
+}
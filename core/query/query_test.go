@@ -0,0 +1,178 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type person struct {
+	Id        uint `gorm:"primaryKey"`
+	Name      string
+	Age       int
+	Team      string
+	Nickname  *string
+	DeletedAt gorm.DeletedAt
+}
+
+func (*person) FilterableFields() []string { return []string{"name", "age", "team", "nickname"} }
+func (*person) SortableFields() []string   { return []string{"id", "name", "age", "team"} }
+func (*person) SelectableFields() []string { return []string{"id", "name"} }
+
+// people returns a database with five people; Eve is soft deleted
+func people(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "query.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&person{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	nick := "bobby"
+	rows := []person{
+		{Name: "Ada", Age: 36, Team: "red"},
+		{Name: "Bob", Age: 25, Team: "blue", Nickname: &nick},
+		{Name: "Cy_1", Age: 25, Team: "red"},
+		{Name: "Dee 100%", Age: 41, Team: "blue"},
+		{Name: "Eve", Age: 30, Team: "red"},
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	db.Delete(&rows[4])
+	return db
+}
+
+// names runs rawQuery against people and returns the matching names in order
+func names(t *testing.T, db *gorm.DB, rawQuery string) []string {
+	t.Helper()
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := Parse(values, &person{})
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", rawQuery, err)
+	}
+	// Scopes run last, so an order added here would come before the sorts
+	if len(params.Sorts) == 0 {
+		params.Sorts = []Sort{{Field: "id"}}
+	}
+	var found []person
+	if err := db.Model(&person{}).Scopes(params.Scope()).Find(&found).Error; err != nil {
+		t.Fatalf("%q: %v", rawQuery, err)
+	}
+	result := make([]string, 0, len(found))
+	for _, p := range found {
+		result = append(result, p.Name)
+	}
+	return result
+}
+
+func TestFilterOperators(t *testing.T) {
+	db := people(t)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"filter[team]=red", []string{"Ada", "Cy_1"}},
+		{"filter[team][eq]=blue", []string{"Bob", "Dee 100%"}},
+		{"filter[team][ne]=red", []string{"Bob", "Dee 100%"}},
+		{"filter[age][gt]=25", []string{"Ada", "Dee 100%"}},
+		{"filter[age][gte]=36", []string{"Ada", "Dee 100%"}},
+		{"filter[age][lt]=36", []string{"Bob", "Cy_1"}},
+		{"filter[age][lte]=36", []string{"Ada", "Bob", "Cy_1"}},
+		{"filter[name][in]=Ada, Bob,Zed", []string{"Ada", "Bob"}},
+		{"filter[name][like]=d", []string{"Ada", "Dee 100%"}},
+		// LIKE wildcards in the value match literally
+		{"filter[name][like]=_", []string{"Cy_1"}},
+		{"filter[name][like]=" + url.QueryEscape("0%"), []string{"Dee 100%"}},
+		{"filter[nickname][null]=true", []string{"Ada", "Cy_1", "Dee 100%"}},
+		{"filter[nickname][null]=false", []string{"Bob"}},
+		{"filter[team]=red&filter[age][gt]=30", []string{"Ada"}},
+		{"filter[team]=red&trashed=with", []string{"Ada", "Cy_1", "Eve"}},
+		{"trashed=only", []string{"Eve"}},
+	}
+	for _, tt := range tests {
+		if got := names(t, db, tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSorts(t *testing.T) {
+	db := people(t)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"sort=name", []string{"Ada", "Bob", "Cy_1", "Dee 100%"}},
+		{"sort=-age", []string{"Dee 100%", "Ada", "Bob", "Cy_1"}},
+		// The second field breaks ties of the first
+		{"sort=age,-name", []string{"Cy_1", "Bob", "Ada", "Dee 100%"}},
+		{"sort=team, -age", []string{"Dee 100%", "Bob", "Ada", "Cy_1"}},
+	}
+	for _, tt := range tests {
+		if got := names(t, db, tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	values, _ := url.ParseQuery("sort=age,-name")
+	params, _ := Parse(values, &person{})
+	if want := []Sort{{Field: "age"}, {Field: "name", Desc: true}}; !slices.Equal(params.Sorts, want) {
+		t.Errorf("Sorts = %v, want %v", params.Sorts, want)
+	}
+}
+
+func TestFields(t *testing.T) {
+	db := people(t)
+	values, _ := url.ParseQuery("fields=id,name&filter[name]=Ada")
+	params, err := Parse(values, &person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []person
+	db.Model(&person{}).Scopes(params.Scope()).Find(&found)
+	if len(found) != 1 || found[0].Name != "Ada" || found[0].Age != 0 {
+		t.Errorf("fields=id,name loaded %+v", found)
+	}
+}
+
+func TestParseRejectsUnknownParams(t *testing.T) {
+	for _, rawQuery := range []string{
+		"filter[password]=x",
+		"filter[name][regex]=x",
+		"filter[name",
+		"filter[]=x",
+		"filter[name]x=1",
+		"filter[nickname][null]=maybe",
+		"sort=password",
+		"sort=name,",
+		"sort=-",
+		"fields=id,password",
+		"trashed=all",
+		"page=0",
+	} {
+		values, _ := url.ParseQuery(rawQuery)
+		if _, err := Parse(values, &person{}); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%q = %v, want it to wrap ErrInvalidQuery", rawQuery, err)
+		}
+	}
+
+	// A model without allowlists accepts none of them
+	values, _ := url.ParseQuery("sort=id")
+	if _, err := Parse(values, &struct{ Id uint }{}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("sort on a model without SortableFields = %v", err)
+	}
+	values, _ = url.ParseQuery("trashed=only")
+	if _, err := Parse(values, &struct{ Id uint }{}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("trashed on a model without soft delete = %v", err)
+	}
+}
@@ -8,17 +8,38 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// defaultMultipartMemory is used until SetMultipartMemory is called
+const defaultMultipartMemory = 32 << 20
+
+var multipartMemory atomic.Int64
+
+// SetMultipartMemory sets how many bytes of a multipart form FormFile and
+// MultipartForm keep in memory; larger files are written to temp files
+func SetMultipartMemory(maxMemory int64) {
+	multipartMemory.Store(maxMemory)
+}
+
+func maxMultipartMemory() int64 {
+	if n := multipartMemory.Load(); n > 0 {
+		return n
+	}
+	return defaultMultipartMemory
+}
+
 // Context represents the context of an HTTP request
 type Context struct {
 	Request  *http.Request
 	Writer   ResponseWriter
 	params   Params
+	fullPath string
 	keys     map[string]any
 	mu       sync.RWMutex
 	index    int8
@@ -49,6 +70,7 @@ func (c *Context) reset(w http.ResponseWriter, r *http.Request) {
 	c.Request = r
 	c.Writer = &responseWriter{ResponseWriter: w, status: http.StatusOK}
 	c.params = c.params[:0]
+	c.fullPath = ""
 	c.keys = make(map[string]any)
 	c.index = -1
 	c.handlers = nil
@@ -89,6 +111,12 @@ func (c *Context) Param(key string) string {
 	return c.params.Get(key)
 }
 
+// FullPath returns the matched route pattern (e.g. "/api/users/:id"), or ""
+// when no route matched
+func (c *Context) FullPath() string {
+	return c.fullPath
+}
+
 // Query returns the keyed url query value
 func (c *Context) Query(key string) string {
 	value, _ := c.GetQuery(key)
@@ -127,7 +155,7 @@ func (c *Context) FormValue(key string) string {
 // FormFile returns the multipart form file for the given key
 func (c *Context) FormFile(key string) (*multipart.FileHeader, error) {
 	if c.Request.MultipartForm == nil {
-		if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		if err := c.Request.ParseMultipartForm(maxMultipartMemory()); err != nil {
 			return nil, err
 		}
 	}
@@ -141,7 +169,7 @@ func (c *Context) FormFile(key string) (*multipart.FileHeader, error) {
 
 // MultipartForm returns the parsed multipart form, including file uploads
 func (c *Context) MultipartForm() (*multipart.Form, error) {
-	err := c.Request.ParseMultipartForm(32 << 20)
+	err := c.Request.ParseMultipartForm(maxMultipartMemory())
 	return c.Request.MultipartForm, err
 }
 
@@ -160,8 +188,11 @@ func (c *Context) Cookie(name string) (*http.Cookie, error) {
 	return c.Request.Cookie(name)
 }
 
-// SetCookie adds a Set-Cookie header to the response
+// SetCookie adds a Set-Cookie header to the response. SameSite and Domain
+// default to the CookieConfig, and the cookie is Secure on TLS requests or
+// when the CookieConfig says so; HttpOnly is left to the caller.
 func (c *Context) SetCookie(cookie *http.Cookie) {
+	applyCookieConfig(cookie, c.Request.TLS != nil)
 	http.SetCookie(c.Writer, cookie)
 }
 
@@ -220,15 +251,16 @@ func (c *Context) ShouldBindJSON(obj any) error {
 
 // BindQuery binds the query parameters to a struct
 func (c *Context) BindQuery(obj any) error {
-	values := c.Request.URL.Query()
-	// This is a simplified version - in production you'd use a proper binding library
-	// or implement reflection-based binding
-	return bindData(obj, values)
+	return bindData(obj, c.Request.URL.Query())
 }
 
 // BindForm binds the form data to a struct
 func (c *Context) BindForm(obj any) error {
-	if err := c.Request.ParseForm(); err != nil {
+	if strings.Contains(c.ContentType(), "multipart/form-data") {
+		if err := c.Request.ParseMultipartForm(maxMultipartMemory()); err != nil {
+			return err
+		}
+	} else if err := c.Request.ParseForm(); err != nil {
 		return err
 	}
 	return bindData(obj, c.Request.Form)
@@ -289,7 +321,7 @@ func (c *Context) Redirect(code int, location string) error {
 
 // Error sends an error response
 func (c *Context) Error(code int, err error) error {
-	c.JSON(code, map[string]any{
+	c.Negotiate(code, map[string]any{
 		"error": err.Error(),
 	})
 	return err
@@ -408,12 +440,108 @@ func (c *Context) AbortWithStatusJSON(code int, obj any) {
 	c.JSON(code, obj)
 }
 
-// bindData is a simplified form/query binding helper
+// bindData sets the fields of the struct obj points to from values. Fields
+// are matched by their form tag, then their json tag, then their name;
+// embedded structs are bound in place and fields with no value are left alone.
 func bindData(obj any, values url.Values) error {
-	// This is a placeholder - in production, you'd use reflection
-	// to properly bind form values to struct fields
-	// For now, returning nil to avoid compilation errors
-	_ = obj    // Avoid unused parameter warning
-	_ = values // Avoid unused parameter warning
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind target must be a pointer to a struct, got %T", obj)
+	}
+	return bindStruct(v.Elem(), values)
+}
+
+func bindStruct(v reflect.Value, values url.Values) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := bindStruct(v.Field(i), values); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		raw, ok := values[name]
+		if !ok || len(raw) == 0 {
+			continue
+		}
+		if err := setField(v.Field(i), raw); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// fieldName is the form key a struct field binds from
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"form", "json"} {
+		if name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]; name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func setField(field reflect.Value, raw []string) error {
+	switch field.Kind() {
+	case reflect.Pointer:
+		value := reflect.New(field.Type().Elem())
+		if err := setField(value.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	case reflect.Slice:
+		slice := reflect.MakeSlice(field.Type(), len(raw), len(raw))
+		for i, item := range raw {
+			if err := setValue(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setValue(field, raw[0])
+}
+
+func setValue(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
 	return nil
 }
@@ -0,0 +1,93 @@
+package router
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type Audit struct {
+	Note string `form:"note"`
+}
+
+type filter struct {
+	Audit
+	Name    string   `form:"name"`
+	Page    int      `form:"page"`
+	Active  bool     `json:"active"`
+	Limit   *uint    `form:"limit"`
+	Tags    []string `form:"tag"`
+	Ignored string   `form:"-"`
+}
+
+func TestBindForm(t *testing.T) {
+	form := url.Values{
+		"name":    {"ada"},
+		"page":    {"2"},
+		"active":  {"true"},
+		"limit":   {"10"},
+		"tag":     {"a", "b"},
+		"note":    {"embedded"},
+		"Ignored": {"x"},
+	}
+	var got filter
+	r := New()
+	r.POST("/", func(c *Context) error { return c.Bind(&got) })
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if got.Name != "ada" || got.Page != 2 || !got.Active || got.Note != "embedded" || got.Ignored != "" {
+		t.Errorf("bound %+v", got)
+	}
+	if got.Limit == nil || *got.Limit != 10 {
+		t.Errorf("Limit = %v, want 10", got.Limit)
+	}
+	if !slices.Equal(got.Tags, []string{"a", "b"}) {
+		t.Errorf("Tags = %v", got.Tags)
+	}
+}
+
+func TestBindMultipartForm(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("name", "ada")
+	part, _ := mw.CreateFormFile("file", "a.txt")
+	part.Write([]byte("hello"))
+	mw.Close()
+
+	var got filter
+	r := New()
+	r.POST("/", func(c *Context) error { return c.ShouldBind(&got) })
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.Name != "ada" {
+		t.Errorf("Name = %q, want ada", got.Name)
+	}
+}
+
+func TestBindQueryRejectsBadNumbers(t *testing.T) {
+	var bindErr error
+	r := New()
+	r.GET("/", func(c *Context) error {
+		var f filter
+		bindErr = c.BindQuery(&f)
+		return c.NoContent()
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?page=two", nil))
+
+	if bindErr == nil || !strings.Contains(bindErr.Error(), "page") {
+		t.Errorf("BindQuery error = %v, want one naming page", bindErr)
+	}
+}
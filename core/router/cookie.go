@@ -0,0 +1,59 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// CookieConfig holds the attributes SetCookie fills in on every cookie
+type CookieConfig struct {
+	// Secure marks every cookie Secure; otherwise only cookies set on TLS
+	// requests are
+	Secure bool
+
+	// SameSite applies to cookies that don't set their own
+	SameSite http.SameSite
+
+	// Domain applies to cookies that don't set their own; empty scopes
+	// cookies to the request host
+	Domain string
+}
+
+var cookieConfig atomic.Pointer[CookieConfig]
+
+// SetCookieConfig sets the attributes SetCookie applies
+func SetCookieConfig(config CookieConfig) {
+	cookieConfig.Store(&config)
+}
+
+// ParseSameSite maps "strict", "none" and "lax" to their http.SameSite
+// mode; anything else is lax
+func ParseSameSite(mode string) http.SameSite {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// applyCookieConfig fills in the configured attributes the cookie leaves
+// unset. Browsers drop SameSite=None cookies that aren't Secure, so those
+// are always Secure.
+func applyCookieConfig(cookie *http.Cookie, tls bool) {
+	config := CookieConfig{SameSite: http.SameSiteLaxMode}
+	if c := cookieConfig.Load(); c != nil {
+		config = *c
+	}
+
+	if cookie.SameSite == 0 {
+		cookie.SameSite = config.SameSite
+	}
+	if cookie.Domain == "" {
+		cookie.Domain = config.Domain
+	}
+	cookie.Secure = cookie.Secure || config.Secure || tls || cookie.SameSite == http.SameSiteNoneMode
+}
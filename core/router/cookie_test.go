@@ -0,0 +1,81 @@
+package router
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// setCookie returns the cookie as the browser receives it after SetCookie
+func setCookie(t *testing.T, cookie *http.Cookie, secureRequest bool) *http.Cookie {
+	t.Helper()
+	r := New()
+	r.GET("/", func(c *Context) error {
+		c.SetCookie(cookie)
+		return c.NoContent()
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if secureRequest {
+		req.TLS = &tls.ConnectionState{}
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Set-Cookie = %q", w.Header().Values("Set-Cookie"))
+	}
+	return cookies[0]
+}
+
+func withCookieConfig(t *testing.T, config CookieConfig) {
+	t.Helper()
+	saved := cookieConfig.Load()
+	SetCookieConfig(config)
+	t.Cleanup(func() { cookieConfig.Store(saved) })
+}
+
+func TestSetCookieProductionConfig(t *testing.T) {
+	withCookieConfig(t, CookieConfig{Secure: true, SameSite: ParseSameSite("strict"), Domain: "example.com"})
+
+	got := setCookie(t, &http.Cookie{Name: "session", Value: "x", HttpOnly: true}, false)
+	if !got.Secure || got.SameSite != http.SameSiteStrictMode || got.Domain != "example.com" || !got.HttpOnly {
+		t.Errorf("cookie = %+v, want Secure, HttpOnly, SameSite=Strict on example.com", got)
+	}
+
+	// Attributes the handler sets win over the configured defaults
+	got = setCookie(t, &http.Cookie{Name: "state", Value: "x", SameSite: http.SameSiteLaxMode, Domain: "auth.example.com"}, false)
+	if got.SameSite != http.SameSiteLaxMode || got.Domain != "auth.example.com" || !got.Secure {
+		t.Errorf("cookie = %+v, want its own SameSite and Domain", got)
+	}
+}
+
+func TestSetCookieDevelopmentConfig(t *testing.T) {
+	withCookieConfig(t, CookieConfig{SameSite: http.SameSiteLaxMode})
+
+	got := setCookie(t, &http.Cookie{Name: "session", Value: "x"}, false)
+	if got.Secure || got.SameSite != http.SameSiteLaxMode || got.Domain != "" || got.HttpOnly {
+		t.Errorf("cookie = %+v, want a plain SameSite=Lax cookie", got)
+	}
+	if got := setCookie(t, &http.Cookie{Name: "session", Value: "x"}, true); !got.Secure {
+		t.Error("cookie set over TLS isn't Secure")
+	}
+	// Browsers reject SameSite=None without Secure
+	if got := setCookie(t, &http.Cookie{Name: "embed", Value: "x", SameSite: http.SameSiteNoneMode}, false); !got.Secure {
+		t.Error("SameSite=None cookie isn't Secure")
+	}
+}
+
+func TestParseSameSite(t *testing.T) {
+	for mode, want := range map[string]http.SameSite{
+		"strict":   http.SameSiteStrictMode,
+		" None ":   http.SameSiteNoneMode,
+		"lax":      http.SameSiteLaxMode,
+		"":         http.SameSiteLaxMode,
+		"sideways": http.SameSiteLaxMode,
+	} {
+		if got := ParseSameSite(mode); got != want {
+			t.Errorf("ParseSameSite(%q) = %v, want %v", mode, got, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package router
+
+// Route describes a registered route
+type Route struct {
+	Method string
+	Path   string
+	Docs   *Doc
+
+	// Security lists the schemes, e.g. "BearerAuth", the route's group
+	// requires; see RouterGroup.Security
+	Security []string
+}
+
+// Doc holds API documentation metadata for a route.
+// Request and Response are sample values (e.g. LoginRequest{}) whose types
+// are reflected over when generating the OpenAPI document.
+type Doc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Request     any
+	Response    any
+	Status      int // success status code, defaults to 200
+
+	// Security overrides the schemes taken from the route's group
+	Security []string
+}
+
+// Doc attaches documentation metadata to the route
+func (rt *Route) Doc(doc Doc) *Route {
+	rt.Docs = &doc
+	return rt
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"base/core/router"
+)
+
+// AdminTokenHeader carries the operator token AdminAuth checks
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminAuth guards operator endpoints such as /system/modules: requests must
+// send token in the X-Admin-Token header. An empty token rejects every request.
+func AdminAuth(token string) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			sent := c.GetHeader(AdminTokenHeader)
+			if token == "" || sent == "" {
+				return c.Fail(http.StatusUnauthorized, "admin_token_required", "Send the admin token in the "+AdminTokenHeader+" header")
+			}
+			if subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+				return c.Fail(http.StatusForbidden, "invalid_admin_token", "Invalid admin token")
+			}
+			return next(c)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"base/core/router"
+	"base/core/types"
 )
 
 // contextKey is an empty struct with a descriptive name tag. Using a
@@ -120,6 +121,32 @@ func Auth(config *AuthConfig) router.MiddlewareFunc {
 	}
 }
 
+// BearerAuth requires a valid access token and stores the user id under "user_id"
+func BearerAuth() router.MiddlewareFunc {
+	config := DefaultAuthConfig()
+	config.Key = "user_id"
+	config.TokenValidator = func(token string) (any, error) {
+		return types.ValidateJWT(token)
+	}
+	return Auth(config)
+}
+
+// StreamAuth is BearerAuth that also takes the access token from the token
+// query parameter, since browsers can't set headers on EventSource and
+// WebSocket requests
+func StreamAuth() router.MiddlewareFunc {
+	bearer := BearerAuth()
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		authenticated := bearer(next)
+		return func(c *router.Context) error {
+			if token := c.Query("token"); token != "" && c.Header("Authorization") == "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+			return authenticated(c)
+		}
+	}
+}
+
 // RequireAuth is a simple auth middleware that just checks if user is present
 func RequireAuth(key string) router.MiddlewareFunc {
 	if key == "" {
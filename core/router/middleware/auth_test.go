@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"base/core/router"
+	"base/core/types"
+)
+
+func TestStreamAuth(t *testing.T) {
+	types.SetKeySet(types.NewHMACKeySet("test", "test-secret"))
+	t.Cleanup(func() { types.SetKeySet(nil) })
+	token, err := types.GenerateJWT(7, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := router.New()
+	r.GET("/events", func(c *router.Context) error {
+		return c.String(http.StatusOK, "%d", c.GetUint("user_id"))
+	}, StreamAuth())
+
+	tests := []struct {
+		path   string
+		header http.Header
+		want   int
+	}{
+		{"/events?token=" + token, nil, http.StatusOK},
+		{"/events", http.Header{"Authorization": {"Bearer " + token}}, http.StatusOK},
+		// A header takes precedence over the query parameter
+		{"/events?token=invalid", http.Header{"Authorization": {"Bearer " + token}}, http.StatusOK},
+		{"/events?token=invalid", nil, http.StatusUnauthorized},
+		{"/events", nil, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		w := request(r, http.MethodGet, tt.path, tt.header)
+		if w.Code != tt.want {
+			t.Errorf("%s with %v = %d, want %d", tt.path, tt.header, w.Code, tt.want)
+		} else if tt.want == http.StatusOK && w.Body.String() != "7" {
+			t.Errorf("%s: user_id = %s, want 7", tt.path, w.Body.String())
+		}
+	}
+}
+
+func TestAdminAuth(t *testing.T) {
+	handler := func(c *router.Context) error { return c.NoContent() }
+	r := router.New()
+	r.GET("/system/modules", handler, AdminAuth("s3cret"))
+	r.GET("/unset", handler, AdminAuth(""))
+
+	tests := []struct {
+		path, token string
+		want        int
+	}{
+		{"/system/modules", "s3cret", http.StatusNoContent},
+		{"/system/modules", "wrong", http.StatusForbidden},
+		{"/system/modules", "", http.StatusUnauthorized},
+		// Without a configured token nothing gets through
+		{"/unset", "", http.StatusUnauthorized},
+		{"/unset", "anything", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		var header http.Header
+		if tt.token != "" {
+			header = http.Header{AdminTokenHeader: {tt.token}}
+		}
+		if w := request(r, http.MethodGet, tt.path, header); w.Code != tt.want {
+			t.Errorf("%s with token %q = %d, want %d", tt.path, tt.token, w.Code, tt.want)
+		}
+	}
+}
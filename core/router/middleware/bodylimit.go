@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"base/core/router"
+)
+
+// BodyLimitKey overrides the body limit, in bytes, for a route group:
+//
+//	uploads := api.Group("").Set(middleware.BodyLimitKey, middleware.UploadBodyLimit())
+//	uploads.POST("/media", h.Create)
+const BodyLimitKey = "body_limit"
+
+var uploadBodyLimit int64 = 20 << 20
+
+// SetUploadBodyLimit sets the limit UploadBodyLimit returns
+func SetUploadBodyLimit(maxBytes int64) {
+	uploadBodyLimit = maxBytes
+}
+
+// UploadBodyLimit is the body limit for upload routes, set from
+// UPLOAD_BODY_LIMIT. Routes read it when they are registered.
+func UploadBodyLimit() int64 {
+	return uploadBodyLimit
+}
+
+// BodyLimit rejects request bodies larger than maxBytes, or the group's
+// BodyLimitKey, with 413. Bodies that declare a larger Content-Length are
+// rejected before the handler runs; others are cut off while being read, and
+// handlers answer the read error with ctx.BodyTooLarge. A limit of 0 or less
+// lets any body through.
+func BodyLimit(maxBytes int64) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			limit := maxBytes
+			if override, ok := c.Get(BodyLimitKey); ok {
+				if n, ok := override.(int64); ok {
+					limit = n
+				}
+			}
+			if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+				return next(c)
+			}
+
+			if c.Request.ContentLength > limit {
+				return c.Fail(http.StatusRequestEntityTooLarge, "body_too_large",
+					fmt.Sprintf("Request body is larger than %d bytes", limit))
+			}
+
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+			return next(c)
+		}
+	}
+}
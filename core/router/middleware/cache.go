@@ -0,0 +1,247 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"base/core/cache"
+	"base/core/logger"
+	"base/core/router"
+)
+
+// CacheConfig contains response caching configuration
+type CacheConfig struct {
+	// TTL is how long a response is served from the cache
+	TTL time.Duration
+
+	// KeyFunc identifies a response; defaults to CacheKey
+	KeyFunc func(*router.Context) string
+
+	// Tags group the cached responses; successful writes through the same
+	// middleware, or cache.Invalidate, drop them
+	Tags []string
+
+	// PerUser adds the authenticated user and organization to the key so
+	// per-user data is never served to someone else. Place the middleware
+	// after BearerAuth and OrganizationContext when set.
+	PerUser bool
+
+	// Store holds the responses; defaults to cache.Default()
+	Store cache.Store
+}
+
+// cachedResponse is what the cache keeps for a GET
+type cachedResponse struct {
+	ContentType string `json:"content_type"`
+	ETag        string `json:"etag"`
+	Body        []byte `json:"body"`
+}
+
+// CacheKey identifies a response by method, path and query, plus what picks
+// its representation: the Accept header, which negotiates JSON, XML or
+// MessagePack, and the request's language
+func CacheKey(c *router.Context) string {
+	language := c.GetHeader("Accept-Language")
+	if negotiated, ok := c.Get("language"); ok {
+		language, _ = negotiated.(string)
+	}
+	return c.Request.Method + " " + c.Request.URL.Path + "?" + c.Request.URL.RawQuery +
+		"|accept=" + c.GetHeader("Accept") + "|lang=" + language
+}
+
+// Cache creates middleware that serves successful GET responses from the
+// cache for ttl and invalidates tags after successful writes:
+//
+//	media := router.Group("", middleware.Cache(time.Minute, nil, "media"))
+func Cache(ttl time.Duration, keyFunc func(*router.Context) string, tags ...string) router.MiddlewareFunc {
+	return CacheWithConfig(&CacheConfig{TTL: ttl, KeyFunc: keyFunc, Tags: tags})
+}
+
+// CacheWithConfig creates response caching middleware. Every GET response
+// gets a weak ETag, and a matching If-None-Match is answered with 304.
+func CacheWithConfig(config *CacheConfig) router.MiddlewareFunc {
+	if config.KeyFunc == nil {
+		config.KeyFunc = CacheKey
+	}
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			store := config.Store
+			if store == nil {
+				store = cache.Default()
+			}
+
+			if c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions {
+				return next(c)
+			}
+			if c.Request.Method != http.MethodGet {
+				err := next(c)
+				if err == nil && len(config.Tags) > 0 && c.Writer.Status() < 300 {
+					if err := store.Invalidate(c, config.Tags...); err != nil {
+						logger.FromContext(c).Warn("Cache invalidation failed", logger.String("error", err.Error()))
+					}
+				}
+				return err
+			}
+
+			key := "http:" + config.KeyFunc(c)
+			if config.PerUser {
+				key += "|user=" + cacheIdentity(c)
+			}
+
+			if value, ok, err := store.Get(c, key); err == nil && ok {
+				var cached cachedResponse
+				if json.Unmarshal(value, &cached) == nil {
+					c.SetHeader("X-Cache", "HIT")
+					return writeCached(c, &cached)
+				}
+			}
+
+			recorder := &cacheRecorder{ResponseWriter: c.Writer, status: http.StatusOK}
+			c.Writer = recorder
+			err := next(c)
+			c.Writer = recorder.ResponseWriter
+			if recorder.hijacked {
+				return err
+			}
+
+			// Only plain successful responses are reusable
+			if err != nil || recorder.status != http.StatusOK || c.Writer.Header().Get("Set-Cookie") != "" {
+				if !recorder.headerWritten {
+					return err // nothing was sent; leave the response to the error handler
+				}
+				c.Writer.WriteHeader(recorder.status)
+				if _, writeErr := c.Writer.Write(recorder.body.Bytes()); err == nil {
+					err = writeErr
+				}
+				return err
+			}
+
+			cached := cachedResponse{
+				ContentType: c.Writer.Header().Get("Content-Type"),
+				ETag:        etag(recorder.body.Bytes()),
+				Body:        recorder.body.Bytes(),
+			}
+			if value, err := json.Marshal(&cached); err == nil {
+				if err := store.Set(c, key, value, config.TTL, config.Tags...); err != nil {
+					logger.FromContext(c).Warn("Cache write failed", logger.String("error", err.Error()))
+				}
+			}
+
+			c.SetHeader("X-Cache", "MISS")
+			return writeCached(c, &cached)
+		}
+	}
+}
+
+// writeCached sends a cached response, or 304 when the client has it. Vary
+// tells shared caches downstream that the representation depends on the
+// headers CacheKey reads.
+func writeCached(c *router.Context, cached *cachedResponse) error {
+	c.Writer.Header().Add("Vary", "Accept")
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	c.SetHeader("ETag", cached.ETag)
+	if ETagMatches(c.GetHeader("If-None-Match"), cached.ETag) {
+		c.Writer.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	if cached.ContentType != "" {
+		c.SetHeader("Content-Type", cached.ContentType)
+	}
+	c.SetHeader("Content-Length", strconv.Itoa(len(cached.Body)))
+	c.Writer.WriteHeader(http.StatusOK)
+	_, err := c.Writer.Write(cached.Body)
+	return err
+}
+
+// cacheIdentity is the authenticated user and organization, or a hash of the
+// credentials when no auth middleware ran first
+func cacheIdentity(c *router.Context) string {
+	if userId := c.GetUint("user_id"); userId != 0 {
+		return strconv.FormatUint(uint64(userId), 10) + "|org=" + strconv.FormatUint(c.OrgID(), 10)
+	}
+	sum := sha256.Sum256([]byte(c.GetHeader("Authorization") + "\x00" + c.GetHeader("X-Api-Key")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// etag is a weak validator: compression may change the bytes on the wire
+func etag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ETagMatches reports whether an If-None-Match header lists tag, comparing
+// weakly as RFC 9110 requires
+func ETagMatches(header, tag string) bool {
+	if header == "" {
+		return false
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheRecorder buffers a response so it can be stored and given an ETag
+// before anything is sent
+type cacheRecorder struct {
+	router.ResponseWriter
+	status        int
+	headerWritten bool
+	hijacked      bool
+	body          bytes.Buffer
+}
+
+// WriteHeader records the status
+func (w *cacheRecorder) WriteHeader(code int) {
+	if w.headerWritten {
+		return
+	}
+	w.status = code
+	w.headerWritten = true
+}
+
+// Write buffers the body
+func (w *cacheRecorder) Write(data []byte) (int, error) {
+	if !w.headerWritten {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.body.Write(data)
+}
+
+// Status returns the recorded status
+func (w *cacheRecorder) Status() int {
+	return w.status
+}
+
+// Size returns the buffered body size
+func (w *cacheRecorder) Size() int {
+	return w.body.Len()
+}
+
+// Written reports whether the handler has responded
+func (w *cacheRecorder) Written() bool {
+	return w.headerWritten
+}
+
+// Flush is a no-op; the body is sent once the handler returns
+func (w *cacheRecorder) Flush() {}
+
+// Hijack hands the connection over; nothing is cached afterwards
+func (w *cacheRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return w.ResponseWriter.Hijack()
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"base/core/cache"
+	"base/core/router"
+)
+
+// cachedRouter counts handler calls to /items behind the cache middleware.
+// The X-User header stands in for BearerAuth.
+func cachedRouter(config *CacheConfig) (*router.Router, *int) {
+	calls := new(int)
+	r := router.New()
+	r.Use(func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			if id, err := strconv.Atoi(c.GetHeader("X-User")); err == nil {
+				c.Set("user_id", uint(id))
+			}
+			return next(c)
+		}
+	})
+	items := r.Group("", CacheWithConfig(config))
+	items.GET("/items", func(c *router.Context) error {
+		*calls++
+		return c.JSON(http.StatusOK, map[string]any{"call": *calls, "user": c.GetUint("user_id")})
+	})
+	items.GET("/missing", func(c *router.Context) error {
+		*calls++
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "missing"})
+	})
+	items.POST("/items", func(c *router.Context) error {
+		return c.JSON(http.StatusCreated, nil)
+	})
+	items.PUT("/items", func(c *router.Context) error {
+		return c.JSON(http.StatusBadRequest, nil)
+	})
+	return r, calls
+}
+
+func request(r *router.Router, method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCacheHitAndMiss(t *testing.T) {
+	r, calls := cachedRouter(&CacheConfig{TTL: time.Minute, Store: cache.NewMemoryStore(0)})
+
+	first := request(r, http.MethodGet, "/items", nil)
+	second := request(r, http.MethodGet, "/items", nil)
+	if first.Header().Get("X-Cache") != "MISS" || second.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("X-Cache = %q then %q, want MISS then HIT", first.Header().Get("X-Cache"), second.Header().Get("X-Cache"))
+	}
+	if *calls != 1 || second.Body.String() != first.Body.String() {
+		t.Errorf("handler ran %d times; bodies %q and %q", *calls, first.Body.String(), second.Body.String())
+	}
+	if second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("Content-Type not kept: %q", second.Header().Get("Content-Type"))
+	}
+
+	// Different queries and representations are cached separately
+	request(r, http.MethodGet, "/items?page=2", nil)
+	request(r, http.MethodGet, "/items", http.Header{"Accept": {"application/xml"}})
+	if *calls != 3 {
+		t.Errorf("handler ran %d times, want a miss per query and Accept", *calls)
+	}
+
+	// Errors aren't cached
+	request(r, http.MethodGet, "/missing", nil)
+	if w := request(r, http.MethodGet, "/missing", nil); w.Code != http.StatusNotFound || *calls != 5 {
+		t.Errorf("missing = %d after %d calls, want 404 from the handler each time", w.Code, *calls)
+	}
+}
+
+func TestCacheExpires(t *testing.T) {
+	r, calls := cachedRouter(&CacheConfig{TTL: 50 * time.Millisecond, Store: cache.NewMemoryStore(0)})
+	request(r, http.MethodGet, "/items", nil)
+	time.Sleep(100 * time.Millisecond)
+	if w := request(r, http.MethodGet, "/items", nil); w.Header().Get("X-Cache") != "MISS" || *calls != 2 {
+		t.Errorf("X-Cache = %q after %d calls, want a miss once the TTL passed", w.Header().Get("X-Cache"), *calls)
+	}
+}
+
+func TestCacheETag(t *testing.T) {
+	r, _ := cachedRouter(&CacheConfig{TTL: time.Minute, Store: cache.NewMemoryStore(0)})
+
+	w := request(r, http.MethodGet, "/items", nil)
+	tag := w.Header().Get("ETag")
+	if tag == "" {
+		t.Fatal("no ETag")
+	}
+	for _, header := range []string{tag, `"other", ` + tag, "*"} {
+		if w := request(r, http.MethodGet, "/items", http.Header{"If-None-Match": {header}}); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s = %d %q, want an empty 304", header, w.Code, w.Body.String())
+		}
+	}
+	if w := request(r, http.MethodGet, "/items", http.Header{"If-None-Match": {`W/"stale"`}}); w.Code != http.StatusOK {
+		t.Errorf("stale If-None-Match = %d, want 200", w.Code)
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	store := cache.NewMemoryStore(0)
+	r, calls := cachedRouter(&CacheConfig{TTL: time.Minute, Store: store, Tags: []string{"items"}})
+
+	request(r, http.MethodGet, "/items", nil)
+	request(r, http.MethodPut, "/items", nil)
+	if w := request(r, http.MethodGet, "/items", nil); w.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("a failed write invalidated the cache")
+	}
+
+	request(r, http.MethodPost, "/items", nil)
+	if w := request(r, http.MethodGet, "/items", nil); w.Header().Get("X-Cache") != "MISS" || *calls != 2 {
+		t.Errorf("X-Cache = %q after a successful write, want MISS", w.Header().Get("X-Cache"))
+	}
+
+	// Services invalidate writes that bypass the middleware through the store
+	saved := cache.Default()
+	cache.SetDefault(store)
+	t.Cleanup(func() { cache.SetDefault(saved) })
+	if err := cache.Invalidate("items"); err != nil {
+		t.Fatal(err)
+	}
+	if w := request(r, http.MethodGet, "/items", nil); w.Header().Get("X-Cache") != "MISS" || *calls != 3 {
+		t.Errorf("X-Cache = %q after cache.Invalidate, want MISS", w.Header().Get("X-Cache"))
+	}
+}
+
+func TestCachePerUser(t *testing.T) {
+	r, calls := cachedRouter(&CacheConfig{TTL: time.Minute, Store: cache.NewMemoryStore(0), PerUser: true})
+
+	bodies := map[int]string{}
+	for _, user := range []int{1, 2, 1} {
+		w := request(r, http.MethodGet, "/items", http.Header{"X-User": {strconv.Itoa(user)}})
+		if previous, ok := bodies[user]; ok && previous != w.Body.String() {
+			t.Errorf("user %d got %q, then %q", user, previous, w.Body.String())
+		}
+		bodies[user] = w.Body.String()
+	}
+	if *calls != 2 || bodies[1] == bodies[2] {
+		t.Errorf("handler ran %d times; bodies %v", *calls, bodies)
+	}
+
+	// Without auth middleware the credentials themselves separate callers
+	for _, key := range []string{"a", "b"} {
+		w := request(r, http.MethodGet, "/items", http.Header{"X-Api-Key": {key}})
+		if w.Header().Get("X-Cache") != "MISS" {
+			t.Errorf("API key %s was served another caller's response", key)
+		}
+	}
+}
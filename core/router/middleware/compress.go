@@ -0,0 +1,309 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"base/core/router"
+
+	"github.com/andybalholm/brotli"
+)
+
+// CompressConfig contains compression middleware configuration
+type CompressConfig struct {
+	// MinSize is the smallest body, in bytes, that gets compressed
+	MinSize int
+
+	// Level is the gzip compression level; brotli uses a level of similar cost
+	Level int
+
+	// SkipContentTypes lists content type prefixes that are already compressed
+	SkipContentTypes []string
+
+	// SkipPaths lists paths that are never compressed
+	SkipPaths []string
+}
+
+// DefaultCompressConfig returns default compression configuration
+func DefaultCompressConfig() *CompressConfig {
+	return &CompressConfig{
+		MinSize: 1024,
+		Level:   gzip.DefaultCompression,
+		SkipContentTypes: []string{
+			"image/",
+			"video/",
+			"audio/",
+			"font/woff",
+			"application/zip",
+			"application/gzip",
+			"application/x-gzip",
+			"application/x-7z-compressed",
+			"application/x-rar-compressed",
+			"application/pdf",
+			"application/msgpack",
+			"application/x-msgpack",
+			"application/vnd.msgpack",
+		},
+	}
+}
+
+// Compress creates middleware that gzip or brotli encodes responses of at
+// least MinSize bytes when the client's Accept-Encoding allows it
+func Compress(config *CompressConfig) router.MiddlewareFunc {
+	if config == nil {
+		config = DefaultCompressConfig()
+	}
+
+	gzipPool := sync.Pool{New: func() any {
+		w, err := gzip.NewWriterLevel(io.Discard, config.Level)
+		if err != nil {
+			w = gzip.NewWriter(io.Discard)
+		}
+		return w
+	}}
+	brotliPool := sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(io.Discard, brotliLevel(config.Level))
+	}}
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			if c.Request.Method == http.MethodHead || c.IsWebSocket() || c.IsEventStream() {
+				return next(c)
+			}
+			for _, path := range config.SkipPaths {
+				if c.Request.URL.Path == path {
+					return next(c)
+				}
+			}
+
+			encoding := acceptedEncoding(c.GetHeader("Accept-Encoding"))
+			c.Writer.Header().Add("Vary", "Accept-Encoding")
+			if encoding == "" {
+				return next(c)
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: c.Writer,
+				config:         config,
+				encoding:       encoding,
+				status:         http.StatusOK,
+			}
+			switch encoding {
+			case "br":
+				cw.newEncoder = func(w io.Writer) io.WriteCloser {
+					bw := brotliPool.Get().(*brotli.Writer)
+					bw.Reset(w)
+					cw.release = func() { brotliPool.Put(bw) }
+					return bw
+				}
+			default:
+				cw.newEncoder = func(w io.Writer) io.WriteCloser {
+					gw := gzipPool.Get().(*gzip.Writer)
+					gw.Reset(w)
+					cw.release = func() { gzipPool.Put(gw) }
+					return gw
+				}
+			}
+
+			c.Writer = cw
+			defer func() {
+				cw.close()
+				c.Writer = cw.ResponseWriter
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+// acceptedEncoding picks br or gzip from Accept-Encoding, preferring br when
+// both have the same quality
+func acceptedEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "br" && name != "gzip" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > bestQ || q == bestQ && name == "br" {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// brotliLevel maps a gzip level (1-9) onto brotli's 0-11 range
+func brotliLevel(level int) int {
+	switch {
+	case level == gzip.DefaultCompression:
+		return brotli.DefaultCompression
+	case level <= gzip.NoCompression:
+		return brotli.BestSpeed
+	case level >= gzip.BestCompression:
+		return brotli.BestCompression
+	}
+	return level * brotli.BestCompression / gzip.BestCompression
+}
+
+// compressWriter buffers the start of the body until MinSize is reached, then
+// either streams it through an encoder or writes it unchanged
+type compressWriter struct {
+	router.ResponseWriter
+	config     *CompressConfig
+	encoding   string
+	newEncoder func(io.Writer) io.WriteCloser
+	release    func()
+
+	status        int
+	headerWritten bool
+	decided       bool
+	buf           []byte
+	encoder       io.WriteCloser
+}
+
+// WriteHeader records the status; it's sent once the body decides the encoding
+func (w *compressWriter) WriteHeader(code int) {
+	if w.headerWritten {
+		return
+	}
+	w.status = code
+	w.headerWritten = true
+
+	// Bodiless responses have nothing to wait for
+	if code == http.StatusNoContent || code == http.StatusNotModified || code < 200 {
+		w.decide(false)
+	}
+}
+
+// Write buffers data until MinSize bytes are seen
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.headerWritten {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf = append(w.buf, data...)
+	if len(w.buf) >= w.config.MinSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// Status returns the response status code
+func (w *compressWriter) Status() int {
+	if !w.decided {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+// Written returns true if the response has been written
+func (w *compressWriter) Written() bool {
+	return w.headerWritten || w.ResponseWriter.Written()
+}
+
+// Flush commits to compressing a streamed body regardless of its size so far
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if !w.headerWritten {
+			w.WriteHeader(http.StatusOK)
+		}
+		w.decide(true)
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Hijack hands the connection over uncompressed
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
+
+// decide sends the header and buffered body, compressed when allowed
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		// Sniff before encoding, net/http would otherwise sniff compressed bytes
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	if compress && w.compressible(header) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		w.ResponseWriter.WriteHeader(w.status)
+		w.encoder = w.newEncoder(w.ResponseWriter)
+		if len(w.buf) == 0 {
+			return nil
+		}
+		_, err := w.encoder.Write(w.buf)
+		w.buf = nil
+		return err
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// compressible reports whether the response may be re-encoded
+func (w *compressWriter) compressible(header http.Header) bool {
+	if w.status < 200 || w.status == http.StatusNoContent || w.status == http.StatusNotModified ||
+		w.status == http.StatusPartialContent {
+		return false
+	}
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	for _, prefix := range w.config.SkipContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// close writes a body that never reached MinSize and finishes the encoder
+func (w *compressWriter) close() {
+	if !w.decided {
+		if w.headerWritten || len(w.buf) > 0 {
+			w.decide(false)
+		}
+		return
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+		w.release()
+		w.encoder = nil
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"base/core/router"
+
+	"github.com/andybalholm/brotli"
+)
+
+func compressed(t *testing.T, body, contentType, acceptEncoding string) *httptest.ResponseRecorder {
+	t.Helper()
+	config := DefaultCompressConfig()
+	config.MinSize = 100
+
+	r := router.New()
+	r.Use(Compress(config))
+	r.GET("/", func(c *router.Context) error {
+		if contentType != "" {
+			c.SetHeader("Content-Type", contentType)
+		}
+		c.Writer.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(c.Writer, body)
+		return err
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCompressSkipsBodiesUnderMinSize(t *testing.T) {
+	body := strings.Repeat("a", 99)
+	w := compressed(t, body, "text/plain", "gzip, br")
+	if enc := w.Header().Get("Content-Encoding"); enc != "" {
+		t.Errorf("Content-Encoding = %q for a body under MinSize", enc)
+	}
+	if w.Body.String() != body {
+		t.Errorf("body changed: %q", w.Body.String())
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("Vary = %q", w.Header().Get("Vary"))
+	}
+}
+
+func TestCompressEncodings(t *testing.T) {
+	body := strings.Repeat("compress me ", 50)
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"br":   func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+	}
+
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"gzip", "gzip"},
+		{"br", "br"},
+		{"gzip, br", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"deflate", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		w := compressed(t, body, "text/plain", tt.acceptEncoding)
+		got := w.Header().Get("Content-Encoding")
+		if got != tt.want {
+			t.Errorf("Accept-Encoding %q: Content-Encoding = %q, want %q", tt.acceptEncoding, got, tt.want)
+			continue
+		}
+		if got == "" {
+			if w.Body.String() != body {
+				t.Errorf("Accept-Encoding %q: body changed", tt.acceptEncoding)
+			}
+			continue
+		}
+
+		reader, err := decoders[got](w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := io.ReadAll(reader)
+		if err != nil || string(decoded) != body {
+			t.Errorf("Accept-Encoding %q: decoded %d bytes, %v", tt.acceptEncoding, len(decoded), err)
+		}
+	}
+}
+
+func TestCompressSkipsCompressedContentTypes(t *testing.T) {
+	w := compressed(t, strings.Repeat("x", 500), "image/png", "gzip")
+	if enc := w.Header().Get("Content-Encoding"); enc != "" {
+		t.Errorf("Content-Encoding = %q for an image", enc)
+	}
+}
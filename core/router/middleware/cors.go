@@ -4,11 +4,31 @@ import (
 	"base/core/router"
 )
 
-func CORSMiddleware(allowedOrigins []string) router.MiddlewareFunc {
+// CORSOriginsKey overrides the allowed origins for a route group:
+//
+//	public := api.Group("/public").Set(middleware.CORSOriginsKey, []string{"*"})
+const CORSOriginsKey = "cors_allowed_origins"
+
+func CORSMiddleware(defaultOrigins []string) router.MiddlewareFunc {
+	return DynamicCORS(func() []string { return defaultOrigins })
+}
+
+// DynamicCORS is CORSMiddleware with the default origins read on every
+// request, so they can follow a configuration reload:
+//
+//	middleware.DynamicCORS(func() []string { return config.Current().CORSAllowedOrigins })
+func DynamicCORS(defaultOrigins func() []string) router.MiddlewareFunc {
 	return func(next router.HandlerFunc) router.HandlerFunc {
 		return func(c *router.Context) error {
 			origin := c.GetHeader("Origin")
 
+			allowedOrigins := defaultOrigins()
+			if override, ok := c.Get(CORSOriginsKey); ok {
+				if origins, ok := override.([]string); ok {
+					allowedOrigins = origins
+				}
+			}
+
 			// Allow all origins if "*" is present, otherwise match against allowedOrigins
 			allowOrigin := ""
 			if len(allowedOrigins) == 1 && allowedOrigins[0] == "*" {
@@ -24,9 +44,9 @@ func CORSMiddleware(allowedOrigins []string) router.MiddlewareFunc {
 
 			if allowOrigin != "" {
 				c.SetHeader("Access-Control-Allow-Origin", allowOrigin)
-				c.SetHeader("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-				c.SetHeader("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Api-Key, Base-Orgid")
-				c.SetHeader("Access-Control-Expose-Headers", "Content-Length, Content-Type")
+				c.SetHeader("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+				c.SetHeader("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Api-Key, Base-Orgid, Idempotency-Key, If-None-Match")
+				c.SetHeader("Access-Control-Expose-Headers", "Content-Length, Content-Type, Content-Disposition, ETag, Location, X-Request-Id, X-Cache, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset")
 				c.SetHeader("Access-Control-Allow-Credentials", "true")
 				c.SetHeader("Access-Control-Max-Age", "43200") // 12 hours
 			}
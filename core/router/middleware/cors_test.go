@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"base/core/router"
+)
+
+func TestCORSPreflight(t *testing.T) {
+	r := router.New()
+	r.Use(CORSMiddleware([]string{"https://app.example.com"}))
+	r.PATCH("/items/:id", func(c *router.Context) error { return c.NoContent() })
+
+	req := httptest.NewRequest(http.MethodOptions, "/items/1", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Fatalf("Access-Control-Allow-Origin = %q", got)
+	}
+
+	checks := map[string][]string{
+		"Access-Control-Allow-Methods":  {"PATCH"},
+		"Access-Control-Allow-Headers":  {"Idempotency-Key", "If-None-Match"},
+		"Access-Control-Expose-Headers": {"ETag", "X-Request-Id", "Retry-After", "X-RateLimit-Remaining"},
+	}
+	for header, values := range checks {
+		listed := strings.Split(w.Header().Get(header), ", ")
+		for _, value := range values {
+			if !slices.Contains(listed, value) {
+				t.Errorf("%s = %q, missing %s", header, w.Header().Get(header), value)
+			}
+		}
+	}
+}
+
+func TestCORSUnknownOrigin(t *testing.T) {
+	r := router.New()
+	r.Use(CORSMiddleware([]string{"https://app.example.com"}))
+	r.GET("/items", func(c *router.Context) error { return c.NoContent() })
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q for an unlisted origin", got)
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"base/core/database"
+	"base/core/router"
+)
+
+// DatabaseAvailable fails requests fast with 503 while the database health
+// monitor reports the database as unreachable, instead of letting them wait
+// on the pool. Requests pass again as soon as a health check succeeds.
+func DatabaseAvailable() router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			if !database.Healthy() {
+				c.SetHeader("Retry-After", "5")
+				return c.Fail(http.StatusServiceUnavailable, "database_unavailable",
+					"The database is temporarily unavailable")
+			}
+			return next(c)
+		}
+	}
+}
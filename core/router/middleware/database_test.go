@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"base/core/database"
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// monitor runs the database health monitor over db until stop is called
+func monitor(db *gorm.DB) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		database.Monitor(ctx, db, 10*time.Millisecond, logger.NewLoggerFromZap(zap.NewNop()))
+		close(done)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestDatabaseAvailable(t *testing.T) {
+	r := router.New()
+	r.Use(DatabaseAvailable())
+	r.GET("/items", func(c *router.Context) error { return c.NoContent() })
+
+	open := func() *gorm.DB {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "db.sqlite")), &gorm.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+	waitFor := func(healthy bool) {
+		t.Helper()
+		for deadline := time.Now().Add(time.Second); database.Healthy() != healthy; time.Sleep(5 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("database.Healthy() never became %t", healthy)
+			}
+		}
+	}
+
+	if w := request(r, http.MethodGet, "/items", nil); w.Code != http.StatusNoContent {
+		t.Errorf("healthy = %d, want the handler's 204", w.Code)
+	}
+
+	// A closed pool can't be pinged
+	down := open()
+	sqlDB, _ := down.DB()
+	sqlDB.Close()
+	stop := monitor(down)
+	waitFor(false)
+	stop()
+
+	w := request(r, http.MethodGet, "/items", nil)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("unavailable = %d with Retry-After %q, want 503 and a retry hint", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// The first successful check lets requests through again
+	defer monitor(open())()
+	waitFor(true)
+	if w := request(r, http.MethodGet, "/items", nil); w.Code != http.StatusNoContent {
+		t.Errorf("recovered = %d, want 204", w.Code)
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+
+	"base/core/router"
+)
+
+// HSTS sets Strict-Transport-Security on responses served over TLS, so
+// browsers use HTTPS for maxAge. A maxAge of 0 or less sends nothing.
+func HSTS(maxAge time.Duration) router.MiddlewareFunc {
+	value := fmt.Sprintf("max-age=%d; includeSubDomains", int64(maxAge.Seconds()))
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			if maxAge > 0 && c.Request.TLS != nil {
+				c.SetHeader("Strict-Transport-Security", value)
+			}
+			return next(c)
+		}
+	}
+}
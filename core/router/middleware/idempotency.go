@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"base/core/cache"
+	"base/core/logger"
+	"base/core/router"
+)
+
+// IdempotencyHeader carries the client's key for a retryable request
+const IdempotencyHeader = "Idempotency-Key"
+
+// IdempotencyReplayedHeader marks responses served from a previous request
+const IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKey bounds the header; clients normally send a UUID
+const maxIdempotencyKey = 255
+
+var idempotencyTTL = 24 * time.Hour
+
+// SetIdempotencyTTL sets how long DefaultIdempotencyConfig keeps responses
+func SetIdempotencyTTL(ttl time.Duration) {
+	idempotencyTTL = ttl
+}
+
+// IdempotencyConfig contains idempotency configuration
+type IdempotencyConfig struct {
+	// TTL is how long a response is replayed for retries of its key
+	TTL time.Duration
+
+	// Store keeps the responses; defaults to cache.Default(), so a Redis
+	// cache store makes keys hold across instances
+	Store cache.Store
+}
+
+// DefaultIdempotencyConfig returns default idempotency configuration
+func DefaultIdempotencyConfig() *IdempotencyConfig {
+	return &IdempotencyConfig{TTL: idempotencyTTL}
+}
+
+// idempotentResponse is what is kept for a key; Status is 0 while the first
+// request is still running. Header holds the headers the handler set, such as
+// Content-Type and Location.
+type idempotentResponse struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// Idempotency creates middleware that makes writes safe to retry. When a
+// request carries an Idempotency-Key header, the first response is kept per
+// key, route and caller, and retries with the same key get it back with an
+// Idempotent-Replayed header instead of running the handler again. Reusing
+// a key with a different body, or while the first request is still running,
+// is rejected with 409. Requests without the header run normally and 5xx
+// responses aren't kept, so those can be retried with the same key.
+//
+// Routes opt in by adding the middleware to a group, or to a single route.
+// In core these are the media writes and POST /register:
+//
+//	media := router.Group("", middleware.Idempotency(nil))
+//	r.POST("/register", c.Register, middleware.Idempotency(nil))
+func Idempotency(config *IdempotencyConfig) router.MiddlewareFunc {
+	if config == nil {
+		config = DefaultIdempotencyConfig()
+	}
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			switch c.Request.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+			idempotencyKey := c.GetHeader(IdempotencyHeader)
+			if idempotencyKey == "" {
+				return next(c)
+			}
+			if len(idempotencyKey) > maxIdempotencyKey {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "Idempotency-Key is too long",
+				})
+			}
+
+			store := config.Store
+			if store == nil {
+				store = cache.Default()
+			}
+
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				if c.BodyTooLarge(err) {
+					return nil
+				}
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "Failed to read request body",
+				})
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+			pending := idempotentResponse{Fingerprint: requestFingerprint(c, body)}
+			key := idempotencyStoreKey(c, idempotencyKey)
+
+			value, _ := json.Marshal(&pending)
+			added, err := store.Add(c, key, value, config.TTL)
+			if err != nil {
+				logger.FromContext(c).Warn("Idempotency store unavailable", logger.String("error", err.Error()))
+				return next(c)
+			}
+			if !added {
+				return replayIdempotent(c, store, key, pending.Fingerprint)
+			}
+
+			before := c.Writer.Header().Clone()
+			recorder := &cacheRecorder{ResponseWriter: c.Writer, status: http.StatusOK}
+			c.Writer = recorder
+			err = next(c)
+			c.Writer = recorder.ResponseWriter
+			if recorder.hijacked {
+				return err
+			}
+
+			// Server errors and handler failures may succeed on retry
+			if err != nil || !recorder.headerWritten || recorder.status >= 500 {
+				if delErr := store.Delete(c, key); delErr != nil {
+					logger.FromContext(c).Warn("Failed to release idempotency key", logger.String("error", delErr.Error()))
+				}
+			} else {
+				done := idempotentResponse{
+					Fingerprint: pending.Fingerprint,
+					Status:      recorder.status,
+					Header:      handlerHeaders(before, c.Writer.Header()),
+					Body:        recorder.body.Bytes(),
+				}
+				value, _ := json.Marshal(&done)
+				if setErr := store.Set(c, key, value, config.TTL); setErr != nil {
+					logger.FromContext(c).Warn("Failed to store idempotent response", logger.String("error", setErr.Error()))
+				}
+			}
+
+			if !recorder.headerWritten {
+				return err
+			}
+			c.Writer.WriteHeader(recorder.status)
+			if _, writeErr := c.Writer.Write(recorder.body.Bytes()); err == nil {
+				err = writeErr
+			}
+			return err
+		}
+	}
+}
+
+// replayIdempotent answers a request whose key is already taken
+func replayIdempotent(c *router.Context, store cache.Store, key, fingerprint string) error {
+	value, ok, err := store.Get(c, key)
+	var previous idempotentResponse
+	if err != nil || !ok || json.Unmarshal(value, &previous) != nil {
+		// Released between Add and Get; the client can simply retry
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "A request with this Idempotency-Key is in progress",
+		})
+	}
+
+	if previous.Fingerprint != fingerprint {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "Idempotency-Key was already used with a different request",
+		})
+	}
+	if previous.Status == 0 {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "A request with this Idempotency-Key is in progress",
+		})
+	}
+
+	for name, values := range previous.Header {
+		c.Writer.Header()[name] = values
+	}
+	c.SetHeader(IdempotencyReplayedHeader, "true")
+	c.SetHeader("Content-Length", strconv.Itoa(len(previous.Body)))
+	c.Writer.WriteHeader(previous.Status)
+	_, err = c.Writer.Write(previous.Body)
+	return err
+}
+
+// handlerHeaders returns the headers of after that weren't already set in
+// before, i.e. those the handler set rather than the middleware around it,
+// which sets its own again on a replay
+func handlerHeaders(before, after http.Header) http.Header {
+	header := http.Header{}
+	for name, values := range after {
+		if name == "Content-Length" || slices.Equal(before[name], values) {
+			continue
+		}
+		header[name] = values
+	}
+	return header
+}
+
+// requestFingerprint hashes the body, ignoring the multipart boundary that
+// clients pick at random for every attempt
+func requestFingerprint(c *router.Context, body []byte) string {
+	if mediaType, params, err := mime.ParseMediaType(c.GetHeader("Content-Type")); err == nil &&
+		strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), nil)
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// idempotencyStoreKey scopes a client key to the route and caller so two
+// callers, or two endpoints, never share a response
+func idempotencyStoreKey(c *router.Context, idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(c.Request.Method + " " + c.Request.URL.Path + "\x00" + cacheIdentity(c) + "\x00" + idempotencyKey))
+	return "idempotency:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"base/core/cache"
+	"base/core/router"
+)
+
+// idempotentRouter counts handler calls to POST /orders. A "fail" body
+// answers 500 and a "wait" body blocks until release is closed.
+func idempotentRouter(release chan struct{}) (*router.Router, *atomic.Int32) {
+	calls := new(atomic.Int32)
+	r := router.New()
+	r.Use(func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			c.SetHeader("X-Request-Id", c.GetHeader("X-Attempt"))
+			return next(c)
+		}
+	})
+	config := &IdempotencyConfig{TTL: time.Minute, Store: cache.NewMemoryStore(0)}
+	r.POST("/orders", func(c *router.Context) error {
+		call := calls.Add(1)
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		switch string(body) {
+		case "fail":
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "try again"})
+		case "wait":
+			<-release
+		}
+		c.SetHeader("Location", "/orders/"+strconv.Itoa(int(call)))
+		return c.JSON(http.StatusCreated, map[string]any{"id": call})
+	}, Idempotency(config))
+	return r, calls
+}
+
+func postOrder(r *router.Router, key, body, attempt string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set(IdempotencyHeader, key)
+	req.Header.Set("X-Attempt", attempt)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	r, calls := idempotentRouter(nil)
+
+	first := postOrder(r, "key-1", `{"item":1}`, "a")
+	retry := postOrder(r, "key-1", `{"item":1}`, "b")
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls.Load())
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", retry.Code, retry.Body.String(), first.Code, first.Body.String())
+	}
+	if retry.Header().Get(IdempotencyReplayedHeader) != "true" || first.Header().Get(IdempotencyReplayedHeader) != "" {
+		t.Errorf("Idempotent-Replayed = %q on the replay, %q on the first response",
+			retry.Header().Get(IdempotencyReplayedHeader), first.Header().Get(IdempotencyReplayedHeader))
+	}
+	for _, name := range []string{"Location", "Content-Type"} {
+		if got, want := retry.Header().Get(name), first.Header().Get(name); got != want || got == "" {
+			t.Errorf("replayed %s = %q, want %q", name, got, want)
+		}
+	}
+	// Headers of the middleware around the handler belong to the retry
+	if got := retry.Header().Get("X-Request-Id"); got != "b" {
+		t.Errorf("replayed X-Request-Id = %q, want the retry's", got)
+	}
+
+	// Another key runs the handler again
+	if other := postOrder(r, "key-2", `{"item":1}`, "c"); other.Header().Get("Location") != "/orders/2" {
+		t.Errorf("new key Location = %q", other.Header().Get("Location"))
+	}
+}
+
+func TestIdempotencyRejectsDifferentBody(t *testing.T) {
+	r, calls := idempotentRouter(nil)
+
+	postOrder(r, "key-1", `{"item":1}`, "a")
+	w := postOrder(r, "key-1", `{"item":2}`, "b")
+	if w.Code != http.StatusConflict || calls.Load() != 1 {
+		t.Errorf("reusing a key with another body = %d after %d calls, want 409 after 1", w.Code, calls.Load())
+	}
+}
+
+func TestIdempotencyRejectsWhileInProgress(t *testing.T) {
+	release := make(chan struct{})
+	r, calls := idempotentRouter(release)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postOrder(r, "key-1", "wait", "a") }()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if w := postOrder(r, "key-1", "wait", "b"); w.Code != http.StatusConflict {
+		t.Errorf("retry while running = %d, want 409", w.Code)
+	}
+	close(release)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first request = %d, want 201", first.Code)
+	}
+	if w := postOrder(r, "key-1", "wait", "c"); w.Code != http.StatusCreated || w.Header().Get(IdempotencyReplayedHeader) != "true" {
+		t.Errorf("retry after completion = %d, want a replayed 201", w.Code)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want 1", calls.Load())
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterServerError(t *testing.T) {
+	r, calls := idempotentRouter(nil)
+
+	for i := 1; i <= 2; i++ {
+		w := postOrder(r, "key-1", "fail", strconv.Itoa(i))
+		if w.Code != http.StatusInternalServerError || w.Header().Get(IdempotencyReplayedHeader) != "" {
+			t.Errorf("attempt %d = %d replayed %q, want a fresh 500", i, w.Code, w.Header().Get(IdempotencyReplayedHeader))
+		}
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler ran %d times, want 2", calls.Load())
+	}
+}
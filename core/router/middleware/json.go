@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"base/core/router"
+)
+
+// ContentTypesKey lists the media types a route group accepts besides JSON:
+//
+//	uploads := api.Group("").Set(middleware.ContentTypesKey, []string{"multipart/form-data"})
+//	uploads.POST("/media", h.Create)
+const ContentTypesKey = "content_types"
+
+// RequireJSON rejects requests whose body isn't JSON with 415, so handlers
+// behind it never see a form or an unlabeled body. Requests without a body
+// pass, and application/json or any +json type is accepted, as are the
+// group's ContentTypesKey types.
+func RequireJSON() router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			if c.Request.Body == nil || c.Request.Body == http.NoBody || c.Request.ContentLength == 0 {
+				return next(c)
+			}
+
+			mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+			if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+				return next(c)
+			}
+			if allowed, ok := c.Get(ContentTypesKey); ok {
+				if types, ok := allowed.([]string); ok && slices.Contains(types, mediaType) {
+					return next(c)
+				}
+			}
+
+			if mediaType == "" {
+				return c.Fail(http.StatusUnsupportedMediaType, "unsupported_media_type",
+					"Content-Type is required, expected application/json")
+			}
+			return c.Fail(http.StatusUnsupportedMediaType, "unsupported_media_type",
+				"Content-Type "+mediaType+" is not supported, expected application/json")
+		}
+	}
+}
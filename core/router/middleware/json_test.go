@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"base/core/router"
+)
+
+func TestRequireJSON(t *testing.T) {
+	r := router.New()
+	api := r.Group("/api", RequireJSON())
+	api.POST("/items", func(c *router.Context) error { return c.NoContent() })
+	api.DELETE("/items", func(c *router.Context) error { return c.NoContent() })
+	api.Group("").Set(ContentTypesKey, []string{"multipart/form-data"}).
+		POST("/uploads", func(c *router.Context) error { return c.NoContent() })
+
+	send := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"JSON", http.MethodPost, "/api/items", "application/json", `{}`, http.StatusNoContent},
+		{"JSON with a charset", http.MethodPost, "/api/items", "application/json; charset=utf-8", `{}`, http.StatusNoContent},
+		{"a +json type", http.MethodPost, "/api/items", "application/merge-patch+json", `{}`, http.StatusNoContent},
+		{"no body", http.MethodDelete, "/api/items", "", "", http.StatusNoContent},
+		{"missing Content-Type", http.MethodPost, "/api/items", "", `{}`, http.StatusUnsupportedMediaType},
+		{"a form", http.MethodPost, "/api/items", "application/x-www-form-urlencoded", "a=1", http.StatusUnsupportedMediaType},
+		{"plain text", http.MethodPost, "/api/items", "text/plain", "hi", http.StatusUnsupportedMediaType},
+		{"a form the group allows", http.MethodPost, "/api/uploads", "multipart/form-data; boundary=x", "--x--", http.StatusNoContent},
+		{"JSON where forms are allowed", http.MethodPost, "/api/uploads", "application/json", `{}`, http.StatusNoContent},
+		{"a form type the group doesn't allow", http.MethodPost, "/api/uploads", "application/x-www-form-urlencoded", "a=1", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		w := send(tt.method, tt.path, tt.contentType, tt.body)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+		if tt.status == http.StatusUnsupportedMediaType && !strings.Contains(w.Body.String(), "unsupported_media_type") {
+			t.Errorf("%s: body = %q, want the unsupported_media_type code", tt.name, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"base/core/router"
+	"base/core/types"
+)
+
+// LanguageCookie remembers the language a visitor chose
+const LanguageCookie = "lang"
+
+// languageCookieMaxAge is how long a language choice is remembered
+const languageCookieMaxAge = 365 * 24 * time.Hour
+
+// Language stores the request language in the context under "language", and
+// the translator under "translator". The language comes from, in order, the
+// ?lang= query parameter, the lang cookie, the Accept-Language header and
+// then fallback; only languages in supported are accepted, or any language
+// when supported is empty. A region falls back to its base language, so
+// pt-BR matches a supported pt.
+//
+// The choice is kept in the lang cookie when it was made with ?lang= or the
+// request is a page load (Accept: text/html), so it sticks across visits.
+// JSON clients get no cookie, which keeps their responses cacheable.
+func Language(supported []string, fallback string) router.MiddlewareFunc {
+	fallback = strings.ToLower(fallback)
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			var saved string
+			if cookie, err := c.Cookie(LanguageCookie); err == nil {
+				saved = matchLanguage(cookie.Value, supported)
+			}
+
+			explicit := matchLanguage(c.Query("lang"), supported)
+			language := explicit
+			if language == "" {
+				language = saved
+			}
+			if language == "" {
+				language = negotiateLanguage(c.GetHeader("Accept-Language"), supported)
+			}
+			if language == "" {
+				language = fallback
+			}
+
+			if language != saved && (explicit != "" || strings.Contains(c.GetHeader("Accept"), "text/html")) {
+				setLanguageCookie(c, language)
+			}
+
+			c.Set("language", language)
+			if translator := types.GetTranslator(); translator != nil {
+				c.Set("translator", translator)
+			}
+			return next(c)
+		}
+	}
+}
+
+// SetLanguage handles POST /set-language, switching the visitor's language
+// explicitly. It takes {"language": "fr"} as JSON or a language form field.
+// Form posts are redirected to the local path in the redirect field, if
+// any, so a plain HTML form can switch language and return to the page.
+func SetLanguage(supported []string) router.HandlerFunc {
+	return func(c *router.Context) error {
+		var request struct {
+			Language string `json:"language"`
+			Redirect string `json:"redirect"`
+		}
+		if strings.HasPrefix(c.GetHeader("Content-Type"), "application/json") {
+			if err := c.BindJSON(&request); err != nil {
+				return c.JSON(http.StatusBadRequest, types.NewErrorResponse(c, "errors.invalid_body"))
+			}
+		} else {
+			request.Language = c.FormValue("language")
+			request.Redirect = c.FormValue("redirect")
+		}
+
+		language := matchLanguage(request.Language, supported)
+		if language == "" {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error":     "Unsupported language",
+				"supported": supported,
+			})
+		}
+
+		setLanguageCookie(c, language)
+		c.Set("language", language)
+
+		if localRedirect(request.Redirect) {
+			c.SetHeader("Location", request.Redirect)
+			c.Status(http.StatusSeeOther)
+			return nil
+		}
+		return c.JSON(http.StatusOK, map[string]string{"language": language})
+	}
+}
+
+// localRedirect reports whether target is a path on this site. Browsers read
+// "//host" and "/\host" as other hosts, so neither is accepted; url.Parse
+// rejects the control characters they would strip to produce one.
+func localRedirect(target string) bool {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return false
+	}
+	u, err := url.Parse(target)
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
+
+func setLanguageCookie(c *router.Context, language string) {
+	c.SetCookie(&http.Cookie{
+		Name:     LanguageCookie,
+		Value:    language,
+		Path:     "/",
+		MaxAge:   int(languageCookieMaxAge.Seconds()),
+		HttpOnly: true,
+	})
+}
+
+// matchLanguage returns the supported language tag matches, lowercased, or
+// "" when there's none. A region matches its base language, and any well
+// formed tag matches when supported is empty.
+func matchLanguage(tag string, supported []string) string {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if tag == "" || len(tag) > 35 || strings.Trim(tag, "abcdefghijklmnopqrstuvwxyz0123456789-") != "" {
+		return ""
+	}
+	if len(supported) == 0 {
+		return tag
+	}
+
+	for {
+		for _, language := range supported {
+			if strings.EqualFold(language, tag) {
+				return strings.ToLower(language)
+			}
+		}
+		i := strings.LastIndex(tag, "-")
+		if i < 0 {
+			return ""
+		}
+		tag = tag[:i]
+	}
+}
+
+// negotiateLanguage returns the supported language of the highest priority
+// Accept-Language entry, e.g. "pt-BR,pt;q=0.9,en;q=0.8" -> "pt-br"
+func negotiateLanguage(header string, supported []string) string {
+	best := ""
+	bestQ := 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		if language := matchLanguage(tag, supported); language != "" {
+			best, bestQ = language, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"base/core/router"
+)
+
+func TestLocalRedirect(t *testing.T) {
+	tests := map[string]bool{
+		"/":                    true,
+		"/settings?tab=lang":   true,
+		"/fr/docs#intro":       true,
+		"":                     false,
+		"settings":             false,
+		"//evil.example.com":   false,
+		"/\\evil.example.com":  false,
+		"/\t/evil.example.com": false,
+		"https://example.com/": false,
+		"javascript:alert(1)":  false,
+	}
+	for target, want := range tests {
+		if got := localRedirect(target); got != want {
+			t.Errorf("localRedirect(%q) = %v, want %v", target, got, want)
+		}
+	}
+}
+
+func TestSetLanguageRedirect(t *testing.T) {
+	r := router.New()
+	r.POST("/set-language", SetLanguage([]string{"en", "fr"}))
+
+	post := func(redirect string) *httptest.ResponseRecorder {
+		form := url.Values{"language": {"fr"}, "redirect": {redirect}}
+		req := httptest.NewRequest(http.MethodPost, "/set-language", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/account")
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/account" {
+		t.Errorf("local redirect: status %d, Location %q", w.Code, w.Header().Get("Location"))
+	}
+
+	w = post("/\\evil.example.com")
+	if w.Code != http.StatusOK || w.Header().Get("Location") != "" {
+		t.Errorf("backslash redirect: status %d, Location %q", w.Code, w.Header().Get("Location"))
+	}
+}
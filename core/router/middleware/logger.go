@@ -55,6 +55,15 @@ func Logger(config *LoggerConfig) router.MiddlewareFunc {
 			path := c.Request.URL.Path
 			raw := c.Request.URL.RawQuery
 
+			// Share a request-scoped child logger with handlers
+			requestId, _ := c.Get("request_id")
+			requestLogger := config.Logger.With(
+				logger.String("method", c.Request.Method),
+				logger.String("path", path),
+				logger.Any("request_id", requestId),
+			)
+			c.WithContext(logger.IntoContext(c.Context(), requestLogger))
+
 			// Process request
 			err := next(c)
 
@@ -66,8 +75,6 @@ func Logger(config *LoggerConfig) router.MiddlewareFunc {
 
 			// Build log fields
 			fields := []logger.Field{
-				logger.String("method", c.Request.Method),
-				logger.String("path", path),
 				logger.Int("status", status),
 				logger.Duration("latency", latency),
 				logger.String("ip", c.ClientIP()),
@@ -75,9 +82,10 @@ func Logger(config *LoggerConfig) router.MiddlewareFunc {
 			}
 
 			if raw != "" {
-				fields = append(fields, logger.String("query", raw))
+				fields = append(fields, logger.String("query", logger.RedactQuery(raw)))
 			}
 
+			// Redact masks credentials in the headers
 			if config.IncludeHeaders {
 				headers := make(map[string][]string)
 				for k, v := range c.Request.Header {
@@ -93,13 +101,13 @@ func Logger(config *LoggerConfig) router.MiddlewareFunc {
 			// Log based on status code
 			switch {
 			case status >= 500:
-				config.Logger.Error("Server error", fields...)
+				requestLogger.Error("Server error", fields...)
 			case status >= 400:
-				config.Logger.Warn("Client error", fields...)
+				requestLogger.Warn("Client error", fields...)
 			case status >= 300:
-				config.Logger.Info("Redirect", fields...)
+				requestLogger.Info("Redirect", fields...)
 			default:
-				config.Logger.Info("Request", fields...)
+				requestLogger.Info("Request", fields...)
 			}
 
 			return err
@@ -194,24 +202,31 @@ func replaceToken(format, token, value string) string {
 	return strings.ReplaceAll(format, token, value)
 }
 
-// Metrics creates metrics collection middleware
+// Metrics creates metrics collection middleware. Requests are labelled by the
+// matched route pattern rather than the concrete path so ids don't create a
+// series per resource.
 func Metrics(collector MetricsCollector) router.MiddlewareFunc {
+	inFlight, _ := collector.(InFlightCollector)
+
 	return func(next router.HandlerFunc) router.HandlerFunc {
 		return func(c *router.Context) error {
 			start := time.Now()
+			route := c.FullPath()
+			if inFlight != nil {
+				inFlight.RequestStarted(c.Request.Method, route)
+			}
 
-			// Process request
-			err := next(c)
-
-			// Collect metrics
-			collector.RecordRequest(
-				c.Request.Method,
-				c.Request.URL.Path,
-				c.Writer.Status(),
-				time.Since(start),
-			)
+			// Collect metrics even if a handler panics so in-flight counts stay balanced
+			defer func() {
+				collector.RecordRequest(
+					c.Request.Method,
+					route,
+					c.Writer.Status(),
+					time.Since(start),
+				)
+			}()
 
-			return err
+			return next(c)
 		}
 	}
 }
@@ -221,6 +236,12 @@ type MetricsCollector interface {
 	RecordRequest(method, path string, status int, duration time.Duration)
 }
 
+// InFlightCollector is implemented by collectors that track requests in progress.
+// RecordRequest marks the end of a request started with RequestStarted.
+type InFlightCollector interface {
+	RequestStarted(method, path string)
+}
+
 // SimpleMetricsCollector is a simple in-memory metrics collector
 type SimpleMetricsCollector struct {
 	requests map[string]*RequestMetrics
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"base/core/logger"
+	"base/core/router"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggerRedactsCredentials(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	r := router.New()
+	r.Use(Logger(&LoggerConfig{Logger: logger.NewLoggerFromZap(zap.New(core)), IncludeHeaders: true}))
+	r.GET("/reset", func(c *router.Context) error {
+		return c.JSON(http.StatusOK, nil)
+	})
+
+	request(r, http.MethodGet, "/reset?email=ada%40example.com&token=s3cret", http.Header{
+		"Authorization": {"Bearer abc"},
+		"Cookie":        {"session=xyz"},
+		"Accept":        {"application/json"},
+	})
+
+	entries := logs.FilterMessage("Request").All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d requests, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if want := "email=ada%40example.com&token=" + logger.Redacted; fields["query"] != want {
+		t.Errorf("query = %v, want %s", fields["query"], want)
+	}
+	headers, _ := fields["headers"].(map[string][]string)
+	for _, name := range []string{"Authorization", "Cookie"} {
+		if got := headers[name]; len(got) != 1 || got[0] != logger.Redacted {
+			t.Errorf("%s header logged as %v", name, got)
+		}
+	}
+	if got := headers["Accept"]; len(got) != 1 || got[0] != "application/json" {
+		t.Errorf("Accept header logged as %v", got)
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+// OrgHeader is the request header selecting the organization a request acts on
+const OrgHeader = "Base-Orgid"
+
+// OrganizationContext resolves the Base-Orgid header once per request. It must
+// run after authentication: the user stored under "user_id" has to be a member
+// of the organization, otherwise the request is rejected with 403. The
+// organization id and membership are then available via c.OrgID() and
+// c.OrgMembership(). Requests without the header pass through unscoped.
+func OrganizationContext(db *gorm.DB) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			header := c.GetHeader(OrgHeader)
+			if header == "" {
+				return next(c)
+			}
+
+			orgID, err := strconv.ParseUint(header, 10, 64)
+			if err != nil || orgID == 0 {
+				c.AbortWithStatusJSON(http.StatusBadRequest, map[string]string{
+					"error": "Invalid organization id",
+				})
+				return nil
+			}
+
+			userID := c.GetUint("user_id")
+			if userID == 0 {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, map[string]string{
+					"error": "Authentication required",
+				})
+				return nil
+			}
+
+			var membership router.OrgMembership
+			err = db.Table("organization_members").
+				Select("id, organization_id, user_id, role_id, is_owner").
+				Where("user_id = ? AND organization_id = ?", userID, orgID).
+				Take(&membership).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.AbortWithStatusJSON(http.StatusForbidden, map[string]string{
+					"error": "Not a member of this organization",
+				})
+				return nil
+			}
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, map[string]string{
+					"error": "Failed to resolve organization membership",
+				})
+				return nil
+			}
+
+			c.Set(router.OrgIDKey, orgID)
+			c.Set(router.OrgMembershipKey, &membership)
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"base/core/router"
+)
+
+// TimeoutKey overrides the deadline, a time.Duration, for a route group; 0
+// sets none. Routes that stream their response, such as exports and file
+// downloads, need it: the deadline would cancel their queries after the 200
+// is sent, truncating the body with no error the client can see.
+//
+//	streams := api.Group("").Set(middleware.TimeoutKey, time.Duration(0))
+const TimeoutKey = "request_timeout"
+
+// Timeout gives each request's context a deadline of d. Services run their
+// queries with that context (db.WithContext(ctx.Context())), so a query is
+// canceled when the deadline passes or the client disconnects, and the
+// handler answers with ctx.Canceled. A d of 0 or less sets no deadline;
+// client disconnects still cancel. Event streams are long-lived, so they get
+// no deadline either.
+func Timeout(d time.Duration) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			d := d
+			if override, ok := c.Get(TimeoutKey); ok {
+				if n, ok := override.(time.Duration); ok {
+					d = n
+				}
+			}
+			if d <= 0 || c.IsEventStream() {
+				return next(c)
+			}
+
+			ctx, cancel := context.WithTimeout(c.Context(), d)
+			defer cancel()
+			c.WithContext(ctx)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"base/core/router"
+)
+
+func TestTimeoutSkipsStreams(t *testing.T) {
+	r := router.New()
+	r.Use(Timeout(time.Minute))
+	deadline := func(c *router.Context) error {
+		_, ok := c.Context().Deadline()
+		return c.String(http.StatusOK, "%t", ok)
+	}
+	r.GET("/items", deadline)
+	r.Group("/streams").Set(TimeoutKey, time.Duration(0)).GET("/jobs", deadline)
+
+	tests := []struct {
+		path   string
+		header http.Header
+		want   string
+	}{
+		{"/items", nil, "true"},
+		{"/items", http.Header{"Accept": {"text/event-stream"}}, "false"},
+		{"/streams/jobs", nil, "false"},
+	}
+	for _, tt := range tests {
+		if got := request(r, http.MethodGet, tt.path, tt.header).Body.String(); got != tt.want {
+			t.Errorf("%s with %v: deadline set = %s, want %s", tt.path, tt.header, got, tt.want)
+		}
+	}
+}
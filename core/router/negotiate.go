@@ -0,0 +1,197 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Encoder serializes v to w for a negotiated response
+type Encoder func(w io.Writer, v any) error
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{
+		"application/json":        encodeJSON,
+		"application/xml":         encodeXML,
+		"text/xml":                encodeXML,
+		"application/msgpack":     encodeMsgpack,
+		"application/x-msgpack":   encodeMsgpack,
+		"application/vnd.msgpack": encodeMsgpack,
+	}
+)
+
+// RegisterEncoder adds or replaces the encoder used by Negotiate for mediaType
+func RegisterEncoder(mediaType string, enc Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[strings.ToLower(mediaType)] = enc
+}
+
+// Negotiate responds with data in the format preferred by the Accept header,
+// falling back to JSON when nothing acceptable is registered
+func (c *Context) Negotiate(code int, data any) error {
+	mediaType, enc := c.negotiate()
+	c.SetHeader("Content-Type", mediaType)
+	c.SetHeader("Vary", "Accept")
+	c.Writer.WriteHeader(code)
+	return enc(c.Writer, data)
+}
+
+// negotiate picks the registered media type with the highest Accept quality
+func (c *Context) negotiate() (string, Encoder) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	for _, mediaType := range parseAccept(c.Request.Header.Get("Accept")) {
+		if enc, ok := encoders[mediaType]; ok {
+			return mediaType, enc
+		}
+		if mediaType == "*/*" || mediaType == "application/*" {
+			break
+		}
+	}
+	return "application/json", encoders["application/json"]
+}
+
+// parseAccept returns the media types in an Accept header ordered by quality,
+// keeping header order for ties and dropping q=0 entries
+func parseAccept(header string) []string {
+	type accepted struct {
+		mediaType string
+		q         float64
+	}
+
+	var types []accepted
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		types = append(types, accepted{mediaType, q})
+	}
+	sort.SliceStable(types, func(i, j int) bool { return types[i].q > types[j].q })
+
+	result := make([]string, len(types))
+	for i, t := range types {
+		result[i] = t.mediaType
+	}
+	return result
+}
+
+func encodeJSON(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// encodeMsgpack uses json tags so field names match the JSON responses
+func encodeMsgpack(w io.Writer, v any) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc.Encode(v)
+}
+
+// encodeXML writes v under a <response> root. encoding/xml cannot marshal
+// maps or interface fields, so v is first normalized through its JSON form:
+// objects become child elements, arrays repeat <item> and null is empty.
+func encodeXML(w io.Writer, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var generic any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := writeXMLElement(enc, "response", generic); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func writeXMLElement(enc *xml.Encoder, name string, v any) error {
+	start := xml.StartElement{Name: xml.Name{Local: xmlName(name)}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch value := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := writeXMLElement(enc, k, value[k]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range value {
+			if err := writeXMLElement(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(xmlText(value))); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+func xmlText(v any) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	return ""
+}
+
+// xmlName replaces characters that are not valid in an XML element name
+func xmlName(name string) string {
+	if name == "" {
+		return "item"
+	}
+	var b strings.Builder
+	for i, r := range name {
+		valid := r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' ||
+			i > 0 && (r == '-' || r == '.' || r >= '0' && r <= '9')
+		if valid {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
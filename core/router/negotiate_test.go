@@ -0,0 +1,101 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type item struct {
+	Id   uint     `json:"id"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func negotiated(accept string) *httptest.ResponseRecorder {
+	r := New()
+	r.GET("/", func(c *Context) error {
+		return c.Negotiate(http.StatusOK, item{Id: 1, Name: "Ada & co", Tags: []string{"a", "b"}})
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestNegotiate(t *testing.T) {
+	const (
+		jsonBody = `{"id":1,"name":"Ada \u0026 co","tags":["a","b"]}` + "\n"
+		xmlBody  = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+			`<response><id>1</id><name>Ada &amp; co</name><tags><item>a</item><item>b</item></tags></response>`
+	)
+
+	tests := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", "application/json", jsonBody},
+		{"application/json", "application/json", jsonBody},
+		{"*/*", "application/json", jsonBody},
+		{"text/html", "application/json", jsonBody},
+		{"application/xml", "application/xml", xmlBody},
+		{"text/xml", "text/xml", xmlBody},
+		{"text/html, application/xml;q=0.9, */*;q=0.8", "application/xml", xmlBody},
+		{"application/xml;q=0.5, application/json", "application/json", jsonBody},
+		{"application/xml;q=0, application/json;q=0.1", "application/json", jsonBody},
+	}
+	for _, tt := range tests {
+		w := negotiated(tt.accept)
+		if got := w.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("Accept %q: Content-Type = %q, want %q", tt.accept, got, tt.contentType)
+		}
+		if got := w.Body.String(); got != tt.body {
+			t.Errorf("Accept %q: body = %q, want %q", tt.accept, got, tt.body)
+		}
+		if w.Header().Get("Vary") != "Accept" {
+			t.Errorf("Accept %q: missing Vary: Accept", tt.accept)
+		}
+	}
+}
+
+func TestNegotiateMsgpack(t *testing.T) {
+	for _, accept := range []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"} {
+		w := negotiated(accept)
+		if got := w.Header().Get("Content-Type"); got != accept {
+			t.Errorf("Accept %q: Content-Type = %q", accept, got)
+		}
+		var decoded map[string]any
+		if err := msgpack.Unmarshal(w.Body.Bytes(), &decoded); err != nil {
+			t.Fatalf("Accept %q: %v", accept, err)
+		}
+		// Field names follow the json tags
+		if decoded["name"] != "Ada & co" || len(decoded["tags"].([]any)) != 2 {
+			t.Errorf("Accept %q: decoded %v", accept, decoded)
+		}
+	}
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	RegisterEncoder("text/plain", func(w io.Writer, v any) error {
+		_, err := fmt.Fprintf(w, "%s", v.(item).Name)
+		return err
+	})
+	t.Cleanup(func() {
+		encodersMu.Lock()
+		delete(encoders, "text/plain")
+		encodersMu.Unlock()
+	})
+
+	w := negotiated("text/plain")
+	if w.Header().Get("Content-Type") != "text/plain" || w.Body.String() != "Ada & co" {
+		t.Errorf("got %q %q", w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
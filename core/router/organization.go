@@ -0,0 +1,39 @@
+package router
+
+// Context keys set by the organization context middleware
+const (
+	OrgIDKey         = "organization_id"
+	OrgMembershipKey = "org_membership"
+)
+
+// OrgMembership is the authenticated user's membership in the organization
+// selected by the Base-Orgid header
+type OrgMembership struct {
+	Id             uint
+	OrganizationId uint64
+	UserId         uint64
+	RoleId         string
+	IsOwner        bool
+}
+
+// OrgID returns the organization id resolved for this request, or 0 when the
+// request is not scoped to an organization
+func (c *Context) OrgID() uint64 {
+	value, exists := c.Get(OrgIDKey)
+	if !exists {
+		return 0
+	}
+	id, _ := value.(uint64)
+	return id
+}
+
+// OrgMembership returns the user's membership in the request's organization,
+// or nil when the request is not scoped to an organization
+func (c *Context) OrgMembership() *OrgMembership {
+	value, exists := c.Get(OrgMembershipKey)
+	if !exists {
+		return nil
+	}
+	membership, _ := value.(*OrgMembership)
+	return membership
+}
@@ -0,0 +1,120 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// ParamUint returns the named path parameter parsed as an unsigned integer
+func (c *Context) ParamUint(name string) (uint64, error) {
+	value, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s parameter", name)
+	}
+	return value, nil
+}
+
+// ParamInt returns the named path parameter parsed as an integer
+func (c *Context) ParamInt(name string) (int64, error) {
+	value, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s parameter", name)
+	}
+	return value, nil
+}
+
+// MustParamUint is like ParamUint but responds with 400 when the parameter is
+// invalid. The handler should return immediately when ok is false:
+//
+//	id, ok := ctx.MustParamUint("id")
+//	if !ok {
+//		return nil
+//	}
+func (c *Context) MustParamUint(name string) (value uint64, ok bool) {
+	value, err := c.ParamUint(name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return 0, false
+	}
+	return value, true
+}
+
+// MustParamInt is like ParamInt but responds with 400 when the parameter is invalid
+func (c *Context) MustParamInt(name string) (value int64, ok bool) {
+	value, err := c.ParamInt(name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return 0, false
+	}
+	return value, true
+}
+
+// BindURI binds path parameters into the fields of obj tagged with `uri`:
+//
+//	var req struct {
+//		RoleID       uint64 `uri:"id"`
+//		PermissionID uint64 `uri:"permissionId"`
+//	}
+//	if err := ctx.BindURI(&req); err != nil { ... }
+func (c *Context) BindURI(obj any) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return errors.New("BindURI requires a pointer to a struct")
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("uri")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		raw := c.Param(name)
+		if raw == "" {
+			continue
+		}
+		if err := setParam(v.Field(i), raw); err != nil {
+			return fmt.Errorf("invalid %s parameter", name)
+		}
+	}
+	return nil
+}
+
+// setParam parses raw into a string, bool, integer or float field
+func setParam(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
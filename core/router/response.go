@@ -0,0 +1,186 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// Response envelope modes, selected with RESPONSE_ENVELOPE
+const (
+	// EnvelopeStandard wraps responses as {"success": true, "data": ...} and
+	// errors as {"success": false, "code": ..., "error": ...}
+	EnvelopeStandard = "standard"
+	// EnvelopeLegacy writes the bodies the migrated endpoints wrote before the
+	// envelope helpers existed: payloads unwrapped (or as {"data": ...} when
+	// passed through Wrapped) and errors as {"error": ..., "success": false}
+	EnvelopeLegacy = "legacy"
+)
+
+var legacyEnvelope atomic.Bool
+
+// SetEnvelope selects the response shape used by OK, Created, Fail and Paginated
+func SetEnvelope(mode string) {
+	legacyEnvelope.Store(mode == EnvelopeLegacy)
+}
+
+// legacyError matches the body of types.ErrorResponse, which router cannot import
+type legacyError struct {
+	Error   string `json:"error"`
+	Success bool   `json:"success"`
+}
+
+// wrapped is a payload that legacy responses wrapped as {"data": ...}
+type wrapped struct {
+	data any
+}
+
+// Wrapped marks data that the endpoint returned as {"data": ...} before it
+// used OK or Created, so legacy mode keeps writing that shape. The standard
+// envelope is the same either way.
+func Wrapped(data any) any {
+	return wrapped{data: data}
+}
+
+// Envelope is the standard response body
+type Envelope struct {
+	Success    bool      `json:"success"`
+	Data       any       `json:"data,omitempty"`
+	Code       string    `json:"code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Pagination *PageInfo `json:"pagination,omitempty"`
+}
+
+// PageInfo describes the page returned by Paginated
+type PageInfo struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// The helpers below honor the Accept header through Negotiate. In legacy mode
+// they keep the previous body shapes, so JSON responses are byte for byte as
+// before.
+
+// OK responds 200 with data in the response envelope; nil data responds {"success": true}
+func (c *Context) OK(data any) error {
+	return c.respond(http.StatusOK, data)
+}
+
+// Created responds 201 with data in the response envelope
+func (c *Context) Created(data any) error {
+	return c.respond(http.StatusCreated, data)
+}
+
+func (c *Context) respond(status int, data any) error {
+	w, isWrapped := data.(wrapped)
+	if isWrapped {
+		data = w.data
+	}
+
+	if legacyEnvelope.Load() {
+		switch {
+		case isWrapped:
+			return c.Negotiate(status, map[string]any{"data": data})
+		case data == nil:
+			return c.Negotiate(status, map[string]any{"success": true})
+		}
+		return c.Negotiate(status, data)
+	}
+	return c.Negotiate(status, Envelope{Success: true, Data: data})
+}
+
+// Fail responds with an error status, a machine-readable code (e.g.
+// "role_not_found") and a human-readable message
+func (c *Context) Fail(status int, code, message string) error {
+	if legacyEnvelope.Load() {
+		return c.Negotiate(status, legacyError{Error: message})
+	}
+	return c.Negotiate(status, Envelope{Success: false, Code: code, Error: message})
+}
+
+// StatusClientClosedRequest is the non-standard status, borrowed from nginx,
+// for requests the client abandoned before the response was written
+const StatusClientClosedRequest = 499
+
+// Canceled responds 503 when err comes from the request's deadline passing
+// and 499 when the client disconnected, and reports whether it responded.
+// Check it before answering a service error with a 500:
+//
+//	roles, err := service.GetRoles(ctx.Context(), orgId)
+//	if err != nil {
+//		if ctx.Canceled(err) {
+//			return nil
+//		}
+//		return ctx.Fail(http.StatusInternalServerError, "internal_error", "Failed to retrieve roles")
+//	}
+func (c *Context) Canceled(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	ctxErr := c.Context().Err()
+	switch {
+	case errors.Is(ctxErr, context.DeadlineExceeded), ctxErr == nil && errors.Is(err, context.DeadlineExceeded):
+		c.Fail(http.StatusServiceUnavailable, "timeout", "The request took too long")
+	case errors.Is(ctxErr, context.Canceled), ctxErr == nil && errors.Is(err, context.Canceled):
+		c.Fail(StatusClientClosedRequest, "client_closed_request", "The client closed the request")
+	default:
+		return false
+	}
+	return true
+}
+
+// BodyTooLarge responds 413 when err comes from reading the request body past
+// its limit (see middleware.BodyLimit), and reports whether it responded:
+//
+//	file, err := ctx.FormFile("file")
+//	if err != nil {
+//		if ctx.BodyTooLarge(err) {
+//			return nil
+//		}
+//		return ctx.Fail(http.StatusBadRequest, "file_required", "file is required")
+//	}
+func (c *Context) BodyTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	c.Fail(http.StatusRequestEntityTooLarge, "body_too_large",
+		fmt.Sprintf("Request body is larger than %d bytes", tooLarge.Limit))
+	return true
+}
+
+// Paginated responds 200 with one page of items and its position in the full result
+func (c *Context) Paginated(items any, page, limit int, total int64) error {
+	totalPages := 0
+	if limit > 0 {
+		totalPages = int((total + int64(limit) - 1) / int64(limit))
+	}
+
+	if legacyEnvelope.Load() {
+		return c.Negotiate(http.StatusOK, map[string]any{
+			"data": items,
+			"pagination": map[string]any{
+				"total":       total,
+				"page":        page,
+				"page_size":   limit,
+				"total_pages": totalPages,
+			},
+		})
+	}
+
+	return c.Negotiate(http.StatusOK, Envelope{
+		Success: true,
+		Data:    items,
+		Pagination: &PageInfo{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	})
+}
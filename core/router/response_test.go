@@ -0,0 +1,146 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serve(t *testing.T, mode string, handler HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	return serveAccept(t, mode, "", handler)
+}
+
+func serveAccept(t *testing.T, mode, accept string, handler HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	SetEnvelope(mode)
+	t.Cleanup(func() { SetEnvelope(EnvelopeLegacy) })
+
+	r := New()
+	r.GET("/", handler)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+type role struct {
+	Id   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestLegacyEnvelopeMatchesPreviousBodies(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler HandlerFunc
+		status  int
+		body    string
+	}{
+		{
+			name:    "wrapped",
+			handler: func(c *Context) error { return c.OK(Wrapped(role{Id: 1, Name: "Admin"})) },
+			status:  http.StatusOK,
+			body:    `{"data":{"id":1,"name":"Admin"}}` + "\n",
+		},
+		{
+			name:    "created wrapped",
+			handler: func(c *Context) error { return c.Created(Wrapped(role{Id: 2, Name: "Member"})) },
+			status:  http.StatusCreated,
+			body:    `{"data":{"id":2,"name":"Member"}}` + "\n",
+		},
+		{
+			name:    "unwrapped",
+			handler: func(c *Context) error { return c.OK(map[string]any{"has_permission": true}) },
+			status:  http.StatusOK,
+			body:    `{"has_permission":true}` + "\n",
+		},
+		{
+			name:    "no data",
+			handler: func(c *Context) error { return c.OK(nil) },
+			status:  http.StatusOK,
+			body:    `{"success":true}` + "\n",
+		},
+		{
+			name:    "error",
+			handler: func(c *Context) error { return c.Fail(http.StatusNotFound, "role_not_found", "Role not found") },
+			status:  http.StatusNotFound,
+			body:    `{"error":"Role not found","success":false}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(t, EnvelopeLegacy, tt.handler)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Body.String(); got != tt.body {
+				t.Errorf("body = %q, want %q", got, tt.body)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+		})
+	}
+}
+
+func TestStandardEnvelope(t *testing.T) {
+	w := serve(t, EnvelopeStandard, func(c *Context) error {
+		return c.OK(Wrapped(role{Id: 1, Name: "Admin"}))
+	})
+	if want := `{"success":true,"data":{"id":1,"name":"Admin"}}` + "\n"; w.Body.String() != want {
+		t.Errorf("body = %q, want %q", w.Body.String(), want)
+	}
+
+	w = serve(t, EnvelopeStandard, func(c *Context) error {
+		return c.Fail(http.StatusForbidden, "system_role_unmodifiable", "System roles cannot be modified")
+	})
+	if want := `{"success":false,"code":"system_role_unmodifiable","error":"System roles cannot be modified"}` + "\n"; w.Body.String() != want {
+		t.Errorf("body = %q, want %q", w.Body.String(), want)
+	}
+}
+
+func TestPaginated(t *testing.T) {
+	w := serve(t, EnvelopeStandard, func(c *Context) error {
+		return c.Paginated([]int{1, 2}, 2, 2, 5)
+	})
+	want := `{"success":true,"data":[1,2],"pagination":{"page":2,"limit":2,"total":5,"total_pages":3}}` + "\n"
+	if w.Body.String() != want {
+		t.Errorf("body = %q, want %q", w.Body.String(), want)
+	}
+}
+
+// RESPONSE_ENVELOPE defaults to legacy, so negotiation has to work there too
+func TestLegacyEnvelopeNegotiates(t *testing.T) {
+	w := serveAccept(t, EnvelopeLegacy, "application/xml", func(c *Context) error {
+		return c.Fail(http.StatusNotFound, "role_not_found", "Role not found")
+	})
+	want := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<response><error>Role not found</error><success>false</success></response>`
+	if w.Code != http.StatusNotFound || w.Body.String() != want {
+		t.Errorf("XML error = %d %q, want 404 %q", w.Code, w.Body.String(), want)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/xml" {
+		t.Errorf("Content-Type = %q, want application/xml", got)
+	}
+
+	w = serveAccept(t, EnvelopeLegacy, "text/xml", func(c *Context) error {
+		return c.Paginated([]int{1, 2}, 1, 2, 3)
+	})
+	want = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<response><data><item>1</item><item>2</item></data>` +
+		`<pagination><page>1</page><page_size>2</page_size><total>3</total><total_pages>2</total_pages></pagination></response>`
+	if w.Body.String() != want {
+		t.Errorf("XML page = %q, want %q", w.Body.String(), want)
+	}
+
+	w = serveAccept(t, EnvelopeLegacy, "application/msgpack", func(c *Context) error {
+		return c.OK(Wrapped(role{Id: 1, Name: "Admin"}))
+	})
+	if got := w.Header().Get("Content-Type"); got != "application/msgpack" || w.Body.Len() == 0 || w.Body.Bytes()[0] == '{' {
+		t.Errorf("msgpack response: Content-Type %q, body %q", got, w.Body.String())
+	}
+}
@@ -72,6 +72,20 @@ func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
 	return http.ErrNotSupported
 }
 
+// headWriter discards the body a GET handler writes when answering a HEAD
+// request, keeping its status and headers
+type headWriter struct {
+	ResponseWriter
+}
+
+// Write drops data, reporting it as written
+func (w *headWriter) Write(data []byte) (int, error) {
+	if !w.Written() {
+		w.WriteHeader(http.StatusOK)
+	}
+	return len(data), nil
+}
+
 // ResponseWriter interface extends http.ResponseWriter
 type ResponseWriter interface {
 	http.ResponseWriter
@@ -1,26 +1,33 @@
 package router
 
 import (
+	"context"
+	"net"
 	"net/http"
-	"path"
+	"slices"
 	"strings"
 	"sync"
 )
 
 // Router is a lightweight HTTP router with middleware support
 type Router struct {
-	trees      map[string]*node // HTTP method -> route tree
-	middleware []MiddlewareFunc
-	notFound   HandlerFunc
-	pool       sync.Pool
-	mu         sync.RWMutex
+	trees            map[string]*node // HTTP method -> route tree
+	preflights       map[string]*node // HTTP method -> automatic OPTIONS handlers for that method's routes
+	preflightMethods []string         // methods in the order their first route was registered
+	middleware       []MiddlewareFunc
+	notFound         HandlerFunc
+	routes           []*Route
+	pool             sync.Pool
+	mu               sync.RWMutex
+	server           *http.Server
 }
 
 // New creates a new router
 func New() *Router {
 	r := &Router{
-		trees:    make(map[string]*node),
-		notFound: defaultNotFound,
+		trees:      make(map[string]*node),
+		preflights: make(map[string]*node),
+		notFound:   defaultNotFound,
 	}
 	r.pool.New = func() any {
 		return &Context{
@@ -43,42 +50,48 @@ func (r *Router) Use(middleware ...MiddlewareFunc) {
 }
 
 // GET registers a GET route
-func (r *Router) GET(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodGet, path, handler, middleware...)
+func (r *Router) GET(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodGet, path, handler, middleware...)
 }
 
 // POST registers a POST route
-func (r *Router) POST(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodPost, path, handler, middleware...)
+func (r *Router) POST(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodPost, path, handler, middleware...)
 }
 
 // PUT registers a PUT route
-func (r *Router) PUT(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodPut, path, handler, middleware...)
+func (r *Router) PUT(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodPut, path, handler, middleware...)
 }
 
 // DELETE registers a DELETE route
-func (r *Router) DELETE(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodDelete, path, handler, middleware...)
+func (r *Router) DELETE(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodDelete, path, handler, middleware...)
 }
 
 // PATCH registers a PATCH route
-func (r *Router) PATCH(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodPatch, path, handler, middleware...)
+func (r *Router) PATCH(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodPatch, path, handler, middleware...)
 }
 
 // HEAD registers a HEAD route
-func (r *Router) HEAD(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodHead, path, handler, middleware...)
+func (r *Router) HEAD(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodHead, path, handler, middleware...)
 }
 
 // OPTIONS registers an OPTIONS route
-func (r *Router) OPTIONS(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodOptions, path, handler, middleware...)
+func (r *Router) OPTIONS(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodOptions, path, handler, middleware...)
+}
+
+// Handle registers a route with the given method and path.
+// Middleware runs in a fixed order: global (Use) -> group -> route. Global
+// middleware must be added before routes are registered.
+func (r *Router) Handle(method, path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.handle(method, path, handler, nil, middleware)
 }
 
-// Handle registers a route with the given method and path
-func (r *Router) Handle(method, path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
+func (r *Router) handle(method, path string, handler HandlerFunc, values map[string]any, middleware []MiddlewareFunc) *Route {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -92,7 +105,49 @@ func (r *Router) Handle(method, path string, handler HandlerFunc, middleware ...
 		r.trees[method] = root
 	}
 
-	// Apply middleware in correct order: global -> route-specific
+	root.addRoute(path, r.chain(path, handler, values, middleware))
+
+	// Answer CORS preflights through the same middleware chain, so a group's
+	// CORS policy applies. Explicit OPTIONS routes are matched before these.
+	if method != http.MethodOptions {
+		r.addPreflight(method, path, r.chain(path, func(c *Context) error {
+			return c.NoContent()
+		}, values, middleware))
+	}
+
+	route := &Route{Method: method, Path: path}
+	r.routes = append(r.routes, route)
+	return route
+}
+
+// addPreflight registers an automatic OPTIONS handler for a method's route.
+// Each method keeps its own preflight tree receiving the same patterns as its
+// route tree, so patterns that only coexist in separate method trees (e.g.
+// "/users/:id" and "/users/:userId") never conflict.
+func (r *Router) addPreflight(method, path string, handler HandlerFunc) {
+	root := r.preflights[method]
+	if root == nil {
+		root = new(node)
+		r.preflights[method] = root
+		r.preflightMethods = append(r.preflightMethods, method)
+	}
+	root.addRoute(path, handler)
+}
+
+// preflight finds the automatic OPTIONS handler for path, taken from the
+// method registered first when several match. Callers hold r.mu.
+func (r *Router) preflight(path string) (HandlerFunc, Params) {
+	for _, method := range r.preflightMethods {
+		if handler, params, _ := r.preflights[method].getValue(path); handler != nil {
+			return handler, params
+		}
+	}
+	return nil, nil
+}
+
+// chain wraps handler in route middleware, then global middleware, and seeds
+// the context with the route pattern and group values before either runs
+func (r *Router) chain(path string, handler HandlerFunc, values map[string]any, middleware []MiddlewareFunc) HandlerFunc {
 	finalHandler := handler
 	for i := len(middleware) - 1; i >= 0; i-- {
 		finalHandler = middleware[i](finalHandler)
@@ -101,7 +156,26 @@ func (r *Router) Handle(method, path string, handler HandlerFunc, middleware ...
 		finalHandler = r.middleware[i](finalHandler)
 	}
 
-	root.addRoute(path, finalHandler)
+	matched := finalHandler
+	return func(c *Context) error {
+		c.fullPath = path
+		for key, value := range values {
+			c.Set(key, value)
+		}
+		return matched(c)
+	}
+}
+
+// Routes returns a snapshot of all registered routes in registration order
+func (r *Router) Routes() []Route {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	routes := make([]Route, len(r.routes))
+	for i, route := range r.routes {
+		routes[i] = *route
+	}
+	return routes
 }
 
 // Group creates a new route group with prefix
@@ -109,7 +183,7 @@ func (r *Router) Group(prefix string, middleware ...MiddlewareFunc) *RouterGroup
 	return &RouterGroup{
 		router:     r,
 		prefix:     prefix,
-		middleware: middleware,
+		middleware: append([]MiddlewareFunc(nil), middleware...),
 	}
 }
 
@@ -124,63 +198,107 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 // handleRequest processes the HTTP request
 func (r *Router) handleRequest(c *Context) {
-	r.mu.RLock()
-	root := r.trees[c.Request.Method]
-	r.mu.RUnlock()
+	// Normalize path: remove trailing slash except for root "/"
+	reqPath := c.Request.URL.Path
+	if len(reqPath) > 1 {
+		reqPath = strings.TrimSuffix(reqPath, "/")
+	}
+	method := c.Request.Method
 
-	if root != nil {
-		// Normalize path: remove trailing slash except for root "/"
-		reqPath := c.Request.URL.Path
-		if len(reqPath) > 1 {
-			reqPath = strings.TrimSuffix(reqPath, "/")
+	if handler, params := r.lookup(method, reqPath); handler != nil {
+		r.serve(c, handler, params)
+		return
+	}
+
+	// HEAD is answered by the GET route, without its body
+	if method == http.MethodHead {
+		if handler, params := r.lookup(http.MethodGet, reqPath); handler != nil {
+			c.Writer = &headWriter{ResponseWriter: c.Writer}
+			r.serve(c, handler, params)
+			return
 		}
+	}
 
-		if handler, params, _ := root.getValue(reqPath); handler != nil {
-			c.params = params
-			if err := handler(c); err != nil {
-				c.Error(http.StatusInternalServerError, err)
-			}
+	allowed := r.allowed(reqPath)
+
+	if method == http.MethodOptions {
+		r.mu.RLock()
+		handler, params := r.preflight(reqPath)
+		r.mu.RUnlock()
+		if handler != nil {
+			c.SetHeader("Allow", strings.Join(allowed, ", "))
+			r.serve(c, handler, params)
 			return
 		}
 	}
 
+	// The path exists, but not for this method
+	if len(allowed) > 0 {
+		c.SetHeader("Allow", strings.Join(allowed, ", "))
+		if err := c.String(http.StatusMethodNotAllowed, "405 method not allowed"); err != nil {
+			c.Error(http.StatusInternalServerError, err)
+		}
+		return
+	}
+
 	// Handle 404
 	if err := r.notFound(c); err != nil {
 		c.Error(http.StatusInternalServerError, err)
 	}
 }
 
-// NotFound sets the 404 handler
-func (r *Router) NotFound(handler HandlerFunc) {
-	r.notFound = handler
+// lookup finds the handler registered for method and path
+func (r *Router) lookup(method, path string) (HandlerFunc, Params) {
+	r.mu.RLock()
+	root := r.trees[method]
+	r.mu.RUnlock()
+	if root == nil {
+		return nil, nil
+	}
+	handler, params, _ := root.getValue(path)
+	return handler, params
 }
 
-// Static serves static files
-func (r *Router) Static(prefix, root string) {
-	// Ensure prefix starts with /
-	if !strings.HasPrefix(prefix, "/") {
-		prefix = "/" + prefix
+// serve runs a matched handler
+func (r *Router) serve(c *Context, handler HandlerFunc, params Params) {
+	c.params = params
+	if err := handler(c); err != nil {
+		c.Error(http.StatusInternalServerError, err)
 	}
+}
 
-	handler := func(c *Context) error {
-		reqPath := c.Request.URL.Path
-
-		// Remove the prefix
-		file := strings.TrimPrefix(reqPath, prefix)
-		file = strings.TrimPrefix(file, "/") // clean leading slash
+// allowed returns the methods path can be requested with, sorted, for the
+// Allow header; nil when no route matches it. GET routes also answer HEAD,
+// and every path with a route answers OPTIONS.
+func (r *Router) allowed(path string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-		if file == "" || strings.HasSuffix(reqPath, "/") {
-			file = "index.html"
+	var methods []string
+	for method, root := range r.trees {
+		if handler, _, _ := root.getValue(path); handler != nil {
+			methods = append(methods, method)
 		}
-
-		fullPath := path.Join(root, file)
-		http.ServeFile(c.Writer, c.Request, fullPath)
+	}
+	if len(methods) == 0 {
 		return nil
 	}
 
-	// register route with wildcard
-	r.GET(prefix+"/*filepath", handler)
-	r.GET(prefix, handler) // also serve the exact prefix URL
+	if slices.Contains(methods, http.MethodGet) && !slices.Contains(methods, http.MethodHead) {
+		methods = append(methods, http.MethodHead)
+	}
+	if !slices.Contains(methods, http.MethodOptions) {
+		if handler, _ := r.preflight(path); handler != nil {
+			methods = append(methods, http.MethodOptions)
+		}
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+// NotFound sets the 404 handler
+func (r *Router) NotFound(handler HandlerFunc) {
+	r.notFound = handler
 }
 
 // defaultNotFound is the default 404 handler
@@ -193,13 +311,51 @@ type RouterGroup struct {
 	router     *Router
 	prefix     string
 	middleware []MiddlewareFunc
+	values     map[string]any
+	security   []string
 }
 
-// Use adds middleware to the group
+// Use adds middleware to the group. It applies to routes registered on the
+// group, and groups created from it, after the call.
 func (g *RouterGroup) Use(middleware ...MiddlewareFunc) {
 	g.middleware = append(g.middleware, middleware...)
 }
 
+// Set stores a value in the context of every request to the group's routes
+// before any middleware runs, letting global middleware such as CORS read
+// group-specific settings. Like Use, it applies to routes registered afterwards.
+func (g *RouterGroup) Set(key string, value any) *RouterGroup {
+	values := make(map[string]any, len(g.values)+1)
+	for k, v := range g.values {
+		values[k] = v
+	}
+	values[key] = value
+	g.values = values
+	return g
+}
+
+// Security records the authentication schemes the group's middleware
+// requires, e.g. "BearerAuth" or "ApiKeyAuth", on routes registered
+// afterwards so the API documentation lists them. Sub-groups add to them.
+//
+//	api := router.Group("", middleware.BearerAuth()).Security("BearerAuth")
+func (g *RouterGroup) Security(schemes ...string) *RouterGroup {
+	security := make([]string, 0, len(g.security)+len(schemes))
+	security = append(security, g.security...)
+	for _, scheme := range schemes {
+		if !slices.Contains(security, scheme) {
+			security = append(security, scheme)
+		}
+	}
+	g.security = security
+	return g
+}
+
+// Router returns the router the group registers routes on
+func (g *RouterGroup) Router() *Router {
+	return g.router
+}
+
 // Group creates a sub-group
 func (g *RouterGroup) Group(prefix string, middleware ...MiddlewareFunc) *RouterGroup {
 	// Normalize path to avoid double slashes
@@ -210,45 +366,56 @@ func (g *RouterGroup) Group(prefix string, middleware ...MiddlewareFunc) *Router
 	// Clean up double slashes
 	normalizedPrefix = strings.ReplaceAll(normalizedPrefix, "//", "/")
 
+	// Copy so sibling groups never share (and overwrite) the parent's backing array
+	groupMiddleware := make([]MiddlewareFunc, 0, len(g.middleware)+len(middleware))
+	groupMiddleware = append(groupMiddleware, g.middleware...)
+	groupMiddleware = append(groupMiddleware, middleware...)
+
 	return &RouterGroup{
 		router:     g.router,
 		prefix:     normalizedPrefix,
-		middleware: append(g.middleware, middleware...),
+		middleware: groupMiddleware,
+		values:     g.values,
+		security:   g.security,
 	}
 }
 
 // GET registers a GET route in the group
-func (g *RouterGroup) GET(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	g.Handle(http.MethodGet, path, handler, middleware...)
+func (g *RouterGroup) GET(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return g.Handle(http.MethodGet, path, handler, middleware...)
 }
 
 // POST registers a POST route in the group
-func (g *RouterGroup) POST(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	g.Handle(http.MethodPost, path, handler, middleware...)
+func (g *RouterGroup) POST(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return g.Handle(http.MethodPost, path, handler, middleware...)
 }
 
 // PUT registers a PUT route in the group
-func (g *RouterGroup) PUT(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	g.Handle(http.MethodPut, path, handler, middleware...)
+func (g *RouterGroup) PUT(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return g.Handle(http.MethodPut, path, handler, middleware...)
 }
 
 // DELETE registers a DELETE route in the group
-func (g *RouterGroup) DELETE(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	g.Handle(http.MethodDelete, path, handler, middleware...)
+func (g *RouterGroup) DELETE(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return g.Handle(http.MethodDelete, path, handler, middleware...)
 }
 
 // PATCH registers a PATCH route in the group
-func (g *RouterGroup) PATCH(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	g.Handle(http.MethodPatch, path, handler, middleware...)
+func (g *RouterGroup) PATCH(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return g.Handle(http.MethodPatch, path, handler, middleware...)
 }
 
 // Handle registers a route in the group
-func (g *RouterGroup) Handle(method, path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
+func (g *RouterGroup) Handle(method, path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
 	finalPath := g.prefix + path
 	// Clean up double slashes
 	finalPath = strings.ReplaceAll(finalPath, "//", "/")
-	allMiddleware := append(g.middleware, middleware...)
-	g.router.Handle(method, finalPath, handler, allMiddleware...)
+	allMiddleware := make([]MiddlewareFunc, 0, len(g.middleware)+len(middleware))
+	allMiddleware = append(allMiddleware, g.middleware...)
+	allMiddleware = append(allMiddleware, middleware...)
+	route := g.router.handle(method, finalPath, handler, g.values, allMiddleware)
+	route.Security = g.security
+	return route
 }
 
 // Static serves static files for the group
@@ -256,6 +423,11 @@ func (g *RouterGroup) Static(relativePath, root string) {
 	g.router.Static(g.prefix+relativePath, root)
 }
 
+// StaticWithConfig serves static files for the group with the given configuration
+func (g *RouterGroup) StaticWithConfig(relativePath, root string, config StaticConfig) {
+	g.router.StaticWithConfig(g.prefix+relativePath, root, config)
+}
+
 // Run starts the HTTP server
 func (r *Router) Run(addr string) error {
 	if !strings.HasPrefix(addr, ":") {
@@ -267,5 +439,37 @@ func (r *Router) Run(addr string) error {
 		Handler: r,
 	}
 
+	r.mu.Lock()
+	r.server = server
+	r.mu.Unlock()
+
 	return server.ListenAndServe()
 }
+
+// Serve starts the HTTP server on an already bound listener, letting the
+// caller know the address is taken before any request is served
+func (r *Router) Serve(ln net.Listener) error {
+	server := &http.Server{
+		Addr:    ln.Addr().String(),
+		Handler: r,
+	}
+
+	r.mu.Lock()
+	r.server = server
+	r.mu.Unlock()
+
+	return server.Serve(ln)
+}
+
+// Shutdown stops the server started by Run or Serve, waiting for in-flight
+// requests until ctx is done. Run or Serve then returns http.ErrServerClosed.
+func (r *Router) Shutdown(ctx context.Context) error {
+	r.mu.RLock()
+	server := r.server
+	r.mu.RUnlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
@@ -0,0 +1,239 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func do(r *Router, method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func ok(c *Context) error { return c.String(http.StatusOK, "ok") }
+
+// requireToken stands in for an auth middleware
+func requireToken(next HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		if c.GetHeader("Authorization") != "Bearer secret" {
+			return c.String(http.StatusUnauthorized, "unauthorized")
+		}
+		return next(c)
+	}
+}
+
+func TestGroupMiddlewareProtectsOnlyItsGroup(t *testing.T) {
+	r := New()
+	r.GET("/public", ok)
+	admin := r.Group("/admin")
+	admin.Use(requireToken)
+	admin.GET("/stats", ok)
+	r.Group("/api").GET("/posts", ok)
+
+	tests := []struct {
+		path   string
+		header http.Header
+		status int
+	}{
+		{"/public", nil, http.StatusOK},
+		{"/api/posts", nil, http.StatusOK},
+		{"/admin/stats", nil, http.StatusUnauthorized},
+		{"/admin/stats", http.Header{"Authorization": {"Bearer secret"}}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if w := do(r, http.MethodGet, tt.path, tt.header); w.Code != tt.status {
+			t.Errorf("GET %s = %d, want %d", tt.path, w.Code, tt.status)
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c *Context) error {
+				calls = append(calls, name)
+				return next(c)
+			}
+		}
+	}
+
+	r := New()
+	r.Use(record("global"))
+	g := r.Group("/g", record("group"))
+	g.GET("/x", ok, record("route"))
+
+	do(r, http.MethodGet, "/g/x", nil)
+	if want := []string{"global", "group", "route"}; !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestPreflightUsesTheRoutesMiddleware(t *testing.T) {
+	cors := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetHeader("Access-Control-Allow-Origin", "*")
+			return next(c)
+		}
+	}
+
+	r := New()
+	r.Group("/public", cors).GET("/feed", ok)
+	r.GET("/private", ok)
+
+	w := do(r, http.MethodOptions, "/public/feed", nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("OPTIONS /public/feed = %d, want 204", w.Code)
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Error("group CORS middleware did not run for the preflight")
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS" {
+		t.Errorf("Allow = %q", allow)
+	}
+
+	if w := do(r, http.MethodOptions, "/private", nil); w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("group CORS middleware ran outside its group")
+	}
+}
+
+func TestPreflightAcrossMethodsWithDifferentParamNames(t *testing.T) {
+	r := New()
+	r.GET("/users/:id", ok)
+	r.DELETE("/users/:userId", ok)
+	r.GET("/files/:name", ok)
+	r.POST("/files/upload", ok)
+
+	for _, path := range []string{"/users/7", "/files/upload", "/files/a.txt"} {
+		if w := do(r, http.MethodOptions, path, nil); w.Code != http.StatusNoContent {
+			t.Errorf("OPTIONS %s = %d, want 204", path, w.Code)
+		}
+	}
+	w := do(r, http.MethodOptions, "/users/7", nil)
+	if allow := w.Header().Get("Allow"); allow != "DELETE, GET, HEAD, OPTIONS" {
+		t.Errorf("Allow = %q", allow)
+	}
+}
+
+func TestExplicitOptionsRouteWins(t *testing.T) {
+	r := New()
+	r.GET("/items", ok)
+	r.OPTIONS("/items", func(c *Context) error {
+		return c.String(http.StatusOK, "custom")
+	})
+
+	w := do(r, http.MethodOptions, "/items", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "custom") {
+		t.Errorf("OPTIONS /items = %d %q, want the explicit route", w.Code, w.Body.String())
+	}
+}
+
+func TestDuplicateRoutePanics(t *testing.T) {
+	r := New()
+	r.GET("/dup", ok)
+	defer func() {
+		if recover() == nil {
+			t.Error("registering the same route twice did not panic")
+		}
+	}()
+	r.GET("/dup", ok)
+}
+
+func TestHeadServedByGet(t *testing.T) {
+	r := New()
+	r.GET("/items/:id", func(c *Context) error {
+		c.SetHeader("X-Item", c.Param("id"))
+		return c.JSON(http.StatusOK, map[string]string{"id": c.Param("id")})
+	})
+	r.GET("/explicit", ok)
+	r.HEAD("/explicit", func(c *Context) error {
+		c.SetHeader("X-Head", "explicit")
+		return c.NoContent()
+	})
+
+	w := do(r, http.MethodHead, "/items/7", nil)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("HEAD /items/7 = %d %q, want 200 without a body", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Item") != "7" || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("HEAD /items/7 headers = %v, want the GET route's", w.Header())
+	}
+
+	if w := do(r, http.MethodHead, "/explicit", nil); w.Header().Get("X-Head") != "explicit" {
+		t.Errorf("HEAD /explicit headers = %v, want the HEAD route's", w.Header())
+	}
+	if w := do(r, http.MethodHead, "/missing", nil); w.Code != http.StatusNotFound {
+		t.Errorf("HEAD /missing = %d, want 404", w.Code)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	r := New()
+	r.GET("/items/:id", ok)
+	r.PUT("/items/:id", ok)
+	r.DELETE("/items/:key", ok)
+	r.POST("/items", ok)
+
+	tests := []struct {
+		method, path string
+		allow        string
+	}{
+		{http.MethodPost, "/items/7", "DELETE, GET, HEAD, OPTIONS, PUT"},
+		{http.MethodPatch, "/items/7/", "DELETE, GET, HEAD, OPTIONS, PUT"},
+		{http.MethodGet, "/items", "OPTIONS, POST"},
+	}
+	for _, tt := range tests {
+		w := do(r, tt.method, tt.path, nil)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s = %d, want 405", tt.method, tt.path, w.Code)
+		}
+		if allow := w.Header().Get("Allow"); allow != tt.allow {
+			t.Errorf("%s %s Allow = %q, want %q", tt.method, tt.path, allow, tt.allow)
+		}
+	}
+
+	// Unknown paths are still 404s
+	if w := do(r, http.MethodPost, "/other", nil); w.Code != http.StatusNotFound || w.Header().Get("Allow") != "" {
+		t.Errorf("POST /other = %d with Allow %q, want a plain 404", w.Code, w.Header().Get("Allow"))
+	}
+}
+
+func TestServeAndShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	r.GET("/ping", ok)
+
+	served := make(chan error, 1)
+	go func() { served <- r.Serve(ln) }()
+
+	// The listener is bound before Serve runs, so requests don't race startup
+	resp, err := http.Get("http://" + ln.Addr().String() + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /ping = %d", resp.StatusCode)
+	}
+
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("Serve = %v, want http.ErrServerClosed", err)
+	}
+}
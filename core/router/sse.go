@@ -0,0 +1,93 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SSEStream writes Server-Sent Events to a response started by Context.SSE
+type SSEStream struct {
+	c *Context
+}
+
+// IsEventStream reports whether the client asked for a Server-Sent Events
+// stream. Middleware that buffers or bounds responses lets these through.
+func (c *Context) IsEventStream() bool {
+	return strings.Contains(c.Header("Accept"), "text/event-stream")
+}
+
+// SSE starts a Server-Sent Events response. The handler then sends events
+// until c.Context() is done, which happens when the client disconnects:
+//
+//	stream := c.SSE()
+//	for {
+//		select {
+//		case <-c.Context().Done():
+//			return nil
+//		case progress := <-updates:
+//			if err := stream.Send("progress", progress); err != nil {
+//				return nil
+//			}
+//		}
+//	}
+func (c *Context) SSE() *SSEStream {
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// Keeps nginx from buffering the stream
+	header.Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.Flush()
+	return &SSEStream{c: c}
+}
+
+// Send writes one event and flushes it. Strings and byte slices are sent as
+// they are, anything else as JSON; an empty event name sends a plain
+// "message" event.
+func (s *SSEStream) Send(event string, data any) error {
+	var payload string
+	switch v := data.(type) {
+	case string:
+		payload = v
+	case []byte:
+		payload = string(v)
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		payload = string(raw)
+	}
+
+	var b strings.Builder
+	if event != "" {
+		fmt.Fprintf(&b, "event: %s\n", sseLine(event))
+	}
+	for _, line := range strings.Split(payload, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", strings.TrimSuffix(line, "\r"))
+	}
+	b.WriteString("\n")
+	return s.write(b.String())
+}
+
+// Comment writes a comment line, which clients ignore. Sent periodically,
+// it keeps proxies from closing an idle stream.
+func (s *SSEStream) Comment(text string) error {
+	return s.write(": " + sseLine(text) + "\n\n")
+}
+
+func (s *SSEStream) write(frame string) error {
+	if _, err := s.c.Writer.Write([]byte(frame)); err != nil {
+		return err
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+// sseLine keeps a field value on one line
+func sseLine(value string) string {
+	return strings.NewReplacer("\r", "", "\n", " ").Replace(value)
+}
@@ -0,0 +1,47 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSSE(t *testing.T) {
+	r := New()
+	r.GET("/events", func(c *Context) error {
+		if !c.IsEventStream() {
+			t.Error("IsEventStream = false for an EventSource request")
+		}
+		stream := c.SSE()
+		stream.Send("progress", map[string]int{"done": 1})
+		stream.Send("", "line one\r\nline two")
+		stream.Comment("ping\nping")
+		stream.Send("multi\nline", []byte("raw"))
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || !w.Flushed {
+		t.Errorf("status = %d, flushed = %t", w.Code, w.Flushed)
+	}
+	for name, want := range map[string]string{
+		"Content-Type":      "text/event-stream",
+		"Cache-Control":     "no-cache",
+		"X-Accel-Buffering": "no",
+	} {
+		if got := w.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	want := "event: progress\ndata: {\"done\":1}\n\n" +
+		"data: line one\ndata: line two\n\n" +
+		": ping ping\n\n" +
+		"event: multi line\ndata: raw\n\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StaticConfig contains static file serving configuration
+type StaticConfig struct {
+	// MaxAge sets Cache-Control: public, max-age; zero sends no-cache so
+	// clients always revalidate with the ETag
+	MaxAge time.Duration
+
+	// Index is served for directory requests
+	Index string
+}
+
+// DefaultStaticConfig returns default static file configuration
+func DefaultStaticConfig() StaticConfig {
+	return StaticConfig{
+		MaxAge: time.Hour,
+		Index:  "index.html",
+	}
+}
+
+// Static serves static files with DefaultStaticConfig
+func (r *Router) Static(prefix, root string) {
+	r.StaticWithConfig(prefix, root, DefaultStaticConfig())
+}
+
+// StaticWithConfig serves static files. Responses carry ETag, Last-Modified
+// and Cache-Control, answer conditional requests with 304 and honor Range
+// requests with 206 so media can be seeked and downloads resumed.
+func (r *Router) StaticWithConfig(prefix, root string, config StaticConfig) {
+	// Ensure prefix starts with /
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	if config.Index == "" {
+		config.Index = "index.html"
+	}
+
+	cacheControl := "no-cache"
+	if config.MaxAge > 0 {
+		cacheControl = "public, max-age=" + strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+
+	handler := func(c *Context) error {
+		reqPath := c.Request.URL.Path
+
+		// Remove the prefix; cleaning a rooted path drops any ".." segments
+		file := path.Clean("/" + strings.TrimPrefix(reqPath, prefix))
+		if file == "/" || strings.HasSuffix(reqPath, "/") {
+			file = path.Join(file, config.Index)
+		}
+
+		// Backslashes separate paths on Windows, where they would slip ".."
+		// past path.Clean, so they are refused along with NUL bytes
+		if strings.ContainsAny(file, "\\\x00") {
+			return defaultNotFound(c)
+		}
+		name := filepath.Join(root, filepath.FromSlash(file))
+		if !withinRoot(root, name) {
+			return defaultNotFound(c)
+		}
+
+		f, info, err := openStatic(name, config.Index)
+		if err != nil {
+			return defaultNotFound(c)
+		}
+		defer f.Close()
+
+		// ServeContent checks If-None-Match against this ETag, handles
+		// If-Modified-Since and Range, and sets Last-Modified
+		c.SetHeader("ETag", fileETag(info))
+		c.SetHeader("Cache-Control", cacheControl)
+		http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), f)
+		return nil
+	}
+
+	doc := Doc{
+		Summary: "Static files under " + prefix,
+		Tags:    []string{"Static"},
+	}
+
+	// register route with wildcard
+	r.GET(prefix+"/*filepath", handler).Doc(doc)
+	r.GET(prefix, handler).Doc(doc) // also serve the exact prefix URL
+	r.HEAD(prefix+"/*filepath", handler).Doc(doc)
+	r.HEAD(prefix, handler).Doc(doc)
+}
+
+// withinRoot reports whether name is root or a path below it
+func withinRoot(root, name string) bool {
+	rel, err := filepath.Rel(root, name)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// openStatic opens a regular file, or the index file when name is a directory
+func openStatic(name, index string) (*os.File, os.FileInfo, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if !info.IsDir() {
+		return f, info, nil
+	}
+
+	f.Close()
+	if index == "" {
+		return nil, nil, os.ErrNotExist
+	}
+	return openStatic(filepath.Join(name, index), "")
+}
+
+// fileETag derives a strong validator from size and modification time, which
+// is cheap to compute and changes whenever the file is replaced
+func fileETag(info os.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.Size(), info.ModTime().UnixNano())
+}
@@ -0,0 +1,88 @@
+package router
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func staticRouter(t *testing.T) *Router {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "clip.mp4"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "index.html"), []byte("<h1>docs</h1>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	r.StaticWithConfig("/media", root, StaticConfig{MaxAge: time.Minute})
+	return r
+}
+
+func TestStaticRange(t *testing.T) {
+	r := staticRouter(t)
+
+	w := do(r, http.MethodGet, "/media/clip.mp4", http.Header{"Range": {"bytes=2-5"}})
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want 206", w.Code)
+	}
+	if w.Body.String() != "2345" {
+		t.Errorf("body = %q, want 2345", w.Body.String())
+	}
+	if got := w.Header().Get("Content-Range"); got != "bytes 2-5/10" {
+		t.Errorf("Content-Range = %q", got)
+	}
+
+	// Seeking from the end, as players do for trailing metadata
+	w = do(r, http.MethodGet, "/media/clip.mp4", http.Header{"Range": {"bytes=-3"}})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "789" {
+		t.Errorf("suffix range = %d %q", w.Code, w.Body.String())
+	}
+
+	w = do(r, http.MethodGet, "/media/clip.mp4", http.Header{"Range": {"bytes=20-30"}})
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("unsatisfiable range = %d, want 416", w.Code)
+	}
+}
+
+func TestStaticConditionalGet(t *testing.T) {
+	r := staticRouter(t)
+
+	w := do(r, http.MethodGet, "/media/clip.mp4", nil)
+	etag, lastModified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	if w.Code != http.StatusOK || etag == "" || lastModified == "" {
+		t.Fatalf("status = %d, ETag = %q, Last-Modified = %q", w.Code, etag, lastModified)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=60" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	if w := do(r, http.MethodGet, "/media/clip.mp4", http.Header{"If-None-Match": {etag}}); w.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match = %d, want 304", w.Code)
+	}
+	if w := do(r, http.MethodGet, "/media/clip.mp4", http.Header{"If-Modified-Since": {lastModified}}); w.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since = %d, want 304", w.Code)
+	}
+	if w := do(r, http.MethodGet, "/media/clip.mp4", http.Header{"If-None-Match": {`"stale"`}}); w.Code != http.StatusOK {
+		t.Errorf("stale If-None-Match = %d, want 200", w.Code)
+	}
+}
+
+func TestStaticPaths(t *testing.T) {
+	r := staticRouter(t)
+
+	if w := do(r, http.MethodGet, "/media/docs/", nil); w.Code != http.StatusOK || w.Body.String() != "<h1>docs</h1>" {
+		t.Errorf("directory index = %d %q", w.Code, w.Body.String())
+	}
+	for _, path := range []string{"/media/../static_test.go", "/media/missing.mp4", "/media/..%5cstatic_test.go"} {
+		if w := do(r, http.MethodGet, path, nil); w.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, w.Code)
+		}
+	}
+}
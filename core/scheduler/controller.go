@@ -2,31 +2,78 @@ package scheduler
 
 import (
 	"base/core/router"
+	"errors"
 	"net/http"
 )
 
 // SchedulerController provides HTTP endpoints for scheduler management
 type SchedulerController struct {
-	scheduler *Scheduler
+	scheduler     *Scheduler
+	cronScheduler *CronScheduler
 }
 
 // NewSchedulerController creates a new scheduler controller
-func NewSchedulerController(scheduler *Scheduler) *SchedulerController {
+func NewSchedulerController(scheduler *Scheduler, cronScheduler *CronScheduler) *SchedulerController {
 	return &SchedulerController{
-		scheduler: scheduler,
+		scheduler:     scheduler,
+		cronScheduler: cronScheduler,
 	}
 }
 
 // Routes registers scheduler endpoints
-func (c *SchedulerController) Routes(router *router.RouterGroup) {
+func (c *SchedulerController) Routes(r *router.RouterGroup) {
 	// Routes are registered directly on the scheduler router group
-	router.GET("/status", c.GetStatus)
-	router.GET("/tasks", c.GetTasks)
-	router.GET("/tasks/:name", c.GetTask)
-	router.POST("/tasks/:name/run", c.RunTask)
-	router.PUT("/tasks/:name/enable", c.EnableTask)
-	router.PUT("/tasks/:name/disable", c.DisableTask)
-	router.GET("/stats", c.GetStats)
+	r.GET("/status", c.GetStatus).Doc(router.Doc{
+		Summary:  "Get the scheduler status",
+		Tags:     []string{"Core/Scheduler"},
+		Response: map[string]any{},
+	})
+	r.GET("/tasks", c.GetTasks).Doc(router.Doc{
+		Summary:  "List the registered tasks",
+		Tags:     []string{"Core/Scheduler"},
+		Response: map[string]any{},
+	})
+	r.GET("/tasks/:name", c.GetTask).Doc(router.Doc{
+		Summary:  "Get a task",
+		Tags:     []string{"Core/Scheduler"},
+		Response: map[string]any{},
+	})
+	r.POST("/tasks/:name/run", c.RunTask).Doc(router.Doc{
+		Summary:  "Run a task now",
+		Tags:     []string{"Core/Scheduler"},
+		Response: map[string]any{},
+	})
+	r.PUT("/tasks/:name/enable", c.EnableTask).Doc(router.Doc{
+		Summary:  "Enable a task",
+		Tags:     []string{"Core/Scheduler"},
+		Response: map[string]any{},
+	})
+	r.PUT("/tasks/:name/disable", c.DisableTask).Doc(router.Doc{
+		Summary:  "Disable a task",
+		Tags:     []string{"Core/Scheduler"},
+		Response: map[string]any{},
+	})
+	r.GET("/stats", c.GetStats).Doc(router.Doc{
+		Summary:  "Get the scheduler statistics",
+		Tags:     []string{"Core/Scheduler"},
+		Response: map[string]any{},
+	})
+}
+
+// CronRoutes registers the cron task endpoints; r must require the
+// admin token, since running a task acts for the whole app
+func (c *SchedulerController) CronRoutes(r *router.RouterGroup) {
+	r.GET("/cron", c.GetCronTasks).Doc(router.Doc{
+		Summary:     "List the cron tasks",
+		Description: "Cron tasks with their expression, last and next run",
+		Tags:        []string{"Core/Scheduler"},
+		Response:    map[string]any{},
+	})
+	r.POST("/cron/:name/run", c.RunCronTask).Doc(router.Doc{
+		Summary:  "Run a cron task now",
+		Tags:     []string{"Core/Scheduler"},
+		Response: map[string]any{},
+	})
 }
 
 // GetStatus returns scheduler status
@@ -40,13 +87,6 @@ func (c *SchedulerController) GetStatus(ctx *router.Context) error {
 }
 
 // GetTasks returns all registered tasks
-// @Summary Get all registered tasks
-// @Tags Core/Scheduler
-// @Description Returns a list of all registered tasks
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {object} []map[string]interface{}
-// @Router /scheduler/tasks [get]
 func (c *SchedulerController) GetTasks(ctx *router.Context) error {
 	tasks := c.scheduler.GetAllTasks()
 
@@ -80,13 +120,6 @@ func (c *SchedulerController) GetTasks(ctx *router.Context) error {
 }
 
 // GetTask returns a specific task
-// @Summary Get a specific task
-// @Tags Core/Scheduler
-// @Param name path string true "Task name"
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Router /scheduler/tasks/{name} [get]
 func (c *SchedulerController) GetTask(ctx *router.Context) error {
 	name := ctx.Param("name")
 
@@ -124,13 +157,6 @@ func (c *SchedulerController) GetTask(ctx *router.Context) error {
 }
 
 // RunTask executes a task immediately
-// @Summary Run a specific task immediately
-// @Tags Core/Scheduler
-// @Param name path string true "Task name"
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Router /scheduler/tasks/{name}/run [post]
 func (c *SchedulerController) RunTask(ctx *router.Context) error {
 	name := ctx.Param("name")
 
@@ -147,13 +173,6 @@ func (c *SchedulerController) RunTask(ctx *router.Context) error {
 }
 
 // EnableTask enables a task
-// @Summary Enable a specific task
-// @Tags Core/Scheduler
-// @Param name path string true "Task name"
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Router /scheduler/tasks/{name}/enable [put]
 func (c *SchedulerController) EnableTask(ctx *router.Context) error {
 	name := ctx.Param("name")
 
@@ -170,13 +189,6 @@ func (c *SchedulerController) EnableTask(ctx *router.Context) error {
 }
 
 // DisableTask disables a task
-// @Summary Disable a specific task
-// @Tags Core/Scheduler
-// @Param name path string true "Task name"
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Router /scheduler/tasks/{name}/disable [put]
 func (c *SchedulerController) DisableTask(ctx *router.Context) error {
 	name := ctx.Param("name")
 
@@ -193,12 +205,6 @@ func (c *SchedulerController) DisableTask(ctx *router.Context) error {
 }
 
 // GetStats returns detailed scheduler statistics
-// @Summary Get scheduler statistics
-// @Tags Core/Scheduler
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Router /scheduler/stats [get]
 func (c *SchedulerController) GetStats(ctx *router.Context) error {
 	stats := c.scheduler.GetStats()
 	ctx.JSON(http.StatusOK, map[string]interface{}{
@@ -207,3 +213,43 @@ func (c *SchedulerController) GetStats(ctx *router.Context) error {
 	})
 	return nil
 }
+
+// GetCronTasks returns all registered cron tasks
+func (c *SchedulerController) GetCronTasks(ctx *router.Context) error {
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data":   c.cronScheduler.GetStats(),
+	})
+	return nil
+}
+
+// RunCronTask runs a cron task immediately, without jitter
+func (c *SchedulerController) RunCronTask(ctx *router.Context) error {
+	name := ctx.Param("name")
+
+	if _, exists := c.cronScheduler.GetTask(name); !exists {
+		ctx.JSON(http.StatusNotFound, map[string]interface{}{
+			"status":  "error",
+			"message": "Task not found",
+		})
+		return nil
+	}
+
+	err := c.cronScheduler.RunTaskNow(name)
+	if errors.Is(err, ErrTaskRunning) {
+		ctx.JSON(http.StatusConflict, map[string]interface{}{
+			"status":  "error",
+			"message": "Task is already running",
+		})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "Task executed successfully",
+	})
+	return nil
+}
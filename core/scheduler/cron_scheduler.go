@@ -2,8 +2,11 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"base/core/logger"
@@ -11,6 +14,16 @@ import (
 	"github.com/robfig/cron/v3"
 )
 
+// ErrTaskRunning is returned when a task that doesn't allow overlapping runs
+// is triggered while its previous run is still going
+var ErrTaskRunning = errors.New("task is already running")
+
+// cronParser accepts standard 5-field expressions, 6 fields with a leading
+// seconds field, and descriptors such as @hourly or @every 10m
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
 // CronScheduler manages cron-based scheduled tasks
 type CronScheduler struct {
 	cron      *cron.Cron
@@ -34,14 +47,24 @@ type CronTask struct {
 	RunCount     int64
 	ErrorCount   int64
 	EntryID      cron.EntryID
+
+	// Jitter delays each scheduled run by a random duration up to this long,
+	// so instances sharing a schedule don't all fire at once
+	Jitter time.Duration
+
+	// AllowOverlap lets a run start while the previous one is still going;
+	// by default such runs are skipped
+	AllowOverlap bool
+
+	running atomic.Bool
 }
 
 // NewCronScheduler creates a new cron-based scheduler
 func NewCronScheduler(log logger.Logger) *CronScheduler {
 	ctx, cancel := context.WithCancel(context.Background())
 	
-	// Create cron with seconds precision
-	c := cron.New(cron.WithSeconds())
+	// Seconds are optional so both 5 and 6 field expressions work
+	c := cron.New(cron.WithParser(cronParser))
 	
 	return &CronScheduler{
 		cron:    c,
@@ -69,19 +92,26 @@ func (cs *CronScheduler) Start() error {
 	return nil
 }
 
-// Stop stops the cron scheduler
-func (cs *CronScheduler) Stop() {
+// Stop stops scheduling new runs and waits for running tasks until ctx is
+// done, then cancels the context passed to them
+func (cs *CronScheduler) Stop(ctx context.Context) error {
 	cs.mu.Lock()
-	defer cs.mu.Unlock()
-	
 	if !cs.running {
-		return
+		cs.mu.Unlock()
+		return nil
 	}
-	
 	cs.logger.Info("Stopping cron scheduler")
-	cs.cron.Stop()
 	cs.running = false
-	cs.cancel()
+	done := cs.cron.Stop()
+	cs.mu.Unlock()
+
+	defer cs.cancel()
+	select {
+	case <-done.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // RegisterTask registers a new cron task
@@ -97,37 +127,8 @@ func (cs *CronScheduler) RegisterTask(task *CronTask) error {
 		return nil
 	}
 	
-	// Wrap handler to update task statistics
-	wrappedHandler := func() {
-		now := time.Now()
-		cs.logger.Info("Executing cron task", 
-			logger.String("name", task.Name),
-			logger.String("description", task.Description))
-		
-		err := task.Handler(cs.ctx)
-		
-		cs.mu.Lock()
-		task.LastRun = &now
-		task.RunCount++
-		if err != nil {
-			task.ErrorCount++
-		}
-		// Update next run time
-		cs.updateNextRunTime(task)
-		cs.mu.Unlock()
-		
-		if err != nil {
-			cs.logger.Error("Cron task execution failed",
-				logger.String("name", task.Name),
-				logger.String("error", err.Error()))
-		} else {
-			cs.logger.Info("Cron task completed successfully", 
-				logger.String("name", task.Name))
-		}
-	}
-	
 	// Add job to cron
-	entryID, err := cs.cron.AddFunc(task.CronExpr, wrappedHandler)
+	entryID, err := cs.cron.AddFunc(task.CronExpr, func() { cs.execute(task, true) })
 	if err != nil {
 		return fmt.Errorf("failed to add cron job: %w", err)
 	}
@@ -242,19 +243,56 @@ func (cs *CronScheduler) RunTaskNow(name string) error {
 	}
 	
 	cs.logger.Info("Running cron task manually", logger.String("name", name))
-	
+	return cs.execute(task, false)
+}
+
+// execute runs a task and updates its statistics. Scheduled runs wait for
+// the task's jitter first; manual runs start right away.
+func (cs *CronScheduler) execute(task *CronTask, scheduled bool) error {
+	if !task.AllowOverlap {
+		if !task.running.CompareAndSwap(false, true) {
+			cs.logger.Warn("Skipping cron task, previous run still in progress",
+				logger.String("name", task.Name))
+			return ErrTaskRunning
+		}
+		defer task.running.Store(false)
+	}
+
+	if scheduled && task.Jitter > 0 {
+		select {
+		case <-time.After(rand.N(task.Jitter)):
+		case <-cs.ctx.Done():
+			return cs.ctx.Err()
+		}
+	}
+
 	now := time.Now()
+	cs.logger.Info("Executing cron task",
+		logger.String("name", task.Name),
+		logger.String("description", task.Description))
+
 	err := task.Handler(cs.ctx)
-	
+
 	cs.mu.Lock()
 	task.LastRun = &now
 	task.RunCount++
 	if err != nil {
 		task.ErrorCount++
 	}
+	cs.updateNextRunTime(task)
 	cs.mu.Unlock()
-	
-	return err
+
+	if err != nil {
+		cs.logger.Error("Cron task execution failed",
+			logger.String("name", task.Name),
+			logger.String("error", err.Error()))
+		return err
+	}
+
+	cs.logger.Info("Cron task completed successfully",
+		logger.String("name", task.Name),
+		logger.String("duration", time.Since(now).String()))
+	return nil
 }
 
 // GetTask returns a task by name
@@ -302,6 +340,7 @@ func (cs *CronScheduler) GetStats() map[string]interface{} {
 			"description":  task.Description,
 			"enabled":      task.Enabled,
 			"cron_expr":    task.CronExpr,
+			"running":      task.running.Load(),
 			"run_count":    task.RunCount,
 			"error_count":  task.ErrorCount,
 		}
@@ -328,36 +367,8 @@ func (cs *CronScheduler) GetStats() map[string]interface{} {
 
 // registerTaskInternal registers a task (internal method - assumes lock is held)
 func (cs *CronScheduler) registerTaskInternal(task *CronTask) error {
-	// Wrap handler to update task statistics
-	wrappedHandler := func() {
-		now := time.Now()
-		cs.logger.Info("Executing cron task", 
-			logger.String("name", task.Name),
-			logger.String("description", task.Description))
-		
-		err := task.Handler(cs.ctx)
-		
-		cs.mu.Lock()
-		task.LastRun = &now
-		task.RunCount++
-		if err != nil {
-			task.ErrorCount++
-		}
-		cs.updateNextRunTime(task)
-		cs.mu.Unlock()
-		
-		if err != nil {
-			cs.logger.Error("Cron task execution failed",
-				logger.String("name", task.Name),
-				logger.String("error", err.Error()))
-		} else {
-			cs.logger.Info("Cron task completed successfully", 
-				logger.String("name", task.Name))
-		}
-	}
-	
 	// Add job to cron
-	entryID, err := cs.cron.AddFunc(task.CronExpr, wrappedHandler)
+	entryID, err := cs.cron.AddFunc(task.CronExpr, func() { cs.execute(task, true) })
 	if err != nil {
 		return fmt.Errorf("failed to add cron job: %w", err)
 	}
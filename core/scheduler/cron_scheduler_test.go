@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"base/core/logger"
+
+	"go.uber.org/zap"
+)
+
+func newTestCron(t *testing.T) *CronScheduler {
+	t.Helper()
+	cs := NewCronScheduler(logger.NewLoggerFromZap(zap.NewNop()))
+	t.Cleanup(func() { cs.Stop(context.Background()) })
+	return cs
+}
+
+func TestEvery(t *testing.T) {
+	SetDefault(nil)
+	if err := Every("0 * * * *", "cleanup", func(context.Context) error { return nil }); !errors.Is(err, ErrNoScheduler) {
+		t.Fatalf("Every without a scheduler = %v, want ErrNoScheduler", err)
+	}
+
+	cs := newTestCron(t)
+	SetDefault(cs)
+	t.Cleanup(func() { SetDefault(nil) })
+
+	var runs atomic.Int32
+	handler := func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}
+	for _, expr := range []string{"0 * * * *", "30 0 * * * *", "@hourly"} {
+		if err := Every(expr, "task "+expr, handler, WithDescription("hourly")); err != nil {
+			t.Errorf("Every(%q): %v", expr, err)
+		}
+	}
+	if err := Every("not a schedule", "broken", handler); err == nil {
+		t.Error("Every accepted an invalid expression")
+	}
+
+	task, ok := cs.GetTask("task 0 * * * *")
+	if !ok || task.Description != "hourly" || task.CronExpr != "0 * * * *" {
+		t.Fatalf("GetTask = %+v, %t", task, ok)
+	}
+	if got := len(cs.GetAllTasks()); got != 3 {
+		t.Errorf("GetAllTasks = %d tasks, want 3", got)
+	}
+
+	if err := cs.RunTaskNow("task @hourly"); err != nil || runs.Load() != 1 {
+		t.Errorf("RunTaskNow = %v after %d runs", err, runs.Load())
+	}
+	if task, _ := cs.GetTask("task @hourly"); task.RunCount != 1 || task.LastRun == nil {
+		t.Errorf("stats not updated: %+v", task)
+	}
+}
+
+func TestCronRunsOnSchedule(t *testing.T) {
+	cs := newTestCron(t)
+	ran := make(chan struct{}, 1)
+	cs.RegisterTask(&CronTask{
+		Name:     "tick",
+		CronExpr: "@every 1s",
+		Enabled:  true,
+		Handler: func(context.Context) error {
+			select {
+			case ran <- struct{}{}:
+			default:
+			}
+			return nil
+		},
+	})
+	if err := cs.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ran:
+	case <-time.After(3 * time.Second):
+		t.Fatal("task didn't run after Start")
+	}
+}
+
+func TestCronSkipsOverlappingRuns(t *testing.T) {
+	cs := newTestCron(t)
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	blocking := func(context.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+	cs.RegisterTask(&CronTask{Name: "single", CronExpr: "@hourly", Enabled: true, Handler: blocking})
+	cs.RegisterTask(&CronTask{Name: "overlapping", CronExpr: "@hourly", Enabled: true, Handler: blocking, AllowOverlap: true})
+
+	done := make(chan error, 3)
+	go func() { done <- cs.RunTaskNow("single") }()
+	<-started
+	if err := cs.RunTaskNow("single"); !errors.Is(err, ErrTaskRunning) {
+		t.Errorf("second run = %v, want ErrTaskRunning", err)
+	}
+
+	go func() { done <- cs.RunTaskNow("overlapping") }()
+	go func() { done <- cs.RunTaskNow("overlapping") }()
+	for range 2 {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("AllowOverlap task didn't run concurrently")
+		}
+	}
+
+	close(release)
+	for range 3 {
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestManualRunsSkipJitter(t *testing.T) {
+	cs := newTestCron(t)
+	cs.RegisterTask(&CronTask{
+		Name:     "jittered",
+		CronExpr: "@hourly",
+		Enabled:  true,
+		Jitter:   time.Hour,
+		Handler:  func(context.Context) error { return nil },
+	})
+
+	start := time.Now()
+	if err := cs.RunTaskNow("jittered"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("manual run took %v", elapsed)
+	}
+}
@@ -74,13 +74,10 @@ func ConvertToNextMinuteCron() string {
 		int(nextMinute.Month()))
 }
 
-// ValidateCronExpression validates a cron expression
+// ValidateCronExpression validates a cron expression with 5 or 6 fields
 func ValidateCronExpression(cronExpr string) error {
-	parts := strings.Fields(cronExpr)
-	if len(parts) != 6 {
-		return fmt.Errorf("cron expression must have 6 fields (seconds minute hour day month dayofweek)")
-	}
-	return nil
+	_, err := cronParser.Parse(cronExpr)
+	return err
 }
 
 // DescribeCronExpression provides a human-readable description of a cron expression
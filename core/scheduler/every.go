@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNoScheduler is returned by Every before a default cron scheduler is set
+var ErrNoScheduler = errors.New("scheduler: no cron scheduler configured")
+
+var (
+	defaultMu   sync.RWMutex
+	defaultCron *CronScheduler
+)
+
+// SetDefault sets the cron scheduler used by Every
+func SetDefault(cs *CronScheduler) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultCron = cs
+}
+
+// Default returns the cron scheduler used by Every, or nil if none is set
+func Default() *CronScheduler {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultCron
+}
+
+// TaskOption configures a task registered with Every
+type TaskOption func(*CronTask)
+
+// WithDescription sets the description shown when listing tasks
+func WithDescription(description string) TaskOption {
+	return func(t *CronTask) {
+		t.Description = description
+	}
+}
+
+// WithJitter delays each scheduled run by a random duration up to max
+func WithJitter(max time.Duration) TaskOption {
+	return func(t *CronTask) {
+		t.Jitter = max
+	}
+}
+
+// AllowOverlap lets a run start while the previous one is still going
+func AllowOverlap() TaskOption {
+	return func(t *CronTask) {
+		t.AllowOverlap = true
+	}
+}
+
+// Every registers handler on the default cron scheduler to run on expr,
+// e.g. "0 * * * *" for hourly or "@every 10m".
+//
+//	scheduler.Every("0 * * * *", "cleanup-tokens", cleanup, scheduler.WithJitter(time.Minute))
+func Every(expr, name string, handler TaskHandler, opts ...TaskOption) error {
+	cs := Default()
+	if cs == nil {
+		return ErrNoScheduler
+	}
+
+	task := &CronTask{
+		Name:     name,
+		CronExpr: expr,
+		Handler:  handler,
+		Enabled:  true,
+	}
+	for _, opt := range opts {
+		opt(task)
+	}
+	return cs.RegisterTask(task)
+}
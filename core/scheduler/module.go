@@ -1,10 +1,14 @@
 package scheduler
 
 import (
+	"context"
+
+	"base/core/config"
 	"base/core/emitter"
 	"base/core/logger"
 	"base/core/module"
 	"base/core/router"
+	"base/core/router/middleware"
 
 	"gorm.io/gorm"
 )
@@ -17,13 +21,15 @@ type Module struct {
 	CronScheduler *CronScheduler // Simple cron scheduler
 	Controller    *SchedulerController
 	Logger        logger.Logger
+	AdminToken    string
 }
 
 // NewSchedulerModule creates a new scheduler module
-func NewSchedulerModule(db *gorm.DB, routerGroup *router.RouterGroup, log logger.Logger, emitter *emitter.Emitter) module.Module {
+func NewSchedulerModule(db *gorm.DB, routerGroup *router.RouterGroup, log logger.Logger, emitter *emitter.Emitter, cfg *config.Config) module.Module {
 	scheduler := NewScheduler(log)
 	cronScheduler := NewCronScheduler(log)
-	controller := NewSchedulerController(scheduler)
+	controller := NewSchedulerController(scheduler, cronScheduler)
+	SetDefault(cronScheduler)
 
 	m := &Module{
 		DB:            db,
@@ -31,6 +37,7 @@ func NewSchedulerModule(db *gorm.DB, routerGroup *router.RouterGroup, log logger
 		CronScheduler: cronScheduler,
 		Controller:    controller,
 		Logger:        log,
+		AdminToken:    cfg.AdminToken,
 	}
 
 	return m
@@ -40,10 +47,11 @@ func NewSchedulerModule(db *gorm.DB, routerGroup *router.RouterGroup, log logger
 func (m *Module) Routes(router *router.RouterGroup) {
 	schedulerGroup := router.Group("/scheduler")
 	m.Controller.Routes(schedulerGroup)
+	m.Controller.CronRoutes(schedulerGroup.Group("", middleware.AdminAuth(m.AdminToken)).Security("AdminToken"))
 }
 
 // Start starts the scheduler
-func (m *Module) Start() error {
+func (m *Module) Start(ctx context.Context) error {
 	m.Logger.Info("Starting scheduler module")
 
 	// Start both schedulers
@@ -56,12 +64,11 @@ func (m *Module) Start() error {
 	return nil
 }
 
-// Stop stops the scheduler
-func (m *Module) Stop() error {
+// Stop stops the scheduler, waiting for running cron tasks until ctx is done
+func (m *Module) Stop(ctx context.Context) error {
 	m.Logger.Info("Stopping scheduler module")
 	m.Scheduler.Stop()
-	m.CronScheduler.Stop()
-	return nil
+	return m.CronScheduler.Stop(ctx)
 }
 
 // GetScheduler returns the scheduler instance
@@ -0,0 +1,119 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Param is the query parameter list endpoints read the search text from
+const Param = "q"
+
+// maxQueryLength bounds the search text so huge patterns can't reach the database
+const maxQueryLength = 256
+
+// Searchable is implemented by models whose list endpoint supports ?q=
+// search over the returned columns
+type Searchable interface {
+	SearchableFields() []string
+}
+
+// Options tunes how a search is run
+type Options struct {
+	// FullText matches words with the database's full-text search where
+	// available (PostgreSQL); elsewhere it falls back to partial matching
+	FullText bool
+	// Ranked orders full-text results by relevance, most relevant first
+	Ranked bool
+}
+
+// Scope filters a query to rows where any of fields contains q, ignoring
+// case. The text is always bound as a parameter; fields are column names
+// from code, never from the request. An empty q leaves the query unchanged.
+//
+//	db.Model(&Post{}).Scopes(search.Scope(c.Query(search.Param), []string{"title", "body"}, search.Options{}))
+func Scope(q string, fields []string, opts Options) func(*gorm.DB) *gorm.DB {
+	q = truncate(strings.TrimSpace(q), maxQueryLength)
+
+	return func(db *gorm.DB) *gorm.DB {
+		if q == "" || len(fields) == 0 {
+			return db
+		}
+
+		if opts.FullText && db.Dialector.Name() == "postgres" {
+			return fullText(db, q, fields, opts.Ranked)
+		}
+		return partial(db, q, fields)
+	}
+}
+
+// ScopeFor is Scope over the model's SearchableFields; models that aren't
+// Searchable are left unfiltered
+func ScopeFor(model any, q string, opts Options) func(*gorm.DB) *gorm.DB {
+	searchable, ok := model.(Searchable)
+	if !ok {
+		return func(db *gorm.DB) *gorm.DB { return db }
+	}
+	return Scope(q, searchable.SearchableFields(), opts)
+}
+
+// partial matches q anywhere in any field: ILIKE on PostgreSQL, LOWER() LIKE
+// elsewhere
+func partial(db *gorm.DB, q string, fields []string) *gorm.DB {
+	pattern := "%" + EscapeLike(q) + "%"
+	sql := "LOWER(?) LIKE ? ESCAPE '!'"
+	if db.Dialector.Name() == "postgres" {
+		sql = "? ILIKE ? ESCAPE '!'"
+	} else {
+		pattern = strings.ToLower(pattern)
+	}
+
+	conditions := make([]clause.Expression, len(fields))
+	for i, field := range fields {
+		conditions[i] = clause.Expr{SQL: sql, Vars: []any{clause.Column{Name: field}, pattern}}
+	}
+	return db.Where(clause.Or(conditions...))
+}
+
+// fullText matches the words of q against the fields' combined text using
+// PostgreSQL's text search, optionally ordering by ts_rank
+func fullText(db *gorm.DB, q string, fields []string, ranked bool) *gorm.DB {
+	placeholders := make([]string, len(fields))
+	vars := make([]any, 0, len(fields)+1)
+	for i, field := range fields {
+		placeholders[i] = "?"
+		vars = append(vars, clause.Column{Name: field})
+	}
+	vars = append(vars, q)
+
+	document := "to_tsvector('simple', concat_ws(' ', " + strings.Join(placeholders, ", ") + "))"
+	query := "plainto_tsquery('simple', ?)"
+
+	db = db.Where(clause.Expr{SQL: document + " @@ " + query, Vars: vars})
+	if ranked {
+		db = db.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "ts_rank(" + document + ", " + query + ") DESC",
+			Vars: vars,
+		}})
+	}
+	return db
+}
+
+// truncate cuts q to at most max bytes without splitting a UTF-8 sequence
+func truncate(q string, max int) string {
+	if len(q) <= max {
+		return q
+	}
+	for max > 0 && !utf8.RuneStart(q[max]) {
+		max--
+	}
+	return q[:max]
+}
+
+// EscapeLike escapes the LIKE wildcards in q so it matches literally. The
+// pattern must be used with ESCAPE '!'.
+func EscapeLike(q string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(q)
+}
@@ -0,0 +1,133 @@
+package search
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type post struct {
+	Id    uint `gorm:"primaryKey"`
+	Title string
+	Body  string
+}
+
+func (*post) SearchableFields() []string { return []string{"title", "body"} }
+
+func posts(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "search.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&post{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	rows := []post{
+		{Title: "Growth of 100%", Body: "yearly"},
+		{Title: "Growth of 1000", Body: "monthly"},
+		{Title: "snake_case names", Body: "style"},
+		{Title: "snakeXcase names", Body: "style"},
+		{Title: "Hello!", Body: "greeting"},
+		{Title: "Release notes", Body: "See the CHANGELOG"},
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return db
+}
+
+func titles(t *testing.T, db *gorm.DB, q string) []string {
+	t.Helper()
+	var found []post
+	if err := db.Scopes(ScopeFor(&post{}, q, Options{FullText: true})).Order("id").Find(&found).Error; err != nil {
+		t.Fatalf("%q: %v", q, err)
+	}
+	result := make([]string, 0, len(found))
+	for _, p := range found {
+		result = append(result, p.Title)
+	}
+	return result
+}
+
+func TestScope(t *testing.T) {
+	db := posts(t)
+	tests := []struct {
+		q    string
+		want []string
+	}{
+		{"growth", []string{"Growth of 100%", "Growth of 1000"}},
+		{"  changelog ", []string{"Release notes"}},
+		// LIKE wildcards and the escape character match literally
+		{"0%", []string{"Growth of 100%"}},
+		{"%", []string{"Growth of 100%"}},
+		{"e_c", []string{"snake_case names"}},
+		{"_", []string{"snake_case names"}},
+		{"!", []string{"Hello!"}},
+		{"o!", []string{"Hello!"}},
+		{"nothing", []string{}},
+	}
+	for _, tt := range tests {
+		if got := titles(t, db, tt.q); !slices.Equal(got, tt.want) {
+			t.Errorf("q=%q = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+
+	// An empty search, or a model that isn't Searchable, doesn't filter
+	if got := titles(t, db, "   "); len(got) != 6 {
+		t.Errorf("blank q matched %d posts, want all 6", len(got))
+	}
+	var all []post
+	db.Scopes(ScopeFor(&struct{ Id uint }{}, "growth", Options{})).Find(&all)
+	if len(all) != 6 {
+		t.Errorf("a model without SearchableFields matched %d posts, want all 6", len(all))
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if got, want := EscapeLike("100%_off!"), "100!%!_off!!"; got != want {
+		t.Errorf("EscapeLike = %q, want %q", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		q    string
+		want string
+	}{
+		{"short", "short"},
+		{"exactly8", "exactly8"},
+		{"too long text", "too long"},
+		// A multi-byte rune across the limit is dropped, not split
+		{"1234567é", "1234567"},
+		{"123456€", "123456"},
+		{"ééééé", "éééé"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.q, 8); got != tt.want {
+			t.Errorf("truncate(%q, 8) = %q, want %q", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestScopeTruncatesOnRuneBoundary(t *testing.T) {
+	db := posts(t)
+	q := strings.Repeat("a", maxQueryLength-1) + "ü and more"
+
+	stmt := db.Session(&gorm.Session{DryRun: true}).Scopes(Scope(q, []string{"title"}, Options{})).Find(&[]post{}).Statement
+	if len(stmt.Vars) != 1 {
+		t.Fatalf("vars %v, want the pattern", stmt.Vars)
+	}
+	pattern := stmt.Vars[0].(string)
+	if !utf8.ValidString(pattern) {
+		t.Errorf("pattern %q isn't valid UTF-8", pattern)
+	}
+	if want := "%" + strings.Repeat("a", maxQueryLength-1) + "%"; pattern != want {
+		t.Errorf("pattern is %d bytes, want the %d before the split rune", len(pattern)-2, maxQueryLength-1)
+	}
+}
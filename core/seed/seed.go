@@ -0,0 +1,114 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"base/core/database"
+
+	"gorm.io/gorm"
+)
+
+// Seeder inserts data a module needs, such as default roles or demo
+// records. Run should be idempotent (see CreateIfNotExists) since a seeder
+// can be re-run after its record in seeds_applied is removed. Envs limits the
+// seeder to those environments; empty means every environment.
+type Seeder struct {
+	Name string
+	Envs []string
+	Run  func(tx *gorm.DB) error
+}
+
+// AppliedSeed records a seeder that has run in the seeds_applied table
+type AppliedSeed struct {
+	Name      string    `gorm:"primaryKey;size:255"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for applied seeders
+func (AppliedSeed) TableName() string {
+	return "seeds_applied"
+}
+
+var (
+	seedersMu sync.RWMutex
+	seeders   []Seeder
+)
+
+// Register registers a seeder. Seeders run in registration order, so a
+// module registering from Init runs after the modules it depends on.
+func Register(s Seeder) {
+	seedersMu.Lock()
+	defer seedersMu.Unlock()
+	for _, existing := range seeders {
+		if existing.Name == s.Name {
+			panic(fmt.Sprintf("seeder already registered: %s", s.Name))
+		}
+	}
+	seeders = append(seeders, s)
+}
+
+// registeredSeeders returns a copy of the registered seeders in order
+func registeredSeeders() []Seeder {
+	seedersMu.RLock()
+	defer seedersMu.RUnlock()
+	return slices.Clone(seeders)
+}
+
+// Run runs every registered seeder for env that hasn't been applied yet and
+// returns the names it ran. Each seeder runs in its own transaction together
+// with its seeds_applied record, and Run stops at the first failure.
+func Run(ctx context.Context, db *gorm.DB, env string) ([]string, error) {
+	if err := db.AutoMigrate(&AppliedSeed{}); err != nil {
+		return nil, fmt.Errorf("failed to create seeds_applied table: %w", err)
+	}
+
+	var records []AppliedSeed
+	if err := db.WithContext(ctx).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load applied seeders: %w", err)
+	}
+	applied := make(map[string]bool, len(records))
+	for _, record := range records {
+		applied[record.Name] = true
+	}
+
+	var ran []string
+	for _, seeder := range registeredSeeders() {
+		if applied[seeder.Name] {
+			continue
+		}
+		if len(seeder.Envs) > 0 && !slices.Contains(seeder.Envs, env) {
+			continue
+		}
+
+		err := database.Transaction(ctx, db, func(tx *gorm.DB) error {
+			if err := seeder.Run(tx); err != nil {
+				return err
+			}
+			return tx.Create(&AppliedSeed{Name: seeder.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return ran, fmt.Errorf("seeder %s failed: %w", seeder.Name, err)
+		}
+		ran = append(ran, seeder.Name)
+	}
+
+	return ran, nil
+}
+
+// CreateIfNotExists loads the row matching keys, its natural key columns,
+// into dst, creating dst when there is none. It reports whether a row was
+// created.
+//
+//	role := Role{Name: "Owner", IsSystem: true}
+//	seed.CreateIfNotExists(tx, &role, map[string]any{"name": role.Name, "is_system": true})
+func CreateIfNotExists(tx *gorm.DB, dst any, keys map[string]any) (bool, error) {
+	result := tx.Where(keys).FirstOrCreate(dst)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,199 @@
+package sse
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"base/core/emitter"
+	"base/core/metrics"
+	"base/core/router"
+)
+
+var droppedEvents = metrics.Default.NewCounterVec("sse_dropped_events_total",
+	"Server-sent events discarded because the subscriber's buffer was full.")
+
+// Config controls heartbeats, buffering and who may subscribe
+type Config struct {
+	// Heartbeat is how often an idle stream gets a comment line, so proxies
+	// don't close it
+	Heartbeat time.Duration
+	// Buffer is the number of queued events per subscriber; events for a
+	// subscriber whose buffer is full are dropped
+	Buffer int
+	// Authorize decides whether userId may subscribe to topic; nil allows
+	// any authenticated user
+	Authorize func(userId uint, topic string) bool
+}
+
+// DefaultConfig returns the default heartbeat and buffer configuration
+func DefaultConfig() Config {
+	return Config{
+		Heartbeat: 15 * time.Second,
+		Buffer:    64,
+	}
+}
+
+// Event is a message published to a topic
+type Event struct {
+	Topic string
+	// Name is the SSE event name; empty sends a "message" event
+	Name string
+	Data any
+	// UserId limits the event to that user's streams; 0 sends it to every
+	// subscriber of the topic
+	UserId uint
+}
+
+// Broker keeps the open event streams by topic and pushes published events
+// to them
+type Broker struct {
+	config Config
+
+	mu     sync.RWMutex
+	topics map[string]map[*subscriber]bool
+}
+
+type subscriber struct {
+	userId uint
+	events chan Event
+}
+
+// NewBroker creates a Broker; zero config values use the defaults
+func NewBroker(config Config) *Broker {
+	defaults := DefaultConfig()
+	if config.Heartbeat <= 0 {
+		config.Heartbeat = defaults.Heartbeat
+	}
+	if config.Buffer <= 0 {
+		config.Buffer = defaults.Buffer
+	}
+	return &Broker{
+		config: config,
+		topics: make(map[string]map[*subscriber]bool),
+	}
+}
+
+// Publish sends an event to every stream subscribed to topic
+func (b *Broker) Publish(topic, name string, data any) {
+	b.PublishEvent(Event{Topic: topic, Name: name, Data: data})
+}
+
+// PublishTo sends an event to userId's streams subscribed to topic
+func (b *Broker) PublishTo(userId uint, topic, name string, data any) {
+	b.PublishEvent(Event{Topic: topic, Name: name, Data: data, UserId: userId})
+}
+
+// PublishEvent sends event to the matching subscribers without blocking
+func (b *Broker) PublishEvent(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.topics[event.Topic] {
+		if event.UserId != 0 && event.UserId != sub.userId {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			droppedEvents.Inc()
+		}
+	}
+}
+
+// Forward publishes the emitter's events to topics of the same name, so
+//
+//	broker.Forward(emitter, "user.registered")
+//
+// streams user.registered to subscribers of the user.registered topic
+func (b *Broker) Forward(e *emitter.Emitter, events ...string) {
+	for _, event := range events {
+		e.On(event, func(data any) {
+			b.Publish(event, event, data)
+		})
+	}
+}
+
+// Subscribers returns the number of open streams across all topics
+func (b *Broker) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	streams := make(map[*subscriber]bool)
+	for _, subs := range b.topics {
+		for sub := range subs {
+			streams[sub] = true
+		}
+	}
+	return len(streams)
+}
+
+// Handler streams the comma separated topics query parameter to the client
+// until it disconnects. It expects the user id under "user_id", as set by
+// middleware.BearerAuth or middleware.StreamAuth.
+func (b *Broker) Handler() router.HandlerFunc {
+	return func(c *router.Context) error {
+		var topics []string
+		for _, topic := range strings.Split(c.Query("topics"), ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				topics = append(topics, topic)
+			}
+		}
+		if len(topics) == 0 {
+			return c.Fail(http.StatusBadRequest, "missing_topics", "Pass the topics to subscribe to in the topics query parameter")
+		}
+
+		userId, _ := c.Get("user_id")
+		id, _ := userId.(uint)
+		if b.config.Authorize != nil {
+			for _, topic := range topics {
+				if !b.config.Authorize(id, topic) {
+					return c.Fail(http.StatusForbidden, "forbidden_topic", "Not allowed to subscribe to "+topic)
+				}
+			}
+		}
+
+		sub := &subscriber{userId: id, events: make(chan Event, b.config.Buffer)}
+		b.subscribe(sub, topics)
+		defer b.unsubscribe(sub, topics)
+
+		stream := c.SSE()
+		heartbeat := time.NewTicker(b.config.Heartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-c.Context().Done():
+				return nil
+			case event := <-sub.events:
+				if err := stream.Send(event.Name, event.Data); err != nil {
+					return nil
+				}
+			case <-heartbeat.C:
+				if err := stream.Comment("ping"); err != nil {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+func (b *Broker) subscribe(sub *subscriber, topics []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, topic := range topics {
+		if b.topics[topic] == nil {
+			b.topics[topic] = make(map[*subscriber]bool)
+		}
+		b.topics[topic][sub] = true
+	}
+}
+
+func (b *Broker) unsubscribe(sub *subscriber, topics []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, topic := range topics {
+		delete(b.topics[topic], sub)
+		if len(b.topics[topic]) == 0 {
+			delete(b.topics, topic)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/emitter"
+	"base/core/router"
+)
+
+// serve mounts the broker at /events; the X-User header stands in for
+// StreamAuth
+func serve(t *testing.T, b *Broker) string {
+	t.Helper()
+	r := router.New()
+	r.Use(func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			if id, err := strconv.Atoi(c.GetHeader("X-User")); err == nil {
+				c.Set("user_id", uint(id))
+			}
+			return next(c)
+		}
+	})
+	r.GET("/events", b.Handler())
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server.URL + "/events"
+}
+
+// stream is an open event stream whose frames arrive on frames
+type stream struct {
+	resp   *http.Response
+	frames chan string
+	cancel context.CancelFunc
+}
+
+func subscribe(t *testing.T, url string, userId int) *stream {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req.Header.Set("X-User", strconv.Itoa(userId))
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	s := &stream{resp: resp, frames: make(chan string, 16), cancel: cancel}
+	t.Cleanup(s.close)
+	if resp.StatusCode != http.StatusOK {
+		return s
+	}
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		var frame []string
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				frame = append(frame, line)
+				continue
+			}
+			s.frames <- strings.Join(frame, "\n")
+			frame = nil
+		}
+		close(s.frames)
+	}()
+	return s
+}
+
+func (s *stream) close() {
+	s.cancel()
+	s.resp.Body.Close()
+}
+
+// next returns the next frame, or "" when none arrives within the timeout
+func (s *stream) next(timeout time.Duration) string {
+	select {
+	case frame := <-s.frames:
+		return frame
+	case <-time.After(timeout):
+		return ""
+	}
+}
+
+func waitSubscribers(t *testing.T, b *Broker, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for b.Subscribers() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("Subscribers = %d, want %d", b.Subscribers(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPublishReachesSubscribers(t *testing.T) {
+	b := NewBroker(Config{Heartbeat: time.Hour})
+	url := serve(t, b)
+	ada := subscribe(t, url+"?topics=jobs,+notifications", 1)
+	bob := subscribe(t, url+"?topics=notifications", 2)
+	if ada.resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ada.resp.Header.Get("Content-Type"))
+	}
+	waitSubscribers(t, b, 2)
+
+	b.Publish("jobs", "progress", map[string]int{"done": 3})
+	if got, want := ada.next(time.Second), "event: progress\ndata: {\"done\":3}"; got != want {
+		t.Errorf("ada got %q, want %q", got, want)
+	}
+	if got := bob.next(50 * time.Millisecond); got != "" {
+		t.Errorf("bob got %q from a topic he didn't subscribe to", got)
+	}
+
+	b.PublishTo(2, "notifications", "", "for bob")
+	if got := bob.next(time.Second); got != "data: for bob" {
+		t.Errorf("bob got %q", got)
+	}
+	if got := ada.next(50 * time.Millisecond); got != "" {
+		t.Errorf("ada got %q meant for bob", got)
+	}
+
+	// Emitter events are forwarded to the topic of the same name
+	e := emitter.New()
+	b.Forward(e, "notifications")
+	e.Emit("notifications", "hello")
+	for name, s := range map[string]*stream{"ada": ada, "bob": bob} {
+		if got := s.next(time.Second); got != "event: notifications\ndata: hello" {
+			t.Errorf("%s got %q", name, got)
+		}
+	}
+}
+
+func TestStreamHeartbeatAndDisconnect(t *testing.T) {
+	b := NewBroker(Config{Heartbeat: 20 * time.Millisecond})
+	s := subscribe(t, serve(t, b)+"?topics=jobs", 1)
+	if got := s.next(time.Second); got != ": ping" {
+		t.Errorf("idle stream got %q, want a heartbeat", got)
+	}
+	waitSubscribers(t, b, 1)
+
+	s.close()
+	waitSubscribers(t, b, 0)
+	// Publishing after the client left neither blocks nor fails
+	b.Publish("jobs", "progress", 1)
+}
+
+func TestStreamRejectsTopics(t *testing.T) {
+	b := NewBroker(Config{Authorize: func(userId uint, topic string) bool {
+		return topic == "user."+strconv.Itoa(int(userId))
+	}})
+	url := serve(t, b)
+
+	if s := subscribe(t, url, 1); s.resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("no topics = %d, want 400", s.resp.StatusCode)
+	}
+	if s := subscribe(t, url+"?topics=user.1,user.2", 1); s.resp.StatusCode != http.StatusForbidden {
+		t.Errorf("another user's topic = %d, want 403", s.resp.StatusCode)
+	}
+	if s := subscribe(t, url+"?topics=user.1", 1); s.resp.StatusCode != http.StatusOK {
+		t.Errorf("own topic = %d, want 200", s.resp.StatusCode)
+	}
+	if got := b.Subscribers(); got > 1 {
+		t.Errorf("Subscribers = %d, refused streams were kept", got)
+	}
+}
+
+func TestFullBufferDropsEvents(t *testing.T) {
+	b := NewBroker(Config{Buffer: 1})
+	sub := &subscriber{userId: 1, events: make(chan Event, 1)}
+	b.subscribe(sub, []string{"jobs"})
+
+	done := make(chan struct{})
+	go func() {
+		for i := range 5 {
+			b.Publish("jobs", "", i)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full subscriber")
+	}
+	if got := (<-sub.events).Data; got != 0 {
+		t.Errorf("buffered event = %v, want the first", got)
+	}
+}
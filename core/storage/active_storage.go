@@ -1,15 +1,39 @@
 package storage
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
 	"mime/multipart"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
+	_ "golang.org/x/image/webp"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrChecksumMismatch means an attachment's content no longer matches its checksum
+	ErrChecksumMismatch = errors.New("attachment checksum mismatch")
+
+	// ErrNoChecksum means the attachment was stored without a checksum
+	ErrNoChecksum = errors.New("attachment has no checksum")
+
+	// ErrOpenUnsupported means the provider can't read objects back
+	ErrOpenUnsupported = errors.New("storage provider can't open files")
+)
+
 func NewActiveStorage(db *gorm.DB, config Config) (*ActiveStorage, error) {
 	var provider Provider
 	var err error
@@ -65,6 +89,7 @@ func NewActiveStorage(db *gorm.DB, config Config) (*ActiveStorage, error) {
 		db:          db,
 		provider:    provider,
 		defaultPath: storagePath,
+		dedup:       config.Dedup,
 		configs:     make(map[string]map[string]AttachmentConfig),
 	}
 
@@ -95,6 +120,11 @@ func (as *ActiveStorage) Attach(model Attachable, field string, file *multipart.
 		return nil, err
 	}
 
+	checksum, err := fileChecksum(file)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create attachment record
 	attachment := &Attachment{
 		ModelType: model.GetModelName(),
@@ -102,6 +132,24 @@ func (as *ActiveStorage) Attach(model Attachable, field string, file *multipart.
 		Field:     field,
 		Filename:  file.Filename,
 		Size:      file.Size,
+		Checksum:  checksum,
+	}
+
+	// Reuse the object of an identical upload instead of storing it again
+	if as.dedup {
+		var existing Attachment
+		if err := as.db.Where("checksum = ? AND size = ?", checksum, file.Size).
+			Limit(1).Find(&existing).Error; err != nil {
+			return nil, err
+		}
+		if existing.Id != 0 {
+			attachment.Path = existing.Path
+			attachment.URL = existing.URL
+			if err := as.db.Create(attachment).Error; err != nil {
+				return nil, err
+			}
+			return attachment, nil
+		}
 	}
 
 	// Upload file using provider
@@ -128,13 +176,83 @@ func (as *ActiveStorage) Attach(model Attachable, field string, file *multipart.
 	return attachment, nil
 }
 
+// Delete removes the attachment, and its object once no other attachment
+// shares it
 func (as *ActiveStorage) Delete(attachment *Attachment) error {
-	if err := as.provider.Delete(attachment.Path); err != nil {
+	var shared int64
+	if err := as.db.Model(&Attachment{}).
+		Where("path = ? AND id <> ?", attachment.Path, attachment.Id).
+		Count(&shared).Error; err != nil {
 		return err
 	}
+	if shared == 0 {
+		if err := as.provider.Delete(attachment.Path); err != nil {
+			return err
+		}
+	}
 	return as.db.Delete(attachment).Error
 }
 
+// Open streams an attachment's content from the provider
+func (as *ActiveStorage) Open(ctx context.Context, attachment *Attachment) (io.ReadCloser, error) {
+	opener, ok := as.provider.(Opener)
+	if !ok {
+		return nil, ErrOpenUnsupported
+	}
+	return opener.Open(ctx, attachment.Path)
+}
+
+// Verify re-reads an attachment's content and compares it with the checksum
+// recorded at upload. It returns ErrChecksumMismatch when they differ and
+// ErrNoChecksum for attachments uploaded before checksums were kept.
+func (as *ActiveStorage) Verify(attachmentID uint) error {
+	var attachment Attachment
+	if err := as.db.First(&attachment, attachmentID).Error; err != nil {
+		return err
+	}
+	if attachment.Checksum == "" {
+		return ErrNoChecksum
+	}
+
+	r, err := as.Open(context.Background(), &attachment)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return fmt.Errorf("failed to read attachment %d: %w", attachmentID, err)
+	}
+	if hex.EncodeToString(h.Sum(nil)) != attachment.Checksum {
+		return fmt.Errorf("%w: attachment %d", ErrChecksumMismatch, attachmentID)
+	}
+	return nil
+}
+
+// Ping checks that the storage backend is reachable. Providers that can't
+// report their health are assumed to be up.
+func (as *ActiveStorage) Ping(ctx context.Context) error {
+	if pinger, ok := as.provider.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func fileChecksum(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func (as *ActiveStorage) getConfig(modelName, field string) (AttachmentConfig, error) {
 	modelConfigs, ok := as.configs[modelName]
 	if !ok {
@@ -151,13 +269,68 @@ func (as *ActiveStorage) getConfig(modelName, field string) (AttachmentConfig, e
 
 func (as *ActiveStorage) validateFile(file *multipart.FileHeader, config AttachmentConfig) error {
 	if file.Size > config.MaxFileSize {
-		return fmt.Errorf("file size exceeds maximum allowed size of %d bytes", config.MaxFileSize)
+		return &ValidationError{Check: "size", Message: fmt.Sprintf("file size exceeds maximum allowed size of %d bytes", config.MaxFileSize)}
 	}
 
 	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if len(config.AllowedExtensions) > 0 && !strings.Contains(strings.Join(config.AllowedExtensions, ","), ext) {
-		return fmt.Errorf("file extension %s is not allowed", ext)
+	if len(config.AllowedExtensions) > 0 && !slices.Contains(config.AllowedExtensions, ext) {
+		return &ValidationError{Check: "extension", Message: fmt.Sprintf("file extension %s is not allowed", ext)}
+	}
+
+	if len(config.AllowedContentTypes) == 0 && !hasDimensionLimits(config) {
+		return nil
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+
+	if len(config.AllowedContentTypes) > 0 && !slices.Contains(config.AllowedContentTypes, contentType) {
+		return &ValidationError{Check: "content_type", Message: fmt.Sprintf("file content type %s is not allowed", contentType)}
+	}
+
+	if !hasDimensionLimits(config) {
+		return nil
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	img, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return &ValidationError{Check: "content_type", Message: "file is not a readable image"}
+	}
+
+	if img.Width < config.MinWidth || img.Height < config.MinHeight ||
+		config.MaxWidth > 0 && img.Width > config.MaxWidth ||
+		config.MaxHeight > 0 && img.Height > config.MaxHeight {
+		return &ValidationError{Check: "dimensions", Message: fmt.Sprintf(
+			"image is %dx%d pixels, allowed range is %dx%d to %s",
+			img.Width, img.Height, config.MinWidth, config.MinHeight, maxDimensions(config))}
 	}
 
 	return nil
 }
+
+func hasDimensionLimits(config AttachmentConfig) bool {
+	return config.MinWidth > 0 || config.MinHeight > 0 || config.MaxWidth > 0 || config.MaxHeight > 0
+}
+
+func maxDimensions(config AttachmentConfig) string {
+	limit := func(v int) string {
+		if v <= 0 {
+			return "any"
+		}
+		return strconv.Itoa(v)
+	}
+	return limit(config.MaxWidth) + "x" + limit(config.MaxHeight)
+}
@@ -1,11 +1,15 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // LocalConfig holds configuration for local storage
@@ -78,3 +82,51 @@ func (p *localProvider) Delete(path string) error {
 func (p *localProvider) GetURL(path string) string {
 	return fmt.Sprintf("%s/%s", p.baseURL, path)
 }
+
+// Ping checks that the base directory still exists
+func (p *localProvider) Ping(ctx context.Context) error {
+	info, err := os.Stat(p.basePath)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", p.basePath)
+	}
+	return nil
+}
+
+// Open opens the file at path, relative to the base directory
+func (p *localProvider) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(p.basePath, path))
+}
+
+// List walks the files under prefix, relative to the base directory
+func (p *localProvider) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	root := filepath.Join(p.basePath, prefix)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(p.basePath, path)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectInfo{Path: rel, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	return objects, err
+}
@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"strings"
 
@@ -109,3 +111,28 @@ func (p *r2Provider) GetURL(path string) string {
 	// Last resort: use R2 URL
 	return fmt.Sprintf("https://%s/%s/%s", p.endpoint, p.bucket, path)
 }
+
+// Ping checks that the bucket is reachable with the configured credentials
+func (p *r2Provider) Ping(ctx context.Context) error {
+	_, err := p.client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(p.bucket),
+	})
+	return err
+}
+
+// Open streams the object at path
+func (p *r2Provider) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	out, err := p.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// List returns the objects under prefix
+func (p *r2Provider) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	return listBucket(ctx, p.client, p.bucket, prefix)
+}
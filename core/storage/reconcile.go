@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultReconcileMinAge keeps Reconcile away from uploads whose attachment
+// row may not be saved yet
+const DefaultReconcileMinAge = time.Hour
+
+// ErrListUnsupported means the provider can't list its objects
+var ErrListUnsupported = errors.New("storage provider can't list files")
+
+// ReconcileOptions controls a Reconcile run
+type ReconcileOptions struct {
+	// DryRun reports what would be removed without removing it
+	DryRun bool
+
+	// MinAge skips objects and attachments younger than this; zero uses
+	// DefaultReconcileMinAge
+	MinAge time.Duration
+}
+
+// ReconcileReport lists what Reconcile found, and removed unless it was a dry run
+type ReconcileReport struct {
+	// OrphanedObjects are stored files no attachment references
+	OrphanedObjects []ObjectInfo `json:"orphaned_objects"`
+
+	// MissingObjects are attachments whose file is gone
+	MissingObjects []Attachment `json:"missing_objects"`
+
+	DryRun bool `json:"dry_run"`
+}
+
+// Reconcile compares the files under the registered attachment paths with
+// the attachments table. Files no attachment references are deleted, and
+// attachments whose file is gone are removed, unless opts.DryRun is set.
+// Only paths registered with RegisterAttachment are scanned, so other files
+// sharing the bucket or directory are left alone.
+func (as *ActiveStorage) Reconcile(ctx context.Context, opts ReconcileOptions) (*ReconcileReport, error) {
+	lister, ok := as.provider.(Lister)
+	if !ok {
+		return nil, ErrListUnsupported
+	}
+	if opts.MinAge <= 0 {
+		opts.MinAge = DefaultReconcileMinAge
+	}
+	cutoff := time.Now().Add(-opts.MinAge)
+
+	// List before reading the table: an object saved after this point is
+	// either missing from the listing or already referenced
+	prefixes := as.uploadPaths()
+	stored := make(map[string]ObjectInfo)
+	for _, prefix := range prefixes {
+		objects, err := lister.List(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+		}
+		for _, object := range objects {
+			stored[object.Path] = object
+		}
+	}
+
+	var attachments []Attachment
+	if err := as.db.WithContext(ctx).Find(&attachments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load attachments: %w", err)
+	}
+
+	report := &ReconcileReport{DryRun: opts.DryRun}
+	referenced := make(map[string]bool, len(attachments))
+	for _, attachment := range attachments {
+		referenced[attachment.Path] = true
+		if _, ok := stored[attachment.Path]; ok || attachment.CreatedAt.After(cutoff) {
+			continue
+		}
+		if underAny(attachment.Path, prefixes) {
+			report.MissingObjects = append(report.MissingObjects, attachment)
+		}
+	}
+	for path, object := range stored {
+		if !referenced[path] && object.ModTime.Before(cutoff) {
+			report.OrphanedObjects = append(report.OrphanedObjects, object)
+		}
+	}
+	slices.SortFunc(report.OrphanedObjects, func(a, b ObjectInfo) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	var errs []error
+	for _, object := range report.OrphanedObjects {
+		if err := as.provider.Delete(object.Path); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", object.Path, err))
+		}
+	}
+	for _, attachment := range report.MissingObjects {
+		if err := as.db.WithContext(ctx).Delete(&Attachment{}, attachment.Id).Error; err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete attachment %d: %w", attachment.Id, err))
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// uploadPaths returns the directories registered attachments upload to
+func (as *ActiveStorage) uploadPaths() []string {
+	var paths []string
+	for modelName, fields := range as.configs {
+		for field, config := range fields {
+			path := filepath.Join(config.Path, modelName, field)
+			if !slices.Contains(paths, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func underAny(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestReconcile(t *testing.T) {
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "storage.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(dir, "files")
+	as, err := NewActiveStorage(db, Config{Provider: "local", Path: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	as.RegisterAttachment("docs", AttachmentConfig{Field: "file", Path: "uploads"})
+
+	old := time.Now().Add(-2 * time.Hour)
+	write := func(path string, modTime time.Time) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(path), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(full, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("uploads/docs/file/kept.txt", old)
+	write("uploads/docs/file/orphan.txt", old)
+	write("uploads/docs/file/fresh.txt", time.Now())
+	write("other/unregistered.txt", old)
+
+	attachments := []Attachment{
+		{ModelType: "docs", ModelId: 1, Field: "file", Path: "uploads/docs/file/kept.txt", CreatedAt: old},
+		{ModelType: "docs", ModelId: 2, Field: "file", Path: "uploads/docs/file/gone.txt", CreatedAt: old},
+		{ModelType: "docs", ModelId: 3, Field: "file", Path: "uploads/docs/file/uploading.txt"},
+	}
+	if err := db.Create(&attachments).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	exists := func(path string) bool {
+		_, err := os.Stat(filepath.Join(root, path))
+		return err == nil
+	}
+	check := func(report *ReconcileReport) {
+		t.Helper()
+		if len(report.OrphanedObjects) != 1 || report.OrphanedObjects[0].Path != filepath.FromSlash("uploads/docs/file/orphan.txt") {
+			t.Errorf("orphaned = %+v, want only orphan.txt", report.OrphanedObjects)
+		}
+		if len(report.MissingObjects) != 1 || report.MissingObjects[0].Id != attachments[1].Id {
+			t.Errorf("missing = %+v, want only gone.txt's attachment", report.MissingObjects)
+		}
+	}
+
+	report, err := as.Reconcile(context.Background(), ReconcileOptions{DryRun: true, MinAge: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(report)
+	var count int64
+	db.Model(&Attachment{}).Count(&count)
+	if !exists("uploads/docs/file/orphan.txt") || count != 3 {
+		t.Fatal("dry run removed something")
+	}
+
+	report, err = as.Reconcile(context.Background(), ReconcileOptions{MinAge: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(report)
+	if exists("uploads/docs/file/orphan.txt") {
+		t.Error("orphan not deleted")
+	}
+	for _, path := range []string{"uploads/docs/file/kept.txt", "uploads/docs/file/fresh.txt", "other/unregistered.txt"} {
+		if !exists(path) {
+			t.Errorf("%s deleted", path)
+		}
+	}
+	if err := db.First(&Attachment{}, attachments[1].Id).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("attachment without a file kept: %v", err)
+	}
+	db.Model(&Attachment{}).Count(&count)
+	if count != 2 {
+		t.Errorf("%d attachments left, want kept.txt's and the recent upload's", count)
+	}
+
+	// A second run finds nothing left to do
+	if report, _ := as.Reconcile(context.Background(), ReconcileOptions{MinAge: time.Minute}); len(report.OrphanedObjects)+len(report.MissingObjects) != 0 {
+		t.Errorf("second run = %+v", report)
+	}
+}
@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -95,3 +98,47 @@ func (p *s3Provider) Delete(path string) error {
 func (p *s3Provider) GetURL(path string) string {
 	return fmt.Sprintf("https://%s/%s/%s", p.endpoint, p.bucket, path)
 }
+
+// Ping checks that the bucket is reachable with the configured credentials
+func (p *s3Provider) Ping(ctx context.Context) error {
+	_, err := p.client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(p.bucket),
+	})
+	return err
+}
+
+// Open streams the object at path
+func (p *s3Provider) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	out, err := p.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// List returns the objects under prefix
+func (p *s3Provider) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	return listBucket(ctx, p.client, p.bucket, prefix)
+}
+
+// listBucket lists the objects under prefix in an S3-compatible bucket
+func listBucket(ctx context.Context, client *s3.S3, bucket, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(strings.TrimSuffix(prefix, "/") + "/"),
+	}, func(page *s3.ListObjectsV2Output, last bool) bool {
+		for _, object := range page.Contents {
+			objects = append(objects, ObjectInfo{
+				Path:    aws.StringValue(object.Key),
+				Size:    aws.Int64Value(object.Size),
+				ModTime: aws.TimeValue(object.LastModified),
+			})
+		}
+		return true
+	})
+	return objects, err
+}
@@ -1,9 +1,11 @@
 package storage
 
 import (
+	"context"
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/textproto"
 	"os"
@@ -23,6 +25,7 @@ type Attachment struct {
 	Path      string    `json:"path"`
 	Size      int64     `json:"size"`
 	URL       string    `json:"url"`
+	Checksum  string    `json:"checksum" gorm:"size:64;index"` // hex SHA-256 of the content
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -77,6 +80,27 @@ type AttachmentConfig struct {
 	AllowedExtensions []string
 	MaxFileSize       int64
 	Multiple          bool
+
+	// AllowedContentTypes is checked against the type sniffed from the file's
+	// bytes, not the filename or the client's Content-Type header
+	AllowedContentTypes []string
+
+	// Image dimension limits in pixels; zero disables a limit
+	MinWidth  int
+	MinHeight int
+	MaxWidth  int
+	MaxHeight int
+}
+
+// ValidationError reports which attachment check rejected a file. Check is
+// one of "size", "extension", "content_type" or "dimensions".
+type ValidationError struct {
+	Check   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
 }
 
 // Config holds storage service configuration
@@ -91,6 +115,10 @@ type Config struct {
 	Bucket    string
 	CDN       string
 	Region    string
+
+	// Dedup stores identical uploads once; attachments with the same
+	// checksum share the object, which is removed with the last of them
+	Dedup bool
 }
 
 // Attachable interface for models that can have attachments
@@ -106,11 +134,34 @@ type Provider interface {
 	GetURL(path string) string
 }
 
+// Pinger is implemented by providers that can report whether their backend is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Opener is implemented by providers that can read stored objects back
+type Opener interface {
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// Lister is implemented by providers that can list their stored objects
+type Lister interface {
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
 // ActiveStorage handles file storage operations
 type ActiveStorage struct {
 	db          *gorm.DB
 	provider    Provider
 	defaultPath string
+	dedup       bool
 	configs     map[string]map[string]AttachmentConfig
 }
 
@@ -0,0 +1,272 @@
+package swagger
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"base/core/router"
+)
+
+// Info holds the top-level API information for the generated document
+type Info struct {
+	Title       string
+	Description string
+	Version     string
+}
+
+// securitySchemes describes the schemes routes name with RouterGroup.Security
+var securitySchemes = map[string]any{
+	"BearerAuth": map[string]any{
+		"type":         "http",
+		"scheme":       "bearer",
+		"bearerFormat": "JWT",
+	},
+	"ApiKeyAuth": map[string]any{
+		"type": "apiKey",
+		"in":   "header",
+		"name": "X-Api-Key",
+	},
+	"AdminToken": map[string]any{
+		"type": "apiKey",
+		"in":   "header",
+		"name": "X-Admin-Token",
+	},
+}
+
+// Generator builds an OpenAPI document from the routes registered on a router.
+// Only routes carrying a router.Doc are documented.
+type Generator struct {
+	router *router.Router
+	info   Info
+
+	mu     sync.Mutex
+	models []reflect.Type
+
+	// The served document, built when first requested and rebuilt once the
+	// number of routes or models changes
+	cached       []byte
+	cachedYAML   []byte
+	cachedRoutes int
+	cachedModels int
+}
+
+// NewGenerator creates a new swagger generator for the given router
+func NewGenerator(r *router.Router, info Info) *Generator {
+	return &Generator{
+		router: r,
+		info:   info,
+	}
+}
+
+// RegisterModels adds model types to components/schemas even when no documented
+// route references them, e.g. RegisterModels(User{}, &Post{})
+func (g *Generator) RegisterModels(models ...any) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, model := range models {
+		g.models = append(g.models, reflect.TypeOf(model))
+	}
+}
+
+// GenerateSwaggerDoc builds the OpenAPI document from the registered route
+// metadata. Each call returns a new document the caller may modify.
+func (g *Generator) GenerateSwaggerDoc() map[string]any {
+	g.mu.Lock()
+	models := slices.Clone(g.models)
+	g.mu.Unlock()
+	return g.generate(g.router.Routes(), models)
+}
+
+func (g *Generator) generate(routes []router.Route, models []reflect.Type) map[string]any {
+	paths := make(map[string]any)
+	schemas := newSchemaRegistry()
+	for _, model := range models {
+		schemas.schemaFor(model)
+	}
+
+	for _, route := range routes {
+		if route.Docs == nil {
+			continue
+		}
+
+		path := openAPIPath(route.Path)
+		item, ok := paths[path].(map[string]any)
+		if !ok {
+			item = make(map[string]any)
+			paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = g.operation(route, schemas)
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       g.info.Title,
+			"description": g.info.Description,
+			"version":     g.info.Version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas":         schemas.schemas,
+			"securitySchemes": securitySchemes,
+		},
+	}
+}
+
+// JSONHandler serves the generated document as JSON
+func (g *Generator) JSONHandler() router.HandlerFunc {
+	return func(c *router.Context) error {
+		doc, err := g.JSON()
+		if err != nil {
+			return err
+		}
+		return c.Data(http.StatusOK, "application/json", doc)
+	}
+}
+
+// YAMLHandler serves the generated document as YAML
+func (g *Generator) YAMLHandler() router.HandlerFunc {
+	return func(c *router.Context) error {
+		doc, err := g.YAML()
+		if err != nil {
+			return err
+		}
+		return c.Data(http.StatusOK, "application/yaml", doc)
+	}
+}
+
+// JSON returns the encoded document. It is built once and reused until
+// routes or models are added; the returned bytes must not be modified.
+func (g *Generator) JSON() ([]byte, error) {
+	routes := g.router.Routes()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.document(routes)
+}
+
+// YAML returns the document as YAML, describing the same spec as JSON
+func (g *Generator) YAML() ([]byte, error) {
+	routes := g.router.Routes()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	doc, err := g.document(routes)
+	if err != nil {
+		return nil, err
+	}
+	if g.cachedYAML == nil {
+		if g.cachedYAML, err = jsonToYAML(doc); err != nil {
+			return nil, err
+		}
+	}
+	return g.cachedYAML, nil
+}
+
+// document returns the cached JSON, rebuilding it when routes or models
+// were added since. g.mu must be held.
+func (g *Generator) document(routes []router.Route) ([]byte, error) {
+	if g.cached != nil && g.cachedRoutes == len(routes) && g.cachedModels == len(g.models) {
+		return g.cached, nil
+	}
+
+	doc, err := json.Marshal(g.generate(routes, g.models))
+	if err != nil {
+		return nil, err
+	}
+	g.cached, g.cachedRoutes, g.cachedModels = doc, len(routes), len(g.models)
+	g.cachedYAML = nil
+	return doc, nil
+}
+
+// operation builds the OpenAPI operation object for a documented route
+func (g *Generator) operation(route router.Route, schemas *schemaRegistry) map[string]any {
+	doc := route.Docs
+	op := map[string]any{
+		"summary":     doc.Summary,
+		"description": doc.Description,
+	}
+	if len(doc.Tags) > 0 {
+		op["tags"] = doc.Tags
+	}
+
+	security := doc.Security
+	if len(security) == 0 {
+		security = route.Security
+	}
+	if len(security) > 0 {
+		// One requirement listing every scheme: all of them apply
+		requirement := make(map[string]any, len(security))
+		for _, scheme := range security {
+			requirement[scheme] = []string{}
+		}
+		op["security"] = []map[string]any{requirement}
+	}
+
+	if params := pathParameters(route.Path); len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if doc.Request != nil {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{
+					"schema": schemas.schemaFor(reflect.TypeOf(doc.Request)),
+				},
+			},
+		}
+	}
+
+	status := doc.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := map[string]any{
+		"description": http.StatusText(status),
+	}
+	if doc.Response != nil {
+		response["content"] = map[string]any{
+			"application/json": map[string]any{
+				"schema": schemas.schemaFor(reflect.TypeOf(doc.Response)),
+			},
+		}
+	}
+	op["responses"] = map[string]any{
+		strconv.Itoa(status): response,
+	}
+
+	return op
+}
+
+// openAPIPath converts router path syntax (:id, *path) to OpenAPI syntax ({id}, {path})
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pathParameters returns the OpenAPI parameter list for the path's wildcards
+func pathParameters(path string) []map[string]any {
+	var params []map[string]any
+	for _, segment := range strings.Split(path, "/") {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			params = append(params, map[string]any{
+				"name":     segment[1:],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+	}
+	return params
+}
@@ -0,0 +1,107 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"base/core/router"
+)
+
+type widget struct {
+	Id   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+func newDocumentedRouter() (*router.Router, *Generator) {
+	r := router.New()
+	r.GET("/widgets", func(c *router.Context) error { return c.NoContent() }).Doc(router.Doc{
+		Summary:  "List widgets",
+		Tags:     []string{"Widgets"},
+		Response: []widget{},
+	})
+	g := NewGenerator(r, Info{Title: "Test API", Version: "1.0"})
+	r.GET("/swagger/doc.json", g.JSONHandler())
+	return r, g
+}
+
+func paths(t *testing.T, doc []byte) map[string]any {
+	t.Helper()
+	var spec struct {
+		Paths map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(doc, &spec); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return spec.Paths
+}
+
+func TestJSONIsCachedUntilRoutesChange(t *testing.T) {
+	r, g := newDocumentedRouter()
+
+	first, err := g.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := g.JSON()
+	if &first[0] != &second[0] {
+		t.Error("document rebuilt without changes")
+	}
+	if _, ok := paths(t, first)["/widgets"]; !ok {
+		t.Errorf("paths = %v, want /widgets", paths(t, first))
+	}
+
+	r.POST("/widgets", func(c *router.Context) error { return c.NoContent() }).Doc(router.Doc{
+		Summary: "Create a widget",
+		Tags:    []string{"Widgets"},
+		Request: widget{},
+	})
+	third, _ := g.JSON()
+	if bytes.Equal(first, third) {
+		t.Fatal("document not rebuilt after a route was added")
+	}
+	if item, _ := paths(t, third)["/widgets"].(map[string]any); item["post"] == nil {
+		t.Errorf("/widgets = %v, want the new post operation", item)
+	}
+
+	// Each generated document is the caller's own
+	doc := g.GenerateSwaggerDoc()
+	doc["paths"] = nil
+	if again, _ := g.JSON(); !bytes.Equal(again, third) {
+		t.Error("modifying a generated document changed the served one")
+	}
+}
+
+// TestJSONHandlerConcurrent is meant for go test -race. Routes are all
+// registered before serving, but models may still be added.
+func TestJSONHandlerConcurrent(t *testing.T) {
+	r, g := newDocumentedRouter()
+
+	type gadget struct {
+		Serial string `json:"serial"`
+	}
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i == 10 {
+				g.RegisterModels(gadget{})
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/doc.json", nil))
+			if w.Code != http.StatusOK || !json.Valid(w.Body.Bytes()) {
+				t.Errorf("GET doc.json = %d %q", w.Code, w.Body.String())
+			}
+		}()
+	}
+	wg.Wait()
+
+	doc, _ := g.JSON()
+	if !bytes.Contains(doc, []byte(`"serial"`)) {
+		t.Error("model registered during the requests missing from the document")
+	}
+}
@@ -0,0 +1,111 @@
+package swagger
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"base/core/router"
+)
+
+// cdnAssetsURL is used when the UI is configured to load assets from unpkg
+const cdnAssetsURL = "https://unpkg.com/swagger-ui-dist@5.10.3"
+
+// staticFiles holds the Swagger UI dist so the docs work without internet access
+//
+//go:embed static
+var staticFiles embed.FS
+
+const indexHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{title}}</title>
+    <link rel="stylesheet" type="text/css" href="{{assets}}/swagger-ui.css" />
+    <link rel="icon" type="image/png" href="{{assets}}/favicon-32x32.png" sizes="32x32" />
+    <link rel="icon" type="image/png" href="{{assets}}/favicon-16x16.png" sizes="16x16" />
+    <style>
+        html {
+            box-sizing: border-box;
+            overflow: -moz-scrollbars-vertical;
+            overflow-y: scroll;
+        }
+        *, *:before, *:after {
+            box-sizing: inherit;
+        }
+        body {
+            margin:0;
+            background: #fafafa;
+        }
+    </style>
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="{{assets}}/swagger-ui-bundle.js"></script>
+    <script src="{{assets}}/swagger-ui-standalone-preset.js"></script>
+    <script>
+        window.onload = function() {
+            const ui = SwaggerUIBundle({
+                url: '{{url}}',
+                dom_id: '#swagger-ui',
+                deepLinking: true,
+                presets: [
+                    SwaggerUIBundle.presets.apis,
+                    SwaggerUIStandalonePreset
+                ],
+                plugins: [
+                    SwaggerUIBundle.plugins.DownloadUrl
+                ],
+                layout: "StandaloneLayout"
+            });
+        };
+    </script>
+</body>
+</html>
+`
+
+// UIHandler serves the Swagger UI for a "/swagger/*any" route.
+// It renders index.html pointing at docURL, serves the embedded assets under
+// /swagger/static/ and delegates /doc.json and /doc.yaml to the generated
+// document.
+// With useCDN the HTML references unpkg instead of the embedded assets.
+func (g *Generator) UIHandler(docURL string, useCDN bool) router.HandlerFunc {
+	assets := "/swagger/static"
+	if useCDN {
+		assets = cdnAssetsURL
+	}
+	page := strings.NewReplacer(
+		"{{title}}", g.info.Title+" Documentation",
+		"{{assets}}", assets,
+		"{{url}}", docURL,
+	).Replace(indexHTML)
+
+	static, _ := fs.Sub(staticFiles, "static")
+	docJSON := g.JSONHandler()
+	docYAML := g.YAMLHandler()
+
+	return func(c *router.Context) error {
+		path := c.Param("any")
+
+		switch {
+		case path == "/doc.json":
+			return docJSON(c)
+		case path == "/doc.yaml":
+			return docYAML(c)
+		case path == "/index.html":
+			return c.HTML(http.StatusOK, page)
+		case strings.HasPrefix(path, "/static/"):
+			name := strings.TrimPrefix(path, "/static/")
+			if _, err := fs.Stat(static, name); err != nil {
+				return c.String(http.StatusNotFound, "404 page not found")
+			}
+			c.SetHeader("Cache-Control", "public, max-age=86400")
+			http.ServeFileFS(c.Writer, c.Request, static, name)
+			return nil
+		default:
+			return c.String(http.StatusNotFound, "404 page not found")
+		}
+	}
+}
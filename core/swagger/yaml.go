@@ -0,0 +1,102 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// jsonToYAML converts a JSON document to block-style YAML with the same
+// content. Keys are sorted like encoding/json sorts them, and every string is
+// written as a double-quoted scalar: JSON's escapes are valid there, so
+// strings such as "yes", "1.0" or "null" keep their type when read back.
+func jsonToYAML(doc []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeYAML(&buf, value, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeYAML writes value with its entries at the given indentation level.
+// Nested collections start on a new line; scalars and empty collections are
+// written inline after a single space.
+func writeYAML(buf *bytes.Buffer, value any, indent int) error {
+	pad := strings.Repeat("  ", indent)
+
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			buf.WriteString(" {}\n")
+			return nil
+		}
+		if indent > 0 {
+			buf.WriteByte('\n')
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			buf.WriteString(pad)
+			writeYAMLString(buf, key)
+			buf.WriteByte(':')
+			if err := writeYAML(buf, v[key], indent+1); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString(" []\n")
+			return nil
+		}
+		if indent > 0 {
+			buf.WriteByte('\n')
+		}
+		for _, item := range v {
+			buf.WriteString(pad)
+			buf.WriteByte('-')
+			if err := writeYAML(buf, item, indent+1); err != nil {
+				return err
+			}
+		}
+	default:
+		buf.WriteByte(' ')
+		if err := writeYAMLScalar(buf, v); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+	}
+	return nil
+}
+
+func writeYAMLScalar(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		fmt.Fprint(buf, v)
+	case json.Number:
+		buf.WriteString(v.String())
+	case string:
+		writeYAMLString(buf, v)
+	default:
+		return fmt.Errorf("unexpected %T in document", value)
+	}
+	return nil
+}
+
+func writeYAMLString(buf *bytes.Buffer, s string) {
+	encoded, _ := json.Marshal(s)
+	buf.Write(encoded)
+}
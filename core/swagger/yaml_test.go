@@ -0,0 +1,192 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"base/core/router"
+)
+
+func TestJSONToYAML(t *testing.T) {
+	doc := `{
+		"description": "Key: value # not a comment",
+		"quoted": "say \"hi\" and 'bye'",
+		"typed": ["yes", "1.0", "null", "", "line\nbreak", "- item", "{x}"],
+		"empty": {"map": {}, "list": []},
+		"servers": [{"url": "http://localhost:8080", "vars": {}}, {"url": "/"}],
+		"a:b #c": {"n": 1.5, "ok": true, "none": null}
+	}`
+	want := `"a:b #c":
+  "n": 1.5
+  "none": null
+  "ok": true
+"description": "Key: value # not a comment"
+"empty":
+  "list": []
+  "map": {}
+"quoted": "say \"hi\" and 'bye'"
+"servers":
+  -
+    "url": "http://localhost:8080"
+    "vars": {}
+  -
+    "url": "/"
+"typed":
+  - "yes"
+  - "1.0"
+  - "null"
+  - ""
+  - "line\nbreak"
+  - "- item"
+  - "{x}"
+`
+	got, err := jsonToYAML([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("jsonToYAML =\n%s\nwant\n%s", got, want)
+	}
+	assertRoundTrip(t, []byte(doc), got)
+}
+
+func TestYAMLDescribesTheJSONDocument(t *testing.T) {
+	r, g := newDocumentedRouter()
+	r.POST("/widgets/:id", func(c *router.Context) error { return c.NoContent() }).Doc(router.Doc{
+		Summary:     "Rename a widget",
+		Description: "Renames it: the new name must be unique # per account",
+		Tags:        []string{"Widgets"},
+		Request:     map[string]any{},
+		Response:    []widget{},
+		Security:    []string{"BearerAuth"},
+	})
+
+	doc, err := g.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	yaml, err := g.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRoundTrip(t, doc, yaml)
+}
+
+// assertRoundTrip reads yaml back and compares it with the JSON document
+func assertRoundTrip(t *testing.T, doc, yaml []byte) {
+	t.Helper()
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+	var want any
+	if err := decoder.Decode(&want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readYAML(yaml)
+	if err != nil {
+		t.Fatalf("reading the YAML back: %v\n%s", err, yaml)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("YAML reads back as\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+// yamlLine is a line of block YAML without its indentation
+type yamlLine struct {
+	indent int
+	text   string
+}
+
+// readYAML parses the block YAML jsonToYAML writes: mappings with quoted
+// keys, "-" sequence entries, and JSON scalars, {} and [] as values. It
+// rejects anything else, such as tabs, plain scalars or uneven indentation.
+func readYAML(doc []byte) (any, error) {
+	var lines []yamlLine
+	for _, line := range strings.Split(strings.TrimSuffix(string(doc), "\n"), "\n") {
+		text := strings.TrimLeft(line, " ")
+		indent := len(line) - len(text)
+		if text == "" || strings.HasPrefix(text, "\t") || indent%2 != 0 {
+			return nil, fmt.Errorf("unexpected line %q", line)
+		}
+		lines = append(lines, yamlLine{indent / 2, text})
+	}
+	value, next, err := readYAMLBlock(lines, 0, 0)
+	if err == nil && next != len(lines) {
+		err = fmt.Errorf("unexpected line %q", lines[next].text)
+	}
+	return value, err
+}
+
+// readYAMLBlock reads the collection starting at lines[i] with entries at
+// indent, returning it and the index of the first line after it
+func readYAMLBlock(lines []yamlLine, i, indent int) (any, int, error) {
+	if i >= len(lines) || lines[i].indent != indent {
+		return nil, i, fmt.Errorf("expected a block at indentation %d", indent)
+	}
+
+	if strings.HasPrefix(lines[i].text, "-") {
+		list := []any{}
+		for i < len(lines) && lines[i].indent == indent && strings.HasPrefix(lines[i].text, "-") {
+			value, next, err := readYAMLValue(lines, i, strings.TrimPrefix(lines[i].text, "-"), indent)
+			if err != nil {
+				return nil, i, err
+			}
+			list = append(list, value)
+			i = next
+		}
+		return list, i, nil
+	}
+
+	object := map[string]any{}
+	for i < len(lines) && lines[i].indent == indent {
+		decoder := json.NewDecoder(strings.NewReader(lines[i].text))
+		var key string
+		if err := decoder.Decode(&key); err != nil {
+			return nil, i, fmt.Errorf("key of %q: %w", lines[i].text, err)
+		}
+		rest := lines[i].text[decoder.InputOffset():]
+		if !strings.HasPrefix(rest, ":") {
+			return nil, i, fmt.Errorf("expected : after the key in %q", lines[i].text)
+		}
+		value, next, err := readYAMLValue(lines, i, rest[1:], indent)
+		if err != nil {
+			return nil, i, err
+		}
+		object[key] = value
+		i = next
+	}
+	return object, i, nil
+}
+
+// readYAMLValue reads the value after a key or "-": an inline scalar after
+// one space, or a nested block on the following lines
+func readYAMLValue(lines []yamlLine, i int, inline string, indent int) (any, int, error) {
+	if inline == "" {
+		return readYAMLBlock(lines, i+1, indent+1)
+	}
+	if !strings.HasPrefix(inline, " ") {
+		return nil, i, fmt.Errorf("expected a space before %q", inline)
+	}
+	switch inline = inline[1:]; inline {
+	case "{}":
+		return map[string]any{}, i + 1, nil
+	case "[]":
+		return []any{}, i + 1, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(inline))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return nil, i, fmt.Errorf("scalar %q: %v", inline, err)
+	}
+	switch value.(type) {
+	case map[string]any, []any:
+		return nil, i, fmt.Errorf("flow collection %q", inline)
+	}
+	return value, i + 1, nil
+}
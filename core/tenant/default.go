@@ -0,0 +1,89 @@
+package tenant
+
+import (
+	"context"
+	"sync"
+
+	"base/core/config"
+	"base/core/database"
+
+	"gorm.io/gorm"
+)
+
+var (
+	mu      sync.RWMutex
+	current *Manager
+	models  []any
+)
+
+// Init creates the manager used by DB from the main database and TENANCY.
+// The app calls it once the database is connected.
+func Init(db *gorm.DB, cfg *config.Config) error {
+	m, err := NewManager(db, cfg)
+	if err != nil {
+		return err
+	}
+	SetDefault(m)
+	return nil
+}
+
+// SetDefault sets the manager used by DB
+func SetDefault(m *Manager) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = m
+}
+
+// Default returns the manager set by Init or SetDefault, or nil
+func Default() *Manager {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// DB returns the database services should use for ctx: the database of the
+// request's organization, or the main database in shared mode, when the
+// request has no organization or before Init. Pass the *router.Context of
+// a request, or a context made with WithOrg:
+//
+//	var posts []Post
+//	err := tenant.DB(c).Where("published = ?", true).Find(&posts).Error
+func DB(ctx context.Context) *gorm.DB {
+	if m := Default(); m != nil {
+		return m.DB(ctx)
+	}
+	return database.DB.WithContext(ctx)
+}
+
+// RegisterModels adds models whose tables live in each organization's
+// database. They are migrated by Migrate and when a tenant is first opened,
+// so modules register them in Init instead of migrating them themselves.
+func RegisterModels(m ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	models = append(models, m...)
+}
+
+// Models returns the registered tenant models
+func Models() []any {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]any(nil), models...)
+}
+
+// Migrate migrates the registered models for every organization with the
+// default manager. The app runs it after the modules are initialized.
+func Migrate(ctx context.Context) error {
+	if m := Default(); m != nil {
+		return m.Migrate(ctx)
+	}
+	return nil
+}
+
+// Close closes the default manager's tenant connections
+func Close() error {
+	if m := Default(); m != nil {
+		return m.Close()
+	}
+	return nil
+}
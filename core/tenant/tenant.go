@@ -0,0 +1,307 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"base/core/config"
+	"base/core/database"
+	"base/core/router"
+
+	"gorm.io/gorm"
+)
+
+// Tenancy modes, set with TENANCY
+const (
+	// ModeShared keeps every organization's data in the main database
+	ModeShared = "shared"
+	// ModeSchema gives each organization a Postgres schema in the main database
+	ModeSchema = "schema"
+	// ModeDatabase gives each organization a database of its own, reached
+	// through TENANT_DSN
+	ModeDatabase = "database"
+)
+
+var (
+	ErrInvalidMode = errors.New("TENANCY must be shared, schema or database")
+	ErrNoTenant    = errors.New("request is not scoped to an organization")
+)
+
+// Manager routes GORM calls to the database of the request's organization.
+// Tenant connections are opened on first use, which also creates the
+// organization's schema (or SQLite file) and migrates the registered models.
+type Manager struct {
+	Mode string
+
+	shared       *gorm.DB
+	driver       string
+	dsn          string // main DSN, for schema mode
+	schemaPrefix string
+	tenantDSN    string // TENANT_DSN template, for database mode
+
+	mu      sync.Mutex
+	tenants map[uint64]*gorm.DB
+	opening map[uint64]*opening
+}
+
+// opening is a tenant database being opened; callers asking for the same
+// organization meanwhile wait for done and share the result
+type opening struct {
+	done chan struct{}
+	db   *gorm.DB
+	err  error
+}
+
+// NewManager creates a manager for the mode in cfg. Schema mode needs the
+// postgres driver; database mode needs a TENANT_DSN with an {org} placeholder.
+func NewManager(db *gorm.DB, cfg *config.Config) (*Manager, error) {
+	m := &Manager{
+		Mode:         cfg.Tenancy,
+		shared:       db,
+		driver:       cfg.DBDriver,
+		dsn:          cfg.GetDatabaseDSN(),
+		schemaPrefix: cfg.TenantSchemaPrefix,
+		tenantDSN:    cfg.TenantDSN,
+		tenants:      make(map[uint64]*gorm.DB),
+		opening:      make(map[uint64]*opening),
+	}
+	if m.Mode == "" {
+		m.Mode = ModeShared
+	}
+
+	switch m.Mode {
+	case ModeShared:
+	case ModeSchema:
+		if m.driver != "postgres" {
+			return nil, fmt.Errorf("TENANCY=schema needs the postgres driver, not %s", m.driver)
+		}
+		if m.schemaPrefix == "" || strings.Trim(m.schemaPrefix, "abcdefghijklmnopqrstuvwxyz0123456789_") != "" {
+			return nil, fmt.Errorf("invalid TENANT_SCHEMA_PREFIX: %q", m.schemaPrefix)
+		}
+	case ModeDatabase:
+		if !strings.Contains(m.tenantDSN, "{org}") {
+			return nil, errors.New("TENANCY=database needs a TENANT_DSN containing {org}")
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidMode, m.Mode)
+	}
+	return m, nil
+}
+
+// Shared reports whether all organizations use the main database
+func (m *Manager) Shared() bool {
+	return m.Mode == ModeShared
+}
+
+// DB returns the database for the organization of ctx, bound to ctx. That's
+// the main database in shared mode and for requests without an organization.
+// If the tenant database can't be opened, the returned DB carries the error
+// and every call made with it fails with it.
+func (m *Manager) DB(ctx context.Context) *gorm.DB {
+	orgId := OrgID(ctx)
+	if m.Shared() || orgId == 0 {
+		return m.shared.WithContext(ctx)
+	}
+
+	db, err := m.For(orgId)
+	if err != nil {
+		db = m.shared.Session(&gorm.Session{NewDB: true})
+		db.AddError(err)
+	}
+	return db.WithContext(ctx)
+}
+
+// For returns the database of an organization, opening and migrating it on
+// first use. Concurrent first uses of one organization open it once, and
+// don't hold up requests for other organizations.
+func (m *Manager) For(orgId uint64) (*gorm.DB, error) {
+	if m.Shared() {
+		return m.shared, nil
+	}
+	if orgId == 0 {
+		return nil, ErrNoTenant
+	}
+
+	m.mu.Lock()
+	if db, ok := m.tenants[orgId]; ok {
+		m.mu.Unlock()
+		return db, nil
+	}
+	if o, ok := m.opening[orgId]; ok {
+		m.mu.Unlock()
+		<-o.done
+		return o.db, o.err
+	}
+	o := &opening{done: make(chan struct{})}
+	m.opening[orgId] = o
+	m.mu.Unlock()
+
+	o.db, o.err = m.openMigrated(orgId)
+
+	m.mu.Lock()
+	delete(m.opening, orgId)
+	if o.err == nil {
+		m.tenants[orgId] = o.db
+	}
+	m.mu.Unlock()
+	close(o.done)
+	return o.db, o.err
+}
+
+// openMigrated opens an organization's database and migrates the registered
+// models into it
+func (m *Manager) openMigrated(orgId uint64) (*gorm.DB, error) {
+	db, err := m.open(orgId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database of organization %d: %w", orgId, err)
+	}
+	if err := db.AutoMigrate(Models()...); err != nil {
+		closeDB(db)
+		return nil, fmt.Errorf("failed to migrate database of organization %d: %w", orgId, err)
+	}
+	return db, nil
+}
+
+// open connects to an organization's schema or database, creating the
+// schema or SQLite file when missing
+func (m *Manager) open(orgId uint64) (*gorm.DB, error) {
+	id := strconv.FormatUint(orgId, 10)
+	gormConfig := &gorm.Config{Logger: m.shared.Config.Logger}
+
+	if m.Mode == ModeSchema {
+		schema := m.schemaPrefix + id
+		if err := m.shared.Exec(`CREATE SCHEMA IF NOT EXISTS "` + schema + `"`).Error; err != nil {
+			return nil, err
+		}
+		return database.Open(m.driver, withSearchPath(m.dsn, schema), gormConfig)
+	}
+
+	dsn := strings.ReplaceAll(m.tenantDSN, "{org}", id)
+	if m.driver == "sqlite" {
+		if err := os.MkdirAll(filepath.Dir(dsn), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return database.Open(m.driver, dsn, gormConfig)
+}
+
+// withSearchPath adds search_path to a Postgres DSN, in either the URL or
+// the key=value form, so every connection of the pool uses schema
+func withSearchPath(dsn, schema string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		if u, err := url.Parse(dsn); err == nil {
+			query := u.Query()
+			query.Set("search_path", schema)
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+	}
+	return dsn + " search_path=" + schema
+}
+
+// Migrate migrates the registered models into every known organization's
+// database: those already opened and those with members. In shared mode
+// they are migrated into the main database.
+func (m *Manager) Migrate(ctx context.Context) error {
+	if m.Shared() {
+		return m.shared.WithContext(ctx).AutoMigrate(Models()...)
+	}
+
+	orgIds, err := m.organizations(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	opened := make([]*gorm.DB, 0, len(m.tenants))
+	for orgId, db := range m.tenants {
+		opened = append(opened, db)
+		delete(orgIds, orgId)
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for _, db := range opened {
+		if err := db.WithContext(ctx).AutoMigrate(Models()...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// Opening a tenant migrates it
+	for orgId := range orgIds {
+		if _, err := m.For(orgId); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// organizations returns the ids of organizations that have members
+func (m *Manager) organizations(ctx context.Context) (map[uint64]bool, error) {
+	orgIds := make(map[uint64]bool)
+	if !m.shared.Migrator().HasTable("organization_members") {
+		return orgIds, nil
+	}
+
+	var ids []uint64
+	err := m.shared.WithContext(ctx).
+		Table("organization_members").
+		Distinct("organization_id").
+		Pluck("organization_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	for _, id := range ids {
+		if id != 0 {
+			orgIds[id] = true
+		}
+	}
+	return orgIds, nil
+}
+
+// Close closes the tenant connections; the main database is left open
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for orgId, db := range m.tenants {
+		errs = append(errs, closeDB(db))
+		delete(m.tenants, orgId)
+	}
+	return errors.Join(errs...)
+}
+
+func closeDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+type orgKey struct{}
+
+// WithOrg returns a context whose tenant is orgId, for work done outside of
+// a request, e.g. in jobs
+func WithOrg(ctx context.Context, orgId uint64) context.Context {
+	return context.WithValue(ctx, orgKey{}, orgId)
+}
+
+// OrgID returns the organization of ctx: the one given to WithOrg, or the
+// request's organization resolved by OrganizationContext from Base-Orgid
+func OrgID(ctx context.Context) uint64 {
+	if orgId, ok := ctx.Value(orgKey{}).(uint64); ok {
+		return orgId
+	}
+	if c, ok := ctx.(*router.Context); ok {
+		return c.OrgID()
+	}
+	return 0
+}
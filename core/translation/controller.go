@@ -2,9 +2,17 @@ package translation
 
 import (
 	"base/core/router"
+	"base/core/router/middleware"
 	"base/core/storage"
+	"base/core/types"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 type TranslationController struct {
@@ -23,40 +31,105 @@ func NewTranslationController(service *TranslationService, storage *storage.Acti
 	}
 }
 
-func (c *TranslationController) Routes(router *router.RouterGroup) {
+func (c *TranslationController) Routes(r *router.RouterGroup) {
 	// CRUD operations
-	router.GET("/translations", c.List)
-	router.POST("/translations", c.Create)
+	r.GET("/translations", c.List).Doc(router.Doc{
+		Summary:     "List translations",
+		Description: "Paged with page and limit; model and model_id filter the list",
+		Tags:        []string{"Core/Translations"},
+		Response:    types.PaginatedResponse{},
+	})
+	r.POST("/translations", c.Create).Doc(router.Doc{
+		Summary:  "Create a translation",
+		Tags:     []string{"Core/Translations"},
+		Request:  CreateTranslationRequest{},
+		Response: TranslationResponse{},
+		Status:   http.StatusCreated,
+	})
 
 	// Bulk operations - MUST come before parameterized routes
-	router.POST("/translations/bulk", c.BulkUpdate)
+	r.POST("/translations/bulk", c.BulkUpdate).Doc(router.Doc{
+		Summary:  "Update the translations of a model in bulk",
+		Tags:     []string{"Core/Translations"},
+		Request:  BulkTranslationRequest{},
+		Response: map[string]string{},
+	})
 
 	// Utility endpoints - MUST come before parameterized routes
-	router.GET("/translations/languages", c.GetSupportedLanguages)
+	r.GET("/translations/languages", c.GetSupportedLanguages).Doc(router.Doc{
+		Summary:  "List the languages that have translations",
+		Tags:     []string{"Core/Translations"},
+		Response: []string{},
+	})
+
+	// API messages per language, with file import/export for translators
+	r.GET("/translations/languages/:language", c.GetMessages).Doc(router.Doc{
+		Summary:  "Get the API messages of a language, by key",
+		Tags:     []string{"Core/Translations"},
+		Response: map[string]string{},
+	})
+	r.GET("/translations/languages/:language/missing", c.GetMissing).Doc(router.Doc{
+		Summary:  "List the API message keys a language has no translation for",
+		Tags:     []string{"Core/Translations"},
+		Response: []string{},
+	})
+	r.GET("/translations/languages/:language/export", c.Export).Doc(router.Doc{
+		Summary:     "Export the API messages of a language",
+		Description: "Downloads every API message key with its translation as a JSON (format=json, the default) or PO (format=po) file; untranslated keys are empty",
+		Tags:        []string{"Core/Translations"},
+	})
+	imports := r.Group("").Set(middleware.ContentTypesKey, []string{"multipart/form-data", "text/csv", "text/plain"})
+	imports.POST("/translations/languages/:language/import", c.Import).Doc(router.Doc{
+		Summary:     "Import the API messages of a language",
+		Description: "Stores the translations of a JSON ({\"key\": \"value\"}) or PO file, sent as the request body or as the multipart field \"file\"; the format comes from ?format or the file name. Empty values are skipped.",
+		Tags:        []string{"Core/Translations"},
+		Response:    ImportResponse{},
+	})
+	r.PUT("/translations/languages/:language/keys/:key", c.SetMessage).Doc(router.Doc{
+		Summary: "Set the translation of an API message",
+		Tags:    []string{"Core/Translations"},
+		Request: SetMessageRequest{},
+		Status:  http.StatusNoContent,
+	})
+	r.DELETE("/translations/languages/:language/keys/:key", c.DeleteMessage).Doc(router.Doc{
+		Summary:     "Delete the translation of an API message",
+		Description: "The English message is used again",
+		Tags:        []string{"Core/Translations"},
+		Status:      http.StatusNoContent,
+	})
 
 	// Model-specific operations - MUST come before parameterized routes
-	router.GET("/translations/models/:model/:model_id", c.GetForModel)
-	router.GET("/translations/models/:model/:model_id/:language", c.GetForModelAndLanguage)
+	r.GET("/translations/models/:model/:model_id", c.GetForModel).Doc(router.Doc{
+		Summary:  "Get the translations of a model",
+		Tags:     []string{"Core/Translations"},
+		Response: map[string]string{},
+	})
+	r.GET("/translations/models/:model/:model_id/:language", c.GetForModelAndLanguage).Doc(router.Doc{
+		Summary:  "Get the translations of a model in a language",
+		Tags:     []string{"Core/Translations"},
+		Response: map[string]string{},
+	})
 
 	// CRUD operations with :id parameter - MUST come LAST
-	router.GET("/translations/by-id/:id", c.Get)
-	router.PUT("/translations/by-id/:id", c.Update)
-	router.DELETE("/translations/by-id/:id", c.Delete)
+	r.GET("/translations/by-id/:id", c.Get).Doc(router.Doc{
+		Summary:  "Get a translation",
+		Tags:     []string{"Core/Translations"},
+		Response: TranslationResponse{},
+	})
+	r.PUT("/translations/by-id/:id", c.Update).Doc(router.Doc{
+		Summary:  "Update a translation",
+		Tags:     []string{"Core/Translations"},
+		Request:  UpdateTranslationRequest{},
+		Response: TranslationResponse{},
+	})
+	r.DELETE("/translations/by-id/:id", c.Delete).Doc(router.Doc{
+		Summary: "Delete a translation",
+		Tags:    []string{"Core/Translations"},
+		Status:  http.StatusNoContent,
+	})
 }
 
-// List godoc
-// @Summary List translations
-// @Description Get a paginated list of translations with optional filtering
-// @Tags Core/Translations
-// @Security ApiKeyAuth
-// @Produce json
-// @Param page query int false "Page number"
-// @Param limit query int false "Number of items per page"
-// @Param model query string false "Filter by model name"
-// @Param model_id query int false "Filter by model ID"
-// @Success 200 {object} types.PaginatedResponse
-// @Failure 500 {object} types.ErrorResponse
-// @Router /translations [get]
+// List handles GET /translations
 func (c *TranslationController) List(ctx *router.Context) error {
 	var page, limit *int
 	var modelId *uint
@@ -98,18 +171,7 @@ func (c *TranslationController) List(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, paginatedResponse)
 }
 
-// Get godoc
-// @Summary Get translation by ID
-// @Description Get a single translation by its ID
-// @Tags Core/Translations
-// @Security ApiKeyAuth
-// @Produce json
-// @Param id path int true "Translation ID"
-// @Success 200 {object} translation.TranslationResponse
-// @Failure 400 {object} types.ErrorResponse
-// @Failure 404 {object} types.ErrorResponse
-// @Failure 500 {object} types.ErrorResponse
-// @Router /translations/by-id/{id} [get]
+// Get handles GET /translations/by-id/:id
 func (c *TranslationController) Get(ctx *router.Context) error {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -129,18 +191,7 @@ func (c *TranslationController) Get(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, translation)
 }
 
-// Create godoc
-// @Summary Create translation
-// @Description Create a new translation
-// @Tags Core/Translations
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param translation body 	translation.CreateTranslationRequest true "Translation data"
-// @Success 201 {object} translation.TranslationResponse
-// @Failure 400 {object} types.ErrorResponse
-// @Failure 500 {object} types.ErrorResponse
-// @Router /translations [post]
+// Create handles POST /translations
 func (c *TranslationController) Create(ctx *router.Context) error {
 	var request CreateTranslationRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
@@ -155,20 +206,7 @@ func (c *TranslationController) Create(ctx *router.Context) error {
 	return ctx.JSON(http.StatusCreated, translation)
 }
 
-// Update godoc
-// @Summary Update translation
-// @Description Update an existing translation
-// @Tags Core/Translations
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param id path int true "Translation ID"
-// @Param translation body translation.UpdateTranslationRequest true "Translation data"
-// @Success 200 {object} translation.TranslationResponse
-// @Failure 400 {object} types.ErrorResponse
-// @Failure 404 {object} types.ErrorResponse
-// @Failure 500 {object} types.ErrorResponse
-// @Router /translations/by-id/{id} [put]
+// Update handles PUT /translations/by-id/:id
 func (c *TranslationController) Update(ctx *router.Context) error {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -194,17 +232,7 @@ func (c *TranslationController) Update(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, translation)
 }
 
-// Delete godoc
-// @Summary Delete translation
-// @Description Delete a translation by ID
-// @Tags Core/Translations
-// @Security ApiKeyAuth
-// @Param id path int true "Translation ID"
-// @Success 204
-// @Failure 400 {object} types.ErrorResponse
-// @Failure 404 {object} types.ErrorResponse
-// @Failure 500 {object} types.ErrorResponse
-// @Router /translations/by-id/{id} [delete]
+// Delete handles DELETE /translations/by-id/:id
 func (c *TranslationController) Delete(ctx *router.Context) error {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -225,18 +253,7 @@ func (c *TranslationController) Delete(ctx *router.Context) error {
 	return nil
 }
 
-// BulkUpdate godoc
-// @Summary Bulk update translations
-// @Description Update multiple translations for a model at once
-// @Tags Core/Translations
-// @Security ApiKeyAuth
-// @Accept json
-// @Produce json
-// @Param bulk body translation.BulkTranslationRequest true "Bulk translation data"
-// @Success 200 {object} map[string]string
-// @Failure 400 {object} types.ErrorResponse
-// @Failure 500 {object} types.ErrorResponse
-// @Router /translations/bulk [post]
+// BulkUpdate handles POST /translations/bulk
 func (c *TranslationController) BulkUpdate(ctx *router.Context) error {
 	var request BulkTranslationRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
@@ -251,18 +268,7 @@ func (c *TranslationController) BulkUpdate(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, map[string]any{"message": "Translations updated successfully"})
 }
 
-// GetForModel godoc
-// @Summary Get translations for model
-// @Description Get all translations for a specific model and model ID
-// @Tags Core/Translations
-// @Security ApiKeyAuth
-// @Produce json
-// @Param model path string true "Model name"
-// @Param model_id path int true "Model ID"
-// @Success 200 {object} map[string]string
-// @Failure 400 {object} types.ErrorResponse
-// @Failure 500 {object} types.ErrorResponse
-// @Router /translations/models/{model}/{model_id} [get]
+// GetForModel handles GET /translations/models/:model/:model_id
 func (c *TranslationController) GetForModel(ctx *router.Context) error {
 	model := ctx.Param("model")
 	modelIdStr := ctx.Param("model_id")
@@ -280,19 +286,7 @@ func (c *TranslationController) GetForModel(ctx *router.Context) error {
 	return ctx.JSON(http.StatusOK, translations)
 }
 
-// GetForModelAndLanguage godoc
-// @Summary Get translations for model and language
-// @Description Get translations for a specific model, model ID, and language
-// @Tags Core/Translations
-// @Security ApiKeyAuth
-// @Produce json
-// @Param model path string true "Model name"
-// @Param model_id path int true "Model ID"
-// @Param language path string true "Language code"
-// @Success 200 {object} translation.TranslationResponse
-// @Failure 400 {object} types.ErrorResponse
-// @Failure 500 {object} types.ErrorResponse
-// @Router /translations/models/{model}/{model_id}/{language} [get]
+// GetForModelAndLanguage handles GET /translations/models/:model/:model_id/:language
 func (c *TranslationController) GetForModelAndLanguage(ctx *router.Context) error {
 	model := ctx.Param("model")
 	modelIdStr := ctx.Param("model_id")
@@ -311,15 +305,7 @@ func (c *TranslationController) GetForModelAndLanguage(ctx *router.Context) erro
 	return ctx.JSON(http.StatusOK, translations)
 }
 
-// GetSupportedLanguages godoc
-// @Summary Get supported languages
-// @Description Get a list of all languages that have translations in the system
-// @Tags Core/Translations
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {array} string
-// @Failure 500 {object} types.ErrorResponse
-// @Router /translations/languages [get]
+// GetSupportedLanguages handles GET /translations/languages
 func (c *TranslationController) GetSupportedLanguages(ctx *router.Context) error {
 	languages, err := c.Service.GetSupportedLanguages()
 	if err != nil {
@@ -328,3 +314,132 @@ func (c *TranslationController) GetSupportedLanguages(ctx *router.Context) error
 
 	return ctx.JSON(http.StatusOK, languages)
 }
+
+// SetMessageRequest represents the request payload for setting an API message
+type SetMessageRequest struct {
+	Value string `json:"value" binding:"required"`
+}
+
+// ImportResponse reports how many messages an import stored
+type ImportResponse struct {
+	Imported int `json:"imported"`
+}
+
+// maxImportSize bounds uploaded translation files
+const maxImportSize = 10 << 20
+
+// GetMessages handles GET /translations/languages/:language
+func (c *TranslationController) GetMessages(ctx *router.Context) error {
+	messages, err := c.Service.Messages(ctx.Param("language"))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch translations: " + err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, messages)
+}
+
+// GetMissing handles GET /translations/languages/:language/missing
+func (c *TranslationController) GetMissing(ctx *router.Context) error {
+	missing, err := c.Service.Missing(ctx.Param("language"))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch missing translations: " + err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, missing)
+}
+
+// SetMessage handles PUT /translations/languages/:language/keys/:key
+func (c *TranslationController) SetMessage(ctx *router.Context) error {
+	var request SetMessageRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil || request.Value == "" {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request data: value is required"})
+	}
+
+	err := c.Service.Set(ctx.Param("language"), ctx.Param("key"), request.Value)
+	if errors.Is(err, ErrInvalidLanguage) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to set translation: " + err.Error()})
+	}
+
+	ctx.Status(http.StatusNoContent)
+	return nil
+}
+
+// DeleteMessage handles DELETE /translations/languages/:language/keys/:key
+func (c *TranslationController) DeleteMessage(ctx *router.Context) error {
+	err := c.Service.Unset(ctx.Param("language"), ctx.Param("key"))
+	if err != nil {
+		if err.Error() == "translation not found" {
+			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete translation: " + err.Error()})
+	}
+
+	ctx.Status(http.StatusNoContent)
+	return nil
+}
+
+// Export handles GET /translations/languages/:language/export
+func (c *TranslationController) Export(ctx *router.Context) error {
+	language := ctx.Param("language")
+	format := ctx.DefaultQuery("format", FormatJSON)
+
+	var buf bytes.Buffer
+	err := c.Service.Export(&buf, language, format)
+	if errors.Is(err, ErrInvalidLanguage) || errors.Is(err, ErrUnsupportedFormat) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to export translations: " + err.Error()})
+	}
+
+	contentType := "application/json"
+	if format == FormatPO {
+		contentType = "text/x-gettext-translation; charset=utf-8"
+	}
+	ctx.SetHeader("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, language, format))
+	return ctx.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// Import handles POST /translations/languages/:language/import
+func (c *TranslationController) Import(ctx *router.Context) error {
+	format := ctx.Query("format")
+	var body io.Reader = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize)
+
+	if strings.HasPrefix(ctx.GetHeader("Content-Type"), "multipart/") {
+		file, err := ctx.FormFile("file")
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "file is required"})
+		}
+		if file.Size > maxImportSize {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Translation file is too large"})
+		}
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+		}
+		f, err := file.Open()
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read translation file"})
+		}
+		defer f.Close()
+		body = f
+	}
+	if format == "" {
+		format = FormatJSON
+	}
+
+	imported, err := c.Service.Import(body, ctx.Param("language"), format)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, ErrInvalidLanguage), errors.Is(err, ErrUnsupportedFormat),
+			errors.Is(err, ErrInvalidFile), errors.As(err, &maxBytesErr):
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to import translations: " + err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, ImportResponse{Imported: imported})
+}
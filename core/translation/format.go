@@ -0,0 +1,131 @@
+package translation
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Message file formats for Import and Export
+const (
+	FormatJSON = "json"
+	FormatPO   = "po"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported translation file format")
+	ErrInvalidFile       = errors.New("invalid translation file")
+)
+
+// encodeJSON writes messages as a flat {"key": "value"} object
+func encodeJSON(w io.Writer, messages map[string]string) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(messages)
+}
+
+// decodeJSON reads a flat {"key": "value"} object
+func decodeJSON(r io.Reader) (map[string]string, error) {
+	var messages map[string]string
+	if err := json.NewDecoder(r).Decode(&messages); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	return messages, nil
+}
+
+// encodePO writes messages as a gettext PO file with keys as msgids. When
+// sources has the English text for a key it is added as a translator comment.
+func encodePO(w io.Writer, language string, messages, sources map[string]string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "msgid \"\"\nmsgstr \"\"\n\"Language: %s\\n\"\n\"Content-Type: text/plain; charset=UTF-8\\n\"\n", poEscape(language))
+
+	keys := make([]string, 0, len(messages))
+	for key := range messages {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		bw.WriteString("\n")
+		if source, ok := sources[key]; ok {
+			for _, line := range strings.Split(source, "\n") {
+				fmt.Fprintf(bw, "#. %s\n", line)
+			}
+		}
+		fmt.Fprintf(bw, "msgid \"%s\"\nmsgstr \"%s\"\n", poEscape(key), poEscape(messages[key]))
+	}
+	return bw.Flush()
+}
+
+// decodePO reads the msgid/msgstr pairs of a PO file. Comments, the header
+// entry and contexts are skipped; plural forms aren't supported.
+func decodePO(r io.Reader) (map[string]string, error) {
+	messages := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var msgid, msgstr, context strings.Builder
+	var current *strings.Builder
+	var hasEntry bool
+
+	flush := func() {
+		if hasEntry && msgid.Len() > 0 {
+			messages[msgid.String()] = msgstr.String()
+		}
+		msgid.Reset()
+		msgstr.Reset()
+		current = nil
+		hasEntry = false
+	}
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#"):
+			continue
+		case strings.HasPrefix(text, "msgctxt "):
+			flush()
+			context.Reset()
+			current = &context
+			text = strings.TrimPrefix(text, "msgctxt ")
+		case strings.HasPrefix(text, "msgid_plural ") || strings.HasPrefix(text, "msgstr["):
+			return nil, fmt.Errorf("%w: line %d: plural forms are not supported", ErrInvalidFile, line)
+		case strings.HasPrefix(text, "msgid "):
+			if hasEntry || msgid.Len() > 0 {
+				flush()
+			}
+			current = &msgid
+			text = strings.TrimPrefix(text, "msgid ")
+		case strings.HasPrefix(text, "msgstr "):
+			current = &msgstr
+			hasEntry = true
+			text = strings.TrimPrefix(text, "msgstr ")
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("%w: line %d: unexpected %q", ErrInvalidFile, line, text)
+		}
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid string %s", ErrInvalidFile, line, text)
+		}
+		current.WriteString(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	flush()
+
+	return messages, nil
+}
+
+// poEscape quotes s for a PO string; PO uses the C escapes Go unquotes
+func poEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return r.Replace(s)
+}
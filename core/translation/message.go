@@ -0,0 +1,284 @@
+package translation
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var defaultLocale = "en"
+
+// SetDefaultLocale sets the last language Lookup falls back to
+func SetDefaultLocale(locale string) {
+	defaultLocale = normalizeLanguage(locale)
+}
+
+// DefaultLocale returns the language Lookup falls back to last
+func DefaultLocale() string {
+	return defaultLocale
+}
+
+// normalizeLanguage lowercases a language tag and uses - between its parts,
+// so pt_BR and pt-BR are stored and looked up as pt-br
+func normalizeLanguage(language string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(language)), "_", "-")
+}
+
+// Fallbacks returns the languages tried for language, most specific first:
+// the language itself, each shorter prefix of its tag, then the default
+// locale. For pt-BR with the default locale en that is pt-br, pt, en.
+func Fallbacks(language string) []string {
+	var chain []string
+	for tag := normalizeLanguage(language); tag != ""; {
+		chain = append(chain, tag)
+		i := strings.LastIndex(tag, "-")
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+	if defaultLocale != "" && !slices.Contains(chain, defaultLocale) {
+		chain = append(chain, defaultLocale)
+	}
+	return chain
+}
+
+// Format fills in an ICU-style message. {name} is replaced by args["name"],
+// and plural and select arguments pick one of their forms:
+//
+//	{count, plural, =0 {No items} one {# item} other {# items}}
+//	{role, select, admin {Administrator} other {Member}}
+//
+// Inside a plural form # is the count. Plural forms are matched by exact
+// value (=0, =1, ...) first, then zero for a count of 0, then the language's
+// plural category (one, two, few, many), then other. Placeholders without a
+// matching arg are left as written.
+func Format(language, message string, args map[string]any) string {
+	if !strings.ContainsRune(message, '{') {
+		return message
+	}
+	return formatMessage(normalizeLanguage(language), message, args, "")
+}
+
+// formatMessage formats message; hash is what # stands for inside a plural
+// form, empty elsewhere
+func formatMessage(language, message string, args map[string]any, hash string) string {
+	var out strings.Builder
+	for i := 0; i < len(message); i++ {
+		switch message[i] {
+		case '#':
+			if hash != "" {
+				out.WriteString(hash)
+				continue
+			}
+		case '{':
+			end := matchingBrace(message, i)
+			if end < 0 {
+				out.WriteString(message[i:])
+				return out.String()
+			}
+			out.WriteString(formatArgument(language, message[i+1:end], args))
+			i = end
+			continue
+		}
+		out.WriteByte(message[i])
+	}
+	return out.String()
+}
+
+// matchingBrace returns the index of the } closing the { at start, or -1
+func matchingBrace(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// formatArgument formats the inside of a {...} placeholder
+func formatArgument(language, argument string, args map[string]any) string {
+	name, rest, hasStyle := strings.Cut(argument, ",")
+	name = strings.TrimSpace(name)
+	value, ok := args[name]
+	if !ok {
+		return "{" + argument + "}"
+	}
+	if !hasStyle {
+		return fmt.Sprint(value)
+	}
+
+	kind, body, _ := strings.Cut(rest, ",")
+	forms := parseForms(body)
+	switch strings.TrimSpace(kind) {
+	case "plural":
+		n, ok := toFloat(value)
+		if !ok {
+			return "{" + argument + "}"
+		}
+		form, ok := forms["="+formatNumber(n)]
+		if !ok && n == 0 {
+			form, ok = forms["zero"]
+		}
+		if !ok {
+			form, ok = forms[PluralCategory(language, n)]
+		}
+		if !ok {
+			form = forms["other"]
+		}
+		return formatMessage(language, form, args, formatNumber(n))
+	case "select":
+		form, ok := forms[fmt.Sprint(value)]
+		if !ok {
+			form = forms["other"]
+		}
+		return formatMessage(language, form, args, "")
+	}
+	return fmt.Sprint(value)
+}
+
+// parseForms splits "one {# item} other {# items}" into its forms
+func parseForms(body string) map[string]string {
+	forms := make(map[string]string)
+	for {
+		open := strings.IndexByte(body, '{')
+		if open < 0 {
+			return forms
+		}
+		end := matchingBrace(body, open)
+		if end < 0 {
+			return forms
+		}
+		forms[strings.TrimSpace(body[:open])] = body[open+1 : end]
+		body = body[end+1:]
+	}
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// PluralCategory returns the CLDR plural category (one, two, few, many or
+// other) of n in language. Common languages are covered; the rest use the
+// English rule of one for exactly 1.
+func PluralCategory(language string, n float64) string {
+	tag := normalizeLanguage(language)
+	primary, _, _ := strings.Cut(tag, "-")
+	integer := n == math.Trunc(n) && n >= 0
+	i := int64(n)
+
+	switch primary {
+	case "ja", "zh", "ko", "vi", "th", "id", "ms", "lo", "my", "km":
+		return "other"
+	case "fr", "hi", "bn", "fa", "hy", "kab":
+		if n >= 0 && n < 2 {
+			return "one"
+		}
+		return "other"
+	case "pt":
+		if tag == "pt-pt" {
+			break
+		}
+		if n >= 0 && n < 2 {
+			return "one"
+		}
+		return "other"
+	case "ru", "uk", "be", "sr", "hr", "bs":
+		if !integer {
+			return "other"
+		}
+		switch {
+		case i%10 == 1 && i%100 != 11:
+			return "one"
+		case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+			return "few"
+		}
+		return "many"
+	case "pl":
+		if !integer {
+			return "other"
+		}
+		switch {
+		case i == 1:
+			return "one"
+		case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+			return "few"
+		}
+		return "many"
+	case "cs", "sk":
+		if !integer {
+			return "many"
+		}
+		switch {
+		case i == 1:
+			return "one"
+		case i >= 2 && i <= 4:
+			return "few"
+		}
+		return "other"
+	case "ar":
+		if !integer {
+			return "other"
+		}
+		switch {
+		case i == 0:
+			return "zero"
+		case i == 1:
+			return "one"
+		case i == 2:
+			return "two"
+		case i%100 >= 3 && i%100 <= 10:
+			return "few"
+		case i%100 >= 11:
+			return "many"
+		}
+		return "other"
+	}
+
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
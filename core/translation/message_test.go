@@ -0,0 +1,77 @@
+package translation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFallbacks(t *testing.T) {
+	tests := []struct {
+		language string
+		want     []string
+	}{
+		{"pt-BR", []string{"pt-br", "pt", "en"}},
+		{"pt_BR", []string{"pt-br", "pt", "en"}},
+		{"zh-Hant-TW", []string{"zh-hant-tw", "zh-hant", "zh", "en"}},
+		{"en-GB", []string{"en-gb", "en"}},
+		{"", []string{"en"}},
+	}
+	for _, tt := range tests {
+		if got := Fallbacks(tt.language); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Fallbacks(%q) = %v, want %v", tt.language, got, tt.want)
+		}
+	}
+
+	SetDefaultLocale("de")
+	t.Cleanup(func() { SetDefaultLocale("en") })
+	if got := Fallbacks("pt-BR"); !reflect.DeepEqual(got, []string{"pt-br", "pt", "de"}) {
+		t.Errorf("Fallbacks with default de = %v", got)
+	}
+}
+
+func TestFormatPlural(t *testing.T) {
+	const items = "{count, plural, =0 {No items} one {# item} other {# items}}"
+	tests := []struct {
+		language string
+		message  string
+		count    any
+		want     string
+	}{
+		{"en", items, 0, "No items"},
+		{"en", items, 1, "1 item"},
+		{"en", items, 2, "2 items"},
+		{"en", items, 1.5, "1.5 items"},
+		{"en", items, "3", "3 items"},
+		{"fr", items, 1.5, "1.5 item"},
+		{"ja", items, 1, "1 items"},
+		{"en", "{count, plural, zero {none} one {one} other {#}}", 0, "none"},
+		{"ru", "{count, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}", 21, "21 файл"},
+		{"ru", "{count, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}", 3, "3 файла"},
+		{"ru", "{count, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}", 11, "11 файлов"},
+		{"ar", "{count, plural, two {two} few {few} many {many} other {other}}", 2, "two"},
+		{"ar", "{count, plural, two {two} few {few} many {many} other {other}}", 105, "few"},
+	}
+	for _, tt := range tests {
+		got := Format(tt.language, tt.message, map[string]any{"count": tt.count})
+		if got != tt.want {
+			t.Errorf("Format(%s, %v) = %q, want %q", tt.language, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestFormatArguments(t *testing.T) {
+	args := map[string]any{"name": "Ada", "role": "admin", "count": 2}
+	tests := []struct{ message, want string }{
+		{"Hello {name}", "Hello Ada"},
+		{"Hello {missing}", "Hello {missing}"},
+		{"{role, select, admin {Administrator} other {Member}}", "Administrator"},
+		{"{name} has {count, plural, one {# file} other {# files, {name}}}", "Ada has 2 files, Ada"},
+		{"# is literal outside plurals", "# is literal outside plurals"},
+		{"Unclosed {name", "Unclosed {name"},
+	}
+	for _, tt := range tests {
+		if got := Format("en", tt.message, args); got != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"base/core/module"
 	"base/core/router"
 	"base/core/storage"
+	"base/core/types"
 
 	"gorm.io/gorm"
 )
@@ -34,6 +35,12 @@ func NewTranslationModule(db *gorm.DB, router *router.RouterGroup, log logger.Lo
 	return m
 }
 
+// Init registers the service as the translator for API messages
+func (m *Module) Init() error {
+	types.SetTranslator(m.Service)
+	return nil
+}
+
 func (m *Module) Routes(router *router.RouterGroup) {
 	m.Logger.Info("Registering Translation module routes")
 	m.Controller.Routes(router)
@@ -7,6 +7,8 @@ import (
 	"base/core/types"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -105,8 +107,12 @@ func (s *TranslationService) GetByID(id uint) (*TranslationResponse, error) {
 func (s *TranslationService) Create(request *CreateTranslationRequest) (*TranslationResponse, error) {
 	// Check if translation already exists for this key, model, model_id, and language
 	var existing Translation
-	err := s.DB.Where("`key` = ? AND model = ? AND model_id = ? AND language = ?",
-		request.Key, request.Model, request.ModelId, request.Language).First(&existing).Error
+	err := s.DB.Where(map[string]any{
+		"key":      request.Key,
+		"model":    request.Model,
+		"model_id": request.ModelId,
+		"language": request.Language,
+	}).First(&existing).Error
 
 	if err == nil {
 		return nil, errors.New("translation already exists for this key, model, model_id, and language combination")
@@ -189,6 +195,48 @@ func (s *TranslationService) Delete(id uint) error {
 	return nil
 }
 
+// MessagesModel is the model name under which API message keys (e.g. errors.user_not_found) are stored
+const MessagesModel = "messages"
+
+// Lookup returns the message stored for key in language or, failing that,
+// in the nearest of its Fallbacks: pt-BR tries pt-br, then pt, then the
+// default locale. It implements types.Translator so API errors can be
+// localized; callers fall back to the built-in English message after it.
+func (s *TranslationService) Lookup(language, key string) (string, bool) {
+	chain := Fallbacks(language)
+	var translations []Translation
+	err := s.DB.Where(map[string]any{"model": MessagesModel, "model_id": 0, "key": key, "language": chain}).
+		Find(&translations).Error
+	if err != nil || len(translations) == 0 {
+		return "", false
+	}
+
+	for _, language := range chain {
+		for _, translation := range translations {
+			if normalizeLanguage(translation.Language) == language && translation.Value != "" {
+				return translation.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Translate returns the message for key in locale, resolved through the
+// fallback chain and then the built-in English messages, formatted with
+// args; see Format for named placeholders and plural forms. Unknown keys
+// come back as the key itself.
+//
+//	s.Translate("pt-BR", "items.count", map[string]any{"count": 3})
+func (s *TranslationService) Translate(locale, key string, args map[string]any) string {
+	message, ok := s.Lookup(locale, key)
+	if !ok {
+		if message, ok = types.DefaultMessage(key); !ok {
+			return key
+		}
+	}
+	return Format(locale, message, args)
+}
+
 func (s *TranslationService) GetTranslationsForModel(model string, modelId uint, language string) (map[string]string, error) {
 	s.Logger.Info("Fetching translations for model", zap.String("model", model), zap.Uint("model_id", modelId), zap.String("language", language))
 
@@ -244,8 +292,12 @@ func (s *TranslationService) BulkSetTranslations(modelName string, modelId uint,
 
 	for key, value := range translations {
 		var translation Translation
-		err := tx.Where("model = ? AND model_id = ? AND `key` = ? AND language = ?",
-			modelName, modelId, key, language).First(&translation).Error
+		err := tx.Where(map[string]any{
+			"model":    modelName,
+			"model_id": modelId,
+			"key":      key,
+			"language": language,
+		}).First(&translation).Error
 
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			tx.Rollback()
@@ -292,7 +344,7 @@ func (s *TranslationService) GetSupportedLanguages() ([]string, error) {
 func (s *TranslationService) LoadTranslationsForField(field *Field, modelName string, modelId uint, fieldName string) error {
 	// Query translations for this specific field
 	var translations []Translation
-	err := s.DB.Where("model = ? AND model_id = ? AND `key` = ?", modelName, modelId, fieldName).Find(&translations).Error
+	err := s.DB.Where(map[string]any{"model": modelName, "model_id": modelId, "key": fieldName}).Find(&translations).Error
 
 	if err != nil {
 		return err
@@ -310,3 +362,155 @@ func (s *TranslationService) LoadTranslationsForField(field *Field, modelName st
 
 	return nil
 }
+
+// ErrInvalidLanguage is returned for language codes that don't fit the
+// language column
+var ErrInvalidLanguage = errors.New("language must be 1 to 5 characters, e.g. en or pt-BR")
+
+func validLanguage(language string) error {
+	if language == "" || len(language) > 5 || language != normalizeLanguage(language) {
+		return ErrInvalidLanguage
+	}
+	return nil
+}
+
+// Messages returns the API messages stored for language, by key
+func (s *TranslationService) Messages(language string) (map[string]string, error) {
+	return s.GetTranslationsForModel(MessagesModel, 0, normalizeLanguage(language))
+}
+
+// Set stores the API message for key in language, replacing any existing one
+func (s *TranslationService) Set(language, key, value string) error {
+	language = normalizeLanguage(language)
+	if err := validLanguage(language); err != nil {
+		return err
+	}
+	if key == "" {
+		return errors.New("key is required")
+	}
+	return s.BulkSetTranslations(MessagesModel, 0, language, map[string]string{key: value})
+}
+
+// Unset deletes the API message for key in language, so lookups fall back
+// to English
+func (s *TranslationService) Unset(language, key string) error {
+	language = normalizeLanguage(language)
+	result := s.DB.Where(map[string]any{"model": MessagesModel, "model_id": 0, "key": key, "language": language}).
+		Delete(&Translation{})
+	if result.Error != nil {
+		s.Logger.Error("Failed to delete message", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("translation not found")
+	}
+	return nil
+}
+
+// Keys returns every known API message key: the core keys and any key
+// stored for some language
+func (s *TranslationService) Keys() ([]string, error) {
+	var stored []string
+	if err := s.DB.Model(&Translation{}).Where("model = ? AND model_id = ?", MessagesModel, 0).
+		Distinct("key").Pluck("key", &stored).Error; err != nil {
+		return nil, err
+	}
+
+	keys := append(types.MessageKeys(), stored...)
+	slices.Sort(keys)
+	return slices.Compact(keys), nil
+}
+
+// Missing returns the known API message keys that have no translation in
+// language, in order
+func (s *TranslationService) Missing(language string) ([]string, error) {
+	keys, err := s.Keys()
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.Messages(language)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := []string{}
+	for _, key := range keys {
+		if messages[key] == "" {
+			missing = append(missing, key)
+		}
+	}
+	return missing, nil
+}
+
+// Export writes every known API message key with its translation in
+// language as a JSON or PO file. Missing keys are written empty so
+// translators can fill them in and Import the file back.
+func (s *TranslationService) Export(w io.Writer, language, format string) error {
+	language = normalizeLanguage(language)
+	if err := validLanguage(language); err != nil {
+		return err
+	}
+	if format != FormatJSON && format != FormatPO {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	keys, err := s.Keys()
+	if err != nil {
+		return err
+	}
+	stored, err := s.Messages(language)
+	if err != nil {
+		return err
+	}
+
+	messages := make(map[string]string, len(keys))
+	for _, key := range keys {
+		messages[key] = stored[key]
+	}
+
+	if format == FormatPO {
+		return encodePO(w, language, messages, types.DefaultMessages())
+	}
+	return encodeJSON(w, messages)
+}
+
+// Import stores the API messages of a JSON or PO file for language in one
+// transaction and returns how many were stored. Empty values are skipped so
+// an exported file can be re-imported while partly translated.
+func (s *TranslationService) Import(r io.Reader, language, format string) (int, error) {
+	language = normalizeLanguage(language)
+	if err := validLanguage(language); err != nil {
+		return 0, err
+	}
+
+	var messages map[string]string
+	var err error
+	switch format {
+	case FormatJSON:
+		messages, err = decodeJSON(r)
+	case FormatPO:
+		messages, err = decodePO(r)
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	for key, value := range messages {
+		if key == "" || value == "" {
+			delete(messages, key)
+		}
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	if err := s.BulkSetTranslations(MessagesModel, 0, language, messages); err != nil {
+		s.Logger.Error("Failed to import messages", zap.Error(err))
+		return 0, err
+	}
+
+	s.Logger.Info("Messages imported", zap.String("language", language), zap.Int("count", len(messages)))
+	return len(messages), nil
+}
@@ -0,0 +1,204 @@
+package translation
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"base/core/logger"
+	"base/core/types"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestService(t *testing.T) *TranslationService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "translations.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&Translation{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return NewTranslationService(db, nil, nil, logger.NewLoggerFromZap(zap.NewNop()))
+}
+
+func TestSetLookupUnset(t *testing.T) {
+	s := newTestService(t)
+
+	if err := s.Set("fr", "errors.user_not_found", "Utilisateur introuvable"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	got, ok := s.Lookup("fr-CA", "errors.user_not_found")
+	if !ok || got != "Utilisateur introuvable" {
+		t.Errorf("Lookup(fr-CA) = %q, %v; want the fr message", got, ok)
+	}
+	if _, ok := s.Lookup("de", "errors.user_not_found"); ok {
+		t.Errorf("Lookup(de) found a message that was only set for fr")
+	}
+
+	if err := s.Set("fr", "errors.user_not_found", "Aucun utilisateur"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got, _ := s.Lookup("fr", "errors.user_not_found"); got != "Aucun utilisateur" {
+		t.Errorf("Lookup after overwrite = %q", got)
+	}
+
+	if err := s.Unset("fr", "errors.user_not_found"); err != nil {
+		t.Fatalf("Unset: %v", err)
+	}
+	if _, ok := s.Lookup("fr", "errors.user_not_found"); ok {
+		t.Errorf("Lookup found a message after Unset")
+	}
+}
+
+// The key column is a reserved word in MySQL; it must be quoted the way the
+// connected database expects rather than with MySQL backticks
+func TestLookupQuotesKeyForPostgres(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	var queries []string
+	db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		queries = append(queries, tx.Statement.SQL.String())
+	})
+
+	s := NewTranslationService(db, nil, nil, logger.NewLoggerFromZap(zap.NewNop()))
+	s.Lookup("fr", "errors.user_not_found")
+
+	if len(queries) != 1 {
+		t.Fatalf("captured %d queries, want 1", len(queries))
+	}
+	if strings.Contains(queries[0], "`") || !strings.Contains(queries[0], `"key"`) {
+		t.Errorf("query does not quote key for postgres: %s", queries[0])
+	}
+}
+
+func TestMissing(t *testing.T) {
+	s := newTestService(t)
+	if err := s.Set("de", "shop.cart_empty", "Warenkorb ist leer"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("fr", "errors.user_not_found", "Utilisateur introuvable"); err != nil {
+		t.Fatal(err)
+	}
+
+	missing, err := s.Missing("fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keys stored for any language count as known
+	if !slices.Contains(missing, "shop.cart_empty") {
+		t.Errorf("Missing(fr) = %v, want shop.cart_empty", missing)
+	}
+	if slices.Contains(missing, "errors.user_not_found") {
+		t.Errorf("Missing(fr) lists a translated key")
+	}
+	if !slices.IsSorted(missing) {
+		t.Errorf("Missing(fr) is not sorted: %v", missing)
+	}
+	for _, key := range types.MessageKeys() {
+		if key != "errors.user_not_found" && !slices.Contains(missing, key) {
+			t.Errorf("Missing(fr) leaves out core key %s", key)
+		}
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatPO} {
+		t.Run(format, func(t *testing.T) {
+			s := newTestService(t)
+			messages := map[string]string{
+				"errors.user_not_found": "Utilisateur introuvable",
+				"shop.greeting":         "Dit \"bonjour\"\n\tà {name}",
+			}
+			for key, value := range messages {
+				if err := s.Set("fr", key, value); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var file bytes.Buffer
+			if err := s.Export(&file, "fr", format); err != nil {
+				t.Fatal(err)
+			}
+
+			// Into a fresh database, as a translator's edited file would be
+			target := newTestService(t)
+			n, err := target.Import(bytes.NewReader(file.Bytes()), "fr", format)
+			if err != nil {
+				t.Fatalf("Import: %v\n%s", err, file.String())
+			}
+			if n != len(messages) {
+				t.Errorf("imported %d messages, want %d; untranslated keys must be skipped", n, len(messages))
+			}
+			got, _ := target.Messages("fr")
+			if !maps.Equal(got, messages) {
+				t.Errorf("messages = %q, want %q", got, messages)
+			}
+		})
+	}
+}
+
+func TestImportRejectsBadFiles(t *testing.T) {
+	s := newTestService(t)
+	tests := []struct {
+		format, body string
+		want         error
+	}{
+		{FormatJSON, `["not", "an", "object"]`, ErrInvalidFile},
+		{FormatPO, "msgid \"a\"\nmsgid_plural \"as\"\nmsgstr[0] \"x\"\n", ErrInvalidFile},
+		{FormatPO, "msgid \"a\nmsgstr \"x\"\n", ErrInvalidFile},
+		{"xliff", "", ErrUnsupportedFormat},
+	}
+	for _, tt := range tests {
+		if _, err := s.Import(strings.NewReader(tt.body), "fr", tt.format); !errors.Is(err, tt.want) {
+			t.Errorf("Import(%s, %q) = %v, want %v", tt.format, tt.body, err, tt.want)
+		}
+	}
+	if _, err := s.Import(strings.NewReader("{}"), "not a language", FormatJSON); !errors.Is(err, ErrInvalidLanguage) {
+		t.Errorf("Import with a bad language = %v", err)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	s := newTestService(t)
+	s.Set("pt", "shop.items", "{count, plural, one {# item} other {# itens}}")
+	s.Set("en", "shop.items", "{count, plural, one {# item} other {# items}}")
+	s.Set("en", "shop.only_english", "Only in English")
+
+	tests := []struct {
+		locale, key string
+		want        string
+	}{
+		{"pt-BR", "shop.items", "3 itens"},
+		{"de", "shop.items", "3 items"},
+		{"pt-BR", "shop.only_english", "Only in English"},
+		{"pt-BR", "shop.unknown", "shop.unknown"},
+	}
+	for _, tt := range tests {
+		if got := s.Translate(tt.locale, tt.key, map[string]any{"count": 3}); got != tt.want {
+			t.Errorf("Translate(%s, %s) = %q, want %q", tt.locale, tt.key, got, tt.want)
+		}
+	}
+
+	// Keys nobody translated use the built-in English message
+	key := types.MessageKeys()[0]
+	want, _ := types.DefaultMessage(key)
+	if got := s.Translate("pt-BR", key, nil); got != Format("pt-BR", want, nil) {
+		t.Errorf("Translate(%s) = %q, want the built-in %q", key, got, want)
+	}
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"base/core/router"
+	"base/core/validator"
+)
+
+// BindAndValidate binds the request body into obj and validates its binding tags.
+// It returns nil on success, or the per-field errors to send back as a 400 response.
+func BindAndValidate(c *router.Context, obj any) *ValidationErrorResponse {
+	bind := c.ShouldBindJSON
+	if strings.Contains(c.ContentType(), "form") {
+		bind = c.ShouldBind
+	}
+
+	if err := bind(obj); err != nil {
+		return &ValidationErrorResponse{
+			Errors: []ValidationError{{
+				Field:   "body",
+				Rule:    "format",
+				Message: T(c, "errors.invalid_body"),
+			}},
+		}
+	}
+
+	errs := validator.Validate(obj)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	response := &ValidationErrorResponse{Errors: make([]ValidationError, 0, len(errs))}
+	for _, err := range errs {
+		message := err.Message
+		if translated, ok := lookup(Language(c), "validation."+err.Tag); ok {
+			message = fmt.Sprintf(translated, err.Field, err.Param)
+		}
+		response.Errors = append(response.Errors, ValidationError{
+			Field:   err.Field,
+			Rule:    err.Tag,
+			Message: message,
+		})
+	}
+	return response
+}
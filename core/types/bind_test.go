@@ -0,0 +1,103 @@
+package types
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"base/core/router"
+)
+
+type signup struct {
+	Email    string `json:"email" form:"email" binding:"required,email"`
+	Password string `json:"password" form:"password" binding:"required,min=8"`
+}
+
+// bind posts body to a handler calling BindAndValidate and returns its errors
+func bind(t *testing.T, contentType, body string) []ValidationError {
+	t.Helper()
+	var errs *ValidationErrorResponse
+	r := router.New()
+	r.POST("/signup", func(c *router.Context) error {
+		var req signup
+		errs = BindAndValidate(c, &req)
+		return c.NoContent()
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if errs == nil {
+		return nil
+	}
+	return errs.Errors
+}
+
+func TestBindAndValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        []ValidationError
+	}{
+		{
+			name:        "valid",
+			contentType: "application/json",
+			body:        `{"email":"ada@example.com","password":"long-enough"}`,
+		},
+		{
+			name:        "password too short",
+			contentType: "application/json",
+			body:        `{"email":"ada@example.com","password":"short"}`,
+			want:        []ValidationError{{Field: "password", Rule: "min"}},
+		},
+		{
+			name:        "every failing field",
+			contentType: "application/json",
+			body:        `{"email":"not-an-email"}`,
+			want:        []ValidationError{{Field: "email", Rule: "email"}, {Field: "password", Rule: "required"}},
+		},
+		{
+			name:        "malformed body",
+			contentType: "application/json",
+			body:        `{"email":`,
+			want:        []ValidationError{{Field: "body", Rule: "format"}},
+		},
+		{
+			name:        "form body",
+			contentType: "application/x-www-form-urlencoded",
+			body:        url.Values{"email": {"ada@example.com"}, "password": {"short"}}.Encode(),
+			want:        []ValidationError{{Field: "password", Rule: "min"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bind(t, tt.contentType, tt.body)
+			if len(got) != len(tt.want) {
+				t.Fatalf("errors = %+v, want %+v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if got[i].Field != want.Field || got[i].Rule != want.Rule {
+					t.Errorf("errors[%d] = %+v, want field %q rule %q", i, got[i], want.Field, want.Rule)
+				}
+				if got[i].Message == "" {
+					t.Errorf("errors[%d] has no message", i)
+				}
+			}
+		})
+	}
+}
+
+func TestValidationErrorResponseJSON(t *testing.T) {
+	body, err := json.Marshal(ValidationErrorResponse{Errors: []ValidationError{{Field: "password", Rule: "min", Message: "too short"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"errors":[{"field":"password","rule":"min","message":"too short"}]}`; string(body) != want {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+}
@@ -5,6 +5,7 @@ import "errors"
 // ValidationError represents a validation error response
 type ValidationError struct {
 	Field   string `json:"field"`
+	Rule    string `json:"rule"`
 	Message string `json:"message"`
 }
 
@@ -13,11 +14,16 @@ type ValidationErrorResponse struct {
 	Errors []ValidationError `json:"errors"`
 }
 
+// Auth errors shared by the packages that sign users in or up; controllers
+// map them to status codes with errors.Is
 var (
-	ErrInvalidToken    = errors.New("invalid token")
-	ErrUserNotFound    = errors.New("user not found")
-	ErrTokenExpired    = errors.New("token expired")
-	ErrInvalidPassword = errors.New("invalid password")
-	ErrEmailExists     = errors.New("email already exists")
-	ErrInvalidEmail    = errors.New("invalid email")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrTokenExpired       = errors.New("token expired")
+	ErrInvalidPassword    = errors.New("invalid password")
+	ErrEmailExists        = errors.New("email already exists")
+	ErrInvalidEmail       = errors.New("invalid email")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrUserExists         = errors.New("user already exists")
+	ErrLoginDenied        = errors.New("login denied")
 )
@@ -0,0 +1,150 @@
+package types
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"base/core/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtKey is a single signing or verification key identified by its kid
+type jwtKey struct {
+	kid    string
+	method jwt.SigningMethod
+	sign   any // nil for verification-only keys
+	verify any
+}
+
+// KeySet holds the key used to sign new tokens plus older keys that are still
+// accepted for verification, so tokens stay valid while keys are rotated
+type KeySet struct {
+	current jwtKey
+	keys    map[string]jwtKey
+}
+
+// NewHMACKeySet creates an HS256 key set signing with secret
+func NewHMACKeySet(kid, secret string) *KeySet {
+	key := jwtKey{kid: kid, method: jwt.SigningMethodHS256, sign: []byte(secret), verify: []byte(secret)}
+	return &KeySet{current: key, keys: map[string]jwtKey{kid: key}}
+}
+
+// NewRSAKeySet creates an RS256 key set signing with privateKey
+func NewRSAKeySet(kid string, privateKey *rsa.PrivateKey) *KeySet {
+	key := jwtKey{kid: kid, method: jwt.SigningMethodRS256, sign: privateKey, verify: &privateKey.PublicKey}
+	return &KeySet{current: key, keys: map[string]jwtKey{kid: key}}
+}
+
+// AddHMACKey accepts tokens signed with a previous HS256 secret
+func (ks *KeySet) AddHMACKey(kid, secret string) {
+	ks.keys[kid] = jwtKey{kid: kid, method: jwt.SigningMethodHS256, verify: []byte(secret)}
+}
+
+// AddRSAKey accepts tokens signed with a previous RS256 key
+func (ks *KeySet) AddRSAKey(kid string, publicKey *rsa.PublicKey) {
+	ks.keys[kid] = jwtKey{kid: kid, method: jwt.SigningMethodRS256, verify: publicKey}
+}
+
+// Algorithm returns the algorithm used to sign new tokens
+func (ks *KeySet) Algorithm() string {
+	return ks.current.method.Alg()
+}
+
+// Sign signs claims with the current key and sets the kid header
+func (ks *KeySet) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(ks.current.method, claims)
+	token.Header["kid"] = ks.current.kid
+	return token.SignedString(ks.current.sign)
+}
+
+// Parse verifies a token against the key named by its kid header. Tokens
+// without a kid (issued before rotation support) are checked against every
+// key using the token's algorithm.
+func (ks *KeySet) Parse(tokenString string) (*jwt.Token, error) {
+	if token, err := jwt.Parse(tokenString, ks.keyFunc); err == nil || !errors.Is(err, errNoKid) {
+		return token, err
+	}
+
+	var lastErr error = jwt.ErrTokenSignatureInvalid
+	for _, key := range ks.keys {
+		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+			if token.Method.Alg() != key.method.Alg() {
+				return nil, jwt.ErrTokenSignatureInvalid
+			}
+			return key.verify, nil
+		})
+		if err == nil {
+			return token, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+var errNoKid = errors.New("token has no kid header")
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errNoKid
+	}
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	// Reject tokens whose alg doesn't match the key, e.g. HS256 signed with an RSA public key
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	return key.verify, nil
+}
+
+// JWKS returns the RSA public keys as a JSON Web Key Set
+func (ks *KeySet) JWKS() map[string]any {
+	keys := make([]map[string]any, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		publicKey, ok := key.verify.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+		keys = append(keys, map[string]any{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": key.method.Alg(),
+			"kid": key.kid,
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		})
+	}
+	return map[string]any{"keys": keys}
+}
+
+var (
+	keySetMu sync.RWMutex
+	keySet   *KeySet
+)
+
+// SetKeySet installs the key set used to issue and validate tokens
+func SetKeySet(ks *KeySet) {
+	keySetMu.Lock()
+	defer keySetMu.Unlock()
+	keySet = ks
+}
+
+// ActiveKeySet returns the installed key set, or an HS256 set using JWT_SECRET
+// when none has been loaded
+func ActiveKeySet() *KeySet {
+	keySetMu.RLock()
+	ks := keySet
+	keySetMu.RUnlock()
+
+	if ks == nil {
+		return NewHMACKeySet("default", config.NewConfig().JWTSecret)
+	}
+	return ks
+}
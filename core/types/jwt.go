@@ -1,46 +1,136 @@
 package types
 
 import (
-	"base/core/config"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// GenerateJWT creates a new JWT token for the given user ID
+// TokenVersionFunc returns a user's current token version. Bumping the
+// version, e.g. on password change, rejects every token issued before it.
+type TokenVersionFunc func(userID uint) (uint, error)
+
+var (
+	tokenVersionMu sync.RWMutex
+	tokenVersion   TokenVersionFunc
+)
+
+// accessTokenTTL is set from ACCESS_TOKEN_TTL at startup
+var accessTokenTTL = 24 * time.Hour
+
+// SetTokenVersionFunc installs the lookup used to stamp and check token versions
+func SetTokenVersionFunc(fn TokenVersionFunc) {
+	tokenVersionMu.Lock()
+	defer tokenVersionMu.Unlock()
+	tokenVersion = fn
+}
+
+func currentTokenVersion(userID uint) (uint, bool, error) {
+	tokenVersionMu.RLock()
+	fn := tokenVersion
+	tokenVersionMu.RUnlock()
+	if fn == nil {
+		return 0, false, nil
+	}
+	version, err := fn(userID)
+	return version, true, err
+}
+
+// SetAccessTokenTTL sets how long tokens from GenerateJWT last
+func SetAccessTokenTTL(ttl time.Duration) {
+	if ttl > 0 {
+		accessTokenTTL = ttl
+	}
+}
+
+// AccessTokenTTL returns how long tokens from GenerateJWT last
+func AccessTokenTTL() time.Duration {
+	return accessTokenTTL
+}
+
+// GenerateJWT creates a new JWT token for the given user ID, valid for AccessTokenTTL
 func GenerateJWT(userID uint, extend any) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
-	cfg := config.NewConfig()
+	return GenerateJWTWithTTL(userID, extend, AccessTokenTTL())
+}
 
-	claims := token.Claims.(jwt.MapClaims)
-	claims["user_id"] = userID
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix()
-	claims["extend"] = extend
+// GenerateJWTWithTTL creates a new JWT token for the given user ID, valid for ttl
+func GenerateJWTWithTTL(userID uint, extend any, ttl time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"exp":     time.Now().Add(ttl).Unix(),
+		"extend":  extend,
+	}
 
-	tokenString, err := token.SignedString([]byte(cfg.JWTSecret))
+	version, ok, err := currentTokenVersion(userID)
 	if err != nil {
 		return "", err
 	}
+	if ok {
+		claims["token_version"] = version
+	}
 
-	return tokenString, nil
+	return ActiveKeySet().Sign(claims)
 }
 
 // ValidateJWT validates a JWT token and returns the user ID
 func ValidateJWT(tokenString string) (uint, error) {
-	cfg := config.NewConfig()
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		return []byte(cfg.JWTSecret), nil
-	})
-
+	token, err := ActiveKeySet().Parse(tokenString)
 	if err != nil {
 		return 0, err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		// MFA pending tokens only grant access to the 2FA verification step
+		if pending, _ := claims["mfa_pending"].(bool); pending {
+			return 0, jwt.ErrTokenInvalidClaims
+		}
 		userID := uint(claims["user_id"].(float64))
+
+		// Tokens without a version predate versioning and count as version 0
+		version, ok, err := currentTokenVersion(userID)
+		if err != nil {
+			return 0, jwt.ErrTokenInvalidClaims
+		}
+		if claimed, _ := claims["token_version"].(float64); ok && uint(claimed) != version {
+			return 0, jwt.ErrTokenInvalidClaims
+		}
 		return userID, nil
 	}
 
 	return 0, jwt.ErrSignatureInvalid
 }
+
+// GenerateMFAPendingJWT creates a short-lived token proving the password step
+// of a login succeeded while the second factor is still outstanding. The
+// challenge lets the issuer accept the token only once.
+func GenerateMFAPendingJWT(userID uint, challenge string, ttl time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id":     userID,
+		"mfa_pending": true,
+		"challenge":   challenge,
+		"exp":         time.Now().Add(ttl).Unix(),
+	}
+
+	return ActiveKeySet().Sign(claims)
+}
+
+// ValidateMFAPendingJWT validates a token from GenerateMFAPendingJWT and returns
+// the user ID and challenge
+func ValidateMFAPendingJWT(tokenString string) (uint, string, error) {
+	token, err := ActiveKeySet().Parse(tokenString)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		if pending, _ := claims["mfa_pending"].(bool); !pending {
+			return 0, "", jwt.ErrTokenInvalidClaims
+		}
+		userID := uint(claims["user_id"].(float64))
+		challenge, _ := claims["challenge"].(string)
+		return userID, challenge, nil
+	}
+
+	return 0, "", jwt.ErrSignatureInvalid
+}
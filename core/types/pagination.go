@@ -0,0 +1,18 @@
+package types
+
+import (
+	"base/core/query"
+	"base/core/router"
+)
+
+// ParsePagination reads the request's ?page= and ?limit= with the shared
+// defaults and bounds. Handlers answer an error with 400:
+//
+//	p, err := types.ParsePagination(c)
+//	var perr *query.PaginationError
+//	if errors.As(err, &perr) {
+//		return c.Fail(http.StatusBadRequest, "invalid_"+perr.Param, perr.Message)
+//	}
+func ParsePagination(c *router.Context) (query.Pagination, error) {
+	return query.ParsePagination(c.Request.URL.Query())
+}
@@ -0,0 +1,117 @@
+package types
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"base/core/router"
+)
+
+// Translator resolves a message key for a language, falling back to related
+// languages as it sees fit
+type Translator interface {
+	Lookup(language, key string) (string, bool)
+}
+
+var (
+	translatorMu sync.RWMutex
+	translator   Translator
+)
+
+// defaultMessages are the English messages for the core API keys,
+// used when no translation exists for the request language.
+// Validation messages receive the field name and the rule parameter.
+var defaultMessages = map[string]string{
+	"errors.internal":            "Internal server error",
+	"errors.invalid_id":          "Invalid Id format",
+	"errors.user_not_found":      "User not found",
+	"errors.invalid_credentials": "Invalid credentials",
+	"errors.invalid_2fa_code":    "Invalid two-factor code",
+	"errors.invalid_token":       "Invalid or expired token",
+	"errors.incorrect_password":  "Current password is incorrect",
+	"errors.request_failed":      "An error occurred while processing your request",
+	"errors.fetch_user_failed":   "Failed to fetch user",
+	"errors.reset_failed":        "Failed to reset password",
+	"errors.password_failed":     "Failed to update password",
+	"errors.invalid_body":        "Request body could not be parsed",
+	"validation.required":        "%[1]s is required",
+	"validation.email":           "%[1]s must be a valid email address",
+	"validation.min":             "%[1]s must be at least %[2]s characters long",
+	"validation.max":             "%[1]s must be at most %[2]s characters long",
+	"validation.len":             "%[1]s must be exactly %[2]s characters long",
+	"validation.oneof":           "%[1]s must be one of: %[2]s",
+}
+
+// SetTranslator sets the translator used to localize API messages
+func SetTranslator(t Translator) {
+	translatorMu.Lock()
+	defer translatorMu.Unlock()
+	translator = t
+}
+
+// DefaultMessages returns the English messages for the core API keys
+func DefaultMessages() map[string]string {
+	return maps.Clone(defaultMessages)
+}
+
+// DefaultMessage returns the English message for a core API key
+func DefaultMessage(key string) (string, bool) {
+	message, ok := defaultMessages[key]
+	return message, ok
+}
+
+// MessageKeys returns the core API message keys in order
+func MessageKeys() []string {
+	return slices.Sorted(maps.Keys(defaultMessages))
+}
+
+// GetTranslator returns the translator set with SetTranslator, or nil
+func GetTranslator() Translator {
+	translatorMu.RLock()
+	defer translatorMu.RUnlock()
+	return translator
+}
+
+// Language returns the request language set by the language middleware
+func Language(c *router.Context) string {
+	if language, ok := c.Get("language"); ok {
+		if s, ok := language.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// T translates key into the request language, formatting it with args.
+// It falls back to the English message and then to the raw key.
+func T(c *router.Context, key string, args ...any) string {
+	message, ok := lookup(Language(c), key)
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// NewErrorResponse builds an ErrorResponse whose message is translated from key
+func NewErrorResponse(c *router.Context, key string, args ...any) ErrorResponse {
+	return ErrorResponse{Error: T(c, key, args...)}
+}
+
+func lookup(language, key string) (string, bool) {
+	translatorMu.RLock()
+	t := translator
+	translatorMu.RUnlock()
+
+	if t != nil && language != "" {
+		if message, ok := t.Lookup(language, key); ok {
+			return message, true
+		}
+	}
+	message, ok := defaultMessages[key]
+	return message, ok
+}
@@ -17,6 +17,7 @@ type Validator struct {
 type ValidationError struct {
 	Field   string `json:"field"`
 	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
 	Value   string `json:"value"`
 	Message string `json:"message"`
 }
@@ -37,9 +38,15 @@ func (v ValidationErrors) Error() string {
 func New() *Validator {
 	v := validator.New()
 
-	// Register custom tag name function to use json tags for field names
+	// Models declare their rules in binding tags
+	v.SetTagName("binding")
+
+	// Register custom tag name function to use json (or form) tags for field names
 	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
 		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "" {
+			name = strings.SplitN(fld.Tag.Get("form"), ",", 2)[0]
+		}
 		if name == "-" {
 			return ""
 		}
@@ -67,6 +74,7 @@ func (v *Validator) Validate(data interface{}) ValidationErrors {
 			validationErrors = append(validationErrors, ValidationError{
 				Field:   err.Field(),
 				Tag:     err.Tag(),
+				Param:   err.Param(),
 				Value:   fmt.Sprintf("%v", err.Value()),
 				Message: v.getErrorMessage(err),
 			})
@@ -89,6 +97,7 @@ func (v *Validator) ValidateVar(field interface{}, tag string) ValidationErrors
 			validationErrors = append(validationErrors, ValidationError{
 				Field:   err.Field(),
 				Tag:     err.Tag(),
+				Param:   err.Param(),
 				Value:   fmt.Sprintf("%v", err.Value()),
 				Message: v.getErrorMessage(err),
 			})
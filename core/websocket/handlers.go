@@ -0,0 +1,151 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"base/core/validator"
+)
+
+// MessageHandler handles a client message routed by its type. payload is
+// the message's raw payload; Handle decodes and validates it instead.
+// A returned error is sent back to the client as an "error" message.
+type MessageHandler func(c *Client, payload json.RawMessage) error
+
+// Error codes in ErrorContent
+const (
+	ErrorUnknownType    = "unknown_type"
+	ErrorInvalidPayload = "invalid_payload"
+	ErrorHandler        = "handler_error"
+)
+
+// ErrorContent is the content of the "error" message a client gets back
+// when its message couldn't be handled
+type ErrorContent struct {
+	// Type is the message type that failed
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+	Error   string `json:"error"`
+	Details any    `json:"details,omitempty"`
+}
+
+// On routes client messages of messageType to handler instead of relaying
+// them to the room:
+//
+//	hub.On("chat.send", func(c *websocket.Client, payload json.RawMessage) error { ... })
+//
+// Types containing a dot are reserved for handlers: one nobody registered
+// is answered with an unknown_type error. Other types without a handler are
+// relayed to the room as before.
+func (h *Hub) On(messageType string, handler MessageHandler) {
+	h.handlersMu.Lock()
+	defer h.handlersMu.Unlock()
+	h.handlers[messageType] = handler
+}
+
+// Handle registers a handler receiving the payload decoded into T. Struct
+// payloads are validated with their binding tags; a payload that doesn't
+// decode or validate is answered with an invalid_payload error.
+//
+//	type SendChat struct {
+//		Text string `json:"text" binding:"required,max=500"`
+//	}
+//	websocket.Handle(hub, "chat.send", func(c *websocket.Client, msg SendChat) error { ... })
+func Handle[T any](h *Hub, messageType string, handler func(c *Client, payload T) error) {
+	h.On(messageType, func(c *Client, raw json.RawMessage) error {
+		var payload T
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &payload); err != nil {
+				return &payloadError{err: err}
+			}
+		}
+		if isStruct(payload) {
+			if errs := validator.Validate(payload); len(errs) > 0 {
+				return &payloadError{err: errs, details: errs}
+			}
+		}
+		return handler(c, payload)
+	})
+}
+
+// SendTo queues a message for one client, if it is still connected
+func (h *Hub) SendTo(c *Client, messageType string, content any) {
+	msgBytes, err := json.Marshal(Message{
+		Type:     messageType,
+		Content:  content,
+		Room:     c.Room,
+		Nickname: "System",
+	})
+	if err != nil {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.rooms[c.Room][c] {
+		h.deliver(c.Room, c, msgBytes)
+	}
+}
+
+// routes reports whether messages of messageType go to a handler
+func (h *Hub) routes(messageType string) bool {
+	if strings.Contains(messageType, ".") {
+		return true
+	}
+	h.handlersMu.RLock()
+	defer h.handlersMu.RUnlock()
+	_, ok := h.handlers[messageType]
+	return ok
+}
+
+// dispatch runs the handler for msg and answers failures with an error
+func (h *Hub) dispatch(c *Client, msg Message) {
+	h.handlersMu.RLock()
+	handler, ok := h.handlers[msg.Type]
+	h.handlersMu.RUnlock()
+	if !ok {
+		h.SendTo(c, "error", ErrorContent{
+			Type:  msg.Type,
+			Code:  ErrorUnknownType,
+			Error: "Unknown message type",
+		})
+		return
+	}
+
+	err := handler(c, msg.Payload)
+	if err == nil {
+		return
+	}
+	content := ErrorContent{Type: msg.Type, Code: ErrorHandler, Error: err.Error()}
+	var invalid *payloadError
+	if errors.As(err, &invalid) {
+		content.Code = ErrorInvalidPayload
+		content.Details = invalid.details
+	}
+	h.SendTo(c, "error", content)
+}
+
+// payloadError is a payload Handle couldn't decode or validate
+type payloadError struct {
+	err     error
+	details validator.ValidationErrors
+}
+
+func (e *payloadError) Error() string {
+	return "Invalid payload: " + e.err.Error()
+}
+
+func (e *payloadError) Unwrap() error {
+	return e.err
+}
+
+// isStruct reports whether v is a struct, or a non-nil pointer to one
+func isStruct(v any) bool {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv.Kind() == reflect.Struct
+}
@@ -0,0 +1,102 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type sendChat struct {
+	Text string `json:"text" binding:"required,max=10"`
+}
+
+// receive reads messages until one of messageType arrives
+func receive(t *testing.T, conn *websocket.Conn, messageType string) Message {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	for {
+		var msg Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("waiting for a %s message: %v", messageType, err)
+		}
+		if msg.Type == messageType {
+			return msg
+		}
+	}
+}
+
+// errorContent reads the next error message sent back to conn
+func errorContent(t *testing.T, conn *websocket.Conn) ErrorContent {
+	t.Helper()
+	data, _ := json.Marshal(receive(t, conn, "error").Content)
+	var content ErrorContent
+	if err := json.Unmarshal(data, &content); err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func TestMessageHandlers(t *testing.T) {
+	hub := NewHub()
+	sent := make(chan string, 1)
+	Handle(hub, "chat.send", func(c *Client, msg sendChat) error {
+		sent <- c.Nickname + ": " + msg.Text
+		hub.SendTo(c, "chat.sent", msg.Text)
+		return nil
+	})
+	hub.On("chat.typing", func(c *Client, payload json.RawMessage) error {
+		return errors.New("typing is disabled")
+	})
+	url := startHub(t, hub) + "?room=lobby"
+	ada := dial(t, url+"&nickname=ada")
+	bob := dial(t, url+"&nickname=bob")
+
+	ada.WriteJSON(map[string]any{"type": "chat.send", "payload": map[string]string{"text": "hello"}})
+	select {
+	case got := <-sent:
+		if got != "ada: hello" {
+			t.Errorf("handler got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("chat.send handler didn't run")
+	}
+	if msg := receive(t, ada, "chat.sent"); msg.Content != "hello" {
+		t.Errorf("reply content = %v", msg.Content)
+	}
+
+	tests := []struct {
+		message map[string]any
+		code    string
+	}{
+		{map[string]any{"type": "chat.send", "payload": map[string]string{}}, ErrorInvalidPayload},
+		{map[string]any{"type": "chat.send", "payload": map[string]string{"text": "far too long"}}, ErrorInvalidPayload},
+		{map[string]any{"type": "chat.send", "payload": "not an object"}, ErrorInvalidPayload},
+		{map[string]any{"type": "chat.typing"}, ErrorHandler},
+		{map[string]any{"type": "chat.unknown"}, ErrorUnknownType},
+	}
+	for _, tt := range tests {
+		ada.WriteJSON(tt.message)
+		content := errorContent(t, ada)
+		if content.Code != tt.code || content.Type != tt.message["type"] || content.Error == "" {
+			t.Errorf("%v answered with %+v, want %s", tt.message, content, tt.code)
+		}
+	}
+	ada.WriteJSON(map[string]any{"type": "chat.send", "payload": map[string]string{}})
+	if content := errorContent(t, ada); content.Details == nil {
+		t.Error("validation failure has no details")
+	}
+
+	// Types without a handler and without a dot are still relayed to the room
+	ada.WriteJSON(map[string]any{"type": "chat", "content": "hi all"})
+	if msg := receive(t, bob, "chat"); msg.Content != "hi all" || msg.Nickname != "ada" {
+		t.Errorf("relayed message = %+v", msg)
+	}
+	select {
+	case got := <-sent:
+		t.Errorf("handler ran for %q", got)
+	default:
+	}
+}
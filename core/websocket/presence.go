@@ -0,0 +1,100 @@
+package websocket
+
+import (
+	"slices"
+	"time"
+)
+
+// Presence events, emitted on Config.Emitter with a PresenceEvent
+const (
+	// EventUserOnline is emitted when a user opens their first connection
+	EventUserOnline = "ws.user_online"
+	// EventUserOffline is emitted when a user's last connection has been
+	// closed for PresenceGrace without a reconnect
+	EventUserOffline = "ws.user_offline"
+)
+
+// PresenceEvent is the payload of EventUserOnline and EventUserOffline
+type PresenceEvent struct {
+	UserId uint      `json:"user_id"`
+	At     time.Time `json:"at"`
+}
+
+// OnlineUsers returns the ids of the users with an open connection, or whose
+// last connection closed less than PresenceGrace ago, in ascending order
+func (h *Hub) OnlineUsers() []uint {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	ids := make([]uint, 0, len(h.users)+len(h.leaving))
+	for id := range h.users {
+		ids = append(ids, id)
+	}
+	for id := range h.leaving {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// IsOnline reports whether userId is in OnlineUsers
+func (h *Hub) IsOnline(userId uint) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	_, connected := h.users[userId]
+	_, leaving := h.leaving[userId]
+	return connected || leaving
+}
+
+// arrive records an authenticated client's connection. A user coming back
+// within the grace period stays online without a new event. The caller must
+// hold h.mutex.
+func (h *Hub) arrive(c *Client) {
+	if c.UserId == 0 {
+		return
+	}
+	if _, ok := h.users[c.UserId]; !ok {
+		h.users[c.UserId] = make(map[*Client]bool)
+		if timer, leaving := h.leaving[c.UserId]; leaving {
+			timer.Stop()
+			delete(h.leaving, c.UserId)
+		} else {
+			h.emitPresence(EventUserOnline, c.UserId)
+		}
+	}
+	h.users[c.UserId][c] = true
+}
+
+// depart forgets a client's connection. When it was the user's last one,
+// the user goes offline after PresenceGrace unless they reconnect. The
+// caller must hold h.mutex.
+func (h *Hub) depart(c *Client) {
+	clients, ok := h.users[c.UserId]
+	if !ok || !clients[c] {
+		return
+	}
+	delete(clients, c)
+	if len(clients) > 0 {
+		return
+	}
+	delete(h.users, c.UserId)
+
+	userId := c.UserId
+	var timer *time.Timer
+	timer = time.AfterFunc(h.config.PresenceGrace, func() {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+		// A reconnect stopped or replaced this timer
+		if h.leaving[userId] != timer {
+			return
+		}
+		delete(h.leaving, userId)
+		h.emitPresence(EventUserOffline, userId)
+	})
+	h.leaving[userId] = timer
+}
+
+func (h *Hub) emitPresence(event string, userId uint) {
+	if h.config.Emitter != nil {
+		h.config.Emitter.EmitAsync(event, PresenceEvent{UserId: userId, At: time.Now()})
+	}
+}
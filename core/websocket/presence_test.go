@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"base/core/emitter"
+	"base/core/types"
+
+	"github.com/gorilla/websocket"
+)
+
+// presenceHub starts a hub whose presence events are sent on the returned
+// channel as "ws.user_online 7" and the like
+func presenceHub(t *testing.T, grace time.Duration) (*Hub, string, chan string) {
+	t.Helper()
+	types.SetKeySet(types.NewHMACKeySet("test", "test-secret"))
+	t.Cleanup(func() { types.SetKeySet(nil) })
+
+	events := make(chan string, 10)
+	e := emitter.New()
+	for _, name := range []string{EventUserOnline, EventUserOffline} {
+		e.On(name, func(data any) {
+			events <- fmt.Sprintf("%s %d", name, data.(PresenceEvent).UserId)
+		})
+	}
+	hub := NewHubWithConfig(Config{PresenceGrace: grace, Emitter: e})
+	return hub, startHub(t, hub) + "?room=lobby", events
+}
+
+func token(t *testing.T, userId uint) string {
+	t.Helper()
+	token, err := types.GenerateJWT(userId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// expectEvents fails unless exactly want arrive on events within the timeout
+func expectEvents(t *testing.T, events chan string, timeout time.Duration, want ...string) {
+	t.Helper()
+	var got []string
+	deadline := time.After(timeout)
+	for {
+		select {
+		case event := <-events:
+			got = append(got, event)
+			continue
+		case <-deadline:
+		}
+		break
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestPresence(t *testing.T) {
+	hub, url, events := presenceHub(t, 200*time.Millisecond)
+
+	first := dial(t, url+"&token="+token(t, 7))
+	second, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Authorization": {"Bearer " + token(t, 7)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dial(t, url)
+	if !eventually(t, time.Second, func() bool { return hub.ClientCount() == 3 }) {
+		t.Fatalf("ClientCount = %d, want 3", hub.ClientCount())
+	}
+	if got := hub.OnlineUsers(); !slices.Equal(got, []uint{7}) || !hub.IsOnline(7) || hub.IsOnline(8) {
+		t.Errorf("OnlineUsers = %v, want only 7", got)
+	}
+	expectEvents(t, events, 100*time.Millisecond, "ws.user_online 7")
+
+	// Closing one of two connections keeps the user online
+	second.Close()
+	if !eventually(t, time.Second, func() bool { return hub.ClientCount() == 2 }) {
+		t.Fatal("second connection not unregistered")
+	}
+	if !hub.IsOnline(7) {
+		t.Error("user went offline with a connection open")
+	}
+
+	// A reconnect within the grace period doesn't flap
+	first.Close()
+	if !eventually(t, time.Second, func() bool { return hub.ClientCount() == 1 }) {
+		t.Fatal("first connection not unregistered")
+	}
+	if !hub.IsOnline(7) {
+		t.Error("user offline before the grace period passed")
+	}
+	reconnected := dial(t, url+"&token="+token(t, 7))
+	expectEvents(t, events, 400*time.Millisecond)
+	if !hub.IsOnline(7) {
+		t.Error("user offline after reconnecting")
+	}
+
+	reconnected.Close()
+	expectEvents(t, events, 500*time.Millisecond, "ws.user_offline 7")
+	if hub.IsOnline(7) || len(hub.OnlineUsers()) != 0 {
+		t.Errorf("OnlineUsers = %v after the grace period", hub.OnlineUsers())
+	}
+}
+
+func TestPresenceRejectsInvalidToken(t *testing.T) {
+	_, url, events := presenceHub(t, 0)
+	_, resp, err := websocket.DefaultDialer.Dial(url+"&token=not-a-jwt", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("dial with an invalid token = %v, want 401", err)
+	}
+	expectEvents(t, events, 50*time.Millisecond)
+}
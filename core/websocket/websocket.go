@@ -1,11 +1,18 @@
 package websocket
 
 import (
+	"base/core/emitter"
+	"base/core/metrics"
 	"base/core/router"
+	"base/core/router/middleware"
+	"base/core/types"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -25,6 +32,9 @@ type Client struct {
 	Room     string
 	Conn     *websocket.Conn
 	Send     chan []byte
+
+	// UserId is the authenticated user, or 0 for an anonymous client
+	UserId uint
 }
 
 // Message represents a message structure
@@ -33,6 +43,66 @@ type Message struct {
 	Content  any    `json:"content"`
 	Room     string `json:"room"`
 	Nickname string `json:"nickname"`
+
+	// Payload is the argument of a message routed to a handler registered
+	// with On
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Overflow policies for clients whose send buffer is full
+const (
+	// OverflowDisconnect closes the slow client's connection
+	OverflowDisconnect = "disconnect"
+	// OverflowDrop discards the message for that client and keeps it connected
+	OverflowDrop = "drop"
+)
+
+var (
+	droppedMessages = metrics.Default.NewCounterVec("websocket_dropped_messages_total",
+		"WebSocket messages discarded by reason (buffer_full, rate_limited).", "reason")
+	overflowDisconnects = metrics.Default.NewCounterVec("websocket_overflow_disconnects_total",
+		"WebSocket clients disconnected because their send buffer overflowed.")
+	oversizedDisconnects = metrics.Default.NewCounterVec("websocket_oversized_disconnects_total",
+		"WebSocket clients disconnected for sending a message over the size limit.")
+)
+
+// Config controls connection liveness checks and limits
+type Config struct {
+	// PingInterval is how often the server pings each client; keep it below PongWait
+	PingInterval time.Duration
+	// PongWait is how long a client may stay silent before it's disconnected
+	PongWait time.Duration
+	// WriteWait bounds each write so a slow client can't stall its write pump
+	WriteWait time.Duration
+	// SendBuffer is the number of queued messages per client
+	SendBuffer int
+	// OverflowPolicy decides what happens when a client's send buffer is full:
+	// OverflowDisconnect (default) or OverflowDrop
+	OverflowPolicy string
+	// MaxMessageSize is the largest message in bytes a client may send; the
+	// connection is closed when it's exceeded
+	MaxMessageSize int64
+	// BroadcastRate caps client messages relayed by the hub per second across
+	// all clients; zero disables the cap
+	BroadcastRate int
+	// PresenceGrace is how long a user whose last connection closed stays
+	// online, so a quick reconnect doesn't report them offline
+	PresenceGrace time.Duration
+	// Emitter receives EventUserOnline and EventUserOffline; nil emits nothing
+	Emitter *emitter.Emitter
+}
+
+// DefaultConfig returns the default heartbeat and limit configuration
+func DefaultConfig() Config {
+	return Config{
+		PingInterval:   54 * time.Second,
+		PongWait:       60 * time.Second,
+		WriteWait:      10 * time.Second,
+		SendBuffer:     256,
+		OverflowPolicy: OverflowDisconnect,
+		MaxMessageSize: 64 << 10, // 64KB
+		PresenceGrace:  5 * time.Second,
+	}
 }
 
 // Hub maintains the set of active clients and broadcasts messages to the clients
@@ -42,17 +112,103 @@ type Hub struct {
 	register   chan *Client
 	unregister chan *Client
 	mutex      *sync.Mutex
+	config     Config
+	limiter    *middleware.TokenBucket
+
+	// Presence: the open connections of each authenticated user, and the
+	// offline timers of users whose last connection closed
+	users   map[uint]map[*Client]bool
+	leaving map[uint]*time.Timer
+
+	// Handlers registered with On, by message type
+	handlersMu sync.RWMutex
+	handlers   map[string]MessageHandler
 }
 
-// NewHub creates a new Hub instance
+// NewHub creates a new Hub instance with DefaultConfig
 func NewHub() *Hub {
-	return &Hub{
+	return NewHubWithConfig(DefaultConfig())
+}
+
+// NewHubWithConfig creates a new Hub instance; zero config values use the defaults
+func NewHubWithConfig(config Config) *Hub {
+	defaults := DefaultConfig()
+	if config.PongWait <= 0 {
+		config.PongWait = defaults.PongWait
+	}
+	if config.PingInterval <= 0 || config.PingInterval >= config.PongWait {
+		config.PingInterval = config.PongWait * 9 / 10
+	}
+	if config.WriteWait <= 0 {
+		config.WriteWait = defaults.WriteWait
+	}
+	if config.SendBuffer <= 0 {
+		config.SendBuffer = defaults.SendBuffer
+	}
+	if config.OverflowPolicy != OverflowDrop {
+		config.OverflowPolicy = OverflowDisconnect
+	}
+	if config.MaxMessageSize <= 0 {
+		config.MaxMessageSize = defaults.MaxMessageSize
+	}
+	if config.PresenceGrace < 0 {
+		config.PresenceGrace = 0
+	}
+
+	hub := &Hub{
 		rooms:      make(map[string]map[*Client]bool),
 		broadcast:  make(chan []byte),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		mutex:      &sync.Mutex{},
+		config:     config,
+		users:      make(map[uint]map[*Client]bool),
+		leaving:    make(map[uint]*time.Timer),
+		handlers:   make(map[string]MessageHandler),
+	}
+	if config.BroadcastRate > 0 {
+		hub.limiter = middleware.NewTokenBucket(config.BroadcastRate, time.Second, config.BroadcastRate)
+	}
+	return hub
+}
+
+// deliver queues message for c, applying the overflow policy when its send
+// buffer is full. The caller must hold h.mutex.
+func (h *Hub) deliver(room string, c *Client, message []byte) {
+	select {
+	case c.Send <- message:
+		return
+	default:
+	}
+
+	if h.config.OverflowPolicy == OverflowDrop {
+		droppedMessages.Inc("buffer_full")
+		return
 	}
+	overflowDisconnects.Inc()
+	close(c.Send)
+	delete(h.rooms[room], c)
+	h.depart(c)
+}
+
+// allowRelay reports whether a client message fits within BroadcastRate
+func (h *Hub) allowRelay() bool {
+	if h.limiter == nil || h.limiter.Allow("broadcast") {
+		return true
+	}
+	droppedMessages.Inc("rate_limited")
+	return false
+}
+
+// ClientCount returns the number of connected clients across all rooms
+func (h *Hub) ClientCount() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	count := 0
+	for _, clients := range h.rooms {
+		count += len(clients)
+	}
+	return count
 }
 
 // Run starts the Hub
@@ -65,6 +221,7 @@ func (h *Hub) Run() {
 				h.rooms[client.Room] = make(map[*Client]bool)
 			}
 			h.rooms[client.Room][client] = true
+			h.arrive(client)
 
 			// Send current users list to all clients in the room
 			users := []string{}
@@ -78,12 +235,7 @@ func (h *Hub) Run() {
 			}
 			if usersBytes, err := json.Marshal(usersUpdate); err == nil {
 				for c := range h.rooms[client.Room] {
-					select {
-					case c.Send <- usersBytes:
-					default:
-						close(c.Send)
-						delete(h.rooms[client.Room], c)
-					}
+					h.deliver(client.Room, c, usersBytes)
 				}
 			}
 
@@ -96,12 +248,7 @@ func (h *Hub) Run() {
 			}
 			msgBytes, _ := json.Marshal(joinMsg)
 			for c := range h.rooms[client.Room] {
-				select {
-				case c.Send <- msgBytes:
-				default:
-					close(c.Send)
-					delete(h.rooms[client.Room], c)
-				}
+				h.deliver(client.Room, c, msgBytes)
 			}
 			h.mutex.Unlock()
 
@@ -111,6 +258,7 @@ func (h *Hub) Run() {
 				if _, ok := h.rooms[client.Room][client]; ok {
 					delete(h.rooms[client.Room], client)
 					close(client.Send)
+					h.depart(client)
 
 					// Send leave message
 					leaveMsg := Message{
@@ -121,12 +269,7 @@ func (h *Hub) Run() {
 					}
 					msgBytes, _ := json.Marshal(leaveMsg)
 					for c := range h.rooms[client.Room] {
-						select {
-						case c.Send <- msgBytes:
-						default:
-							close(c.Send)
-							delete(h.rooms[client.Room], c)
-						}
+						h.deliver(client.Room, c, msgBytes)
 					}
 
 					// Send updated users list
@@ -141,12 +284,7 @@ func (h *Hub) Run() {
 					}
 					if usersBytes, err := json.Marshal(usersUpdate); err == nil {
 						for c := range h.rooms[client.Room] {
-							select {
-							case c.Send <- usersBytes:
-							default:
-								close(c.Send)
-								delete(h.rooms[client.Room], c)
-							}
+							h.deliver(client.Room, c, usersBytes)
 						}
 					}
 
@@ -163,12 +301,7 @@ func (h *Hub) Run() {
 			if err := json.Unmarshal(message, &msg); err == nil {
 				if room, ok := h.rooms[msg.Room]; ok {
 					for client := range room {
-						select {
-						case client.Send <- message:
-						default:
-							close(client.Send)
-							delete(h.rooms[msg.Room], client)
-						}
+						h.deliver(msg.Room, client, message)
 					}
 				}
 			}
@@ -183,17 +316,38 @@ func (c *Client) readPump(hub *Hub) {
 		c.Conn.Close()
 	}()
 
+	// Any pong pushes the deadline out; a client that stops answering pings
+	// fails its next read and is unregistered
+	c.Conn.SetReadLimit(hub.config.MaxMessageSize)
+	c.Conn.SetReadDeadline(time.Now().Add(hub.config.PongWait))
+	c.Conn.SetPongHandler(func(string) error {
+		return c.Conn.SetReadDeadline(time.Now().Add(hub.config.PongWait))
+	})
+
 	for {
 		_, message, err := c.Conn.ReadMessage()
 		if err != nil {
+			if errors.Is(err, websocket.ErrReadLimit) {
+				oversizedDisconnects.Inc()
+			}
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				fmt.Printf("WebSocket error: %v\n", err)
 			}
 			break
 		}
 
+		if !hub.allowRelay() {
+			continue
+		}
+
 		var msg Message
 		if err := json.Unmarshal(message, &msg); err == nil {
+			// Registered and dotted types are handled on the server
+			if hub.routes(msg.Type) {
+				hub.dispatch(c, msg)
+				continue
+			}
+
 			// Always ensure nickname is set from the client
 			msg.Nickname = c.Nickname
 			msg.Room = c.Room // Ensure room is set correctly
@@ -209,16 +363,13 @@ func (c *Client) readPump(hub *Hub) {
 			if msg.Type == "cursor_update" || msg.Type == "cursor_move" ||
 				msg.Type == "draw" || msg.Type == "code_update" ||
 				msg.Type == "clear" {
+				hub.mutex.Lock()
 				if room, ok := hub.rooms[c.Room]; ok {
 					for client := range room {
-						select {
-						case client.Send <- msgBytes:
-						default:
-							close(client.Send)
-							delete(hub.rooms[c.Room], client)
-						}
+						hub.deliver(c.Room, client, msgBytes)
 					}
 				}
+				hub.mutex.Unlock()
 			} else {
 				// For other messages, use the general broadcast channel
 				hub.broadcast <- msgBytes
@@ -227,22 +378,40 @@ func (c *Client) readPump(hub *Hub) {
 	}
 }
 
-func (c *Client) writePump() {
+func (c *Client) writePump(config Config) {
+	ticker := time.NewTicker(config.PingInterval)
 	defer func() {
+		ticker.Stop()
 		c.Conn.Close()
 	}()
 
-	for message := range c.Send {
-		w, err := c.Conn.NextWriter(websocket.TextMessage)
-		if err != nil {
-			return
-		}
-		if _, err := w.Write(message); err != nil {
-			return
-		}
+	for {
+		select {
+		case message, ok := <-c.Send:
+			c.Conn.SetWriteDeadline(time.Now().Add(config.WriteWait))
+			if !ok {
+				// The hub closed the channel: unregistered or send buffer overflowed
+				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
 
-		if err := w.Close(); err != nil {
-			return
+			w, err := c.Conn.NextWriter(websocket.TextMessage)
+			if err != nil {
+				return
+			}
+			if _, err := w.Write(message); err != nil {
+				return
+			}
+
+			if err := w.Close(); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			c.Conn.SetWriteDeadline(time.Now().Add(config.WriteWait))
+			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
 		}
 	}
 }
@@ -250,6 +419,12 @@ func (c *Client) writePump() {
 // ServeWs handles WebSocket requests from the peer
 func ServeWs(hub *Hub, c *router.Context) {
 	fmt.Println("Received WebSocket connection request")
+	userId, err := handshakeUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid access token"})
+		return
+	}
+
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		fmt.Printf("Failed to upgrade connection to WebSocket: %v\n", err)
@@ -262,15 +437,31 @@ func ServeWs(hub *Hub, c *router.Context) {
 		Nickname: c.Query("nickname"),
 		Room:     c.Query("room"),
 		Conn:     conn,
-		Send:     make(chan []byte, 256),
+		Send:     make(chan []byte, hub.config.SendBuffer),
+		UserId:   userId,
 	}
 
 	hub.register <- client
 
-	go client.writePump()
+	go client.writePump(hub.config)
 	go client.readPump(hub)
 }
 
+// handshakeUser returns the user id from the access token in the
+// Authorization header, or in the token query parameter for browsers, which
+// can't set headers on a WebSocket handshake. Without a token the client is
+// anonymous.
+func handshakeUser(c *router.Context) (uint, error) {
+	token := c.Query("token")
+	if header, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		token = header
+	}
+	if token == "" {
+		return 0, nil
+	}
+	return types.ValidateJWT(token)
+}
+
 // BroadcastMessage sends a message to all connected clients
 func (h *Hub) BroadcastMessage(messageType string, content any) {
 	message := Message{
@@ -284,32 +475,24 @@ func (h *Hub) BroadcastMessage(messageType string, content any) {
 }
 
 // InitWebSocketModule initializes the WebSocket module
-func InitWebSocketModule(router *router.RouterGroup) *Hub {
-	hub := NewHub()
+func InitWebSocketModule(router *router.RouterGroup, config Config) *Hub {
+	hub := NewHubWithConfig(config)
 	go hub.Run()
 	SetupWebSocketRoutes(router, hub)
 	return hub
 }
 
 // SetupWebSocketRoutes sets up the WebSocket routes
-func SetupWebSocketRoutes(router *router.RouterGroup, hub *Hub) {
-	router.GET("/ws", WebSocketHandler(hub))
+func SetupWebSocketRoutes(r *router.RouterGroup, hub *Hub) {
+	r.GET("/ws", WebSocketHandler(hub)).Doc(router.Doc{
+		Summary:     "Connect to the WebSocket hub",
+		Description: "Upgrades to a WebSocket; id, nickname and room query parameters name the client and its chat room, and token, an access token, enables presence tracking. See the example at /static/chat.html",
+		Tags:        []string{"Core/Websocket"},
+		Status:      http.StatusSwitchingProtocols,
+	})
 }
 
 // WebSocketHandler returns a router.HandlerFunc for handling WebSocket connections
-// @Summary Connect to WebSocket
-// @Description Establishes a WebSocket connection, check example at: /static/chat.html
-// @Security ApiKeyAuth
-// @Security BearerAuth
-// @Tags Core/Websocket
-// @Accept  json
-// @Produce  json
-// @Param id query string false "Client ID"
-// @Param nickname query string false "User Nickname"
-// @Param room query string false "Chat Room"
-// @Success 101 {string} string "Switching Protocols"
-// @Failure 400 {object} ErrorResponse
-// @Router /ws [get]
 func WebSocketHandler(hub *Hub) router.HandlerFunc {
 	return func(c *router.Context) error {
 		ServeWs(hub, c)
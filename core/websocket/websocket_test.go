@@ -0,0 +1,86 @@
+package websocket
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"base/core/router"
+
+	"github.com/gorilla/websocket"
+)
+
+// startHub serves hub's routes on a test server and returns its ws:// URL
+func startHub(t *testing.T, hub *Hub) string {
+	t.Helper()
+	go hub.Run()
+	r := router.New()
+	SetupWebSocketRoutes(r.Group(""), hub)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// eventually polls cond until it holds or the timeout passes
+func eventually(t *testing.T, timeout time.Duration, cond func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return cond()
+}
+
+func TestHeartbeatReapsSilentClients(t *testing.T) {
+	hub := NewHubWithConfig(Config{PingInterval: 50 * time.Millisecond, PongWait: 200 * time.Millisecond})
+	url := startHub(t, hub) + "?room=lobby"
+
+	// The gorilla client answers pings from inside its read loop, so a
+	// client that stops reading stops answering and looks dead to the server
+	live := dial(t, url)
+	go func() {
+		for {
+			if _, _, err := live.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	dial(t, url)
+
+	if !eventually(t, time.Second, func() bool { return hub.ClientCount() == 2 }) {
+		t.Fatalf("ClientCount = %d, want 2 connected", hub.ClientCount())
+	}
+	if !eventually(t, 2*time.Second, func() bool { return hub.ClientCount() == 1 }) {
+		t.Fatalf("ClientCount = %d, want the silent client reaped", hub.ClientCount())
+	}
+
+	// The client answering pings outlives several pong deadlines
+	time.Sleep(500 * time.Millisecond)
+	if got := hub.ClientCount(); got != 1 {
+		t.Errorf("ClientCount = %d, want the live client kept", got)
+	}
+}
+
+func TestNewHubWithConfigDefaults(t *testing.T) {
+	hub := NewHubWithConfig(Config{PongWait: time.Second, PingInterval: 2 * time.Second})
+	if hub.config.PingInterval >= hub.config.PongWait {
+		t.Errorf("PingInterval %v must stay below PongWait %v", hub.config.PingInterval, hub.config.PongWait)
+	}
+	if hub.config.WriteWait != DefaultConfig().WriteWait || hub.config.SendBuffer != DefaultConfig().SendBuffer {
+		t.Errorf("zero values not defaulted: %+v", hub.config)
+	}
+}
@@ -3,24 +3,46 @@ package main
 import (
 	appmodules "base/app"
 	coremodules "base/core/app"
+	"base/core/base"
+	"base/core/cache"
 	"base/core/config"
 	"base/core/database"
 	"base/core/email"
 	"base/core/emitter"
+	"base/core/helper"
 	"base/core/logger"
+	"base/core/metrics"
 	"base/core/module"
+	"base/core/query"
 	"base/core/router"
 	"base/core/router/middleware"
+	"base/core/seed"
+	"base/core/sse"
 	"base/core/storage"
-	_ "base/core/translation"
+	"base/core/swagger"
+	"base/core/tenant"
+	"base/core/translation"
+	"base/core/types"
 	"base/core/websocket"
+	_ "base/migrations"
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
-	"github.com/joho/godotenv" // swagger embed files
+	"golang.org/x/crypto/acme/autocert"
 	"gorm.io/gorm"
 )
 
@@ -74,6 +96,14 @@ type App struct {
 	storage     *storage.ActiveStorage
 	emailSender email.Sender
 	wsHub       *websocket.Hub
+	events      *sse.Broker
+	swagger     *swagger.Generator
+	keys        *types.KeySet
+	modules     []module.Module // initialized core and app modules, in start order
+	cache       cache.Store
+	stopMonitor context.CancelFunc
+	static      []config.StaticMount // mounts added with Static, served after STATIC_MOUNTS
+	redirect    *http.Server         // plain HTTP to HTTPS redirects, when TLS is on
 
 	// State
 	running bool
@@ -90,19 +120,25 @@ func (app *App) Start() error {
 		loadEnvironment().
 		initConfig().
 		initLogger().
+		initKeys().
+		initPasswords().
 		initDatabase().
 		initInfrastructure().
 		initRouter().
 		autoDiscoverModules().
+		migrateTenants().
 		setupRoutes().
+		seedOnBoot().
+		startModules().
 		displayServerInfo().
 		run()
 }
 
 // loadEnvironment loads environment variables
 func (app *App) loadEnvironment() *App {
-	if err := godotenv.Load(); err != nil {
-		// Non-fatal - continue without .env file
+	if err := config.LoadEnv(); err != nil {
+		// Non-fatal - continue with the process environment
+		fmt.Printf("[CONFIG ERROR] %v\n", err)
 	}
 	return app
 }
@@ -110,15 +146,24 @@ func (app *App) loadEnvironment() *App {
 // initConfig initializes configuration
 func (app *App) initConfig() *App {
 	app.config = config.NewConfig()
+	config.SetCurrent(app.config)
 	return app
 }
 
 // initLogger initializes the logger
 func (app *App) initLogger() *App {
 	logConfig := logger.Config{
-		Environment: app.config.Env,
-		LogPath:     "logs",
-		Level:       "debug",
+		Environment:        app.config.Env,
+		LogPath:            app.config.LogPath,
+		Level:              app.config.LogLevel,
+		Format:             app.config.LogFormat,
+		Outputs:            app.config.LogOutputs,
+		MaxSize:            app.config.LogMaxSize,
+		MaxAge:             app.config.LogMaxAge,
+		MaxBackups:         app.config.LogMaxBackups,
+		SamplingInitial:    app.config.LogSamplingInitial,
+		SamplingThereafter: app.config.LogSamplingThereafter,
+		RedactKeys:         app.config.LogRedactKeys,
 	}
 
 	log, err := logger.NewLogger(logConfig)
@@ -127,6 +172,7 @@ func (app *App) initLogger() *App {
 	}
 
 	app.logger = log
+	logger.SetDefault(log)
 	app.logger.Info("🚀 Starting Base Framework",
 		logger.String("version", app.config.Version),
 		logger.String("environment", app.config.Env))
@@ -134,6 +180,33 @@ func (app *App) initLogger() *App {
 	return app
 }
 
+// initKeys loads the JWT signing and verification keys
+func (app *App) initKeys() *App {
+	keys, err := helper.LoadKeys(app.config)
+	if err != nil {
+		app.logger.Error("Failed to load JWT keys", logger.String("error", err.Error()))
+		panic(fmt.Sprintf("JWT key loading failed: %v", err))
+	}
+
+	app.keys = keys
+	types.SetAccessTokenTTL(app.config.AccessTokenTTL)
+	app.logger.Info("✅ JWT keys loaded", logger.String("algorithm", keys.Algorithm()))
+	return app
+}
+
+// initPasswords installs the PASSWORD_HASH_ALGO hasher, refusing to start on
+// an unknown algorithm rather than silently hashing with bcrypt
+func (app *App) initPasswords() *App {
+	hasher, err := helper.ParsePasswordHasher(app.config.PasswordHashAlgo)
+	if err != nil {
+		app.logger.Error("Invalid password hashing configuration", logger.String("error", err.Error()))
+		panic(fmt.Sprintf("Password hasher setup failed: %v", err))
+	}
+
+	helper.SetPasswords(hasher)
+	return app
+}
+
 // initDatabase initializes the database connection
 func (app *App) initDatabase() *App {
 	db, err := database.InitDB(app.config)
@@ -144,13 +217,41 @@ func (app *App) initDatabase() *App {
 
 	app.db = db
 	app.logger.Info("✅ Database initialized")
+
+	// Watch the pool so API requests fail fast while the database is down
+	monitorCtx, cancel := context.WithCancel(context.Background())
+	app.stopMonitor = cancel
+	go database.Monitor(monitorCtx, db.DB, app.config.DBHealthInterval, app.logger)
+
+	// Apply versioned migrations before modules run AutoMigrate
+	if database.HasMigrations() {
+		migrator, err := database.NewMigrator(db.DB)
+		if err != nil {
+			app.logger.Error("Failed to initialize migrator", logger.String("error", err.Error()))
+			panic(fmt.Sprintf("Migration initialization failed: %v", err))
+		}
+		applied, err := migrator.Migrate()
+		for _, id := range applied {
+			app.logger.Info("Applied migration", logger.String("migration", id))
+		}
+		if err != nil {
+			app.logger.Error("Failed to apply migrations", logger.String("error", err.Error()))
+			panic(fmt.Sprintf("Migrations failed: %v", err))
+		}
+	}
+
+	if err := tenant.Init(db.DB, app.config); err != nil {
+		app.logger.Error("Failed to initialize tenancy", logger.String("error", err.Error()))
+		panic(fmt.Sprintf("Tenancy initialization failed: %v", err))
+	}
+
 	return app
 }
 
 // initInfrastructure initializes core infrastructure components
 func (app *App) initInfrastructure() *App {
 	// Initialize emitter
-	app.emitter = &emitter.Emitter{}
+	app.emitter = emitter.New()
 
 	// Initialize storage
 	storageConfig := storage.Config{
@@ -162,6 +263,7 @@ func (app *App) initInfrastructure() *App {
 		Endpoint:  app.config.StorageEndpoint,
 		Bucket:    app.config.StorageBucket,
 		CDN:       app.config.CDN,
+		Dedup:     app.config.StorageDedup,
 	}
 
 	activeStorage, err := storage.NewActiveStorage(app.db.DB, storageConfig)
@@ -181,6 +283,24 @@ func (app *App) initInfrastructure() *App {
 		app.emailSender = emailSender
 	}
 
+	// Shared cache for middleware and modules; Redis when configured,
+	// falling back to memory (non-fatal)
+	if app.config.CacheDriver == "redis" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		store, err := cache.NewRedisStoreFromURL(ctx, app.config.RedisURL, "base:cache:")
+		cancel()
+		if err != nil {
+			app.logger.Warn("Redis cache unavailable - continuing with the in-memory cache",
+				logger.String("error", err.Error()))
+		} else {
+			app.cache = store
+		}
+	}
+	if app.cache == nil {
+		app.cache = cache.NewMemoryStore(app.config.CacheMaxEntries)
+	}
+	cache.SetDefault(app.cache)
+
 	app.logger.Info("✅ Infrastructure initialized")
 	return app
 }
@@ -188,9 +308,30 @@ func (app *App) initInfrastructure() *App {
 // initRouter initializes the router with middleware
 func (app *App) initRouter() *App {
 	app.router = router.New()
+	router.SetEnvelope(app.config.ResponseEnvelope)
+	middleware.SetIdempotencyTTL(app.config.IdempotencyTTL)
+	middleware.SetUploadBodyLimit(app.config.UploadBodyLimit)
+	router.SetMultipartMemory(app.config.MultipartMemory)
+	router.SetCookieConfig(router.CookieConfig{
+		Secure:   app.config.CookieSecure || app.config.TLSEnabled(),
+		SameSite: router.ParseSameSite(app.config.CookieSameSite),
+		Domain:   app.config.CookieDomain,
+	})
+	base.SetMaxBulkItems(app.config.BulkMaxItems)
+	helper.SetPasswordPolicy(helper.PasswordPolicy{
+		MinLength:     app.config.PasswordMinLength,
+		RequireUpper:  app.config.PasswordRequireUpper,
+		RequireLower:  app.config.PasswordRequireLower,
+		RequireDigit:  app.config.PasswordRequireDigit,
+		RequireSymbol: app.config.PasswordRequireSymbol,
+		RejectCommon:  app.config.PasswordRejectCommon,
+	})
+	query.SetLimits(app.config.PaginationLimit, app.config.PaginationMaxLimit)
+	translation.SetDefaultLocale(app.config.DefaultLocale)
 	app.setupMiddleware()
 	app.setupStaticRoutes()
 	app.initWebSocket()
+	app.initEvents()
 
 	app.logger.Info("✅ Router initialized")
 	return app
@@ -211,15 +352,30 @@ func (app *App) setupMiddleware() {
 		}
 	})
 
-	// Request logging middleware
+	// Request metrics, labelled by route pattern
+	if app.config.MetricsEnabled {
+		app.router.Use(middleware.Metrics(metrics.HTTPCollector{}))
+	}
+
+	// Request ids correlate a request's log lines and response
+	app.router.Use(middleware.RequestId())
+
+	// Request logging middleware. Handlers and services pick up the request
+	// logger with logger.FromContext so their lines carry the same fields.
 	app.router.Use(func(next router.HandlerFunc) router.HandlerFunc {
 		return func(c *router.Context) error {
 			start := time.Now()
-			err := next(c)
-
-			app.logger.Info("Request",
+			requestId, _ := c.Get("request_id")
+			requestLogger := app.logger.With(
 				logger.String("method", c.Request.Method),
 				logger.String("path", c.Request.URL.Path),
+				logger.Any("request_id", requestId),
+			)
+			c.WithContext(logger.IntoContext(c.Context(), requestLogger))
+
+			err := next(c)
+
+			requestLogger.Info("Request",
 				logger.Int("status", c.Writer.Status()),
 				logger.Duration("duration", time.Since(start)),
 				logger.String("ip", c.ClientIP()),
@@ -227,17 +383,49 @@ func (app *App) setupMiddleware() {
 			return err
 		}
 	})
-	corsOrigins := strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",")
 
-	// CORS middleware
-	app.router.Use(middleware.CORSMiddleware(corsOrigins))
+	// Response compression
+	if app.config.CompressEnabled {
+		compress := middleware.DefaultCompressConfig()
+		compress.MinSize = app.config.CompressMinSize
+		app.router.Use(middleware.Compress(compress))
+	}
+
+	// HTTPS responses ask browsers to stay on HTTPS
+	if app.config.TLSEnabled() {
+		app.router.Use(middleware.HSTS(app.config.HSTSMaxAge))
+	}
+
+	// CORS middleware; origins follow config reloads
+	app.router.Use(middleware.DynamicCORS(func() []string {
+		return config.Current().CORSAllowedOrigins
+	}))
+
+	// Request language for localized API messages
+	app.router.Use(middleware.Language(app.config.SupportedLocales, app.config.DefaultLocale))
+
+	// Request deadline; queries run with the request context stop with it
+	app.router.Use(middleware.Timeout(app.config.DBQueryTimeout))
+
+	// Request body size; upload routes raise it with middleware.BodyLimitKey
+	app.router.Use(middleware.BodyLimit(app.config.BodyLimit))
 }
 
 // setupStaticRoutes configures static file serving
 func (app *App) setupStaticRoutes() {
-	app.router.Static("/static", "./static")
-	app.router.Static("/storage", "./storage")
-	app.router.Static("/docs", "./docs")
+	static := router.DefaultStaticConfig()
+	static.MaxAge = time.Duration(app.config.StaticMaxAge) * time.Second
+
+	for _, mount := range append(slices.Clone(app.config.StaticMounts), app.static...) {
+		app.router.StaticWithConfig(mount.Prefix, mount.Dir, static)
+	}
+}
+
+// Static serves the files in dir under prefix alongside STATIC_MOUNTS. Call
+// it before Start.
+func (app *App) Static(prefix, dir string) *App {
+	app.static = append(app.static, config.StaticMount{Prefix: prefix, Dir: dir})
+	return app
 }
 
 // initWebSocket initializes the WebSocket hub if enabled
@@ -247,30 +435,141 @@ func (app *App) initWebSocket() {
 		return
 	}
 
-	app.wsHub = websocket.InitWebSocketModule(app.router.Group("/api"))
+	app.wsHub = websocket.InitWebSocketModule(app.router.Group("/api"), websocket.Config{
+		PingInterval:   app.config.WSPingInterval,
+		PongWait:       app.config.WSPongWait,
+		WriteWait:      app.config.WSWriteWait,
+		MaxMessageSize: app.config.WSMaxMessageSize,
+		OverflowPolicy: app.config.WSOverflowPolicy,
+		BroadcastRate:  app.config.WSBroadcastRate,
+		PresenceGrace:  app.config.WSPresenceGrace,
+		Emitter:        app.emitter,
+	})
 	app.logger.Info("✅ WebSocket hub initialized")
 }
 
+// initEvents serves server-sent event streams at /api/events and forwards
+// the SSE_EVENTS emitter events to them
+func (app *App) initEvents() {
+	app.events = sse.NewBroker(sse.Config{
+		Heartbeat: app.config.SSEHeartbeat,
+		Authorize: app.authorizeStream,
+	})
+	app.events.Forward(app.emitter, app.config.SSEEvents...)
+
+	streams := app.router.Group("/api").Set(middleware.TimeoutKey, time.Duration(0)).Security("BearerAuth")
+	streams.GET("/events", app.events.Handler(), middleware.StreamAuth()).Doc(router.Doc{
+		Summary:     "Stream server-sent events",
+		Description: "Streams the comma separated topics query parameter as text/event-stream. Browsers that cannot send headers may pass the access token as the token query parameter instead.",
+		Tags:        []string{"Core/Events"},
+	})
+	app.logger.Info("✅ Event streams initialized")
+}
+
+// authorizeStream lets a signed-in user subscribe to the SSE_EVENTS topics,
+// to user.<id> for their own id and to organization.<id> for organizations
+// they are a member of. Every other topic is refused.
+func (app *App) authorizeStream(userId uint, topic string) bool {
+	if userId == 0 {
+		return false
+	}
+	if slices.Contains(app.config.SSEEvents, topic) {
+		return true
+	}
+
+	if id, ok := strings.CutPrefix(topic, "user."); ok {
+		return id == strconv.FormatUint(uint64(userId), 10)
+	}
+
+	if id, ok := strings.CutPrefix(topic, "organization."); ok {
+		orgId, err := strconv.ParseUint(id, 10, 64)
+		if err != nil || orgId == 0 {
+			return false
+		}
+		var members int64
+		err = app.db.DB.Table("organization_members").
+			Where("user_id = ? AND organization_id = ?", userId, orgId).
+			Count(&members).Error
+		if err != nil {
+			app.logger.Error("Failed to check organization membership for event stream",
+				logger.Uint("user_id", userId),
+				logger.Uint64("organization_id", orgId),
+				logger.String("error", err.Error()))
+			return false
+		}
+		return members > 0
+	}
+
+	return false
+}
+
 // autoDiscoverModules automatically discovers and registers modules
 func (app *App) autoDiscoverModules() *App {
 	app.registerCoreModules()
+	// Strict mode stops here rather than building app modules on broken core ones
+	app.checkModules()
 	app.discoverAndRegisterAppModules()
+	app.checkModules()
 
-	app.logger.Info("✅ Modules auto-discovered and registered")
+	statuses := module.Statuses()
+	summary := module.Summarize(statuses)
+	app.logger.Info("✅ Modules auto-discovered and registered",
+		logger.Int("core", summary.Core),
+		logger.Int("app", summary.App),
+		logger.Int("initialized", summary.Initialized),
+		logger.Int("failed", summary.Failed),
+		logger.Int("skipped", summary.Skipped))
+	for _, status := range statuses {
+		if status.State != module.StateInitialized {
+			app.logger.Warn("Module not running",
+				logger.String("module", status.Name),
+				logger.String("state", status.State),
+				logger.String("error", status.Error))
+		}
+	}
 	return app
 }
 
+// checkModules aborts startup with STRICT_MODULE_INIT when a core module, or
+// an app module that isn't optional, failed or was skipped so far
+func (app *App) checkModules() {
+	if !app.config.StrictModuleInit {
+		return
+	}
+	var failures []string
+	for _, status := range module.Statuses() {
+		if status.State == module.StateInitialized || !status.Required() {
+			continue
+		}
+		app.logger.Error("Required module not running",
+			logger.String("module", status.Name),
+			logger.String("kind", status.Kind),
+			logger.String("state", status.State),
+			logger.String("error", status.Error))
+		failures = append(failures, fmt.Sprintf("  • %s %s (%s): %s", status.Kind, status.Name, status.State, status.Error))
+	}
+	if len(failures) == 0 {
+		return
+	}
+
+	fmt.Printf("\n❌ Application failed to start:\n%d required module(s) failed to initialize with STRICT_MODULE_INIT on:\n%s\n\n",
+		len(failures), strings.Join(failures, "\n"))
+	os.Exit(1)
+}
+
 // registerCoreModules registers core framework modules
 func (app *App) registerCoreModules() {
 	// Create dependencies for core modules
 	deps := module.Dependencies{
 		DB:          app.db.DB,
-		Router:      app.router.Group("/api"),
+		Router:      app.router.Group("/api", middleware.DatabaseAvailable(), middleware.RequireJSON()),
 		Logger:      app.logger,
 		Emitter:     app.emitter,
 		Storage:     app.storage,
 		EmailSender: app.emailSender,
 		Config:      app.config,
+		Cache:       app.cache,
+		Events:      app.events,
 	}
 
 	// Initialize core modules via orchestrator to ensure proper init/migrate/routes
@@ -282,6 +581,7 @@ func (app *App) registerCoreModules() {
 	if err != nil {
 		app.logger.Error("Failed to initialize core modules", logger.String("error", err.Error()))
 	}
+	app.modules = append(app.modules, initialized...)
 
 	app.logger.Info("✅ Core modules registered", logger.Int("count", len(initialized)))
 }
@@ -291,12 +591,14 @@ func (app *App) discoverAndRegisterAppModules() {
 	// Create dependencies for app modules
 	deps := module.Dependencies{
 		DB:          app.db.DB,
-		Router:      app.router.Group("/api"),
+		Router:      app.router.Group("/api", middleware.DatabaseAvailable(), middleware.RequireJSON()),
 		Logger:      app.logger,
 		Emitter:     app.emitter,
 		Storage:     app.storage,
 		EmailSender: app.emailSender,
 		Config:      app.config,
+		Cache:       app.cache,
+		Events:      app.events,
 	}
 
 	// Use app module provider (like core modules)
@@ -315,21 +617,221 @@ func (app *App) discoverAndRegisterAppModules() {
 // initializeModules initializes a collection of modules
 func (app *App) initializeModules(modules map[string]module.Module, deps module.Dependencies) {
 	initializer := module.NewInitializer(app.logger)
-	initializedModules := initializer.Initialize(modules, deps)
+	initializedModules, err := initializer.Initialize(modules, deps)
+	if err != nil {
+		app.logger.Error("Failed to initialize app modules", logger.String("error", err.Error()))
+		return
+	}
+	app.modules = append(app.modules, initializedModules...)
 
 	app.logger.Info("✅ Module initialization complete",
 		logger.Int("total", len(modules)),
 		logger.Int("initialized", len(initializedModules)))
 }
 
+// migrateTenants migrates the models modules registered with
+// tenant.RegisterModels into every organization's schema or database
+func (app *App) migrateTenants() *App {
+	if err := tenant.Migrate(context.Background()); err != nil {
+		app.logger.Error("Failed to migrate tenants", logger.String("error", err.Error()))
+	}
+	return app
+}
+
+// seedOnBoot runs pending seeders for the current environment
+func (app *App) seedOnBoot() *App {
+	if !app.config.SeedOnBoot {
+		return app
+	}
+	if err := app.runSeeders(app.config.Env); err != nil {
+		app.logger.Error("Failed to seed database", logger.String("error", err.Error()))
+	}
+	return app
+}
+
+// runSeeders runs the seeders registered by the initialized modules
+func (app *App) runSeeders(env string) error {
+	ran, err := seed.Run(context.Background(), app.db.DB, env)
+	for _, name := range ran {
+		app.logger.Info("Applied seeder", logger.String("seeder", name))
+	}
+	return err
+}
+
+// Seed initializes the modules so they register their seeders, runs the
+// pending ones and exits. It backs the "seed" command:
+//
+//	base seed [--env=production]
+func (app *App) Seed(args []string) error {
+	app.loadEnvironment().initConfig()
+
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	env := flags.String("env", app.config.Env, "only run seeders enabled for this environment")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	app.initLogger().
+		initKeys().
+		initPasswords().
+		initDatabase().
+		initInfrastructure().
+		initRouter().
+		autoDiscoverModules()
+
+	return app.runSeeders(*env)
+}
+
+// StorageGC initializes the modules so they register their attachments,
+// then removes stored files no attachment references and attachments whose
+// file is gone. It backs the "storage:gc" command:
+//
+//	base storage:gc [--dry-run] [--min-age=1h]
+func (app *App) StorageGC(args []string) error {
+	app.loadEnvironment().initConfig()
+
+	flags := flag.NewFlagSet("storage:gc", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "report what would be removed without removing it")
+	minAge := flags.Duration("min-age", storage.DefaultReconcileMinAge, "skip files and attachments younger than this")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	app.initLogger().
+		initKeys().
+		initPasswords().
+		initDatabase().
+		initInfrastructure().
+		initRouter().
+		autoDiscoverModules()
+
+	report, err := app.storage.Reconcile(context.Background(), storage.ReconcileOptions{
+		DryRun: *dryRun,
+		MinAge: *minAge,
+	})
+	if report != nil {
+		action := "Removed"
+		if report.DryRun {
+			action = "Would remove"
+		}
+		for _, object := range report.OrphanedObjects {
+			fmt.Printf("%s orphaned file %s (%d bytes)\n", action, object.Path, object.Size)
+		}
+		for _, attachment := range report.MissingObjects {
+			fmt.Printf("%s attachment %d, its file %s is missing\n", action, attachment.Id, attachment.Path)
+		}
+		fmt.Printf("%d orphaned files, %d attachments without a file\n",
+			len(report.OrphanedObjects), len(report.MissingObjects))
+	}
+	return err
+}
+
+// Migrate manages the versioned migrations without starting the modules.
+// It backs the "migrate", "migrate:rollback" and "migrate:status" commands:
+//
+//	base migrate
+//	base migrate:rollback [--steps=1]
+//	base migrate:status
+func (app *App) Migrate(command string, args []string) error {
+	app.loadEnvironment().initConfig()
+
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	steps := 1
+	if command == "migrate:rollback" {
+		flags.IntVar(&steps, "steps", 1, "number of applied migrations to roll back")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if steps < 1 {
+		return fmt.Errorf("--steps must be at least 1")
+	}
+
+	app.initLogger()
+
+	// Open the connection directly: initDatabase would apply pending migrations
+	db, err := database.InitDB(app.config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	if sqlDB, err := db.DB.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	migrator, err := database.NewMigrator(db.DB)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "migrate":
+		applied, err := migrator.Migrate()
+		for _, id := range applied {
+			fmt.Printf("Applied %s\n", id)
+		}
+		fmt.Printf("%d migrations applied\n", len(applied))
+		return err
+	case "migrate:rollback":
+		rolledBack, err := migrator.Rollback(steps)
+		for _, id := range rolledBack {
+			fmt.Printf("Rolled back %s\n", id)
+		}
+		fmt.Printf("%d migrations rolled back\n", len(rolledBack))
+		return err
+	case "migrate:status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		pending := 0
+		for _, status := range statuses {
+			if status.Applied && status.AppliedAt != nil {
+				fmt.Printf("[x] %s (applied %s)\n", status.ID, status.AppliedAt.Format(time.RFC3339))
+				continue
+			}
+			pending++
+			fmt.Printf("[ ] %s\n", status.ID)
+		}
+		fmt.Printf("%d migrations, %d pending\n", len(statuses), pending)
+		return nil
+	}
+	return fmt.Errorf("unknown command %q", command)
+}
+
+// startModules runs the Start hook of every initialized module
+func (app *App) startModules() *App {
+	started, err := module.StartModules(context.Background(), app.modules, app.logger)
+	app.modules = started
+	if err != nil {
+		app.logger.Error("Failed to start modules", logger.String("error", err.Error()))
+	}
+	return app
+}
+
 // setupRoutes sets up basic system routes
 func (app *App) setupRoutes() *App {
-	// Health check
-	app.router.GET("/health", func(c *router.Context) error {
+	// Health checks: liveness only needs the process, readiness checks dependencies
+	live := func(c *router.Context) error {
 		return c.JSON(200, map[string]any{
 			"status":  "ok",
 			"version": app.config.Version,
 		})
+	}
+	app.router.GET("/health", live).Doc(router.Doc{
+		Summary:  "Liveness check",
+		Tags:     []string{"System"},
+		Response: map[string]any{},
+	})
+	app.router.GET("/health/live", live).Doc(router.Doc{
+		Summary:  "Liveness check",
+		Tags:     []string{"System"},
+		Response: map[string]any{},
+	})
+	app.router.GET("/health/ready", app.readiness).Doc(router.Doc{
+		Summary:     "Readiness check",
+		Description: "Checks the database and storage, answering 503 with the status of each when one is down",
+		Tags:        []string{"System"},
+		Response:    map[string]any{},
 	})
 
 	// Root endpoint
@@ -338,28 +840,210 @@ func (app *App) setupRoutes() *App {
 			"message": "pong",
 			"version": app.config.Version,
 		})
+	}).Doc(router.Doc{
+		Summary:  "Ping",
+		Tags:     []string{"System"},
+		Response: map[string]any{},
 	})
 
-	// Swagger documentation - serve swag-generated docs
-	app.router.GET("/swagger/*any", func(c *router.Context) error {
-		// Redirect to docs index.html for swagger UI
-		return c.Redirect(302, "/docs/index.html")
+	// Explicit language switch, remembered in the lang cookie
+	app.router.POST("/set-language", middleware.SetLanguage(app.config.SupportedLocales)).Doc(router.Doc{
+		Summary:     "Switch the language",
+		Description: "Takes {\"language\": \"fr\"} as JSON or a language form field and remembers it in the lang cookie. Form posts are redirected to the local path in the redirect field, if any.",
+		Tags:        []string{"System"},
 	})
 
+	// Public keys for services verifying our RS256 tokens
+	if app.keys.Algorithm() == "RS256" {
+		app.router.GET("/.well-known/jwks.json", func(c *router.Context) error {
+			return c.JSON(200, app.keys.JWKS())
+		}).Doc(router.Doc{
+			Summary:  "Public keys for verifying access tokens",
+			Tags:     []string{"System"},
+			Response: map[string]any{},
+		})
+	}
+
+	// Prometheus metrics
+	if app.config.MetricsEnabled {
+		app.setupMetrics()
+	}
+
+	// Loaded modules, for operators checking a deployment
+	if app.config.AdminToken != "" {
+		app.router.GET("/system/modules", app.moduleStatus, middleware.AdminAuth(app.config.AdminToken)).Doc(router.Doc{
+			Summary:  "List the modules processed at startup",
+			Tags:     []string{"System"},
+			Response: map[string]any{},
+			Security: []string{"AdminToken"},
+		})
+	}
+
+	// Swagger documentation
+	if app.config.SwaggerEnabled {
+		app.swagger = swagger.NewGenerator(app.router, swagger.Info{
+			Title:       "Base Framework API",
+			Description: "This is the API documentation for Base Framework",
+			Version:     app.config.Version,
+		})
+		app.router.GET("/swagger/*any", app.swagger.UIHandler("/swagger/doc.json", app.config.SwaggerUseCDN)).Doc(router.Doc{
+			Summary:     "API documentation",
+			Description: "The Swagger UI, with this document at /swagger/doc.json and /swagger/doc.yaml",
+			Tags:        []string{"System"},
+		})
+	}
+
 	return app
 }
 
+// moduleStatus lists the modules processed at startup and what happened to
+// each, so a module that failed to initialize shows up without the logs
+func (app *App) moduleStatus(c *router.Context) error {
+	statuses := module.Statuses()
+	return c.JSON(200, map[string]any{
+		"version": app.config.Version,
+		"summary": module.Summarize(statuses),
+		"modules": statuses,
+	})
+}
+
+// metricsHub is the hub behind the websocket_connected_clients gauge, which
+// is registered once per process since the registry rejects duplicates
+var (
+	metricsHub      atomic.Pointer[websocket.Hub]
+	metricsHubGauge sync.Once
+)
+
+// setupMetrics instruments the database and WebSocket hub and serves /metrics
+func (app *App) setupMetrics() {
+	if err := metrics.InstrumentDB(app.db.DB); err != nil {
+		app.logger.Warn("Failed to instrument database queries", logger.String("error", err.Error()))
+	}
+	if app.wsHub != nil {
+		metricsHub.Store(app.wsHub)
+		metricsHubGauge.Do(func() {
+			metrics.Default.NewGaugeFunc("websocket_connected_clients",
+				"WebSocket clients currently connected.", func() float64 {
+					return float64(metricsHub.Load().ClientCount())
+				})
+		})
+	}
+	app.router.GET("/metrics", metrics.Handler()).Doc(router.Doc{
+		Summary: "Prometheus metrics",
+		Tags:    []string{"System"},
+	})
+}
+
+// healthCheckTimeout bounds each readiness check so probes stay fast
+const healthCheckTimeout = 2 * time.Second
+
+// readiness reports whether the database and storage are reachable, returning
+// 503 with per-component status when any of them is down. The email provider
+// is probed and reported too, but never fails readiness since the app runs
+// without it.
+func (app *App) readiness(c *router.Context) error {
+	checks := map[string]func(ctx context.Context) error{
+		"database": func(ctx context.Context) error {
+			if err := database.HealthError(); err != nil {
+				return err
+			}
+			sqlDB, err := app.db.DB.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+		"storage": func(ctx context.Context) error {
+			if app.storage == nil {
+				return errors.New("storage not initialized")
+			}
+			return app.storage.Ping(ctx)
+		},
+	}
+	optional := map[string]bool{"email": true}
+	if app.emailSender != nil {
+		checks["email"] = func(ctx context.Context) error {
+			return email.Ping(ctx, app.emailSender)
+		}
+	}
+
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		components = make(map[string]any, len(checks)+1)
+		ready      = true
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(c.Context(), healthCheckTimeout)
+			defer cancel()
+
+			status := map[string]any{"status": "up"}
+			if err := check(ctx); err != nil {
+				status = map[string]any{"status": "down", "error": err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			components[name] = status
+			if status["status"] == "down" && !optional[name] {
+				ready = false
+			}
+		}()
+	}
+	wg.Wait()
+
+	if app.emailSender == nil {
+		components["email"] = map[string]any{"status": "disabled"}
+	}
+
+	code, status := 200, "ok"
+	if !ready {
+		code, status = 503, "unavailable"
+	}
+	return c.JSON(code, map[string]any{
+		"status":     status,
+		"version":    app.config.Version,
+		"components": components,
+	})
+}
+
 // displayServerInfo shows server startup information
 func (app *App) displayServerInfo() *App {
-	localIP := app.getLocalIP()
-	port := app.config.ServerPort
+	if app.config.NoBanner {
+		return app
+	}
+
+	network, address, err := app.config.ListenAddress()
+	if err != nil {
+		// run reports the invalid address
+		return app
+	}
 
 	fmt.Printf("\n🎉 Base Framework Ready!\n\n")
 	fmt.Printf("📍 Server URLs:\n")
-	fmt.Printf("   • Local:   http://localhost%s\n", port)
-	fmt.Printf("   • Network: http://%s%s\n", localIP, port)
+	scheme := "http"
+	if app.config.TLSEnabled() {
+		scheme = "https"
+	}
+	docsURL := "/swagger/index.html"
+	if network == "unix" {
+		fmt.Printf("   • Socket:  unix:%s\n", address)
+	} else {
+		host, port, _ := net.SplitHostPort(address)
+		if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+			fmt.Printf("   • Local:   %s://localhost:%s\n", scheme, port)
+			fmt.Printf("   • Network: %s://%s\n", scheme, net.JoinHostPort(app.getLocalIP(), port))
+			docsURL = scheme + "://localhost:" + port + docsURL
+		} else {
+			fmt.Printf("   • Local:   %s://%s\n", scheme, address)
+			docsURL = scheme + "://" + address + docsURL
+		}
+	}
 	fmt.Printf("\n📚 Documentation:\n")
-	fmt.Printf("   • Swagger: http://localhost%s/swagger/index.html\n", port)
+	fmt.Printf("   • Swagger: %s\n", docsURL)
 	fmt.Printf("\n")
 
 	return app
@@ -382,32 +1066,241 @@ func (app *App) getLocalIP() string {
 	return "localhost"
 }
 
-// run starts the HTTP server
+// run starts the HTTP server and blocks until it fails or SIGINT/SIGTERM
+// triggers a graceful shutdown
 func (app *App) run() error {
 	app.running = true
-	port := app.config.ServerPort
+
+	network, address, err := app.config.ListenAddress()
+	if err != nil {
+		app.logger.Error("❌ Invalid server address", logger.String("error", err.Error()))
+		return fmt.Errorf("invalid server address: %w", err)
+	}
 
 	app.logger.Info("🌐 Server starting",
-		logger.String("port", port))
+		logger.String("network", network),
+		logger.String("address", address))
 
-	err := app.router.Run(port)
+	if network == "unix" {
+		removeStaleSocket(address)
+	}
+	tlsConfig, challenges, err := app.tlsConfig()
 	if err != nil {
-		// Check if it's an "address already in use" error
-		if strings.Contains(err.Error(), "bind: address already in use") {
-			app.logger.Error("❌ Server failed to start - Port already in use",
-				logger.String("port", port),
+		app.logger.Error("❌ Invalid TLS configuration", logger.String("error", err.Error()))
+		return fmt.Errorf("invalid TLS configuration: %w", err)
+	}
+
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		return app.serverFailed(address, err)
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+		app.startRedirect(address, challenges)
+	}
+	app.ready(ln.Addr().String())
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- app.router.Serve(ln)
+	}()
+
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// SIGHUP reloads the configuration without a restart
+	defer app.reloadOnHangup()()
+
+	select {
+	case err = <-serverErr:
+	case <-signals.Done():
+		return app.Stop()
+	}
+
+	app.removeReadyFile()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return app.serverFailed(address, err)
+	}
+	return nil
+}
+
+// tlsConfig builds the TLS configuration from TLS_CERT/TLS_KEY or
+// TLS_AUTOCERT_DOMAINS, or returns nil when TLS is off. With autocert, the
+// returned handler answers ACME HTTP challenges on the redirect listener.
+func (app *App) tlsConfig() (*tls.Config, func(http.Handler) http.Handler, error) {
+	cfg := app.config
+	if !cfg.TLSEnabled() {
+		return nil, nil, nil
+	}
+	if cfg.TLSCert != "" && len(cfg.TLSAutocertDomains) > 0 {
+		return nil, nil, errors.New("TLS_CERT and TLS_AUTOCERT_DOMAINS can't both be set")
+	}
+
+	if len(cfg.TLSAutocertDomains) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.TLSAutocertDomains...),
+			Cache:      autocert.DirCache(cfg.TLSAutocertCache),
+		}
+		return manager.TLSConfig(), manager.HTTPHandler, nil
+	}
+
+	if cfg.TLSKey == "" {
+		return nil, nil, errors.New("TLS_KEY is required with TLS_CERT")
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load TLS_CERT and TLS_KEY: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}, nil, nil
+}
+
+// startRedirect answers plain HTTP on TLS_REDIRECT_ADDRESS with a redirect
+// to the HTTPS server listening on address
+func (app *App) startRedirect(address string, challenges func(http.Handler) http.Handler) {
+	if app.config.TLSRedirectAddress == "" {
+		return
+	}
+
+	_, httpsPort, _ := net.SplitHostPort(address)
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+	if challenges != nil {
+		handler = challenges(handler)
+	}
+
+	app.redirect = &http.Server{
+		Addr:              app.config.TLSRedirectAddress,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := app.redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			app.logger.Error("HTTPS redirect server failed",
+				logger.String("address", app.config.TLSRedirectAddress),
 				logger.String("error", err.Error()))
-			return fmt.Errorf("port %s is already in use. Please:\n  • Stop any other servers running on this port\n  • Change the SERVER_PORT in your .env file\n  • Use a different port with: export SERVER_PORT=:8101", port)
 		}
-		// For other network errors, provide a generic helpful message
-		app.logger.Error("❌ Server failed to start",
+	}()
+	app.logger.Info("🔒 Redirecting HTTP to HTTPS",
+		logger.String("address", app.config.TLSRedirectAddress))
+}
+
+// serverFailed logs why the server couldn't start or stopped serving
+func (app *App) serverFailed(address string, err error) error {
+	// Check if it's an "address already in use" error
+	if strings.Contains(err.Error(), "bind: address already in use") {
+		app.logger.Error("❌ Server failed to start - Address already in use",
+			logger.String("address", address),
 			logger.String("error", err.Error()))
-		return fmt.Errorf("server failed to start: %w", err)
+		return fmt.Errorf("%s is already in use. Please:\n  • Stop any other servers running on this address\n  • Change the SERVER_PORT or SERVER_HOST in your .env file\n  • Use a different port with: export SERVER_PORT=:8101", address)
+	}
+	// For other network errors, provide a generic helpful message
+	app.logger.Error("❌ Server failed to start",
+		logger.String("error", err.Error()))
+	return fmt.Errorf("server failed to start: %w", err)
+}
+
+// removeStaleSocket removes a Unix socket left behind by a server that
+// didn't shut down cleanly; a socket something still answers on is kept
+func removeStaleSocket(path string) {
+	info, err := os.Stat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return
+	}
+	os.Remove(path)
+}
+
+// ready signals that the server is listening: a structured server.ready log
+// line, and READY_FILE holding the address and pid when configured
+func (app *App) ready(address string) {
+	app.logger.Info("server.ready",
+		logger.String("address", address),
+		logger.Bool("tls", app.config.TLSEnabled()),
+		logger.Int("pid", os.Getpid()))
+
+	if app.config.ReadyFile == "" {
+		return
+	}
+	content := fmt.Sprintf("address=%s\npid=%d\n", address, os.Getpid())
+	if err := os.WriteFile(app.config.ReadyFile, []byte(content), 0644); err != nil {
+		app.logger.Error("Failed to write ready file",
+			logger.String("path", app.config.ReadyFile),
+			logger.String("error", err.Error()))
+	}
+}
+
+// removeReadyFile removes READY_FILE so a stopped server doesn't look ready
+func (app *App) removeReadyFile() {
+	if app.config.ReadyFile == "" {
+		return
+	}
+	if err := os.Remove(app.config.ReadyFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		app.logger.Warn("Failed to remove ready file",
+			logger.String("path", app.config.ReadyFile),
+			logger.String("error", err.Error()))
+	}
+}
+
+// reloadOnHangup calls reloadConfig on every SIGHUP until the returned
+// function is called
+func (app *App) reloadOnHangup() (stop func()) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	go func() {
+		for range hangups {
+			app.reloadConfig()
+		}
+	}()
+	return func() {
+		signal.Stop(hangups)
+		close(hangups)
 	}
-	return nil
 }
 
-// Graceful shutdown (future enhancement)
+// reloadConfig re-reads the environment and applies the values listed in
+// config.Reloadable; changes to anything else are logged as needing a
+// restart
+func (app *App) reloadConfig() {
+	next, restart, err := config.Reload()
+	if err != nil {
+		app.logger.Error("Configuration reload failed, keeping the current configuration",
+			logger.String("error", err.Error()))
+		return
+	}
+
+	if l, ok := app.logger.(interface{ SetLevel(string) error }); ok {
+		if err := l.SetLevel(next.LogLevel); err != nil {
+			app.logger.Warn("Failed to change log level", logger.String("error", err.Error()))
+		}
+	}
+	router.SetEnvelope(next.ResponseEnvelope)
+	base.SetMaxBulkItems(next.BulkMaxItems)
+
+	if len(restart) > 0 {
+		app.logger.Warn("Configuration changes need a restart to take effect",
+			logger.Any("fields", restart))
+	}
+	app.logger.Info("🔄 Configuration reloaded",
+		logger.String("log_level", next.LogLevel))
+}
+
+// Stop drains in-flight requests, then stops modules in reverse start order
 func (app *App) Stop() error {
 	if !app.running {
 		return nil
@@ -415,7 +1308,27 @@ func (app *App) Stop() error {
 
 	app.logger.Info("🛑 Shutting down gracefully...")
 	app.running = false
-	return nil
+	app.removeReadyFile()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := app.router.Shutdown(ctx); err != nil {
+		app.logger.Error("Failed to shut down server", logger.String("error", err.Error()))
+	}
+	if app.redirect != nil {
+		if err := app.redirect.Shutdown(ctx); err != nil {
+			app.logger.Error("Failed to shut down HTTPS redirect server", logger.String("error", err.Error()))
+		}
+	}
+	err := module.StopModules(ctx, app.modules, app.logger)
+	if app.stopMonitor != nil {
+		app.stopMonitor()
+	}
+	if closeErr := tenant.Close(); closeErr != nil {
+		app.logger.Error("Failed to close tenant databases", logger.String("error", closeErr.Error()))
+	}
+	return err
 }
 
 func main() {
@@ -423,6 +1336,30 @@ func main() {
 	// Initialize the Base application
 	app := New()
 
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		if err := app.Seed(os.Args[2:]); err != nil {
+			fmt.Printf("\n❌ Seeding failed:\n%v\n\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "storage:gc" {
+		if err := app.StorageGC(os.Args[2:]); err != nil {
+			fmt.Printf("\n❌ Storage cleanup failed:\n%v\n\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && (os.Args[1] == "migrate" || strings.HasPrefix(os.Args[1], "migrate:")) {
+		if err := app.Migrate(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Printf("\n❌ Migration failed:\n%v\n\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Normal application startup
 	if err := app.Start(); err != nil {
 		// Print user-friendly error message instead of panicking
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"base/core/config"
+	"base/core/database"
+	"base/core/logger"
+	"base/core/module"
+	"base/core/router"
+	"base/core/router/middleware"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestHangupReloadsLogLevel(t *testing.T) {
+	if value, ok := os.LookupEnv("LOG_LEVEL"); ok {
+		os.Unsetenv("LOG_LEVEL")
+		t.Cleanup(func() { os.Setenv("LOG_LEVEL", value) })
+	}
+	env := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(env, []byte("LOG_LEVEL=info\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.LoadEnv(env); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Unsetenv("LOG_LEVEL")
+		config.SetCurrent(nil)
+	})
+
+	app := New()
+	app.initConfig()
+	log, err := logger.NewLogger(logger.Config{Level: app.config.LogLevel, Outputs: []string{"stdout"}, Format: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.logger = log
+	core := log.GetZapLogger().Core()
+	if core.Enabled(zapcore.DebugLevel) {
+		t.Fatal("debug enabled at startup with LOG_LEVEL=info")
+	}
+
+	stop := app.reloadOnHangup()
+	defer stop()
+	if err := os.WriteFile(env, []byte("LOG_LEVEL=debug\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !core.Enabled(zapcore.DebugLevel) {
+		if time.Now().After(deadline) {
+			t.Fatal("debug still disabled after SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := config.Current().LogLevel; got != "debug" {
+		t.Errorf("config.Current().LogLevel = %q, want debug", got)
+	}
+}
+
+func TestReadySignal(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	readyFile := filepath.Join(t.TempDir(), "ready")
+	app := &App{
+		config: &config.Config{ReadyFile: readyFile},
+		logger: logger.NewLoggerFromZap(zap.New(core)),
+	}
+
+	app.ready("127.0.0.1:8100")
+	entries := logs.FilterMessage("server.ready").All()
+	if len(entries) != 1 {
+		t.Fatalf("%d server.ready lines", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["address"] != "127.0.0.1:8100" || fields["pid"] != int64(os.Getpid()) {
+		t.Errorf("server.ready fields = %v", fields)
+	}
+
+	content, err := os.ReadFile(readyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("address=127.0.0.1:8100\npid=%d\n", os.Getpid()); string(content) != want {
+		t.Errorf("ready file = %q, want %q", content, want)
+	}
+
+	app.removeReadyFile()
+	if _, err := os.Stat(readyFile); !os.IsNotExist(err) {
+		t.Errorf("ready file left after shutdown: %v", err)
+	}
+}
+
+func TestNoBanner(t *testing.T) {
+	banner := func(noBanner bool) string {
+		t.Helper()
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdout := os.Stdout
+		os.Stdout = w
+		defer func() { os.Stdout = stdout }()
+
+		app := &App{config: &config.Config{ServerPort: ":8100", NoBanner: noBanner}}
+		app.displayServerInfo()
+		w.Close()
+		out, _ := io.ReadAll(r)
+		return string(out)
+	}
+
+	if banner(false) == "" {
+		t.Error("no banner by default")
+	}
+	if out := banner(true); out != "" {
+		t.Errorf("NO_BANNER printed %q", out)
+	}
+}
+
+func TestAuthorizeStream(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "app.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("CREATE TABLE organization_members (user_id integer, organization_id integer)").Error; err != nil {
+		t.Fatal(err)
+	}
+	db.Exec("INSERT INTO organization_members VALUES (7, 3)")
+
+	app := New()
+	app.config = &config.Config{SSEEvents: []string{"user.registered"}}
+	app.db = &database.Database{DB: db}
+	app.logger = logger.NewLoggerFromZap(zap.NewNop())
+
+	tests := []struct {
+		userId uint
+		topic  string
+		want   bool
+	}{
+		{7, "user.registered", true},
+		{7, "user.7", true},
+		{7, "user.8", false},
+		{7, "organization.3", true},
+		{7, "organization.4", false},
+		{8, "organization.3", false},
+		{7, "organization.x", false},
+		{7, "jobs", false},
+		{0, "user.registered", false},
+		{0, "user.0", false},
+	}
+	for _, tt := range tests {
+		if got := app.authorizeStream(tt.userId, tt.topic); got != tt.want {
+			t.Errorf("authorizeStream(%d, %q) = %t, want %t", tt.userId, tt.topic, got, tt.want)
+		}
+	}
+}
+
+// stubModule is a module whose Init returns err
+type stubModule struct{ err error }
+
+func (m stubModule) Init() error      { return m.err }
+func (m stubModule) Migrate() error   { return nil }
+func (m stubModule) GetModels() []any { return nil }
+
+func TestModuleStatus(t *testing.T) {
+	log := logger.NewLoggerFromZap(zap.NewNop())
+	_, err := module.NewInitializer(log).Initialize(map[string]module.Module{
+		"main_status_ok":     stubModule{},
+		"main_status_broken": stubModule{err: errors.New("boom")},
+	}, module.Dependencies{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := New()
+	app.config = &config.Config{Version: "1.0.0"}
+	r := router.New()
+	r.GET("/system/modules", app.moduleStatus, middleware.AdminAuth("s3cret"))
+
+	req := httptest.NewRequest(http.MethodGet, "/system/modules", nil)
+	req.Header.Set(middleware.AdminTokenHeader, "s3cret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var body struct {
+		Version string
+		Summary module.Summary
+		Modules []module.Status
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	if body.Version != "1.0.0" || body.Summary != module.Summarize(module.Statuses()) {
+		t.Errorf("version %q, summary %+v", body.Version, body.Summary)
+	}
+	states := map[string]string{}
+	for _, status := range body.Modules {
+		states[status.Name] = status.State + " " + status.Error
+	}
+	if states["main_status_ok"] != "initialized " || states["main_status_broken"] != "failed boom" {
+		t.Errorf("module states = %v", states)
+	}
+}
+
+// optionalModule is a stubModule the app can run without
+type optionalModule struct{ stubModule }
+
+func (optionalModule) Optional() bool { return true }
+
+func TestCheckModules(t *testing.T) {
+	// The scenarios run in a child process, since strict mode exits
+	if scenario := os.Getenv("CHECK_MODULES_SCENARIO"); scenario != "" {
+		mod := module.Module(stubModule{err: errors.New("boom")})
+		if scenario == "optional" {
+			mod = optionalModule{stubModule{err: errors.New("boom")}}
+		}
+		log := logger.NewLoggerFromZap(zap.NewNop())
+		module.NewInitializer(log).Initialize(map[string]module.Module{"main_check_broken": mod}, module.Dependencies{})
+
+		app := New()
+		app.config = &config.Config{StrictModuleInit: scenario != "lenient"}
+		app.logger = log
+		app.checkModules()
+		fmt.Println("started")
+		return
+	}
+
+	tests := []struct {
+		scenario string
+		exit     int
+		output   string
+	}{
+		{"required", 1, "app main_check_broken (failed): boom"},
+		{"optional", 0, "started"},
+		{"lenient", 0, "started"},
+	}
+	for _, tt := range tests {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestCheckModules$")
+		cmd.Env = append(os.Environ(), "CHECK_MODULES_SCENARIO="+tt.scenario)
+		output, _ := cmd.Output()
+		if code := cmd.ProcessState.ExitCode(); code != tt.exit || !strings.Contains(string(output), tt.output) {
+			t.Errorf("%s: exit %d with %q, want exit %d with %q", tt.scenario, code, output, tt.exit, tt.output)
+		}
+	}
+}
+
+// newTestApp sets up the routes of an app on a temporary SQLite database,
+// with Swagger and metrics on
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", filepath.Join(dir, "app.db"))
+	t.Setenv("STORAGE_PATH", filepath.Join(dir, "storage"))
+	t.Setenv("LOG_PATH", filepath.Join(dir, "logs"))
+	t.Setenv("LOG_OUTPUT", "file")
+	t.Setenv("SWAGGER_ENABLED", "true")
+	t.Setenv("METRICS_ENABLED", "true")
+	t.Setenv("ADMIN_TOKEN", "s3cret")
+	t.Cleanup(func() {
+		config.SetCurrent(nil)
+		logger.SetDefault(nil)
+	})
+
+	app := New().
+		initConfig().
+		initLogger().
+		initKeys().
+		initPasswords().
+		initDatabase().
+		initInfrastructure().
+		initRouter().
+		autoDiscoverModules().
+		setupRoutes()
+	t.Cleanup(func() {
+		app.stopMonitor()
+		if sqlDB, err := app.db.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return app
+}
+
+func TestRoutesAreDocumented(t *testing.T) {
+	app := newTestApp(t)
+	paths := app.swagger.GenerateSwaggerDoc()["paths"].(map[string]any)
+	routes := app.router.Routes()
+	if len(routes) < 100 {
+		t.Fatalf("only %d routes registered", len(routes))
+	}
+	for _, route := range routes {
+		if route.Docs == nil {
+			t.Errorf("%s %s has no router.Doc", route.Method, route.Path)
+			continue
+		}
+		path := route.Path
+		for _, segment := range strings.Split(path, "/") {
+			if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+				path = strings.Replace(path, segment, "{"+segment[1:]+"}", 1)
+			}
+		}
+		item, _ := paths[path].(map[string]any)
+		if _, ok := item[strings.ToLower(route.Method)]; !ok {
+			t.Errorf("%s %s missing from the generated document", route.Method, route.Path)
+		}
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	app := newTestApp(t)
+	server := httptest.NewServer(app.router)
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/ws?room=lobby", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	// Modules register once per process, so this is a route of main's own
+	resp, err := http.Get(server.URL + "/storage/avatars/missing.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// The hub registers clients asynchronously
+	var body string
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err := http.Get(server.URL + "/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		scraped, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		body = string(scraped)
+		if strings.Contains(body, "\nwebsocket_connected_clients 1\n") || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Requests are labelled by route pattern, not by the path requested
+	series := fmt.Sprintf(`method="GET",route="/storage/*filepath",status="%d"`, resp.StatusCode)
+	for _, want := range []string{
+		"# TYPE http_request_duration_seconds histogram",
+		"http_request_duration_seconds_bucket{" + series + `,le="0.005"}`,
+		"http_request_duration_seconds_bucket{" + series + `,le="+Inf"} 1`,
+		"http_request_duration_seconds_count{" + series + "} 1",
+		"http_request_duration_seconds_sum{" + series + "} ",
+		"# TYPE websocket_connected_clients gauge",
+		"\nwebsocket_connected_clients 1\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics is missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `route="/storage/avatars/missing.png"`) {
+		t.Error("a request was labelled with its raw path")
+	}
+}
@@ -0,0 +1,22 @@
+// Package migrations holds versioned schema migrations for the application.
+//
+// Each migration lives in its own file named after its ID and registers
+// itself from init():
+//
+//	func init() {
+//		database.RegisterMigration(database.Migration{
+//			ID: "20240101120000_drop_posts_legacy_slug",
+//			Up: func(tx *gorm.DB) error {
+//				return tx.Migrator().DropColumn("posts", "legacy_slug")
+//			},
+//			Down: func(tx *gorm.DB) error {
+//				return tx.Exec("ALTER TABLE posts ADD COLUMN legacy_slug varchar(255)").Error
+//			},
+//		})
+//	}
+//
+// Pending migrations are applied in ID order on startup, before module
+// AutoMigrate runs, and are recorded in the schema_migrations table. The
+// migrate, migrate:rollback [--steps=1] and migrate:status commands manage
+// them without starting the server.
+package migrations